@@ -0,0 +1,50 @@
+// Copyright (c) 2025 Suricata Contributors
+// Original Author: Stefano Scafiti
+//
+// This file is part of Suricata: Type-Safe AI Agents for Go.
+//
+// Licensed under the MIT License. You may obtain a copy of the License at
+//
+//	https://opensource.org/licenses/MIT
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package main
+
+import (
+	"fmt"
+
+	"github.com/ostafen/suricata/pkg/spec"
+	"github.com/spf13/cobra"
+)
+
+func runDiff(cmd *cobra.Command, args []string) error {
+	from, err := spec.LoadSpec(args[0])
+	if err != nil {
+		return fmt.Errorf("%s: %w", args[0], err)
+	}
+
+	to, err := spec.LoadSpec(args[1])
+	if err != nil {
+		return fmt.Errorf("%s: %w", args[1], err)
+	}
+
+	allowBreaking, err := cmd.Flags().GetBool("allow-breaking")
+	if err != nil {
+		return err
+	}
+
+	changes := spec.Diff(from, to)
+	for _, c := range changes {
+		fmt.Fprintln(cmd.OutOrStdout(), c)
+	}
+
+	if spec.HasBreaking(changes) && !allowBreaking {
+		return fmt.Errorf("%s has breaking changes", args[1])
+	}
+	return nil
+}
@@ -0,0 +1,66 @@
+// Copyright (c) 2025 Suricata Contributors
+// Original Author: Stefano Scafiti
+//
+// This file is part of Suricata: Type-Safe AI Agents for Go.
+//
+// Licensed under the MIT License. You may obtain a copy of the License at
+//
+//	https://opensource.org/licenses/MIT
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package main
+
+import (
+	"os"
+
+	"github.com/ostafen/suricata/pkg/gen"
+	"github.com/ostafen/suricata/pkg/spec"
+	"github.com/spf13/cobra"
+)
+
+func runDoc(cmd *cobra.Command, args []string) error {
+	html, err := cmd.Flags().GetBool("html")
+	if err != nil {
+		return err
+	}
+
+	output, err := cmd.Flags().GetString("output")
+	if err != nil {
+		return err
+	}
+
+	s, err := spec.LoadSpec(args[0])
+	if err != nil {
+		return err
+	}
+
+	h, err := promptHost(s)
+	if err != nil {
+		return err
+	}
+
+	render := func(agent, action string, input []byte) (string, error) {
+		return renderPrompt(h, agent, action, input)
+	}
+
+	generate := gen.GenerateMarkdown
+	if html {
+		generate = gen.GenerateHTML
+	}
+
+	doc, err := generate(s, render)
+	if err != nil {
+		return err
+	}
+
+	if output == "" {
+		_, err := cmd.OutOrStdout().Write(doc)
+		return err
+	}
+	return os.WriteFile(output, doc, 0666)
+}
@@ -0,0 +1,65 @@
+// Copyright (c) 2025 Suricata Contributors
+// Original Author: Stefano Scafiti
+//
+// This file is part of Suricata: Type-Safe AI Agents for Go.
+//
+// Licensed under the MIT License. You may obtain a copy of the License at
+//
+//	https://opensource.org/licenses/MIT
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package main
+
+import (
+	"bytes"
+	"fmt"
+	"os"
+
+	"github.com/ostafen/suricata/pkg/spec"
+	"github.com/spf13/cobra"
+)
+
+func runFmt(cmd *cobra.Command, args []string) error {
+	write, err := cmd.Flags().GetBool("write")
+	if err != nil {
+		return err
+	}
+
+	list, err := cmd.Flags().GetBool("list")
+	if err != nil {
+		return err
+	}
+
+	for _, path := range args {
+		src, err := os.ReadFile(path)
+		if err != nil {
+			return err
+		}
+
+		out, err := spec.Format(src)
+		if err != nil {
+			return fmt.Errorf("%s: %w", path, err)
+		}
+
+		changed := !bytes.Equal(src, out)
+		if list && changed {
+			fmt.Fprintln(cmd.OutOrStdout(), path)
+		}
+		if write && changed {
+			if err := os.WriteFile(path, out, 0666); err != nil {
+				return err
+			}
+		}
+		if !list && !write {
+			if _, err := cmd.OutOrStdout().Write(out); err != nil {
+				return err
+			}
+		}
+	}
+	return nil
+}
@@ -16,14 +16,20 @@
 package main
 
 import (
+	"bytes"
 	"fmt"
+	"io"
 	"os"
 	"path/filepath"
 	"strings"
 
 	"github.com/ostafen/suricata/pkg/gen"
+	"github.com/ostafen/suricata/pkg/host"
+	"github.com/ostafen/suricata/pkg/importer"
 	"github.com/ostafen/suricata/pkg/spec"
+	"github.com/ostafen/suricata/runtime"
 	"github.com/spf13/cobra"
+	"gopkg.in/yaml.v3"
 )
 
 func main() {
@@ -39,40 +45,478 @@ func main() {
 		RunE:         runGen,
 	}
 
+	genCmd.Flags().StringP("out", "o", ".", "Root directory of the generated packages")
+	genCmd.Flags().String("module", "", "Go module of the output directory: the module prefix is stripped from package import paths")
+	genCmd.Flags().String("header", "", "File whose content is written as a comment at the top of each generated file (e.g. a license)")
+	genCmd.Flags().Bool("mock", false, "Also generate mock implementations of agents and tools interfaces")
+	genCmd.Flags().Bool("http", false, "Also generate HTTP handlers, and their OpenAPI description, exposing the actions of each agent")
+	genCmd.Flags().Bool("mcp", false, "Also generate MCP servers exposing the actions and tools of each agent")
+	genCmd.Flags().String("schemas-out", "", "Also write a JSON Schema file for each message to this directory")
+	genCmd.Flags().String("ts-out", "", "Also write a TypeScript module, with the message types and HTTP clients of the agents, to this directory")
+	genCmd.Flags().String("py-out", "", "Also write a Python module, with pydantic models of the messages and HTTP clients of the agents, to this directory")
+
+	var validateCmd = &cobra.Command{
+		Use:          "validate [files...]",
+		Short:        "Report all the errors and warnings of one or more spec YAML files",
+		Args:         cobra.MinimumNArgs(1),
+		SilenceUsage: true,
+		RunE:         runValidate,
+	}
+
+	var promptCmd = &cobra.Command{
+		Use:          "prompt <spec> <agent> <action>",
+		Short:        "Print the system message and the prompt an action would send to the model, and estimate their tokens, without calling it",
+		Args:         cobra.ExactArgs(3),
+		SilenceUsage: true,
+		RunE:         runPrompt,
+	}
+
+	promptCmd.Flags().StringP("input", "i", "", "JSON file holding the input of the action, or - for stdin (default: empty object)")
+	promptCmd.Flags().StringP("config", "c", "", "Config file, as for serve, selecting the model whose tokenizer and context window the prompt is checked against")
+
+	var testCmd = &cobra.Command{
+		Use:          "test [files...]",
+		Short:        "Check that the prompts of the actions of one or more spec YAML files match their golden files",
+		Args:         cobra.MinimumNArgs(1),
+		SilenceUsage: true,
+		RunE:         runTest,
+	}
+
+	testCmd.Flags().Bool("golden", false, "Compare the prompt of each action, rendered with the inputs of its examples, with its golden file")
+	testCmd.Flags().Bool("update", false, "Write the golden files instead of comparing them")
+	testCmd.Flags().String("golden-dir", "", "Directory of the golden files (default: testdata/golden, next to each spec)")
+
+	var docCmd = &cobra.Command{
+		Use:          "doc <spec>",
+		Short:        "Generate the documentation of the agents, tools and messages of a spec YAML file",
+		Args:         cobra.ExactArgs(1),
+		SilenceUsage: true,
+		RunE:         runDoc,
+	}
+
+	docCmd.Flags().Bool("html", false, "Generate a standalone HTML page instead of Markdown")
+	docCmd.Flags().StringP("output", "o", "", "Output file (default: stdout)")
+
+	var fmtCmd = &cobra.Command{
+		Use:          "fmt [files...]",
+		Short:        "Rewrite one or more spec YAML files in canonical form",
+		Args:         cobra.MinimumNArgs(1),
+		SilenceUsage: true,
+		RunE:         runFmt,
+	}
+
+	fmtCmd.Flags().BoolP("write", "w", false, "Write the result to the source file instead of stdout")
+	fmtCmd.Flags().BoolP("list", "l", false, "List the files whose formatting differs from the canonical one")
+
+	var diffCmd = &cobra.Command{
+		Use:          "diff <old> <new>",
+		Short:        "Report the changes between two revisions of a spec YAML file, and fail on breaking ones",
+		Args:         cobra.ExactArgs(2),
+		SilenceUsage: true,
+		RunE:         runDiff,
+	}
+
+	diffCmd.Flags().Bool("allow-breaking", false, "Report breaking changes without failing")
+
+	var importCmd = &cobra.Command{
+		Use:   "import",
+		Short: "Convert existing API contracts into spec YAML files",
+	}
+
+	var importOpenAPICmd = &cobra.Command{
+		Use:          "openapi <file>",
+		Short:        "Generate spec tools and messages from an OpenAPI 3 document",
+		Args:         cobra.ExactArgs(1),
+		SilenceUsage: true,
+		RunE:         runImportOpenAPI,
+	}
+
+	importCmd.PersistentFlags().StringP("package", "p", "api", "Package of the generated spec")
+	importCmd.PersistentFlags().StringP("output", "o", "", "Output file (default: stdout)")
+
+	var importProtoCmd = &cobra.Command{
+		Use:          "proto <files...>",
+		Short:        "Generate spec messages and enums from .proto files",
+		Args:         cobra.MinimumNArgs(1),
+		SilenceUsage: true,
+		RunE:         runImportProto,
+	}
+
+	var importGoCmd = &cobra.Command{
+		Use:          "go <dir>",
+		Short:        "Generate spec messages and enums from the exported structs of a Go package",
+		Args:         cobra.ExactArgs(1),
+		SilenceUsage: true,
+		RunE:         runImportGo,
+	}
+
+	importGoCmd.Flags().StringSlice("type", nil, "Structs to convert, together with the types they reference (default: all exported structs)")
+
+	var serveCmd = &cobra.Command{
+		Use:          "serve [files...]",
+		Short:        "Serve the agents of one or more spec YAML files over HTTP",
+		Args:         cobra.MinimumNArgs(1),
+		SilenceUsage: true,
+		RunE:         runServe,
+	}
+
+	serveCmd.Flags().StringP("config", "c", "", "Config file selecting the invoker and the tool endpoints")
+	serveCmd.Flags().String("addr", "", "Address to listen on (default \":8080\")")
+	serveCmd.Flags().Bool("mcp", false, "Also serve each agent over MCP, at /mcp/<agent>")
+	serveCmd.Flags().Bool("skip-ping", false, "Do not check the connection to the provider, and that it serves the model, at startup")
+
+	var replCmd = &cobra.Command{
+		Use:          "repl <spec>",
+		Short:        "Run the actions of an agent of a spec YAML file interactively",
+		Args:         cobra.ExactArgs(1),
+		SilenceUsage: true,
+		RunE:         runRepl,
+	}
+
+	replCmd.Flags().StringP("config", "c", "", "Config file selecting the invoker and the tool endpoints, as for serve")
+	replCmd.Flags().StringP("agent", "a", "", "Agent to run (default: asked when the spec defines several)")
+	replCmd.Flags().Bool("skip-ping", false, "Do not check the connection to the provider, and that it serves the model, at startup")
+
+	importCmd.AddCommand(importOpenAPICmd)
+	importCmd.AddCommand(importProtoCmd)
+	importCmd.AddCommand(importGoCmd)
+
 	rootCmd.AddCommand(genCmd)
+	rootCmd.AddCommand(validateCmd)
+	rootCmd.AddCommand(promptCmd)
+	rootCmd.AddCommand(testCmd)
+	rootCmd.AddCommand(docCmd)
+	rootCmd.AddCommand(fmtCmd)
+	rootCmd.AddCommand(diffCmd)
+	rootCmd.AddCommand(importCmd)
+	rootCmd.AddCommand(serveCmd)
+	rootCmd.AddCommand(replCmd)
+	// Errors are reported once, on stderr, so that build tools such as go generate show them
+	rootCmd.SilenceErrors = true
 	if err := rootCmd.Execute(); err != nil {
-		fmt.Println(err)
+		fmt.Fprintln(os.Stderr, "suricata:", err)
 		os.Exit(1)
 	}
 }
 
 func runGen(cmd *cobra.Command, args []string) error {
-	var gen gen.CodeGenerator
+	var opts gen.FileOptions
 
-	for _, specPath := range args {
-		s, err := spec.LoadSpec(specPath)
+	headerPath, err := cmd.Flags().GetString("header")
+	if err != nil {
+		return err
+	}
+
+	if headerPath != "" {
+		header, err := os.ReadFile(headerPath)
 		if err != nil {
 			return err
 		}
+		opts.Header = string(header)
+	}
 
-		code, err := gen.Generate(s)
+	if opts.Out, err = cmd.Flags().GetString("out"); err != nil {
+		return err
+	}
+	if opts.Module, err = cmd.Flags().GetString("module"); err != nil {
+		return err
+	}
+	if opts.Mock, err = cmd.Flags().GetBool("mock"); err != nil {
+		return err
+	}
+	if opts.HTTP, err = cmd.Flags().GetBool("http"); err != nil {
+		return err
+	}
+	if opts.MCP, err = cmd.Flags().GetBool("mcp"); err != nil {
+		return err
+	}
+	if opts.SchemasOut, err = cmd.Flags().GetString("schemas-out"); err != nil {
+		return err
+	}
+	if opts.TSOut, err = cmd.Flags().GetString("ts-out"); err != nil {
+		return err
+	}
+	if opts.PyOut, err = cmd.Flags().GetString("py-out"); err != nil {
+		return err
+	}
+
+	// Run by go generate, from the directory of the file holding the directive: the code
+	// belongs to the package of that file, unless the output directory is set by module.
+	if pkg := os.Getenv("GOPACKAGE"); pkg != "" && opts.Module == "" {
+		opts.Flat = true
+		opts.Package = strings.TrimSuffix(pkg, "_test")
+	}
+
+	for _, specPath := range args {
+		if _, err := gen.GenerateFile(specPath, opts); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func runValidate(cmd *cobra.Command, args []string) error {
+	var errors, warnings int
+
+	for _, specPath := range args {
+		diags, err := spec.Check(specPath)
 		if err != nil {
 			return err
 		}
 
-		path, name := splitPackage(s.Package)
-		if err := os.MkdirAll(path, 0755); err != nil {
+		for _, d := range diags {
+			fmt.Fprintln(cmd.OutOrStdout(), d)
+
+			if d.Severity == spec.SeverityError {
+				errors++
+			} else {
+				warnings++
+			}
+		}
+	}
+
+	if errors > 0 {
+		return fmt.Errorf("%d error(s), %d warning(s)", errors, warnings)
+	}
+	if warnings > 0 {
+		fmt.Fprintf(cmd.OutOrStdout(), "%d warning(s)\n", warnings)
+	}
+	return nil
+}
+
+func runPrompt(cmd *cobra.Command, args []string) error {
+	inputPath, err := cmd.Flags().GetString("input")
+	if err != nil {
+		return err
+	}
+
+	configPath, err := cmd.Flags().GetString("config")
+	if err != nil {
+		return err
+	}
+
+	input := []byte("{}")
+	switch inputPath {
+	case "":
+	case "-":
+		input, err = io.ReadAll(cmd.InOrStdin())
+	default:
+		input, err = os.ReadFile(inputPath)
+	}
+	if err != nil {
+		return err
+	}
+
+	s, err := spec.LoadSpec(args[0])
+	if err != nil {
+		return err
+	}
+
+	h, err := promptHost(s)
+	if err != nil {
+		return err
+	}
+
+	req, prompt, err := preparePrompt(h, args[1], args[2], input)
+	if err != nil {
+		return err
+	}
+
+	if _, err := fmt.Fprint(cmd.OutOrStdout(), formatPrompt(req.Instructions, prompt)); err != nil {
+		return err
+	}
+	return reportTokens(cmd.ErrOrStderr(), configPath, args[1], req, prompt)
+}
+
+// reportTokens writes to w the estimated tokens of the system message and of the prompt of req. Given
+// a config, they are counted with the tokenizer of the model of agent, together with the tokens reserved
+// for the reply, and an error is returned if they exceed its context window.
+func reportTokens(w io.Writer, configPath, agent string, req *runtime.Request, prompt string) error {
+	messages := []runtime.Message{{Role: runtime.RoleUser, Content: prompt}}
+	if configPath == "" {
+		_, err := fmt.Fprintf(w, "\n~%d tokens (~%d with the encodings of OpenAI models)\n",
+			runtime.CountPromptTokens(runtime.HeuristicTokenizer, req.Instructions, messages),
+			runtime.CountPromptTokens(runtime.TiktokenTokenizer, req.Instructions, messages))
+		return err
+	}
+
+	cfg, err := loadServeConfig(configPath)
+	if err != nil {
+		return err
+	}
+
+	// Building an invoker does not connect to its provider
+	inv, err := cfg.Runtime.Invoker(agent)
+	if err != nil {
+		return err
+	}
+
+	m := cfg.Runtime.Model(agent)
+	if req.ModelOptions.MaxTokens > 0 {
+		m.MaxTokens = req.ModelOptions.MaxTokens
+	}
+	tokens := runtime.CountPromptTokens(runtime.TokenizerOf(inv), req.Instructions, messages) + m.MaxTokens
+
+	limit := m.ContextSize
+	if cs, ok := inv.(runtime.ContextSizer); ok && limit == 0 {
+		limit = cs.ContextSize()
+	}
+	if limit <= 0 {
+		_, err := fmt.Fprintf(w, "\n~%d tokens for %s\n", tokens, m.Model)
+		return err
+	}
+
+	if _, err := fmt.Fprintf(w, "\n~%d tokens for %s, %d%% of its context window of %d\n", tokens, m.Model, tokens*100/limit, limit); err != nil {
+		return err
+	}
+	if tokens > limit {
+		return &runtime.ContextError{Tokens: tokens, Limit: limit}
+	}
+	return nil
+}
+
+// promptHost returns a host of the agents of s, able to render their prompts only.
+func promptHost(s *spec.Spec) (*host.Host, error) {
+	// Tools are never called while rendering prompts
+	tools := make(map[string]host.ToolFunc, len(s.Tools))
+	for name := range s.Tools {
+		tools[name] = nil
+	}
+	return host.New(s, nil, tools)
+}
+
+// renderPrompt returns the system message and the prompt the action would send for input.
+func renderPrompt(h *host.Host, agent, action string, input []byte) (string, error) {
+	req, prompt, err := preparePrompt(h, agent, action, input)
+	if err != nil {
+		return "", err
+	}
+	return formatPrompt(req.Instructions, prompt), nil
+}
+
+// preparePrompt returns the request of the action for input, and the prompt it would send.
+func preparePrompt(h *host.Host, agent, action string, input []byte) (*runtime.Request, string, error) {
+	req, err := h.Request(agent, action, input)
+	if err != nil {
+		return nil, "", err
+	}
+
+	prompt, err := runtime.RenderPrompt(*req)
+	if err != nil {
+		return nil, "", err
+	}
+	return req, prompt, nil
+}
+
+// formatPrompt joins the system message and the prompt, as printed by the prompt command.
+func formatPrompt(system, prompt string) string {
+	// The instructions travel as the system message, separately from the prompt
+	if system != "" {
+		return fmt.Sprintf("[SYSTEM MESSAGE]\n\n%s\n\n[USER MESSAGE]\n\n%s", system, prompt)
+	}
+	return prompt
+}
+
+func runImportOpenAPI(cmd *cobra.Command, args []string) error {
+	data, err := os.ReadFile(args[0])
+	if err != nil {
+		return err
+	}
+
+	pkg, err := cmd.Flags().GetString("package")
+	if err != nil {
+		return err
+	}
+
+	s, err := importer.FromOpenAPI(data, pkg)
+	if err != nil {
+		return err
+	}
+	return writeSpec(cmd, s)
+}
+
+func runImportProto(cmd *cobra.Command, args []string) error {
+	var sources [][]byte
+	for _, path := range args {
+		data, err := os.ReadFile(path)
+		if err != nil {
 			return err
 		}
+		sources = append(sources, data)
+	}
+
+	pkg, err := cmd.Flags().GetString("package")
+	if err != nil {
+		return err
+	}
+
+	s, err := importer.FromProto(sources, pkg)
+	if err != nil {
+		return err
+	}
+	return writeSpec(cmd, s)
+}
 
-		if err := os.WriteFile(filepath.Join(path, name)+".go", code, 0666); err != nil {
+func runImportGo(cmd *cobra.Command, args []string) error {
+	entries, err := os.ReadDir(args[0])
+	if err != nil {
+		return err
+	}
+
+	var sources [][]byte
+	for _, e := range entries {
+		if e.IsDir() || !strings.HasSuffix(e.Name(), ".go") || strings.HasSuffix(e.Name(), "_test.go") {
+			continue
+		}
+
+		data, err := os.ReadFile(filepath.Join(args[0], e.Name()))
+		if err != nil {
 			return err
 		}
+		sources = append(sources, data)
 	}
-	return nil
+	if len(sources) == 0 {
+		return fmt.Errorf("no Go files in %s", args[0])
+	}
+
+	pkg, err := cmd.Flags().GetString("package")
+	if err != nil {
+		return err
+	}
+
+	roots, err := cmd.Flags().GetStringSlice("type")
+	if err != nil {
+		return err
+	}
+
+	s, err := importer.FromGo(sources, pkg, roots...)
+	if err != nil {
+		return err
+	}
+	return writeSpec(cmd, s)
 }
 
-func splitPackage(pkg string) (string, string) {
-	parts := strings.Split(pkg, ".")
-	return filepath.Join(parts[:]...), parts[len(parts)-1]
+// writeSpec encodes s as YAML to the file given by the --output flag, or to stdout.
+func writeSpec(cmd *cobra.Command, s *spec.Spec) error {
+	output, err := cmd.Flags().GetString("output")
+	if err != nil {
+		return err
+	}
+
+	var buf bytes.Buffer
+
+	enc := yaml.NewEncoder(&buf)
+	enc.SetIndent(2)
+	if err := enc.Encode(s); err != nil {
+		return err
+	}
+	if err := enc.Close(); err != nil {
+		return err
+	}
+
+	if output == "" {
+		_, err := cmd.OutOrStdout().Write(buf.Bytes())
+		return err
+	}
+	return os.WriteFile(output, buf.Bytes(), 0666)
 }
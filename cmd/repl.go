@@ -0,0 +1,300 @@
+// Copyright (c) 2025 Suricata Contributors
+// Original Author: Stefano Scafiti
+//
+// This file is part of Suricata: Type-Safe AI Agents for Go.
+//
+// Licensed under the MIT License. You may obtain a copy of the License at
+//
+//	https://opensource.org/licenses/MIT
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package main
+
+import (
+	"bufio"
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"maps"
+	"slices"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/ostafen/suricata/pkg/host"
+	"github.com/ostafen/suricata/pkg/spec"
+	"github.com/ostafen/suricata/runtime"
+	"github.com/spf13/cobra"
+)
+
+// errQuit is returned by the readers of the REPL when the user ends the session.
+var errQuit = errors.New("quit")
+
+// repl is an interactive session running the actions of the agents of a spec.
+// Tool calls run concurrently: mu serializes their access to the terminal.
+type repl struct {
+	spec *spec.Spec
+	host *host.Host
+
+	mu  sync.Mutex
+	in  *bufio.Scanner
+	out io.Writer
+}
+
+func runRepl(cmd *cobra.Command, args []string) error {
+	configPath, err := cmd.Flags().GetString("config")
+	if err != nil {
+		return err
+	}
+
+	agentName, err := cmd.Flags().GetString("agent")
+	if err != nil {
+		return err
+	}
+
+	skipPing, err := cmd.Flags().GetBool("skip-ping")
+	if err != nil {
+		return err
+	}
+
+	cfg, err := loadServeConfig(configPath)
+	if err != nil {
+		return err
+	}
+
+	s, err := spec.LoadSpec(args[0])
+	if err != nil {
+		return err
+	}
+
+	r := &repl{
+		spec: s,
+		in:   bufio.NewScanner(cmd.InOrStdin()),
+		out:  cmd.OutOrStdout(),
+	}
+
+	// Tools without an endpoint in the config are answered by the user
+	tools := make(map[string]host.ToolFunc, len(s.Tools))
+	for name := range s.Tools {
+		if url, ok := cfg.Tools[name]; ok {
+			tools[name] = host.HTTPTool(url)
+		} else {
+			tools[name] = r.manualTool(name)
+		}
+	}
+
+	if agentName == "" {
+		if agentName, err = r.choose("agent", slices.Sorted(maps.Keys(s.Agents))); err != nil {
+			return r.end(err)
+		}
+	} else if _, ok := s.Agents[agentName]; !ok {
+		return fmt.Errorf("%w: agent %q", host.ErrNotFound, agentName)
+	}
+
+	invoker, err := newInvokerPool(cfg.Runtime, !skipPing).get(cmd.Context(), agentName)
+	if err != nil {
+		return err
+	}
+
+	opts := append(cfg.Runtime.Options(agentName), runtime.WithHooks(r.hooks()))
+	if r.host, err = host.New(s, invoker, tools, opts...); err != nil {
+		return err
+	}
+
+	fmt.Fprintf(r.out, "agent %s: enter :q or end the input to quit\n", agentName)
+	return r.end(r.loop(cmd.Context(), agentName))
+}
+
+// end turns the end of the session into a clean exit.
+func (r *repl) end(err error) error {
+	if errors.Is(err, errQuit) {
+		return nil
+	}
+	return err
+}
+
+// loop runs the actions chosen by the user, until the session ends.
+// Failed calls are reported without ending the session.
+func (r *repl) loop(ctx context.Context, agentName string) error {
+	agent := r.spec.Agents[agentName]
+	actions := slices.Sorted(maps.Keys(agent.Actions))
+
+	for {
+		actionName, err := r.choose("action", actions)
+		if err != nil {
+			return err
+		}
+
+		input, err := r.readInput(agent.Actions[actionName])
+		if err != nil {
+			return err
+		}
+
+		start := time.Now()
+		out, err := r.host.Invoke(ctx, agentName, actionName, input, nil)
+		if err != nil {
+			fmt.Fprintf(r.out, "error: %v\n\n", err)
+			continue
+		}
+
+		if text, ok := out.(string); ok {
+			fmt.Fprintln(r.out, text)
+		} else {
+			data, err := json.MarshalIndent(out, "", "  ")
+			if err != nil {
+				return err
+			}
+			fmt.Fprintln(r.out, string(data))
+		}
+		fmt.Fprintf(r.out, "(%s)\n\n", time.Since(start).Round(time.Millisecond))
+	}
+}
+
+// choose asks the user to pick one of names, by number or by name.
+// A single name is picked without asking.
+func (r *repl) choose(what string, names []string) (string, error) {
+	if len(names) == 1 {
+		return names[0], nil
+	}
+
+	for i, name := range names {
+		fmt.Fprintf(r.out, "  %d) %s\n", i+1, name)
+	}
+
+	for {
+		line, err := r.readLine(what + "> ")
+		if err != nil {
+			return "", err
+		}
+		if line == "" {
+			continue
+		}
+
+		if n, err := strconv.Atoi(line); err == nil && n >= 1 && n <= len(names) {
+			return names[n-1], nil
+		}
+		if slices.Contains(names, line) {
+			return line, nil
+		}
+		fmt.Fprintf(r.out, "no %s %q\n", what, line)
+	}
+}
+
+// readInput reads the input of action: free text for inputs made of a single string field,
+// or a JSON object, possibly spanning multiple lines, otherwise.
+func (r *repl) readInput(action spec.Actions) (json.RawMessage, error) {
+	msg := r.spec.Messages[action.Input]
+	if len(msg.Fields) == 1 && msg.Fields[0].Type == "string" && !msg.Fields[0].Repeated {
+		field := msg.Fields[0].Name
+
+		text, err := r.readLine(field + "> ")
+		if err != nil {
+			return nil, err
+		}
+		return json.Marshal(map[string]string{field: text})
+	}
+	return r.readJSON(action.Input + "> ")
+}
+
+// readJSON reads lines until they form a JSON value. An empty first line stands for {}.
+func (r *repl) readJSON(prompt string) (json.RawMessage, error) {
+	var buf bytes.Buffer
+	for {
+		line, err := r.readLine(prompt)
+		if err != nil {
+			return nil, err
+		}
+		if buf.Len() == 0 && line == "" {
+			return json.RawMessage("{}"), nil
+		}
+
+		buf.WriteString(line)
+		buf.WriteByte('\n')
+		if json.Valid(buf.Bytes()) {
+			return json.RawMessage(bytes.TrimSpace(buf.Bytes())), nil
+		}
+		prompt = strings.Repeat(" ", len(prompt)-2) + "> "
+	}
+}
+
+// readLine prints prompt and reads a line, returning errQuit at the end of the input or on ":q".
+func (r *repl) readLine(prompt string) (string, error) {
+	fmt.Fprint(r.out, prompt)
+	if !r.in.Scan() {
+		fmt.Fprintln(r.out)
+		if err := r.in.Err(); err != nil {
+			return "", err
+		}
+		return "", errQuit
+	}
+
+	line := strings.TrimSpace(r.in.Text())
+	if line == ":q" {
+		return "", errQuit
+	}
+	return line, nil
+}
+
+// manualTool returns a tool whose output is entered by the user.
+func (r *repl) manualTool(name string) host.ToolFunc {
+	return func(ctx context.Context, in json.RawMessage) (json.RawMessage, error) {
+		r.mu.Lock()
+		defer r.mu.Unlock()
+
+		fmt.Fprintf(r.out, "tool %s has no endpoint: enter its output\n", name)
+		out, err := r.readJSON(r.spec.Tools[name].Output + "> ")
+		if errors.Is(err, errQuit) {
+			return nil, fmt.Errorf("tool %q: no output", name)
+		}
+		return out, err
+	}
+}
+
+// hooks trace the tool calls of the model.
+func (r *repl) hooks() runtime.Hooks {
+	return runtime.Hooks{
+		OnToolCall: func(ctx context.Context, name string, in any) {
+			r.trace("-> %s %s", name, compactJSON(in))
+		},
+		OnToolResult: func(ctx context.Context, name string, out any, err error) {
+			if err != nil {
+				r.trace("<- %s error: %v", name, err)
+				return
+			}
+			r.trace("<- %s %s", name, compactJSON(out))
+		},
+	}
+}
+
+func (r *repl) trace(format string, args ...any) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	fmt.Fprintf(r.out, format+"\n", args...)
+}
+
+// compactJSON encodes v, which may already be JSON, on a single line.
+func compactJSON(v any) string {
+	data, ok := v.(json.RawMessage)
+	if !ok {
+		var err error
+		if data, err = json.Marshal(v); err != nil {
+			return fmt.Sprint(v)
+		}
+	}
+
+	var buf bytes.Buffer
+	if err := json.Compact(&buf, data); err != nil {
+		return string(data)
+	}
+	return buf.String()
+}
@@ -0,0 +1,203 @@
+// Copyright (c) 2025 Suricata Contributors
+// Original Author: Stefano Scafiti
+//
+// This file is part of Suricata: Type-Safe AI Agents for Go.
+//
+// Licensed under the MIT License. You may obtain a copy of the License at
+//
+//     https://opensource.org/licenses/MIT
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package main
+
+import (
+	"context"
+	"fmt"
+	"maps"
+	"net/http"
+	"os"
+	"slices"
+
+	"github.com/ostafen/suricata/pkg/host"
+	"github.com/ostafen/suricata/pkg/spec"
+	"github.com/ostafen/suricata/runtime"
+	"github.com/ostafen/suricata/runtime/config"
+	"github.com/spf13/cobra"
+	"gopkg.in/yaml.v3"
+)
+
+// serveConfig is the configuration file of the serve and repl commands: the runtime config
+// of the agents (see runtime/config), with the address of the server and the tool endpoints.
+type serveConfig struct {
+	Runtime *config.Config    `yaml:"-"`
+	Addr    string            `yaml:"addr"`
+	Tools   map[string]string `yaml:"tools"` // URL of the HTTP endpoint implementing each tool
+
+	// Invoker is the single provider of the config files written before providers.
+	Invoker *invokerConfig `yaml:"invoker"`
+}
+
+type invokerConfig struct {
+	Provider  string          `yaml:"provider"` // One of ollama, openai, openaicompat and anthropic
+	Model     string          `yaml:"model"`
+	MaxTokens int             `yaml:"max_tokens"`
+	Settings  config.Provider `yaml:",inline"`
+}
+
+func loadServeConfig(path string) (*serveConfig, error) {
+	var data []byte
+	if path != "" {
+		var err error
+		if data, err = os.ReadFile(path); err != nil {
+			return nil, err
+		}
+	}
+
+	rc, err := config.Parse(data)
+	if err != nil {
+		return nil, fmt.Errorf("%s: %w", path, err)
+	}
+
+	cfg := &serveConfig{Runtime: rc}
+	if err := yaml.Unmarshal([]byte(os.ExpandEnv(string(data))), cfg); err != nil {
+		return nil, fmt.Errorf("%s: %w", path, err)
+	}
+
+	if inv := cfg.Invoker; inv != nil {
+		name := inv.Provider
+		if name == "" {
+			name = config.Ollama
+		}
+		inv.Settings.Type = name
+
+		if rc.Providers == nil {
+			rc.Providers = make(map[string]config.Provider)
+		}
+		rc.Providers[name] = inv.Settings
+		rc.Default = config.Model{Provider: name, Model: inv.Model, MaxTokens: inv.MaxTokens}
+	}
+
+	// Environment variables take precedence over the config file
+	rc.ApplyEnv()
+
+	if cfg.Addr == "" {
+		cfg.Addr = ":8080"
+	}
+	return cfg, nil
+}
+
+// invokerPool builds the invokers of agents. Agents running the same model of the same
+// provider, with the same settings, share an invoker, whose connection is checked once, at creation.
+type invokerPool struct {
+	cfg      *config.Config
+	ping     bool
+	invokers map[config.Model]runtime.Invoker
+}
+
+func newInvokerPool(cfg *config.Config, ping bool) *invokerPool {
+	return &invokerPool{cfg: cfg, ping: ping, invokers: make(map[config.Model]runtime.Invoker)}
+}
+
+// get returns the invoker of agent, checking that its provider serves its model.
+func (p *invokerPool) get(ctx context.Context, agent string) (runtime.Invoker, error) {
+	m := p.cfg.Model(agent)
+	// Settings such as the context size are built into the invoker
+	key := m
+	if inv, ok := p.invokers[key]; ok {
+		return inv, nil
+	}
+
+	inv, err := p.cfg.Invoker(agent)
+	if err != nil {
+		return nil, err
+	}
+
+	if hc, ok := inv.(runtime.HealthChecker); ok && p.ping {
+		if err := hc.Ping(ctx); err != nil {
+			return nil, fmt.Errorf("provider %q: %w", m.Provider, err)
+		}
+	}
+	p.invokers[key] = inv
+	return inv, nil
+}
+
+func runServe(cmd *cobra.Command, args []string) error {
+	configPath, err := cmd.Flags().GetString("config")
+	if err != nil {
+		return err
+	}
+
+	withMCP, err := cmd.Flags().GetBool("mcp")
+	if err != nil {
+		return err
+	}
+
+	skipPing, err := cmd.Flags().GetBool("skip-ping")
+	if err != nil {
+		return err
+	}
+
+	cfg, err := loadServeConfig(configPath)
+	if err != nil {
+		return err
+	}
+
+	if addr, _ := cmd.Flags().GetString("addr"); addr != "" {
+		cfg.Addr = addr
+	}
+
+	invokers := newInvokerPool(cfg.Runtime, !skipPing)
+
+	tools := make(map[string]host.ToolFunc, len(cfg.Tools))
+	for name, url := range cfg.Tools {
+		tools[name] = host.HTTPTool(url)
+	}
+
+	mux := http.NewServeMux()
+	served := make(map[string]string)
+
+	for _, specPath := range args {
+		s, err := spec.LoadSpec(specPath)
+		if err != nil {
+			return err
+		}
+
+		for _, agent := range slices.Sorted(maps.Keys(s.Agents)) {
+			if other, ok := served[agent]; ok {
+				return fmt.Errorf("agent %q is defined by both %s and %s", agent, other, specPath)
+			}
+			served[agent] = specPath
+
+			invoker, err := invokers.get(cmd.Context(), agent)
+			if err != nil {
+				return err
+			}
+
+			// Each agent runs on its own host, so that it gets its own model
+			h, err := host.New(s, invoker, tools, cfg.Runtime.Options(agent)...)
+			if err != nil {
+				return fmt.Errorf("%s: %w", specPath, err)
+			}
+
+			mux.Handle("/"+agent+"/", h.Handler())
+			fmt.Fprintf(cmd.ErrOrStderr(), "serving agent %q at /%s/<action>\n", agent, agent)
+
+			if withMCP {
+				srv, err := h.MCPServer(agent)
+				if err != nil {
+					return err
+				}
+				mux.Handle("/mcp/"+agent, srv.SSEHandler())
+				fmt.Fprintf(cmd.ErrOrStderr(), "serving agent %q over MCP at /mcp/%s\n", agent, agent)
+			}
+		}
+	}
+
+	fmt.Fprintf(cmd.ErrOrStderr(), "listening on %s\n", cfg.Addr)
+	return http.ListenAndServe(cfg.Addr, mux)
+}
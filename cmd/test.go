@@ -0,0 +1,170 @@
+// Copyright (c) 2025 Suricata Contributors
+// Original Author: Stefano Scafiti
+//
+// This file is part of Suricata: Type-Safe AI Agents for Go.
+//
+// Licensed under the MIT License. You may obtain a copy of the License at
+//
+//	https://opensource.org/licenses/MIT
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package main
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"maps"
+	"os"
+	"path/filepath"
+	"slices"
+	"strings"
+
+	"github.com/ostafen/suricata/pkg/spec"
+	"github.com/spf13/cobra"
+)
+
+func runTest(cmd *cobra.Command, args []string) error {
+	golden, err := cmd.Flags().GetBool("golden")
+	if err != nil {
+		return err
+	}
+	if !golden {
+		return errors.New("no test selected: pass --golden to compare prompts with their golden files")
+	}
+
+	update, err := cmd.Flags().GetBool("update")
+	if err != nil {
+		return err
+	}
+
+	goldenDir, err := cmd.Flags().GetString("golden-dir")
+	if err != nil {
+		return err
+	}
+
+	var failures int
+	for _, specPath := range args {
+		dir := goldenDir
+		if dir == "" {
+			dir = filepath.Join(filepath.Dir(specPath), "testdata", "golden")
+		}
+
+		n, err := testGoldenPrompts(cmd, specPath, dir, update)
+		if err != nil {
+			return err
+		}
+		failures += n
+	}
+
+	if failures > 0 {
+		return fmt.Errorf("%d prompt(s) do not match their golden file", failures)
+	}
+	return nil
+}
+
+// testGoldenPrompts renders the prompts of the actions of a spec with the inputs of their examples,
+// and compares them with the golden files in dir. Actions without examples are skipped.
+// It returns the number of mismatches.
+func testGoldenPrompts(cmd *cobra.Command, specPath, dir string, update bool) (int, error) {
+	s, err := spec.LoadSpec(specPath)
+	if err != nil {
+		return 0, err
+	}
+
+	h, err := promptHost(s)
+	if err != nil {
+		return 0, err
+	}
+
+	var failures int
+	for _, agentName := range h.Agents() {
+		agent := s.Agents[agentName]
+
+		for _, actionName := range slices.Sorted(maps.Keys(agent.Actions)) {
+			inputs, err := sampleInputs(agent.Actions[actionName])
+			if err != nil {
+				return 0, fmt.Errorf("agent %q action %q: %w", agentName, actionName, err)
+			}
+			if len(inputs) == 0 {
+				fmt.Fprintf(cmd.OutOrStdout(), "skip %s/%s: no examples\n", agentName, actionName)
+				continue
+			}
+
+			for i, input := range inputs {
+				name := actionName + ".golden"
+				if len(inputs) > 1 {
+					name = fmt.Sprintf("%s.%d.golden", actionName, i+1)
+				}
+				path := filepath.Join(dir, agentName, name)
+
+				prompt, err := renderPrompt(h, agentName, actionName, input)
+				if err != nil {
+					return 0, fmt.Errorf("agent %q action %q: %w", agentName, actionName, err)
+				}
+
+				if update {
+					if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+						return 0, err
+					}
+					if err := os.WriteFile(path, []byte(prompt), 0666); err != nil {
+						return 0, err
+					}
+					fmt.Fprintf(cmd.OutOrStdout(), "updated %s\n", path)
+					continue
+				}
+
+				want, err := os.ReadFile(path)
+				switch {
+				case errors.Is(err, os.ErrNotExist):
+					fmt.Fprintf(cmd.OutOrStdout(), "FAIL %s: missing golden file (run with --update to create it)\n", path)
+					failures++
+				case err != nil:
+					return 0, err
+				case string(want) != prompt:
+					fmt.Fprintf(cmd.OutOrStdout(), "FAIL %s: %s\n", path, firstDifference(string(want), prompt))
+					failures++
+				default:
+					fmt.Fprintf(cmd.OutOrStdout(), "ok   %s\n", path)
+				}
+			}
+		}
+	}
+	return failures, nil
+}
+
+// sampleInputs returns the inputs of the examples of action, encoded as JSON.
+func sampleInputs(action spec.Actions) ([][]byte, error) {
+	inputs := make([][]byte, len(action.Examples))
+	for i, example := range action.Examples {
+		data, err := json.Marshal(example.Input)
+		if err != nil {
+			return nil, fmt.Errorf("example %d: %w", i+1, err)
+		}
+		inputs[i] = data
+	}
+	return inputs, nil
+}
+
+// firstDifference describes the first line which differs between the golden prompt and the rendered one.
+func firstDifference(want, got string) string {
+	wantLines, gotLines := strings.Split(want, "\n"), strings.Split(got, "\n")
+	for i := range max(len(wantLines), len(gotLines)) {
+		var w, g string
+		if i < len(wantLines) {
+			w = wantLines[i]
+		}
+		if i < len(gotLines) {
+			g = gotLines[i]
+		}
+		if w != g || i >= len(wantLines) || i >= len(gotLines) {
+			return fmt.Sprintf("line %d: want %q, got %q", i+1, w, g)
+		}
+	}
+	return "prompts differ"
+}
@@ -4,25 +4,21 @@ package eval
 
 import (
 	"context"
+	"encoding/json"
 	"fmt"
+
 	"github.com/ostafen/suricata/runtime"
-	"github.com/xeipuuv/gojsonschema"
 )
 
 var (
-	MathRequestSchema = gojsonschema.NewStringLoader(`{"properties":{"a":{"type":"number"},"b":{"type":"number"}},"required":["a","b"],"type":"object"}`)
-	MathReplySchema   = gojsonschema.NewStringLoader(`{"properties":{"result":{"type":"number"}},"required":["result"],"type":"object"}`)
-	EvalRequestSchema = gojsonschema.NewStringLoader(`{"properties":{"expr":{"type":"string"}},"required":["expr"],"type":"object"}`)
-	EvalReplySchema   = gojsonschema.NewStringLoader(`{"properties":{"result":{"type":"number"}},"required":["result"],"type":"object"}`)
+	EvalReplySchema   = runtime.NewSchema(`{"properties":{"result":{"type":"number"}},"required":["result"],"type":"object"}`)
+	EvalRequestSchema = runtime.NewSchema(`{"properties":{"expr":{"type":"string"}},"required":["expr"],"type":"object"}`)
+	MathReplySchema   = runtime.NewSchema(`{"properties":{"result":{"type":"number"}},"required":["result"],"type":"object"}`)
+	MathRequestSchema = runtime.NewSchema(`{"properties":{"a":{"type":"number"},"b":{"type":"number"}},"required":["a","b"],"type":"object"}`)
 )
 
 type (
-	MathRequest struct {
-		A float64 `json:"a"`
-		B float64 `json:"b"`
-	}
-
-	MathReply struct {
+	EvalReply struct {
 		Result float64 `json:"result"`
 	}
 
@@ -30,9 +26,14 @@ type (
 		Expr string `json:"expr"`
 	}
 
-	EvalReply struct {
+	MathReply struct {
 		Result float64 `json:"result"`
 	}
+
+	MathRequest struct {
+		A float64 `json:"a"`
+		B float64 `json:"b"`
+	}
 )
 
 type MathAgentTools interface {
@@ -49,16 +50,27 @@ Break it down into steps and use the appropriate tool for each operation.
 Return the final numeric result.
 `
 
-type MathAgent struct {
+// MathAgent lists the actions of the agent. It is implemented by MathAgentClient.
+type MathAgent interface {
+	// Evaluate: Evaluate a math expression step by step
+	Evaluate(ctx context.Context, in *EvalRequest) (*EvalReply, error)
+}
+
+// MathAgentClient implements MathAgent by running its actions on a runtime.Runtime.
+type MathAgentClient struct {
 	runtime *runtime.Runtime
 	tools   MathAgentTools
 }
 
-func NewMathAgent(invoker runtime.Invoker, tools MathAgentTools) *MathAgent {
-	return &MathAgent{runtime: runtime.NewRuntime(invoker), tools: tools}
+// NewMathAgent returns a client of the agent. The options configure the runtime running its actions,
+// e.g. runtime.WithHooks, runtime.WithMemory, runtime.WithRetryPolicy, runtime.WithPromptOverride or runtime.WithTracer.
+func NewMathAgent(invoker runtime.Invoker, tools MathAgentTools, opts ...runtime.Option) *MathAgentClient {
+	return &MathAgentClient{runtime: runtime.NewRuntime(invoker, opts...), tools: tools}
 }
 
-func (a *MathAgent) unmarshaller(method string, data []byte) (any, error) {
+var _ MathAgent = (*MathAgentClient)(nil)
+
+func (a *MathAgentClient) unmarshaller(method string, data []byte) (any, error) {
 	switch method {
 	case "AddTool":
 		var payload MathRequest
@@ -81,7 +93,7 @@ func (a *MathAgent) unmarshaller(method string, data []byte) (any, error) {
 	return nil, fmt.Errorf("no such tool: \"%s\"", method)
 }
 
-func (a *MathAgent) toolsInvoker(ctx context.Context, name string, in any) (any, error) {
+func (a *MathAgentClient) toolsInvoker(ctx context.Context, name string, in any) (any, error) {
 	switch name {
 	case "AddTool":
 		return a.tools.AddTool(ctx, in.(*MathRequest))
@@ -96,7 +108,7 @@ func (a *MathAgent) toolsInvoker(ctx context.Context, name string, in any) (any,
 	return nil, fmt.Errorf("no such tool: \"%s\"", name)
 }
 
-func (c *MathAgent) Evaluate(ctx context.Context, in *EvalRequest) (*EvalReply, error) {
+func (c *MathAgentClient) Evaluate(ctx context.Context, in *EvalRequest) (*EvalReply, error) {
 	prompt := `{{- /* Decide the operation sequence and tool calls */ -}}
 Evaluate the expression: {{ .Expr }}
 `
@@ -104,6 +116,7 @@ Evaluate the expression: {{ .Expr }}
 	// Invoke LLM runtime
 	out := EvalReply{}
 	err := c.runtime.Invoke(ctx, runtime.Request{
+		Action:           "Evaluate",
 		SkipInput:        false,
 		Instructions:     MathAgentInstructions,
 		PromptTemplate:   prompt,
@@ -121,3 +134,22 @@ Evaluate the expression: {{ .Expr }}
 
 	return &out, nil
 }
+
+// Routes returns the actions of the agent, so that a runtime.Supervisor can delegate requests to them.
+func (c *MathAgentClient) Routes() []runtime.Route {
+	return []runtime.Route{
+		{
+			Agent:       "MathAgent",
+			Action:      "Evaluate",
+			Description: "Evaluate a math expression step by step",
+			InputSchema: EvalRequestSchema,
+			Invoke: func(ctx context.Context, args json.RawMessage) (any, error) {
+				var in EvalRequest
+				if err := runtime.UnmarshalValidate(args, &in, EvalRequestSchema); err != nil {
+					return nil, err
+				}
+				return c.Evaluate(ctx, &in)
+			},
+		},
+	}
+}
@@ -0,0 +1,83 @@
+// Code generated by suricata-gen; DO NOT EDIT.
+
+package eval
+
+import (
+	"context"
+	"encoding/json"
+
+	"github.com/ostafen/suricata/runtime"
+	"github.com/ostafen/suricata/runtime/mcp"
+)
+
+// NewMathAgentMCPServer returns an MCP server exposing the actions and the tools of agent.
+func NewMathAgentMCPServer(agent *MathAgentClient) *mcp.Server {
+	srv := mcp.NewServer("MathAgent", "")
+
+	srv.AddTool(mcp.Tool{
+		Name:        "Evaluate",
+		Description: "Evaluate a math expression step by step",
+		InputSchema: EvalRequestSchema,
+		Handler: func(ctx context.Context, args json.RawMessage) (any, error) {
+			var in EvalRequest
+			if err := runtime.UnmarshalValidate(args, &in, EvalRequestSchema); err != nil {
+				return nil, err
+			}
+			return agent.Evaluate(ctx, &in)
+		},
+	})
+
+	srv.AddTool(mcp.Tool{
+		Name:        "tool_AddTool",
+		Description: "Add two numbers",
+		InputSchema: MathRequestSchema,
+		Handler: func(ctx context.Context, args json.RawMessage) (any, error) {
+			var in MathRequest
+			if err := runtime.UnmarshalValidate(args, &in, MathRequestSchema); err != nil {
+				return nil, err
+			}
+			return agent.tools.AddTool(ctx, &in)
+		},
+	})
+
+	srv.AddTool(mcp.Tool{
+		Name:        "tool_SubTool",
+		Description: "Subtract two numbers",
+		InputSchema: MathRequestSchema,
+		Handler: func(ctx context.Context, args json.RawMessage) (any, error) {
+			var in MathRequest
+			if err := runtime.UnmarshalValidate(args, &in, MathRequestSchema); err != nil {
+				return nil, err
+			}
+			return agent.tools.SubTool(ctx, &in)
+		},
+	})
+
+	srv.AddTool(mcp.Tool{
+		Name:        "tool_MulTool",
+		Description: "Multiply two numbers",
+		InputSchema: MathRequestSchema,
+		Handler: func(ctx context.Context, args json.RawMessage) (any, error) {
+			var in MathRequest
+			if err := runtime.UnmarshalValidate(args, &in, MathRequestSchema); err != nil {
+				return nil, err
+			}
+			return agent.tools.MulTool(ctx, &in)
+		},
+	})
+
+	srv.AddTool(mcp.Tool{
+		Name:        "tool_DivTool",
+		Description: "Divide two numbers",
+		InputSchema: MathRequestSchema,
+		Handler: func(ctx context.Context, args json.RawMessage) (any, error) {
+			var in MathRequest
+			if err := runtime.UnmarshalValidate(args, &in, MathRequestSchema); err != nil {
+				return nil, err
+			}
+			return agent.tools.DivTool(ctx, &in)
+		},
+	})
+
+	return srv
+}
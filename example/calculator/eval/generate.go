@@ -0,0 +1,3 @@
+package eval
+
+//go:generate go run ../../../cmd gen --mcp ../eval.yml
@@ -0,0 +1,3 @@
+package hello
+
+//go:generate go run ../../../cmd gen --mcp ../hello.yml
@@ -4,34 +4,35 @@ package hello
 
 import (
 	"context"
+	"encoding/json"
 	"fmt"
+
 	"github.com/ostafen/suricata/runtime"
-	"github.com/xeipuuv/gojsonschema"
 )
 
 var (
-	SayHelloAllRequestSchema  = gojsonschema.NewStringLoader(`{"properties":{"names":{"items":{"type":"string"},"type":"array"}},"required":["names"],"type":"object"}`)
-	SayHelloAllReplySchema    = gojsonschema.NewStringLoader(`{"properties":{"ok":{"type":"boolean"}},"required":["ok"],"type":"object"}`)
-	SayHelloToolRequestSchema = gojsonschema.NewStringLoader(`{"properties":{"name":{"description":"the name","type":"string"}},"required":["name"],"type":"object"}`)
-	SayHelloToolReplySchema   = gojsonschema.NewStringLoader(`{"properties":{"ok":{"type":"boolean"}},"required":["ok"],"type":"object"}`)
+	SayHelloAllReplySchema    = runtime.NewSchema(`{"properties":{"ok":{"type":"boolean"}},"required":["ok"],"type":"object"}`)
+	SayHelloAllRequestSchema  = runtime.NewSchema(`{"properties":{"names":{"items":{"type":"string"},"type":"array"}},"required":["names"],"type":"object"}`)
+	SayHelloToolReplySchema   = runtime.NewSchema(`{"properties":{"ok":{"type":"boolean"}},"required":["ok"],"type":"object"}`)
+	SayHelloToolRequestSchema = runtime.NewSchema(`{"properties":{"name":{"description":"the name","type":"string"}},"required":["name"],"type":"object"}`)
 )
 
 type (
+	SayHelloAllReply struct {
+		Ok bool `json:"ok"`
+	}
+
 	SayHelloAllRequest struct {
 		Names []string `json:"names,omitempty"`
 	}
 
-	SayHelloAllReply struct {
+	SayHelloToolReply struct {
 		Ok bool `json:"ok"`
 	}
 
 	SayHelloToolRequest struct {
 		Name string `json:"name"`
 	}
-
-	SayHelloToolReply struct {
-		Ok bool `json:"ok"`
-	}
 )
 
 type HelloAgentTools interface {
@@ -43,16 +44,27 @@ var HelloAgentToolsSpec = []runtime.ToolSpec{{Name: "SayHelloTool", Description:
 var HelloAgentInstructions = `You are a helpful and precise assistant. Your role is to say hello to people.
 `
 
-type HelloAgent struct {
+// HelloAgent lists the actions of the agent. It is implemented by HelloAgentClient.
+type HelloAgent interface {
+	// SayHelloAll: Say hello to all names given as input
+	SayHelloAll(ctx context.Context, in *SayHelloAllRequest) (*SayHelloAllReply, error)
+}
+
+// HelloAgentClient implements HelloAgent by running its actions on a runtime.Runtime.
+type HelloAgentClient struct {
 	runtime *runtime.Runtime
 	tools   HelloAgentTools
 }
 
-func NewHelloAgent(invoker runtime.Invoker, tools HelloAgentTools) *HelloAgent {
-	return &HelloAgent{runtime: runtime.NewRuntime(invoker), tools: tools}
+// NewHelloAgent returns a client of the agent. The options configure the runtime running its actions,
+// e.g. runtime.WithHooks, runtime.WithMemory, runtime.WithRetryPolicy, runtime.WithPromptOverride or runtime.WithTracer.
+func NewHelloAgent(invoker runtime.Invoker, tools HelloAgentTools, opts ...runtime.Option) *HelloAgentClient {
+	return &HelloAgentClient{runtime: runtime.NewRuntime(invoker, opts...), tools: tools}
 }
 
-func (a *HelloAgent) unmarshaller(method string, data []byte) (any, error) {
+var _ HelloAgent = (*HelloAgentClient)(nil)
+
+func (a *HelloAgentClient) unmarshaller(method string, data []byte) (any, error) {
 	switch method {
 	case "SayHelloTool":
 		var payload SayHelloToolRequest
@@ -63,7 +75,7 @@ func (a *HelloAgent) unmarshaller(method string, data []byte) (any, error) {
 	return nil, fmt.Errorf("no such tool: \"%s\"", method)
 }
 
-func (a *HelloAgent) toolsInvoker(ctx context.Context, name string, in any) (any, error) {
+func (a *HelloAgentClient) toolsInvoker(ctx context.Context, name string, in any) (any, error) {
 	switch name {
 	case "SayHelloTool":
 		return a.tools.SayHelloTool(ctx, in.(*SayHelloToolRequest))
@@ -72,7 +84,7 @@ func (a *HelloAgent) toolsInvoker(ctx context.Context, name string, in any) (any
 	return nil, fmt.Errorf("no such tool: \"%s\"", name)
 }
 
-func (c *HelloAgent) SayHelloAll(ctx context.Context, in *SayHelloAllRequest) (*SayHelloAllReply, error) {
+func (c *HelloAgentClient) SayHelloAll(ctx context.Context, in *SayHelloAllRequest) (*SayHelloAllReply, error) {
 	prompt := `{{- /* Use Go templating for dynamic prompts */ -}}
 Please say hello to all the following names:
 {{- range .Names }}
@@ -83,6 +95,7 @@ Please say hello to all the following names:
 	// Invoke LLM runtime
 	out := SayHelloAllReply{}
 	err := c.runtime.Invoke(ctx, runtime.Request{
+		Action:           "SayHelloAll",
 		SkipInput:        false,
 		Instructions:     HelloAgentInstructions,
 		PromptTemplate:   prompt,
@@ -100,3 +113,22 @@ Please say hello to all the following names:
 
 	return &out, nil
 }
+
+// Routes returns the actions of the agent, so that a runtime.Supervisor can delegate requests to them.
+func (c *HelloAgentClient) Routes() []runtime.Route {
+	return []runtime.Route{
+		{
+			Agent:       "helloAgent",
+			Action:      "SayHelloAll",
+			Description: "Say hello to all names given as input",
+			InputSchema: SayHelloAllRequestSchema,
+			Invoke: func(ctx context.Context, args json.RawMessage) (any, error) {
+				var in SayHelloAllRequest
+				if err := runtime.UnmarshalValidate(args, &in, SayHelloAllRequestSchema); err != nil {
+					return nil, err
+				}
+				return c.SayHelloAll(ctx, &in)
+			},
+		},
+	}
+}
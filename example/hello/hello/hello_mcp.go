@@ -0,0 +1,44 @@
+// Code generated by suricata-gen; DO NOT EDIT.
+
+package hello
+
+import (
+	"context"
+	"encoding/json"
+
+	"github.com/ostafen/suricata/runtime"
+	"github.com/ostafen/suricata/runtime/mcp"
+)
+
+// NewHelloAgentMCPServer returns an MCP server exposing the actions and the tools of agent.
+func NewHelloAgentMCPServer(agent *HelloAgentClient) *mcp.Server {
+	srv := mcp.NewServer("helloAgent", "")
+
+	srv.AddTool(mcp.Tool{
+		Name:        "SayHelloAll",
+		Description: "Say hello to all names given as input",
+		InputSchema: SayHelloAllRequestSchema,
+		Handler: func(ctx context.Context, args json.RawMessage) (any, error) {
+			var in SayHelloAllRequest
+			if err := runtime.UnmarshalValidate(args, &in, SayHelloAllRequestSchema); err != nil {
+				return nil, err
+			}
+			return agent.SayHelloAll(ctx, &in)
+		},
+	})
+
+	srv.AddTool(mcp.Tool{
+		Name:        "tool_SayHelloTool",
+		Description: "say hello to a given name",
+		InputSchema: SayHelloToolRequestSchema,
+		Handler: func(ctx context.Context, args json.RawMessage) (any, error) {
+			var in SayHelloToolRequest
+			if err := runtime.UnmarshalValidate(args, &in, SayHelloToolRequestSchema); err != nil {
+				return nil, err
+			}
+			return agent.tools.SayHelloTool(ctx, &in)
+		},
+	})
+
+	return srv
+}
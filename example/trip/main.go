@@ -5,6 +5,7 @@ import (
 	"fmt"
 
 	"github.com/ostafen/suricata/example/trip/travel"
+	"github.com/ostafen/suricata/runtime"
 	"github.com/ostafen/suricata/runtime/ollama"
 )
 
@@ -60,17 +61,17 @@ func (tools *flightTools) FindFlights(ctx context.Context, in *travel.FlightRequ
 		Flights: []travel.Flight{
 			{
 				Id:        "1",
-				Cost:      100,
+				Cost:      runtime.MustParseDecimal("100.00"),
 				RoundTrip: true,
 			},
 			{
 				Id:        "2",
-				Cost:      50,
+				Cost:      runtime.MustParseDecimal("50.00"),
 				RoundTrip: false,
 			},
 			{
 				Id:        "3",
-				Cost:      80,
+				Cost:      runtime.MustParseDecimal("80.00"),
 				RoundTrip: true,
 			},
 		},
@@ -0,0 +1,3 @@
+package travel
+
+//go:generate go run ../../../cmd gen --mcp ../trip.yml
@@ -4,57 +4,48 @@ package travel
 
 import (
 	"context"
+	"encoding/json"
 	"fmt"
+
 	"github.com/ostafen/suricata/runtime"
-	"github.com/xeipuuv/gojsonschema"
 )
 
 var (
-	FlightSchema            = gojsonschema.NewStringLoader(`{"properties":{"cost":{"type":"number"},"id":{"type":"string"},"round_trip":{"type":"boolean"}},"required":["id","cost","round_trip"],"type":"object"}`)
-	BookFlightRequestSchema = gojsonschema.NewStringLoader(`{"properties":{"id":{"type":"integer"}},"required":["id"],"type":"object"}`)
-	BookHotelRequestSchema  = gojsonschema.NewStringLoader(`{"properties":{"checkin_date":{"type":"string"},"checkout_date":{"type":"string"},"name":{"type":"string"},"rooms":{"type":"integer"}},"required":["name","checkin_date","checkout_date","rooms"],"type":"object"}`)
-	FlightReplySchema       = gojsonschema.NewStringLoader(`{"properties":{"flights":{"items":{"properties":{"cost":{"type":"number"},"id":{"type":"string"},"round_trip":{"type":"boolean"}},"required":["id","cost","round_trip"],"type":"object"},"type":"array"}},"required":["flights"],"type":"object"}`)
-	BookFlightReplySchema   = gojsonschema.NewStringLoader(`{"properties":{"booked":{"type":"boolean"}},"required":["booked"],"type":"object"}`)
-	ItineraryRequestSchema  = gojsonschema.NewStringLoader(`{"properties":{"request":{"type":"string"}},"required":["request"],"type":"object"}`)
-	LocationSchema          = gojsonschema.NewStringLoader(`{"properties":{"city":{"type":"string"},"country":{"type":"string"}},"required":["country","city"],"type":"object"}`)
-	HotelSchema             = gojsonschema.NewStringLoader(`{"properties":{"name":{"type":"string"}},"required":["name"],"type":"object"}`)
-	HotelReplySchema        = gojsonschema.NewStringLoader(`{"properties":{"booked":{"type":"boolean"}},"required":["booked"],"type":"object"}`)
-	BookHotelReplySchema    = gojsonschema.NewStringLoader(`{"properties":{"booked":{"type":"boolean"}},"required":["booked"],"type":"object"}`)
-	FindHotelReplySchema    = gojsonschema.NewStringLoader(`{"properties":{"hotels":{"items":{"properties":{"name":{"type":"string"}},"required":["name"],"type":"object"},"type":"array"}},"required":["hotels"],"type":"object"}`)
-	FlightRequestSchema     = gojsonschema.NewStringLoader(`{"properties":{"date":{"type":"string"},"from":{"properties":{"city":{"type":"string"},"country":{"type":"string"}},"required":["country","city"],"type":"object"},"round_trip":{"type":"boolean"},"to":{"properties":{"city":{"type":"string"},"country":{"type":"string"}},"required":["country","city"],"type":"object"}},"required":["from","to","date","round_trip"],"type":"object"}`)
-	HotelRequestSchema      = gojsonschema.NewStringLoader(`{"properties":{"checkin_date":{"type":"string"},"checkout_date":{"type":"string"},"location":{"properties":{"city":{"type":"string"},"country":{"type":"string"}},"required":["country","city"],"type":"object"}},"required":["location","checkin_date","checkout_date"],"type":"object"}`)
-	ItineraryReplySchema    = gojsonschema.NewStringLoader(`{"properties":{"end_date":{"type":"string"},"from":{"properties":{"city":{"type":"string"},"country":{"type":"string"}},"required":["country","city"],"type":"object"},"start_date":{"type":"string"},"to":{"properties":{"city":{"type":"string"},"country":{"type":"string"}},"required":["country","city"],"type":"object"}},"required":["from","to","start_date","end_date"],"type":"object"}`)
-	FindHotelRequestSchema  = gojsonschema.NewStringLoader(`{"properties":{"checkin_date":{"type":"string"},"checkout_date":{"type":"string"},"location":{"properties":{"city":{"type":"string"},"country":{"type":"string"}},"required":["country","city"],"type":"object"}},"required":["location","checkin_date","checkout_date"],"type":"object"}`)
+	BookFlightReplySchema   = runtime.NewSchema(`{"properties":{"booked":{"type":"boolean"}},"required":["booked"],"type":"object"}`)
+	BookFlightRequestSchema = runtime.NewSchema(`{"properties":{"id":{"type":"integer"}},"required":["id"],"type":"object"}`)
+	BookHotelReplySchema    = runtime.NewSchema(`{"properties":{"booked":{"type":"boolean"}},"required":["booked"],"type":"object"}`)
+	BookHotelRequestSchema  = runtime.NewSchema(`{"properties":{"checkin_date":{"type":"string"},"checkout_date":{"type":"string"},"name":{"type":"string"},"rooms":{"type":"integer"}},"required":["name","checkin_date","checkout_date","rooms"],"type":"object"}`)
+	FindHotelReplySchema    = runtime.NewSchema(`{"properties":{"hotels":{"items":{"properties":{"name":{"type":"string"}},"required":["name"],"type":"object"},"type":"array"}},"required":["hotels"],"type":"object"}`)
+	FindHotelRequestSchema  = runtime.NewSchema(`{"properties":{"checkin_date":{"type":"string"},"checkout_date":{"type":"string"},"location":{"properties":{"city":{"type":"string"},"country":{"type":"string"}},"required":["country","city"],"type":"object"}},"required":["location","checkin_date","checkout_date"],"type":"object"}`)
+	FlightSchema            = runtime.NewSchema(`{"properties":{"cost":{"format":"decimal","pattern":"^-?[0-9]+(\\.[0-9]+)?$","type":"string"},"id":{"type":"string"},"round_trip":{"type":"boolean"}},"required":["id","cost","round_trip"],"type":"object"}`)
+	FlightReplySchema       = runtime.NewSchema(`{"properties":{"flights":{"items":{"properties":{"cost":{"format":"decimal","pattern":"^-?[0-9]+(\\.[0-9]+)?$","type":"string"},"id":{"type":"string"},"round_trip":{"type":"boolean"}},"required":["id","cost","round_trip"],"type":"object"},"type":"array"}},"required":["flights"],"type":"object"}`)
+	FlightRequestSchema     = runtime.NewSchema(`{"$defs":{"Location":{"properties":{"city":{"type":"string"},"country":{"type":"string"}},"required":["country","city"],"type":"object"}},"properties":{"date":{"type":"string"},"from":{"$ref":"#/$defs/Location"},"round_trip":{"type":"boolean"},"to":{"$ref":"#/$defs/Location"}},"required":["from","to","date","round_trip"],"type":"object"}`)
+	HotelSchema             = runtime.NewSchema(`{"properties":{"name":{"type":"string"}},"required":["name"],"type":"object"}`)
+	HotelReplySchema        = runtime.NewSchema(`{"properties":{"booked":{"type":"boolean"}},"required":["booked"],"type":"object"}`)
+	HotelRequestSchema      = runtime.NewSchema(`{"properties":{"checkin_date":{"type":"string"},"checkout_date":{"type":"string"},"location":{"properties":{"city":{"type":"string"},"country":{"type":"string"}},"required":["country","city"],"type":"object"}},"required":["location","checkin_date","checkout_date"],"type":"object"}`)
+	ItineraryReplySchema    = runtime.NewSchema(`{"$defs":{"Location":{"properties":{"city":{"type":"string"},"country":{"type":"string"}},"required":["country","city"],"type":"object"}},"properties":{"end_date":{"type":"string"},"from":{"$ref":"#/$defs/Location"},"start_date":{"type":"string"},"to":{"$ref":"#/$defs/Location"}},"required":["from","to","start_date","end_date"],"type":"object"}`)
+	ItineraryRequestSchema  = runtime.NewSchema(`{"properties":{"request":{"type":"string"}},"required":["request"],"type":"object"}`)
+	LocationSchema          = runtime.NewSchema(`{"properties":{"city":{"type":"string"},"country":{"type":"string"}},"required":["country","city"],"type":"object"}`)
 )
 
 type (
-	Location struct {
-		Country string `json:"country"`
-		City    string `json:"city"`
-	}
-
-	Hotel struct {
-		Name string `json:"name"`
-	}
-
-	FlightReply struct {
-		Flights []Flight `json:"flights,omitempty"`
-	}
-
 	BookFlightReply struct {
 		Booked bool `json:"booked"`
 	}
 
-	ItineraryRequest struct {
-		Request string `json:"request"`
+	BookFlightRequest struct {
+		Id int `json:"id"`
 	}
 
-	HotelReply struct {
+	BookHotelReply struct {
 		Booked bool `json:"booked"`
 	}
 
-	BookHotelReply struct {
-		Booked bool `json:"booked"`
+	BookHotelRequest struct {
+		Name         string `json:"name"`
+		CheckinDate  string `json:"checkin_date"`
+		CheckoutDate string `json:"checkout_date"`
+		Rooms        int    `json:"rooms"`
 	}
 
 	FindHotelReply struct {
@@ -67,6 +58,16 @@ type (
 		CheckoutDate string   `json:"checkout_date"`
 	}
 
+	Flight struct {
+		Id        string          `json:"id"`
+		Cost      runtime.Decimal `json:"cost"`
+		RoundTrip bool            `json:"round_trip"`
+	}
+
+	FlightReply struct {
+		Flights []Flight `json:"flights,omitempty"`
+	}
+
 	FlightRequest struct {
 		From      Location `json:"from"`
 		To        Location `json:"to"`
@@ -74,6 +75,14 @@ type (
 		RoundTrip bool     `json:"round_trip"`
 	}
 
+	Hotel struct {
+		Name string `json:"name"`
+	}
+
+	HotelReply struct {
+		Booked bool `json:"booked"`
+	}
+
 	HotelRequest struct {
 		Location     Location `json:"location"`
 		CheckinDate  string   `json:"checkin_date"`
@@ -87,24 +96,121 @@ type (
 		EndDate   string   `json:"end_date"`
 	}
 
-	Flight struct {
-		Id        string  `json:"id"`
-		Cost      float64 `json:"cost"`
-		RoundTrip bool    `json:"round_trip"`
+	ItineraryRequest struct {
+		Request string `json:"request"`
 	}
 
-	BookFlightRequest struct {
-		Id int `json:"id"`
+	Location struct {
+		Country string `json:"country"`
+		City    string `json:"city"`
 	}
+)
 
-	BookHotelRequest struct {
-		Name         string `json:"name"`
-		CheckinDate  string `json:"checkin_date"`
-		CheckoutDate string `json:"checkout_date"`
-		Rooms        int    `json:"rooms"`
-	}
+// ItineraryReplyRules checks the rules of ItineraryReply on the outputs of actions.
+var ItineraryReplyRules = runtime.CheckRules(
+	runtime.MustParseRule("end_date >= start_date"),
 )
 
+type FlightAgentTools interface {
+	FindFlights(ctx context.Context, in *FlightRequest) (*FlightReply, error)
+	BookFlight(ctx context.Context, in *BookFlightRequest) (*BookFlightReply, error)
+}
+
+var FlightAgentToolsSpec = []runtime.ToolSpec{{Name: "FindFlights", Description: "Find flights between two cities", Schema: FlightRequestSchema}, {Name: "BookFlight", Description: "Book a flight for a given date", Schema: BookFlightRequestSchema}}
+
+var FlightAgentInstructions = `You are a flight planning assistant. Your role is to find the most suitable flight option.
+`
+
+// FlightAgent lists the actions of the agent. It is implemented by FlightAgentClient.
+type FlightAgent interface {
+	// SearchFlights: Search flights for a given route and date and book the cheapest
+	SearchFlights(ctx context.Context, in *FlightRequest) (*FlightReply, error)
+}
+
+// FlightAgentClient implements FlightAgent by running its actions on a runtime.Runtime.
+type FlightAgentClient struct {
+	runtime *runtime.Runtime
+	tools   FlightAgentTools
+}
+
+// NewFlightAgent returns a client of the agent. The options configure the runtime running its actions,
+// e.g. runtime.WithHooks, runtime.WithMemory, runtime.WithRetryPolicy, runtime.WithPromptOverride or runtime.WithTracer.
+func NewFlightAgent(invoker runtime.Invoker, tools FlightAgentTools, opts ...runtime.Option) *FlightAgentClient {
+	return &FlightAgentClient{runtime: runtime.NewRuntime(invoker, opts...), tools: tools}
+}
+
+var _ FlightAgent = (*FlightAgentClient)(nil)
+
+func (a *FlightAgentClient) unmarshaller(method string, data []byte) (any, error) {
+	switch method {
+	case "FindFlights":
+		var payload FlightRequest
+		err := runtime.UnmarshalValidate(data, &payload, FlightRequestSchema)
+		return &payload, err
+	case "BookFlight":
+		var payload BookFlightRequest
+		err := runtime.UnmarshalValidate(data, &payload, BookFlightRequestSchema)
+		return &payload, err
+	}
+
+	return nil, fmt.Errorf("no such tool: \"%s\"", method)
+}
+
+func (a *FlightAgentClient) toolsInvoker(ctx context.Context, name string, in any) (any, error) {
+	switch name {
+	case "FindFlights":
+		return a.tools.FindFlights(ctx, in.(*FlightRequest))
+	case "BookFlight":
+		return a.tools.BookFlight(ctx, in.(*BookFlightRequest))
+	}
+
+	return nil, fmt.Errorf("no such tool: \"%s\"", name)
+}
+
+func (c *FlightAgentClient) SearchFlights(ctx context.Context, in *FlightRequest) (*FlightReply, error) {
+	prompt := ``
+
+	// Invoke LLM runtime
+	out := FlightReply{}
+	err := c.runtime.Invoke(ctx, runtime.Request{
+		Action:           "SearchFlights",
+		SkipInput:        false,
+		Instructions:     FlightAgentInstructions,
+		PromptTemplate:   prompt,
+		Input:            in,
+		Output:           &out,
+		InputSchema:      FlightRequestSchema,
+		OutputSchema:     FlightReplySchema,
+		ToolUnmarshaller: c.unmarshaller,
+		ToolInvoker:      c.toolsInvoker,
+		ToolSpecs:        FlightAgentToolsSpec,
+	})
+	if err != nil {
+		return nil, fmt.Errorf("llm call failed: %w", err)
+	}
+
+	return &out, nil
+}
+
+// Routes returns the actions of the agent, so that a runtime.Supervisor can delegate requests to them.
+func (c *FlightAgentClient) Routes() []runtime.Route {
+	return []runtime.Route{
+		{
+			Agent:       "FlightAgent",
+			Action:      "SearchFlights",
+			Description: "Search flights for a given route and date and book the cheapest",
+			InputSchema: FlightRequestSchema,
+			Invoke: func(ctx context.Context, args json.RawMessage) (any, error) {
+				var in FlightRequest
+				if err := runtime.UnmarshalValidate(args, &in, FlightRequestSchema); err != nil {
+					return nil, err
+				}
+				return c.SearchFlights(ctx, &in)
+			},
+		},
+	}
+}
+
 type HotelAgentTools interface {
 	FindHotels(ctx context.Context, in *FindHotelRequest) (*FindHotelReply, error)
 	BookHotel(ctx context.Context, in *BookHotelRequest) (*BookHotelReply, error)
@@ -115,16 +221,27 @@ var HotelAgentToolsSpec = []runtime.ToolSpec{{Name: "FindHotels", Description: "
 var HotelAgentInstructions = `You are a hotel planning assistant. Your role is to provide hotel options.
 `
 
-type HotelAgent struct {
+// HotelAgent lists the actions of the agent. It is implemented by HotelAgentClient.
+type HotelAgent interface {
+	// BookHotel: Book an hotel for a given city and date range
+	BookHotel(ctx context.Context, in *HotelRequest) (*HotelReply, error)
+}
+
+// HotelAgentClient implements HotelAgent by running its actions on a runtime.Runtime.
+type HotelAgentClient struct {
 	runtime *runtime.Runtime
 	tools   HotelAgentTools
 }
 
-func NewHotelAgent(invoker runtime.Invoker, tools HotelAgentTools) *HotelAgent {
-	return &HotelAgent{runtime: runtime.NewRuntime(invoker), tools: tools}
+// NewHotelAgent returns a client of the agent. The options configure the runtime running its actions,
+// e.g. runtime.WithHooks, runtime.WithMemory, runtime.WithRetryPolicy, runtime.WithPromptOverride or runtime.WithTracer.
+func NewHotelAgent(invoker runtime.Invoker, tools HotelAgentTools, opts ...runtime.Option) *HotelAgentClient {
+	return &HotelAgentClient{runtime: runtime.NewRuntime(invoker, opts...), tools: tools}
 }
 
-func (a *HotelAgent) unmarshaller(method string, data []byte) (any, error) {
+var _ HotelAgent = (*HotelAgentClient)(nil)
+
+func (a *HotelAgentClient) unmarshaller(method string, data []byte) (any, error) {
 	switch method {
 	case "FindHotels":
 		var payload FindHotelRequest
@@ -139,7 +256,7 @@ func (a *HotelAgent) unmarshaller(method string, data []byte) (any, error) {
 	return nil, fmt.Errorf("no such tool: \"%s\"", method)
 }
 
-func (a *HotelAgent) toolsInvoker(ctx context.Context, name string, in any) (any, error) {
+func (a *HotelAgentClient) toolsInvoker(ctx context.Context, name string, in any) (any, error) {
 	switch name {
 	case "FindHotels":
 		return a.tools.FindHotels(ctx, in.(*FindHotelRequest))
@@ -150,12 +267,13 @@ func (a *HotelAgent) toolsInvoker(ctx context.Context, name string, in any) (any
 	return nil, fmt.Errorf("no such tool: \"%s\"", name)
 }
 
-func (c *HotelAgent) BookHotel(ctx context.Context, in *HotelRequest) (*HotelReply, error) {
+func (c *HotelAgentClient) BookHotel(ctx context.Context, in *HotelRequest) (*HotelReply, error) {
 	prompt := ``
 
 	// Invoke LLM runtime
 	out := HotelReply{}
 	err := c.runtime.Invoke(ctx, runtime.Request{
+		Action:           "BookHotel",
 		SkipInput:        false,
 		Instructions:     HotelAgentInstructions,
 		PromptTemplate:   prompt,
@@ -174,23 +292,54 @@ func (c *HotelAgent) BookHotel(ctx context.Context, in *HotelRequest) (*HotelRep
 	return &out, nil
 }
 
+// Routes returns the actions of the agent, so that a runtime.Supervisor can delegate requests to them.
+func (c *HotelAgentClient) Routes() []runtime.Route {
+	return []runtime.Route{
+		{
+			Agent:       "HotelAgent",
+			Action:      "BookHotel",
+			Description: "Book an hotel for a given city and date range",
+			InputSchema: HotelRequestSchema,
+			Invoke: func(ctx context.Context, args json.RawMessage) (any, error) {
+				var in HotelRequest
+				if err := runtime.UnmarshalValidate(args, &in, HotelRequestSchema); err != nil {
+					return nil, err
+				}
+				return c.BookHotel(ctx, &in)
+			},
+		},
+	}
+}
+
 var ItineraryAgentInstructions = `You are an itinerary planner. Combine flight and hotel results into a suggested itinerary.
 `
 
-type ItineraryAgent struct {
+// ItineraryAgent lists the actions of the agent. It is implemented by ItineraryAgentClient.
+type ItineraryAgent interface {
+	// ExtractInfo: Extract itinerary data into the output
+	ExtractInfo(ctx context.Context, in *ItineraryRequest) (*ItineraryReply, error)
+}
+
+// ItineraryAgentClient implements ItineraryAgent by running its actions on a runtime.Runtime.
+type ItineraryAgentClient struct {
 	runtime *runtime.Runtime
 }
 
-func NewItineraryAgent(invoker runtime.Invoker) *ItineraryAgent {
-	return &ItineraryAgent{runtime: runtime.NewRuntime(invoker)}
+// NewItineraryAgent returns a client of the agent. The options configure the runtime running its actions,
+// e.g. runtime.WithHooks, runtime.WithMemory, runtime.WithRetryPolicy, runtime.WithPromptOverride or runtime.WithTracer.
+func NewItineraryAgent(invoker runtime.Invoker, opts ...runtime.Option) *ItineraryAgentClient {
+	return &ItineraryAgentClient{runtime: runtime.NewRuntime(invoker, opts...)}
 }
 
-func (c *ItineraryAgent) ExtractInfo(ctx context.Context, in *ItineraryRequest) (*ItineraryReply, error) {
+var _ ItineraryAgent = (*ItineraryAgentClient)(nil)
+
+func (c *ItineraryAgentClient) ExtractInfo(ctx context.Context, in *ItineraryRequest) (*ItineraryReply, error) {
 	prompt := ``
 
 	// Invoke LLM runtime
 	out := ItineraryReply{}
 	err := c.runtime.Invoke(ctx, runtime.Request{
+		Action:         "ExtractInfo",
 		SkipInput:      false,
 		Instructions:   ItineraryAgentInstructions,
 		PromptTemplate: prompt,
@@ -198,6 +347,7 @@ func (c *ItineraryAgent) ExtractInfo(ctx context.Context, in *ItineraryRequest)
 		Output:         &out,
 		InputSchema:    ItineraryRequestSchema,
 		OutputSchema:   ItineraryReplySchema,
+		PostProcessors: []runtime.PostProcessor{ItineraryReplyRules},
 	})
 	if err != nil {
 		return nil, fmt.Errorf("llm call failed: %w", err)
@@ -206,71 +356,21 @@ func (c *ItineraryAgent) ExtractInfo(ctx context.Context, in *ItineraryRequest)
 	return &out, nil
 }
 
-type FlightAgentTools interface {
-	FindFlights(ctx context.Context, in *FlightRequest) (*FlightReply, error)
-	BookFlight(ctx context.Context, in *BookFlightRequest) (*BookFlightReply, error)
-}
-
-var FlightAgentToolsSpec = []runtime.ToolSpec{{Name: "FindFlights", Description: "Find flights between two cities", Schema: FlightRequestSchema}, {Name: "BookFlight", Description: "Book a flight for a given date", Schema: BookFlightRequestSchema}}
-
-var FlightAgentInstructions = `You are a flight planning assistant. Your role is to find the most suitable flight option.
-`
-
-type FlightAgent struct {
-	runtime *runtime.Runtime
-	tools   FlightAgentTools
-}
-
-func NewFlightAgent(invoker runtime.Invoker, tools FlightAgentTools) *FlightAgent {
-	return &FlightAgent{runtime: runtime.NewRuntime(invoker), tools: tools}
-}
-
-func (a *FlightAgent) unmarshaller(method string, data []byte) (any, error) {
-	switch method {
-	case "FindFlights":
-		var payload FlightRequest
-		err := runtime.UnmarshalValidate(data, &payload, FlightRequestSchema)
-		return &payload, err
-	case "BookFlight":
-		var payload BookFlightRequest
-		err := runtime.UnmarshalValidate(data, &payload, BookFlightRequestSchema)
-		return &payload, err
-	}
-
-	return nil, fmt.Errorf("no such tool: \"%s\"", method)
-}
-
-func (a *FlightAgent) toolsInvoker(ctx context.Context, name string, in any) (any, error) {
-	switch name {
-	case "FindFlights":
-		return a.tools.FindFlights(ctx, in.(*FlightRequest))
-	case "BookFlight":
-		return a.tools.BookFlight(ctx, in.(*BookFlightRequest))
-	}
-
-	return nil, fmt.Errorf("no such tool: \"%s\"", name)
-}
-
-func (c *FlightAgent) SearchFlights(ctx context.Context, in *FlightRequest) (*FlightReply, error) {
-	prompt := ``
-
-	// Invoke LLM runtime
-	out := FlightReply{}
-	err := c.runtime.Invoke(ctx, runtime.Request{
-		SkipInput:        false,
-		Instructions:     FlightAgentInstructions,
-		PromptTemplate:   prompt,
-		Input:            in,
-		Output:           &out,
-		InputSchema:      FlightRequestSchema,
-		OutputSchema:     FlightReplySchema,
-		ToolUnmarshaller: c.unmarshaller,
-		ToolInvoker:      c.toolsInvoker,
-		ToolSpecs:        FlightAgentToolsSpec,
-	})
-	if err != nil {
-		return nil, fmt.Errorf("llm call failed: %w", err)
+// Routes returns the actions of the agent, so that a runtime.Supervisor can delegate requests to them.
+func (c *ItineraryAgentClient) Routes() []runtime.Route {
+	return []runtime.Route{
+		{
+			Agent:       "ItineraryAgent",
+			Action:      "ExtractInfo",
+			Description: "Extract itinerary data into the output",
+			InputSchema: ItineraryRequestSchema,
+			Invoke: func(ctx context.Context, args json.RawMessage) (any, error) {
+				var in ItineraryRequest
+				if err := runtime.UnmarshalValidate(args, &in, ItineraryRequestSchema); err != nil {
+					return nil, err
+				}
+				return c.ExtractInfo(ctx, &in)
+			},
+		},
 	}
-
-	return &out, nil
 }
@@ -0,0 +1,123 @@
+// Code generated by suricata-gen; DO NOT EDIT.
+
+package travel
+
+import (
+	"context"
+	"encoding/json"
+
+	"github.com/ostafen/suricata/runtime"
+	"github.com/ostafen/suricata/runtime/mcp"
+)
+
+// NewFlightAgentMCPServer returns an MCP server exposing the actions and the tools of agent.
+func NewFlightAgentMCPServer(agent *FlightAgentClient) *mcp.Server {
+	srv := mcp.NewServer("FlightAgent", "")
+
+	srv.AddTool(mcp.Tool{
+		Name:        "SearchFlights",
+		Description: "Search flights for a given route and date and book the cheapest",
+		InputSchema: FlightRequestSchema,
+		Handler: func(ctx context.Context, args json.RawMessage) (any, error) {
+			var in FlightRequest
+			if err := runtime.UnmarshalValidate(args, &in, FlightRequestSchema); err != nil {
+				return nil, err
+			}
+			return agent.SearchFlights(ctx, &in)
+		},
+	})
+
+	srv.AddTool(mcp.Tool{
+		Name:        "tool_FindFlights",
+		Description: "Find flights between two cities",
+		InputSchema: FlightRequestSchema,
+		Handler: func(ctx context.Context, args json.RawMessage) (any, error) {
+			var in FlightRequest
+			if err := runtime.UnmarshalValidate(args, &in, FlightRequestSchema); err != nil {
+				return nil, err
+			}
+			return agent.tools.FindFlights(ctx, &in)
+		},
+	})
+
+	srv.AddTool(mcp.Tool{
+		Name:        "tool_BookFlight",
+		Description: "Book a flight for a given date",
+		InputSchema: BookFlightRequestSchema,
+		Handler: func(ctx context.Context, args json.RawMessage) (any, error) {
+			var in BookFlightRequest
+			if err := runtime.UnmarshalValidate(args, &in, BookFlightRequestSchema); err != nil {
+				return nil, err
+			}
+			return agent.tools.BookFlight(ctx, &in)
+		},
+	})
+
+	return srv
+}
+
+// NewHotelAgentMCPServer returns an MCP server exposing the actions and the tools of agent.
+func NewHotelAgentMCPServer(agent *HotelAgentClient) *mcp.Server {
+	srv := mcp.NewServer("HotelAgent", "")
+
+	srv.AddTool(mcp.Tool{
+		Name:        "BookHotel",
+		Description: "Book an hotel for a given city and date range",
+		InputSchema: HotelRequestSchema,
+		Handler: func(ctx context.Context, args json.RawMessage) (any, error) {
+			var in HotelRequest
+			if err := runtime.UnmarshalValidate(args, &in, HotelRequestSchema); err != nil {
+				return nil, err
+			}
+			return agent.BookHotel(ctx, &in)
+		},
+	})
+
+	srv.AddTool(mcp.Tool{
+		Name:        "tool_FindHotels",
+		Description: "Find hotels in a city",
+		InputSchema: FindHotelRequestSchema,
+		Handler: func(ctx context.Context, args json.RawMessage) (any, error) {
+			var in FindHotelRequest
+			if err := runtime.UnmarshalValidate(args, &in, FindHotelRequestSchema); err != nil {
+				return nil, err
+			}
+			return agent.tools.FindHotels(ctx, &in)
+		},
+	})
+
+	srv.AddTool(mcp.Tool{
+		Name:        "tool_BookHotel",
+		Description: "Create an hotel reservation",
+		InputSchema: BookHotelRequestSchema,
+		Handler: func(ctx context.Context, args json.RawMessage) (any, error) {
+			var in BookHotelRequest
+			if err := runtime.UnmarshalValidate(args, &in, BookHotelRequestSchema); err != nil {
+				return nil, err
+			}
+			return agent.tools.BookHotel(ctx, &in)
+		},
+	})
+
+	return srv
+}
+
+// NewItineraryAgentMCPServer returns an MCP server exposing the actions and the tools of agent.
+func NewItineraryAgentMCPServer(agent *ItineraryAgentClient) *mcp.Server {
+	srv := mcp.NewServer("ItineraryAgent", "")
+
+	srv.AddTool(mcp.Tool{
+		Name:        "ExtractInfo",
+		Description: "Extract itinerary data into the output",
+		InputSchema: ItineraryRequestSchema,
+		Handler: func(ctx context.Context, args json.RawMessage) (any, error) {
+			var in ItineraryRequest
+			if err := runtime.UnmarshalValidate(args, &in, ItineraryRequestSchema); err != nil {
+				return nil, err
+			}
+			return agent.ExtractInfo(ctx, &in)
+		},
+	})
+
+	return srv
+}
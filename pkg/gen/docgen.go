@@ -0,0 +1,657 @@
+// Copyright (c) 2025 Suricata Contributors
+// Original Author: Stefano Scafiti
+//
+// This file is part of Suricata: Type-Safe AI Agents for Go.
+//
+// Licensed under the MIT License. You may obtain a copy of the License at
+//
+//	https://opensource.org/licenses/MIT
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package gen
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	htmltemplate "html/template"
+	"strings"
+	"text/template"
+
+	"github.com/ostafen/suricata/pkg/spec"
+)
+
+// PromptRenderer renders the prompt the given action would send for input, the JSON
+// input of one of its examples.
+type PromptRenderer func(agent, action string, input []byte) (string, error)
+
+// GenerateMarkdown generates the Markdown documentation of a spec: its agents and actions,
+// with their prompt templates, the catalog of its tools, and its messages, enums and
+// workflows. If render is not nil, the prompts of the examples of each action are shown too.
+func GenerateMarkdown(s *spec.Spec, render PromptRenderer) ([]byte, error) {
+	doc, err := newSpecDoc(s, render)
+	if err != nil {
+		return nil, err
+	}
+
+	var buf bytes.Buffer
+	if err := markdownTemplate.Execute(&buf, doc); err != nil {
+		return nil, err
+	}
+	return terminated(buf.Bytes()), nil
+}
+
+// GenerateHTML generates the same documentation as GenerateMarkdown, as a standalone HTML page.
+func GenerateHTML(s *spec.Spec, render PromptRenderer) ([]byte, error) {
+	doc, err := newSpecDoc(s, render)
+	if err != nil {
+		return nil, err
+	}
+
+	var buf bytes.Buffer
+	if err := htmlTemplate.Execute(&buf, doc); err != nil {
+		return nil, err
+	}
+	return bytes.Clone(buf.Bytes()), nil
+}
+
+type (
+	specDoc struct {
+		Package   string
+		Version   string
+		Agents    []agentDoc
+		Tools     []toolDoc
+		Messages  []messageDoc
+		Enums     []enumDoc
+		Workflows []workflowDoc
+
+		types map[string]bool // Messages and enums, which are linked to
+	}
+
+	agentDoc struct {
+		Name         string
+		Instructions string
+		Model        string
+		Tools        []string
+		Actions      []actionDoc
+	}
+
+	actionDoc struct {
+		Name        string
+		Description string
+		Input       string
+		Output      string
+		Stream      bool
+		Tools       []string
+		Model       string
+		Prompt      string
+		Examples    []exampleDoc
+	}
+
+	exampleDoc struct {
+		Input  string // Indented JSON
+		Prompt string // Rendered prompt, if a renderer is set
+	}
+
+	toolDoc struct {
+		Name        string
+		Description string
+		Input       string
+		Output      string
+		Builtin     string
+		Approval    bool
+	}
+
+	messageDoc struct {
+		Name   string
+		Fields []fieldDoc
+		OneOf  []string
+		Rules  []string
+		Schema string // Indented JSON Schema
+	}
+
+	fieldDoc struct {
+		Name        string
+		Type        string
+		Repeated    bool
+		Required    bool
+		Description string
+		Constraints string
+	}
+
+	enumDoc struct {
+		Name        string
+		Description string
+		Values      []string
+	}
+
+	workflowDoc struct {
+		Name        string
+		Description string
+		Input       string
+		Output      string
+		Steps       []stepDoc
+	}
+
+	stepDoc struct {
+		Name     string
+		Agent    string
+		Action   string
+		Optional bool
+	}
+)
+
+func newSpecDoc(s *spec.Spec, render PromptRenderer) (*specDoc, error) {
+	doc := &specDoc{
+		Package: s.Package,
+		Version: s.Version,
+		types:   make(map[string]bool),
+	}
+
+	for _, name := range sortedKeys(s.Enums) {
+		enum := s.Enums[name]
+		doc.types[name] = true
+		doc.Enums = append(doc.Enums, enumDoc{Name: name, Description: enum.Description, Values: enum.Values})
+	}
+
+	schemaGen := NewJSONSchemaGenerator()
+	for _, name := range sortedKeys(s.Messages) {
+		msg := s.Messages[name]
+		doc.types[name] = true
+
+		schema, err := schemaGen.GenerateJSONSchema(name, &msg, s.Messages, s.Enums)
+		if err != nil {
+			return nil, fmt.Errorf("message %q: %w", name, err)
+		}
+		var rawSchema bytes.Buffer
+		enc := json.NewEncoder(&rawSchema)
+		enc.SetEscapeHTML(false)
+		enc.SetIndent("", "  ")
+		if err := enc.Encode(schema); err != nil {
+			return nil, err
+		}
+
+		msgDoc := messageDoc{Name: name, OneOf: msg.OneOf, Rules: msg.Rules, Schema: strings.TrimSpace(rawSchema.String())}
+		for _, field := range msg.Fields {
+			var constraints []string
+			for _, c := range sortedKeys(fieldConstraints(&field)) {
+				v, _ := json.Marshal(fieldConstraints(&field)[c])
+				constraints = append(constraints, c+": "+string(v))
+			}
+
+			msgDoc.Fields = append(msgDoc.Fields, fieldDoc{
+				Name:        field.Name,
+				Type:        field.Type,
+				Repeated:    field.Repeated,
+				Required:    !field.Optional,
+				Description: field.Description,
+				Constraints: strings.Join(constraints, ", "),
+			})
+		}
+		doc.Messages = append(doc.Messages, msgDoc)
+	}
+
+	for _, name := range sortedKeys(s.Tools) {
+		tool := s.Tools[name]
+		doc.Tools = append(doc.Tools, toolDoc{
+			Name:        name,
+			Description: tool.Description,
+			Input:       tool.Input,
+			Output:      tool.Output,
+			Builtin:     tool.Builtin,
+			Approval:    tool.RequiresApproval(),
+		})
+	}
+
+	for _, name := range sortedKeys(s.Agents) {
+		agent := s.Agents[name]
+		agentDoc := agentDoc{
+			Name:         getAgentTypeName(name),
+			Instructions: agent.Instructions,
+			Model:        modelDoc(&agent.ModelConfig),
+			Tools:        agent.Tools,
+		}
+
+		for _, actionName := range sortedKeys(agent.Actions) {
+			action := agent.Actions[actionName]
+			actionDoc := actionDoc{
+				Name:        actionName,
+				Description: action.Description,
+				Input:       action.Input,
+				Output:      action.Output,
+				Stream:      action.Stream,
+				Tools:       agent.ActionTools(&action),
+				Model:       modelDoc(&action.ModelConfig),
+				Prompt:      action.Prompt,
+			}
+			if action.IsTextOutput() {
+				actionDoc.Output = spec.TextOutput
+			}
+
+			for i, example := range action.Examples {
+				input, err := json.MarshalIndent(example.Input, "", "  ")
+				if err != nil {
+					return nil, err
+				}
+
+				exampleDoc := exampleDoc{Input: string(input)}
+				if render != nil {
+					if exampleDoc.Prompt, err = render(name, actionName, input); err != nil {
+						return nil, fmt.Errorf("agent %q action %q example %d: %w", name, actionName, i+1, err)
+					}
+				}
+				actionDoc.Examples = append(actionDoc.Examples, exampleDoc)
+			}
+			agentDoc.Actions = append(agentDoc.Actions, actionDoc)
+		}
+		doc.Agents = append(doc.Agents, agentDoc)
+	}
+
+	for _, name := range sortedKeys(s.Workflows) {
+		wf := s.Workflows[name]
+		wfDoc := workflowDoc{
+			Name:        name,
+			Description: wf.Description,
+			Input:       wf.Input,
+			Output:      s.WorkflowOutput(&wf),
+		}
+		for _, step := range wf.Steps {
+			wfDoc.Steps = append(wfDoc.Steps, stepDoc{
+				Name:     step.Name,
+				Agent:    getAgentTypeName(step.Agent),
+				Action:   step.Action,
+				Optional: step.Optional,
+			})
+		}
+		doc.Workflows = append(doc.Workflows, wfDoc)
+	}
+	return doc, nil
+}
+
+// modelDoc describes the model settings of cfg, or returns an empty string if there are none.
+func modelDoc(cfg *spec.ModelConfig) string {
+	var parts []string
+	if cfg.Model != "" {
+		parts = append(parts, "model "+cfg.Model)
+	}
+	if cfg.Temperature != nil {
+		parts = append(parts, fmt.Sprintf("temperature %g", *cfg.Temperature))
+	}
+	if cfg.MaxTokens > 0 {
+		parts = append(parts, fmt.Sprintf("max tokens %d", cfg.MaxTokens))
+	}
+	return strings.Join(parts, ", ")
+}
+
+// typeParts splits a type into the names of the messages and enums it references, which are
+// linked to, and the text around them. Even elements are text, odd ones are type names.
+func (doc *specDoc) typeParts(t string) []string {
+	if key, value, ok := spec.ParseMapType(t); ok {
+		parts := doc.typeParts(value)
+		parts[0] = "map<" + key + ", " + parts[0]
+		parts[len(parts)-1] += ">"
+		return parts
+	}
+	if doc.types[t] {
+		return []string{"", t, ""}
+	}
+	return []string{t}
+}
+
+// anchor returns the id of the heading documenting the message or enum name.
+func anchor(name string) string {
+	return strings.ToLower(name)
+}
+
+// markdownType returns t, linking the messages and enums it references.
+func (doc *specDoc) markdownType(t string) string {
+	var sb strings.Builder
+	for i, part := range doc.typeParts(t) {
+		if i%2 == 0 {
+			sb.WriteString(cell(part))
+		} else {
+			fmt.Fprintf(&sb, "[%s](#%s)", part, anchor(part))
+		}
+	}
+	return sb.String()
+}
+
+// htmlType returns t, linking the messages and enums it references.
+func (doc *specDoc) htmlType(t string) htmltemplate.HTML {
+	var sb strings.Builder
+	for i, part := range doc.typeParts(t) {
+		if i%2 == 0 {
+			sb.WriteString(htmltemplate.HTMLEscapeString(part))
+		} else {
+			fmt.Fprintf(&sb, `<a href="#%s">%s</a>`, anchor(part), htmltemplate.HTMLEscapeString(part))
+		}
+	}
+	return htmltemplate.HTML(sb.String())
+}
+
+var cellReplacer = strings.NewReplacer("|", `\|`, "<", "&lt;", ">", "&gt;", "\n", "<br>")
+
+// cell escapes s for a Markdown table cell.
+func cell(s string) string {
+	return cellReplacer.Replace(strings.TrimSpace(s))
+}
+
+// fence returns a fence for a Markdown code block holding s, longer than any backtick run in s.
+func fence(s string) string {
+	longest := 0
+	for _, run := range strings.FieldsFunc(s, func(r rune) bool { return r != '`' }) {
+		longest = max(longest, len(run))
+	}
+	return strings.Repeat("`", max(3, longest+1))
+}
+
+var markdownTemplate = template.Must(template.New("markdown").Funcs(template.FuncMap{
+	"type":  func(doc *specDoc, t string) string { return doc.markdownType(t) },
+	"cell":  cell,
+	"fence": fence,
+	"trim":  strings.TrimSpace,
+	"join":  strings.Join,
+	"inc":   func(i int) int { return i + 1 },
+}).Parse(`# {{.Package}}
+{{- if .Version}}
+
+Version {{.Version}}
+{{- end}}
+{{- $doc := .}}
+{{- if .Agents}}
+
+## Agents
+{{- range .Agents}}
+
+### {{.Name}}
+{{- if .Instructions}}
+
+{{with trim .Instructions}}{{$f := fence .}}{{$f}}text
+{{.}}
+{{$f}}{{end}}
+{{- end}}
+{{- if .Model}}
+
+Model settings: {{.Model}}.
+{{- end}}
+{{- if .Tools}}
+
+Tools: {{join .Tools ", "}}.
+{{- end}}
+{{- range .Actions}}
+
+#### {{.Name}}
+{{- if .Description}}
+
+{{trim .Description}}
+{{- end}}
+
+- Input: {{type $doc .Input}}
+- Output: {{type $doc .Output}}
+{{- if .Stream}}
+- Streams the model responses
+{{- end}}
+{{- if .Tools}}
+- Tools: {{join .Tools ", "}}
+{{- end}}
+{{- if .Model}}
+- Model settings: {{.Model}}
+{{- end}}
+{{- if .Prompt}}
+
+Prompt template:
+
+{{with trim .Prompt}}{{$f := fence .}}{{$f}}gotemplate
+{{.}}
+{{$f}}{{end}}
+{{- end}}
+{{- range $i, $example := .Examples}}
+
+Example {{inc $i}} input:
+
+` + "```" + `json
+{{$example.Input}}
+` + "```" + `
+{{- if $example.Prompt}}
+
+Rendered prompt:
+
+{{with $example.Prompt}}{{$f := fence .}}{{$f}}text
+{{trim .}}
+{{$f}}{{end}}
+{{- end}}
+{{- end}}
+{{- end}}
+{{- end}}
+{{- end}}
+{{- if .Tools}}
+
+## Tools
+
+| Tool | Description | Input | Output | Approval |
+| --- | --- | --- | --- | --- |
+{{- range .Tools}}
+| {{.Name}}{{if .Builtin}} (builtin {{.Builtin}}){{end}} | {{cell .Description}} | {{type $doc .Input}} | {{type $doc .Output}} | {{if .Approval}}required{{end}} |
+{{- end}}
+{{- end}}
+{{- if .Workflows}}
+
+## Workflows
+{{- range .Workflows}}
+
+### {{.Name}}
+{{- if .Description}}
+
+{{trim .Description}}
+{{- end}}
+
+- Input: {{type $doc .Input}}
+- Output: {{type $doc .Output}}
+
+Steps:
+{{range $i, $step := .Steps}}
+{{inc $i}}. {{$step.Name}}: {{$step.Agent}}.{{$step.Action}}{{if $step.Optional}} (optional){{end}}
+{{- end}}
+{{- end}}
+{{- end}}
+{{- if .Messages}}
+
+## Messages
+{{- range .Messages}}
+
+### {{.Name}}
+{{- if .OneOf}}
+
+One of:
+{{range .OneOf}}
+- {{type $doc .}}
+{{- end}}
+{{- else if .Fields}}
+
+| Field | Type | Required | Description |
+| --- | --- | --- | --- |
+{{- range .Fields}}
+| {{.Name}} | {{if .Repeated}}list of {{end}}{{type $doc .Type}} | {{if .Required}}yes{{else}}no{{end}} | {{cell .Description}}{{if .Constraints}}{{if .Description}}<br>{{end}}{{cell .Constraints}}{{end}} |
+{{- end}}
+{{- end}}
+{{- if .Rules}}
+
+Rules:
+{{range .Rules}}
+- ` + "`{{.}}`" + `
+{{- end}}
+{{- end}}
+
+<details><summary>JSON Schema</summary>
+
+` + "```" + `json
+{{.Schema}}
+` + "```" + `
+
+</details>
+{{- end}}
+{{- end}}
+{{- if .Enums}}
+
+## Enums
+{{- range .Enums}}
+
+### {{.Name}}
+{{- if .Description}}
+
+{{trim .Description}}
+{{- end}}
+
+Values: {{join .Values ", "}}.
+{{- end}}
+{{- end}}
+`))
+
+var htmlTemplate = htmltemplate.Must(htmltemplate.New("html").Funcs(htmltemplate.FuncMap{
+	"type":   func(doc *specDoc, t string) htmltemplate.HTML { return doc.htmlType(t) },
+	"anchor": anchor,
+	"trim":   strings.TrimSpace,
+	"join":   strings.Join,
+	"inc":    func(i int) int { return i + 1 },
+}).Parse(`<!DOCTYPE html>
+<html lang="en">
+<head>
+<meta charset="utf-8">
+<title>{{.Package}}</title>
+<style>
+body { font-family: system-ui, sans-serif; max-width: 960px; margin: 2em auto; padding: 0 1em; color: #222; }
+pre { background: #f5f5f5; padding: 1em; overflow-x: auto; white-space: pre-wrap; }
+table { border-collapse: collapse; width: 100%; }
+th, td { border: 1px solid #ddd; padding: 0.4em 0.6em; text-align: left; vertical-align: top; }
+code { font-size: 0.95em; }
+</style>
+</head>
+<body>
+<h1>{{.Package}}</h1>
+{{- if .Version}}
+<p>Version {{.Version}}</p>
+{{- end}}
+{{- $doc := .}}
+{{- if .Agents}}
+<h2>Agents</h2>
+{{- range .Agents}}
+<h3>{{.Name}}</h3>
+{{- if .Instructions}}
+<pre>{{trim .Instructions}}</pre>
+{{- end}}
+{{- if .Model}}
+<p>Model settings: {{.Model}}.</p>
+{{- end}}
+{{- if .Tools}}
+<p>Tools: {{join .Tools ", "}}.</p>
+{{- end}}
+{{- range .Actions}}
+<h4>{{.Name}}</h4>
+{{- if .Description}}
+<p>{{trim .Description}}</p>
+{{- end}}
+<ul>
+<li>Input: {{type $doc .Input}}</li>
+<li>Output: {{type $doc .Output}}</li>
+{{- if .Stream}}
+<li>Streams the model responses</li>
+{{- end}}
+{{- if .Tools}}
+<li>Tools: {{join .Tools ", "}}</li>
+{{- end}}
+{{- if .Model}}
+<li>Model settings: {{.Model}}</li>
+{{- end}}
+</ul>
+{{- if .Prompt}}
+<p>Prompt template:</p>
+<pre><code>{{trim .Prompt}}</code></pre>
+{{- end}}
+{{- range $i, $example := .Examples}}
+<p>Example {{inc $i}} input:</p>
+<pre><code>{{$example.Input}}</code></pre>
+{{- if $example.Prompt}}
+<p>Rendered prompt:</p>
+<pre>{{trim $example.Prompt}}</pre>
+{{- end}}
+{{- end}}
+{{- end}}
+{{- end}}
+{{- end}}
+{{- if .Tools}}
+<h2>Tools</h2>
+<table>
+<tr><th>Tool</th><th>Description</th><th>Input</th><th>Output</th><th>Approval</th></tr>
+{{- range .Tools}}
+<tr><td>{{.Name}}{{if .Builtin}} (builtin {{.Builtin}}){{end}}</td><td>{{.Description}}</td><td>{{type $doc .Input}}</td><td>{{type $doc .Output}}</td><td>{{if .Approval}}required{{end}}</td></tr>
+{{- end}}
+</table>
+{{- end}}
+{{- if .Workflows}}
+<h2>Workflows</h2>
+{{- range .Workflows}}
+<h3>{{.Name}}</h3>
+{{- if .Description}}
+<p>{{trim .Description}}</p>
+{{- end}}
+<ul>
+<li>Input: {{type $doc .Input}}</li>
+<li>Output: {{type $doc .Output}}</li>
+</ul>
+<ol>
+{{- range .Steps}}
+<li>{{.Name}}: {{.Agent}}.{{.Action}}{{if .Optional}} (optional){{end}}</li>
+{{- end}}
+</ol>
+{{- end}}
+{{- end}}
+{{- if .Messages}}
+<h2>Messages</h2>
+{{- range .Messages}}
+<h3 id="{{anchor .Name}}">{{.Name}}</h3>
+{{- if .OneOf}}
+<p>One of:</p>
+<ul>
+{{- range .OneOf}}
+<li>{{type $doc .}}</li>
+{{- end}}
+</ul>
+{{- else if .Fields}}
+<table>
+<tr><th>Field</th><th>Type</th><th>Required</th><th>Description</th></tr>
+{{- range .Fields}}
+<tr><td>{{.Name}}</td><td>{{if .Repeated}}list of {{end}}{{type $doc .Type}}</td><td>{{if .Required}}yes{{else}}no{{end}}</td><td>{{.Description}}{{if .Constraints}}{{if .Description}}<br>{{end}}{{.Constraints}}{{end}}</td></tr>
+{{- end}}
+</table>
+{{- end}}
+{{- if .Rules}}
+<p>Rules:</p>
+<ul>
+{{- range .Rules}}
+<li><code>{{.}}</code></li>
+{{- end}}
+</ul>
+{{- end}}
+<details><summary>JSON Schema</summary>
+<pre><code>{{.Schema}}</code></pre>
+</details>
+{{- end}}
+{{- end}}
+{{- if .Enums}}
+<h2>Enums</h2>
+{{- range .Enums}}
+<h3 id="{{anchor .Name}}">{{.Name}}</h3>
+{{- if .Description}}
+<p>{{trim .Description}}</p>
+{{- end}}
+<p>Values: {{join .Values ", "}}.</p>
+{{- end}}
+{{- end}}
+</body>
+</html>
+`))
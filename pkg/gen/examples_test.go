@@ -0,0 +1,89 @@
+// Copyright (c) 2025 Suricata Contributors
+// Original Author: Stefano Scafiti
+//
+// This file is part of Suricata: Type-Safe AI Agents for Go.
+//
+// Licensed under the MIT License. You may obtain a copy of the License at
+//
+//	https://opensource.org/licenses/MIT
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package gen_test
+
+import (
+	"bytes"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/ostafen/suricata/pkg/gen"
+	"github.com/ostafen/suricata/pkg/spec"
+)
+
+// TestGenerate_Examples fails when the generated code of the examples, MCP servers
+// included, drifts from the output of the generator: run go generate ./example/... to update it.
+func TestGenerate_Examples(t *testing.T) {
+	specs, err := filepath.Glob("../../example/*/*.yml")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(specs) == 0 {
+		t.Fatal("no example found")
+	}
+
+	for _, path := range specs {
+		t.Run(filepath.Base(path), func(t *testing.T) {
+			out := t.TempDir()
+
+			files, err := gen.GenerateFile(path, gen.FileOptions{Out: out, MCP: true})
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+
+			for _, f := range files {
+				rel, err := filepath.Rel(out, f)
+				if err != nil {
+					t.Fatal(err)
+				}
+
+				got, err := os.ReadFile(f)
+				if err != nil {
+					t.Fatal(err)
+				}
+				want, err := os.ReadFile(filepath.Join(filepath.Dir(path), rel))
+				if err != nil {
+					t.Fatal(err)
+				}
+				if !bytes.Equal(got, want) {
+					t.Errorf("%s is out of date: run go generate ./example/...", rel)
+				}
+			}
+		})
+	}
+}
+
+func TestGenerateMCP_ToolNames(t *testing.T) {
+	s, err := spec.LoadSpec("../../example/trip/trip.yml")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	var g gen.CodeGenerator
+	src, err := g.GenerateMCP(s)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	// HotelAgent has both a BookHotel action and a BookHotel tool
+	for _, expected := range []string{`Name:        "BookHotel",`, `Name:        "tool_BookHotel",`} {
+		if !strings.Contains(string(src), expected) {
+			t.Errorf("expected generated code to contain %s", expected)
+		}
+	}
+}
@@ -0,0 +1,170 @@
+// Copyright (c) 2025 Suricata Contributors
+// Original Author: Stefano Scafiti
+//
+// This file is part of Suricata: Type-Safe AI Agents for Go.
+//
+// Licensed under the MIT License. You may obtain a copy of the License at
+//
+//	https://opensource.org/licenses/MIT
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package gen
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/ostafen/suricata/pkg/spec"
+)
+
+// FileOptions configures GenerateFile. Relative paths are resolved from the working directory.
+type FileOptions struct {
+	Out    string // Root directory of the generated packages. Defaults to the working directory.
+	Module string // Go module of Out: the module prefix is stripped from package import paths
+	// Flat writes the Go files to Out itself, rather than to the directory of their package,
+	// as go:generate directives expect.
+	Flat bool
+	// Package, if set, is the name the generated Go package must have, such as the one of
+	// the file holding a go:generate directive.
+	Package string
+	Header  string // Written as a comment at the top of each generated file, e.g. a license
+
+	Mock bool // Also generate mock implementations of agents and tools interfaces
+	HTTP bool // Also generate HTTP handlers exposing the actions of each agent
+	MCP  bool // Also generate MCP servers exposing the actions and tools of each agent
+
+	SchemasOut string // If set, directory of the JSON Schema files of the messages
+	TSOut      string // If set, directory of the TypeScript module
+	PyOut      string // If set, directory of the Python module
+}
+
+// GenerateFile loads the spec file at path, generates its code as configured by opts, and
+// writes it. It returns the paths of the written files. It is the library counterpart of
+// "suricata gen", for build systems invoking generation programmatically.
+func GenerateFile(path string, opts FileOptions) ([]string, error) {
+	s, err := spec.LoadSpec(path)
+	if err != nil {
+		return nil, fmt.Errorf("%s: %w", path, err)
+	}
+
+	files, err := generateFiles(s, &opts)
+	if err != nil {
+		return nil, fmt.Errorf("%s: %w", path, err)
+	}
+
+	written := make([]string, 0, len(files))
+	for _, f := range files {
+		if err := os.MkdirAll(filepath.Dir(f.path), 0755); err != nil {
+			return written, err
+		}
+		if err := os.WriteFile(f.path, f.data, 0666); err != nil {
+			return written, err
+		}
+		written = append(written, f.path)
+	}
+	return written, nil
+}
+
+type generatedFile struct {
+	path string
+	data []byte
+}
+
+// generateFiles generates all the files of s, so that nothing is written if any of them fails.
+func generateFiles(s *spec.Spec, opts *FileOptions) ([]generatedFile, error) {
+	name := s.GoPackageName()
+	if opts.Package != "" && name != opts.Package {
+		return nil, fmt.Errorf("generated package %q does not match package %q: set go_package in the spec", name, opts.Package)
+	}
+
+	dir := "."
+	if !opts.Flat {
+		var err error
+		if dir, err = packageDir(s.GoPackagePath(), opts.Module); err != nil {
+			return nil, err
+		}
+	}
+	base := filepath.Join(opts.Out, dir, name)
+
+	gen := CodeGenerator{Header: opts.Header}
+
+	var files []generatedFile
+	add := func(path string, generate func(*spec.Spec) ([]byte, error)) error {
+		data, err := generate(s)
+		if err != nil {
+			return err
+		}
+		files = append(files, generatedFile{path: path, data: data})
+		return nil
+	}
+
+	if err := add(base+".go", gen.Generate); err != nil {
+		return nil, err
+	}
+
+	if opts.Mock {
+		if err := add(base+"_mock.go", gen.GenerateMocks); err != nil {
+			return nil, err
+		}
+	}
+
+	if opts.HTTP {
+		if err := add(base+"_http.go", gen.GenerateHTTP); err != nil {
+			return nil, err
+		}
+	}
+
+	if opts.MCP {
+		if err := add(base+"_mcp.go", gen.GenerateMCP); err != nil {
+			return nil, err
+		}
+	}
+
+	if opts.SchemasOut != "" {
+		schemas, err := GenerateSchemas(s)
+		if err != nil {
+			return nil, err
+		}
+		for _, file := range sortedKeys(schemas) {
+			files = append(files, generatedFile{path: filepath.Join(opts.SchemasOut, file), data: schemas[file]})
+		}
+	}
+
+	if opts.TSOut != "" {
+		if err := add(filepath.Join(opts.TSOut, name)+".ts", gen.GenerateTypeScript); err != nil {
+			return nil, err
+		}
+	}
+
+	if opts.PyOut != "" {
+		if err := add(filepath.Join(opts.PyOut, name)+".py", gen.GeneratePython); err != nil {
+			return nil, err
+		}
+	}
+	return files, nil
+}
+
+// packageDir returns the directory of a generated package, relative to the output directory.
+// When module is set, the package must belong to it, and the module prefix is stripped.
+func packageDir(importPath, module string) (string, error) {
+	if module == "" {
+		return filepath.FromSlash(importPath), nil
+	}
+
+	if importPath == module {
+		return ".", nil
+	}
+
+	rel, ok := strings.CutPrefix(importPath, strings.TrimSuffix(module, "/")+"/")
+	if !ok {
+		return "", fmt.Errorf("go package %q does not belong to module %q", importPath, module)
+	}
+	return filepath.FromSlash(rel), nil
+}
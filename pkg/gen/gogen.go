@@ -19,15 +19,40 @@ import (
 	"bytes"
 	"encoding/json"
 	"fmt"
+	"maps"
+	"slices"
 	"strings"
+	"unicode"
 
 	"golang.org/x/tools/imports"
 
 	"github.com/ostafen/suricata/pkg/spec"
 )
 
+// Banner marks generated files, following the convention recognized by Go tools.
+const Banner = "// Code generated by suricata-gen; DO NOT EDIT."
+
+// baseImports are the packages which may be referenced by any generated file.
+var baseImports = []string{
+	"context",
+	"encoding/json",
+	"fmt",
+	"time",
+	"github.com/ostafen/suricata/runtime",
+	"github.com/xeipuuv/gojsonschema",
+}
+
+// CodeGenerator generates Go code from specs. Its output only depends on the
+// spec and on the generator settings, so generated files can be committed and diffed.
 type CodeGenerator struct {
-	buf bytes.Buffer
+	// Header is written at the top of each generated file, before the banner,
+	// typically holding a license. Lines not starting with "//" are commented out.
+	Header string
+
+	buf      bytes.Buffer
+	partials bool              // Whether the spec defines templates, which prompts may include
+	rules    map[string]bool   // Messages with rules, of their own or of their nested messages
+	external map[string]string // Qualified names of the enums and messages generated in imported packages
 }
 
 func (gen *CodeGenerator) write(format string, a ...any) {
@@ -38,15 +63,116 @@ func (gen *CodeGenerator) write(format string, a ...any) {
 	}
 }
 
+// writePreamble writes the header, the banner, the package clause and the imports of a generated file.
+// All the imports which may be needed are listed explicitly, and the unused ones are later dropped
+// by format: resolving missing imports instead would depend on the packages found in the environment.
+func (gen *CodeGenerator) writePreamble(spec *spec.Spec, extraImports ...string) {
+	gen.writeBanner("//")
+	gen.write("package %s\n\n", spec.GoPackageName())
+
+	gen.write("import (\n")
+	for _, path := range append(slices.Clone(baseImports), extraImports...) {
+		gen.write("\t%q\n", path)
+	}
+	for _, path := range gen.resolveExternal(spec) {
+		gen.write("\t%s %q\n", packageName(path), path)
+	}
+	gen.write(")\n\n")
+}
+
+// resolveExternal records the enums and messages of spec generated in the packages of imported specs,
+// and returns the import paths of these packages.
+func (gen *CodeGenerator) resolveExternal(s *spec.Spec) []string {
+	gen.external = make(map[string]string)
+
+	var paths []string
+	for _, name := range append(sortedKeys(s.Enums), sortedKeys(s.Messages)...) {
+		path := s.GoPackageOf(name)
+		if path == s.GoPackagePath() {
+			continue
+		}
+		gen.external[name] = packageName(path) + "." + name
+		if !slices.Contains(paths, path) {
+			paths = append(paths, path)
+		}
+	}
+	slices.Sort(paths)
+	return paths
+}
+
+// local returns the enums or messages of m generated in the package of the spec.
+func local[T any](gen *CodeGenerator, m map[string]T) map[string]T {
+	l := make(map[string]T, len(m))
+	for name, v := range m {
+		if !gen.isExternal(name) {
+			l[name] = v
+		}
+	}
+	return l
+}
+
+// typeName returns the Go type of the enum or message name, qualified by its package if imported.
+func (gen *CodeGenerator) typeName(name string) string {
+	if qualified, ok := gen.external[name]; ok {
+		return qualified
+	}
+	return CapitalizeFirst(name)
+}
+
+// isExternal reports whether the enum or message name is generated in the package of an imported spec.
+func (gen *CodeGenerator) isExternal(name string) bool {
+	_, ok := gen.external[name]
+	return ok
+}
+
+// writeBanner writes the header and the banner of a generated file, as line comments starting with
+// prefix, which is "//" for Go and TypeScript files.
+func (gen *CodeGenerator) writeBanner(prefix string) {
+	if header := strings.TrimRight(gen.Header, "\n"); header != "" {
+		for _, line := range strings.Split(header, "\n") {
+			switch {
+			case strings.HasPrefix(line, prefix):
+				gen.write("%s\n", line)
+			case strings.TrimSpace(line) == "":
+				gen.write("%s\n", prefix)
+			default:
+				gen.write("%s %s\n", prefix, line)
+			}
+		}
+		gen.write("\n")
+	}
+
+	gen.write("%s %s\n\n", prefix, strings.TrimPrefix(Banner, "// "))
+}
+
+// format drops unused imports and formats the generated code as gofmt would.
+func (gen *CodeGenerator) format() ([]byte, error) {
+	src, err := imports.Process("", gen.buf.Bytes(), &imports.Options{
+		Comments:   true,
+		TabIndent:  true,
+		TabWidth:   8,
+		FormatOnly: false,
+	})
+	if err != nil {
+		return bytes.Clone(gen.buf.Bytes()), err
+	}
+	return src, nil
+}
+
+// terminated returns a copy of src ending with a single newline. The copy does not share
+// the memory of the buffer of the generator, which is reused by the next target.
+func terminated(src []byte) []byte {
+	return append(bytes.Clone(bytes.TrimRight(src, "\n")), '\n')
+}
+
 func (gen *CodeGenerator) Generate(spec *spec.Spec) ([]byte, error) {
 	gen.buf.Reset()
 
-	gen.write("// Code generated by suricata-gen; DO NOT EDIT.\n\n")
-	gen.write("package %s\n\n", packageName(spec.Package))
+	gen.writePreamble(spec)
 
 	// Generate enums first
-	if len(spec.Enums) > 0 {
-		gen.generateEnums(spec.Enums)
+	if enums := local(gen, spec.Enums); len(enums) > 0 {
+		gen.generateEnums(enums)
 	}
 
 	if len(spec.Messages) > 0 {
@@ -54,19 +180,68 @@ func (gen *CodeGenerator) Generate(spec *spec.Spec) ([]byte, error) {
 			return nil, err
 		}
 		gen.generateTypes(spec.Messages, spec.Enums)
+		gen.generateMessageRules(spec)
+	}
+
+	gen.partials = len(spec.Templates) > 0
+	if gen.partials {
+		gen.generatePromptPartials(spec.Templates)
 	}
 
 	// Generate RPC methods
-	for name, svc := range spec.Agents {
+	for _, name := range sortedKeys(spec.Agents) {
+		svc := spec.Agents[name]
+		for actionName := range svc.Actions {
+			if CapitalizeFirst(actionName) == "Routes" {
+				return nil, fmt.Errorf("agent %q: action %q clashes with the generated Routes method", name, actionName)
+			}
+		}
 		gen.generateAgent(name, &svc, spec.Tools)
 	}
 
-	// Use imports.Process to organize imports and format code
-	src, err := imports.Process("", gen.buf.Bytes(), nil)
-	if err != nil {
-		return gen.buf.Bytes(), err
+	for _, name := range sortedKeys(spec.Workflows) {
+		wf := spec.Workflows[name]
+		gen.generateWorkflow(spec, name, &wf)
 	}
-	return src, nil
+
+	return gen.format()
+}
+
+// generateMessageRules generates, for each message with rules, a post-processor checking them
+// together with the rules of its nested messages.
+func (gen *CodeGenerator) generateMessageRules(s *spec.Spec) {
+	gen.rules = make(map[string]bool)
+
+	for _, name := range sortedKeys(s.Messages) {
+		rules := s.Rules(name)
+		if len(rules) == 0 {
+			continue
+		}
+		gen.rules[name] = true
+		if gen.isExternal(name) {
+			continue // Generated in the imported package
+		}
+
+		gen.write("// %sRules checks the rules of %s on the outputs of actions.\n", name, name)
+		gen.write("var %sRules = runtime.CheckRules(\n", name)
+		for _, rule := range rules {
+			if rule.Scope == "" {
+				gen.write("\truntime.MustParseRule(%q),\n", rule.Expr)
+			} else {
+				gen.write("\truntime.MustParseRule(%q).At(%q),\n", rule.Expr, rule.Scope)
+			}
+		}
+		gen.write(")\n\n")
+	}
+}
+
+// generatePromptPartials generates the map of the templates which prompts can include.
+func (gen *CodeGenerator) generatePromptPartials(templates map[string]string) {
+	gen.write("var promptPartials = map[string]string{\n")
+	for _, name := range sortedKeys(templates) {
+		gen.write("\t%q: `%s`,\n", name, escapeBackticks(templates[name]))
+	}
+	gen.write("}\n\n")
 }
 
 func (gen *CodeGenerator) generateEnums(enums map[string]spec.Enum) {
@@ -77,13 +252,14 @@ func (gen *CodeGenerator) generateEnums(enums map[string]spec.Enum) {
 	// Generate enum type definitions
 	gen.write("// Enum types\n")
 	gen.write("type (\n")
-	for name := range enums {
+	for _, name := range sortedKeys(enums) {
 		gen.write("\t%s string\n", name)
 	}
 	gen.write(")\n\n")
 
 	// Generate enum constants and methods for each enum
-	for name, enum := range enums {
+	for _, name := range sortedKeys(enums) {
+		enum := enums[name]
 		gen.generateEnumConstants(name, enum)
 		gen.generateEnumMethods(name, enum)
 	}
@@ -93,10 +269,19 @@ func (gen *CodeGenerator) generateEnumConstants(name string, enum spec.Enum) {
 	gen.write("// %s values\n", name)
 	gen.write("const (\n")
 	for _, value := range enum.Values {
-		constName := name + CapitalizeFirst(toCamelCase(value))
-		gen.write("\t%s %s = \"%s\"\n", constName, name, value)
+		gen.write("\t%s %s = %q\n", enumConstName(name, value), name, value)
 	}
 	gen.write(")\n\n")
+
+	gen.write("// %sValues lists all the valid %s values\n", name, name)
+	gen.write("var %sValues = []%s{", name, name)
+	for i, value := range enum.Values {
+		if i > 0 {
+			gen.write(", ")
+		}
+		gen.write("%s", enumConstName(name, value))
+	}
+	gen.write("}\n\n")
 }
 
 func (gen *CodeGenerator) generateEnumMethods(name string, enum spec.Enum) {
@@ -109,8 +294,7 @@ func (gen *CodeGenerator) generateEnumMethods(name string, enum spec.Enum) {
 		if i > 0 {
 			gen.write(", ")
 		}
-		constName := name + CapitalizeFirst(toCamelCase(value))
-		gen.write(constName)
+		gen.write("%s", enumConstName(name, value))
 	}
 	gen.write(":\n")
 	gen.write("\t\treturn true\n")
@@ -119,18 +303,58 @@ func (gen *CodeGenerator) generateEnumMethods(name string, enum spec.Enum) {
 	gen.write("\t}\n")
 	gen.write("}\n\n")
 
+	// Generate Validate method
+	gen.write("// Validate returns an error if the %s value is not valid\n", name)
+	gen.write("func (e %s) Validate() error {\n", name)
+	gen.write("\tif !e.IsValid() {\n")
+	gen.write("\t\treturn fmt.Errorf(\"invalid %s value %%q\", string(e))\n", name)
+	gen.write("\t}\n")
+	gen.write("\treturn nil\n")
+	gen.write("}\n\n")
+
 	// Generate String method
 	gen.write("// String returns the string representation of %s\n", name)
 	gen.write("func (e %s) String() string {\n", name)
 	gen.write("\treturn string(e)\n")
 	gen.write("}\n\n")
+
+	// Generate UnmarshalJSON method
+	gen.write("// UnmarshalJSON decodes a %s, rejecting values which are not valid\n", name)
+	gen.write("func (e *%s) UnmarshalJSON(data []byte) error {\n", name)
+	gen.write("\tvar s string\n")
+	gen.write("\tif err := json.Unmarshal(data, &s); err != nil {\n")
+	gen.write("\t\treturn err\n")
+	gen.write("\t}\n\n")
+	gen.write("\tv := %s(s)\n", name)
+	gen.write("\tif err := v.Validate(); err != nil {\n")
+	gen.write("\t\treturn err\n")
+	gen.write("\t}\n\n")
+	gen.write("\t*e = v\n")
+	gen.write("\treturn nil\n")
+	gen.write("}\n\n")
+}
+
+// enumConstName returns the name of the constant of an enum value,
+// dropping any character which is not valid in a Go identifier.
+func enumConstName(enum, value string) string {
+	parts := strings.FieldsFunc(value, func(r rune) bool {
+		return !unicode.IsLetter(r) && !unicode.IsDigit(r)
+	})
+
+	var sb strings.Builder
+	sb.WriteString(enum)
+	for _, p := range parts {
+		sb.WriteString(CapitalizeFirst(p))
+	}
+	return sb.String()
 }
 
 func (gen *CodeGenerator) generateMessageSchemas(messages map[string]spec.Message, enums map[string]spec.Enum) error {
 	schemaGen := NewJSONSchemaGenerator()
 
 	gen.write("var (\n")
-	for name, msg := range messages {
+	for _, name := range sortedKeys(local(gen, messages)) {
+		msg := messages[name]
 		schema, err := schemaGen.GenerateJSONSchema(name, &msg, messages, enums)
 		if err != nil {
 			return err
@@ -141,7 +365,7 @@ func (gen *CodeGenerator) generateMessageSchemas(messages map[string]spec.Messag
 			return err
 		}
 
-		gen.write("\t%sSchema = gojsonschema.NewStringLoader(`%s`)\n", name, string(rawSchema))
+		gen.write("\t%sSchema = runtime.NewSchema(`%s`)\n", name, string(rawSchema))
 	}
 	gen.write(")\n")
 	return nil
@@ -150,11 +374,17 @@ func (gen *CodeGenerator) generateMessageSchemas(messages map[string]spec.Messag
 func (gen *CodeGenerator) generateTypes(messages map[string]spec.Message, enums map[string]spec.Enum) {
 	// Generate structs for messages
 	gen.write("type (\n")
-	for name, msg := range messages {
+	for _, name := range sortedKeys(local(gen, messages)) {
+		msg := messages[name]
+		if msg.IsUnion() {
+			gen.generateUnionStruct(name, &msg)
+			continue
+		}
+
 		gen.write(fmt.Sprintf("\t%s struct {\n", name))
 		for _, field := range msg.Fields {
-			goType := goTypeForField(field, enums)
-			fieldName := toCamelCase(field.Name)
+			goType := gen.goTypeForField(field)
+			fieldName := ToCamelCase(field.Name)
 
 			tagParts := []string{field.Name}
 			if field.Optional || field.Repeated {
@@ -166,7 +396,54 @@ func (gen *CodeGenerator) generateTypes(messages map[string]spec.Message, enums
 		}
 		gen.write("}\n\n")
 	}
-	gen.write(")\n")
+	gen.write(")\n\n")
+
+	for _, name := range sortedKeys(local(gen, messages)) {
+		msg := messages[name]
+		if msg.IsUnion() {
+			gen.generateUnionMethods(name, &msg)
+		}
+	}
+}
+
+func (gen *CodeGenerator) generateUnionStruct(name string, msg *spec.Message) {
+	gen.write("\t// %s is a union: exactly one of its fields is expected to be set.\n", name)
+	gen.write("\t%s struct {\n", name)
+	for _, variant := range msg.OneOf {
+		gen.write("\t%s *%s `json:\"-\"`\n", CapitalizeFirst(variant), gen.typeName(variant))
+	}
+	gen.write("}\n\n")
+}
+
+func (gen *CodeGenerator) generateUnionMethods(name string, msg *spec.Message) {
+	gen.write("// MarshalJSON encodes the set variant as {\"type\": <variant>, \"value\": <payload>}.\n")
+	gen.write("func (u %s) MarshalJSON() ([]byte, error) {\n", name)
+	gen.write("\ttype envelope struct {\n\t\tType string `json:\"type\"`\n\t\tValue any `json:\"value\"`\n\t}\n\n")
+	gen.write("\tswitch {\n")
+	for _, variant := range msg.OneOf {
+		field := CapitalizeFirst(variant)
+		gen.write("\tcase u.%s != nil:\n", field)
+		gen.write("\t\treturn json.Marshal(envelope{Type: \"%s\", Value: u.%s})\n", variant, field)
+	}
+	gen.write("\t}\n")
+	gen.write("\treturn []byte(\"null\"), nil\n")
+	gen.write("}\n\n")
+
+	gen.write("// UnmarshalJSON decodes a {\"type\": <variant>, \"value\": <payload>} envelope.\n")
+	gen.write("func (u *%s) UnmarshalJSON(data []byte) error {\n", name)
+	gen.write("\tvar envelope struct {\n\t\tType string `json:\"type\"`\n\t\tValue json.RawMessage `json:\"value\"`\n\t}\n\n")
+	gen.write("\tif err := json.Unmarshal(data, &envelope); err != nil {\n\t\treturn err\n\t}\n\n")
+	gen.write("\t*u = %s{}\n\n", name)
+	gen.write("\tswitch envelope.Type {\n")
+	for _, variant := range msg.OneOf {
+		field := CapitalizeFirst(variant)
+		gen.write("\tcase \"%s\":\n", variant)
+		gen.write("\t\tu.%s = new(%s)\n", field, gen.typeName(variant))
+		gen.write("\t\treturn json.Unmarshal(envelope.Value, u.%s)\n", field)
+	}
+	gen.write("\t}\n")
+	gen.write("\treturn fmt.Errorf(\"%s: unknown variant %%q\", envelope.Type)\n", name)
+	gen.write("}\n\n")
 }
 
 func getAgentTypeName(name string) string {
@@ -178,71 +455,269 @@ func getAgentTypeName(name string) string {
 	return name + "Agent"
 }
 
-func (gen *CodeGenerator) generateAgent(name string, agent *spec.Agent, tools map[string]spec.Tool) {
-	name = getAgentTypeName(name)
+func (gen *CodeGenerator) generateAgent(agentName string, agent *spec.Agent, tools map[string]spec.Tool) {
+	name := getAgentTypeName(agentName)
+	allTools := agent.AllTools()
+
+	gen.generateToolsInterface(name, allTools, tools)
+	gen.generateToolsSpec(name+"ToolsSpec", agent.Tools, tools)
 
-	gen.generateToolsInterface(name, agent.Tools, tools)
-	gen.generateToolsSpec(name, agent.Tools, tools)
+	for _, actionName := range sortedKeys(agent.Actions) {
+		action := agent.Actions[actionName]
+		if action.Tools != nil {
+			gen.generateToolsSpec(name+CapitalizeFirst(actionName)+"ToolsSpec", action.Tools, tools)
+		}
+	}
 
 	instructions := escapeBackticks(agent.Instructions)
 	gen.write("var %sInstructions =  `%s`\n\n", name, instructions)
 
-	if len(agent.Tools) > 0 {
-		gen.write("type %s struct {\n\truntime *runtime.Runtime\n\ttools %sTools\n}\n\n", name, name)
-		gen.write("func New%s(invoker runtime.Invoker, tools %sTools) *%s {\n\treturn &%s{runtime: runtime.NewRuntime(invoker), tools: tools}\n}\n\n", name, name, name, name)
+	gen.generateAgentInterface(name, agent)
+
+	client := name + "Client"
+	gen.write("// %s implements %s by running its actions on a runtime.Runtime.\n", client, name)
+	ctorDoc := fmt.Sprintf("// New%s returns a client of the agent. The options configure the runtime running its actions,\n"+
+		"// e.g. runtime.WithHooks, runtime.WithMemory, runtime.WithRetryPolicy, runtime.WithPromptOverride or runtime.WithTracer.\n", name)
+	if len(allTools) > 0 {
+		gen.write("type %s struct {\n\truntime *runtime.Runtime\n\ttools %sTools\n}\n\n", client, name)
+		gen.write("%s", ctorDoc)
+		gen.write("func New%s(invoker runtime.Invoker, tools %sTools, opts ...runtime.Option) *%s {\n\treturn &%s{runtime: runtime.NewRuntime(invoker, opts...), tools: tools}\n}\n\n", name, name, client, client)
 	} else {
-		gen.write("type %s struct {\n\truntime *runtime.Runtime\n}\n\n", name)
-		gen.write("func New%s(invoker runtime.Invoker) *%s {\n\treturn &%s{runtime: runtime.NewRuntime(invoker)}\n}\n\n", name, name, name)
+		gen.write("type %s struct {\n\truntime *runtime.Runtime\n}\n\n", client)
+		gen.write("%s", ctorDoc)
+		gen.write("func New%s(invoker runtime.Invoker, opts ...runtime.Option) *%s {\n\treturn &%s{runtime: runtime.NewRuntime(invoker, opts...)}\n}\n\n", name, client, client)
 	}
+	gen.write("var _ %s = (*%s)(nil)\n\n", name, client)
 
-	gen.generateUnmarshaller(name, agent.Tools, tools)
-	gen.generateToolsInvoker(name, agent.Tools, tools)
+	gen.generateUnmarshaller(name, allTools, tools)
+	gen.generateToolsInvoker(name, allTools, tools)
 
-	for actionName, action := range agent.Actions {
-		inType := CapitalizeFirst(action.Input)
-		outType := CapitalizeFirst(action.Output)
-		methodName := CapitalizeFirst(actionName)
+	for _, actionName := range sortedKeys(agent.Actions) {
+		action := agent.Actions[actionName]
+		if action.Stream {
+			gen.generateStreamAction(name, actionName, agent, &action)
+		} else {
+			gen.generateAction(name, actionName, agent, &action)
+		}
+	}
 
-		gen.write(fmt.Sprintf("func (c *%s) %s(ctx context.Context, in *%s) (*%s, error) {\n", name, methodName, inType, outType))
+	gen.generateRoutes(agentName, name, agent)
+}
 
-		// Prepare prompt (raw string literal)
-		prompt := escapeBackticks(action.Prompt)
-		gen.write("\tprompt := `%s`\n\n", prompt)
+// generateAgentInterface generates the interface of an agent, listing its actions, so that
+// consumers can depend on it, mock it and wrap the client with decorators.
+func (gen *CodeGenerator) generateAgentInterface(name string, agent *spec.Agent) {
+	gen.write("// %s lists the actions of the agent. It is implemented by %sClient.\n", name, name)
+	gen.write("type %s interface {\n", name)
+	for _, actionName := range sortedKeys(agent.Actions) {
+		action := agent.Actions[actionName]
+		if action.Description != "" {
+			// Descriptions may span several lines
+			gen.write("\t// %s: %s\n", CapitalizeFirst(actionName), strings.Join(strings.Fields(action.Description), " "))
+		}
+		gen.write("\t%s%s\n", CapitalizeFirst(actionName), gen.actionSignature(&action))
+	}
+	gen.write("}\n\n")
+}
 
-		gen.write("\t// Invoke LLM runtime\n")
-		gen.write("\tout := %s{}\n", outType)
-		gen.write("\terr := c.runtime.Invoke(ctx, runtime.Request{\n")
-		gen.write("\t\tSkipInput: %t,\n", action.SkipInput)
-		gen.write("\t\tInstructions: %sInstructions,\n", name)
-		gen.write("\t\tPromptTemplate: prompt,\n")
-		gen.write("\t\tInput: in,\n")
-		gen.write("\t\tOutput: &out,\n")
-		gen.write("\t\tInputSchema: %sSchema ,\n", inType)
-		gen.write("\t\tOutputSchema: %sSchema ,\n", outType)
-
-		if len(agent.Tools) > 0 {
-			gen.write("\t\tToolUnmarshaller: c.unmarshaller,\n")
-			gen.write("\t\tToolInvoker: c.toolsInvoker,\n")
-			gen.write("\t\tToolSpecs: %sToolsSpec,\n", name)
+// generateRoutes generates a method exposing the actions of an agent to a runtime.Supervisor.
+func (gen *CodeGenerator) generateRoutes(agentName, name string, agent *spec.Agent) {
+	gen.write("// Routes returns the actions of the agent, so that a runtime.Supervisor can delegate requests to them.\n")
+	gen.write("func (c *%sClient) Routes() []runtime.Route {\n", name)
+	gen.write("\treturn []runtime.Route{\n")
+
+	for _, actionName := range sortedKeys(agent.Actions) {
+		action := agent.Actions[actionName]
+		inType := gen.typeName(action.Input)
+
+		call := fmt.Sprintf("c.%s(ctx, &in)", CapitalizeFirst(actionName))
+		if action.Stream {
+			call += ".Result()"
 		}
 
-		gen.write("\t})\n")
-		gen.write("\tif err != nil {\n\t\treturn nil, fmt.Errorf(\"llm call failed: %w\", err)\n\t}\n\n")
+		gen.write("\t\t{\n")
+		gen.write("\t\t\tAgent: %q,\n", agentName)
+		gen.write("\t\t\tAction: %q,\n", actionName)
+		gen.write("\t\t\tDescription: %q,\n", action.Description)
+		gen.write("\t\t\tInputSchema: %sSchema,\n", inType)
+		gen.write("\t\t\tInvoke: func(ctx context.Context, args json.RawMessage) (any, error) {\n")
+		gen.write("\t\t\t\tvar in %s\n", inType)
+		gen.write("\t\t\t\tif err := runtime.UnmarshalValidate(args, &in, %sSchema); err != nil {\n", inType)
+		gen.write("\t\t\t\t\treturn nil, err\n")
+		gen.write("\t\t\t\t}\n")
+		gen.write("\t\t\t\treturn %s\n", call)
+		gen.write("\t\t\t},\n")
+		gen.write("\t\t},\n")
+	}
+
+	gen.write("\t}\n")
+	gen.write("}\n\n")
+}
+
+func (gen *CodeGenerator) generateAction(name, actionName string, agent *spec.Agent, action *spec.Actions) {
+	outType := gen.actionOutputType(action)
+	methodName := CapitalizeFirst(actionName)
 
+	gen.write("func (c *%sClient) %s%s {\n", name, methodName, gen.actionSignature(action))
+
+	// Prepare prompt (raw string literal)
+	prompt := escapeBackticks(action.Prompt)
+	gen.write("\tprompt := `%s`\n\n", prompt)
+
+	gen.write("\t// Invoke LLM runtime\n")
+	if action.IsTextOutput() {
+		gen.write("\tvar out string\n")
+	} else {
+		gen.write("\tout := %s{}\n", outType)
+	}
+	gen.write("\terr := c.runtime.Invoke(ctx, runtime.Request{\n")
+	gen.generateRequestFields(name, actionName, agent, action, "&out")
+	gen.write("\t})\n")
+
+	if action.IsTextOutput() {
+		gen.write("\tif err != nil {\n\t\treturn \"\", fmt.Errorf(%q, err)\n\t}\n\n", "llm call failed: %w")
+		gen.write("\treturn out, nil\n")
+	} else {
+		gen.write("\tif err != nil {\n\t\treturn nil, fmt.Errorf(%q, err)\n\t}\n\n", "llm call failed: %w")
 		gen.write("\treturn &out, nil\n")
-		gen.write("}\n\n")
 	}
+	gen.write("}\n\n")
+}
+
+// generateStreamAction generates an action method returning a stream of the chunks
+// of the model responses, followed by the typed output.
+func (gen *CodeGenerator) generateStreamAction(name, actionName string, agent *spec.Agent, action *spec.Actions) {
+	outType := gen.actionOutputType(action)
+	methodName := CapitalizeFirst(actionName)
+
+	gen.write("func (c *%sClient) %s%s {\n", name, methodName, gen.actionSignature(action))
+
+	prompt := escapeBackticks(action.Prompt)
+	gen.write("\tprompt := `%s`\n\n", prompt)
+
+	gen.write("\treturn runtime.StartStream(ctx, func(ctx context.Context, out *%s, onDelta func(string)) error {\n", outType)
+	gen.write("\t\terr := c.runtime.Invoke(ctx, runtime.Request{\n")
+	gen.generateRequestFields(name, actionName, agent, action, "out")
+	gen.write("\t\t\tOnDelta: onDelta,\n")
+	gen.write("\t\t})\n")
+	gen.write("\t\tif err != nil {\n\t\t\treturn fmt.Errorf(%q, err)\n\t\t}\n", "llm call failed: %w")
+	gen.write("\t\treturn nil\n")
+	gen.write("\t})\n")
+	gen.write("}\n\n")
+}
+
+func (gen *CodeGenerator) generateRequestFields(name, actionName string, agent *spec.Agent, action *spec.Actions, out string) {
+	gen.write("\t\tAction: %q,\n", actionName)
+	gen.write("\t\tSkipInput: %t,\n", action.SkipInput)
+	gen.write("\t\tInstructions: %sInstructions,\n", name)
+	gen.write("\t\tPromptTemplate: prompt,\n")
+	if gen.partials {
+		gen.write("\t\tPromptPartials: promptPartials,\n")
+	}
+	if action.StrictTemplate {
+		gen.write("\t\tStrictTemplate: true,\n")
+	}
+	if action.PromptVersion != "" {
+		gen.write("\t\tPromptVersion: %q,\n", action.PromptVersion)
+	}
+	gen.generateExamples(action)
+	gen.write("\t\tInput: in,\n")
+	gen.write("\t\tOutput: %s,\n", out)
+	gen.write("\t\tInputSchema: %sSchema ,\n", gen.typeName(action.Input))
+
+	// Free-text actions have no output schema
+	if !action.IsTextOutput() {
+		gen.write("\t\tOutputSchema: %sSchema ,\n", gen.typeName(action.Output))
+	}
+
+	if cfg := agent.ActionModel(action); !cfg.IsZero() {
+		gen.generateModelOptions(&cfg)
+	}
+
+	if gen.rules[action.Output] {
+		gen.write("\t\tPostProcessors: []runtime.PostProcessor{%sRules},\n", gen.typeName(action.Output))
+	}
+
+	if len(agent.ActionTools(action)) > 0 {
+		toolsSpec := name + "ToolsSpec"
+		if action.Tools != nil {
+			toolsSpec = name + CapitalizeFirst(actionName) + "ToolsSpec"
+		}
+
+		gen.write("\t\tToolUnmarshaller: c.unmarshaller,\n")
+		gen.write("\t\tToolInvoker: c.toolsInvoker,\n")
+		gen.write("\t\tToolSpecs: %s,\n", toolsSpec)
+	}
+}
+
+// generateExamples generates the few-shot examples of action, as raw JSON values.
+func (gen *CodeGenerator) generateExamples(action *spec.Actions) {
+	if len(action.Examples) == 0 {
+		return
+	}
+
+	gen.write("\t\tExamples: []runtime.Example{\n")
+	for _, example := range action.Examples {
+		input, _ := json.Marshal(example.Input)
+
+		output := fmt.Sprintf("%q", example.Output)
+		if !action.IsTextOutput() {
+			data, _ := json.Marshal(example.Output)
+			output = fmt.Sprintf("json.RawMessage(`%s`)", escapeBackticks(string(data)))
+		}
+		gen.write("\t\t\t{Input: json.RawMessage(`%s`), Output: %s},\n", escapeBackticks(string(input)), output)
+	}
+	gen.write("\t\t},\n")
+}
+
+// actionSignature returns the parameters and results of the method generated for action.
+func (gen *CodeGenerator) actionSignature(action *spec.Actions) string {
+	inType, outType := gen.typeName(action.Input), gen.actionOutputType(action)
+
+	switch {
+	case action.Stream:
+		return fmt.Sprintf("(ctx context.Context, in *%s) *runtime.Stream[%s]", inType, outType)
+	case action.IsTextOutput():
+		return fmt.Sprintf("(ctx context.Context, in *%s) (string, error)", inType)
+	}
+	return fmt.Sprintf("(ctx context.Context, in *%s) (*%s, error)", inType, outType)
+}
+
+// actionOutputType returns the Go type of the output of action.
+func (gen *CodeGenerator) actionOutputType(action *spec.Actions) string {
+	if action.IsTextOutput() {
+		return "string"
+	}
+	return gen.typeName(action.Output)
+}
+
+func (gen *CodeGenerator) generateModelOptions(cfg *spec.ModelConfig) {
+	gen.write("\t\tModelOptions: runtime.ModelOptions{\n")
+	if cfg.Model != "" {
+		gen.write("\t\t\tModel: %q,\n", cfg.Model)
+	}
+	if cfg.Temperature != nil {
+		gen.write("\t\t\tTemperature: runtime.Float64(%v),\n", *cfg.Temperature)
+	}
+	if cfg.MaxTokens > 0 {
+		gen.write("\t\t\tMaxTokens: %d,\n", cfg.MaxTokens)
+	}
+	gen.write("\t\t},\n")
 }
 
-func (gen *CodeGenerator) generateToolsSpec(name string, tools []string, toolsMap map[string]spec.Tool) {
+func (gen *CodeGenerator) generateToolsSpec(varName string, tools []string, toolsMap map[string]spec.Tool) {
 	if len(tools) == 0 {
 		return
 	}
 
-	gen.write("var %sToolsSpec = []runtime.ToolSpec{", name)
+	gen.write("var %s = []runtime.ToolSpec{", varName)
 	for _, name := range tools {
 		t := toolsMap[name]
-		gen.write("{Name: \"%s\", Description: \"%s\", Schema: %sSchema},", CapitalizeFirst(name), t.Description, t.Input)
+		gen.write("{Name: %q, Description: %q, Schema: %sSchema", CapitalizeFirst(name), t.Description, gen.typeName(t.Input))
+		if t.RequiresApproval() {
+			gen.write(", RequiresApproval: true")
+		}
+		gen.write("},")
 	}
 	gen.write("}\n\n")
 }
@@ -257,7 +732,7 @@ func (gen *CodeGenerator) generateToolsInterface(name string, tools []string, to
 	for _, toolName := range tools {
 		tool := toolsMap[toolName]
 
-		gen.write("%s(ctx context.Context, in *%s) (*%s, error)\n", CapitalizeFirst(toolName), tool.Input, tool.Output)
+		gen.write("%s(ctx context.Context, in *%s) (*%s, error)\n", CapitalizeFirst(toolName), gen.typeName(tool.Input), gen.typeName(tool.Output))
 	}
 
 	gen.write("}\n\n")
@@ -268,16 +743,16 @@ func (gen *CodeGenerator) generateUnmarshaller(name string, tools []string, tool
 		return
 	}
 
-	gen.write("\nfunc (a *%s) unmarshaller(method string, data []byte) (any, error) {\n", name)
+	gen.write("\nfunc (a *%sClient) unmarshaller(method string, data []byte) (any, error) {\n", name)
 	gen.write("\tswitch method {\n")
 
 	for _, name := range tools {
 		tool := toolsMap[name]
-		gen.write("\t\t case \"%s\":\n\t\t\tvar payload %s\n\t\t\terr := runtime.UnmarshalValidate(data, &payload, %sSchema)\n\t\t\treturn &payload, err\n", name, tool.Input, tool.Input)
+		gen.write("\t\t case \"%s\":\n\t\t\tvar payload %s\n\t\t\terr := runtime.UnmarshalValidate(data, &payload, %sSchema)\n\t\t\treturn &payload, err\n", name, gen.typeName(tool.Input), gen.typeName(tool.Input))
 	}
 
 	gen.write("\t}\n")
-	gen.write("\n\t" + `return nil, fmt.Errorf("no such tool: \"%s\"", method)`)
+	gen.write("\n\treturn nil, fmt.Errorf(%q, method)", `no such tool: "%s"`)
 	gen.write("\n}\n\n")
 }
 
@@ -286,25 +761,31 @@ func (gen *CodeGenerator) generateToolsInvoker(name string, tools []string, tool
 		return
 	}
 
-	gen.write("\nfunc (a *%s) toolsInvoker(ctx context.Context, name string, in any) (any, error) {\n", name)
+	gen.write("\nfunc (a *%sClient) toolsInvoker(ctx context.Context, name string, in any) (any, error) {\n", name)
 	gen.write("\tswitch name {\n")
 
 	for _, name := range tools {
 		tool := toolsMap[name]
-		gen.write("\t\t case \"%s\":\n\t\t\treturn a.tools.%s(ctx, in.(*%s))\n", name, name, tool.Input)
+		gen.write("\t\t case \"%s\":\n\t\t\treturn a.tools.%s(ctx, in.(*%s))\n", name, name, gen.typeName(tool.Input))
 	}
 
 	gen.write("\t}\n")
-	gen.write("\n\t" + `return nil, fmt.Errorf("no such tool: \"%s\"", name)`)
+	gen.write("\n\treturn nil, fmt.Errorf(%q, name)", `no such tool: "%s"`)
 	gen.write("\n}\n\n")
 }
 
-func packageName(full string) string {
-	parts := strings.Split(full, ".")
-	return parts[len(parts)-1]
+// sortedKeys returns the keys of m in lexicographic order, so that generated code is deterministic.
+func sortedKeys[T any](m map[string]T) []string {
+	return slices.Sorted(maps.Keys(m))
+}
+
+// packageName returns the name of the Go package with the given import path, i.e. its last element.
+func packageName(path string) string {
+	return path[strings.LastIndex(path, "/")+1:]
 }
 
-func toCamelCase(s string) string {
+// ToCamelCase returns the name of the struct field generated for a message field.
+func ToCamelCase(s string) string {
 	parts := strings.Split(s, "_")
 	for i, p := range parts {
 		if len(p) == 0 {
@@ -315,31 +796,12 @@ func toCamelCase(s string) string {
 	return strings.Join(parts, "")
 }
 
-func goTypeForField(f spec.Field, enums map[string]spec.Enum) string {
-	var goType string
-	switch f.Type {
-	case "string":
-		goType = "string"
-	case "int", "int32", "int64":
-		goType = "int"
-	case "float", "float32", "float64":
-		goType = "float64"
-	case "bool":
-		goType = "bool"
-	case "datetime":
-		goType = "time.Time" // RFC3339 format
-	default:
-		// Check if it's an enum type
-		if _, isEnum := enums[f.Type]; isEnum {
-			goType = f.Type // Use the enum type name directly
-		} else {
-			// Custom message type
-			goType = f.Type
-		}
-	}
+func (gen *CodeGenerator) goTypeForField(f spec.Field) string {
+	goType := gen.goTypeForName(f.Type)
 
-	// Pointer for optional scalar or custom type (but not slices)
-	if f.Optional && !f.Repeated {
+	// Pointer for optional scalar or custom type (but not slices or maps)
+	_, _, isMap := spec.ParseMapType(f.Type)
+	if f.Optional && !f.Repeated && !isMap {
 		goType = "*" + goType
 	}
 
@@ -349,6 +811,38 @@ func goTypeForField(f spec.Field, enums map[string]spec.Enum) string {
 	return goType
 }
 
+func (gen *CodeGenerator) goTypeForName(t string) string {
+	if _, value, ok := spec.ParseMapType(t); ok {
+		return "map[string]" + gen.goTypeForName(value)
+	}
+
+	switch t {
+	case "string":
+		return "string"
+	case "int", "int32", "int64":
+		return "int"
+	case "float", "float32", "float64":
+		return "float64"
+	case "decimal", "money":
+		return "runtime.Decimal" // Fixed-point, encoded as a string
+	case "bool":
+		return "bool"
+	case "datetime":
+		return "time.Time" // RFC3339 format
+	case "uuid", "url":
+		return "string" // Validated by the JSON Schema format
+	case "bytes":
+		return "[]byte" // base64
+	case "image":
+		return "runtime.Attachment" // Data URL, sent to the model as an attachment
+	case "file":
+		return "runtime.Attachment" // Data URL, whose text is extracted into the prompt
+	default:
+		// Enum and message types use the type name directly, qualified if imported
+		return gen.typeName(t)
+	}
+}
+
 func escapeBackticks(s string) string {
 	return strings.ReplaceAll(s, "`", "` + \"`\" + `")
 }
@@ -0,0 +1,915 @@
+// Copyright (c) 2025 Suricata Contributors
+// Original Author: Stefano Scafiti
+//
+// This file is part of Suricata: Type-Safe AI Agents for Go.
+//
+// Licensed under the MIT License. You may obtain a copy of the License at
+//
+//	https://opensource.org/licenses/MIT
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package gen_test
+
+import (
+	"bytes"
+	"encoding/json"
+	"go/format"
+	"os"
+	"path/filepath"
+	"regexp"
+	"strings"
+	"testing"
+
+	"github.com/ostafen/suricata/pkg/gen"
+	"github.com/ostafen/suricata/pkg/spec"
+	"github.com/ostafen/suricata/runtime"
+)
+
+const testSpec = `
+version: 0.0.1
+package: example.shop
+enums:
+  Status:
+    values: [OPEN, CLOSED]
+messages:
+  Query:
+    fields:
+      - name: text
+        type: string
+      - name: since
+        type: datetime
+        optional: true
+  Result:
+    fields:
+      - name: status
+        type: Status
+      - name: tags
+        type: map<string, string>
+      - name: total
+        type: money
+    rules:
+      - status != "CLOSED"
+tools:
+  Search:
+    description: Searches "everything"
+    input: Query
+    output: Result
+    approval: required
+agents:
+  shop:
+    tools: [Search]
+    actions:
+      Find:
+        input: Query
+        output: Result
+        prompt: Find {{.Text}}.
+      Describe:
+        input: Query
+        prompt: Describe {{.Text}}.
+        stream: true
+workflows:
+  lookup:
+    description: finds and describes a query.
+    input: Query
+    steps:
+      - name: find
+        agent: shop
+        action: Find
+        optional: true
+      - name: describe
+        agent: shop
+        action: Describe
+        input:
+          text: input.text
+          since: input.since
+`
+
+func loadSpec(t *testing.T) *spec.Spec {
+	t.Helper()
+
+	path := filepath.Join(t.TempDir(), "shop.yml")
+	if err := os.WriteFile(path, []byte(testSpec), 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	s, err := spec.LoadSpec(path)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	return s
+}
+
+func TestGenerate_Deterministic(t *testing.T) {
+	generators := map[string]func(*gen.CodeGenerator, *spec.Spec) ([]byte, error){
+		"code":  (*gen.CodeGenerator).Generate,
+		"mocks": (*gen.CodeGenerator).GenerateMocks,
+		"mcp":   (*gen.CodeGenerator).GenerateMCP,
+		"http":  (*gen.CodeGenerator).GenerateHTTP,
+	}
+
+	for name, generate := range generators {
+		t.Run(name, func(t *testing.T) {
+			var first []byte
+			for i := 0; i < 5; i++ {
+				g := gen.CodeGenerator{Header: "Copyright (c) Shop\n\nLicensed under MIT.\n"}
+
+				src, err := generate(&g, loadSpec(t))
+				if err != nil {
+					t.Fatalf("unexpected error: %v\n%s", err, src)
+				}
+
+				if i == 0 {
+					first = src
+				} else if !bytes.Equal(first, src) {
+					t.Fatalf("output differs between runs")
+				}
+			}
+
+			formatted, err := format.Source(first)
+			if err != nil {
+				t.Fatalf("invalid code: %v", err)
+			}
+			if !bytes.Equal(formatted, first) {
+				t.Errorf("output is not gofmt-formatted")
+			}
+
+			expectedPrefix := "// Copyright (c) Shop\n//\n// Licensed under MIT.\n\n" + gen.Banner + "\n\npackage shop\n"
+			if !strings.HasPrefix(string(first), expectedPrefix) {
+				t.Errorf("unexpected preamble:\n%s", first[:min(len(first), len(expectedPrefix))])
+			}
+		})
+	}
+}
+
+func TestGenerate_Workflow(t *testing.T) {
+	var g gen.CodeGenerator
+
+	src, err := g.Generate(loadSpec(t))
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	for _, expected := range []string{
+		"func NewLookupWorkflow(shopAgent ShopAgent) *LookupWorkflow {",
+		"func (w *LookupWorkflow) Run(ctx context.Context, in *Query) (string, error) {",
+		"\t_, err := w.ShopAgent.Find(ctx, findIn)\n",
+		"\tif err != nil && w.OnStepError != nil {\n\t\tw.OnStepError(ctx, \"find\", err)\n",
+		"\tdescribeIn.Since = in.Since\n",
+		"\tdescribeOut, err := w.ShopAgent.Describe(ctx, describeIn).Result()\n",
+		"&runtime.StepError{Workflow: \"lookup\", Step: \"describe\", Err: err}",
+		"\treturn *describeOut, nil\n",
+	} {
+		if !strings.Contains(string(src), expected) {
+			t.Errorf("expected generated code to contain %q", expected)
+		}
+	}
+}
+
+func TestGenerate_Decimal(t *testing.T) {
+	var g gen.CodeGenerator
+
+	src, err := g.Generate(loadSpec(t))
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	if !regexp.MustCompile("Total +runtime.Decimal +`json:\"total\"`").Match(src) {
+		t.Errorf("expected money fields to be runtime.Decimal, got:\n%s", src)
+	}
+}
+
+func TestGenerate_NestedMaps(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "matrix.yml")
+	err := os.WriteFile(path, []byte(`
+version: 0.0.1
+package: matrix
+enums:
+  Level:
+    values: [LOW, HIGH]
+messages:
+  Matrix:
+    fields:
+      - name: cells
+        type: map<string, map<string, Level>>
+`), 0644)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	s, err := spec.LoadSpec(path)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	var g gen.CodeGenerator
+	src, err := g.Generate(s)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if !regexp.MustCompile("Cells +map\\[string\\]map\\[string\\]Level +`json:\"cells\"`").Match(src) {
+		t.Errorf("expected a nested map field, got:\n%s", src)
+	}
+
+	files, err := gen.GenerateSchemas(s)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	var schema struct {
+		Properties struct {
+			Cells struct {
+				Type                 string `json:"type"`
+				AdditionalProperties struct {
+					Type                 string `json:"type"`
+					AdditionalProperties struct {
+						Enum []string `json:"enum"`
+					} `json:"additionalProperties"`
+				} `json:"additionalProperties"`
+			} `json:"cells"`
+		} `json:"properties"`
+	}
+	if err := json.Unmarshal(files["Matrix.schema.json"], &schema); err != nil {
+		t.Fatalf("invalid schema: %v", err)
+	}
+	if cells := schema.Properties.Cells; cells.Type != "object" || cells.AdditionalProperties.Type != "object" || len(cells.AdditionalProperties.AdditionalProperties.Enum) != 2 {
+		t.Errorf("expected a nested map schema, got %+v", cells)
+	}
+}
+
+func TestGenerate_Union(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "payment.yml")
+	err := os.WriteFile(path, []byte(`
+version: 0.0.1
+package: payment
+messages:
+  Card:
+    fields:
+      - name: number
+        type: string
+  PayPal:
+    fields:
+      - name: email
+        type: string
+  PaymentMethod:
+    oneof: [Card, PayPal]
+`), 0644)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	s, err := spec.LoadSpec(path)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	var g gen.CodeGenerator
+	src, err := g.Generate(s)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	for _, expected := range []string{
+		"PaymentMethod struct {\n\t\tCard   *Card   `json:\"-\"`\n\t\tPayPal *PayPal `json:\"-\"`\n\t}",
+		"func (u PaymentMethod) MarshalJSON() ([]byte, error) {",
+		"\t\treturn json.Marshal(envelope{Type: \"PayPal\", Value: u.PayPal})\n",
+		"func (u *PaymentMethod) UnmarshalJSON(data []byte) error {",
+		"\t\tu.Card = new(Card)\n",
+	} {
+		if !strings.Contains(string(src), expected) {
+			t.Errorf("expected generated code to contain %q, got:\n%s", expected, src)
+		}
+	}
+
+	files, err := gen.GenerateSchemas(s)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	schema := runtime.NewSchema(string(files["PaymentMethod.schema.json"]))
+
+	// Exactly one variant must match the envelope
+	for payload, valid := range map[string]bool{
+		`{"type": "Card", "value": {"number": "4111"}}`:         true,
+		`{"type": "PayPal", "value": {"email": "a@b.c"}}`:       true,
+		`{"type": "Cash", "value": {}}`:                         false,
+		`{"type": "Card", "value": {"number": 4111}}`:           false,
+		`{"Card": {"number": "4111"}, "PayPal": {"email": ""}}`: false,
+	} {
+		if err := runtime.ValidateRawJSON([]byte(payload), schema); (err == nil) != valid {
+			t.Errorf("%s: expected valid=%t, got %v", payload, valid, err)
+		}
+	}
+}
+
+func TestGenerate_Enums(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "tasks.yml")
+	err := os.WriteFile(path, []byte(`
+version: 0.0.1
+package: tasks
+enums:
+  State:
+    values: [todo, in-progress, DONE]
+messages:
+  Task:
+    fields:
+      - name: state
+        type: State
+      - name: previous
+        type: State
+        optional: true
+`), 0644)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	s, err := spec.LoadSpec(path)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	var g gen.CodeGenerator
+	src, err := g.Generate(s)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	for _, expected := range []string{
+		"\tState string\n",
+		"\tStateTodo       State = \"todo\"\n\tStateInProgress State = \"in-progress\"\n\tStateDONE       State = \"DONE\"\n",
+		"var StateValues = []State{StateTodo, StateInProgress, StateDONE}",
+		"func (e State) IsValid() bool {\n\tswitch e {\n\tcase StateTodo, StateInProgress, StateDONE:\n",
+		"func (e State) Validate() error {",
+		"func (e *State) UnmarshalJSON(data []byte) error {",
+		"Previous *State `json:\"previous,omitempty\"`",
+	} {
+		if !strings.Contains(string(src), expected) {
+			t.Errorf("expected generated code to contain %q, got:\n%s", expected, src)
+		}
+	}
+
+	files, err := gen.GenerateSchemas(s)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	schema := runtime.NewSchema(string(files["Task.schema.json"]))
+
+	for payload, valid := range map[string]bool{
+		`{"state": "in-progress"}`:              true,
+		`{"state": "todo", "previous": "DONE"}`: true,
+		`{"state": "blocked"}`:                  false,
+		`{"state": "todo", "previous": "done"}`: false,
+	} {
+		if err := runtime.ValidateRawJSON([]byte(payload), schema); (err == nil) != valid {
+			t.Errorf("%s: expected valid=%t, got %v", payload, valid, err)
+		}
+	}
+}
+
+func TestGenerate_Rules(t *testing.T) {
+	var g gen.CodeGenerator
+
+	src, err := g.Generate(loadSpec(t))
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	for _, expected := range []string{
+		"var ResultRules = runtime.CheckRules(\n\truntime.MustParseRule(\"status != \\\"CLOSED\\\"\"),\n)\n",
+		"PostProcessors:   []runtime.PostProcessor{ResultRules},\n",
+	} {
+		if !strings.Contains(string(src), expected) {
+			t.Errorf("expected generated code to contain %q", expected)
+		}
+	}
+}
+
+func TestGenerate_ImportedPackage(t *testing.T) {
+	dir := t.TempDir()
+	err := os.WriteFile(filepath.Join(dir, "common.yml"), []byte(`
+version: 0.0.1
+package: common
+go_package: github.com/acme/app/common
+messages:
+  Location:
+    fields:
+      - name: city
+        type: string
+`), 0644)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	path := filepath.Join(dir, "trip.yml")
+	err = os.WriteFile(path, []byte(`
+version: 0.0.1
+package: trip
+imports: [common.yml]
+messages:
+  Trip:
+    fields:
+      - name: to
+        type: Location
+agents:
+  planner:
+    actions:
+      Locate:
+        input: Trip
+        output: Location
+`), 0644)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	s, err := spec.LoadSpec(path)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	var g gen.CodeGenerator
+	src, err := g.Generate(s)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	for _, expected := range []string{
+		"\"github.com/acme/app/common\"",
+		"To common.Location `json:\"to\"`",
+		"(*common.Location, error)",
+		"OutputSchema:   common.LocationSchema,",
+	} {
+		if !strings.Contains(string(src), expected) {
+			t.Errorf("expected generated code to contain %q", expected)
+		}
+	}
+	if strings.Contains(string(src), "Location struct") || strings.Contains(string(src), "\tLocationSchema") {
+		t.Errorf("expected the imported Location not to be generated:\n%s", src)
+	}
+}
+
+func TestGenerate_Routes(t *testing.T) {
+	var g gen.CodeGenerator
+
+	src, err := g.Generate(loadSpec(t))
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	for _, expected := range []string{
+		"func (c *ShopAgentClient) Routes() []runtime.Route {",
+		"Agent:       \"shop\",\n\t\t\tAction:      \"Describe\",",
+		"\t\t\t\treturn c.Describe(ctx, &in).Result()\n",
+		"\t\t\t\treturn c.Find(ctx, &in)\n",
+	} {
+		if !strings.Contains(string(src), expected) {
+			t.Errorf("expected generated code to contain %q", expected)
+		}
+	}
+}
+
+func TestGenerate_ToolApproval(t *testing.T) {
+	var g gen.CodeGenerator
+
+	src, err := g.Generate(loadSpec(t))
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	for _, expected := range []string{
+		`{Name: "Search", Description: "Searches \"everything\"", Schema: QuerySchema, RequiresApproval: true}`,
+		"func NewShopAgent(invoker runtime.Invoker, tools ShopAgentTools, opts ...runtime.Option) *ShopAgentClient {",
+	} {
+		if !strings.Contains(string(src), expected) {
+			t.Errorf("expected generated code to contain %q", expected)
+		}
+	}
+}
+
+func TestGenerate_AgentInterface(t *testing.T) {
+	var g gen.CodeGenerator
+
+	src, err := g.Generate(loadSpec(t))
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	for _, expected := range []string{
+		"type ShopAgent interface {\n\tDescribe(ctx context.Context, in *Query) *runtime.Stream[string]\n\tFind(ctx context.Context, in *Query) (*Result, error)\n}",
+		"type ShopAgentClient struct {",
+		"var _ ShopAgent = (*ShopAgentClient)(nil)",
+	} {
+		if !strings.Contains(string(src), expected) {
+			t.Errorf("expected generated code to contain %q", expected)
+		}
+	}
+
+	mocks, err := g.GenerateMocks(loadSpec(t))
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if !strings.Contains(string(mocks), "var _ ShopAgent = (*MockShopAgent)(nil)") {
+		t.Errorf("expected the mock to implement the agent interface")
+	}
+}
+
+func TestGenerateMocks(t *testing.T) {
+	var g gen.CodeGenerator
+
+	src, err := g.GenerateMocks(loadSpec(t))
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	for _, expected := range []string{
+		"type MockShopAgentTools struct {\n\tSearchFunc func(ctx context.Context, in *Query) (*Result, error)\n}",
+		"func (m *MockShopAgentTools) Search(ctx context.Context, in *Query) (*Result, error) {\n\tif m.SearchFunc == nil {\n\t\treturn nil, fmt.Errorf(\"MockShopAgentTools.Search: not implemented\")\n\t}\n\treturn m.SearchFunc(ctx, in)\n}",
+		"\tDescribeFunc func(ctx context.Context, in *Query) *runtime.Stream[string]\n",
+		"\t\treturn runtime.StreamError[string](fmt.Errorf(\"MockShopAgent.Describe: not implemented\"))\n",
+		"\t\treturn nil, fmt.Errorf(\"MockShopAgent.Find: not implemented\")\n",
+		"var _ ShopAgent = (*MockShopAgent)(nil)",
+	} {
+		if !strings.Contains(string(src), expected) {
+			t.Errorf("expected generated mocks to contain %q, got:\n%s", expected, src)
+		}
+	}
+
+	// Mocks belong to the package of the generated agents
+	if !strings.Contains(string(src), "package shop\n") {
+		t.Errorf("expected the package of the agents, got:\n%s", src)
+	}
+}
+
+func TestGenerateSchemas(t *testing.T) {
+	files, err := gen.GenerateSchemas(loadSpec(t))
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	if len(files) != 2 || files["Query.schema.json"] == nil || files["Result.schema.json"] == nil {
+		t.Fatalf("expected a schema file for each message, got %d files", len(files))
+	}
+
+	var schema map[string]any
+	if err := json.Unmarshal(files["Result.schema.json"], &schema); err != nil {
+		t.Fatalf("invalid schema: %v", err)
+	}
+	if schema["$schema"] != gen.SchemaDialect || schema["title"] != "Result" {
+		t.Errorf("expected the dialect and the title of the schema, got %v", schema)
+	}
+
+	status := schema["properties"].(map[string]any)["status"].(map[string]any)
+	if status["type"] != "string" || len(status["enum"].([]any)) != 2 {
+		t.Errorf("expected the enum to be inlined, got %v", status)
+	}
+
+	total := schema["properties"].(map[string]any)["total"].(map[string]any)
+	if total["type"] != "string" || total["format"] != "decimal" {
+		t.Errorf("expected money to be a decimal string, got %v", total)
+	}
+}
+
+func TestGenerateSchemas_Constraints(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "booking.yml")
+	err := os.WriteFile(path, []byte(`
+version: 0.0.1
+package: booking
+messages:
+  Booking:
+    fields:
+      - name: day
+        type: string
+        format: date
+      - name: created_at
+        type: datetime
+        format: date-time
+      - name: price
+        type: float
+        min: 0
+      - name: code
+        type: string
+        pattern: ^[A-Z]{3}$
+`), 0644)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	s, err := spec.LoadSpec(path)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	files, err := gen.GenerateSchemas(s)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	var schema struct {
+		Properties map[string]map[string]any `json:"properties"`
+	}
+	if err := json.Unmarshal(files["Booking.schema.json"], &schema); err != nil {
+		t.Fatalf("invalid schema: %v", err)
+	}
+
+	for name, expected := range map[string]map[string]any{
+		"day":        {"type": "string", "format": "date"},
+		"created_at": {"type": "string", "format": "date-time"},
+		"price":      {"type": "number", "minimum": 0.0},
+		"code":       {"type": "string", "pattern": "^[A-Z]{3}$"},
+	} {
+		for key, value := range expected {
+			if got := schema.Properties[name][key]; got != value {
+				t.Errorf("%s: expected %s %v, got %v", name, key, value, got)
+			}
+		}
+	}
+}
+
+func TestGenerateSchemas_Recursive(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "catalog.yml")
+	err := os.WriteFile(path, []byte(`
+version: 0.0.1
+package: catalog
+messages:
+  Category:
+    fields:
+      - name: name
+        type: string
+      - name: children
+        type: Category
+        repeated: true
+      - name: parent
+        type: Category
+        optional: true
+  Catalog:
+    fields:
+      - name: root
+        type: Category
+`), 0644)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	s, err := spec.LoadSpec(path)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	files, err := gen.GenerateSchemas(s)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	var schema map[string]any
+	if err := json.Unmarshal(files["Catalog.schema.json"], &schema); err != nil {
+		t.Fatalf("invalid schema: %v", err)
+	}
+	root := schema["properties"].(map[string]any)["root"].(map[string]any)
+	if root["$ref"] != "#/$defs/Category" {
+		t.Errorf("expected a reference to the recursive message, got %v", root)
+	}
+	if _, ok := schema["$defs"].(map[string]any)["Category"]; !ok {
+		t.Errorf("expected the recursive message to be defined, got %v", schema["$defs"])
+	}
+
+	loader := runtime.NewSchema(string(files["Catalog.schema.json"]))
+	if err := runtime.ValidateRawJSON([]byte(`{"root": {"name": "a", "children": [{"name": "b", "children": [{"name": "c", "children": []}]}]}}`), loader); err != nil {
+		t.Errorf("unexpected error: %v", err)
+	}
+	if err := runtime.ValidateRawJSON([]byte(`{"root": {"name": "a", "children": [{"children": []}]}}`), loader); err == nil {
+		t.Errorf("expected nested values to be validated")
+	}
+
+	var g gen.CodeGenerator
+	src, err := g.Generate(s)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if !regexp.MustCompile(`Parent +\*Category`).Match(src) || !regexp.MustCompile(`Children +\[\]Category`).Match(src) {
+		t.Errorf("expected self references through a pointer and a slice, got:\n%s", src)
+	}
+}
+
+func TestGenerateSchemas_SharedDefs(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "travel.yml")
+	err := os.WriteFile(path, []byte(`
+version: 0.0.1
+package: travel
+messages:
+  Location:
+    fields:
+      - name: city
+        type: string
+  Trip:
+    fields:
+      - name: from
+        type: Location
+      - name: to
+        type: Location
+        description: The destination
+      - name: budget
+        type: Budget
+  Budget:
+    fields:
+      - name: amount
+        type: int
+  Quote:
+    fields:
+      - name: budget
+        type: Budget
+`), 0644)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	s, err := spec.LoadSpec(path)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	files, err := gen.GenerateSchemas(s)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	var schema map[string]any
+	if err := json.Unmarshal(files["Trip.schema.json"], &schema); err != nil {
+		t.Fatalf("invalid schema: %v", err)
+	}
+
+	props := schema["properties"].(map[string]any)
+	if to := props["to"].(map[string]any); to["$ref"] != "#/$defs/Location" || to["description"] != "The destination" {
+		t.Errorf("expected a described reference to the shared message, got %v", to)
+	}
+	if budget := props["budget"].(map[string]any); budget["type"] != "object" {
+		t.Errorf("expected a message used once by the schema to be inlined, got %v", budget)
+	}
+	if defs := schema["$defs"].(map[string]any); len(defs) != 1 || defs["Location"] == nil {
+		t.Errorf("expected the shared message to be defined once, got %v", defs)
+	}
+
+	loader := runtime.NewSchema(string(files["Trip.schema.json"]))
+	if err := runtime.ValidateRawJSON([]byte(`{"from": {"city": "Milan"}, "to": {}, "budget": {"amount": 1}}`), loader); err == nil {
+		t.Errorf("expected referenced messages to be validated")
+	}
+}
+
+func TestGenerateTypeScript(t *testing.T) {
+	var g gen.CodeGenerator
+
+	src, err := g.GenerateTypeScript(loadSpec(t))
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	for _, expected := range []string{
+		"// Code generated by suricata-gen; DO NOT EDIT.\n",
+		`export type Status = "OPEN" | "CLOSED";`,
+		"export interface Query {\n  text: string;\n  since?: string;\n}\n",
+		"export interface Result {\n  status: Status;\n  tags: Record<string, string>;\n  total: string;\n}\n",
+		"export class ShopAgentClient {\n",
+		"  find(input: Query, signal?: AbortSignal): Promise<Result> {\n" +
+			"    return call<Result>(this.baseURL, \"/Find\", input, this.options, signal);\n",
+		"  describe(input: Query, onDelta?: DeltaHandler, signal?: AbortSignal): Promise<string> {\n" +
+			"    return stream<string>(this.baseURL, \"/Describe\", input, this.options, onDelta, signal);\n",
+	} {
+		if !strings.Contains(string(src), expected) {
+			t.Errorf("expected generated code to contain %q", expected)
+		}
+	}
+}
+
+func TestGeneratePython(t *testing.T) {
+	var g gen.CodeGenerator
+
+	src, err := g.GeneratePython(loadSpec(t))
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	for _, expected := range []string{
+		"# Code generated by suricata-gen; DO NOT EDIT.\n",
+		`Status = Literal["OPEN", "CLOSED"]`,
+		"class Query(BaseModel):\n    text: str\n    since: Optional[datetime] = None\n",
+		"class Result(BaseModel):\n    status: Status\n    tags: Dict[str, str]\n",
+		"class ShopAgentClient(_Client):\n",
+		"    def find(self, input: Query) -> Result:\n        return self._call(\"/Find\", input, Result)\n",
+		"    def describe(self, input: Query, on_delta: Optional[Callable[[str], None]] = None) -> str:\n" +
+			"        return self._stream(\"/Describe\", input, str, on_delta)\n",
+	} {
+		if !strings.Contains(string(src), expected) {
+			t.Errorf("expected generated code to contain %q", expected)
+		}
+	}
+}
+
+func TestToSnakeCase(t *testing.T) {
+	for in, want := range map[string]string{
+		"SayHelloAll":  "say_hello_all",
+		"find":         "find",
+		"ParseHTTPUrl": "parse_http_url",
+		"GetID":        "get_id",
+	} {
+		if got := gen.ToSnakeCase(in); got != want {
+			t.Errorf("ToSnakeCase(%q) = %q, want %q", in, got, want)
+		}
+	}
+}
+
+func TestGenerateFile(t *testing.T) {
+	dir := t.TempDir()
+
+	specPath := filepath.Join(dir, "shop.yml")
+	if err := os.WriteFile(specPath, []byte(testSpec), 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	files, err := gen.GenerateFile(specPath, gen.FileOptions{Out: dir, Module: "example", Mock: true, SchemasOut: filepath.Join(dir, "schemas")})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	expected := []string{
+		filepath.Join(dir, "shop", "shop.go"),
+		filepath.Join(dir, "shop", "shop_mock.go"),
+		filepath.Join(dir, "schemas", "Query.schema.json"),
+		filepath.Join(dir, "schemas", "Result.schema.json"),
+	}
+	if strings.Join(files, ",") != strings.Join(expected, ",") {
+		t.Fatalf("expected files %v, got %v", expected, files)
+	}
+	for _, f := range files {
+		if _, err := os.Stat(f); err != nil {
+			t.Errorf("expected %s to be written: %v", f, err)
+		}
+	}
+
+	files, err = gen.GenerateFile(specPath, gen.FileOptions{Out: dir, Flat: true, Package: "shop"})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if len(files) != 1 || files[0] != filepath.Join(dir, "shop.go") {
+		t.Errorf("expected the code to be written to the output directory, got %v", files)
+	}
+
+	_, err = gen.GenerateFile(specPath, gen.FileOptions{Out: dir, Flat: true, Package: "store"})
+	if err == nil || !strings.Contains(err.Error(), `does not match package "store"`) {
+		t.Errorf("expected a package mismatch error, got %v", err)
+	}
+
+	tsOut, pyOut := filepath.Join(dir, "ts"), filepath.Join(dir, "py")
+	if _, err := gen.GenerateFile(specPath, gen.FileOptions{Out: dir, Flat: true, TSOut: tsOut, PyOut: pyOut}); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	ts, err := os.ReadFile(filepath.Join(tsOut, "shop.ts"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !strings.Contains(string(ts), "export interface Query") || strings.Contains(string(ts), "BaseModel") {
+		t.Errorf("expected the TypeScript module not to be overwritten by the Python one, got:\n%s", ts)
+	}
+}
+
+func TestGenerateDocs(t *testing.T) {
+	s := loadSpec(t)
+
+	var rendered []string
+	render := func(agent, action string, input []byte) (string, error) {
+		rendered = append(rendered, agent+"."+action)
+		return "", nil
+	}
+
+	md, err := gen.GenerateMarkdown(s, render)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	for _, expected := range []string{
+		"# example.shop\n",
+		"### ShopAgent\n",
+		"#### Find\n\n- Input: [Query](#query)\n- Output: [Result](#result)\n- Tools: Search\n",
+		"```gotemplate\nFind {{.Text}}.\n```\n",
+		"| Search | Searches \"everything\" | [Query](#query) | [Result](#result) | required |\n",
+		"| since | datetime | no |  |\n",
+		"| tags | map&lt;string, string&gt; | yes |  |\n",
+		"Values: OPEN, CLOSED.\n",
+		"1. find: ShopAgent.Find (optional)\n2. describe: ShopAgent.Describe\n",
+	} {
+		if !strings.Contains(string(md), expected) {
+			t.Errorf("expected the Markdown documentation to contain %q", expected)
+		}
+	}
+	if len(rendered) > 0 {
+		t.Errorf("expected no prompts to be rendered without examples, got %v", rendered)
+	}
+
+	html, err := gen.GenerateHTML(s, nil)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if !strings.Contains(string(html), `<td>Searches &#34;everything&#34;</td>`) || !strings.Contains(string(html), `<a href="#status">Status</a>`) {
+		t.Errorf("unexpected HTML documentation:\n%s", html)
+	}
+}
@@ -0,0 +1,188 @@
+// Copyright (c) 2025 Suricata Contributors
+// Original Author: Stefano Scafiti
+//
+// This file is part of Suricata: Type-Safe AI Agents for Go.
+//
+// Licensed under the MIT License. You may obtain a copy of the License at
+//
+//	https://opensource.org/licenses/MIT
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package gen
+
+import (
+	"encoding/json"
+	"maps"
+
+	"github.com/ostafen/suricata/pkg/spec"
+)
+
+// GenerateHTTP generates, for each agent, an HTTP handler exposing its actions as JSON endpoints,
+// together with their OpenAPI description. The output belongs to the same package as the code produced by Generate.
+func (gen *CodeGenerator) GenerateHTTP(spec *spec.Spec) ([]byte, error) {
+	gen.buf.Reset()
+
+	gen.writePreamble(spec, "net/http", "github.com/ostafen/suricata/runtime/httpserve")
+
+	for _, name := range sortedKeys(spec.Agents) {
+		agent := spec.Agents[name]
+
+		doc, err := openAPIDocument(spec, name, &agent)
+		if err != nil {
+			return nil, err
+		}
+		gen.generateHTTPHandler(name, &agent, doc)
+	}
+
+	return gen.format()
+}
+
+func (gen *CodeGenerator) generateHTTPHandler(name string, agent *spec.Agent, doc []byte) {
+	typeName := getAgentTypeName(name)
+
+	gen.write("// %sOpenAPI is the OpenAPI description of the endpoints served by New%sHandler.\n", typeName, typeName)
+	gen.write("const %sOpenAPI = `%s`\n\n", typeName, escapeBackticks(string(doc)))
+
+	gen.write("// New%sHandler returns an HTTP handler exposing each action of agent as a POST endpoint.\n", typeName)
+	gen.write("// The OpenAPI description of the endpoints is served at /openapi.json.\n")
+	gen.write("func New%sHandler(agent %s) http.Handler {\n", typeName, typeName)
+	gen.write("\tmux := http.NewServeMux()\n")
+
+	for _, actionName := range sortedKeys(agent.Actions) {
+		action := agent.Actions[actionName]
+		inType := gen.typeName(action.Input)
+
+		if action.Stream {
+			gen.write("\tmux.Handle(\"POST /%s\", httpserve.StreamAction(%sSchema, agent.%s))\n", actionName, inType, CapitalizeFirst(actionName))
+			continue
+		}
+
+		gen.write("\tmux.Handle(\"POST /%s\", httpserve.Action(%sSchema, func(ctx context.Context, in *%s) (any, error) {\n", actionName, inType, inType)
+		gen.write("\t\treturn agent.%s(ctx, in)\n", CapitalizeFirst(actionName))
+		gen.write("\t}))\n")
+	}
+
+	gen.write("\tmux.Handle(\"GET /openapi.json\", httpserve.Document(%sOpenAPI))\n", typeName)
+	gen.write("\treturn mux\n")
+	gen.write("}\n\n")
+}
+
+// componentsRefPrefix prefixes the references to the schemas of an OpenAPI document.
+const componentsRefPrefix = "#/components/schemas/"
+
+// openAPIDocument returns the OpenAPI 3.1 description of the endpoints generated for an agent.
+func openAPIDocument(s *spec.Spec, name string, agent *spec.Agent) ([]byte, error) {
+	schemaGen := NewJSONSchemaGenerator()
+	schemas := make(map[string]any)
+
+	schemaRef := func(msgName string) (any, error) {
+		if _, ok := schemas[msgName]; !ok {
+			msg := s.Messages[msgName]
+			schema, err := schemaGen.GenerateJSONSchema(msgName, &msg, s.Messages, s.Enums)
+			if err != nil {
+				return nil, err
+			}
+
+			// Definitions of recursive messages become components, like the other messages
+			if defs, ok := schema["$defs"].(map[string]any); ok {
+				schema = maps.Clone(schema)
+				delete(schema, "$defs")
+				for defName, def := range defs {
+					schemas[defName] = rewriteRefs(def, componentsRefPrefix)
+				}
+			}
+			schemas[msgName] = rewriteRefs(schema, componentsRefPrefix)
+		}
+		return map[string]any{"$ref": componentsRefPrefix + msgName}, nil
+	}
+
+	// Avoid clashing with a message of the spec
+	errorName := "Error"
+	for {
+		if _, ok := s.Messages[errorName]; !ok {
+			break
+		}
+		errorName += "Response"
+	}
+
+	errorResponse := map[string]any{
+		"description": "Error",
+		"content": map[string]any{
+			"application/json": map[string]any{"schema": map[string]any{"$ref": componentsRefPrefix + errorName}},
+		},
+	}
+
+	paths := make(map[string]any)
+	for _, actionName := range sortedKeys(agent.Actions) {
+		action := agent.Actions[actionName]
+
+		inSchema, err := schemaRef(action.Input)
+		if err != nil {
+			return nil, err
+		}
+
+		var outSchema any = map[string]any{"type": "string"}
+		if !action.IsTextOutput() {
+			if outSchema, err = schemaRef(action.Output); err != nil {
+				return nil, err
+			}
+		}
+
+		okResponse := map[string]any{
+			"description": "Output of the action",
+			"content": map[string]any{
+				"application/json": map[string]any{"schema": outSchema},
+			},
+		}
+		if action.Stream {
+			okResponse = map[string]any{
+				"description": "Stream of \"delta\" events, followed by a \"result\" event carrying the output of the action, or an \"error\" event",
+				"content": map[string]any{
+					"text/event-stream": map[string]any{"schema": map[string]any{"type": "string"}},
+				},
+			}
+		}
+
+		op := map[string]any{
+			"operationId": actionName,
+			"requestBody": map[string]any{
+				"required": true,
+				"content": map[string]any{
+					"application/json": map[string]any{"schema": inSchema},
+				},
+			},
+			"responses": map[string]any{
+				"200": okResponse,
+				"400": errorResponse,
+				"500": errorResponse,
+			},
+		}
+		if action.Description != "" {
+			op["summary"] = action.Description
+		}
+		paths["/"+actionName] = map[string]any{"post": op}
+	}
+
+	schemas[errorName] = map[string]any{
+		"type":       "object",
+		"properties": map[string]any{"error": map[string]any{"type": "string"}},
+		"required":   []string{"error"},
+	}
+
+	info := map[string]any{"title": getAgentTypeName(name), "version": s.Version}
+	if agent.Instructions != "" {
+		info["description"] = agent.Instructions
+	}
+
+	return json.MarshalIndent(map[string]any{
+		"openapi":    "3.1.0",
+		"info":       info,
+		"paths":      paths,
+		"components": map[string]any{"schemas": schemas},
+	}, "", "  ")
+}
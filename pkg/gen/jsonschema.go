@@ -16,26 +16,160 @@
 package gen
 
 import (
+	"encoding/json"
 	"fmt"
+	"maps"
+	"slices"
+	"strings"
 
 	"github.com/ostafen/suricata/pkg/spec"
+	"github.com/ostafen/suricata/runtime"
 )
 
 type JSONSchema map[string]any
 
+// Patterns of the data URLs encoding image and file fields.
+const (
+	imageDataURLPattern = `^data:image/[^;]+;base64,`
+	fileDataURLPattern  = `^data:[^;]+;base64,`
+)
+
+// SchemaDialect is the JSON Schema draft of the generated schema files.
+const SchemaDialect = "http://json-schema.org/draft-07/schema#"
+
+// GenerateSchemas returns a standalone JSON Schema document for each message of the spec,
+// keyed by file name ("<Message>.schema.json"), so that other languages can validate the
+// payloads exchanged with the agents. Referenced messages and enums are inlined.
+func GenerateSchemas(s *spec.Spec) (map[string][]byte, error) {
+	schemaGen := NewJSONSchemaGenerator()
+
+	files := make(map[string][]byte, len(s.Messages))
+	for _, name := range sortedKeys(s.Messages) {
+		msg := s.Messages[name]
+		schema, err := schemaGen.GenerateJSONSchema(name, &msg, s.Messages, s.Enums)
+		if err != nil {
+			return nil, fmt.Errorf("message %q: %w", name, err)
+		}
+
+		// Copy the schema, since it may be shared by the messages referencing it
+		doc := maps.Clone(schema)
+		doc["$schema"] = SchemaDialect
+		doc["title"] = name
+
+		data, err := json.MarshalIndent(doc, "", "  ")
+		if err != nil {
+			return nil, err
+		}
+		files[name+".schema.json"] = append(data, '\n')
+	}
+	return files, nil
+}
+
+// defsRefPrefix prefixes the references to the definitions of a schema.
+const defsRefPrefix = "#/$defs/"
+
 type JSONSchemaGenerator struct {
-	schemas map[string]JSONSchema
+	schemas   map[string]JSONSchema // Schemas of the messages of the root schema being generated
+	recursive map[string]bool
+	refCounts map[string]int // Number of fields and variants referencing each message in the root schema
 }
 
 func NewJSONSchemaGenerator() *JSONSchemaGenerator {
 	return &JSONSchemaGenerator{
-		schemas: make(map[string]JSONSchema),
+		schemas:   make(map[string]JSONSchema),
+		recursive: make(map[string]bool),
 	}
 }
 
 // GenerateJSONSchema returns a JSON Schema object (as a map) for the given message.
-// It recursively includes referenced custom types.
+// It recursively includes referenced custom types. Messages referenced by several fields
+// of the schema, or referencing themselves, are defined once under "$defs" and referenced with "$ref",
+// so that shared types do not bloat prompts; others are inlined.
 func (gen *JSONSchemaGenerator) GenerateJSONSchema(name string, msg *spec.Message, allMessages map[string]spec.Message, allEnums map[string]spec.Enum) (JSONSchema, error) {
+	// Whether a message is inlined depends on the root, so schemas are not reused across roots
+	gen.schemas = make(map[string]JSONSchema)
+	gen.refCounts = make(map[string]int)
+	gen.countRefs(msg, allMessages, map[string]bool{name: true})
+
+	schema, err := gen.messageSchema(name, msg, allMessages, allEnums)
+	if err != nil {
+		return nil, err
+	}
+
+	defs := make(map[string]any)
+	if err := gen.collectDefs(schema, defs, allMessages, allEnums); err != nil {
+		return nil, err
+	}
+	if len(defs) == 0 {
+		return schema, nil
+	}
+
+	schema = maps.Clone(schema)
+	schema["$defs"] = defs
+	return schema, nil
+}
+
+// collectDefs adds to defs the schemas of the messages referenced by schema, and by their own schemas.
+func (gen *JSONSchemaGenerator) collectDefs(schema any, defs map[string]any, allMessages map[string]spec.Message, allEnums map[string]spec.Enum) error {
+	switch s := schema.(type) {
+	case JSONSchema:
+		return gen.collectDefs(map[string]any(s), defs, allMessages, allEnums)
+	case map[string]any:
+		if ref, ok := s["$ref"].(string); ok && strings.HasPrefix(ref, defsRefPrefix) {
+			name := strings.TrimPrefix(ref, defsRefPrefix)
+			if _, ok := defs[name]; ok {
+				return nil
+			}
+
+			msg := allMessages[name]
+			def, err := gen.messageSchema(name, &msg, allMessages, allEnums)
+			if err != nil {
+				return err
+			}
+			defs[name] = def
+			return gen.collectDefs(def, defs, allMessages, allEnums)
+		}
+		for _, key := range sortedKeys(s) {
+			if err := gen.collectDefs(s[key], defs, allMessages, allEnums); err != nil {
+				return err
+			}
+		}
+	case []any:
+		for _, item := range s {
+			if err := gen.collectDefs(item, defs, allMessages, allEnums); err != nil {
+				return err
+			}
+		}
+	}
+	return nil
+}
+
+// rewriteRefs returns a copy of schema where the references to its definitions start with prefix.
+func rewriteRefs(schema any, prefix string) any {
+	switch s := schema.(type) {
+	case JSONSchema:
+		return rewriteRefs(map[string]any(s), prefix)
+	case map[string]any:
+		out := make(map[string]any, len(s))
+		for key, value := range s {
+			if ref, ok := value.(string); ok && key == "$ref" {
+				value = prefix + strings.TrimPrefix(ref, defsRefPrefix)
+			}
+			out[key] = rewriteRefs(value, prefix)
+		}
+		return out
+	case []any:
+		out := make([]any, len(s))
+		for i, item := range s {
+			out[i] = rewriteRefs(item, prefix)
+		}
+		return out
+	}
+	return schema
+}
+
+// messageSchema returns the schema of a message, where the shared and recursive messages are references.
+func (gen *JSONSchemaGenerator) messageSchema(name string, msg *spec.Message, allMessages map[string]spec.Message, allEnums map[string]spec.Enum) (JSONSchema, error) {
 	schema, has := gen.schemas[name]
 	if has {
 		return schema, nil
@@ -51,6 +185,10 @@ func (gen *JSONSchemaGenerator) GenerateJSONSchema(name string, msg *spec.Messag
 }
 
 func (gen *JSONSchemaGenerator) generateJSONSchema(msg *spec.Message, allMessages map[string]spec.Message, allEnums map[string]spec.Enum) (JSONSchema, error) {
+	if msg.IsUnion() {
+		return gen.generateUnionSchema(msg, allMessages, allEnums)
+	}
+
 	properties := make(map[string]any)
 
 	schema := map[string]any{
@@ -79,54 +217,50 @@ func (gen *JSONSchemaGenerator) generateJSONSchema(msg *spec.Message, allMessage
 	return schema, nil
 }
 
-// fieldToSchema generates the JSON Schema for a single field, recursively if needed.
-func (gen *JSONSchemaGenerator) fieldToSchema(field spec.Field, allMessages map[string]spec.Message, allEnums map[string]spec.Enum) (map[string]interface{}, error) {
-	var baseSchema map[string]any
-
-	// Check if it's an enum type
-	if enum, isEnum := allEnums[field.Type]; isEnum {
-		baseSchema = map[string]any{
-			"type": "string",
-			"enum": enum.Values,
+// generateUnionSchema generates a oneOf schema, where each variant is wrapped
+// in a {"type": <variant name>, "value": <variant>} envelope.
+func (gen *JSONSchemaGenerator) generateUnionSchema(msg *spec.Message, allMessages map[string]spec.Message, allEnums map[string]spec.Enum) (JSONSchema, error) {
+	variants := make([]any, 0, len(msg.OneOf))
+	for _, name := range msg.OneOf {
+		valueSchema, err := gen.typeToSchema(name, allMessages, allEnums)
+		if err != nil {
+			return nil, fmt.Errorf("variant %q: %w", name, err)
 		}
-		if enum.Description != "" {
-			baseSchema["description"] = enum.Description
-		}
-	}
-
-	// Check primitive types and custom message types
-	if baseSchema == nil {
-		switch field.Type {
-		case "string":
-			baseSchema = map[string]any{"type": "string"}
-		case "int", "int32", "int64":
-			baseSchema = map[string]any{"type": "integer"}
-		case "float", "float32", "float64":
-			baseSchema = map[string]any{"type": "number"}
-		case "bool":
-			baseSchema = map[string]any{"type": "boolean"}
-		case "datetime":
-			baseSchema = map[string]any{"type": "string", "format": "date-time"} // RFC3339
-		default:
-			// Custom message type - lookup in allMessages
-			msg, ok := allMessages[field.Type]
-			if !ok {
-				return nil, fmt.Errorf("unknown custom type %q", field.Type)
-			}
 
-			// Recursive schema for nested message
-			nestedSchema, err := gen.GenerateJSONSchema(field.Type, &msg, allMessages, allEnums)
-			if err != nil {
-				return nil, err
-			}
-			baseSchema = nestedSchema
-		}
+		variants = append(variants, map[string]any{
+			"type": "object",
+			"properties": map[string]any{
+				"type":  map[string]any{"type": "string", "enum": []string{name}},
+				"value": valueSchema,
+			},
+			"required": []string{"type", "value"},
+		})
+	}
+
+	return JSONSchema{
+		"type":  "object",
+		"oneOf": variants,
+	}, nil
+}
+
+// fieldToSchema generates the JSON Schema for a single field, recursively if needed.
+func (gen *JSONSchemaGenerator) fieldToSchema(field spec.Field, allMessages map[string]spec.Message, allEnums map[string]spec.Enum) (map[string]any, error) {
+	baseSchema, err := gen.typeToSchema(field.Type, allMessages, allEnums)
+	if err != nil {
+		return nil, err
 	}
 
 	if field.Description != "" && baseSchema["description"] == nil {
+		// Copy the schema, since nested message schemas are shared
+		baseSchema = maps.Clone(baseSchema)
 		baseSchema["description"] = field.Description
 	}
 
+	if constraints := fieldConstraints(&field); len(constraints) > 0 {
+		baseSchema = maps.Clone(baseSchema)
+		maps.Copy(baseSchema, constraints)
+	}
+
 	// Wrap in array if repeated
 	if field.Repeated {
 		return map[string]any{
@@ -136,3 +270,150 @@ func (gen *JSONSchemaGenerator) fieldToSchema(field spec.Field, allMessages map[
 	}
 	return baseSchema, nil
 }
+
+// fieldConstraints returns the JSON Schema keywords for the validation constraints of a field.
+func fieldConstraints(field *spec.Field) map[string]any {
+	constraints := make(map[string]any)
+	if field.Min != nil {
+		constraints["minimum"] = *field.Min
+	}
+	if field.Max != nil {
+		constraints["maximum"] = *field.Max
+	}
+	if field.MinLength != nil {
+		constraints["minLength"] = *field.MinLength
+	}
+	if field.MaxLength != nil {
+		constraints["maxLength"] = *field.MaxLength
+	}
+	if field.Pattern != "" {
+		constraints["pattern"] = field.Pattern
+	}
+	if field.Format != "" {
+		constraints["format"] = field.Format
+	}
+	if len(field.Enum) > 0 {
+		constraints["enum"] = field.Enum
+	}
+	return constraints
+}
+
+// typeToSchema generates the JSON Schema for a type name.
+func (gen *JSONSchemaGenerator) typeToSchema(t string, allMessages map[string]spec.Message, allEnums map[string]spec.Enum) (map[string]any, error) {
+	if _, value, ok := spec.ParseMapType(t); ok {
+		valueSchema, err := gen.typeToSchema(value, allMessages, allEnums)
+		if err != nil {
+			return nil, err
+		}
+
+		return map[string]any{
+			"type":                 "object",
+			"additionalProperties": valueSchema,
+		}, nil
+	}
+
+	// Check if it's an enum type
+	if enum, isEnum := allEnums[t]; isEnum {
+		schema := map[string]any{
+			"type": "string",
+			"enum": enum.Values,
+		}
+		if enum.Description != "" {
+			schema["description"] = enum.Description
+		}
+		return schema, nil
+	}
+
+	switch t {
+	case "string":
+		return map[string]any{"type": "string"}, nil
+	case "int", "int32", "int64":
+		return map[string]any{"type": "integer"}, nil
+	case "float", "float32", "float64":
+		return map[string]any{"type": "number"}, nil
+	case "decimal", "money":
+		return map[string]any{"type": "string", "format": "decimal", "pattern": runtime.DecimalPattern}, nil
+	case "bool":
+		return map[string]any{"type": "boolean"}, nil
+	case "datetime":
+		return map[string]any{"type": "string", "format": "date-time"}, nil // RFC3339
+	case "uuid":
+		return map[string]any{"type": "string", "format": "uuid"}, nil
+	case "url":
+		return map[string]any{"type": "string", "format": "uri"}, nil // Absolute
+	case "bytes":
+		return map[string]any{"type": "string", "contentEncoding": "base64"}, nil
+	case "image":
+		return map[string]any{"type": "string", "pattern": imageDataURLPattern}, nil
+	case "file":
+		return map[string]any{"type": "string", "pattern": fileDataURLPattern}, nil
+	}
+
+	// Custom message type - lookup in allMessages
+	msg, ok := allMessages[t]
+	if !ok {
+		return nil, fmt.Errorf("unknown custom type %q", t)
+	}
+
+	// Shared and recursive messages are defined once by the root schema
+	if gen.isShared(t) || gen.isRecursive(t, allMessages) {
+		return map[string]any{"$ref": defsRefPrefix + t}, nil
+	}
+
+	// Recursive schema for nested message
+	return gen.messageSchema(t, &msg, allMessages, allEnums)
+}
+
+// countRefs counts the fields and variants referencing each message in the schema of msg. Since
+// messages referenced more than once are defined once, the references of each message are counted once.
+func (gen *JSONSchemaGenerator) countRefs(msg *spec.Message, allMessages map[string]spec.Message, visited map[string]bool) {
+	refs := slices.Clone(msg.OneOf)
+	for _, field := range msg.Fields {
+		refs = append(refs, spec.ElemType(field.Type))
+	}
+
+	for _, ref := range refs {
+		refMsg, ok := allMessages[ref]
+		if !ok {
+			continue
+		}
+		gen.refCounts[ref]++
+		if !visited[ref] {
+			visited[ref] = true
+			gen.countRefs(&refMsg, allMessages, visited)
+		}
+	}
+}
+
+// isShared reports whether the message name is referenced by more than one field or variant of the root schema.
+func (gen *JSONSchemaGenerator) isShared(name string) bool {
+	return gen.refCounts[name] > 1
+}
+
+// isRecursive reports whether the message name references itself, directly or through other messages.
+func (gen *JSONSchemaGenerator) isRecursive(name string, allMessages map[string]spec.Message) bool {
+	recursive, ok := gen.recursive[name]
+	if !ok {
+		recursive = reaches(name, name, allMessages, map[string]bool{})
+		gen.recursive[name] = recursive
+	}
+	return recursive
+}
+
+// reaches reports whether the message target is referenced by the message from, directly or not.
+func reaches(from, target string, allMessages map[string]spec.Message, visited map[string]bool) bool {
+	msg := allMessages[from]
+	for _, ref := range msg.TypeRefs() {
+		if ref == target {
+			return true
+		}
+		if _, ok := allMessages[ref]; !ok || visited[ref] {
+			continue
+		}
+		visited[ref] = true
+		if reaches(ref, target, allMessages, visited) {
+			return true
+		}
+	}
+	return false
+}
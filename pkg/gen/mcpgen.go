@@ -0,0 +1,94 @@
+// Copyright (c) 2025 Suricata Contributors
+// Original Author: Stefano Scafiti
+//
+// This file is part of Suricata: Type-Safe AI Agents for Go.
+//
+// Licensed under the MIT License. You may obtain a copy of the License at
+//
+//	https://opensource.org/licenses/MIT
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package gen
+
+import (
+	"fmt"
+
+	"github.com/ostafen/suricata/pkg/spec"
+)
+
+// GenerateMCP generates, for each agent, a constructor of an MCP server exposing
+// its actions and tools. The output belongs to the same package as the code produced by Generate.
+func (gen *CodeGenerator) GenerateMCP(spec *spec.Spec) ([]byte, error) {
+	gen.buf.Reset()
+
+	gen.writePreamble(spec, "github.com/ostafen/suricata/runtime/mcp")
+
+	for _, name := range sortedKeys(spec.Agents) {
+		agent := spec.Agents[name]
+		if err := gen.generateMCPServer(name, &agent, spec.Tools); err != nil {
+			return nil, err
+		}
+	}
+
+	return gen.format()
+}
+
+// MCPToolName returns the name of the MCP tool exposing a tool of an agent. Tools are
+// namespaced, as agents often have an action and a tool with the same name, such as an
+// action booking a hotel through a BookHotel tool.
+func MCPToolName(tool string) string {
+	return "tool_" + tool
+}
+
+func (gen *CodeGenerator) generateMCPServer(name string, agent *spec.Agent, tools map[string]spec.Tool) error {
+	typeName := getAgentTypeName(name)
+
+	for _, toolName := range agent.AllTools() {
+		if _, ok := agent.Actions[MCPToolName(toolName)]; ok {
+			return fmt.Errorf("agent %q: MCP tool %q of tool %q has the same name as an action", name, MCPToolName(toolName), toolName)
+		}
+	}
+
+	gen.write("// New%sMCPServer returns an MCP server exposing the actions and the tools of agent.\n", typeName)
+	gen.write("func New%sMCPServer(agent *%sClient) *mcp.Server {\n", typeName, typeName)
+	gen.write("\tsrv := mcp.NewServer(%q, \"\")\n\n", name)
+
+	for _, actionName := range sortedKeys(agent.Actions) {
+		action := agent.Actions[actionName]
+
+		call := fmt.Sprintf("agent.%s(ctx, &in)", CapitalizeFirst(actionName))
+		if action.Stream {
+			call += ".Result()"
+		}
+		gen.generateMCPTool(actionName, action.Description, gen.typeName(action.Input), call)
+	}
+
+	for _, toolName := range agent.AllTools() {
+		tool := tools[toolName]
+		gen.generateMCPTool(MCPToolName(toolName), tool.Description, gen.typeName(tool.Input), fmt.Sprintf("agent.tools.%s(ctx, &in)", CapitalizeFirst(toolName)))
+	}
+
+	gen.write("\treturn srv\n")
+	gen.write("}\n\n")
+	return nil
+}
+
+func (gen *CodeGenerator) generateMCPTool(name, description, input, call string) {
+	gen.write("\tsrv.AddTool(mcp.Tool{\n")
+	gen.write("\t\tName: %q,\n", name)
+	gen.write("\t\tDescription: %q,\n", description)
+	gen.write("\t\tInputSchema: %sSchema,\n", input)
+	gen.write("\t\tHandler: func(ctx context.Context, args json.RawMessage) (any, error) {\n")
+	gen.write("\t\t\tvar in %s\n", input)
+	gen.write("\t\t\tif err := runtime.UnmarshalValidate(args, &in, %sSchema); err != nil {\n", input)
+	gen.write("\t\t\t\treturn nil, err\n")
+	gen.write("\t\t\t}\n")
+	gen.write("\t\t\treturn %s\n", call)
+	gen.write("\t\t},\n")
+	gen.write("\t})\n\n")
+}
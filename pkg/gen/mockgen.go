@@ -0,0 +1,98 @@
+// Copyright (c) 2025 Suricata Contributors
+// Original Author: Stefano Scafiti
+//
+// This file is part of Suricata: Type-Safe AI Agents for Go.
+//
+// Licensed under the MIT License. You may obtain a copy of the License at
+//
+//	https://opensource.org/licenses/MIT
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package gen
+
+import (
+	"github.com/ostafen/suricata/pkg/spec"
+)
+
+// GenerateMocks generates mock implementations of each agent and of its tools interface.
+// The output belongs to the same package as the code produced by Generate.
+func (gen *CodeGenerator) GenerateMocks(spec *spec.Spec) ([]byte, error) {
+	gen.buf.Reset()
+
+	gen.writePreamble(spec)
+
+	for _, name := range sortedKeys(spec.Agents) {
+		agent := spec.Agents[name]
+		gen.generateToolsMock(getAgentTypeName(name), agent.AllTools(), spec.Tools)
+		gen.generateAgentMock(getAgentTypeName(name), &agent)
+	}
+
+	return gen.format()
+}
+
+func (gen *CodeGenerator) generateToolsMock(name string, tools []string, toolsMap map[string]spec.Tool) {
+	if len(tools) == 0 {
+		return
+	}
+
+	mockName := "Mock" + name + "Tools"
+
+	gen.write("// %s is a mock implementation of %sTools.\n", mockName, name)
+	gen.write("// Each method delegates to the corresponding function field, failing if it is not set.\n")
+	gen.write("type %s struct {\n", mockName)
+	for _, toolName := range tools {
+		tool := toolsMap[toolName]
+		gen.write("\t%sFunc func(ctx context.Context, in *%s) (*%s, error)\n", CapitalizeFirst(toolName), gen.typeName(tool.Input), gen.typeName(tool.Output))
+	}
+	gen.write("}\n\n")
+
+	for _, toolName := range tools {
+		tool := toolsMap[toolName]
+		method := CapitalizeFirst(toolName)
+
+		gen.write("func (m *%s) %s(ctx context.Context, in *%s) (*%s, error) {\n", mockName, method, gen.typeName(tool.Input), gen.typeName(tool.Output))
+		gen.write("\tif m.%sFunc == nil {\n", method)
+		gen.write("\t\treturn nil, fmt.Errorf(\"%s.%s: not implemented\")\n", mockName, method)
+		gen.write("\t}\n")
+		gen.write("\treturn m.%sFunc(ctx, in)\n", method)
+		gen.write("}\n\n")
+	}
+}
+
+func (gen *CodeGenerator) generateAgentMock(name string, agent *spec.Agent) {
+	mockName := "Mock" + name
+
+	gen.write("// %s is a mock of %s.\n", mockName, name)
+	gen.write("// Each method delegates to the corresponding function field, failing if it is not set.\n")
+	gen.write("type %s struct {\n", mockName)
+	for _, actionName := range sortedKeys(agent.Actions) {
+		action := agent.Actions[actionName]
+		gen.write("\t%sFunc func%s\n", CapitalizeFirst(actionName), gen.actionSignature(&action))
+	}
+	gen.write("}\n\n")
+
+	for _, actionName := range sortedKeys(agent.Actions) {
+		action := agent.Actions[actionName]
+		method := CapitalizeFirst(actionName)
+
+		gen.write("func (m *%s) %s%s {\n", mockName, method, gen.actionSignature(&action))
+		gen.write("\tif m.%sFunc == nil {\n", method)
+		if action.Stream {
+			gen.write("\t\treturn runtime.StreamError[%s](fmt.Errorf(\"%s.%s: not implemented\"))\n", gen.actionOutputType(&action), mockName, method)
+		} else if action.IsTextOutput() {
+			gen.write("\t\treturn \"\", fmt.Errorf(\"%s.%s: not implemented\")\n", mockName, method)
+		} else {
+			gen.write("\t\treturn nil, fmt.Errorf(\"%s.%s: not implemented\")\n", mockName, method)
+		}
+		gen.write("\t}\n")
+		gen.write("\treturn m.%sFunc(ctx, in)\n", method)
+		gen.write("}\n\n")
+	}
+
+	gen.write("var _ %s = (*%s)(nil)\n\n", name, mockName)
+}
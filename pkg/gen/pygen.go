@@ -0,0 +1,325 @@
+// Copyright (c) 2025 Suricata Contributors
+// Original Author: Stefano Scafiti
+//
+// This file is part of Suricata: Type-Safe AI Agents for Go.
+//
+// Licensed under the MIT License. You may obtain a copy of the License at
+//
+//	https://opensource.org/licenses/MIT
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package gen
+
+import (
+	"encoding/json"
+	"regexp"
+	"slices"
+	"strings"
+	"unicode"
+
+	"github.com/ostafen/suricata/pkg/spec"
+)
+
+// GeneratePython generates a Python module declaring a pydantic model for each message of the spec, and a
+// literal type for each enum, matching their JSON encoding, together with an httpx-based client for each
+// agent served by the handlers of GenerateHTTP.
+func (gen *CodeGenerator) GeneratePython(spec *spec.Spec) ([]byte, error) {
+	gen.buf.Reset()
+
+	gen.writeBanner("#")
+	gen.write("%s\n", pyImports)
+
+	for _, name := range sortedKeys(spec.Enums) {
+		gen.generatePyEnum(name, spec.Enums[name])
+	}
+	for _, name := range sortedKeys(spec.Messages) {
+		gen.generatePyMessage(name, spec.Messages[name])
+	}
+
+	if len(spec.Agents) > 0 {
+		gen.write("%s\n\n\n", pyClientRuntime)
+	}
+	for _, name := range sortedKeys(spec.Agents) {
+		agent := spec.Agents[name]
+		gen.generatePyClient(name, &agent)
+	}
+	return terminated(gen.buf.Bytes()), nil
+}
+
+func (gen *CodeGenerator) generatePyEnum(name string, enum spec.Enum) {
+	values := make([]string, len(enum.Values))
+	for i, value := range enum.Values {
+		values[i] = pyString(value)
+	}
+	gen.write("%s = Literal[%s]\n", name, strings.Join(values, ", "))
+	if doc := pyDocstring(enum.Description); doc != "" {
+		gen.write("%s\n", doc)
+	}
+	gen.write("\n\n")
+}
+
+func (gen *CodeGenerator) generatePyMessage(name string, msg spec.Message) {
+	if msg.IsUnion() {
+		// Each variant is wrapped in a {"type": <variant>, "value": <payload>} envelope
+		variants := make([]string, len(msg.OneOf))
+		for i, variant := range msg.OneOf {
+			variants[i] = name + variant
+			gen.write("class %s(BaseModel):\n", variants[i])
+			gen.write("    type: Literal[%s] = %s\n", pyString(variant), pyString(variant))
+			gen.write("    value: %s\n\n\n", variant)
+		}
+		gen.write("%s = Annotated[Union[%s], Field(discriminator=\"type\")]\n", name, strings.Join(variants, ", "))
+		gen.write("%s\n\n\n", pyDocstring(name+" is a union: the type of the value is named by the type property."))
+		return
+	}
+
+	gen.write("class %s(BaseModel):\n", name)
+	if len(msg.Fields) == 0 {
+		gen.write("    pass\n\n\n")
+		return
+	}
+	if slices.ContainsFunc(msg.Fields, func(f spec.Field) bool { return pyIdentifier(f.Name) != f.Name }) {
+		// Allow setting the fields renamed by aliases by their Python name
+		gen.write("    model_config = ConfigDict(populate_by_name=True)\n\n")
+	}
+	for _, field := range msg.Fields {
+		attr := pyIdentifier(field.Name)
+		typ := pyTypeForName(field.Type)
+
+		var args []string
+		if attr != field.Name {
+			args = append(args, "alias="+pyString(field.Name))
+		}
+		if field.Description != "" {
+			args = append(args, "description="+pyString(field.Description))
+		}
+
+		// Optional and repeated fields are omitted by the Go encoding when empty
+		switch {
+		case field.Repeated:
+			typ = "List[" + typ + "]"
+			args = append([]string{"default_factory=list"}, args...)
+		case field.Optional:
+			typ = "Optional[" + typ + "]"
+			args = append([]string{"default=None"}, args...)
+		}
+
+		switch {
+		case len(args) == 0:
+			gen.write("    %s: %s\n", attr, typ)
+		case len(args) == 1 && args[0] == "default=None":
+			gen.write("    %s: %s = None\n", attr, typ)
+		default:
+			gen.write("    %s: %s = Field(%s)\n", attr, typ, strings.Join(args, ", "))
+		}
+	}
+	gen.write("\n\n")
+}
+
+func (gen *CodeGenerator) generatePyClient(name string, agent *spec.Agent) {
+	typeName := getAgentTypeName(name)
+
+	gen.write("class %sClient(_Client):\n", typeName)
+	gen.write("    %s\n", pyDocstring("Client of the endpoints served by New"+typeName+"Handler."))
+
+	for _, actionName := range sortedKeys(agent.Actions) {
+		action := agent.Actions[actionName]
+
+		out := "str"
+		if !action.IsTextOutput() {
+			out = action.Output
+		}
+		method := pyIdentifier(ToSnakeCase(actionName))
+		path := pyString("/" + actionName)
+
+		gen.write("\n")
+		if action.Stream {
+			gen.write("    def %s(self, input: %s, on_delta: Optional[Callable[[str], None]] = None) -> %s:\n", method, action.Input, out)
+		} else {
+			gen.write("    def %s(self, input: %s) -> %s:\n", method, action.Input, out)
+		}
+		if doc := pyDocstring(action.Description); doc != "" {
+			gen.write("        %s\n", strings.ReplaceAll(doc, "\n", "\n        "))
+		}
+		if action.Stream {
+			gen.write("        return self._stream(%s, input, %s, on_delta)\n", path, out)
+		} else {
+			gen.write("        return self._call(%s, input, %s)\n", path, out)
+		}
+	}
+	gen.write("\n\n")
+}
+
+// ToSnakeCase converts a CamelCase name to snake_case, e.g. "SayHelloAll" to "say_hello_all".
+func ToSnakeCase(s string) string {
+	runes := []rune(s)
+
+	var sb strings.Builder
+	for i, r := range runes {
+		if unicode.IsUpper(r) {
+			// Start a word, unless within an acronym such as "HTTP"
+			if i > 0 && (unicode.IsLower(runes[i-1]) || i+1 < len(runes) && unicode.IsLower(runes[i+1]) && unicode.IsUpper(runes[i-1])) {
+				sb.WriteByte('_')
+			}
+			r = unicode.ToLower(r)
+		}
+		sb.WriteRune(r)
+	}
+	return sb.String()
+}
+
+func pyTypeForName(t string) string {
+	if _, value, ok := spec.ParseMapType(t); ok {
+		return "Dict[str, " + pyTypeForName(value) + "]"
+	}
+
+	switch t {
+	case "string":
+		return "str"
+	case "int", "int32", "int64":
+		return "int"
+	case "float", "float32", "float64":
+		return "float"
+	case "decimal", "money":
+		return "Decimal" // Encoded as a string
+	case "bool":
+		return "bool"
+	case "datetime":
+		return "datetime" // RFC3339 format
+	case "uuid", "url":
+		return "str"
+	case "bytes":
+		return "str" // base64
+	case "image", "file":
+		return "str" // Data URL
+	default:
+		// Enum and message types use the type name directly
+		return t
+	}
+}
+
+// pyKeywords are the reserved words of Python, which cannot be used as identifiers.
+var pyKeywords = []string{
+	"False", "None", "True", "and", "as", "assert", "async", "await", "break", "class", "continue",
+	"def", "del", "elif", "else", "except", "finally", "for", "from", "global", "if", "import", "in",
+	"is", "lambda", "nonlocal", "not", "or", "pass", "raise", "return", "try", "while", "with", "yield",
+}
+
+var pyInvalidCharsRegexp = regexp.MustCompile(`[^A-Za-z0-9_]`)
+
+// pyIdentifier returns a valid Python identifier for name: invalid characters are replaced
+// by underscores, and reserved words get a trailing underscore.
+func pyIdentifier(name string) string {
+	ident := pyInvalidCharsRegexp.ReplaceAllString(name, "_")
+	if ident == "" || unicode.IsDigit(rune(ident[0])) {
+		ident = "_" + ident
+	}
+	if slices.Contains(pyKeywords, ident) {
+		ident += "_"
+	}
+	return ident
+}
+
+// pyString returns s as a Python string literal.
+func pyString(s string) string {
+	data, _ := json.Marshal(s)
+	return string(data)
+}
+
+// pyDocstring returns doc as a docstring, or an empty string if doc is empty.
+func pyDocstring(doc string) string {
+	doc = strings.TrimSpace(doc)
+	if doc == "" {
+		return ""
+	}
+
+	doc = strings.ReplaceAll(doc, `\`, `\\`)
+	doc = strings.ReplaceAll(doc, `"""`, `\"\"\"`)
+	if strings.HasSuffix(doc, `"`) {
+		doc += " "
+	}
+	return `"""` + doc + `"""`
+}
+
+const pyImports = `from __future__ import annotations
+
+import json
+from datetime import datetime
+from decimal import Decimal
+from typing import Annotated, Any, Callable, Dict, List, Literal, Optional, Union
+
+import httpx
+from pydantic import BaseModel, ConfigDict, Field, TypeAdapter
+
+`
+
+// pyClientRuntime holds the helpers shared by the generated clients.
+const pyClientRuntime = `class AgentError(Exception):
+    """Error replied by an agent endpoint."""
+
+    def __init__(self, status: int, message: str) -> None:
+        super().__init__(message)
+        self.status = status
+
+
+def _error(resp: httpx.Response) -> AgentError:
+    try:
+        message = resp.json()["error"]
+    except (ValueError, KeyError, TypeError):
+        message = resp.reason_phrase
+    return AgentError(resp.status_code, message)
+
+
+class _Client:
+    def __init__(
+        self,
+        base_url: str,
+        headers: Optional[Dict[str, str]] = None,
+        timeout: Optional[float] = None,
+        client: Optional[httpx.Client] = None,
+    ) -> None:
+        """Creates a client of the agent served at base_url. Headers are sent with each request,
+        e.g. for authentication. Model calls may be slow, so there is no timeout by default."""
+        self._base_url = base_url.rstrip("/")
+        self._client = client or httpx.Client(timeout=timeout)
+        self._headers = headers or {}
+
+    def _request(self, input: BaseModel) -> Dict[str, Any]:
+        return {
+            "json": input.model_dump(mode="json", by_alias=True, exclude_none=True),
+            "headers": self._headers,
+        }
+
+    def _call(self, path: str, input: BaseModel, out: Any) -> Any:
+        resp = self._client.post(self._base_url + path, **self._request(input))
+        if resp.is_error:
+            raise _error(resp)
+        return TypeAdapter(out).validate_python(resp.json())
+
+    def _stream(self, path: str, input: BaseModel, out: Any, on_delta: Optional[Callable[[str], None]]) -> Any:
+        with self._client.stream("POST", self._base_url + path, **self._request(input)) as resp:
+            if resp.is_error:
+                resp.read()
+                raise _error(resp)
+
+            event, data = "message", ""
+            for line in resp.iter_lines():
+                if line.startswith("event: "):
+                    event = line[len("event: "):]
+                elif line.startswith("data: "):
+                    data += line[len("data: "):]
+                elif line == "" and data:
+                    value = json.loads(data)
+                    if event == "delta" and on_delta is not None:
+                        on_delta(value)
+                    elif event == "result":
+                        return TypeAdapter(out).validate_python(value)
+                    elif event == "error":
+                        raise AgentError(resp.status_code, value["error"])
+                    event, data = "message", ""
+        raise AgentError(resp.status_code, "stream ended without a result")`
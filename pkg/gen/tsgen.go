@@ -0,0 +1,299 @@
+// Copyright (c) 2025 Suricata Contributors
+// Original Author: Stefano Scafiti
+//
+// This file is part of Suricata: Type-Safe AI Agents for Go.
+//
+// Licensed under the MIT License. You may obtain a copy of the License at
+//
+//	https://opensource.org/licenses/MIT
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package gen
+
+import (
+	"encoding/json"
+	"regexp"
+	"strings"
+
+	"github.com/ostafen/suricata/pkg/spec"
+)
+
+// GenerateTypeScript generates a TypeScript module declaring a type for each enum and message of the spec,
+// matching their JSON encoding, and a fetch-based client for each agent served by the handlers of GenerateHTTP.
+func (gen *CodeGenerator) GenerateTypeScript(spec *spec.Spec) ([]byte, error) {
+	gen.buf.Reset()
+
+	gen.writeBanner("//")
+
+	for _, name := range sortedKeys(spec.Enums) {
+		gen.generateTSEnum(name, spec.Enums[name])
+	}
+	for _, name := range sortedKeys(spec.Messages) {
+		gen.generateTSMessage(name, spec.Messages[name])
+	}
+
+	if len(spec.Agents) > 0 {
+		gen.write("%s\n", tsClientRuntime)
+	}
+	for _, name := range sortedKeys(spec.Agents) {
+		agent := spec.Agents[name]
+		gen.generateTSClient(name, &agent)
+	}
+	return terminated(gen.buf.Bytes()), nil
+}
+
+func (gen *CodeGenerator) generateTSEnum(name string, enum spec.Enum) {
+	gen.writeTSDoc("", enum.Description)
+
+	values := make([]string, len(enum.Values))
+	for i, value := range enum.Values {
+		values[i] = tsString(value)
+	}
+	gen.write("export type %s = %s;\n\n", name, strings.Join(values, " | "))
+}
+
+func (gen *CodeGenerator) generateTSMessage(name string, msg spec.Message) {
+	if msg.IsUnion() {
+		variants := make([]string, len(msg.OneOf))
+		for i, variant := range msg.OneOf {
+			variants[i] = "{ type: " + tsString(variant) + "; value: " + variant + " }"
+		}
+		gen.write("/** %s is a union: the type of the value is named by the type property. */\n", name)
+		gen.write("export type %s =\n  | %s;\n\n", name, strings.Join(variants, "\n  | "))
+		return
+	}
+
+	gen.write("export interface %s {\n", name)
+	for _, field := range msg.Fields {
+		gen.writeTSDoc("  ", field.Description)
+
+		// Optional and repeated fields are omitted by the Go encoding when empty
+		optional := ""
+		if field.Optional || field.Repeated {
+			optional = "?"
+		}
+		gen.write("  %s%s: %s;\n", tsPropertyName(field.Name), optional, tsTypeForField(field))
+	}
+	gen.write("}\n\n")
+}
+
+func (gen *CodeGenerator) generateTSClient(name string, agent *spec.Agent) {
+	typeName := getAgentTypeName(name)
+
+	gen.write("/** Client of the endpoints served by New%sHandler. */\n", typeName)
+	gen.write("export class %sClient {\n", typeName)
+	gen.write("  readonly baseURL: string;\n")
+	gen.write("  readonly options: ClientOptions;\n\n")
+	gen.write("  constructor(baseURL: string, options: ClientOptions = {}) {\n")
+	gen.write("    this.baseURL = baseURL;\n")
+	gen.write("    this.options = options;\n")
+	gen.write("  }\n")
+
+	for _, actionName := range sortedKeys(agent.Actions) {
+		action := agent.Actions[actionName]
+
+		out := "string"
+		if !action.IsTextOutput() {
+			out = action.Output
+		}
+		method := strings.ToLower(actionName[:1]) + actionName[1:]
+		path := tsString("/" + actionName)
+
+		gen.write("\n")
+		gen.writeTSDoc("  ", action.Description)
+		if action.Stream {
+			gen.write("  %s(input: %s, onDelta?: DeltaHandler, signal?: AbortSignal): Promise<%s> {\n", method, action.Input, out)
+			gen.write("    return stream<%s>(this.baseURL, %s, input, this.options, onDelta, signal);\n", out, path)
+		} else {
+			gen.write("  %s(input: %s, signal?: AbortSignal): Promise<%s> {\n", method, action.Input, out)
+			gen.write("    return call<%s>(this.baseURL, %s, input, this.options, signal);\n", out, path)
+		}
+		gen.write("  }\n")
+	}
+	gen.write("}\n\n")
+}
+
+// writeTSDoc writes doc as a JSDoc comment, if not empty.
+func (gen *CodeGenerator) writeTSDoc(indent, doc string) {
+	doc = strings.TrimSpace(strings.ReplaceAll(doc, "*/", "*\\/"))
+	if doc == "" {
+		return
+	}
+
+	lines := strings.Split(doc, "\n")
+	if len(lines) == 1 {
+		gen.write("%s/** %s */\n", indent, doc)
+		return
+	}
+
+	gen.write("%s/**\n", indent)
+	for _, line := range lines {
+		gen.write("%s * %s\n", indent, strings.TrimRight(line, " \t"))
+	}
+	gen.write("%s */\n", indent)
+}
+
+func tsTypeForField(f spec.Field) string {
+	t := tsTypeForName(f.Type)
+	if f.Repeated {
+		if strings.ContainsAny(t, " |") {
+			t = "(" + t + ")"
+		}
+		t += "[]"
+	}
+	return t
+}
+
+func tsTypeForName(t string) string {
+	if _, value, ok := spec.ParseMapType(t); ok {
+		return "Record<string, " + tsTypeForName(value) + ">"
+	}
+
+	switch t {
+	case "string":
+		return "string"
+	case "int", "int32", "int64", "float", "float32", "float64":
+		return "number"
+	case "decimal", "money":
+		return "string" // Fixed-point, e.g. "12.50"
+	case "bool":
+		return "boolean"
+	case "datetime":
+		return "string" // RFC3339 format
+	case "uuid", "url":
+		return "string"
+	case "bytes":
+		return "string" // base64
+	case "image", "file":
+		return "string" // Data URL
+	default:
+		// Enum and message types use the type name directly
+		return t
+	}
+}
+
+var tsIdentRegexp = regexp.MustCompile(`^[A-Za-z_$][A-Za-z0-9_$]*$`)
+
+func tsPropertyName(name string) string {
+	if tsIdentRegexp.MatchString(name) {
+		return name
+	}
+	return tsString(name)
+}
+
+// tsString returns s as a TypeScript string literal.
+func tsString(s string) string {
+	data, _ := json.Marshal(s)
+	return string(data)
+}
+
+// tsClientRuntime holds the helpers shared by the generated clients.
+const tsClientRuntime = `/** Options of the agent clients. */
+export interface ClientOptions {
+  /** Headers sent with each request, e.g. for authentication. */
+  headers?: Record<string, string>;
+  /** Implementation of fetch, defaulting to the global one. */
+  fetch?: typeof fetch;
+}
+
+/** Error replied by an agent endpoint. */
+export class AgentError extends Error {
+  readonly status: number;
+
+  constructor(status: number, message: string) {
+    super(message);
+    this.name = "AgentError";
+    this.status = status;
+  }
+}
+
+/** Receives the chunks of the model responses of streaming actions, as soon as they are generated. */
+export type DeltaHandler = (delta: string) => void;
+
+async function send(baseURL: string, path: string, input: unknown, options: ClientOptions, signal?: AbortSignal): Promise<Response> {
+  const doFetch = options.fetch ?? fetch;
+  const resp = await doFetch(baseURL.replace(/\/+$/, "") + path, {
+    method: "POST",
+    headers: { "Content-Type": "application/json", ...options.headers },
+    body: JSON.stringify(input),
+    signal,
+  });
+  if (!resp.ok) {
+    let message = resp.statusText;
+    try {
+      message = (await resp.json()).error ?? message;
+    } catch {
+      // Not a JSON error
+    }
+    throw new AgentError(resp.status, message);
+  }
+  return resp;
+}
+
+async function call<Out>(baseURL: string, path: string, input: unknown, options: ClientOptions, signal?: AbortSignal): Promise<Out> {
+  const resp = await send(baseURL, path, input, options, signal);
+  return (await resp.json()) as Out;
+}
+
+async function stream<Out>(
+  baseURL: string,
+  path: string,
+  input: unknown,
+  options: ClientOptions,
+  onDelta?: DeltaHandler,
+  signal?: AbortSignal,
+): Promise<Out> {
+  const resp = await send(baseURL, path, input, options, signal);
+  if (!resp.body) {
+    throw new AgentError(resp.status, "empty response");
+  }
+
+  const reader = resp.body.pipeThrough(new TextDecoderStream()).getReader();
+  let buffer = "";
+  for (;;) {
+    const { value, done } = await reader.read();
+    if (done) {
+      break;
+    }
+    buffer += value;
+
+    let end: number;
+    while ((end = buffer.indexOf("\n\n")) >= 0) {
+      const event = parseEvent(buffer.slice(0, end));
+      buffer = buffer.slice(end + 2);
+
+      switch (event.name) {
+        case "delta":
+          onDelta?.(event.data as string);
+          break;
+        case "result":
+          void reader.cancel();
+          return event.data as Out;
+        case "error":
+          void reader.cancel();
+          throw new AgentError(resp.status, (event.data as { error: string }).error);
+      }
+    }
+  }
+  throw new AgentError(resp.status, "stream ended without a result");
+}
+
+// parseEvent parses a server-sent event, whose data is JSON.
+function parseEvent(block: string): { name: string; data: unknown } {
+  let name = "message";
+  let data = "";
+  for (const line of block.split("\n")) {
+    if (line.startsWith("event: ")) {
+      name = line.slice("event: ".length);
+    } else if (line.startsWith("data: ")) {
+      data += line.slice("data: ".length);
+    }
+  }
+  return { name, data: JSON.parse(data) };
+}
+`
@@ -0,0 +1,258 @@
+// Copyright (c) 2025 Suricata Contributors
+// Original Author: Stefano Scafiti
+//
+// This file is part of Suricata: Type-Safe AI Agents for Go.
+//
+// Licensed under the MIT License. You may obtain a copy of the License at
+//
+//	https://opensource.org/licenses/MIT
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package gen
+
+import (
+	"fmt"
+	"slices"
+	"strings"
+
+	"github.com/ostafen/suricata/pkg/spec"
+)
+
+// workflowStep is a step of a workflow, as seen by the generated Run method.
+type workflowStep struct {
+	*spec.Step
+	action *spec.Actions
+	out    string // Name of the variable holding the output of the step
+	ptr    bool   // Whether out is a pointer
+}
+
+func getWorkflowTypeName(name string) string {
+	return ToCamelCase(name) + "Workflow"
+}
+
+// generateWorkflow generates a struct holding the agents of a workflow, along with a Run
+// method executing its steps in order.
+func (gen *CodeGenerator) generateWorkflow(s *spec.Spec, name string, wf *spec.Workflow) {
+	typeName := getWorkflowTypeName(name)
+
+	var agents []string
+	for _, step := range wf.Steps {
+		if agent := getAgentTypeName(step.Agent); !slices.Contains(agents, agent) {
+			agents = append(agents, agent)
+		}
+	}
+	slices.Sort(agents)
+
+	if wf.Description != "" {
+		gen.write("// %s %s\n", typeName, strings.TrimSpace(wf.Description))
+	}
+	gen.write("type %s struct {\n", typeName)
+	for _, agent := range agents {
+		gen.write("\t%s %s\n", agent, agent)
+	}
+	gen.write("\n\t// OnStepError is called when an optional step fails. The workflow goes on without its output.\n")
+	gen.write("\tOnStepError func(ctx context.Context, step string, err error)\n")
+	gen.write("}\n\n")
+
+	params := make([]string, len(agents))
+	fields := make([]string, len(agents))
+	for i, agent := range agents {
+		params[i] = fmt.Sprintf("%s %s", lowerFirst(agent), agent)
+		fields[i] = fmt.Sprintf("%s: %s", agent, lowerFirst(agent))
+	}
+	gen.write("func New%s(%s) *%s {\n", typeName, strings.Join(params, ", "), typeName)
+	gen.write("\treturn &%s{%s}\n}\n\n", typeName, strings.Join(fields, ", "))
+
+	steps := make([]workflowStep, len(wf.Steps))
+	for i := range wf.Steps {
+		step := &wf.Steps[i]
+		action := s.StepAction(step)
+		steps[i] = workflowStep{
+			Step:   step,
+			action: action,
+			out:    lowerFirst(ToCamelCase(step.Name)) + "Out",
+			ptr:    !action.IsTextOutput() || action.Stream,
+		}
+	}
+
+	outType := s.WorkflowOutput(wf)
+	zero := "nil"
+	if outType == spec.TextOutput {
+		zero = `""`
+		gen.write("func (w *%s) Run(ctx context.Context, in *%s) (string, error) {\n", typeName, gen.typeName(wf.Input))
+	} else {
+		gen.write("func (w *%s) Run(ctx context.Context, in *%s) (*%s, error) {\n", typeName, gen.typeName(wf.Input), gen.typeName(outType))
+	}
+
+	used := workflowUsedSteps(wf)
+	errDeclared := false
+	for i, step := range steps {
+		gen.generateWorkflowStep(s, wf, steps, i)
+
+		call := fmt.Sprintf("w.%s.%s(ctx, %sIn)", getAgentTypeName(step.Agent), CapitalizeFirst(step.Action), strings.TrimSuffix(step.out, "Out"))
+		if step.action.Stream {
+			call += ".Result()"
+		}
+
+		out := step.out
+		if !used[step.Name] && (wf.Result != nil || i < len(steps)-1) {
+			out = "_"
+		}
+
+		assign := ":="
+		if out == "_" && errDeclared {
+			assign = "="
+		}
+		errDeclared = true
+
+		gen.write("\t%s, err %s %s\n", out, assign, call)
+		if step.Optional {
+			gen.write("\tif err != nil && w.OnStepError != nil {\n\t\tw.OnStepError(ctx, %q, err)\n\t}\n\n", step.Name)
+		} else {
+			gen.write("\tif err != nil {\n\t\treturn %s, &runtime.StepError{Workflow: %q, Step: %q, Err: err}\n\t}\n\n", zero, name, step.Name)
+		}
+	}
+
+	if wf.Result == nil {
+		last := steps[len(steps)-1]
+		if outType == spec.TextOutput && last.ptr {
+			gen.write("\treturn *%s, nil\n}\n\n", last.out)
+		} else {
+			gen.write("\treturn %s, nil\n}\n\n", last.out)
+		}
+		return
+	}
+
+	gen.write("\tout := &%s{}\n", gen.typeName(wf.Output))
+	out := s.Messages[wf.Output]
+	for _, field := range out.Fields {
+		if expr, ok := wf.Result[field.Name]; ok {
+			gen.generateWorkflowMapping(s, wf, steps, len(steps), expr, "out."+ToCamelCase(field.Name), fieldIsPointer(field))
+		}
+	}
+	gen.write("\treturn out, nil\n}\n\n")
+}
+
+// generateWorkflowStep generates the construction of the input of the step with the given index.
+func (gen *CodeGenerator) generateWorkflowStep(s *spec.Spec, wf *spec.Workflow, steps []workflowStep, idx int) {
+	step := steps[idx]
+	in := strings.TrimSuffix(step.out, "Out") + "In"
+	inType := gen.typeName(step.action.Input)
+
+	gen.write("\t// Step %q: %s.%s\n", step.Name, step.Agent, step.Action)
+	if src := step.Source(); src != "" {
+		access, guards := workflowAccess(s, wf, steps, idx, src, true, true)
+		if len(guards) == 0 {
+			gen.write("\t%s := %s\n", in, access)
+			return
+		}
+
+		gen.write("\t%s := &%s{}\n", in, inType)
+		gen.write("\tif %s {\n\t\t%s = %s\n\t}\n", strings.Join(guards, " && "), in, access)
+		return
+	}
+
+	gen.write("\t%s := &%s{}\n", in, inType)
+	msg := s.Messages[step.action.Input]
+	for _, field := range msg.Fields {
+		if expr, ok := step.Input[field.Name]; ok {
+			gen.generateWorkflowMapping(s, wf, steps, idx, expr, in+"."+ToCamelCase(field.Name), fieldIsPointer(field))
+		}
+	}
+}
+
+// generateWorkflowMapping generates the assignment of expr to target, skipped when the value is missing.
+func (gen *CodeGenerator) generateWorkflowMapping(s *spec.Spec, wf *spec.Workflow, steps []workflowStep, idx int, expr, target string, ptr bool) {
+	access, guards := workflowAccess(s, wf, steps, idx, expr, ptr, false)
+	if len(guards) == 0 {
+		gen.write("\t%s = %s\n", target, access)
+		return
+	}
+	gen.write("\tif %s {\n\t\t%s = %s\n\t}\n", strings.Join(guards, " && "), target, access)
+}
+
+// workflowAccess returns the Go expression evaluating expr as a pointer, if ptr is set, or as a value otherwise,
+// together with the conditions under which it can be evaluated. If nonNil is set, the conditions also ensure
+// that the resulting pointer is not nil.
+func workflowAccess(s *spec.Spec, wf *spec.Workflow, steps []workflowStep, idx int, expr string, ptr, nonNil bool) (string, []string) {
+	// Expressions are validated along with the spec
+	res, _ := s.ResolveExpr(wf, idx, expr)
+
+	var (
+		access   = "in"
+		isPtr    = true
+		nullable = false
+		guards   []string
+	)
+
+	if res.Root != spec.WorkflowInput {
+		for _, step := range steps[:idx] {
+			if step.Name == res.Root {
+				access, isPtr, nullable = step.out, step.ptr, step.Optional
+			}
+		}
+	}
+
+	for _, field := range res.Fields {
+		if nullable {
+			guards = append(guards, access+" != nil")
+		}
+		access += "." + ToCamelCase(field.Name)
+		isPtr = fieldIsPointer(field)
+		nullable = isPtr
+	}
+
+	switch {
+	case isPtr && ptr:
+		if nullable && nonNil {
+			guards = append(guards, access+" != nil")
+		}
+	case isPtr && !ptr:
+		if nullable {
+			guards = append(guards, access+" != nil")
+		}
+		access = "*" + access
+	case !isPtr && ptr:
+		access = "&" + access
+	}
+	return access, guards
+}
+
+// workflowUsedSteps returns the steps whose output is referenced by later steps or by the result.
+func workflowUsedSteps(wf *spec.Workflow) map[string]bool {
+	used := make(map[string]bool)
+	mark := func(expr string) {
+		used[strings.Split(expr, ".")[0]] = true
+	}
+
+	for _, step := range wf.Steps {
+		if src := step.Source(); src != "" {
+			mark(src)
+		}
+		for _, expr := range step.Input {
+			mark(expr)
+		}
+	}
+	for _, expr := range wf.Result {
+		mark(expr)
+	}
+	return used
+}
+
+// fieldIsPointer reports whether the struct field generated for f is a pointer.
+func fieldIsPointer(f spec.Field) bool {
+	_, _, isMap := spec.ParseMapType(f.Type)
+	return f.Optional && !f.Repeated && !isMap
+}
+
+func lowerFirst(s string) string {
+	if len(s) == 0 {
+		return s
+	}
+	return strings.ToLower(s[:1]) + s[1:]
+}
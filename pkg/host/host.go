@@ -0,0 +1,312 @@
+// Copyright (c) 2025 Suricata Contributors
+// Original Author: Stefano Scafiti
+//
+// This file is part of Suricata: Type-Safe AI Agents for Go.
+//
+// Licensed under the MIT License. You may obtain a copy of the License at
+//
+//	https://opensource.org/licenses/MIT
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+// Package host runs the agents of a spec without generating code, exposing their
+// actions over HTTP and MCP. It backs the "suricata serve" command.
+package host
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"maps"
+	"net/http"
+	"reflect"
+	"slices"
+
+	"github.com/ostafen/suricata/pkg/gen"
+	"github.com/ostafen/suricata/pkg/spec"
+	"github.com/ostafen/suricata/runtime"
+	"github.com/ostafen/suricata/runtime/httpserve"
+	"github.com/ostafen/suricata/runtime/mcp"
+	"github.com/xeipuuv/gojsonschema"
+)
+
+var ErrNotFound = errors.New("not found")
+
+// ToolFunc implements a tool of the hosted agents, taking and returning JSON.
+type ToolFunc func(ctx context.Context, in json.RawMessage) (json.RawMessage, error)
+
+// Host runs the agents of a spec.
+type Host struct {
+	spec    *spec.Spec
+	runtime *runtime.Runtime
+	tools   map[string]ToolFunc
+	types   *typeBuilder
+	schemas map[string]gojsonschema.JSONLoader
+}
+
+// New returns a host running the agents of s with invoker.
+// Each tool used by the agents must be implemented by an entry of tools.
+func New(s *spec.Spec, invoker runtime.Invoker, tools map[string]ToolFunc, opts ...runtime.Option) (*Host, error) {
+	for _, agentName := range sortedKeys(s.Agents) {
+		agent := s.Agents[agentName]
+		for _, toolName := range agent.AllTools() {
+			if _, ok := tools[toolName]; !ok {
+				return nil, fmt.Errorf("agent %q: tool %q has no implementation", agentName, toolName)
+			}
+		}
+	}
+
+	schemas := make(map[string]gojsonschema.JSONLoader, len(s.Messages))
+
+	schemaGen := gen.NewJSONSchemaGenerator()
+	for name, msg := range s.Messages {
+		schema, err := schemaGen.GenerateJSONSchema(name, &msg, s.Messages, s.Enums)
+		if err != nil {
+			return nil, fmt.Errorf("message %q: %w", name, err)
+		}
+
+		raw, err := json.Marshal(schema)
+		if err != nil {
+			return nil, err
+		}
+		schemas[name] = runtime.NewSchema(string(raw))
+	}
+
+	return &Host{
+		spec:    s,
+		runtime: runtime.NewRuntime(invoker, opts...),
+		tools:   tools,
+		types:   newTypeBuilder(s),
+		schemas: schemas,
+	}, nil
+}
+
+// Agents returns the names of the hosted agents, in lexicographic order.
+func (h *Host) Agents() []string {
+	return sortedKeys(h.spec.Agents)
+}
+
+// Request returns the runtime request running an action of an agent on the given JSON input.
+func (h *Host) Request(agentName, actionName string, in json.RawMessage) (*runtime.Request, error) {
+	agent, ok := h.spec.Agents[agentName]
+	if !ok {
+		return nil, fmt.Errorf("%w: agent %q", ErrNotFound, agentName)
+	}
+
+	action, ok := agent.Actions[actionName]
+	if !ok {
+		return nil, fmt.Errorf("%w: action %q of agent %q", ErrNotFound, actionName, agentName)
+	}
+
+	input := reflect.New(h.types.messageType(action.Input)).Interface()
+	if err := runtime.UnmarshalValidate(in, input, h.schemas[action.Input]); err != nil {
+		return nil, fmt.Errorf("invalid input: %w", err)
+	}
+
+	var output any = new(string)
+	if !action.IsTextOutput() {
+		output = reflect.New(h.types.messageType(action.Output)).Interface()
+	}
+
+	req := &runtime.Request{
+		Action:         actionName,
+		SkipInput:      action.SkipInput,
+		Instructions:   agent.Instructions,
+		PromptTemplate: action.Prompt,
+		PromptPartials: h.spec.Templates,
+		StrictTemplate: action.StrictTemplate,
+		PromptVersion:  action.PromptVersion,
+		Input:          input,
+		Output:         output,
+		InputSchema:    h.schemas[action.Input],
+	}
+
+	if !action.IsTextOutput() {
+		req.OutputSchema = h.schemas[action.Output]
+	}
+
+	for _, example := range action.Examples {
+		req.Examples = append(req.Examples, runtime.Example{Input: example.Input, Output: example.Output})
+	}
+
+	if rules := h.spec.Rules(action.Output); len(rules) > 0 {
+		req.PostProcessors = []runtime.PostProcessor{runtime.CheckRules(rules...)}
+	}
+
+	cfg := agent.ActionModel(&action)
+	req.ModelOptions = runtime.ModelOptions{Model: cfg.Model, Temperature: cfg.Temperature, MaxTokens: cfg.MaxTokens}
+
+	if tools := agent.ActionTools(&action); len(tools) > 0 {
+		for _, name := range tools {
+			tool := h.spec.Tools[name]
+			req.ToolSpecs = append(req.ToolSpecs, runtime.ToolSpec{
+				Name:             name,
+				Description:      tool.Description,
+				Schema:           h.schemas[tool.Input],
+				RequiresApproval: tool.RequiresApproval(),
+			})
+		}
+		req.ToolUnmarshaller = h.unmarshalTool
+		req.ToolInvoker = h.invokeTool
+	}
+	return req, nil
+}
+
+// Invoke runs an action of an agent on the given JSON input.
+// The output is a string for free-text actions, and a pointer to a struct otherwise.
+// If onDelta is not nil, it receives the chunks of the model responses as they are generated.
+func (h *Host) Invoke(ctx context.Context, agentName, actionName string, in json.RawMessage, onDelta func(delta string)) (any, error) {
+	req, err := h.Request(agentName, actionName, in)
+	if err != nil {
+		return nil, err
+	}
+	req.OnDelta = onDelta
+
+	if err := h.runtime.Invoke(ctx, *req); err != nil {
+		return nil, fmt.Errorf("llm call failed: %w", err)
+	}
+
+	if text, ok := req.Output.(*string); ok {
+		return *text, nil
+	}
+	return req.Output, nil
+}
+
+func (h *Host) unmarshalTool(name string, data []byte) (any, error) {
+	tool, ok := h.spec.Tools[name]
+	if !ok {
+		return nil, fmt.Errorf("no such tool: %q", name)
+	}
+
+	var in json.RawMessage
+	err := runtime.UnmarshalValidate(data, &in, h.schemas[tool.Input])
+	return in, err
+}
+
+func (h *Host) invokeTool(ctx context.Context, name string, in any) (any, error) {
+	return h.callTool(ctx, name, in.(json.RawMessage))
+}
+
+func (h *Host) callTool(ctx context.Context, name string, in json.RawMessage) (any, error) {
+	tool := h.spec.Tools[name]
+
+	out, err := h.tools[name](ctx, in)
+	if err != nil {
+		return nil, err
+	}
+
+	if err := runtime.ValidateRawJSON(out, h.schemas[tool.Output]); err != nil {
+		return nil, fmt.Errorf("tool %q: %w", name, err)
+	}
+	return out, nil
+}
+
+// Handler returns an HTTP handler serving each action as a POST /<agent>/<action> endpoint,
+// following the conventions of the handlers produced by "suricata gen --http".
+func (h *Host) Handler() http.Handler {
+	mux := http.NewServeMux()
+
+	for _, agentName := range h.Agents() {
+		agent := h.spec.Agents[agentName]
+
+		for _, actionName := range sortedKeys(agent.Actions) {
+			action := agent.Actions[actionName]
+			pattern := fmt.Sprintf("POST /%s/%s", agentName, actionName)
+			schema := h.schemas[action.Input]
+
+			if action.Stream {
+				mux.Handle(pattern, httpserve.StreamAction(schema, func(ctx context.Context, in *json.RawMessage) *runtime.Stream[any] {
+					return runtime.StartStream(ctx, func(ctx context.Context, out *any, onDelta func(string)) error {
+						v, err := h.Invoke(ctx, agentName, actionName, *in, onDelta)
+						*out = v
+						return err
+					})
+				}))
+				continue
+			}
+
+			mux.Handle(pattern, httpserve.Action(schema, func(ctx context.Context, in *json.RawMessage) (any, error) {
+				return h.Invoke(ctx, agentName, actionName, *in, nil)
+			}))
+		}
+	}
+	return mux
+}
+
+// MCPServer returns an MCP server exposing the actions and the tools of an agent,
+// following the conventions of the servers produced by "suricata gen --mcp".
+func (h *Host) MCPServer(agentName string) (*mcp.Server, error) {
+	agent, ok := h.spec.Agents[agentName]
+	if !ok {
+		return nil, fmt.Errorf("%w: agent %q", ErrNotFound, agentName)
+	}
+
+	srv := mcp.NewServer(agentName, h.spec.Version)
+
+	for _, actionName := range sortedKeys(agent.Actions) {
+		action := agent.Actions[actionName]
+		srv.AddTool(mcp.Tool{
+			Name:        actionName,
+			Description: action.Description,
+			InputSchema: h.schemas[action.Input],
+			Handler: func(ctx context.Context, args json.RawMessage) (any, error) {
+				return h.Invoke(ctx, agentName, actionName, args, nil)
+			},
+		})
+	}
+
+	for _, toolName := range agent.AllTools() {
+		tool := h.spec.Tools[toolName]
+		srv.AddTool(mcp.Tool{
+			Name:        gen.MCPToolName(toolName),
+			Description: tool.Description,
+			InputSchema: h.schemas[tool.Input],
+			Handler: func(ctx context.Context, args json.RawMessage) (any, error) {
+				if err := runtime.ValidateRawJSON(args, h.schemas[tool.Input]); err != nil {
+					return nil, err
+				}
+				return h.callTool(ctx, toolName, args)
+			},
+		})
+	}
+	return srv, nil
+}
+
+// HTTPTool returns a tool implemented by an HTTP endpoint, which receives the
+// input of each call as the JSON body of a POST request and replies with the output.
+func HTTPTool(url string) ToolFunc {
+	return func(ctx context.Context, in json.RawMessage) (json.RawMessage, error) {
+		req, err := http.NewRequestWithContext(ctx, http.MethodPost, url, bytes.NewReader(in))
+		if err != nil {
+			return nil, err
+		}
+		req.Header.Set("Content-Type", "application/json")
+
+		resp, err := http.DefaultClient.Do(req)
+		if err != nil {
+			return nil, err
+		}
+		defer resp.Body.Close()
+
+		data, err := io.ReadAll(resp.Body)
+		if err != nil {
+			return nil, err
+		}
+
+		if resp.StatusCode/100 != 2 {
+			return nil, fmt.Errorf("%s: unexpected status %s: %s", url, resp.Status, bytes.TrimSpace(data))
+		}
+		return data, nil
+	}
+}
+
+func sortedKeys[T any](m map[string]T) []string {
+	return slices.Sorted(maps.Keys(m))
+}
@@ -0,0 +1,180 @@
+// Copyright (c) 2025 Suricata Contributors
+// Original Author: Stefano Scafiti
+//
+// This file is part of Suricata: Type-Safe AI Agents for Go.
+//
+// Licensed under the MIT License. You may obtain a copy of the License at
+//
+//	https://opensource.org/licenses/MIT
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package host_test
+
+import (
+	"context"
+	"encoding/json"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/ostafen/suricata/pkg/host"
+	"github.com/ostafen/suricata/pkg/spec"
+	"github.com/ostafen/suricata/runtime"
+	"github.com/ostafen/suricata/runtime/runtimetest"
+)
+
+const testSpec = `
+version: 0.0.1
+package: shop
+messages:
+  Query:
+    fields:
+      - name: user_name
+        type: string
+      - name: limit
+        type: int
+        optional: true
+  Item:
+    fields:
+      - name: sku
+        type: string
+    rules:
+      - sku != "unknown"
+  Items:
+    fields:
+      - name: items
+        type: Item
+        repeated: true
+tools:
+  Search:
+    description: Searches the catalog
+    input: Query
+    output: Items
+agents:
+  shop:
+    instructions: You are a shop assistant.
+    tools: [Search]
+    actions:
+      Recommend:
+        input: Query
+        output: Items
+        prompt: Recommend items to {{.UserName}}.
+      Greet:
+        input: Query
+        prompt: Greet {{.UserName}}.
+        tools: []
+`
+
+func loadSpec(t *testing.T) *spec.Spec {
+	t.Helper()
+
+	path := filepath.Join(t.TempDir(), "shop.yml")
+	if err := os.WriteFile(path, []byte(testSpec), 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	s, err := spec.LoadSpec(path)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	return s
+}
+
+func TestHost_Invoke(t *testing.T) {
+	inv := runtimetest.NewInvoker(t)
+	inv.Expect().PromptContains("Recommend items to alice.").RespondToolCall("Search", map[string]any{"user_name": "alice"})
+	inv.Expect().PromptContains(`"sku":"a1"`).RespondFinal(map[string]any{"items": []any{map[string]any{"sku": "a1"}}})
+
+	var searched string
+	tools := map[string]host.ToolFunc{
+		"Search": func(ctx context.Context, in json.RawMessage) (json.RawMessage, error) {
+			searched = string(in)
+			return json.RawMessage(`{"items": [{"sku": "a1"}]}`), nil
+		},
+	}
+
+	h, err := host.New(loadSpec(t), inv, tools)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	out, err := h.Invoke(context.Background(), "shop", "Recommend", json.RawMessage(`{"user_name": "alice"}`), nil)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	if searched != `{"user_name":"alice"}` {
+		t.Errorf("unexpected tool input: %s", searched)
+	}
+
+	data, _ := json.Marshal(out)
+	if string(data) != `{"items":[{"sku":"a1"}]}` {
+		t.Errorf("unexpected output: %s", data)
+	}
+
+	if _, err := h.Invoke(context.Background(), "shop", "Missing", nil, nil); err == nil {
+		t.Errorf("expected error for unknown action")
+	}
+}
+
+func TestHost_MissingTool(t *testing.T) {
+	if _, err := host.New(loadSpec(t), runtimetest.NewInvoker(t), nil); err == nil || !strings.Contains(err.Error(), "no implementation") {
+		t.Errorf("expected missing tool error, got %v", err)
+	}
+}
+
+func TestHost_Rules(t *testing.T) {
+	inv := runtimetest.NewInvoker(t)
+	inv.Expect().RespondFinal(map[string]any{"items": []any{map[string]any{"sku": "unknown"}}})
+	inv.Expect().PromptContains("items.0.sku: must satisfy sku != \"unknown\"").RespondFinal(map[string]any{"items": []any{map[string]any{"sku": "a1"}}})
+
+	h, err := host.New(loadSpec(t), inv, map[string]host.ToolFunc{"Search": nil}, runtime.WithRetryPolicy(runtime.DefaultRetryPolicy()))
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	out, err := h.Invoke(context.Background(), "shop", "Recommend", json.RawMessage(`{"user_name": "alice"}`), nil)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	data, _ := json.Marshal(out)
+	if string(data) != `{"items":[{"sku":"a1"}]}` {
+		t.Errorf("unexpected output: %s", data)
+	}
+}
+
+func TestHost_Handler(t *testing.T) {
+	inv := runtimetest.NewInvoker(t)
+	inv.Expect().PromptContains("Greet bob.").Respond("Hello, Bob!")
+
+	tools := map[string]host.ToolFunc{"Search": nil}
+
+	h, err := host.New(loadSpec(t), inv, tools)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	srv := httptest.NewServer(h.Handler())
+	defer srv.Close()
+
+	resp, err := http.Post(srv.URL+"/shop/Greet", "application/json", strings.NewReader(`{"user_name": "bob"}`))
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	defer resp.Body.Close()
+
+	body, _ := io.ReadAll(resp.Body)
+	if resp.StatusCode != http.StatusOK || strings.TrimSpace(string(body)) != `"Hello, Bob!"` {
+		t.Errorf("unexpected response: %d %s", resp.StatusCode, body)
+	}
+}
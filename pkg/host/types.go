@@ -0,0 +1,126 @@
+// Copyright (c) 2025 Suricata Contributors
+// Original Author: Stefano Scafiti
+//
+// This file is part of Suricata: Type-Safe AI Agents for Go.
+//
+// Licensed under the MIT License. You may obtain a copy of the License at
+//
+//	https://opensource.org/licenses/MIT
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package host
+
+import (
+	"reflect"
+	"time"
+
+	"github.com/ostafen/suricata/pkg/gen"
+	"github.com/ostafen/suricata/pkg/spec"
+	"github.com/ostafen/suricata/runtime"
+)
+
+var (
+	anyType        = reflect.TypeOf((*any)(nil)).Elem()
+	timeType       = reflect.TypeOf(time.Time{})
+	attachmentType = reflect.TypeOf(runtime.Attachment{})
+	decimalType    = reflect.TypeOf(runtime.Decimal{})
+)
+
+// typeBuilder builds, at run time, struct types shaped like the ones generated for spec messages,
+// so that prompt templates written against generated code work unchanged.
+type typeBuilder struct {
+	spec     *spec.Spec
+	types    map[string]reflect.Type
+	building map[string]bool
+}
+
+func newTypeBuilder(s *spec.Spec) *typeBuilder {
+	return &typeBuilder{
+		spec:     s,
+		types:    make(map[string]reflect.Type),
+		building: make(map[string]bool),
+	}
+}
+
+// messageType returns the struct type of the named message.
+// Unions, and messages referencing themselves, are decoded as plain JSON values.
+func (b *typeBuilder) messageType(name string) reflect.Type {
+	if t, ok := b.types[name]; ok {
+		return t
+	}
+
+	msg := b.spec.Messages[name]
+	if msg.IsUnion() || b.building[name] {
+		return anyType
+	}
+
+	b.building[name] = true
+	defer delete(b.building, name)
+
+	fields := make([]reflect.StructField, 0, len(msg.Fields))
+	for _, field := range msg.Fields {
+		tag := field.Name
+		if field.Optional || field.Repeated {
+			tag += ",omitempty"
+		}
+
+		fields = append(fields, reflect.StructField{
+			Name: gen.ToCamelCase(field.Name),
+			Type: b.fieldType(&field),
+			Tag:  reflect.StructTag(`json:"` + tag + `"`),
+		})
+	}
+
+	t := reflect.StructOf(fields)
+	b.types[name] = t
+	return t
+}
+
+func (b *typeBuilder) fieldType(field *spec.Field) reflect.Type {
+	t := b.typeForName(field.Type)
+
+	_, _, isMap := spec.ParseMapType(field.Type)
+	if field.Optional && !field.Repeated && !isMap && t != anyType {
+		t = reflect.PointerTo(t)
+	}
+
+	if field.Repeated {
+		t = reflect.SliceOf(t)
+	}
+	return t
+}
+
+func (b *typeBuilder) typeForName(name string) reflect.Type {
+	if _, value, ok := spec.ParseMapType(name); ok {
+		return reflect.MapOf(reflect.TypeOf(""), b.typeForName(value))
+	}
+
+	switch name {
+	case "string", "uuid", "url":
+		return reflect.TypeOf("")
+	case "int", "int32", "int64":
+		return reflect.TypeOf(0)
+	case "float", "float32", "float64":
+		return reflect.TypeOf(0.0)
+	case "bool":
+		return reflect.TypeOf(false)
+	case "datetime":
+		return timeType
+	case "decimal", "money":
+		return decimalType
+	case "bytes":
+		return reflect.TypeOf([]byte(nil))
+	case "image", "file":
+		return attachmentType
+	}
+
+	if _, ok := b.spec.Enums[name]; ok {
+		return reflect.TypeOf("")
+	}
+	return b.messageType(name)
+}
@@ -0,0 +1,376 @@
+// Copyright (c) 2025 Suricata Contributors
+// Original Author: Stefano Scafiti
+//
+// This file is part of Suricata: Type-Safe AI Agents for Go.
+//
+// Licensed under the MIT License. You may obtain a copy of the License at
+//
+//	https://opensource.org/licenses/MIT
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package importer
+
+import (
+	"fmt"
+	"go/ast"
+	"go/constant"
+	"go/parser"
+	"go/token"
+	"go/types"
+	"maps"
+	"reflect"
+	"slices"
+	"strings"
+
+	"github.com/ostafen/suricata/pkg/spec"
+)
+
+// goBasicTypes maps Go predeclared types to spec primitive types.
+var goBasicTypes = map[string]string{
+	"string":  "string",
+	"bool":    "bool",
+	"int":     "int",
+	"int8":    "int",
+	"int16":   "int",
+	"int32":   "int",
+	"int64":   "int",
+	"uint":    "int",
+	"uint8":   "int",
+	"uint16":  "int",
+	"uint32":  "int",
+	"uint64":  "int",
+	"float32": "float",
+	"float64": "float",
+	"rune":    "int",
+	"byte":    "int",
+}
+
+// FromGo converts the exported struct types declared by the Go source files of a package into spec
+// messages, following their JSON encoding: fields are named after their json tag, and pointer fields,
+// or fields tagged with omitempty, are optional. String types with constants of their type become enums.
+// Descriptions are taken from the description tag of fields or from their doc comments, while the enum
+// and format tags become constraints, as for runtime/tools. When roots is not empty, only the listed
+// types and the ones they reference are converted.
+func FromGo(sources [][]byte, pkg string, roots ...string) (*spec.Spec, error) {
+	imp := &goImporter{
+		types:  make(map[string]*ast.TypeSpec),
+		docs:   make(map[string]string),
+		consts: make(map[string][]string),
+		spec: &spec.Spec{
+			Version:  "0.0.1",
+			Package:  pkg,
+			Enums:    make(map[string]spec.Enum),
+			Messages: make(map[string]spec.Message),
+		},
+	}
+
+	fset := token.NewFileSet()
+	for i, src := range sources {
+		f, err := parser.ParseFile(fset, "", src, parser.ParseComments)
+		if err != nil {
+			return nil, fmt.Errorf("go: file %d: %w", i+1, err)
+		}
+		imp.declare(f)
+	}
+
+	if len(roots) == 0 {
+		for _, name := range slices.Sorted(maps.Keys(imp.types)) {
+			if _, ok := imp.types[name].Type.(*ast.StructType); ok && ast.IsExported(name) {
+				roots = append(roots, name)
+			}
+		}
+	}
+
+	for _, name := range roots {
+		ts, ok := imp.types[name]
+		if !ok {
+			return nil, fmt.Errorf("go: undefined type %q", name)
+		}
+		if _, ok := ts.Type.(*ast.StructType); !ok {
+			return nil, fmt.Errorf("go: type %q is not a struct", name)
+		}
+		if _, err := imp.namedType(name); err != nil {
+			return nil, fmt.Errorf("go: %w", err)
+		}
+	}
+
+	if err := imp.spec.Validate(); err != nil {
+		return nil, fmt.Errorf("go: %w", err)
+	}
+	return imp.spec, nil
+}
+
+type goImporter struct {
+	types  map[string]*ast.TypeSpec // Types declared at package level
+	docs   map[string]string        // Doc comments of the declared types
+	consts map[string][]string      // String constants of each declared type, in order of declaration
+	spec   *spec.Spec
+}
+
+// declare registers the types and the typed string constants declared by f.
+func (imp *goImporter) declare(f *ast.File) {
+	for _, decl := range f.Decls {
+		gen, ok := decl.(*ast.GenDecl)
+		if !ok {
+			continue
+		}
+
+		var typ ast.Expr // Type of the constants of the block, repeated by specs omitting it
+		for _, s := range gen.Specs {
+			switch s := s.(type) {
+			case *ast.TypeSpec:
+				if s.TypeParams != nil {
+					continue
+				}
+				imp.types[s.Name.Name] = s
+				doc := s.Doc
+				if doc == nil && len(gen.Specs) == 1 {
+					doc = gen.Doc
+				}
+				imp.docs[s.Name.Name] = docText(doc)
+			case *ast.ValueSpec:
+				if gen.Tok != token.CONST {
+					continue
+				}
+				if s.Type != nil || len(s.Values) > 0 {
+					typ = s.Type
+				}
+
+				ident, ok := typ.(*ast.Ident)
+				if !ok {
+					continue
+				}
+				for _, value := range s.Values {
+					if lit, ok := value.(*ast.BasicLit); ok && lit.Kind == token.STRING {
+						imp.consts[ident.Name] = append(imp.consts[ident.Name], constant.StringVal(constant.MakeFromLiteral(lit.Value, lit.Kind, 0)))
+					}
+				}
+			}
+		}
+	}
+}
+
+// namedType returns the spec type of the declared type name, converting it on first use.
+func (imp *goImporter) namedType(name string) (string, error) {
+	if _, ok := imp.spec.Messages[name]; ok {
+		return name, nil
+	}
+	if _, ok := imp.spec.Enums[name]; ok {
+		return name, nil
+	}
+
+	ts := imp.types[name]
+	if ts.Assign.IsValid() {
+		// Alias
+		return imp.fieldType(name, ts.Type)
+	}
+
+	switch t := ts.Type.(type) {
+	case *ast.StructType:
+		// Registered before converting the fields, for recursive types
+		imp.spec.Messages[name] = spec.Message{}
+		msg, err := imp.message(name, t)
+		if err != nil {
+			return "", err
+		}
+		imp.spec.Messages[name] = msg
+		return name, nil
+	case *ast.Ident:
+		if t.Name == "string" && len(imp.consts[name]) > 0 {
+			imp.spec.Enums[name] = spec.Enum{Description: imp.docs[name], Values: imp.consts[name]}
+			return name, nil
+		}
+	}
+	return imp.fieldType(name, ts.Type)
+}
+
+func (imp *goImporter) message(name string, st *ast.StructType) (spec.Message, error) {
+	var msg spec.Message
+	for _, f := range st.Fields.List {
+		tag := reflect.StructTag("")
+		if f.Tag != nil {
+			tag = reflect.StructTag(constant.StringVal(constant.MakeFromLiteral(f.Tag.Value, token.STRING, 0)))
+		}
+
+		jsonName, opts, _ := strings.Cut(tag.Get("json"), ",")
+		if jsonName == "-" && opts == "" {
+			continue
+		}
+
+		if len(f.Names) == 0 {
+			// Embedded structs are flattened by the JSON encoding, unless named by a tag
+			embedded, err := imp.embedded(name, f.Type, jsonName)
+			if err != nil {
+				return msg, err
+			}
+			msg.Fields = append(msg.Fields, embedded...)
+			continue
+		}
+
+		for _, ident := range f.Names {
+			if !ident.IsExported() {
+				continue
+			}
+
+			field, err := imp.field(name, ident.Name, f, tag)
+			if err != nil {
+				return msg, fmt.Errorf("message %q: field %q: %w", name, ident.Name, err)
+			}
+			msg.Fields = append(msg.Fields, field)
+		}
+	}
+	return msg, nil
+}
+
+// embedded returns the fields promoted by an embedded field of the struct name.
+func (imp *goImporter) embedded(name string, typ ast.Expr, jsonName string) ([]spec.Field, error) {
+	if star, ok := typ.(*ast.StarExpr); ok {
+		typ = star.X
+	}
+
+	ident, ok := typ.(*ast.Ident)
+	if !ok || !ident.IsExported() && jsonName == "" {
+		return nil, nil
+	}
+
+	ts, ok := imp.types[ident.Name]
+	if !ok {
+		return nil, fmt.Errorf("message %q: embedded type %q is not declared by the package", name, ident.Name)
+	}
+	st, ok := ts.Type.(*ast.StructType)
+	if !ok || jsonName != "" {
+		t, err := imp.namedType(ident.Name)
+		if err != nil {
+			return nil, err
+		}
+		return []spec.Field{{Name: firstNonEmpty(jsonName, ident.Name), Type: t}}, nil
+	}
+
+	msg, err := imp.message(ident.Name, st)
+	return msg.Fields, err
+}
+
+func (imp *goImporter) field(msgName, name string, f *ast.Field, tag reflect.StructTag) (spec.Field, error) {
+	jsonName, opts, _ := strings.Cut(tag.Get("json"), ",")
+
+	field := spec.Field{
+		Name:        firstNonEmpty(jsonName, name),
+		Description: firstNonEmpty(tag.Get("description"), docText(firstNonNil(f.Doc, f.Comment))),
+		Format:      tag.Get("format"),
+	}
+	if enum := tag.Get("enum"); enum != "" {
+		field.Enum = strings.Split(enum, ",")
+	}
+
+	typ := f.Type
+	if star, ok := typ.(*ast.StarExpr); ok {
+		field.Optional = true
+		typ = star.X
+	}
+
+	switch t := typ.(type) {
+	case *ast.ArrayType:
+		if ident, ok := t.Elt.(*ast.Ident); ok && (ident.Name == "byte" || ident.Name == "uint8") {
+			// Encoded as base64
+			field.Type = "bytes"
+			break
+		}
+
+		field.Repeated = true
+		elem := t.Elt
+		if star, ok := elem.(*ast.StarExpr); ok {
+			elem = star.X
+		}
+		if _, ok := elem.(*ast.ArrayType); ok {
+			return field, fmt.Errorf("nested lists are not supported")
+		}
+
+		var err error
+		if field.Type, err = imp.fieldType(msgName+identifier(field.Name), elem); err != nil {
+			return field, err
+		}
+	default:
+		var err error
+		if field.Type, err = imp.fieldType(msgName+identifier(field.Name), typ); err != nil {
+			return field, err
+		}
+	}
+
+	_, _, isMap := spec.ParseMapType(field.Type)
+	if slices.Contains(strings.Split(opts, ","), "omitempty") && !field.Repeated && !isMap {
+		field.Optional = true
+	}
+	return field, nil
+}
+
+// fieldType returns the spec type of the Go type expression typ. Anonymous structs become messages named name.
+func (imp *goImporter) fieldType(name string, typ ast.Expr) (string, error) {
+	switch t := typ.(type) {
+	case *ast.Ident:
+		if basic, ok := goBasicTypes[t.Name]; ok {
+			return basic, nil
+		}
+		if _, ok := imp.types[t.Name]; ok {
+			return imp.namedType(t.Name)
+		}
+	case *ast.SelectorExpr:
+		if pkg, ok := t.X.(*ast.Ident); ok {
+			switch pkg.Name + "." + t.Sel.Name {
+			case "time.Time":
+				return "datetime", nil
+			case "runtime.Decimal", "decimal.Decimal":
+				return "decimal", nil
+			case "uuid.UUID":
+				return "uuid", nil
+			case "url.URL":
+				return "url", nil
+			}
+		}
+	case *ast.StarExpr:
+		return imp.fieldType(name, t.X)
+	case *ast.MapType:
+		if key, ok := t.Key.(*ast.Ident); !ok || goBasicTypes[key.Name] != "string" {
+			return "", fmt.Errorf("unsupported map key type %s (only string is allowed)", types.ExprString(t.Key))
+		}
+
+		value := t.Value
+		if star, ok := value.(*ast.StarExpr); ok {
+			value = star.X
+		}
+		valueType, err := imp.fieldType(name, value)
+		if err != nil {
+			return "", err
+		}
+		return "map<string, " + valueType + ">", nil
+	case *ast.StructType:
+		if _, ok := imp.spec.Messages[name]; ok {
+			return "", fmt.Errorf("message %q is already defined", name)
+		}
+		imp.spec.Messages[name] = spec.Message{}
+		msg, err := imp.message(name, t)
+		if err != nil {
+			return "", err
+		}
+		imp.spec.Messages[name] = msg
+		return name, nil
+	}
+	return "", fmt.Errorf("unsupported type %s", types.ExprString(typ))
+}
+
+func docText(doc *ast.CommentGroup) string {
+	return strings.TrimSpace(doc.Text())
+}
+
+func firstNonNil(groups ...*ast.CommentGroup) *ast.CommentGroup {
+	for _, g := range groups {
+		if g != nil {
+			return g
+		}
+	}
+	return nil
+}
@@ -0,0 +1,106 @@
+// Copyright (c) 2025 Suricata Contributors
+// Original Author: Stefano Scafiti
+//
+// This file is part of Suricata: Type-Safe AI Agents for Go.
+//
+// Licensed under the MIT License. You may obtain a copy of the License at
+//
+//	https://opensource.org/licenses/MIT
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package importer_test
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/ostafen/suricata/pkg/importer"
+)
+
+func TestFromGo(t *testing.T) {
+	types := "package shop\n\n" +
+		"import \"time\"\n\n" +
+		"// Status of an order\n" +
+		"type Status string\n\n" +
+		"const (\n" +
+		"\tStatusOpen Status = \"open\"\n" +
+		"\tStatusPaid Status = \"paid\"\n" +
+		")\n\n" +
+		"type Audit struct {\n" +
+		"\tCreatedAt time.Time `json:\"created_at\"`\n" +
+		"}\n\n" +
+		"type Order struct {\n" +
+		"\tAudit\n\n" +
+		"\t// Unique identifier\n" +
+		"\tID       string            `json:\"id\"`\n" +
+		"\tItems    []*Item           `json:\"items,omitempty\"`\n" +
+		"\tQuantity map[string]int    `json:\"quantities\"`\n" +
+		"\tStatus   Status            `json:\"status\"`\n" +
+		"\tNote     *string           `json:\"note\" description:\"Free text\"`\n" +
+		"\tCurrency string            `json:\"currency,omitempty\" enum:\"EUR,USD\"`\n" +
+		"\tShipping struct{ City string } `json:\"shipping\"`\n" +
+		"\tinternal string\n" +
+		"\tIgnored  string `json:\"-\"`\n" +
+		"}\n"
+
+	items := "package shop\n\n" +
+		"import \"net/url\"\n\n" +
+		"type Item struct {\n" +
+		"\tSKU   string   `json:\"sku\"`\n" +
+		"\tPrice float64  `json:\"price\"` // Unit price\n" +
+		"\tData  []byte   `json:\"data\"`\n" +
+		"\tLink  *url.URL `json:\"link\"`\n" +
+		"}\n"
+
+	s, err := importer.FromGo([][]byte{[]byte(types), []byte(items)}, "shop")
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	if enum := s.Enums["Status"]; strings.Join(enum.Values, ",") != "open,paid" || enum.Description != "Status of an order" {
+		t.Errorf("unexpected Status enum: %+v", enum)
+	}
+
+	var fields []string
+	for _, f := range s.Messages["Order"].Fields {
+		fields = append(fields, f.Name+":"+f.Type)
+	}
+
+	expected := "created_at:datetime,id:string,items:Item,quantities:map<string, int>,status:Status,note:string,currency:string,shipping:OrderShipping"
+	if got := strings.Join(fields, ","); got != expected {
+		t.Errorf("expected fields %s, got %s", expected, got)
+	}
+
+	order := s.Messages["Order"].Fields
+	if order[1].Description != "Unique identifier" || order[5].Description != "Free text" {
+		t.Errorf("unexpected descriptions %q and %q", order[1].Description, order[5].Description)
+	}
+	if !order[2].Repeated || order[2].Optional || !order[5].Optional || !order[6].Optional {
+		t.Errorf("expected repeated items, and optional note and currency")
+	}
+	if strings.Join(order[6].Enum, ",") != "EUR,USD" {
+		t.Errorf("expected the enum constraint of currency, got %v", order[6].Enum)
+	}
+
+	item := s.Messages["Item"].Fields
+	if item[1].Description != "Unit price" || item[2].Type != "bytes" || item[3].Type != "url" {
+		t.Errorf("unexpected Item fields %+v", item)
+	}
+	if city := s.Messages["OrderShipping"].Fields; len(city) != 1 || city[0].Name != "City" {
+		t.Errorf("expected the anonymous struct to become a message, got %+v", city)
+	}
+}
+
+func TestFromGo_UnsupportedType(t *testing.T) {
+	src := "package shop\n\ntype A struct {\n\tB any `json:\"b\"`\n}\n"
+
+	_, err := importer.FromGo([][]byte{[]byte(src)}, "shop")
+	if err == nil || !strings.Contains(err.Error(), "unsupported type any") {
+		t.Errorf("expected unsupported type error, got %v", err)
+	}
+}
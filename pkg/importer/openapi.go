@@ -0,0 +1,483 @@
+// Copyright (c) 2025 Suricata Contributors
+// Original Author: Stefano Scafiti
+//
+// This file is part of Suricata: Type-Safe AI Agents for Go.
+//
+// Licensed under the MIT License. You may obtain a copy of the License at
+//
+//	https://opensource.org/licenses/MIT
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+// Package importer converts existing API contracts into suricata specs.
+package importer
+
+import (
+	"fmt"
+	"maps"
+	"slices"
+	"strings"
+	"unicode"
+
+	"gopkg.in/yaml.v3"
+
+	"github.com/ostafen/suricata/pkg/spec"
+)
+
+// openAPIDoc is the subset of an OpenAPI 3 document used by the importer.
+type openAPIDoc struct {
+	Paths      map[string]map[string]*openAPIOperation `yaml:"paths"`
+	Components struct {
+		Schemas map[string]*openAPISchema `yaml:"schemas"`
+	} `yaml:"components"`
+}
+
+type openAPIOperation struct {
+	OperationID string             `yaml:"operationId"`
+	Summary     string             `yaml:"summary"`
+	Description string             `yaml:"description"`
+	Parameters  []openAPIParameter `yaml:"parameters"`
+	RequestBody *struct {
+		Required bool                        `yaml:"required"`
+		Content  map[string]openAPIMediaType `yaml:"content"`
+	} `yaml:"requestBody"`
+	Responses map[string]struct {
+		Content map[string]openAPIMediaType `yaml:"content"`
+	} `yaml:"responses"`
+}
+
+type openAPIParameter struct {
+	Name        string         `yaml:"name"`
+	In          string         `yaml:"in"`
+	Description string         `yaml:"description"`
+	Required    bool           `yaml:"required"`
+	Schema      *openAPISchema `yaml:"schema"`
+}
+
+type openAPIMediaType struct {
+	Schema *openAPISchema `yaml:"schema"`
+}
+
+type openAPISchema struct {
+	Ref                  string                    `yaml:"$ref"`
+	Type                 string                    `yaml:"type"`
+	Format               string                    `yaml:"format"`
+	Description          string                    `yaml:"description"`
+	Properties           map[string]*openAPISchema `yaml:"properties"`
+	Required             []string                  `yaml:"required"`
+	Items                *openAPISchema            `yaml:"items"`
+	AdditionalProperties any                       `yaml:"additionalProperties"`
+	Enum                 []any                     `yaml:"enum"`
+	AllOf                []*openAPISchema          `yaml:"allOf"`
+	OneOf                []*openAPISchema          `yaml:"oneOf"`
+	Minimum              *float64                  `yaml:"minimum"`
+	Maximum              *float64                  `yaml:"maximum"`
+	MinLength            *int                      `yaml:"minLength"`
+	MaxLength            *int                      `yaml:"maxLength"`
+	Pattern              string                    `yaml:"pattern"`
+}
+
+var httpMethods = []string{"get", "put", "post", "delete", "patch", "head", "options"}
+
+// FromOpenAPI converts the operations of an OpenAPI 3 document, in YAML or JSON format,
+// into spec tools. Each operation becomes a tool whose input message collects the path,
+// query and header parameters, plus the JSON request body, and whose output message
+// is the JSON payload of the first successful response.
+func FromOpenAPI(data []byte, pkg string) (*spec.Spec, error) {
+	var doc openAPIDoc
+	if err := yaml.Unmarshal(data, &doc); err != nil {
+		return nil, fmt.Errorf("openapi: unmarshal: %w", err)
+	}
+
+	imp := &openAPIImporter{
+		doc: &doc,
+		spec: &spec.Spec{
+			Version:  "0.0.1",
+			Package:  pkg,
+			Enums:    make(map[string]spec.Enum),
+			Messages: make(map[string]spec.Message),
+			Tools:    make(map[string]spec.Tool),
+		},
+	}
+
+	for _, name := range slices.Sorted(maps.Keys(doc.Components.Schemas)) {
+		// Array schemas are inlined as repeated fields where they are referenced
+		if doc.Components.Schemas[name].Type == "array" {
+			continue
+		}
+		if _, err := imp.namedType(identifier(name), doc.Components.Schemas[name]); err != nil {
+			return nil, fmt.Errorf("openapi: schema %q: %w", name, err)
+		}
+	}
+
+	for _, path := range slices.Sorted(maps.Keys(doc.Paths)) {
+		for _, method := range httpMethods {
+			op, ok := doc.Paths[path][method]
+			if !ok {
+				continue
+			}
+
+			if err := imp.importOperation(method, path, op); err != nil {
+				return nil, fmt.Errorf("openapi: %s %s: %w", strings.ToUpper(method), path, err)
+			}
+		}
+	}
+
+	if err := imp.spec.Validate(); err != nil {
+		return nil, fmt.Errorf("openapi: %w", err)
+	}
+	return imp.spec, nil
+}
+
+type openAPIImporter struct {
+	doc  *openAPIDoc
+	spec *spec.Spec
+}
+
+func (imp *openAPIImporter) importOperation(method, path string, op *openAPIOperation) error {
+	name := identifier(op.OperationID)
+	if name == "" {
+		name = identifier(method + " " + strings.NewReplacer("{", "by ", "}", "").Replace(path))
+	}
+
+	if _, exists := imp.spec.Tools[name]; exists {
+		return fmt.Errorf("duplicate tool %q", name)
+	}
+
+	input := spec.Message{}
+	for _, param := range op.Parameters {
+		if param.In == "cookie" {
+			continue
+		}
+
+		field, err := imp.field(name+identifier(param.Name), param.Name, param.Schema, param.Required)
+		if err != nil {
+			return fmt.Errorf("parameter %q: %w", param.Name, err)
+		}
+		if field.Description == "" {
+			field.Description = param.Description
+		}
+		input.Fields = append(input.Fields, field)
+	}
+
+	if op.RequestBody != nil {
+		if media, ok := op.RequestBody.Content["application/json"]; ok && media.Schema != nil {
+			field, err := imp.field(name+"Body", "body", media.Schema, op.RequestBody.Required)
+			if err != nil {
+				return fmt.Errorf("request body: %w", err)
+			}
+			input.Fields = append(input.Fields, field)
+		}
+	}
+
+	output, err := imp.responseType(name, op)
+	if err != nil {
+		return err
+	}
+
+	imp.spec.Messages[name+"Request"] = input
+	imp.spec.Tools[name] = spec.Tool{
+		Description: strings.TrimSpace(firstNonEmpty(op.Summary, op.Description)),
+		Input:       name + "Request",
+		Output:      output,
+	}
+	return nil
+}
+
+// responseType returns the message of the JSON payload of the first 2xx response.
+// Payloads which are not objects are wrapped into a message with a single "result" field.
+func (imp *openAPIImporter) responseType(name string, op *openAPIOperation) (string, error) {
+	for _, code := range slices.Sorted(maps.Keys(op.Responses)) {
+		if !strings.HasPrefix(code, "2") {
+			continue
+		}
+
+		media, ok := op.Responses[code].Content["application/json"]
+		if !ok || media.Schema == nil {
+			break
+		}
+
+		if ref := media.Schema.Ref; ref != "" {
+			if _, isMessage := imp.spec.Messages[identifier(refName(ref))]; isMessage {
+				return identifier(refName(ref)), nil
+			}
+		} else if media.Schema.Type == "object" && len(media.Schema.Properties) > 0 {
+			return imp.namedType(name+"Response", media.Schema)
+		}
+
+		field, err := imp.field(name+"Result", "result", media.Schema, true)
+		if err != nil {
+			return "", fmt.Errorf("response %s: %w", code, err)
+		}
+
+		imp.spec.Messages[name+"Response"] = spec.Message{Fields: []spec.Field{field}}
+		return name + "Response", nil
+	}
+
+	imp.spec.Messages[name+"Response"] = spec.Message{}
+	return name + "Response", nil
+}
+
+// field converts the schema of a property into a spec field. Inline object and enum
+// schemas are turned into messages and enums called typeName.
+func (imp *openAPIImporter) field(typeName, name string, schema *openAPISchema, required bool) (spec.Field, error) {
+	field := spec.Field{Name: name, Optional: !required}
+	if schema == nil {
+		field.Type = "string"
+		return field, nil
+	}
+
+	if schema.Ref != "" {
+		if resolved, ok := imp.doc.Components.Schemas[refName(schema.Ref)]; ok && resolved.Type == "array" {
+			schema = resolved
+		}
+	}
+
+	field.Description = strings.TrimSpace(schema.Description)
+
+	if schema.Type == "array" {
+		if schema.Items == nil {
+			return field, fmt.Errorf("array without items")
+		}
+		if schema.Items.Type == "array" {
+			return field, fmt.Errorf("nested arrays are not supported")
+		}
+
+		field.Repeated = true
+		field.Optional = false
+		schema = schema.Items
+	}
+
+	t, err := imp.typeOf(typeName, schema)
+	if err != nil {
+		return field, err
+	}
+	field.Type = t
+
+	// Validation constraints are only supported on primitive types
+	switch {
+	case t == "string":
+		field.MinLength = schema.MinLength
+		field.MaxLength = schema.MaxLength
+		field.Pattern = schema.Pattern
+		if spec.IsKnownFormat(schema.Format) {
+			field.Format = schema.Format
+		}
+	case t == "int" || t == "float":
+		field.Min = schema.Minimum
+		field.Max = schema.Maximum
+	}
+	return field, nil
+}
+
+// typeOf returns the spec type of schema, defining a message or an enum called typeName if needed.
+func (imp *openAPIImporter) typeOf(typeName string, schema *openAPISchema) (string, error) {
+	if schema.Ref != "" {
+		return imp.refType(schema.Ref)
+	}
+
+	switch schema.Type {
+	case "string":
+		if len(schema.Enum) > 0 {
+			return imp.namedType(typeName, schema)
+		}
+		switch schema.Format {
+		case "date-time":
+			return "datetime", nil
+		case "byte":
+			return "bytes", nil
+		case "decimal":
+			return "decimal", nil
+		case "uuid":
+			return "uuid", nil
+		case "uri", "url":
+			return "url", nil
+		}
+		return "string", nil
+	case "integer":
+		return "int", nil
+	case "number":
+		return "float", nil
+	case "boolean":
+		return "bool", nil
+	case "object", "":
+		if valueSchema, ok := schema.additionalPropertiesSchema(); ok && len(schema.Properties) == 0 {
+			valueType, err := imp.typeOf(typeName+"Value", valueSchema)
+			if err != nil {
+				return "", err
+			}
+			return "map<string, " + valueType + ">", nil
+		}
+		return imp.namedType(typeName, schema)
+	}
+	return "", fmt.Errorf("unsupported type %q", schema.Type)
+}
+
+// namedType defines the message or the enum called name for schema, unless the schema
+// is a primitive, in which case its type is returned.
+func (imp *openAPIImporter) namedType(name string, schema *openAPISchema) (string, error) {
+	if _, ok := imp.spec.Messages[name]; ok {
+		return name, nil
+	}
+	if _, ok := imp.spec.Enums[name]; ok {
+		return name, nil
+	}
+
+	if schema.Ref != "" {
+		return imp.refType(schema.Ref)
+	}
+
+	if schema.Type == "string" && len(schema.Enum) > 0 {
+		enum := spec.Enum{Description: strings.TrimSpace(schema.Description)}
+		for _, v := range schema.Enum {
+			enum.Values = append(enum.Values, fmt.Sprint(v))
+		}
+		imp.spec.Enums[name] = enum
+		return name, nil
+	}
+
+	if len(schema.OneOf) > 0 {
+		return imp.unionType(name, schema)
+	}
+
+	if schema.Type != "object" && schema.Type != "" {
+		return imp.typeOf(name, schema)
+	}
+
+	// Reserve the name first, so that recursive references resolve to it
+	imp.spec.Messages[name] = spec.Message{}
+
+	props, required, err := imp.objectProperties(name, schema)
+	if err != nil {
+		return "", err
+	}
+
+	var msg spec.Message
+	for _, propName := range slices.Sorted(maps.Keys(props)) {
+		prop := props[propName]
+
+		field, err := imp.field(prop.owner+identifier(propName), propName, prop.schema, slices.Contains(required, propName))
+		if err != nil {
+			return "", fmt.Errorf("property %q: %w", propName, err)
+		}
+		msg.Fields = append(msg.Fields, field)
+	}
+
+	imp.spec.Messages[name] = msg
+	return name, nil
+}
+
+// property is a property of an object schema, along with the name of the schema declaring it,
+// used to name the inline types of the property.
+type property struct {
+	owner  string
+	schema *openAPISchema
+}
+
+// objectProperties returns the properties of an object schema, merging the ones of its allOf schemas.
+func (imp *openAPIImporter) objectProperties(name string, schema *openAPISchema) (map[string]property, []string, error) {
+	props := make(map[string]property, len(schema.Properties))
+	for propName, propSchema := range schema.Properties {
+		props[propName] = property{owner: name, schema: propSchema}
+	}
+	required := slices.Clone(schema.Required)
+
+	for _, sub := range schema.AllOf {
+		owner := name
+		if sub.Ref != "" {
+			resolved, ok := imp.doc.Components.Schemas[refName(sub.Ref)]
+			if !ok {
+				return nil, nil, fmt.Errorf("unresolved reference %q", sub.Ref)
+			}
+			owner, sub = identifier(refName(sub.Ref)), resolved
+		}
+
+		subProps, subRequired, err := imp.objectProperties(owner, sub)
+		if err != nil {
+			return nil, nil, err
+		}
+		maps.Copy(props, subProps)
+		required = append(required, subRequired...)
+	}
+	return props, required, nil
+}
+
+func (imp *openAPIImporter) unionType(name string, schema *openAPISchema) (string, error) {
+	var msg spec.Message
+	for _, variant := range schema.OneOf {
+		if variant.Ref == "" {
+			return "", fmt.Errorf("oneOf variants must be references to object schemas")
+		}
+
+		t, err := imp.refType(variant.Ref)
+		if err != nil {
+			return "", err
+		}
+		msg.OneOf = append(msg.OneOf, t)
+	}
+
+	imp.spec.Messages[name] = msg
+	return name, nil
+}
+
+func (imp *openAPIImporter) refType(ref string) (string, error) {
+	schema, ok := imp.doc.Components.Schemas[refName(ref)]
+	if !ok {
+		return "", fmt.Errorf("unresolved reference %q", ref)
+	}
+	return imp.namedType(identifier(refName(ref)), schema)
+}
+
+func (schema *openAPISchema) additionalPropertiesSchema() (*openAPISchema, bool) {
+	node, ok := schema.AdditionalProperties.(map[string]any)
+	if !ok {
+		return nil, false
+	}
+
+	data, err := yaml.Marshal(node)
+	if err != nil {
+		return nil, false
+	}
+
+	var value openAPISchema
+	if err := yaml.Unmarshal(data, &value); err != nil {
+		return nil, false
+	}
+	return &value, true
+}
+
+// refName returns the name of the schema referenced by a local reference such as "#/components/schemas/Pet".
+func refName(ref string) string {
+	return ref[strings.LastIndex(ref, "/")+1:]
+}
+
+// identifier converts s into an exported Go identifier, e.g. "list-pets" becomes "ListPets".
+func identifier(s string) string {
+	parts := strings.FieldsFunc(s, func(r rune) bool {
+		return !unicode.IsLetter(r) && !unicode.IsDigit(r)
+	})
+
+	var sb strings.Builder
+	for _, p := range parts {
+		sb.WriteString(strings.ToUpper(p[:1]) + p[1:])
+	}
+
+	id := sb.String()
+	if id != "" && unicode.IsDigit(rune(id[0])) {
+		id = "T" + id
+	}
+	return id
+}
+
+func firstNonEmpty(values ...string) string {
+	for _, v := range values {
+		if v != "" {
+			return v
+		}
+	}
+	return ""
+}
@@ -0,0 +1,110 @@
+// Copyright (c) 2025 Suricata Contributors
+// Original Author: Stefano Scafiti
+//
+// This file is part of Suricata: Type-Safe AI Agents for Go.
+//
+// Licensed under the MIT License. You may obtain a copy of the License at
+//
+//	https://opensource.org/licenses/MIT
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+package importer_test
+
+import (
+	"testing"
+
+	"github.com/ostafen/suricata/pkg/importer"
+)
+
+const petstore = `
+openapi: 3.0.0
+paths:
+  /pets:
+    get:
+      operationId: listPets
+      summary: List all pets
+      parameters:
+        - name: limit
+          in: query
+          schema: {type: integer, maximum: 100}
+      responses:
+        '200':
+          content:
+            application/json:
+              schema: {$ref: '#/components/schemas/Pets'}
+    post:
+      operationId: createPet
+      requestBody:
+        required: true
+        content:
+          application/json:
+            schema: {$ref: '#/components/schemas/Pet'}
+      responses:
+        '201':
+          content:
+            application/json:
+              schema: {$ref: '#/components/schemas/Pet'}
+  /pets/{petId}:
+    delete:
+      parameters:
+        - {name: petId, in: path, required: true, schema: {type: string}}
+      responses:
+        '204': {description: deleted}
+components:
+  schemas:
+    Pet:
+      type: object
+      required: [name]
+      properties:
+        name: {type: string}
+        status: {type: string, enum: [available, sold]}
+        born: {type: string, format: date-time}
+    Pets:
+      type: array
+      items: {$ref: '#/components/schemas/Pet'}
+`
+
+func TestFromOpenAPI(t *testing.T) {
+	s, err := importer.FromOpenAPI([]byte(petstore), "petstore")
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	for name, io := range map[string][2]string{
+		"ListPets":          {"ListPetsRequest", "ListPetsResponse"},
+		"CreatePet":         {"CreatePetRequest", "Pet"},
+		"DeletePetsByPetId": {"DeletePetsByPetIdRequest", "DeletePetsByPetIdResponse"},
+	} {
+		tool, ok := s.Tools[name]
+		if !ok {
+			t.Errorf("expected tool %q", name)
+			continue
+		}
+		if tool.Input != io[0] || tool.Output != io[1] {
+			t.Errorf("tool %q: expected %s -> %s, got %s -> %s", name, io[0], io[1], tool.Input, tool.Output)
+		}
+	}
+
+	if _, ok := s.Enums["PetStatus"]; !ok {
+		t.Errorf("expected enum PetStatus")
+	}
+
+	fields := s.Messages["Pet"].Fields
+	if len(fields) != 3 || fields[0].Name != "born" || fields[0].Type != "datetime" || !fields[0].Optional {
+		t.Errorf("unexpected Pet fields: %+v", fields)
+	}
+
+	result := s.Messages["ListPetsResponse"].Fields
+	if len(result) != 1 || result[0].Type != "Pet" || !result[0].Repeated {
+		t.Errorf("unexpected ListPetsResponse fields: %+v", result)
+	}
+
+	limit := s.Messages["ListPetsRequest"].Fields[0]
+	if limit.Type != "int" || limit.Max == nil || *limit.Max != 100 {
+		t.Errorf("unexpected limit field: %+v", limit)
+	}
+}
@@ -0,0 +1,570 @@
+// Copyright (c) 2025 Suricata Contributors
+// Original Author: Stefano Scafiti
+//
+// This file is part of Suricata: Type-Safe AI Agents for Go.
+//
+// Licensed under the MIT License. You may obtain a copy of the License at
+//
+//	https://opensource.org/licenses/MIT
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package importer
+
+import (
+	"fmt"
+	"strings"
+	"unicode"
+
+	"github.com/ostafen/suricata/pkg/spec"
+)
+
+// protoScalars maps protobuf scalar types to spec primitive types.
+var protoScalars = map[string]string{
+	"double":   "float",
+	"float":    "float",
+	"int32":    "int",
+	"int64":    "int",
+	"uint32":   "int",
+	"uint64":   "int",
+	"sint32":   "int",
+	"sint64":   "int",
+	"fixed32":  "int",
+	"fixed64":  "int",
+	"sfixed32": "int",
+	"sfixed64": "int",
+	"bool":     "bool",
+	"string":   "string",
+	"bytes":    "bytes",
+
+	"google.protobuf.Timestamp": "datetime",
+}
+
+// FromProto converts the message and enum definitions of one or more .proto files into
+// spec messages and enums. Nested definitions are flattened, prefixing their name with
+// the one of the enclosing message (Outer.Inner becomes OuterInner), and oneof members
+// become optional fields. Services are ignored.
+func FromProto(sources [][]byte, pkg string) (*spec.Spec, error) {
+	imp := &protoImporter{
+		types: make(map[string]string),
+		spec: &spec.Spec{
+			Version:  "0.0.1",
+			Package:  pkg,
+			Enums:    make(map[string]spec.Enum),
+			Messages: make(map[string]spec.Message),
+		},
+	}
+
+	var files []*protoFile
+	for i, src := range sources {
+		f, err := parseProto(string(src))
+		if err != nil {
+			return nil, fmt.Errorf("proto: file %d: %w", i+1, err)
+		}
+		imp.declare(f.pkg, "", f.messages, f.enums)
+		files = append(files, f)
+	}
+
+	for _, f := range files {
+		if err := imp.importDefs(f.pkg, f.messages, f.enums); err != nil {
+			return nil, fmt.Errorf("proto: %w", err)
+		}
+	}
+
+	if err := imp.spec.Validate(); err != nil {
+		return nil, fmt.Errorf("proto: %w", err)
+	}
+	return imp.spec, nil
+}
+
+type protoImporter struct {
+	types map[string]string // Fully qualified proto name to spec name
+	spec  *spec.Spec
+}
+
+// declare registers the names of the messages and enums defined in scope, recursively.
+func (imp *protoImporter) declare(scope, prefix string, messages []*protoMessage, enums []*protoEnum) {
+	for _, e := range enums {
+		imp.types[qualify(scope, e.name)] = prefix + identifier(e.name)
+	}
+	for _, m := range messages {
+		imp.types[qualify(scope, m.name)] = prefix + identifier(m.name)
+		imp.declare(qualify(scope, m.name), prefix+identifier(m.name), m.messages, m.enums)
+	}
+}
+
+func (imp *protoImporter) importDefs(scope string, messages []*protoMessage, enums []*protoEnum) error {
+	for _, e := range enums {
+		name := imp.types[qualify(scope, e.name)]
+		if _, exists := imp.spec.Enums[name]; exists {
+			return fmt.Errorf("duplicate enum %q", name)
+		}
+		imp.spec.Enums[name] = spec.Enum{Description: e.comment, Values: e.values}
+	}
+
+	for _, m := range messages {
+		msgScope := qualify(scope, m.name)
+		name := imp.types[msgScope]
+		if _, exists := imp.spec.Messages[name]; exists {
+			return fmt.Errorf("duplicate message %q", name)
+		}
+
+		var msg spec.Message
+		for _, f := range m.fields {
+			field, err := imp.field(msgScope, f)
+			if err != nil {
+				return fmt.Errorf("message %q: field %q: %w", m.name, f.name, err)
+			}
+			msg.Fields = append(msg.Fields, field)
+		}
+		imp.spec.Messages[name] = msg
+
+		if err := imp.importDefs(msgScope, m.messages, m.enums); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func (imp *protoImporter) field(scope string, f *protoField) (spec.Field, error) {
+	field := spec.Field{
+		Name:        f.name,
+		Description: f.comment,
+		Repeated:    f.repeated,
+		Optional:    f.optional,
+	}
+
+	if f.mapKey != "" {
+		if f.mapKey != "string" {
+			return field, fmt.Errorf("unsupported map key type %q (only string is allowed)", f.mapKey)
+		}
+
+		valueType, err := imp.resolve(scope, f.typ)
+		if err != nil {
+			return field, err
+		}
+		field.Type = "map<string, " + valueType + ">"
+		return field, nil
+	}
+
+	t, err := imp.resolve(scope, f.typ)
+	if err != nil {
+		return field, err
+	}
+	field.Type = t
+	return field, nil
+}
+
+// resolve returns the spec type of a type reference, looking it up from the innermost scope outwards.
+func (imp *protoImporter) resolve(scope, ref string) (string, error) {
+	if t, ok := protoScalars[strings.TrimPrefix(ref, ".")]; ok {
+		return t, nil
+	}
+
+	if strings.HasPrefix(ref, ".") {
+		if t, ok := imp.types[ref[1:]]; ok {
+			return t, nil
+		}
+		return "", fmt.Errorf("undefined type %q", ref)
+	}
+
+	for {
+		if t, ok := imp.types[qualify(scope, ref)]; ok {
+			return t, nil
+		}
+		if scope == "" {
+			return "", fmt.Errorf("undefined type %q", ref)
+		}
+
+		i := strings.LastIndexByte(scope, '.')
+		if i < 0 {
+			scope = ""
+		} else {
+			scope = scope[:i]
+		}
+	}
+}
+
+func qualify(scope, name string) string {
+	if scope == "" {
+		return name
+	}
+	return scope + "." + name
+}
+
+type (
+	protoFile struct {
+		pkg      string
+		messages []*protoMessage
+		enums    []*protoEnum
+	}
+
+	protoMessage struct {
+		name     string
+		fields   []*protoField
+		messages []*protoMessage
+		enums    []*protoEnum
+	}
+
+	protoEnum struct {
+		name    string
+		comment string
+		values  []string
+	}
+
+	protoField struct {
+		name     string
+		typ      string
+		mapKey   string
+		comment  string
+		repeated bool
+		optional bool
+	}
+)
+
+// protoParser is a recursive descent parser for the subset of the protobuf language
+// describing messages and enums. Other definitions are skipped.
+type protoParser struct {
+	toks []protoToken
+	pos  int
+}
+
+type protoToken struct {
+	text    string
+	comment string // Comment immediately preceding the token
+	line    int
+}
+
+func parseProto(src string) (*protoFile, error) {
+	toks, err := tokenizeProto(src)
+	if err != nil {
+		return nil, err
+	}
+
+	p := &protoParser{toks: toks}
+
+	f := &protoFile{}
+	for !p.eof() {
+		switch tok := p.next(); tok.text {
+		case "syntax", "edition", "import", "option":
+			if err := p.skipStatement(); err != nil {
+				return nil, err
+			}
+		case "package":
+			f.pkg = p.next().text
+			if err := p.expect(";"); err != nil {
+				return nil, err
+			}
+		case "message":
+			m, err := p.parseMessage()
+			if err != nil {
+				return nil, err
+			}
+			f.messages = append(f.messages, m)
+		case "enum":
+			e, err := p.parseEnum(tok.comment)
+			if err != nil {
+				return nil, err
+			}
+			f.enums = append(f.enums, e)
+		case "service", "extend":
+			p.next() // name
+			if err := p.skipBlock(); err != nil {
+				return nil, err
+			}
+		case ";":
+		default:
+			return nil, p.errorf(tok, "unexpected %q", tok.text)
+		}
+	}
+	return f, nil
+}
+
+func (p *protoParser) parseMessage() (*protoMessage, error) {
+	m := &protoMessage{name: p.next().text}
+	if err := p.expect("{"); err != nil {
+		return nil, err
+	}
+
+	for {
+		tok := p.peek()
+		switch tok.text {
+		case "":
+			return nil, fmt.Errorf("unexpected end of file in message %q", m.name)
+		case "}":
+			p.next()
+			return m, nil
+		case ";":
+			p.next()
+		case "message":
+			p.next()
+			nested, err := p.parseMessage()
+			if err != nil {
+				return nil, err
+			}
+			m.messages = append(m.messages, nested)
+		case "enum":
+			p.next()
+			e, err := p.parseEnum(tok.comment)
+			if err != nil {
+				return nil, err
+			}
+			m.enums = append(m.enums, e)
+		case "option", "reserved", "extensions":
+			p.next()
+			if err := p.skipStatement(); err != nil {
+				return nil, err
+			}
+		case "extend":
+			p.next()
+			p.next() // name
+			if err := p.skipBlock(); err != nil {
+				return nil, err
+			}
+		case "oneof":
+			p.next()
+			p.next() // name
+			if err := p.expect("{"); err != nil {
+				return nil, err
+			}
+			for p.peek().text != "}" {
+				if p.peek().text == "option" {
+					p.next()
+					if err := p.skipStatement(); err != nil {
+						return nil, err
+					}
+					continue
+				}
+
+				f, err := p.parseField()
+				if err != nil {
+					return nil, err
+				}
+				f.optional = true
+				m.fields = append(m.fields, f)
+			}
+			p.next()
+		default:
+			f, err := p.parseField()
+			if err != nil {
+				return nil, err
+			}
+			m.fields = append(m.fields, f)
+		}
+	}
+}
+
+func (p *protoParser) parseField() (*protoField, error) {
+	first := p.peek()
+	f := &protoField{comment: first.comment}
+
+	switch first.text {
+	case "repeated":
+		f.repeated = true
+		p.next()
+	case "optional":
+		f.optional = true
+		p.next()
+	case "required":
+		p.next()
+	}
+
+	tok := p.next()
+	if tok.text == "map" {
+		if err := p.expect("<"); err != nil {
+			return nil, err
+		}
+		f.mapKey = p.next().text
+		if err := p.expect(","); err != nil {
+			return nil, err
+		}
+		f.typ = p.next().text
+		if err := p.expect(">"); err != nil {
+			return nil, err
+		}
+	} else {
+		if !isProtoIdent(tok.text) {
+			return nil, p.errorf(tok, "unexpected %q", tok.text)
+		}
+		f.typ = tok.text
+	}
+
+	f.name = p.next().text
+	if err := p.expect("="); err != nil {
+		return nil, err
+	}
+	return f, p.skipStatement()
+}
+
+func (p *protoParser) parseEnum(comment string) (*protoEnum, error) {
+	e := &protoEnum{name: p.next().text, comment: comment}
+	if err := p.expect("{"); err != nil {
+		return nil, err
+	}
+
+	for {
+		tok := p.next()
+		switch tok.text {
+		case "":
+			return nil, fmt.Errorf("unexpected end of file in enum %q", e.name)
+		case "}":
+			return e, nil
+		case ";":
+		case "option", "reserved":
+			if err := p.skipStatement(); err != nil {
+				return nil, err
+			}
+		default:
+			e.values = append(e.values, tok.text)
+			if err := p.skipStatement(); err != nil {
+				return nil, err
+			}
+		}
+	}
+}
+
+// skipStatement skips tokens up to the next ";", including bracketed field options.
+func (p *protoParser) skipStatement() error {
+	depth := 0
+	for {
+		tok := p.next()
+		switch tok.text {
+		case "":
+			return fmt.Errorf("unexpected end of file")
+		case "[", "{", "(":
+			depth++
+		case "]", "}", ")":
+			depth--
+		case ";":
+			if depth == 0 {
+				return nil
+			}
+		}
+	}
+}
+
+// skipBlock skips a block delimited by braces, including nested ones.
+func (p *protoParser) skipBlock() error {
+	if err := p.expect("{"); err != nil {
+		return err
+	}
+
+	for depth := 1; depth > 0; {
+		switch p.next().text {
+		case "":
+			return fmt.Errorf("unexpected end of file")
+		case "{":
+			depth++
+		case "}":
+			depth--
+		}
+	}
+	return nil
+}
+
+func (p *protoParser) expect(text string) error {
+	if tok := p.next(); tok.text != text {
+		return p.errorf(tok, "expected %q, got %q", text, tok.text)
+	}
+	return nil
+}
+
+func (p *protoParser) errorf(tok protoToken, format string, args ...any) error {
+	return fmt.Errorf("line %d: %s", tok.line, fmt.Sprintf(format, args...))
+}
+
+func (p *protoParser) peek() protoToken {
+	if p.eof() {
+		return protoToken{}
+	}
+	return p.toks[p.pos]
+}
+
+func (p *protoParser) next() protoToken {
+	tok := p.peek()
+	if !p.eof() {
+		p.pos++
+	}
+	return tok
+}
+
+func (p *protoParser) eof() bool {
+	return p.pos >= len(p.toks)
+}
+
+func isProtoIdent(s string) bool {
+	return s != "" && (unicode.IsLetter(rune(s[0])) || s[0] == '_' || s[0] == '.')
+}
+
+func tokenizeProto(src string) ([]protoToken, error) {
+	var (
+		toks     []protoToken
+		comment  []string
+		line     = 1
+		lastLine = 0 // Line of the last token, to ignore trailing comments
+	)
+
+	for i := 0; i < len(src); {
+		c := src[i]
+		switch {
+		case c == '\n':
+			line++
+			i++
+		case c == ' ' || c == '\t' || c == '\r':
+			i++
+		case strings.HasPrefix(src[i:], "//"):
+			end := strings.IndexByte(src[i:], '\n')
+			if end < 0 {
+				end = len(src) - i
+			}
+			if line != lastLine {
+				comment = append(comment, strings.TrimSpace(src[i+2:i+end]))
+			}
+			i += end
+		case strings.HasPrefix(src[i:], "/*"):
+			end := strings.Index(src[i+2:], "*/")
+			if end < 0 {
+				return nil, fmt.Errorf("line %d: unterminated comment", line)
+			}
+			text := src[i+2 : i+2+end]
+			if line != lastLine {
+				comment = append(comment, strings.TrimSpace(strings.Trim(text, "*")))
+			}
+			line += strings.Count(text, "\n")
+			i += end + 4
+		case c == '"' || c == '\'':
+			end := i + 1
+			for end < len(src) && src[end] != c {
+				if src[end] == '\\' {
+					end++
+				}
+				end++
+			}
+			if end >= len(src) {
+				return nil, fmt.Errorf("line %d: unterminated string", line)
+			}
+			toks = append(toks, protoToken{text: src[i : end+1], line: line})
+			lastLine = line
+			i = end + 1
+		case unicode.IsLetter(rune(c)) || unicode.IsDigit(rune(c)) || c == '_' || c == '.' || c == '-' || c == '+':
+			end := i + 1
+			for end < len(src) && (unicode.IsLetter(rune(src[end])) || unicode.IsDigit(rune(src[end])) || src[end] == '_' || src[end] == '.') {
+				end++
+			}
+			toks = append(toks, protoToken{text: src[i:end], comment: strings.Join(comment, " "), line: line})
+			comment = nil
+			lastLine = line
+			i = end
+		default:
+			toks = append(toks, protoToken{text: string(c), line: line})
+			comment = nil
+			lastLine = line
+			i++
+		}
+	}
+	return toks, nil
+}
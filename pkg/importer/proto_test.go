@@ -0,0 +1,102 @@
+// Copyright (c) 2025 Suricata Contributors
+// Original Author: Stefano Scafiti
+//
+// This file is part of Suricata: Type-Safe AI Agents for Go.
+//
+// Licensed under the MIT License. You may obtain a copy of the License at
+//
+//	https://opensource.org/licenses/MIT
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+package importer_test
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/ostafen/suricata/pkg/importer"
+)
+
+func TestFromProto(t *testing.T) {
+	common := `
+syntax = "proto3";
+package shop.common;
+
+// Status of an order
+enum Status {
+  STATUS_UNSPECIFIED = 0;
+  STATUS_PAID = 1;
+}
+`
+
+	orders := `
+syntax = "proto3";
+package shop.v1;
+
+import "google/protobuf/timestamp.proto";
+
+message Order {
+  // Unique identifier
+  string id = 1; // not a description
+  repeated Item items = 2;
+  map<string, int64> quantities = 3;
+  google.protobuf.Timestamp created_at = 4;
+  shop.common.Status status = 5;
+  oneof payment {
+    string card = 6;
+    string voucher = 7;
+  }
+
+  message Item {
+    string sku = 1;
+    double price = 2 [deprecated = true];
+  }
+}
+
+service Shop {
+  rpc Get(Order) returns (Order);
+}
+`
+
+	s, err := importer.FromProto([][]byte{[]byte(common), []byte(orders)}, "shop")
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	if got := strings.Join(s.Enums["Status"].Values, ","); got != "STATUS_UNSPECIFIED,STATUS_PAID" {
+		t.Errorf("unexpected Status values: %s", got)
+	}
+
+	var types []string
+	for _, f := range s.Messages["Order"].Fields {
+		types = append(types, f.Name+":"+f.Type)
+	}
+
+	expected := "id:string,items:OrderItem,quantities:map<string, int>,created_at:datetime,status:Status,card:string,voucher:string"
+	if got := strings.Join(types, ","); got != expected {
+		t.Errorf("expected fields %s, got %s", expected, got)
+	}
+
+	fields := s.Messages["Order"].Fields
+	if fields[0].Description != "Unique identifier" {
+		t.Errorf("unexpected description %q", fields[0].Description)
+	}
+	if !fields[1].Repeated || !fields[5].Optional {
+		t.Errorf("expected repeated items and optional oneof members")
+	}
+
+	if _, ok := s.Messages["OrderItem"]; !ok {
+		t.Errorf("expected nested message OrderItem")
+	}
+}
+
+func TestFromProto_UndefinedType(t *testing.T) {
+	_, err := importer.FromProto([][]byte{[]byte(`message A { B b = 1; }`)}, "test")
+	if err == nil || !strings.Contains(err.Error(), "undefined type") {
+		t.Errorf("expected undefined type error, got %v", err)
+	}
+}
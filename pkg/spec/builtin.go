@@ -0,0 +1,159 @@
+// Copyright (c) 2025 Suricata Contributors
+// Original Author: Stefano Scafiti
+//
+// This file is part of Suricata: Type-Safe AI Agents for Go.
+//
+// Licensed under the MIT License. You may obtain a copy of the License at
+//
+//	https://opensource.org/licenses/MIT
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package spec
+
+// BuiltinRetrieval is the builtin tool searching the documents indexed by a retrieval.Retriever
+// (see runtime/retrieval).
+const BuiltinRetrieval = "retrieval"
+
+// BuiltinExec is the builtin tool running commands in the sandbox of an exec.Sandbox
+// (see runtime/tools/exec).
+const BuiltinExec = "exec"
+
+// BuiltinWebSearch is the builtin tool searching the web through a websearch.Searcher
+// (see runtime/tools/websearch).
+const BuiltinWebSearch = "websearch"
+
+// builtinOrigin is the origin of the messages added by builtin tools.
+const builtinOrigin = "<builtin>"
+
+type builtinTool struct {
+	description string
+	input       string
+	output      string
+	messages    map[string]Message
+}
+
+func floatPtr(n int) *float64 {
+	v := float64(n)
+	return &v
+}
+
+var builtinTools = map[string]builtinTool{
+	BuiltinRetrieval: {
+		description: "Searches the indexed documents relevant to a query",
+		input:       "RetrievalQuery",
+		output:      "RetrievalResults",
+		messages: map[string]Message{
+			"RetrievalQuery": {Fields: []Field{
+				{Name: "query", Type: "string", Description: "Text to search for"},
+				{Name: "limit", Type: "int", Optional: true, Min: floatPtr(1), Description: "Maximum number of documents to return"},
+			}},
+			"RetrievalResults": {Fields: []Field{
+				{Name: "results", Type: "RetrievalResult", Repeated: true},
+			}},
+			"RetrievalResult": {Fields: []Field{
+				{Name: "id", Type: "string"},
+				{Name: "text", Type: "string"},
+				{Name: "score", Type: "float"},
+				{Name: "metadata", Type: "map<string, string>", Optional: true},
+			}},
+		},
+	},
+	BuiltinExec: {
+		description: "Runs a command in a sandbox, returning its output",
+		input:       "ExecCommand",
+		output:      "ExecResult",
+		messages: map[string]Message{
+			"ExecCommand": {Fields: []Field{
+				{Name: "command", Type: "string", Description: "Program to run"},
+				{Name: "args", Type: "string", Repeated: true, Optional: true, Description: "Arguments of the program"},
+				{Name: "stdin", Type: "string", Optional: true, Description: "Standard input of the program, such as the code run by an interpreter"},
+			}},
+			"ExecResult": {Fields: []Field{
+				{Name: "stdout", Type: "string"},
+				{Name: "stderr", Type: "string"},
+				{Name: "exit_code", Type: "int"},
+				{Name: "timed_out", Type: "bool"},
+				{Name: "truncated", Type: "bool", Description: "Whether the output was cut to the size limit"},
+			}},
+		},
+	},
+	BuiltinWebSearch: {
+		description: "Searches the web, returning the title, the URL and a snippet of the pages found",
+		input:       "WebSearchQuery",
+		output:      "WebSearchResults",
+		messages: map[string]Message{
+			"WebSearchQuery": {Fields: []Field{
+				{Name: "query", Type: "string", Description: "Text to search the web for"},
+				{Name: "limit", Type: "int", Optional: true, Min: floatPtr(1), Description: "Maximum number of results to return"},
+			}},
+			"WebSearchResults": {Fields: []Field{
+				{Name: "results", Type: "WebSearchResult", Repeated: true},
+			}},
+			"WebSearchResult": {Fields: []Field{
+				{Name: "title", Type: "string"},
+				{Name: "url", Type: "string"},
+				{Name: "snippet", Type: "string"},
+			}},
+		},
+	},
+}
+
+// resolveBuiltins fills the input and the output of builtin tools, adding the messages they need to the spec.
+// Invalid builtin tools are reported by validate.
+func (spec *Spec) resolveBuiltins() {
+	for _, name := range sortedKeys(spec.Tools) {
+		tool := spec.Tools[name]
+
+		builtin, ok := builtinTools[tool.Builtin]
+		if !ok || tool.Input != "" || tool.Output != "" {
+			continue
+		}
+
+		tool.Input, tool.Output = builtin.input, builtin.output
+		if tool.Description == "" {
+			tool.Description = builtin.description
+		}
+		spec.Tools[name] = tool
+
+		for msgName, msg := range builtin.messages {
+			if _, ok := spec.Messages[msgName]; ok {
+				continue
+			}
+
+			if spec.Messages == nil {
+				spec.Messages = make(map[string]Message)
+			}
+			if spec.origins == nil {
+				spec.origins = make(map[string]string)
+			}
+			spec.Messages[msgName] = msg
+			spec.origins["messages/"+msgName] = builtinOrigin
+		}
+	}
+}
+
+func (spec *Spec) validateBuiltin(c *checker, name string, tool *Tool) {
+	path := []string{"tools", name, "builtin"}
+
+	builtin, ok := builtinTools[tool.Builtin]
+	if !ok {
+		c.errorf(path, "tool %q references unknown builtin %q", name, tool.Builtin)
+		return
+	}
+
+	if tool.Input != builtin.input || tool.Output != builtin.output {
+		c.errorf(path, "builtin tool %q cannot override its input and output", name)
+		return
+	}
+
+	for _, msgName := range sortedKeys(builtin.messages) {
+		if spec.origins["messages/"+msgName] != builtinOrigin {
+			c.errorf(path, "message %q, required by builtin tool %q, is already defined", msgName, name)
+		}
+	}
+}
@@ -0,0 +1,281 @@
+// Copyright (c) 2025 Suricata Contributors
+// Original Author: Stefano Scafiti
+//
+// This file is part of Suricata: Type-Safe AI Agents for Go.
+//
+// Licensed under the MIT License. You may obtain a copy of the License at
+//
+//	https://opensource.org/licenses/MIT
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package spec
+
+import (
+	"cmp"
+	"fmt"
+	"maps"
+	"os"
+	"path/filepath"
+	"slices"
+	"strconv"
+	"strings"
+
+	"gopkg.in/yaml.v3"
+)
+
+type Severity string
+
+const (
+	SeverityError   Severity = "error"
+	SeverityWarning Severity = "warning"
+)
+
+// Diagnostic is a problem found in a spec file.
+type Diagnostic struct {
+	Severity Severity
+	File     string
+	Line     int // 1-based. Zero if the position is unknown.
+	Column   int
+	Message  string
+
+	path []string // Keys leading to the offending YAML node
+}
+
+func (d Diagnostic) String() string {
+	pos := d.File
+	if d.Line > 0 {
+		pos = fmt.Sprintf("%s:%d:%d", d.File, d.Line, d.Column)
+	}
+	return fmt.Sprintf("%s: %s: %s", pos, d.Severity, d.Message)
+}
+
+// checker collects the diagnostics of a spec.
+type checker struct {
+	diags []Diagnostic
+}
+
+func (c *checker) errorf(path []string, format string, args ...any) {
+	c.report(SeverityError, path, format, args...)
+}
+
+func (c *checker) warnf(path []string, format string, args ...any) {
+	c.report(SeverityWarning, path, format, args...)
+}
+
+// err returns the first error reported, if any.
+func (c *checker) err() error {
+	for _, d := range c.diags {
+		if d.Severity == SeverityError {
+			return fmt.Errorf("spec: %s", d.Message)
+		}
+	}
+	return nil
+}
+
+func (c *checker) report(severity Severity, path []string, format string, args ...any) {
+	c.diags = append(c.diags, Diagnostic{
+		Severity: severity,
+		Message:  fmt.Sprintf(format, args...),
+		path:     slices.Clone(path),
+	})
+}
+
+// Check loads the spec at path, together with its imports, and returns all the errors and
+// warnings found in it, ordered by position. Unlike LoadSpec, it does not stop at the first error.
+// The returned error is only set when the file cannot be read.
+func Check(path string) ([]Diagnostic, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("read file: %w", err)
+	}
+
+	var root yaml.Node
+	if err := yaml.Unmarshal(data, &root); err != nil {
+		return []Diagnostic{yamlDiagnostic(path, err)}, nil
+	}
+
+	var spec Spec
+	if err := root.Decode(&spec); err != nil {
+		return []Diagnostic{yamlDiagnostic(path, err)}, nil
+	}
+
+	absPath, err := filepath.Abs(path)
+	if err != nil {
+		return nil, err
+	}
+
+	var c checker
+
+	// Definitions of failed imports are missing: further errors would be spurious
+	if err := spec.resolveImports(absPath, map[string]bool{absPath: true}); err != nil {
+		c.errorf([]string{"imports"}, "%s", strings.TrimPrefix(err.Error(), "spec: "))
+	} else {
+		spec.resolveBuiltins()
+		spec.resolvePromptFiles(absPath, &c)
+		spec.validate(&c)
+		spec.lint(&c)
+	}
+
+	resolver := positionResolver{files: map[string]*yaml.Node{absPath: &root}}
+	for i := range c.diags {
+		d := &c.diags[i]
+
+		file := absPath
+		if len(d.path) >= 2 {
+			if origin, ok := spec.origins[d.path[0]+"/"+d.path[1]]; ok {
+				file = origin
+			}
+		}
+
+		d.File = path
+		if file != absPath {
+			d.File = file
+		}
+		d.Line, d.Column = resolver.locate(file, d.path)
+	}
+
+	slices.SortStableFunc(c.diags, func(a, b Diagnostic) int {
+		return cmp.Or(
+			cmp.Compare(a.File, b.File),
+			cmp.Compare(a.Line, b.Line),
+			cmp.Compare(a.Column, b.Column),
+		)
+	})
+	return c.diags, nil
+}
+
+// yamlDiagnostic converts a YAML decoding error, whose message embeds the line, into a diagnostic.
+func yamlDiagnostic(path string, err error) Diagnostic {
+	msg := strings.TrimPrefix(err.Error(), "yaml: ")
+
+	var line int
+	if rest, ok := strings.CutPrefix(msg, "line "); ok {
+		if n, tail, ok := strings.Cut(rest, ": "); ok {
+			if l, err := strconv.Atoi(n); err == nil {
+				line, msg = l, tail
+			}
+		}
+	}
+	return Diagnostic{Severity: SeverityError, File: path, Line: line, Column: 1, Message: msg}
+}
+
+// lint reports definitions which are likely mistakes, although valid.
+// Definitions imported from other files are not reported, since they may be used elsewhere.
+func (spec *Spec) lint(c *checker) {
+	used := make(map[string]bool)
+
+	for _, msg := range spec.Messages {
+		for _, field := range msg.Fields {
+			used["messages/"+ElemType(field.Type)] = true
+		}
+		for _, variant := range msg.OneOf {
+			used["messages/"+variant] = true
+		}
+	}
+
+	for _, tool := range spec.Tools {
+		used["messages/"+tool.Input] = true
+		used["messages/"+tool.Output] = true
+	}
+
+	for _, name := range sortedKeys(spec.Agents) {
+		agent := spec.Agents[name]
+		for _, tool := range agent.AllTools() {
+			used["tools/"+tool] = true
+		}
+
+		for _, actionName := range sortedKeys(agent.Actions) {
+			action := agent.Actions[actionName]
+			used["messages/"+action.Input] = true
+			used["messages/"+action.Output] = true
+
+			if strings.TrimSpace(action.Prompt) == "" {
+				c.warnf([]string{"agents", name, "actions", actionName}, "agent %q action %q has no prompt", name, actionName)
+			}
+		}
+	}
+
+	for _, wf := range spec.Workflows {
+		used["messages/"+wf.Input] = true
+		used["messages/"+wf.Output] = true
+	}
+
+	for _, name := range sortedKeys(spec.Messages) {
+		key := "messages/" + name
+		if _, imported := spec.origins[key]; !imported && !used[key] {
+			c.warnf([]string{"messages", name}, "message %q is never used", name)
+		}
+	}
+
+	for _, name := range sortedKeys(spec.Tools) {
+		key := "tools/" + name
+		if _, imported := spec.origins[key]; !imported && !used[key] {
+			c.warnf([]string{"tools", name}, "tool %q is not used by any agent", name)
+		}
+	}
+}
+
+// positionResolver maps the paths of diagnostics to positions in the YAML files of a spec.
+type positionResolver struct {
+	files map[string]*yaml.Node
+}
+
+func (r *positionResolver) locate(file string, path []string) (line, column int) {
+	root, ok := r.files[file]
+	if !ok {
+		root = &yaml.Node{}
+		if data, err := os.ReadFile(file); err == nil {
+			yaml.Unmarshal(data, root)
+		}
+		r.files[file] = root
+	}
+
+	node := root
+	if node.Kind == yaml.DocumentNode && len(node.Content) > 0 {
+		node = node.Content[0]
+	}
+	line, column = node.Line, node.Column
+
+	// Walk down to the deepest node of the path found in the file
+	for _, key := range path {
+		next, pos := child(node, key)
+		if next == nil {
+			break
+		}
+		node, line, column = next, pos.Line, pos.Column
+	}
+	return line, column
+}
+
+// child returns the child of node with the given key, together with the node
+// whose position should be reported: the key itself for nested blocks, the value otherwise.
+func child(node *yaml.Node, key string) (*yaml.Node, *yaml.Node) {
+	switch node.Kind {
+	case yaml.MappingNode:
+		for i := 0; i+1 < len(node.Content); i += 2 {
+			k, v := node.Content[i], node.Content[i+1]
+			if k.Value != key {
+				continue
+			}
+			if v.Kind == yaml.ScalarNode {
+				return v, v
+			}
+			return v, k
+		}
+	case yaml.SequenceNode:
+		if i, err := strconv.Atoi(key); err == nil && i >= 0 && i < len(node.Content) {
+			return node.Content[i], node.Content[i]
+		}
+	}
+	return nil, nil
+}
+
+// sortedKeys returns the keys of m in lexicographic order.
+func sortedKeys[T any](m map[string]T) []string {
+	return slices.Sorted(maps.Keys(m))
+}
@@ -0,0 +1,288 @@
+// Copyright (c) 2025 Suricata Contributors
+// Original Author: Stefano Scafiti
+//
+// This file is part of Suricata: Type-Safe AI Agents for Go.
+//
+// Licensed under the MIT License. You may obtain a copy of the License at
+//
+//	https://opensource.org/licenses/MIT
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package spec
+
+import (
+	"fmt"
+	"reflect"
+	"slices"
+	"strings"
+)
+
+// Change is a difference between two revisions of a spec.
+type Change struct {
+	Path     string // Dotted path of the changed definition, e.g. "messages.Trip.fields.to"
+	Message  string
+	Breaking bool // Whether clients or tool implementations of the old revision may stop working
+}
+
+func (c Change) String() string {
+	kind := "compatible"
+	if c.Breaking {
+		kind = "breaking"
+	}
+	return fmt.Sprintf("%s: %s: %s", c.Path, kind, c.Message)
+}
+
+// HasBreaking reports whether any of changes is breaking.
+func HasBreaking(changes []Change) bool {
+	return slices.ContainsFunc(changes, func(c Change) bool { return c.Breaking })
+}
+
+// Diff returns the changes of the enums, messages, tools, agents and workflows of from
+// made by to, in a stable order. Changes which only affect prompts, descriptions or model
+// settings are reported as compatible.
+func Diff(from, to *Spec) []Change {
+	d := differ{from: from, to: to}
+	diffDefs(&d, nil, "enums", from.Enums, to.Enums, diffEnum)
+	diffDefs(&d, nil, "messages", from.Messages, to.Messages, diffMessage)
+	diffDefs(&d, nil, "tools", from.Tools, to.Tools, diffTool)
+	diffDefs(&d, nil, "agents", from.Agents, to.Agents, diffAgent)
+	diffDefs(&d, nil, "workflows", from.Workflows, to.Workflows, diffWorkflow)
+	return d.changes
+}
+
+// differ collects the changes between two specs.
+type differ struct {
+	from, to *Spec
+	changes  []Change
+}
+
+func (d *differ) breakingf(path []string, format string, args ...any) {
+	d.report(true, path, format, args...)
+}
+
+func (d *differ) compatiblef(path []string, format string, args ...any) {
+	d.report(false, path, format, args...)
+}
+
+func (d *differ) report(breaking bool, path []string, format string, args ...any) {
+	d.changes = append(d.changes, Change{
+		Path:     strings.Join(path, "."),
+		Message:  fmt.Sprintf(format, args...),
+		Breaking: breaking,
+	})
+}
+
+// diffDefs reports the definitions under the key kind of parent removed from from as breaking,
+// the ones added by to as compatible, and calls diff on the definitions found in both maps.
+func diffDefs[T any](d *differ, parent []string, kind string, from, to map[string]T, diff func(d *differ, path []string, from, to *T)) {
+	singular := strings.TrimSuffix(kind, "s")
+
+	for _, name := range sortedKeys(from) {
+		path := append(slices.Clone(parent), kind, name)
+
+		def, ok := to[name]
+		if !ok {
+			d.breakingf(path, "%s removed", singular)
+			continue
+		}
+		old := from[name]
+		diff(d, path, &old, &def)
+	}
+	for _, name := range sortedKeys(to) {
+		if _, ok := from[name]; !ok {
+			d.compatiblef(append(slices.Clone(parent), kind, name), "%s added", singular)
+		}
+	}
+}
+
+// diffList reports the values removed from a list and the ones added to it.
+func diffList(d *differ, path []string, from, to []string, kind string, removedBreaking, addedBreaking bool) {
+	for _, v := range from {
+		if !slices.Contains(to, v) {
+			d.report(removedBreaking, path, "%s %q removed", kind, v)
+		}
+	}
+	for _, v := range to {
+		if !slices.Contains(from, v) {
+			d.report(addedBreaking, path, "%s %q added", kind, v)
+		}
+	}
+}
+
+func diffEnum(d *differ, path []string, from, to *Enum) {
+	diffList(d, path, from.Values, to.Values, "value", true, false)
+}
+
+func diffMessage(d *differ, path []string, from, to *Message) {
+	diffList(d, path, from.OneOf, to.OneOf, "variant", true, false)
+	// Rules which are added may reject outputs accepted before
+	diffList(d, path, from.Rules, to.Rules, "rule", false, true)
+
+	for _, old := range from.Fields {
+		fieldPath := append(slices.Clone(path), "fields", old.Name)
+
+		field, ok := to.field(old.Name)
+		if !ok {
+			d.breakingf(fieldPath, "field removed")
+			continue
+		}
+		diffField(d, fieldPath, &old, &field)
+	}
+
+	for _, field := range to.Fields {
+		if _, ok := from.field(field.Name); ok {
+			continue
+		}
+
+		fieldPath := append(slices.Clone(path), "fields", field.Name)
+		if field.Optional {
+			d.compatiblef(fieldPath, "optional field added")
+		} else {
+			d.breakingf(fieldPath, "required field added")
+		}
+	}
+}
+
+func diffField(d *differ, path []string, from, to *Field) {
+	if from.Type != to.Type {
+		d.breakingf(path, "type changed from %s to %s", from.Type, to.Type)
+	}
+	if from.Repeated != to.Repeated {
+		d.breakingf(path, "repeated changed from %t to %t", from.Repeated, to.Repeated)
+	}
+	// Optional fields are pointers in the generated code, so either way the Go type changes
+	switch {
+	case from.Optional && !to.Optional:
+		d.breakingf(path, "field made required")
+	case !from.Optional && to.Optional:
+		d.breakingf(path, "field made optional")
+	}
+	if from.Description != to.Description {
+		d.compatiblef(path, "description changed")
+	}
+
+	// Constraints which are added or changed may reject values accepted before
+	oldConstraints, newConstraints := fieldConstraints(from), fieldConstraints(to)
+	for _, name := range sortedKeys(newConstraints) {
+		old, ok := oldConstraints[name]
+		switch {
+		case !ok:
+			d.breakingf(path, "constraint %s added", name)
+		case !reflect.DeepEqual(old, newConstraints[name]):
+			d.breakingf(path, "constraint %s changed", name)
+		}
+	}
+	for _, name := range sortedKeys(oldConstraints) {
+		if _, ok := newConstraints[name]; !ok {
+			d.compatiblef(path, "constraint %s removed", name)
+		}
+	}
+}
+
+// fieldConstraints returns the validation constraints set on field, keyed by their YAML name.
+func fieldConstraints(field *Field) map[string]any {
+	constraints := make(map[string]any)
+	if field.Min != nil {
+		constraints["min"] = *field.Min
+	}
+	if field.Max != nil {
+		constraints["max"] = *field.Max
+	}
+	if field.MinLength != nil {
+		constraints["min_length"] = *field.MinLength
+	}
+	if field.MaxLength != nil {
+		constraints["max_length"] = *field.MaxLength
+	}
+	if field.Pattern != "" {
+		constraints["pattern"] = field.Pattern
+	}
+	if field.Format != "" {
+		constraints["format"] = field.Format
+	}
+	if len(field.Enum) > 0 {
+		constraints["enum"] = field.Enum
+	}
+	return constraints
+}
+
+func diffTool(d *differ, path []string, from, to *Tool) {
+	if from.Builtin != to.Builtin {
+		d.breakingf(path, "builtin changed from %q to %q", from.Builtin, to.Builtin)
+	}
+	if from.Input != to.Input {
+		d.breakingf(path, "input changed from %s to %s", from.Input, to.Input)
+	}
+	if from.Output != to.Output {
+		d.breakingf(path, "output changed from %s to %s", from.Output, to.Output)
+	}
+	if from.Approval != to.Approval {
+		d.compatiblef(path, "approval changed from %q to %q", from.Approval, to.Approval)
+	}
+	if from.Description != to.Description {
+		d.compatiblef(path, "description changed")
+	}
+}
+
+func diffAgent(d *differ, path []string, from, to *Agent) {
+	if from.Instructions != to.Instructions {
+		d.compatiblef(path, "instructions changed")
+	}
+	if !reflect.DeepEqual(from.ModelConfig, to.ModelConfig) {
+		d.compatiblef(path, "model settings changed")
+	}
+	// The tools interface of the agent gains a method for each added tool
+	diffList(d, path, from.AllTools(), to.AllTools(), "tool", false, true)
+
+	diffDefs(d, path, "actions", from.Actions, to.Actions, diffAction)
+}
+
+func diffAction(d *differ, path []string, from, to *Actions) {
+	if from.Input != to.Input {
+		d.breakingf(path, "input changed from %s to %s", from.Input, to.Input)
+	}
+	if from.IsTextOutput() != to.IsTextOutput() || !from.IsTextOutput() && from.Output != to.Output {
+		d.breakingf(path, "output changed from %s to %s", actionOutput(from), actionOutput(to))
+	}
+	if from.Stream && !to.Stream {
+		d.breakingf(path, "streaming removed")
+	} else if !from.Stream && to.Stream {
+		d.compatiblef(path, "streaming added")
+	}
+	if from.Prompt != to.Prompt || from.PromptFile != to.PromptFile || from.PromptVersion != to.PromptVersion {
+		d.compatiblef(path, "prompt changed")
+	}
+	if !reflect.DeepEqual(from.Examples, to.Examples) {
+		d.compatiblef(path, "examples changed")
+	}
+	if !slices.Equal(from.Tools, to.Tools) || (from.Tools == nil) != (to.Tools == nil) {
+		d.compatiblef(path, "tools changed")
+	}
+	if !reflect.DeepEqual(from.ModelConfig, to.ModelConfig) {
+		d.compatiblef(path, "model settings changed")
+	}
+}
+
+func actionOutput(action *Actions) string {
+	if action.IsTextOutput() {
+		return TextOutput
+	}
+	return action.Output
+}
+
+func diffWorkflow(d *differ, path []string, from, to *Workflow) {
+	if from.Input != to.Input {
+		d.breakingf(path, "input changed from %s to %s", from.Input, to.Input)
+	}
+	if was, now := d.from.WorkflowOutput(from), d.to.WorkflowOutput(to); was != now {
+		d.breakingf(path, "output changed from %s to %s", was, now)
+	}
+	if !reflect.DeepEqual(from.Steps, to.Steps) || !reflect.DeepEqual(from.Result, to.Result) {
+		d.compatiblef(path, "steps changed")
+	}
+}
@@ -0,0 +1,219 @@
+// Copyright (c) 2025 Suricata Contributors
+// Original Author: Stefano Scafiti
+//
+// This file is part of Suricata: Type-Safe AI Agents for Go.
+//
+// Licensed under the MIT License. You may obtain a copy of the License at
+//
+//	https://opensource.org/licenses/MIT
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package spec
+
+import (
+	"bytes"
+	"fmt"
+	"reflect"
+	"slices"
+	"strings"
+
+	"gopkg.in/yaml.v3"
+)
+
+// Format returns the canonical form of the spec YAML src, so that diffs only show actual changes.
+// Keys are written in the order of declaration of the spec types, followed by unknown keys,
+// while definitions keep the order chosen by their authors. Collections use the block style,
+// multi-line texts, such as prompts, are written as literal blocks, and definitions are
+// separated by blank lines. Comments are preserved.
+func Format(src []byte) ([]byte, error) {
+	var doc yaml.Node
+	if err := yaml.Unmarshal(src, &doc); err != nil {
+		return nil, fmt.Errorf("unmarshal yaml: %w", err)
+	}
+	if doc.Kind == 0 {
+		return nil, nil
+	}
+	formatNode(&doc, reflect.TypeFor[Spec]())
+
+	var buf bytes.Buffer
+	enc := yaml.NewEncoder(&buf)
+	enc.SetIndent(2)
+	if err := enc.Encode(&doc); err != nil {
+		return nil, fmt.Errorf("marshal yaml: %w", err)
+	}
+	if err := enc.Close(); err != nil {
+		return nil, fmt.Errorf("marshal yaml: %w", err)
+	}
+	return separateDefinitions(buf.Bytes())
+}
+
+// formatNode normalizes node, a value of type t, in place. A nil t stands for arbitrary data.
+func formatNode(node *yaml.Node, t reflect.Type) {
+	for t != nil && t.Kind() == reflect.Pointer {
+		t = t.Elem()
+	}
+
+	switch node.Kind {
+	case yaml.DocumentNode:
+		for _, child := range node.Content {
+			formatNode(child, t)
+		}
+	case yaml.MappingNode:
+		node.Style &^= yaml.FlowStyle
+		if t != nil && t.Kind() == reflect.Struct {
+			formatStruct(node, t)
+			return
+		}
+
+		var elem reflect.Type
+		if t != nil && t.Kind() == reflect.Map {
+			elem = t.Elem()
+		}
+		for i := 1; i < len(node.Content); i += 2 {
+			formatNode(node.Content[i], elem)
+		}
+	case yaml.SequenceNode:
+		node.Style &^= yaml.FlowStyle
+
+		var elem reflect.Type
+		if t != nil && t.Kind() == reflect.Slice {
+			elem = t.Elem()
+		}
+		for _, child := range node.Content {
+			formatNode(child, elem)
+		}
+	case yaml.ScalarNode:
+		if node.Tag != "!!str" {
+			return
+		}
+		if strings.Contains(node.Value, "\n") {
+			node.Style = yaml.LiteralStyle
+		} else {
+			// Quoted only when required
+			node.Style = 0
+		}
+	}
+}
+
+// formatStruct sorts the keys of node by the order of the fields of t, and formats their values.
+func formatStruct(node *yaml.Node, t reflect.Type) {
+	fields := yamlFields(t)
+	keys := make([]string, len(fields))
+	for i, f := range fields {
+		keys[i] = f.key
+	}
+
+	type pair struct{ key, value *yaml.Node }
+
+	pairs := make([]pair, 0, len(node.Content)/2)
+	for i := 1; i < len(node.Content); i += 2 {
+		key, value := node.Content[i-1], node.Content[i]
+
+		var ft reflect.Type
+		if i := slices.Index(keys, key.Value); i >= 0 {
+			ft = fields[i].typ
+		}
+		formatNode(value, ft)
+		pairs = append(pairs, pair{key, value})
+	}
+
+	rank := func(p pair) int {
+		if i := slices.Index(keys, p.key.Value); i >= 0 {
+			return i
+		}
+		return len(keys)
+	}
+	slices.SortStableFunc(pairs, func(a, b pair) int { return rank(a) - rank(b) })
+
+	node.Content = node.Content[:0]
+	for _, p := range pairs {
+		node.Content = append(node.Content, p.key, p.value)
+	}
+}
+
+type yamlField struct {
+	key string
+	typ reflect.Type
+}
+
+// yamlFields returns the keys of the exported fields of the struct t, in order of declaration,
+// flattening inline fields.
+func yamlFields(t reflect.Type) []yamlField {
+	var fields []yamlField
+	for i := range t.NumField() {
+		f := t.Field(i)
+		if !f.IsExported() {
+			continue
+		}
+
+		name, opts, _ := strings.Cut(f.Tag.Get("yaml"), ",")
+		if name == "-" {
+			continue
+		}
+		if slices.Contains(strings.Split(opts, ","), "inline") {
+			fields = append(fields, yamlFields(f.Type)...)
+			continue
+		}
+		if name == "" {
+			name = strings.ToLower(f.Name)
+		}
+		fields = append(fields, yamlField{key: name, typ: f.Type})
+	}
+	return fields
+}
+
+// definitionKeys are the top-level keys holding named definitions, which are separated by blank lines.
+var definitionKeys = []string{"enums", "messages", "tools", "agents", "workflows", "templates"}
+
+// separateDefinitions inserts a blank line before each top-level collection, and between the
+// definitions of the collections listed in definitionKeys. Head comments stay with their node.
+func separateDefinitions(out []byte) ([]byte, error) {
+	var doc yaml.Node
+	if err := yaml.Unmarshal(out, &doc); err != nil {
+		return nil, fmt.Errorf("unmarshal yaml: %w", err)
+	}
+	if len(doc.Content) == 0 || doc.Content[0].Kind != yaml.MappingNode {
+		return out, nil
+	}
+
+	// Lines, starting from 1, before which a blank line is inserted
+	breaks := make(map[int]bool)
+	brk := func(key *yaml.Node) {
+		line := key.Line
+		if key.HeadComment != "" {
+			line -= strings.Count(key.HeadComment, "\n") + 1
+		}
+		breaks[line] = true
+	}
+
+	root := doc.Content[0]
+	for i := 1; i < len(root.Content); i += 2 {
+		key, value := root.Content[i-1], root.Content[i]
+		if i > 1 && value.Kind != yaml.ScalarNode {
+			brk(key)
+		}
+
+		if value.Kind != yaml.MappingNode || !slices.Contains(definitionKeys, key.Value) {
+			continue
+		}
+		for j := 3; j < len(value.Content); j += 2 {
+			brk(value.Content[j-1])
+		}
+	}
+
+	lines := strings.SplitAfter(string(out), "\n")
+
+	var buf bytes.Buffer
+	for i, line := range lines {
+		if breaks[i+1] && i > 0 && strings.TrimSpace(lines[i-1]) != "" {
+			buf.WriteString("\n")
+		}
+		buf.WriteString(line)
+	}
+	return buf.Bytes(), nil
+}
@@ -17,19 +17,34 @@ package spec
 
 import (
 	"fmt"
+	"maps"
 	"os"
+	"path/filepath"
+	"reflect"
+	"regexp"
+	"slices"
+	"strconv"
+	"strings"
 
+	"github.com/ostafen/suricata/runtime"
 	"gopkg.in/yaml.v3"
 )
 
 // Root of the spec
 type Spec struct {
-	Version  string             `yaml:"version"`
-	Package  string             `yaml:"package"`
-	Enums    map[string]Enum    `yaml:"enums"`
-	Messages map[string]Message `yaml:"messages"`
-	Tools    map[string]Tool    `yaml:"tools"`
-	Agents   map[string]Agent   `yaml:"agents"`
+	Version   string              `yaml:"version"`
+	Package   string              `yaml:"package"`
+	GoPackage string              `yaml:"go_package,omitempty"` // Import path of the generated Go package, overriding the one derived from Package
+	Imports   []string            `yaml:"imports,omitempty"`    // Spec files, relative to this one, whose enums, messages and tools can be referenced
+	Enums     map[string]Enum     `yaml:"enums,omitempty"`
+	Messages  map[string]Message  `yaml:"messages,omitempty"`
+	Tools     map[string]Tool     `yaml:"tools,omitempty"`
+	Agents    map[string]Agent    `yaml:"agents,omitempty"`
+	Workflows map[string]Workflow `yaml:"workflows,omitempty"`
+	Templates map[string]string   `yaml:"templates,omitempty"` // Named sub-templates, which prompts can include with {{template "name" .}}
+
+	origins  map[string]string // File defining each imported enum, message and tool, keyed by "<kind>s/<name>"
+	packages map[string]string // Go package of the imported definitions of specs declaring a package, keyed as origins
 }
 
 type Enum struct {
@@ -38,7 +53,31 @@ type Enum struct {
 }
 
 type Message struct {
-	Fields []Field `yaml:"fields"`
+	Fields []Field `yaml:"fields,omitempty"`
+	// OneOf turns the message into a tagged union of the listed message types.
+	// A union message cannot declare fields.
+	OneOf []string `yaml:"oneof,omitempty"`
+	// Rules are invariants relating the fields of the message, such as "check_out > check_in",
+	// checked on the outputs of actions. Violations are sent back to the model for repair.
+	Rules []string `yaml:"rules,omitempty"`
+}
+
+// IsUnion reports whether the message is a oneof union of other messages.
+func (msg *Message) IsUnion() bool {
+	return len(msg.OneOf) > 0
+}
+
+// TypeRefs returns the types referenced by the fields and the variants of the message,
+// without duplicates. Map types, even nested, are replaced by the type of their values.
+func (msg *Message) TypeRefs() []string {
+	refs := slices.Clone(msg.OneOf)
+	for _, field := range msg.Fields {
+		t := ElemType(field.Type)
+		if !slices.Contains(refs, t) {
+			refs = append(refs, t)
+		}
+	}
+	return refs
 }
 
 type Field struct {
@@ -47,29 +86,182 @@ type Field struct {
 	Description string `yaml:"description,omitempty"`
 	Repeated    bool   `yaml:"repeated,omitempty"`
 	Optional    bool   `yaml:"optional,omitempty"`
+
+	// Validation constraints. For repeated fields, they apply to each element.
+	Min       *float64 `yaml:"min,omitempty"`        // Minimum value of numeric fields
+	Max       *float64 `yaml:"max,omitempty"`        // Maximum value of numeric fields
+	MinLength *int     `yaml:"min_length,omitempty"` // Minimum length of string fields
+	MaxLength *int     `yaml:"max_length,omitempty"` // Maximum length of string fields
+	Pattern   string   `yaml:"pattern,omitempty"`    // Regular expression string fields must match
+	Format    string   `yaml:"format,omitempty"`     // JSON Schema format of string fields (e.g. date, email), or date-time for datetime fields
+	Enum      []string `yaml:"enum,omitempty"`       // Allowed values of string fields
+}
+
+// knownFormats lists the JSON Schema formats supported by the runtime validator.
+var knownFormats = map[string]bool{
+	"date":          true,
+	"time":          true,
+	"date-time":     true,
+	"email":         true,
+	"hostname":      true,
+	"ipv4":          true,
+	"ipv6":          true,
+	"uri":           true,
+	"uri-reference": true,
+	"uuid":          true,
+	"regex":         true,
+}
+
+// IsKnownFormat reports whether format is a JSON Schema format supported by the runtime validator.
+func IsKnownFormat(format string) bool {
+	return knownFormats[format]
 }
 
+// ApprovalRequired marks tools whose calls must be approved before running.
+const ApprovalRequired = "required"
+
 type Tool struct {
-	Description string `yaml:"description"`
+	Description string `yaml:"description,omitempty"`
 	Input       string `yaml:"input"`
 	Output      string `yaml:"output"`
+	Approval    string `yaml:"approval,omitempty"` // Set to "required" to pause calls until they are approved
+	Builtin     string `yaml:"builtin,omitempty"`  // Name of a builtin tool, e.g. "retrieval", "exec" or "websearch", providing input and output
+}
+
+// RequiresApproval reports whether calls to the tool must be approved before running.
+func (tool *Tool) RequiresApproval() bool {
+	return tool.Approval == ApprovalRequired
 }
 
 type Agent struct {
 	Instructions string             `yaml:"instructions,omitempty"`
 	Actions      map[string]Actions `yaml:"actions"`
 	Tools        []string           `yaml:"tools"`
+	ModelConfig  `yaml:",inline"`
 }
 
+// ModelConfig overrides the invoker defaults for the actions of an agent.
+// Settings of an action take precedence over the ones of its agent.
+type ModelConfig struct {
+	Model       string   `yaml:"model,omitempty"`
+	Temperature *float64 `yaml:"temperature,omitempty"`
+	MaxTokens   int      `yaml:"max_tokens,omitempty"`
+}
+
+// IsZero reports whether the config leaves all the invoker defaults untouched.
+func (cfg *ModelConfig) IsZero() bool {
+	return cfg.Model == "" && cfg.Temperature == nil && cfg.MaxTokens == 0
+}
+
+func (cfg *ModelConfig) validate() error {
+	if cfg.Temperature != nil && *cfg.Temperature < 0 {
+		return fmt.Errorf("temperature must not be negative")
+	}
+	if cfg.MaxTokens < 0 {
+		return fmt.Errorf("max_tokens must not be negative")
+	}
+	return nil
+}
+
+// TextOutput is the output type of actions replying with free text rather than a message.
+const TextOutput = "text"
+
 type Actions struct {
 	Description string `yaml:"description"`
 	Input       string `yaml:"input"`
 	Output      string `yaml:"output"`
 	Prompt      string `yaml:"prompt"`
-	SkipInput   bool   `yaml:"skip_input"`
+	// PromptFile is a template file, relative to the spec, holding the prompt in place of Prompt.
+	PromptFile string `yaml:"prompt_file,omitempty"`
+	// PromptVersion identifies the revision of the prompt in hooks, logs and metrics.
+	PromptVersion string    `yaml:"prompt_version,omitempty"`
+	Examples      []Example `yaml:"examples,omitempty"` // Few-shot demonstrations, shown to the model before the actual input
+	SkipInput     bool      `yaml:"skip_input"`
+	Stream        bool      `yaml:"stream,omitempty"` // Generate a method streaming the model responses as they are generated
+	// StrictTemplate makes the prompt fail on missing map keys, instead of rendering "<no value>".
+	StrictTemplate bool `yaml:"strict_template,omitempty"`
+	// Tools restricts the tools exposed by the action. When omitted, the action
+	// exposes all the tools of its agent; an empty list exposes no tools.
+	Tools       []string `yaml:"tools,omitempty"`
+	ModelConfig `yaml:",inline"`
+}
+
+// Example is a sample input of an action, together with the expected output:
+// an object of the output message, or a string for free-text actions.
+type Example struct {
+	Input  map[string]any `yaml:"input"`
+	Output any            `yaml:"output"`
+}
+
+// ActionModel returns the model config of the given action of the agent.
+func (agent *Agent) ActionModel(action *Actions) ModelConfig {
+	cfg := action.ModelConfig
+	if cfg.Model == "" {
+		cfg.Model = agent.Model
+	}
+	if cfg.Temperature == nil {
+		cfg.Temperature = agent.Temperature
+	}
+	if cfg.MaxTokens == 0 {
+		cfg.MaxTokens = agent.MaxTokens
+	}
+	return cfg
+}
+
+// IsTextOutput reports whether the action replies with free text. This is the case
+// when the output is omitted or set to "text".
+func (action *Actions) IsTextOutput() bool {
+	return action.Output == "" || action.Output == TextOutput
+}
+
+// ActionTools returns the tools exposed by the given action of the agent.
+func (agent *Agent) ActionTools(action *Actions) []string {
+	if action.Tools != nil {
+		return action.Tools
+	}
+	return agent.Tools
+}
+
+// AllTools returns the tools used by the agent or by any of its actions,
+// without duplicates, in order of declaration.
+func (agent *Agent) AllTools() []string {
+	tools := slices.Clone(agent.Tools)
+	for _, name := range slices.Sorted(maps.Keys(agent.Actions)) {
+		for _, tool := range agent.Actions[name].Tools {
+			if !slices.Contains(tools, tool) {
+				tools = append(tools, tool)
+			}
+		}
+	}
+	return tools
 }
 
 func LoadSpec(path string) (*Spec, error) {
+	spec, err := loadSpecFile(path)
+	if err != nil {
+		return nil, err
+	}
+
+	absPath, err := filepath.Abs(path)
+	if err != nil {
+		return nil, err
+	}
+
+	if err := spec.resolveImports(absPath, map[string]bool{absPath: true}); err != nil {
+		return nil, err
+	}
+	spec.resolveBuiltins()
+
+	var c checker
+	spec.resolvePromptFiles(absPath, &c)
+	if err := c.err(); err != nil {
+		return nil, err
+	}
+
+	return spec, spec.Validate()
+}
+
+func loadSpecFile(path string) (*Spec, error) {
 	data, err := os.ReadFile(path)
 	if err != nil {
 		return nil, fmt.Errorf("read file: %w", err)
@@ -79,143 +271,702 @@ func LoadSpec(path string) (*Spec, error) {
 	if err := yaml.Unmarshal(data, &spec); err != nil {
 		return nil, fmt.Errorf("unmarshal yaml: %w", err)
 	}
-	return &spec, spec.Validate()
+	return &spec, nil
+}
+
+// resolveImports loads the imported specs, recursively, and merges their enums,
+// messages, tools and templates into spec. Imported files may omit version and package.
+func (spec *Spec) resolveImports(path string, visiting map[string]bool) error {
+	for _, imp := range spec.Imports {
+		impPath := imp
+		if !filepath.IsAbs(impPath) {
+			impPath = filepath.Join(filepath.Dir(path), imp)
+		}
+
+		if visiting[impPath] {
+			return fmt.Errorf("spec: import cycle detected: %q imports %q", path, imp)
+		}
+
+		imported, err := loadSpecFile(impPath)
+		if err != nil {
+			return fmt.Errorf("spec: import %q: %w", imp, err)
+		}
+
+		visiting[impPath] = true
+		err = imported.resolveImports(impPath, visiting)
+		delete(visiting, impPath)
+		if err != nil {
+			return err
+		}
+
+		spec.recordOrigins(imported, impPath)
+		if err := spec.merge(imported, imp); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// resolvePromptFiles loads the prompts of the actions setting prompt_file, relative
+// to the spec at path.
+func (spec *Spec) resolvePromptFiles(path string, c *checker) {
+	for _, name := range sortedKeys(spec.Agents) {
+		agent := spec.Agents[name]
+
+		for _, actionName := range sortedKeys(agent.Actions) {
+			action := agent.Actions[actionName]
+			if action.PromptFile == "" {
+				continue
+			}
+
+			actionPath := []string{"agents", name, "actions", actionName, "prompt_file"}
+			if action.Prompt != "" {
+				c.errorf(actionPath, "agent %q action %q sets both prompt and prompt_file", name, actionName)
+				continue
+			}
+
+			file := action.PromptFile
+			if !filepath.IsAbs(file) {
+				file = filepath.Join(filepath.Dir(path), file)
+			}
+
+			data, err := os.ReadFile(file)
+			if err != nil {
+				c.errorf(actionPath, "agent %q action %q: read prompt_file: %v", name, actionName, err)
+				continue
+			}
+			action.Prompt = string(data)
+			agent.Actions[actionName] = action
+		}
+	}
+}
+
+// recordOrigins records the file defining each definition of imported, loaded from path,
+// which is not already defined by spec.
+func (spec *Spec) recordOrigins(imported *Spec, path string) {
+	if spec.origins == nil {
+		spec.origins = make(map[string]string)
+	}
+	if spec.packages == nil {
+		spec.packages = make(map[string]string)
+	}
+
+	record := func(key string, defined bool) {
+		if _, ok := spec.origins[key]; ok || defined {
+			return
+		}
+
+		origin, ok := imported.origins[key]
+		if !ok {
+			origin = path
+		}
+		spec.origins[key] = origin
+
+		// Definitions of files without a package belong to the package importing them
+		if pkg, ok := imported.packages[key]; ok {
+			spec.packages[key] = pkg
+		} else if imported.Package != "" || imported.GoPackage != "" {
+			spec.packages[key] = imported.GoPackagePath()
+		}
+	}
+
+	for name := range imported.Enums {
+		_, defined := spec.Enums[name]
+		record("enums/"+name, defined)
+	}
+	for name := range imported.Messages {
+		_, defined := spec.Messages[name]
+		record("messages/"+name, defined)
+	}
+	for name := range imported.Tools {
+		_, defined := spec.Tools[name]
+		record("tools/"+name, defined)
+	}
+	for name := range imported.Templates {
+		_, defined := spec.Templates[name]
+		record("templates/"+name, defined)
+	}
+}
+
+func (spec *Spec) merge(other *Spec, source string) error {
+	if err := mergeDefs(&spec.Enums, other.Enums, "enum", source); err != nil {
+		return err
+	}
+	if err := mergeDefs(&spec.Messages, other.Messages, "message", source); err != nil {
+		return err
+	}
+	if err := mergeDefs(&spec.Tools, other.Tools, "tool", source); err != nil {
+		return err
+	}
+	return mergeDefs(&spec.Templates, other.Templates, "template", source)
+}
+
+// mergeDefs copies the definitions of src into dst. The same name can be defined more
+// than once only if all definitions are identical (e.g. a file imported twice).
+func mergeDefs[T any](dst *map[string]T, src map[string]T, kind, source string) error {
+	if len(src) == 0 {
+		return nil
+	}
+
+	if *dst == nil {
+		*dst = make(map[string]T, len(src))
+	}
+
+	for name, def := range src {
+		if existing, ok := (*dst)[name]; ok && !reflect.DeepEqual(existing, def) {
+			return fmt.Errorf("spec: %s %q imported from %q conflicts with an existing definition", kind, name, source)
+		}
+		(*dst)[name] = def
+	}
+	return nil
 }
 
 // isPrimitiveType checks if the given type is a built-in primitive type
 func isPrimitiveType(t string) bool {
 	switch t {
-	case "string", "int", "int32", "int64", "float", "float32", "float64", "bool", "datetime":
+	case "string", "int", "int32", "int64", "float", "float32", "float64", "decimal", "money", "bool", "datetime", "uuid", "url", "bytes", "image", "file":
 		return true
 	default:
 		return false
 	}
 }
 
+// ParseMapType splits a map type of the form "map<K, V>" into its key and value types.
+func ParseMapType(t string) (key, value string, ok bool) {
+	if !strings.HasPrefix(t, "map<") || !strings.HasSuffix(t, ">") {
+		return "", "", false
+	}
+
+	key, value, ok = strings.Cut(t[len("map<"):len(t)-1], ",")
+	if !ok {
+		return "", "", false
+	}
+	return strings.TrimSpace(key), strings.TrimSpace(value), true
+}
+
+// ElemType returns the type of the values of t, unwrapping nested maps, or t if it is not a map.
+func ElemType(t string) string {
+	for {
+		_, value, ok := ParseMapType(t)
+		if !ok {
+			return t
+		}
+		t = value
+	}
+}
+
 // isEnumType checks if the given type is a defined enum type
 func (spec *Spec) isEnumType(t string) bool {
 	_, exists := spec.Enums[t]
 	return exists
 }
 
+// isKnownType checks if the given type is a primitive, an enum or a message type
+func (spec *Spec) isKnownType(t string) bool {
+	if isPrimitiveType(t) || spec.isEnumType(t) {
+		return true
+	}
+	_, ok := spec.Messages[t]
+	return ok
+}
+
+// GoPackagePath returns the import path of the generated Go package: GoPackage if set,
+// or Package with dots replaced by slashes.
+func (spec *Spec) GoPackagePath() string {
+	if spec.GoPackage != "" {
+		return spec.GoPackage
+	}
+	return strings.ReplaceAll(spec.Package, ".", "/")
+}
+
+// GoPackageOf returns the import path of the Go package defining the type generated for the enum or
+// message name: the package of the spec, or the one of the imported spec declaring it, if it declares a
+// package. In the latter case, the generated code refers to the types of the imported package, which
+// must be generated from the imported spec, instead of duplicating them.
+func (spec *Spec) GoPackageOf(name string) string {
+	for _, key := range []string{"messages/" + name, "enums/" + name} {
+		if pkg, ok := spec.packages[key]; ok {
+			return pkg
+		}
+	}
+	return spec.GoPackagePath()
+}
+
+// GoPackageName returns the name of the generated Go package, i.e. the last element of its import path.
+func (spec *Spec) GoPackageName() string {
+	path := spec.GoPackagePath()
+	return path[strings.LastIndex(path, "/")+1:]
+}
+
+// Validate returns the first error found in the spec.
+// Use Check to get all the errors at once, with their positions.
 func (spec *Spec) Validate() error {
+	var c checker
+	spec.validate(&c)
+	return c.err()
+}
+
+func (spec *Spec) validate(c *checker) {
 	if spec.Version == "" {
-		return fmt.Errorf("spec: version is required")
+		c.errorf(nil, "version is required")
 	}
 	if spec.Package == "" {
-		return fmt.Errorf("spec: package is required")
+		c.errorf(nil, "package is required")
 	}
-
-	if err := spec.validateEnums(); err != nil {
-		return err
+	if spec.GoPackage != "" && !isValidGoPackage(spec.GoPackage) {
+		c.errorf([]string{"go_package"}, "invalid go_package %q: expected an import path whose last element is a valid package name", spec.GoPackage)
 	}
 
-	if err := spec.validateMessages(); err != nil {
-		return err
-	}
+	spec.validateEnums(c)
+	spec.validateMessages(c)
+	spec.validateTools(c)
+	spec.validateTemplates(c)
+	spec.validateAgents(c)
+	spec.validateWorkflows(c)
+}
 
-	if err := spec.validateTools(); err != nil {
-		return err
-	}
-	return spec.validateAgents()
+var goPackageRegexp = regexp.MustCompile(`^([A-Za-z0-9._~-]+/)*[A-Za-z_][A-Za-z0-9_]*$`)
+
+func isValidGoPackage(path string) bool {
+	return goPackageRegexp.MatchString(path)
 }
 
-func (spec *Spec) validateEnums() error {
-	for name, enum := range spec.Enums {
+func (spec *Spec) validateEnums(c *checker) {
+	for _, name := range sortedKeys(spec.Enums) {
+		enum := spec.Enums[name]
+		path := []string{"enums", name}
+
 		if name == "" {
-			return fmt.Errorf("spec: enum has empty name")
+			c.errorf(path, "enum has empty name")
 		}
 		if len(enum.Values) == 0 {
-			return fmt.Errorf("spec: enum %q has no values", name)
+			c.errorf(path, "enum %q has no values", name)
 		}
 		// Check for duplicate values
 		seen := make(map[string]bool)
-		for _, value := range enum.Values {
+		for i, value := range enum.Values {
+			valuePath := append(path, "values", strconv.Itoa(i))
 			if value == "" {
-				return fmt.Errorf("spec: enum %q has empty value", name)
+				c.errorf(valuePath, "enum %q has empty value", name)
 			}
 			if seen[value] {
-				return fmt.Errorf("spec: enum %q has duplicate value %q", name, value)
+				c.errorf(valuePath, "enum %q has duplicate value %q", name, value)
 			}
 			seen[value] = true
 		}
 	}
-	return nil
 }
 
-func (spec *Spec) validateMessages() error {
-	for name, msg := range spec.Messages {
+func (spec *Spec) validateMessages(c *checker) {
+	for _, name := range sortedKeys(spec.Messages) {
+		msg := spec.Messages[name]
+		path := []string{"messages", name}
+
 		if name == "" {
-			return fmt.Errorf("spec: message has empty name")
+			c.errorf(path, "message has empty name")
+		}
+		if name == TextOutput {
+			c.errorf(path, "message name %q is reserved", name)
 		}
-		for _, field := range msg.Fields {
+		spec.validateUnion(c, name, &msg)
+		spec.validateRules(c, name, &msg)
+		spec.validateRecursion(c, name)
+
+		for i, field := range msg.Fields {
+			fieldPath := append(path, "fields", strconv.Itoa(i))
+
 			if field.Name == "" {
-				return fmt.Errorf("spec: field in message %q has empty name", name)
+				c.errorf(fieldPath, "field in message %q has empty name", name)
 			}
 			if field.Type == "" {
-				return fmt.Errorf("spec: field %q in message %q has empty type", field.Name, name)
+				c.errorf(fieldPath, "field %q in message %q has empty type", field.Name, name)
+				continue
 			}
 			// Validate field type existence
-			if !isPrimitiveType(field.Type) && !spec.isEnumType(field.Type) {
-				if _, ok := spec.Messages[field.Type]; !ok {
-					return fmt.Errorf("spec: field %q in message %q references undefined type %q", field.Name, name, field.Type)
+			fieldType := field.Type
+			for {
+				key, value, ok := ParseMapType(fieldType)
+				if !ok {
+					break
 				}
+				if key != "string" {
+					c.errorf(append(fieldPath, "type"), "field %q in message %q has unsupported map key type %q (only string is allowed)", field.Name, name, key)
+				}
+				fieldType = value
+			}
+
+			if !spec.isKnownType(fieldType) {
+				c.errorf(append(fieldPath, "type"), "field %q in message %q references undefined type %q", field.Name, name, fieldType)
+			}
+
+			if err := validateConstraints(&field); err != nil {
+				c.errorf(fieldPath, "field %q in message %q: %v", field.Name, name, err)
+			}
+		}
+	}
+}
+
+func isNumericType(t string) bool {
+	switch t {
+	case "int", "int32", "int64", "float", "float32", "float64":
+		return true
+	default:
+		return false
+	}
+}
+
+func validateConstraints(field *Field) error {
+	numeric := isNumericType(field.Type)
+	str := field.Type == "string"
+
+	if (field.Min != nil || field.Max != nil) && !numeric {
+		return fmt.Errorf("min and max require a numeric type, got %q", field.Type)
+	}
+	if field.Min != nil && field.Max != nil && *field.Min > *field.Max {
+		return fmt.Errorf("min (%v) is greater than max (%v)", *field.Min, *field.Max)
+	}
+
+	if (field.MinLength != nil || field.MaxLength != nil || field.Pattern != "" || len(field.Enum) > 0) && !str {
+		return fmt.Errorf("min_length, max_length, pattern and enum require the string type, got %q", field.Type)
+	}
+
+	// Datetime fields are decoded into a time.Time, from RFC3339 timestamps only
+	if field.Type == "datetime" && field.Format != "" && field.Format != "date-time" {
+		return fmt.Errorf("format of datetime fields must be date-time, got %q: use a string field for other formats", field.Format)
+	}
+	if field.Format != "" && !str && field.Type != "datetime" {
+		return fmt.Errorf("format requires the string or datetime type, got %q", field.Type)
+	}
+	if field.MinLength != nil && *field.MinLength < 0 {
+		return fmt.Errorf("min_length must not be negative")
+	}
+	if field.MaxLength != nil && *field.MaxLength < 0 {
+		return fmt.Errorf("max_length must not be negative")
+	}
+	if field.MinLength != nil && field.MaxLength != nil && *field.MinLength > *field.MaxLength {
+		return fmt.Errorf("min_length (%d) is greater than max_length (%d)", *field.MinLength, *field.MaxLength)
+	}
+
+	if field.Pattern != "" {
+		if _, err := regexp.Compile(field.Pattern); err != nil {
+			return fmt.Errorf("invalid pattern: %w", err)
+		}
+	}
+	if field.Format != "" && !knownFormats[field.Format] {
+		return fmt.Errorf("unknown format %q", field.Format)
+	}
+	return nil
+}
+
+// validateRules checks that the rules of a message compare its fields with values of the same kind.
+func (spec *Spec) validateRules(c *checker, name string, msg *Message) {
+	for i, expr := range msg.Rules {
+		path := []string{"messages", name, "rules", strconv.Itoa(i)}
+
+		rule, err := runtime.ParseRule(expr)
+		if err != nil {
+			c.errorf(path, "message %q: %v", name, err)
+			continue
+		}
+
+		left, err := spec.ruleOperandKind(msg, rule.Left)
+		if err != nil {
+			c.errorf(path, "message %q: rule %q: %v", name, expr, err)
+			continue
+		}
+		right, err := spec.ruleOperandKind(msg, rule.Right)
+		if err != nil {
+			c.errorf(path, "message %q: rule %q: %v", name, expr, err)
+			continue
+		}
+
+		switch {
+		case left != right:
+			c.errorf(path, "message %q: rule %q compares a %s with a %s", name, expr, left, right)
+		case left == "bool" && rule.Op != "==" && rule.Op != "!=":
+			c.errorf(path, "message %q: rule %q: booleans can only be compared with == and !=", name, expr)
+		}
+	}
+}
+
+// ruleOperandKind returns the kind of value of a rule operand: number, string or bool.
+// Fields are looked up in msg, following the nested messages named by their path.
+func (spec *Spec) ruleOperandKind(msg *Message, op runtime.RuleOperand) (string, error) {
+	switch op.Value.(type) {
+	case float64:
+		return "number", nil
+	case string:
+		return "string", nil
+	case bool:
+		return "bool", nil
+	}
+
+	segments := strings.Split(op.Field, ".")
+	for i, segment := range segments {
+		field, ok := msg.field(segment)
+		if !ok {
+			return "", fmt.Errorf("no field %q", strings.Join(segments[:i+1], "."))
+		}
+		if field.Repeated {
+			return "", fmt.Errorf("field %q is repeated", op.Field)
+		}
+
+		if i < len(segments)-1 {
+			next, ok := spec.Messages[field.Type]
+			if !ok || next.IsUnion() {
+				return "", fmt.Errorf("field %q is not a message", strings.Join(segments[:i+1], "."))
 			}
+			msg = &next
+			continue
+		}
+
+		switch {
+		case isNumericType(field.Type) || field.Type == "decimal" || field.Type == "money":
+			return "number", nil
+		case field.Type == "string" || field.Type == "datetime" || field.Type == "uuid" || field.Type == "url":
+			return "string", nil
+		case field.Type == "bool":
+			return "bool", nil
+		}
+		if _, ok := spec.Enums[field.Type]; ok {
+			return "string", nil
+		}
+	}
+	return "", fmt.Errorf("field %q cannot be compared", op.Field)
+}
+
+// Rules returns the rules of the message name, and the ones of the messages nested in its fields,
+// applied to the JSON path of the nested messages, such as "legs" for the elements of a list.
+// Invalid rules are skipped: they are reported by Validate.
+func (spec *Spec) Rules(name string) []runtime.Rule {
+	return spec.collectRules(name, "", map[string]bool{})
+}
+
+func (spec *Spec) collectRules(name, scope string, visiting map[string]bool) []runtime.Rule {
+	msg, ok := spec.Messages[name]
+	if !ok || visiting[name] {
+		return nil
+	}
+	visiting[name] = true
+	defer delete(visiting, name)
+
+	var rules []runtime.Rule
+	for _, expr := range msg.Rules {
+		if rule, err := runtime.ParseRule(expr); err == nil {
+			rules = append(rules, rule.At(scope))
+		}
+	}
+
+	for _, field := range msg.Fields {
+		fieldScope := field.Name
+		if scope != "" {
+			fieldScope = scope + "." + field.Name
+		}
+		rules = append(rules, spec.collectRules(field.Type, fieldScope, visiting)...)
+	}
+	return rules
+}
+
+// validateRecursion checks that a message referencing itself does so through at least one
+// optional, repeated or map field, or a union: otherwise its values would be infinite.
+// A cycle is reported once, on the first of its messages by name.
+func (spec *Spec) validateRecursion(c *checker, name string) {
+	cycle := spec.requiredCycle(name, name, nil, map[string]bool{})
+	if cycle == nil {
+		return
+	}
+
+	for _, ref := range cycle {
+		if msgName, _, _ := strings.Cut(ref, "."); msgName < name {
+			return
+		}
+	}
+	c.errorf([]string{"messages", name}, "message %q references itself through required fields only (%s): make one of them optional or repeated",
+		name, strings.Join(cycle, " -> "))
+}
+
+// requiredCycle returns the required, non-repeated fields leading from the message current back to
+// start, as "Message.field", or nil if there are none.
+func (spec *Spec) requiredCycle(start, current string, path []string, visited map[string]bool) []string {
+	msg := spec.Messages[current]
+	for _, field := range msg.Fields {
+		if _, _, isMap := ParseMapType(field.Type); field.Optional || field.Repeated || isMap {
+			continue
+		}
+
+		fieldPath := append(slices.Clip(path), current+"."+field.Name)
+		if field.Type == start {
+			return fieldPath
+		}
+
+		next, ok := spec.Messages[field.Type]
+		if !ok || next.IsUnion() || visited[field.Type] {
+			continue
+		}
+		visited[field.Type] = true
+		if cycle := spec.requiredCycle(start, field.Type, fieldPath, visited); cycle != nil {
+			return cycle
 		}
 	}
 	return nil
 }
 
-func (spec *Spec) validateTools() error {
-	for name, tool := range spec.Tools {
+func (spec *Spec) validateUnion(c *checker, name string, msg *Message) {
+	if !msg.IsUnion() {
+		return
+	}
+
+	path := []string{"messages", name, "oneof"}
+	if len(msg.Fields) > 0 {
+		c.errorf(path, "message %q cannot declare both fields and oneof", name)
+	}
+	if len(msg.OneOf) < 2 {
+		c.errorf(path, "oneof message %q must list at least two variants", name)
+	}
+
+	seen := make(map[string]bool)
+	for i, variant := range msg.OneOf {
+		variantPath := append(path, strconv.Itoa(i))
+
+		if seen[variant] {
+			c.errorf(variantPath, "oneof message %q has duplicate variant %q", name, variant)
+		}
+		seen[variant] = true
+
+		if variant == name {
+			c.errorf(variantPath, "oneof message %q cannot reference itself", name)
+		} else if _, ok := spec.Messages[variant]; !ok {
+			c.errorf(variantPath, "oneof message %q references undefined message %q", name, variant)
+		}
+	}
+}
+
+func (spec *Spec) validateTools(c *checker) {
+	for _, name := range sortedKeys(spec.Tools) {
+		tool := spec.Tools[name]
+		path := []string{"tools", name}
+
 		if name == "" {
-			return fmt.Errorf("spec: tool has empty name")
+			c.errorf(path, "tool has empty name")
+		}
+
+		if tool.Approval != "" && !tool.RequiresApproval() {
+			c.errorf(append(path, "approval"), "tool %q has invalid approval %q (expected %q)", name, tool.Approval, ApprovalRequired)
+		}
+
+		if tool.Builtin != "" {
+			spec.validateBuiltin(c, name, &tool)
+			continue
 		}
+
 		if tool.Input == "" {
-			return fmt.Errorf("spec: tool %q missing input type", name)
+			c.errorf(path, "tool %q missing input type", name)
+		} else if _, ok := spec.Messages[tool.Input]; !ok {
+			c.errorf(append(path, "input"), "tool %q input references undefined message %q", name, tool.Input)
 		}
+
 		if tool.Output == "" {
-			return fmt.Errorf("spec: tool %q missing output type", name)
+			c.errorf(path, "tool %q missing output type", name)
+		} else if _, ok := spec.Messages[tool.Output]; !ok {
+			c.errorf(append(path, "output"), "tool %q output references undefined message %q", name, tool.Output)
 		}
+	}
+}
 
-		if _, ok := spec.Messages[tool.Input]; !ok {
-			return fmt.Errorf("spec: tool %q input references undefined message %q", name, tool.Input)
+func (spec *Spec) validateTemplates(c *checker) {
+	for _, name := range sortedKeys(spec.Templates) {
+		if name == "" {
+			c.errorf([]string{"templates"}, "template has empty name")
+			continue
+		}
+		if _, err := runtime.ParsePrompt("", map[string]string{name: spec.Templates[name]}); err != nil {
+			c.errorf([]string{"templates", name}, "invalid template %q: %v", name, err)
 		}
-		if _, ok := spec.Messages[tool.Output]; !ok {
-			return fmt.Errorf("spec: tool %q output references undefined message %q", name, tool.Output)
+	}
+}
+
+// validateExample checks that value, a sample of the message msgName, is an object
+// only setting fields of the message. Nested values are not checked.
+func (spec *Spec) validateExample(msgName string, value any) error {
+	msg, ok := spec.Messages[msgName]
+	if !ok || msg.IsUnion() {
+		return nil
+	}
+
+	obj, ok := value.(map[string]any)
+	if !ok {
+		return fmt.Errorf("expected a %s object", msgName)
+	}
+
+	for _, key := range sortedKeys(obj) {
+		if !slices.ContainsFunc(msg.Fields, func(field Field) bool { return field.Name == key }) {
+			return fmt.Errorf("message %q has no field %q", msgName, key)
 		}
 	}
 	return nil
 }
 
-func (spec *Spec) validateAgents() error {
-	for name, agent := range spec.Agents {
+func (spec *Spec) validateAgents(c *checker) {
+	for _, name := range sortedKeys(spec.Agents) {
+		agent := spec.Agents[name]
+		path := []string{"agents", name}
+
 		if name == "" {
-			return fmt.Errorf("spec: agent has empty name")
+			c.errorf(path, "agent has empty name")
+		}
+		if err := agent.ModelConfig.validate(); err != nil {
+			c.errorf(path, "agent %q: %v", name, err)
 		}
 
-		for actionName, action := range agent.Actions {
+		for _, actionName := range sortedKeys(agent.Actions) {
+			action := agent.Actions[actionName]
+			actionPath := append(path, "actions", actionName)
+
 			if actionName == "" {
-				return fmt.Errorf("spec: agent %q has action with empty name", name)
+				c.errorf(actionPath, "agent %q has action with empty name", name)
 			}
 			if action.Input != "" {
 				if _, ok := spec.Messages[action.Input]; !ok {
-					return fmt.Errorf("spec: agent %q action %q input references undefined message %q", name, actionName, action.Input)
+					c.errorf(append(actionPath, "input"), "agent %q action %q input references undefined message %q", name, actionName, action.Input)
 				}
 			}
-			if action.Output != "" {
+			if !action.IsTextOutput() {
 				if _, ok := spec.Messages[action.Output]; !ok {
-					return fmt.Errorf("spec: agent %q action %q output references undefined message %q", name, actionName, action.Output)
+					c.errorf(append(actionPath, "output"), "agent %q action %q output references undefined message %q", name, actionName, action.Output)
+				}
+			}
+			if err := action.ModelConfig.validate(); err != nil {
+				c.errorf(actionPath, "agent %q action %q: %v", name, actionName, err)
+			}
+			if _, err := runtime.ParsePrompt(action.Prompt, nil); err != nil {
+				c.errorf(append(actionPath, "prompt"), "agent %q action %q: invalid prompt: %v", name, actionName, err)
+			}
+			for i, example := range action.Examples {
+				examplePath := append(actionPath, "examples", strconv.Itoa(i))
+				if err := spec.validateExample(action.Input, example.Input); err != nil {
+					c.errorf(append(examplePath, "input"), "agent %q action %q example %d: invalid input: %v", name, actionName, i+1, err)
+				}
+
+				if action.IsTextOutput() {
+					if _, ok := example.Output.(string); !ok {
+						c.errorf(append(examplePath, "output"), "agent %q action %q example %d: expected a text output", name, actionName, i+1)
+					}
+				} else if err := spec.validateExample(action.Output, example.Output); err != nil {
+					c.errorf(append(examplePath, "output"), "agent %q action %q example %d: invalid output: %v", name, actionName, i+1, err)
+				}
+			}
+			for i, toolName := range action.Tools {
+				if _, ok := spec.Tools[toolName]; !ok {
+					c.errorf(append(actionPath, "tools", strconv.Itoa(i)), "agent %q action %q references undefined tool %q", name, actionName, toolName)
 				}
 			}
 		}
 
 		// Validate tools used by agent
-		for _, toolName := range agent.Tools {
+		for i, toolName := range agent.Tools {
 			if _, ok := spec.Tools[toolName]; !ok {
-				return fmt.Errorf("spec: agent %q references undefined tool %q", name, toolName)
+				c.errorf(append(path, "tools", strconv.Itoa(i)), "agent %q references undefined tool %q", name, toolName)
 			}
 		}
 	}
-	return nil
 }
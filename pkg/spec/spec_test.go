@@ -0,0 +1,1144 @@
+// Copyright (c) 2025 Suricata Contributors
+// Original Author: Stefano Scafiti
+//
+// This file is part of Suricata: Type-Safe AI Agents for Go.
+//
+// Licensed under the MIT License. You may obtain a copy of the License at
+//
+//	https://opensource.org/licenses/MIT
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+package spec_test
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"slices"
+	"strings"
+	"testing"
+
+	"github.com/ostafen/suricata/pkg/spec"
+)
+
+func writeFile(t *testing.T, dir, name, content string) string {
+	t.Helper()
+
+	path := filepath.Join(dir, name)
+	if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(path, []byte(content), 0644); err != nil {
+		t.Fatal(err)
+	}
+	return path
+}
+
+func TestLoadSpec_Imports(t *testing.T) {
+	dir := t.TempDir()
+
+	writeFile(t, dir, "common/location.yml", `
+messages:
+  Location:
+    fields:
+      - name: city
+        type: string
+`)
+
+	writeFile(t, dir, "common/types.yml", `
+imports:
+  - location.yml
+messages:
+  Trip:
+    fields:
+      - name: to
+        type: Location
+`)
+
+	path := writeFile(t, dir, "trip.yml", `
+version: 0.0.1
+package: trip
+imports:
+  - common/types.yml
+  - common/location.yml
+agents:
+  planner:
+    actions:
+      Plan:
+        input: Trip
+        output: Location
+`)
+
+	s, err := spec.LoadSpec(path)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	for _, name := range []string{"Location", "Trip"} {
+		if _, ok := s.Messages[name]; !ok {
+			t.Errorf("expected imported message %q", name)
+		}
+	}
+}
+
+func TestLoadSpec_ImportedPackage(t *testing.T) {
+	dir := t.TempDir()
+
+	writeFile(t, dir, "common.yml", `
+version: 0.0.1
+package: common
+go_package: github.com/acme/app/common
+enums:
+  Unit:
+    values: [KM, MI]
+messages:
+  Location:
+    fields:
+      - name: city
+        type: string
+`)
+	writeFile(t, dir, "shared.yml", `
+messages:
+  Route:
+    fields:
+      - name: to
+        type: Location
+`)
+
+	path := writeFile(t, dir, "trip.yml", `
+version: 0.0.1
+package: trip
+imports: [common.yml, shared.yml]
+`)
+
+	s, err := spec.LoadSpec(path)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	for name, expected := range map[string]string{
+		"Location": "github.com/acme/app/common",
+		"Unit":     "github.com/acme/app/common",
+		"Route":    "trip",
+	} {
+		if pkg := s.GoPackageOf(name); pkg != expected {
+			t.Errorf("%s: expected package %q, got %q", name, expected, pkg)
+		}
+	}
+}
+
+func TestLoadSpec_ImportCycle(t *testing.T) {
+	dir := t.TempDir()
+
+	writeFile(t, dir, "a.yml", "imports: [b.yml]\n")
+	writeFile(t, dir, "b.yml", "imports: [a.yml]\n")
+	path := writeFile(t, dir, "main.yml", "version: 0.0.1\npackage: main\nimports: [a.yml]\n")
+
+	_, err := spec.LoadSpec(path)
+	if err == nil || !strings.Contains(err.Error(), "import cycle") {
+		t.Errorf("expected import cycle error, got %v", err)
+	}
+}
+
+func TestLoadSpec_ImportConflict(t *testing.T) {
+	dir := t.TempDir()
+
+	writeFile(t, dir, "other.yml", `
+messages:
+  Location:
+    fields:
+      - name: country
+        type: string
+`)
+
+	path := writeFile(t, dir, "main.yml", `
+version: 0.0.1
+package: main
+imports: [other.yml]
+messages:
+  Location:
+    fields:
+      - name: city
+        type: string
+`)
+
+	_, err := spec.LoadSpec(path)
+	if err == nil || !strings.Contains(err.Error(), "conflicts") {
+		t.Errorf("expected conflict error, got %v", err)
+	}
+}
+
+func TestLoadSpec_ActionTools(t *testing.T) {
+	dir := t.TempDir()
+
+	path := writeFile(t, dir, "main.yml", `
+version: 0.0.1
+package: main
+messages:
+  Req:
+    fields:
+      - name: text
+        type: string
+tools:
+  Find:
+    input: Req
+    output: Req
+  Book:
+    input: Req
+    output: Req
+agents:
+  planner:
+    tools: [Find]
+    actions:
+      Extract:
+        input: Req
+        output: Req
+        tools: []
+      Plan:
+        input: Req
+        output: Req
+      Reserve:
+        input: Req
+        output: Req
+        tools: [Book]
+`)
+
+	s, err := spec.LoadSpec(path)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	agent := s.Agents["planner"]
+	for action, want := range map[string]string{"Extract": "", "Plan": "Find", "Reserve": "Book"} {
+		a := agent.Actions[action]
+		if got := strings.Join(agent.ActionTools(&a), ","); got != want {
+			t.Errorf("action %s: expected tools %q, got %q", action, want, got)
+		}
+	}
+
+	if got := strings.Join(agent.AllTools(), ","); got != "Find,Book" {
+		t.Errorf("expected all tools Find,Book, got %q", got)
+	}
+}
+
+func TestLoadSpec_ActionModel(t *testing.T) {
+	dir := t.TempDir()
+
+	path := writeFile(t, dir, "main.yml", `
+version: 0.0.1
+package: main
+messages:
+  Req:
+    fields:
+      - name: text
+        type: string
+agents:
+  writer:
+    model: small
+    temperature: 0.2
+    actions:
+      Draft:
+        input: Req
+        output: Req
+      Review:
+        input: Req
+        output: Req
+        model: large
+        max_tokens: 2048
+`)
+
+	s, err := spec.LoadSpec(path)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	agent := s.Agents["writer"]
+
+	draft := agent.Actions["Draft"]
+	if cfg := agent.ActionModel(&draft); cfg.Model != "small" || cfg.MaxTokens != 0 {
+		t.Errorf("unexpected Draft model config: %+v", cfg)
+	}
+
+	review := agent.Actions["Review"]
+	cfg := agent.ActionModel(&review)
+	if cfg.Model != "large" || cfg.MaxTokens != 2048 || cfg.Temperature == nil || *cfg.Temperature != 0.2 {
+		t.Errorf("unexpected Review model config: %+v", cfg)
+	}
+}
+
+func TestCheck(t *testing.T) {
+	dir := t.TempDir()
+
+	writeFile(t, dir, "lib.yml", `
+messages:
+  Shared:
+    fields:
+      - name: x
+        type: Ghost
+  Other:
+    fields:
+      - name: y
+        type: string
+`)
+
+	path := writeFile(t, dir, "main.yml", `version: 0.0.1
+package: main
+imports: [lib.yml]
+messages:
+  Req:
+    fields:
+      - name: text
+        type: strin
+  Unused:
+    fields:
+      - name: a
+        type: string
+tools:
+  Lonely:
+    input: Req
+    output: Req
+agents:
+  writer:
+    tools: [Missing]
+    actions:
+      Draft:
+        input: Req
+        output: Shared
+`)
+
+	diags, err := spec.Check(path)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	var got []string
+	for _, d := range diags {
+		got = append(got, fmt.Sprintf("%s:%d:%d %s %s", filepath.Base(d.File), d.Line, d.Column, d.Severity, d.Message))
+	}
+
+	expected := []string{
+		`lib.yml:6:15 error field "x" in message "Shared" references undefined type "Ghost"`,
+		`main.yml:8:15 error field "text" in message "Req" references undefined type "strin"`,
+		`main.yml:9:3 warning message "Unused" is never used`,
+		`main.yml:14:3 warning tool "Lonely" is not used by any agent`,
+		`main.yml:19:13 error agent "writer" references undefined tool "Missing"`,
+		`main.yml:21:7 warning agent "writer" action "Draft" has no prompt`,
+	}
+	if strings.Join(got, "\n") != strings.Join(expected, "\n") {
+		t.Errorf("expected:\n%s\ngot:\n%s", strings.Join(expected, "\n"), strings.Join(got, "\n"))
+	}
+}
+
+func TestCheck_Recursion(t *testing.T) {
+	path := writeFile(t, t.TempDir(), "main.yml", `version: 0.0.1
+package: main
+messages:
+  Category:
+    fields:
+      - name: name
+        type: string
+      - name: children
+        type: Category
+        repeated: true
+      - name: parent
+        type: Category
+        optional: true
+  Node:
+    fields:
+      - name: edge
+        type: Edge
+  Edge:
+    fields:
+      - name: to
+        type: Node
+agents:
+  shop:
+    actions:
+      Classify:
+        input: Category
+        output: Node
+        prompt: Classify {{.Name}}.
+`)
+
+	diags, err := spec.Check(path)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	var got []string
+	for _, d := range diags {
+		got = append(got, fmt.Sprintf("%d:%d %s %s", d.Line, d.Column, d.Severity, d.Message))
+	}
+
+	expected := `18:3 error message "Edge" references itself through required fields only (Edge.to -> Node.edge): make one of them optional or repeated`
+	if strings.Join(got, "\n") != expected {
+		t.Errorf("expected:\n%s\ngot:\n%s", expected, strings.Join(got, "\n"))
+	}
+}
+
+func TestCheck_SyntaxError(t *testing.T) {
+	path := writeFile(t, t.TempDir(), "main.yml", "version: 0.0.1\nmessages: [\n")
+
+	diags, err := spec.Check(path)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if len(diags) != 1 || diags[0].Severity != spec.SeverityError || diags[0].Line == 0 {
+		t.Errorf("expected a single positioned error, got %v", diags)
+	}
+}
+
+func TestLoadSpec_GoPackage(t *testing.T) {
+	dir := t.TempDir()
+
+	tests := []struct {
+		goPackage string
+		path      string
+		name      string
+		valid     bool
+	}{
+		{"", "example/hello", "hello", true},
+		{"github.com/acme/app/internal/gen/hello", "github.com/acme/app/internal/gen/hello", "hello", true},
+		{"github.com/acme/app/my-pkg", "", "", false},
+		{"github.com/acme/app/", "", "", false},
+	}
+
+	for _, test := range tests {
+		content := "version: 0.0.1\npackage: example.hello\n"
+		if test.goPackage != "" {
+			content += "go_package: " + test.goPackage + "\n"
+		}
+
+		s, err := spec.LoadSpec(writeFile(t, dir, "main.yml", content))
+		if !test.valid {
+			if err == nil || !strings.Contains(err.Error(), "go_package") {
+				t.Errorf("%q: expected go_package error, got %v", test.goPackage, err)
+			}
+			continue
+		}
+
+		if err != nil {
+			t.Fatalf("%q: unexpected error: %v", test.goPackage, err)
+		}
+		if s.GoPackagePath() != test.path || s.GoPackageName() != test.name {
+			t.Errorf("%q: expected %s (%s), got %s (%s)", test.goPackage, test.path, test.name, s.GoPackagePath(), s.GoPackageName())
+		}
+	}
+}
+
+func TestLoadSpec_Workflows(t *testing.T) {
+	dir := t.TempDir()
+
+	const base = `version: 0.0.1
+package: main
+messages:
+  Req:
+    fields:
+      - name: city
+        type: string
+      - name: nights
+        type: int
+        optional: true
+  Plan:
+    fields:
+      - name: stop
+        type: Req
+      - name: days
+        type: int
+agents:
+  planner:
+    actions:
+      plan:
+        input: Req
+        output: Plan
+        prompt: plan
+      describe:
+        input: Req
+        prompt: describe
+workflows:
+  trip:
+    input: Req
+`
+
+	tests := []struct {
+		workflow string
+		err      string
+	}{
+		{`    steps:
+      - {name: plan, agent: planner, action: plan}
+      - {name: describe, agent: planner, action: describe, from: plan.stop}
+`, ""},
+		{`    output: Plan
+    steps:
+      - {name: plan, agent: planner, action: plan}
+      - name: again
+        agent: planner
+        action: plan
+        optional: true
+        input: {city: plan.stop.city, nights: input.nights}
+    result: {stop: input, days: again.days}
+`, ""},
+		{`    steps: []
+`, `workflow "trip" has no steps`},
+		{`    steps:
+      - {name: input, agent: planner, action: plan}
+`, `workflow "trip" step name "input" is reserved`},
+		{`    steps:
+      - {name: plan, agent: planner, action: plan}
+      - {name: plan, agent: planner, action: plan}
+`, `workflow "trip" has duplicate step "plan"`},
+		{`    steps:
+      - {name: plan, agent: ghost, action: plan}
+`, `references undefined agent "ghost"`},
+		{`    steps:
+      - {name: plan, agent: planner, action: plan, input: {city: later.stop.city}}
+      - {name: later, agent: planner, action: plan}
+`, `"later" is neither "input" nor an earlier step`},
+		{`    steps:
+      - {name: plan, agent: planner, action: plan, input: {nights: input.city}}
+`, `expression "input.city" has type string, expected int`},
+		{`    steps:
+      - {name: plan, agent: planner, action: plan, input: {city: input.city.name}}
+`, `cannot select "name" from a value of type "string"`},
+		{`    steps:
+      - {name: plan, agent: planner, action: plan, from: input, input: {city: input.city}}
+`, `cannot set both from and input`},
+		{`    steps:
+      - {name: plan, agent: planner, action: plan, optional: true}
+`, `the last step cannot be optional`},
+		{`    result: {days: plan.days}
+    steps:
+      - {name: plan, agent: planner, action: plan}
+`, `workflow "trip" output references undefined message ""`},
+	}
+
+	for _, test := range tests {
+		_, err := spec.LoadSpec(writeFile(t, dir, "main.yml", base+test.workflow))
+		if test.err == "" {
+			if err != nil {
+				t.Errorf("unexpected error: %v\n%s", err, test.workflow)
+			}
+			continue
+		}
+		if err == nil || !strings.Contains(err.Error(), test.err) {
+			t.Errorf("expected error containing %q, got %v\n%s", test.err, err, test.workflow)
+		}
+	}
+}
+
+func TestLoadSpec_ToolApproval(t *testing.T) {
+	dir := t.TempDir()
+
+	const content = `version: 0.0.1
+package: main
+messages:
+  Req:
+    fields:
+      - name: text
+        type: string
+tools:
+  Book:
+    input: Req
+    output: Req
+    approval: %s
+`
+
+	s, err := spec.LoadSpec(writeFile(t, dir, "main.yml", fmt.Sprintf(content, "required")))
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if tool := s.Tools["Book"]; !tool.RequiresApproval() {
+		t.Error("expected tool to require approval")
+	}
+
+	_, err = spec.LoadSpec(writeFile(t, dir, "main.yml", fmt.Sprintf(content, "always")))
+	if err == nil || !strings.Contains(err.Error(), `tool "Book" has invalid approval "always"`) {
+		t.Errorf("expected invalid approval error, got %v", err)
+	}
+}
+
+func TestLoadSpec_BuiltinTools(t *testing.T) {
+	dir := t.TempDir()
+
+	const base = `version: 0.0.1
+package: main
+tools:
+  Search:
+    builtin: %s
+agents:
+  support:
+    tools: [Search]
+`
+
+	s, err := spec.LoadSpec(writeFile(t, dir, "main.yml", fmt.Sprintf(base, "retrieval")))
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	tool := s.Tools["Search"]
+	if tool.Input != "RetrievalQuery" || tool.Output != "RetrievalResults" || tool.Description == "" {
+		t.Errorf("unexpected tool: %+v", tool)
+	}
+	for _, name := range []string{"RetrievalQuery", "RetrievalResults", "RetrievalResult"} {
+		if _, ok := s.Messages[name]; !ok {
+			t.Errorf("expected message %q to be defined", name)
+		}
+	}
+
+	diags, err := spec.Check(writeFile(t, dir, "main.yml", fmt.Sprintf(base, "retrieval")))
+	if err != nil || len(diags) != 0 {
+		t.Errorf("expected no diagnostics, got %v (%v)", diags, err)
+	}
+
+	s, err = spec.LoadSpec(writeFile(t, dir, "main.yml", fmt.Sprintf(base, "exec")))
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if tool := s.Tools["Search"]; tool.Input != "ExecCommand" || tool.Output != "ExecResult" {
+		t.Errorf("unexpected tool: %+v", tool)
+	}
+
+	s, err = spec.LoadSpec(writeFile(t, dir, "main.yml", fmt.Sprintf(base, "websearch")))
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if tool := s.Tools["Search"]; tool.Input != "WebSearchQuery" || tool.Output != "WebSearchResults" {
+		t.Errorf("unexpected tool: %+v", tool)
+	}
+
+	_, err = spec.LoadSpec(writeFile(t, dir, "main.yml", fmt.Sprintf(base, "ghost")))
+	if err == nil || !strings.Contains(err.Error(), `unknown builtin "ghost"`) {
+		t.Errorf("expected unknown builtin error, got %v", err)
+	}
+
+	_, err = spec.LoadSpec(writeFile(t, dir, "main.yml", fmt.Sprintf(base, "retrieval")+`messages:
+  RetrievalQuery:
+    fields:
+      - name: q
+        type: string
+`))
+	if err == nil || !strings.Contains(err.Error(), `message "RetrievalQuery", required by builtin tool "Search", is already defined`) {
+		t.Errorf("expected conflict error, got %v", err)
+	}
+}
+
+func TestLoadSpec_Templates(t *testing.T) {
+	dir := t.TempDir()
+
+	const content = `version: 0.0.1
+package: main
+templates:
+  person: %q
+messages:
+  Req:
+    fields:
+      - name: name
+        type: string
+agents:
+  Greeter:
+    actions:
+      greet:
+        input: Req
+        prompt: %q
+`
+
+	s, err := spec.LoadSpec(writeFile(t, dir, "main.yml", fmt.Sprintf(content, "{{.Name | upper}}", `Greet {{template "person" .}}`)))
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if s.Templates["person"] != "{{.Name | upper}}" {
+		t.Errorf("unexpected templates: %v", s.Templates)
+	}
+
+	_, err = spec.LoadSpec(writeFile(t, dir, "main.yml", fmt.Sprintf(content, "{{.Name | shout}}", "Greet")))
+	if err == nil || !strings.Contains(err.Error(), `invalid template "person"`) {
+		t.Errorf("expected invalid template error, got %v", err)
+	}
+
+	_, err = spec.LoadSpec(writeFile(t, dir, "main.yml", fmt.Sprintf(content, "{{.Name}}", "Greet {{.Name")))
+	if err == nil || !strings.Contains(err.Error(), `agent "Greeter" action "greet": invalid prompt`) {
+		t.Errorf("expected invalid prompt error, got %v", err)
+	}
+}
+
+func TestLoadSpec_NestedMaps(t *testing.T) {
+	dir := t.TempDir()
+
+	const content = `version: 0.0.1
+package: main
+enums:
+  Level:
+    values: [LOW, HIGH]
+messages:
+  Matrix:
+    fields:
+      - name: cells
+        type: %s
+`
+
+	s, err := spec.LoadSpec(writeFile(t, dir, "main.yml", fmt.Sprintf(content, "map<string, map<string, Level>>")))
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if msg := s.Messages["Matrix"]; !slices.Equal(msg.TypeRefs(), []string{"Level"}) {
+		t.Errorf("expected the nested map to reference Level, got %v", msg.TypeRefs())
+	}
+
+	_, err = spec.LoadSpec(writeFile(t, dir, "main.yml", fmt.Sprintf(content, "map<string, map<int, Level>>")))
+	if err == nil || !strings.Contains(err.Error(), `unsupported map key type "int"`) {
+		t.Errorf("expected map key error, got %v", err)
+	}
+
+	_, err = spec.LoadSpec(writeFile(t, dir, "main.yml", fmt.Sprintf(content, "map<string, map<string, Grade>>")))
+	if err == nil || !strings.Contains(err.Error(), `undefined type "Grade"`) {
+		t.Errorf("expected undefined type error, got %v", err)
+	}
+}
+
+func TestLoadSpec_Constraints(t *testing.T) {
+	dir := t.TempDir()
+
+	const content = `version: 0.0.1
+package: main
+messages:
+  Booking:
+    fields:
+      - name: value
+        type: %s
+        %s
+`
+
+	tests := []struct {
+		typ        string
+		constraint string
+		err        string
+	}{
+		{"string", "format: date", ""},
+		{"string", "format: email", ""},
+		{"string", "format: birthday", `unknown format "birthday"`},
+		{"datetime", "format: date-time", ""},
+		{"datetime", "format: date", "format of datetime fields must be date-time"},
+		{"int", "format: date", "format requires the string or datetime type"},
+		{"float", "min: 0", ""},
+		{"string", "min: 0", "min and max require a numeric type"},
+		{"string", "max_length: 3", ""},
+		{"int", "max_length: 3", "require the string type"},
+		{"string", "pattern: '['", "invalid pattern"},
+	}
+
+	for _, test := range tests {
+		_, err := spec.LoadSpec(writeFile(t, dir, "main.yml", fmt.Sprintf(content, test.typ, test.constraint)))
+		if test.err == "" {
+			if err != nil {
+				t.Errorf("%s with %s: unexpected error: %v", test.typ, test.constraint, err)
+			}
+			continue
+		}
+		if err == nil || !strings.Contains(err.Error(), test.err) {
+			t.Errorf("%s with %s: expected %q error, got %v", test.typ, test.constraint, test.err, err)
+		}
+	}
+}
+
+func TestLoadSpec_OneOf(t *testing.T) {
+	dir := t.TempDir()
+
+	const content = `version: 0.0.1
+package: main
+messages:
+  Card:
+    fields:
+      - name: number
+        type: string
+  PayPal:
+    fields:
+      - name: email
+        type: string
+  PaymentMethod:
+    %s
+`
+
+	s, err := spec.LoadSpec(writeFile(t, dir, "main.yml", fmt.Sprintf(content, "oneof: [Card, PayPal]")))
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if msg := s.Messages["PaymentMethod"]; !msg.IsUnion() || !slices.Equal(msg.TypeRefs(), []string{"Card", "PayPal"}) {
+		t.Errorf("expected a union of Card and PayPal, got %+v", msg)
+	}
+
+	for union, expected := range map[string]string{
+		"oneof: [Card]":                "must list at least two variants",
+		"oneof: [Card, Card]":          `duplicate variant "Card"`,
+		"oneof: [Card, PaymentMethod]": "cannot reference itself",
+		"oneof: [Card, Cash]":          `undefined message "Cash"`,
+		"oneof: [Card, PayPal]\n    fields: [{name: id, type: string}]": "cannot declare both fields and oneof",
+	} {
+		_, err := spec.LoadSpec(writeFile(t, dir, "main.yml", fmt.Sprintf(content, union)))
+		if err == nil || !strings.Contains(err.Error(), expected) {
+			t.Errorf("%s: expected %q error, got %v", union, expected, err)
+		}
+	}
+}
+
+func TestLoadSpec_PromptFile(t *testing.T) {
+	dir := t.TempDir()
+	writeFile(t, dir, "prompts/greet.tmpl", "Greet {{.Name}}\n")
+
+	const content = `version: 0.0.1
+package: main
+messages:
+  Req:
+    fields:
+      - name: name
+        type: string
+agents:
+  Greeter:
+    actions:
+      greet:
+        input: Req
+        prompt_file: %s
+        prompt_version: v2
+`
+
+	s, err := spec.LoadSpec(writeFile(t, dir, "main.yml", fmt.Sprintf(content, "prompts/greet.tmpl")))
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	action := s.Agents["Greeter"].Actions["greet"]
+	if action.Prompt != "Greet {{.Name}}\n" {
+		t.Errorf("unexpected prompt: %q", action.Prompt)
+	}
+	if action.PromptVersion != "v2" {
+		t.Errorf("unexpected prompt version: %q", action.PromptVersion)
+	}
+
+	_, err = spec.LoadSpec(writeFile(t, dir, "main.yml", fmt.Sprintf(content, "prompts/missing.tmpl")))
+	if err == nil || !strings.Contains(err.Error(), `agent "Greeter" action "greet": read prompt_file`) {
+		t.Errorf("expected missing prompt file error, got %v", err)
+	}
+
+	diags, err := spec.Check(writeFile(t, dir, "main.yml", fmt.Sprintf(content, "prompts/greet.tmpl")+"        prompt: Greet\n"))
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if len(diags) != 1 || !strings.Contains(diags[0].Message, "sets both prompt and prompt_file") || diags[0].Line != 13 {
+		t.Errorf("unexpected diagnostics: %v", diags)
+	}
+}
+
+func TestLoadSpec_Examples(t *testing.T) {
+	dir := t.TempDir()
+
+	const content = `version: 0.0.1
+package: main
+messages:
+  Req:
+    fields:
+      - name: text
+        type: string
+  Reply:
+    fields:
+      - name: city
+        type: string
+agents:
+  Extractor:
+    actions:
+      extract:
+        input: Req
+        output: Reply
+        prompt: Extract the city
+        examples:
+          - input: {text: Ada lives in London}
+            output: %s
+`
+
+	s, err := spec.LoadSpec(writeFile(t, dir, "main.yml", fmt.Sprintf(content, "{city: London}")))
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	examples := s.Agents["Extractor"].Actions["extract"].Examples
+	if len(examples) != 1 || examples[0].Input["text"] != "Ada lives in London" {
+		t.Errorf("unexpected examples: %v", examples)
+	}
+
+	_, err = spec.LoadSpec(writeFile(t, dir, "main.yml", fmt.Sprintf(content, "{town: London}")))
+	if err == nil || !strings.Contains(err.Error(), `example 1: invalid output: message "Reply" has no field "town"`) {
+		t.Errorf("expected invalid example error, got %v", err)
+	}
+
+	_, err = spec.LoadSpec(writeFile(t, dir, "main.yml", fmt.Sprintf(content, "London")))
+	if err == nil || !strings.Contains(err.Error(), "expected a Reply object") {
+		t.Errorf("expected invalid example error, got %v", err)
+	}
+}
+
+func TestFormat(t *testing.T) {
+	src := `package: travel
+version: "1"
+# Shared types
+messages:
+  Location: {fields: [{type: string, name: city, optional: true}]}
+  Trip:
+    fields:
+      - {name: to, type: Location, description: 'Destination'}
+agents:
+  Planner:
+    tools: [Search]
+    actions:
+      Plan:
+        prompt: "Plan a trip to {{.to.city}}.\nKeep it short.\n"
+        output: Trip
+        input: Trip
+    instructions: You plan trips
+    model: small
+`
+
+	want := `version: "1"
+package: travel
+
+# Shared types
+messages:
+  Location:
+    fields:
+      - name: city
+        type: string
+        optional: true
+
+  Trip:
+    fields:
+      - name: to
+        type: Location
+        description: Destination
+
+agents:
+  Planner:
+    instructions: You plan trips
+    actions:
+      Plan:
+        input: Trip
+        output: Trip
+        prompt: |
+          Plan a trip to {{.to.city}}.
+          Keep it short.
+    tools:
+      - Search
+    model: small
+`
+
+	out, err := spec.Format([]byte(src))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if string(out) != want {
+		t.Fatalf("unexpected output:\n%s", out)
+	}
+
+	again, err := spec.Format(out)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if string(again) != string(out) {
+		t.Errorf("expected formatting to be idempotent, got:\n%s", again)
+	}
+}
+
+func TestDiff(t *testing.T) {
+	dir := t.TempDir()
+
+	from, err := spec.LoadSpec(writeFile(t, dir, "old.yml", `
+version: "1"
+package: travel
+enums:
+  Class:
+    values: [economy, business]
+messages:
+  Trip:
+    fields:
+      - name: city
+        type: string
+      - name: days
+        type: int
+  Legacy:
+    fields:
+      - name: id
+        type: string
+tools:
+  Search:
+    input: Trip
+    output: Trip
+agents:
+  Planner:
+    actions:
+      Plan:
+        input: Trip
+        output: Trip
+        prompt: Plan a trip
+`))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	to, err := spec.LoadSpec(writeFile(t, dir, "new.yml", `
+version: "1"
+package: travel
+enums:
+  Class:
+    values: [economy, business, first]
+messages:
+  Trip:
+    fields:
+      - name: city
+        type: string
+        description: Destination
+      - name: days
+        type: float
+      - name: budget
+        type: float
+        optional: true
+      - name: people
+        type: int
+    rules:
+      - days > 0
+tools:
+  Search:
+    input: Trip
+    output: Trip
+agents:
+  Planner:
+    tools: [Search]
+    actions:
+      Plan:
+        input: Trip
+        output: Trip
+        prompt: Plan a short trip
+      Summarize:
+        input: Trip
+        prompt: Summarize the trip
+`))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	var got []string
+	for _, c := range spec.Diff(from, to) {
+		got = append(got, c.String())
+	}
+
+	want := []string{
+		`enums.Class: compatible: value "first" added`,
+		`messages.Legacy: breaking: message removed`,
+		`messages.Trip: breaking: rule "days > 0" added`,
+		`messages.Trip.fields.city: compatible: description changed`,
+		`messages.Trip.fields.days: breaking: type changed from int to float`,
+		`messages.Trip.fields.budget: compatible: optional field added`,
+		`messages.Trip.fields.people: breaking: required field added`,
+		`agents.Planner: breaking: tool "Search" added`,
+		`agents.Planner.actions.Plan: compatible: prompt changed`,
+		`agents.Planner.actions.Summarize: compatible: action added`,
+	}
+	if strings.Join(got, "\n") != strings.Join(want, "\n") {
+		t.Errorf("unexpected changes:\n%s", strings.Join(got, "\n"))
+	}
+	if !spec.HasBreaking(spec.Diff(from, to)) {
+		t.Error("expected breaking changes")
+	}
+	if changes := spec.Diff(to, to); len(changes) > 0 {
+		t.Errorf("expected no changes, got %v", changes)
+	}
+}
+
+func TestRules(t *testing.T) {
+	dir := t.TempDir()
+
+	diags, err := spec.Check(writeFile(t, dir, "invalid.yml", `
+version: "1"
+package: travel
+messages:
+  Stay:
+    fields:
+      - name: check_in
+        type: datetime
+      - name: check_out
+        type: datetime
+      - name: nights
+        type: int
+      - name: tags
+        type: string
+        repeated: true
+      - name: paid
+        type: bool
+    rules:
+      - check_out > check_in
+      - nights >= 1
+      - nights > check_in
+      - tags != "x"
+      - paid > false
+      - missing == 1
+      - nights >>= 2
+`))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	var got []string
+	for _, d := range diags {
+		if d.Severity == spec.SeverityError {
+			got = append(got, d.Message)
+		}
+	}
+
+	want := []string{
+		`message "Stay": rule "nights > check_in" compares a number with a string`,
+		`message "Stay": rule "tags != \"x\"": field "tags" is repeated`,
+		`message "Stay": rule "paid > false": booleans can only be compared with == and !=`,
+		`message "Stay": rule "missing == 1": no field "missing"`,
+		`message "Stay": rule "nights >>= 2": invalid operand ">= 2"`,
+	}
+	if strings.Join(got, "\n") != strings.Join(want, "\n") {
+		t.Errorf("unexpected errors:\n%s", strings.Join(got, "\n"))
+	}
+
+	s, err := spec.LoadSpec(writeFile(t, dir, "trip.yml", `
+version: "1"
+package: travel
+messages:
+  Stay:
+    fields:
+      - name: check_in
+        type: string
+      - name: check_out
+        type: string
+    rules:
+      - check_out > check_in
+  Trip:
+    fields:
+      - name: start
+        type: string
+      - name: end
+        type: string
+      - name: stays
+        type: Stay
+        repeated: true
+    rules:
+      - end >= start
+`))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	got = nil
+	for _, rule := range s.Rules("Trip") {
+		got = append(got, fmt.Sprintf("%s @ %q", rule.Expr, rule.Scope))
+	}
+
+	want = []string{`end >= start @ ""`, `check_out > check_in @ "stays"`}
+	if strings.Join(got, "\n") != strings.Join(want, "\n") {
+		t.Errorf("unexpected rules:\n%s", strings.Join(got, "\n"))
+	}
+}
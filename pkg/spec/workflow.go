@@ -0,0 +1,286 @@
+// Copyright (c) 2025 Suricata Contributors
+// Original Author: Stefano Scafiti
+//
+// This file is part of Suricata: Type-Safe AI Agents for Go.
+//
+// Licensed under the MIT License. You may obtain a copy of the License at
+//
+//	https://opensource.org/licenses/MIT
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package spec
+
+import (
+	"fmt"
+	"regexp"
+	"strconv"
+	"strings"
+)
+
+// WorkflowInput is the root of expressions referring to the input of a workflow.
+const WorkflowInput = "input"
+
+// Workflow chains agent actions, mapping the input of the workflow and the outputs
+// of earlier steps to the inputs of later ones.
+type Workflow struct {
+	Description string `yaml:"description,omitempty"`
+	Input       string `yaml:"input"`
+	Output      string `yaml:"output,omitempty"` // Required when Result is set. Otherwise, the output of the last step is returned.
+	Steps       []Step `yaml:"steps"`
+	// Result maps the fields of Output to expressions. When omitted, the workflow returns the output of its last step.
+	Result map[string]string `yaml:"result,omitempty"`
+}
+
+// Step runs an action of an agent, as part of a workflow.
+type Step struct {
+	Name   string `yaml:"name"`
+	Agent  string `yaml:"agent"`
+	Action string `yaml:"action"`
+	// From is an expression providing the whole input of the action. It cannot be combined with Input.
+	// When both are omitted, the action receives the input of the workflow.
+	From string `yaml:"from,omitempty"`
+	// Input maps the fields of the input of the action to expressions.
+	// An expression is either "input" or the name of an earlier step, optionally
+	// followed by a dotted path of fields, e.g. "itinerary.destination.city".
+	Input map[string]string `yaml:"input,omitempty"`
+	// Optional steps do not stop the workflow on failure: their output is left empty.
+	Optional bool `yaml:"optional,omitempty"`
+}
+
+// Source returns the expression providing the whole input of the step, if any.
+func (step *Step) Source() string {
+	if step.From == "" && len(step.Input) == 0 {
+		return WorkflowInput
+	}
+	return step.From
+}
+
+// Expr is a resolved workflow expression.
+type Expr struct {
+	Root   string  // WorkflowInput or the name of a step
+	Fields []Field // Fields selected from the root, in order. Empty when the whole root is selected.
+
+	// Type of the selected value
+	Type     string
+	Repeated bool
+	Optional bool
+}
+
+// StepAction returns the action run by a step.
+func (spec *Spec) StepAction(step *Step) *Actions {
+	action := spec.Agents[step.Agent].Actions[step.Action]
+	return &action
+}
+
+// WorkflowOutput returns the output type of a workflow, which is either a message or TextOutput.
+func (spec *Spec) WorkflowOutput(wf *Workflow) string {
+	if wf.Result != nil || len(wf.Steps) == 0 {
+		return wf.Output
+	}
+
+	action := spec.StepAction(&wf.Steps[len(wf.Steps)-1])
+	if action.IsTextOutput() {
+		return TextOutput
+	}
+	return action.Output
+}
+
+// ResolveExpr resolves an expression evaluated before the step with the given index runs.
+// Use len(wf.Steps) for expressions of the result.
+func (spec *Spec) ResolveExpr(wf *Workflow, stepIdx int, expr string) (*Expr, error) {
+	parts := strings.Split(expr, ".")
+
+	res := &Expr{Root: parts[0]}
+	if res.Root == WorkflowInput {
+		res.Type = wf.Input
+	} else {
+		idx := wf.stepIndex(res.Root)
+		if idx < 0 || idx >= stepIdx {
+			return nil, fmt.Errorf("expression %q: %q is neither %q nor an earlier step", expr, res.Root, WorkflowInput)
+		}
+
+		res.Type = spec.StepAction(&wf.Steps[idx]).Output
+		if spec.StepAction(&wf.Steps[idx]).IsTextOutput() {
+			res.Type = "string"
+		}
+	}
+
+	for _, name := range parts[1:] {
+		msg, ok := spec.Messages[res.Type]
+		if !ok || msg.IsUnion() || res.Repeated {
+			return nil, fmt.Errorf("expression %q: cannot select %q from a value of type %q", expr, name, res.Type)
+		}
+
+		field, ok := msg.field(name)
+		if !ok {
+			return nil, fmt.Errorf("expression %q: message %q has no field %q", expr, res.Type, name)
+		}
+
+		res.Fields = append(res.Fields, field)
+		res.Type, res.Repeated, res.Optional = field.Type, field.Repeated, field.Optional
+	}
+	return res, nil
+}
+
+var stepNameRegexp = regexp.MustCompile(`^[A-Za-z_][A-Za-z0-9_]*$`)
+
+func (wf *Workflow) stepIndex(name string) int {
+	for i := range wf.Steps {
+		if wf.Steps[i].Name == name {
+			return i
+		}
+	}
+	return -1
+}
+
+func (msg *Message) field(name string) (Field, bool) {
+	for _, f := range msg.Fields {
+		if f.Name == name {
+			return f, true
+		}
+	}
+	return Field{}, false
+}
+
+func (spec *Spec) validateWorkflows(c *checker) {
+	for _, name := range sortedKeys(spec.Workflows) {
+		wf := spec.Workflows[name]
+		path := []string{"workflows", name}
+
+		if _, ok := spec.Messages[wf.Input]; !ok {
+			c.errorf(append(path, "input"), "workflow %q input references undefined message %q", name, wf.Input)
+			continue
+		}
+		if len(wf.Steps) == 0 {
+			c.errorf(path, "workflow %q has no steps", name)
+			continue
+		}
+
+		valid := true
+		for i := range wf.Steps {
+			valid = spec.validateStep(c, name, &wf, i) && valid
+		}
+
+		// Expressions of the result can only be checked once all steps are known to be valid
+		if valid {
+			spec.validateWorkflowOutput(c, name, &wf)
+		}
+	}
+}
+
+func (spec *Spec) validateStep(c *checker, wfName string, wf *Workflow, idx int) bool {
+	step := &wf.Steps[idx]
+	path := []string{"workflows", wfName, "steps", strconv.Itoa(idx)}
+
+	switch {
+	case step.Name == "":
+		c.errorf(path, "workflow %q step #%d has empty name", wfName, idx+1)
+		return false
+	case !stepNameRegexp.MatchString(step.Name):
+		c.errorf(append(path, "name"), "workflow %q step name %q is not a valid identifier", wfName, step.Name)
+		return false
+	case step.Name == WorkflowInput:
+		c.errorf(append(path, "name"), "workflow %q step name %q is reserved", wfName, step.Name)
+		return false
+	case wf.stepIndex(step.Name) != idx:
+		c.errorf(append(path, "name"), "workflow %q has duplicate step %q", wfName, step.Name)
+		return false
+	}
+
+	agent, ok := spec.Agents[step.Agent]
+	if !ok {
+		c.errorf(append(path, "agent"), "workflow %q step %q references undefined agent %q", wfName, step.Name, step.Agent)
+		return false
+	}
+
+	action, ok := agent.Actions[step.Action]
+	if !ok {
+		c.errorf(append(path, "action"), "workflow %q step %q references undefined action %q of agent %q", wfName, step.Name, step.Action, step.Agent)
+		return false
+	}
+
+	in, ok := spec.Messages[action.Input]
+	if !ok {
+		c.errorf(append(path, "action"), "workflow %q step %q: action %q has no input message", wfName, step.Name, step.Action)
+		return false
+	}
+
+	if step.From != "" && len(step.Input) > 0 {
+		c.errorf(append(path, "from"), "workflow %q step %q cannot set both from and input", wfName, step.Name)
+		return false
+	}
+
+	if src := step.Source(); src != "" {
+		return spec.checkMapping(c, append(path, "from"), wf, idx, src, Field{Type: action.Input})
+	}
+
+	valid := true
+	for _, fieldName := range sortedKeys(step.Input) {
+		field, ok := in.field(fieldName)
+		if !ok {
+			c.errorf(append(path, "input", fieldName), "workflow %q step %q: message %q has no field %q", wfName, step.Name, action.Input, fieldName)
+			valid = false
+			continue
+		}
+		valid = spec.checkMapping(c, append(path, "input", fieldName), wf, idx, step.Input[fieldName], field) && valid
+	}
+	return valid
+}
+
+func (spec *Spec) validateWorkflowOutput(c *checker, wfName string, wf *Workflow) {
+	path := []string{"workflows", wfName}
+
+	if wf.Result == nil {
+		last := &wf.Steps[len(wf.Steps)-1]
+		if last.Optional {
+			c.errorf(append(path, "steps", strconv.Itoa(len(wf.Steps)-1)), "workflow %q: the last step cannot be optional when no result is set", wfName)
+		}
+
+		if out := spec.WorkflowOutput(wf); wf.Output != "" && wf.Output != out {
+			c.errorf(append(path, "output"), "workflow %q output %q does not match the output %q of its last step", wfName, wf.Output, out)
+		}
+		return
+	}
+
+	out, ok := spec.Messages[wf.Output]
+	if !ok || out.IsUnion() {
+		c.errorf(append(path, "output"), "workflow %q output references undefined message %q", wfName, wf.Output)
+		return
+	}
+
+	for _, fieldName := range sortedKeys(wf.Result) {
+		field, ok := out.field(fieldName)
+		if !ok {
+			c.errorf(append(path, "result", fieldName), "workflow %q: message %q has no field %q", wfName, wf.Output, fieldName)
+			continue
+		}
+		spec.checkMapping(c, append(path, "result", fieldName), wf, len(wf.Steps), wf.Result[fieldName], field)
+	}
+}
+
+// checkMapping checks that expr, evaluated before the step with the given index, can be assigned to target.
+func (spec *Spec) checkMapping(c *checker, path []string, wf *Workflow, stepIdx int, expr string, target Field) bool {
+	res, err := spec.ResolveExpr(wf, stepIdx, expr)
+	if err != nil {
+		c.errorf(path, "%v", err)
+		return false
+	}
+
+	if res.Type != target.Type || res.Repeated != target.Repeated {
+		c.errorf(path, "expression %q has type %s, expected %s", expr, describeType(res.Type, res.Repeated), describeType(target.Type, target.Repeated))
+		return false
+	}
+	return true
+}
+
+func describeType(t string, repeated bool) string {
+	if repeated {
+		return "repeated " + t
+	}
+	return t
+}
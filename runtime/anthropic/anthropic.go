@@ -16,12 +16,14 @@
 package anthropic
 
 import (
+	"bufio"
 	"bytes"
 	"context"
 	"encoding/json"
 	"fmt"
 	"io"
 	"net/http"
+	"strings"
 
 	"github.com/ostafen/suricata/runtime"
 )
@@ -44,8 +46,53 @@ const (
 
 // Message represents a single message in the conversation
 type Message struct {
-	Role    string `json:"role"`
-	Content string `json:"content"`
+	Role    string         `json:"role"`
+	Content []ContentBlock `json:"content"`
+}
+
+// Types of content blocks.
+const (
+	BlockText       = "text"
+	BlockImage      = "image"
+	BlockToolUse    = "tool_use"
+	BlockToolResult = "tool_result"
+)
+
+// ContentBlock is a part of the content of a message: text, an image, a tool call
+// requested by the model, or the result of a tool call.
+type ContentBlock struct {
+	Type   string       `json:"type"`
+	Text   string       `json:"text,omitempty"`
+	Source *ImageSource `json:"source,omitempty"`
+
+	ID    string          `json:"id,omitempty"`    // Identifier of a tool_use block
+	Name  string          `json:"name,omitempty"`  // Tool called by a tool_use block
+	Input json.RawMessage `json:"input,omitempty"` // Arguments of a tool_use block
+
+	ToolUseID string `json:"tool_use_id,omitempty"` // Call answered by a tool_result block
+	Content   string `json:"content,omitempty"`     // Output of a tool_result block
+
+	CacheControl *CacheControl `json:"cache_control,omitempty"`
+}
+
+// ImageSource holds the data of an image block.
+type ImageSource struct {
+	Type      string `json:"type"` // Always "base64"
+	MediaType string `json:"media_type"`
+	Data      []byte `json:"data"`
+}
+
+// CacheControl marks the end of a prompt prefix to be cached.
+type CacheControl struct {
+	Type string `json:"type"` // Always "ephemeral"
+}
+
+// Tool declares a tool the model can call.
+type Tool struct {
+	Name         string          `json:"name"`
+	Description  string          `json:"description,omitempty"`
+	InputSchema  json.RawMessage `json:"input_schema"`
+	CacheControl *CacheControl   `json:"cache_control,omitempty"`
 }
 
 // AnthropicInvoker is the client for Anthropic API
@@ -53,6 +100,14 @@ type AnthropicInvoker struct {
 	APIKey    string
 	Model     Model
 	MaxTokens int
+	BaseURL   string // Messages endpoint. Empty means AnthropicBaseURL.
+
+	HTTPClient *http.Client // Client sending the requests. Nil means runtime.DefaultHTTPClient.
+
+	// PromptCaching marks the system prompt, the tool definitions and the first user
+	// message, which holds the static sections of the prompt, as cacheable, so that
+	// the following calls of an agent loop are billed at the cached-input rate.
+	PromptCaching bool
 }
 
 // NewInvoker creates a new invoker instance
@@ -66,85 +121,402 @@ func NewInvoker(apiKey string, model Model, maxTokens int) *AnthropicInvoker {
 
 // anthropicRequest represents the request payload
 type anthropicRequest struct {
-	Model     string    `json:"model"`
-	MaxTokens int       `json:"max_tokens"`
-	Messages  []Message `json:"messages"`
+	Model       string         `json:"model"`
+	MaxTokens   int            `json:"max_tokens"`
+	Temperature *float64       `json:"temperature,omitempty"`
+	System      []ContentBlock `json:"system,omitempty"`
+	Messages    []Message      `json:"messages"`
+	Tools       []Tool         `json:"tools,omitempty"`
+	Stream      bool           `json:"stream,omitempty"`
 }
 
 // anthropicResponse represents the response from Anthropic API
 type anthropicResponse struct {
-	Content []struct {
-		Type string `json:"type"`
-		Text string `json:"text"`
-	} `json:"content"`
+	Content []ContentBlock `json:"content"`
 }
 
-// Invoke sends a set of messages and returns the assistant response
+// Invoke sends a set of messages and returns the assistant response. When the runtime
+// exposes tools (see runtime.ToolSpecsFromContext), they are declared to the model, tool
+// calls and results are exchanged as native tool_use and tool_result blocks, and the tool
+// calls of the response are returned as the JSON array of calls the runtime expects.
 func (a *AnthropicInvoker) Invoke(ctx context.Context, system string, messages []runtime.Message) (string, error) {
+	req, err := a.newRequest(ctx, system, messages, false)
+	if err != nil {
+		return "", err
+	}
+
+	var anthropicResp anthropicResponse
+	if err := a.do(req, &anthropicResp); err != nil {
+		return "", err
+	}
+	return responseText(anthropicResp.Content)
+}
+
+// InvokeStream implements runtime.StreamingInvoker on top of the streaming Messages API.
+// Only text is delivered as deltas: tool calls are returned once the response is complete.
+func (a *AnthropicInvoker) InvokeStream(ctx context.Context, system string, messages []runtime.Message, onDelta func(delta string)) (string, error) {
+	req, err := a.newRequest(ctx, system, messages, true)
+	if err != nil {
+		return "", err
+	}
+
+	resp, err := a.send(req)
+	if err != nil {
+		return "", err
+	}
+	defer resp.Body.Close()
+
+	content, err := readStream(resp.Body, onDelta)
+	if err != nil {
+		return "", err
+	}
+	return responseText(content)
+}
+
+// newRequest builds the request of a call of the Messages API.
+func (a *AnthropicInvoker) newRequest(ctx context.Context, system string, messages []runtime.Message, stream bool) (*http.Request, error) {
+	opts := runtime.ModelOptionsFromContext(ctx)
+	tools := toAnthropicTools(runtime.ToolSpecsFromContext(ctx))
+	system, msgs := toAnthropicMessages(system, messages, len(tools) > 0)
+
 	reqBody := anthropicRequest{
-		Model:     string(a.Model),
-		MaxTokens: a.MaxTokens,
-		Messages:  toAnthropicMessages(messages),
+		Model:       opts.ModelOr(string(a.Model)),
+		MaxTokens:   opts.MaxTokensOr(a.MaxTokens),
+		Temperature: opts.Temperature,
+		Messages:    msgs,
+		Tools:       tools,
+		Stream:      stream,
+	}
+	if system != "" {
+		reqBody.System = []ContentBlock{{Type: BlockText, Text: system}}
+	}
+	if a.PromptCaching {
+		setCacheControl(&reqBody)
 	}
 
 	data, err := json.Marshal(reqBody)
 	if err != nil {
-		return "", fmt.Errorf("failed to marshal request: %w", err)
+		return nil, fmt.Errorf("failed to marshal request: %w", err)
 	}
 
-	req, err := http.NewRequestWithContext(ctx, http.MethodPost, AnthropicBaseURL, bytes.NewBuffer(data))
+	url := a.BaseURL
+	if url == "" {
+		url = AnthropicBaseURL
+	}
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, url, bytes.NewBuffer(data))
 	if err != nil {
-		return "", fmt.Errorf("failed to create request: %w", err)
+		return nil, fmt.Errorf("failed to create request: %w", err)
 	}
 
-	req.Header.Set("x-api-key", a.APIKey)
 	req.Header.Set("content-type", "application/json")
-	req.Header.Set("anthropic-version", AnthropicVersion)
+	return req, nil
+}
 
-	resp, err := http.DefaultClient.Do(req)
+// ListModels implements runtime.HealthChecker, returning the models available through the API.
+func (a *AnthropicInvoker) ListModels(ctx context.Context) ([]string, error) {
+	base := a.BaseURL
+	if base == "" {
+		base = AnthropicBaseURL
+	}
+	base = strings.TrimSuffix(base, "/messages") + "/models?limit=1000"
+
+	var models []string
+	for url := base; ; {
+		req, err := http.NewRequestWithContext(ctx, http.MethodGet, url, nil)
+		if err != nil {
+			return nil, fmt.Errorf("failed to create request: %w", err)
+		}
+
+		var page struct {
+			Data []struct {
+				ID string `json:"id"`
+			} `json:"data"`
+			HasMore bool   `json:"has_more"`
+			LastID  string `json:"last_id"`
+		}
+		if err := a.do(req, &page); err != nil {
+			return nil, err
+		}
+
+		for _, m := range page.Data {
+			models = append(models, m.ID)
+		}
+		if !page.HasMore || page.LastID == "" {
+			return models, nil
+		}
+		url = base + "&after_id=" + page.LastID
+	}
+}
+
+// Ping implements runtime.HealthChecker.
+func (a *AnthropicInvoker) Ping(ctx context.Context) error {
+	models, err := a.ListModels(ctx)
 	if err != nil {
-		return "", fmt.Errorf("request failed: %w", err)
+		return err
+	}
+	return runtime.CheckModel(string(a.Model), models)
+}
+
+// do sends req and decodes the JSON response into v.
+func (a *AnthropicInvoker) do(req *http.Request, v any) error {
+	resp, err := a.send(req)
+	if err != nil {
+		return err
 	}
 	defer resp.Body.Close()
 
+	if err := json.NewDecoder(resp.Body).Decode(v); err != nil {
+		return fmt.Errorf("failed to decode response: %w", err)
+	}
+	return nil
+}
+
+// send sends req, returning the response if successful.
+func (a *AnthropicInvoker) send(req *http.Request) (*http.Response, error) {
+	req.Header.Set("x-api-key", a.APIKey)
+	req.Header.Set("anthropic-version", AnthropicVersion)
+
+	client := a.HTTPClient
+	if client == nil {
+		client = runtime.DefaultHTTPClient
+	}
+
+	resp, err := client.Do(req)
+	if err != nil {
+		return nil, fmt.Errorf("request failed: %w", err)
+	}
+
 	if resp.StatusCode != http.StatusOK {
-		body, _ := io.ReadAll(resp.Body)
-		return "", fmt.Errorf("non-200 status: %d, body: %s", resp.StatusCode, body)
+		defer resp.Body.Close()
+		return nil, runtime.NewProviderError("anthropic", resp)
 	}
+	return resp, nil
+}
 
-	var anthropicResp anthropicResponse
-	if err := json.NewDecoder(resp.Body).Decode(&anthropicResp); err != nil {
-		return "", fmt.Errorf("failed to decode response: %w", err)
+// streamEvent is a server-sent event of the streaming Messages API.
+type streamEvent struct {
+	Type         string        `json:"type"`
+	Index        int           `json:"index"`
+	ContentBlock *ContentBlock `json:"content_block"`
+	Delta        struct {
+		Type        string `json:"type"`
+		Text        string `json:"text"`
+		PartialJSON string `json:"partial_json"`
+	} `json:"delta"`
+	Error struct {
+		Type    string `json:"type"`
+		Message string `json:"message"`
+	} `json:"error"`
+}
+
+// readStream reads the events of a streaming response, calling onDelta with each chunk
+// of text, and returns the content of the whole response.
+func readStream(r io.Reader, onDelta func(delta string)) ([]ContentBlock, error) {
+	var (
+		content []ContentBlock
+		inputs  []string // Partial JSON arguments of tool_use blocks
+	)
+
+	scanner := bufio.NewScanner(r)
+	scanner.Buffer(nil, 1<<20)
+	for scanner.Scan() {
+		data, ok := strings.CutPrefix(scanner.Text(), "data:")
+		if !ok {
+			continue
+		}
+
+		var ev streamEvent
+		if err := json.Unmarshal([]byte(data), &ev); err != nil {
+			return nil, fmt.Errorf("failed to decode event: %w", err)
+		}
+
+		switch ev.Type {
+		case "content_block_start":
+			if ev.ContentBlock == nil || ev.Index != len(content) {
+				return nil, fmt.Errorf("unexpected start of content block %d", ev.Index)
+			}
+			content = append(content, *ev.ContentBlock)
+			inputs = append(inputs, "")
+		case "content_block_delta":
+			if ev.Index < 0 || ev.Index >= len(content) {
+				return nil, fmt.Errorf("delta of unknown content block %d", ev.Index)
+			}
+
+			switch ev.Delta.Type {
+			case "text_delta":
+				content[ev.Index].Text += ev.Delta.Text
+				onDelta(ev.Delta.Text)
+			case "input_json_delta":
+				inputs[ev.Index] += ev.Delta.PartialJSON
+			}
+		case "error":
+			return nil, fmt.Errorf("stream failed: %s: %s", ev.Error.Type, ev.Error.Message)
+		case "message_stop":
+			for i, in := range inputs {
+				if in != "" {
+					content[i].Input = json.RawMessage(in)
+				}
+			}
+			return content, nil
+		}
+	}
+
+	if err := scanner.Err(); err != nil {
+		return nil, fmt.Errorf("failed to read stream: %w", err)
+	}
+	return nil, fmt.Errorf("failed to read stream: %w", io.ErrUnexpectedEOF)
+}
+
+// responseText returns the text of the response, or the JSON array of its tool calls, if any.
+func responseText(content []ContentBlock) (string, error) {
+	type toolCall struct {
+		Name string          `json:"name"`
+		Args json.RawMessage `json:"args"`
+	}
+
+	var (
+		text  strings.Builder
+		calls []toolCall
+	)
+	for _, c := range content {
+		switch c.Type {
+		case BlockText:
+			text.WriteString(c.Text)
+		case BlockToolUse:
+			calls = append(calls, toolCall{Name: c.Name, Args: c.Input})
+		}
 	}
 
-	// Combine all text parts
-	var result string
-	for _, c := range anthropicResp.Content {
-		if c.Type == "text" {
-			result += c.Text
+	if len(calls) == 0 {
+		return text.String(), nil
+	}
+
+	data, err := json.Marshal(calls)
+	if err != nil {
+		return "", fmt.Errorf("failed to encode tool calls: %w", err)
+	}
+	return string(data), nil
+}
+
+func toAnthropicTools(specs []runtime.ToolSpec) []Tool {
+	tools := make([]Tool, 0, len(specs))
+	for _, spec := range specs {
+		schema := json.RawMessage(`{"type":"object"}`)
+		if spec.Schema != nil {
+			if v, err := spec.Schema.LoadJSON(); err == nil {
+				if data, err := json.Marshal(v); err == nil {
+					schema = data
+				}
+			}
 		}
+		tools = append(tools, Tool{Name: spec.Name, Description: spec.Description, InputSchema: schema})
 	}
-	return result, nil
+	return tools
 }
 
-func toAnthropicMessages(messages []runtime.Message) []Message {
-	out := make([]Message, len(messages))
+// setCacheControl marks the system prompt, the last tool and the first message as
+// the ends of cacheable prefixes.
+func setCacheControl(req *anthropicRequest) {
+	ephemeral := &CacheControl{Type: "ephemeral"}
+
+	if n := len(req.System); n > 0 {
+		req.System[n-1].CacheControl = ephemeral
+	}
+	if n := len(req.Tools); n > 0 {
+		req.Tools[n-1].CacheControl = ephemeral
+	}
+	if len(req.Messages) > 0 {
+		content := req.Messages[0].Content
+		if n := len(content); n > 0 {
+			content[n-1].CacheControl = ephemeral
+		}
+	}
+}
+
+// toAnthropicMessages converts messages, moving system messages to the system prompt,
+// as the API only accepts user and assistant messages. With native tools, the calls
+// answered by tool messages become tool_use blocks of the preceding assistant message,
+// and tool messages become tool_result blocks. Otherwise, or when the call cannot be
+// matched, tool outputs are delivered as text. Consecutive messages of the same role
+// are merged.
+func toAnthropicMessages(system string, messages []runtime.Message, nativeTools bool) (string, []Message) {
+	out := make([]Message, 0, len(messages))
+
+	// Index of the assistant message answered by the tool messages which follow it
+	assistant := -1
+	for _, msg := range messages {
+		if msg.Role == runtime.RoleSystem {
+			system = strings.TrimSpace(system + "\n\n" + msg.Content)
+			continue
+		}
 
-	for i, msg := range messages {
-		out[i] = Message{
-			Role:    getRole(msg.Role),
-			Content: msg.Content,
+		if msg.Role == runtime.RoleTool && msg.ToolCall != nil && nativeTools && assistant >= 0 {
+			out[assistant].Content = append(toolUseBlocks(out[assistant].Content), ContentBlock{
+				Type:  BlockToolUse,
+				ID:    msg.ToolCall.ID,
+				Name:  msg.ToolCall.Name,
+				Input: toolArgs(msg.ToolCall.Args),
+			})
+			out = appendMessage(out, RoleUser, ContentBlock{
+				Type:      BlockToolResult,
+				ToolUseID: msg.ToolCall.ID,
+				Content:   msg.Content,
+			})
+			continue
 		}
+
+		var content []ContentBlock
+		for _, att := range msg.Attachments {
+			if att.IsImage() {
+				content = append(content, ContentBlock{
+					Type:   BlockImage,
+					Source: &ImageSource{Type: "base64", MediaType: att.MIMEType, Data: att.Data},
+				})
+			}
+		}
+		out = appendMessage(out, getRole(msg.Role), append(content, ContentBlock{Type: BlockText, Text: msg.Text()})...)
+
+		assistant = -1
+		if msg.Role == runtime.RoleAgent {
+			assistant = len(out) - 1
+		}
+	}
+	return system, out
+}
+
+// toolUseBlocks returns content without its text blocks, which hold the tool calls
+// in the JSON format of the runtime, once they are replaced by tool_use blocks.
+func toolUseBlocks(content []ContentBlock) []ContentBlock {
+	blocks := content[:0]
+	for _, c := range content {
+		if c.Type == BlockToolUse {
+			blocks = append(blocks, c)
+		}
+	}
+	return blocks
+}
+
+func toolArgs(args json.RawMessage) json.RawMessage {
+	if len(args) == 0 {
+		return json.RawMessage("{}")
+	}
+	return args
+}
+
+// appendMessage appends a message with the given role and content to messages,
+// merging it with the last one if it has the same role.
+func appendMessage(messages []Message, role string, content ...ContentBlock) []Message {
+	if n := len(messages); n > 0 && messages[n-1].Role == role {
+		messages[n-1].Content = append(messages[n-1].Content, content...)
+		return messages
 	}
-	return out
+	return append(messages, Message{Role: role, Content: content})
 }
 
 func getRole(r runtime.Role) string {
-	switch r {
-	case runtime.RoleAgent:
+	if r == runtime.RoleAgent {
 		return RoleAssistant
-	case runtime.RoleUser:
-		return RoleUser
 	}
-	return ""
+	return RoleUser
 }
@@ -0,0 +1,309 @@
+// Copyright (c) 2025 Suricata Contributors
+// Original Author: Stefano Scafiti
+//
+// This file is part of Suricata: Type-Safe AI Agents for Go.
+//
+// Licensed under the MIT License. You may obtain a copy of the License at
+//
+//	https://opensource.org/licenses/MIT
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package anthropic_test
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/ostafen/suricata/runtime"
+	"github.com/ostafen/suricata/runtime/anthropic"
+)
+
+type request struct {
+	System   []anthropic.ContentBlock `json:"system"`
+	Messages []anthropic.Message      `json:"messages"`
+	Tools    []anthropic.Tool         `json:"tools"`
+}
+
+func newServer(t *testing.T, response string, got *request) *httptest.Server {
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if err := json.NewDecoder(r.Body).Decode(got); err != nil {
+			t.Errorf("failed to decode request: %v", err)
+		}
+		w.Write([]byte(response))
+	}))
+	t.Cleanup(srv.Close)
+	return srv
+}
+
+func TestInvoke_ToolUse(t *testing.T) {
+	var got request
+	srv := newServer(t, `{"content":[{"type":"text","text":"Checking."},{"type":"tool_use","id":"toolu_2","name":"weather","input":{"city":"Milan"}}]}`, &got)
+
+	inv := anthropic.NewInvoker("key", anthropic.ClaudeSonnet4, 1024)
+	inv.BaseURL = srv.URL
+	inv.PromptCaching = true
+
+	ctx := runtime.WithToolSpecs(context.Background(), []runtime.ToolSpec{
+		{Name: "weather", Description: "Current weather", Schema: runtime.NewSchema(`{"type":"object","properties":{"city":{"type":"string"}}}`)},
+	})
+
+	out, err := inv.Invoke(ctx, "Be brief.", []runtime.Message{
+		{Role: runtime.RoleUser, Content: "Weather in Rome and Milan?"},
+		{Role: runtime.RoleAgent, Content: `{"name":"weather","args":{"city":"Rome"}}`},
+		{Role: runtime.RoleTool, Content: `"sunny"`, ToolCall: &runtime.ToolCallRef{ID: "toolu_1", Name: "weather", Args: json.RawMessage(`{"city":"Rome"}`)}},
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if out != `[{"name":"weather","args":{"city":"Milan"}}]` {
+		t.Errorf("expected tool calls, got %s", out)
+	}
+
+	if len(got.Tools) != 1 || got.Tools[0].Name != "weather" || got.Tools[0].CacheControl == nil {
+		t.Errorf("unexpected tools: %+v", got.Tools)
+	}
+	if len(got.System) != 1 || got.System[0].Text != "Be brief." || got.System[0].CacheControl == nil {
+		t.Errorf("unexpected system prompt: %+v", got.System)
+	}
+	if len(got.Messages) != 3 {
+		t.Fatalf("expected 3 messages, got %+v", got.Messages)
+	}
+	if c := got.Messages[0].Content; c[len(c)-1].CacheControl == nil {
+		t.Errorf("expected the first message to be cached")
+	}
+
+	use := got.Messages[1].Content
+	if len(use) != 1 || use[0].Type != anthropic.BlockToolUse || use[0].ID != "toolu_1" || string(use[0].Input) != `{"city":"Rome"}` {
+		t.Errorf("unexpected assistant content: %+v", use)
+	}
+	result := got.Messages[2].Content
+	if got.Messages[2].Role != anthropic.RoleUser || len(result) != 1 || result[0].ToolUseID != "toolu_1" || result[0].Content != `"sunny"` {
+		t.Errorf("unexpected tool result: %+v", got.Messages[2])
+	}
+}
+
+func TestInvoke_TextOnly(t *testing.T) {
+	var got request
+	srv := newServer(t, `{"content":[{"type":"text","text":"Hello"}]}`, &got)
+
+	inv := anthropic.NewInvoker("key", anthropic.ClaudeSonnet4, 1024)
+	inv.BaseURL = srv.URL
+
+	out, err := inv.Invoke(context.Background(), "", []runtime.Message{
+		{Role: runtime.RoleUser, Content: "Hi"},
+		{Role: runtime.RoleTool, Content: `"sunny"`, ToolCall: &runtime.ToolCallRef{ID: "toolu_1", Name: "weather"}},
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if out != "Hello" {
+		t.Errorf("expected Hello, got %s", out)
+	}
+
+	// Without tools, tool outputs are delivered as text and merged with the user message
+	if len(got.Tools) != 0 || len(got.System) != 0 || len(got.Messages) != 1 || len(got.Messages[0].Content) != 2 {
+		t.Fatalf("unexpected request: %+v", got)
+	}
+	if got.Messages[0].Content[1].Type != anthropic.BlockText || got.Messages[0].Content[0].CacheControl != nil {
+		t.Errorf("unexpected content: %+v", got.Messages[0].Content)
+	}
+}
+
+func TestInvoke_Roles(t *testing.T) {
+	var got request
+	srv := newServer(t, `{"content":[{"type":"text","text":"Done"}]}`, &got)
+
+	inv := anthropic.NewInvoker("key", anthropic.ClaudeSonnet4, 1024)
+	inv.BaseURL = srv.URL
+
+	_, err := inv.Invoke(context.Background(), "Be brief.", []runtime.Message{
+		{Role: runtime.RoleSystem, Content: "Answer in English."},
+		{Role: runtime.RoleUser, Content: "Weather in Rome?"},
+		{Role: runtime.RoleAgent, Content: `{"name":"weather","args":{"city":"Rome"}}`},
+		{Role: runtime.RoleTool, Content: `"sunny"`, ToolCall: &runtime.ToolCallRef{ID: "toolu_1", Name: "weather"}},
+		{Role: runtime.RoleUser, Content: "Thanks"},
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// System messages join the system prompt, and tool outputs are merged with the following user message
+	if len(got.System) != 1 || got.System[0].Text != "Be brief.\n\nAnswer in English." {
+		t.Errorf("unexpected system prompt: %+v", got.System)
+	}
+
+	roles := []string{anthropic.RoleUser, anthropic.RoleAssistant, anthropic.RoleUser}
+	if len(got.Messages) != len(roles) {
+		t.Fatalf("expected %d messages, got %+v", len(roles), got.Messages)
+	}
+	for i, role := range roles {
+		if got.Messages[i].Role != role {
+			t.Errorf("message %d: expected role %s, got %s", i, role, got.Messages[i].Role)
+		}
+	}
+	if content := got.Messages[2].Content; len(content) != 2 || content[0].Text != `weather OUTPUT: "sunny"` {
+		t.Errorf("unexpected tool output: %+v", content)
+	}
+}
+
+func TestListModels(t *testing.T) {
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path != "/v1/models" || r.Header.Get("x-api-key") != "key" {
+			t.Errorf("unexpected request: %s %v", r.URL, r.Header)
+		}
+
+		if r.URL.Query().Get("after_id") == "" {
+			w.Write([]byte(`{"data":[{"id":"claude-opus-4-20250514"}],"has_more":true,"last_id":"claude-opus-4-20250514"}`))
+			return
+		}
+		w.Write([]byte(`{"data":[{"id":"claude-sonnet-4-20250514"}],"has_more":false}`))
+	}))
+	defer srv.Close()
+
+	inv := anthropic.NewInvoker("key", anthropic.ClaudeSonnet4, 1024)
+	inv.BaseURL = srv.URL + "/v1/messages"
+
+	models, err := inv.ListModels(context.Background())
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(models) != 2 || models[1] != string(anthropic.ClaudeSonnet4) {
+		t.Errorf("unexpected models: %v", models)
+	}
+
+	if err := inv.Ping(context.Background()); err != nil {
+		t.Errorf("unexpected error: %v", err)
+	}
+
+	inv.Model = anthropic.ClaudeSonnet37
+	if err := inv.Ping(context.Background()); !errors.Is(err, runtime.ErrModelNotFound) {
+		t.Errorf("expected ErrModelNotFound, got %v", err)
+	}
+}
+
+func streamServer(t *testing.T, events string, stream *bool) *httptest.Server {
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		var req struct {
+			Stream bool `json:"stream"`
+		}
+		if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+			t.Errorf("failed to decode request: %v", err)
+		}
+		*stream = req.Stream
+
+		w.Header().Set("Content-Type", "text/event-stream")
+		w.Write([]byte(events))
+	}))
+	t.Cleanup(srv.Close)
+	return srv
+}
+
+func TestInvokeStream(t *testing.T) {
+	var stream bool
+	srv := streamServer(t, `event: message_start
+data: {"type":"message_start","message":{"id":"msg_1","content":[]}}
+
+event: content_block_start
+data: {"type":"content_block_start","index":0,"content_block":{"type":"text","text":""}}
+
+event: content_block_delta
+data: {"type":"content_block_delta","index":0,"delta":{"type":"text_delta","text":"Ro"}}
+
+event: ping
+data: {"type":"ping"}
+
+event: content_block_delta
+data: {"type":"content_block_delta","index":0,"delta":{"type":"text_delta","text":"me"}}
+
+event: content_block_stop
+data: {"type":"content_block_stop","index":0}
+
+event: message_stop
+data: {"type":"message_stop"}
+
+`, &stream)
+
+	inv := anthropic.NewInvoker("key", anthropic.ClaudeSonnet4, 1024)
+	inv.BaseURL = srv.URL
+
+	var deltas []string
+	out, err := inv.InvokeStream(context.Background(), "", []runtime.Message{{Role: runtime.RoleUser, Content: "Capital of Italy?"}}, func(delta string) {
+		deltas = append(deltas, delta)
+	})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	if !stream {
+		t.Error("expected a streaming request")
+	}
+	if out != "Rome" {
+		t.Errorf("expected 'Rome', got %q", out)
+	}
+	if len(deltas) != 2 || deltas[0] != "Ro" || deltas[1] != "me" {
+		t.Errorf("unexpected deltas: %q", deltas)
+	}
+}
+
+func TestInvokeStream_ToolUse(t *testing.T) {
+	var stream bool
+	srv := streamServer(t, `event: content_block_start
+data: {"type":"content_block_start","index":0,"content_block":{"type":"tool_use","id":"toolu_1","name":"weather","input":{}}}
+
+event: content_block_delta
+data: {"type":"content_block_delta","index":0,"delta":{"type":"input_json_delta","partial_json":"{\"city\":"}}
+
+event: content_block_delta
+data: {"type":"content_block_delta","index":0,"delta":{"type":"input_json_delta","partial_json":"\"Milan\"}"}}
+
+event: message_stop
+data: {"type":"message_stop"}
+
+`, &stream)
+
+	inv := anthropic.NewInvoker("key", anthropic.ClaudeSonnet4, 1024)
+	inv.BaseURL = srv.URL
+
+	out, err := inv.InvokeStream(context.Background(), "", []runtime.Message{{Role: runtime.RoleUser, Content: "Weather in Milan?"}}, func(delta string) {
+		t.Errorf("unexpected delta: %q", delta)
+	})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if out != `[{"name":"weather","args":{"city":"Milan"}}]` {
+		t.Errorf("unexpected output: %s", out)
+	}
+}
+
+func TestInvokeStream_Error(t *testing.T) {
+	for name, events := range map[string]string{
+		"error event": `event: error
+data: {"type":"error","error":{"type":"overloaded_error","message":"Overloaded"}}
+
+`,
+		"truncated stream": `event: content_block_start
+data: {"type":"content_block_start","index":0,"content_block":{"type":"text","text":""}}
+
+`,
+	} {
+		t.Run(name, func(t *testing.T) {
+			var stream bool
+			inv := anthropic.NewInvoker("key", anthropic.ClaudeSonnet4, 1024)
+			inv.BaseURL = streamServer(t, events, &stream).URL
+
+			_, err := inv.InvokeStream(context.Background(), "", []runtime.Message{{Role: runtime.RoleUser, Content: "Hi"}}, func(string) {})
+			if err == nil {
+				t.Fatal("expected an error")
+			}
+		})
+	}
+}
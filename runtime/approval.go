@@ -0,0 +1,97 @@
+// Copyright (c) 2025 Suricata Contributors
+// Original Author: Stefano Scafiti
+//
+// This file is part of Suricata: Type-Safe AI Agents for Go.
+//
+// Licensed under the MIT License. You may obtain a copy of the License at
+//
+//	https://opensource.org/licenses/MIT
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package runtime
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+)
+
+var (
+	// ErrApprovalDenied is reported to the model when a tool call requiring approval is not approved.
+	ErrApprovalDenied = errors.New("tool call not approved")
+	// ErrApprovalPending can be returned by an ApprovalFunc to pause the run until a decision is taken.
+	// The paused run is saved to the checkpoint store, and continues once resumed with Runtime.Resume.
+	ErrApprovalPending = errors.New("approval pending")
+)
+
+// PendingCall is a tool call awaiting approval. It can be serialized, so that it can be
+// handed over to an asynchronous approval process (a queue, a chat message, a web UI...).
+type PendingCall struct {
+	RequestID string          `json:"request_id,omitempty"`
+	Tool      string          `json:"tool"`
+	Args      json.RawMessage `json:"args"`
+}
+
+// ApprovalFunc decides whether a tool call may run. It may block until an out-of-band
+// confirmation is received. Returning nil approves the call; ErrApprovalPending pauses the run;
+// any other error denies the call, and is reported to the model in place of the tool output.
+type ApprovalFunc func(ctx context.Context, call PendingCall) error
+
+// WithApproval registers the function approving the calls of tools marked with ToolSpec.RequiresApproval.
+// Without it, such calls are always denied.
+func WithApproval(fn ApprovalFunc) Option {
+	return func(r *Runtime) {
+		r.approve = fn
+	}
+}
+
+// approveCall asks for the approval of a tool call.
+func (r *Runtime) approveCall(ctx context.Context, call PendingCall) error {
+	if r.approve == nil {
+		return &ToolError{Tool: call.Tool, Err: fmt.Errorf("%w: approval required", ErrApprovalDenied)}
+	}
+
+	err := r.approve(ctx, call)
+	switch {
+	case err == nil, errors.Is(err, ErrApprovalPending):
+		return err
+	case errors.Is(err, ErrApprovalDenied):
+		return &ToolError{Tool: call.Tool, Err: err}
+	}
+	return &ToolError{Tool: call.Tool, Err: fmt.Errorf("%w: %w", ErrApprovalDenied, err)}
+}
+
+// approveCalls asks for the approval of the calls requiring it, marking the denied ones.
+// The run is paused if any decision is pending.
+func (r *Runtime) approveCalls(ctx context.Context, calls []toolCall, req *Request, st *runState) error {
+	var pending []PendingCall
+	for i, call := range calls {
+		if call.err != nil || !req.requiresApproval(call.name) {
+			continue
+		}
+
+		args, err := json.Marshal(call.in)
+		if err != nil {
+			calls[i].err = fmt.Errorf("marshal tool args: %w", err)
+			continue
+		}
+
+		pc := PendingCall{RequestID: RequestIDFromContext(ctx), Tool: call.name, Args: args}
+		if err := r.approveCall(ctx, pc); errors.Is(err, ErrApprovalPending) {
+			pending = append(pending, pc)
+		} else {
+			calls[i].err = err
+		}
+	}
+
+	if len(pending) > 0 {
+		return r.pause(ctx, st, pending)
+	}
+	return nil
+}
@@ -0,0 +1,94 @@
+// Copyright (c) 2025 Suricata Contributors
+// Original Author: Stefano Scafiti
+//
+// This file is part of Suricata: Type-Safe AI Agents for Go.
+//
+// Licensed under the MIT License. You may obtain a copy of the License at
+//
+//	https://opensource.org/licenses/MIT
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package runtime
+
+import (
+	"encoding/base64"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"strings"
+)
+
+// Attachment is a binary document, such as an image or a PDF file. It is the Go type of
+// spec fields of type image and file, and is encoded in JSON as a data URL
+// (data:<mime type>;base64,<data>). Images are sent to the model along with the prompt,
+// while the text of the other documents is extracted and inlined in the prompt.
+type Attachment struct {
+	MIMEType string
+	Data     []byte
+}
+
+// NewImage returns an attachment holding data, whose MIME type is detected from its content.
+func NewImage(data []byte) Attachment {
+	return Attachment{MIMEType: http.DetectContentType(data), Data: data}
+}
+
+// NewFile reads a document of the given MIME type from r.
+func NewFile(r io.Reader, mimeType string) (Attachment, error) {
+	data, err := io.ReadAll(r)
+	if err != nil {
+		return Attachment{}, err
+	}
+	return Attachment{MIMEType: mimeType, Data: data}, nil
+}
+
+// IsImage reports whether the attachment holds an image.
+func (a Attachment) IsImage() bool {
+	return strings.HasPrefix(a.MIMEType, "image/")
+}
+
+// DataURL returns the attachment encoded as a data URL.
+func (a Attachment) DataURL() string {
+	return "data:" + a.MIMEType + ";base64," + base64.StdEncoding.EncodeToString(a.Data)
+}
+
+func (a Attachment) MarshalJSON() ([]byte, error) {
+	return json.Marshal(a.DataURL())
+}
+
+func (a *Attachment) UnmarshalJSON(data []byte) error {
+	var url string
+	if err := json.Unmarshal(data, &url); err != nil {
+		return err
+	}
+
+	att, ok := parseDataURL(url)
+	if !ok {
+		return fmt.Errorf("invalid attachment: expected a base64 data URL")
+	}
+	*a = att
+	return nil
+}
+
+func parseDataURL(url string) (Attachment, bool) {
+	rest, ok := strings.CutPrefix(url, "data:")
+	if !ok {
+		return Attachment{}, false
+	}
+
+	mimeType, encoded, ok := strings.Cut(rest, ";base64,")
+	if !ok || mimeType == "" {
+		return Attachment{}, false
+	}
+
+	data, err := base64.StdEncoding.DecodeString(encoded)
+	if err != nil {
+		return Attachment{}, false
+	}
+	return Attachment{MIMEType: mimeType, Data: data}, true
+}
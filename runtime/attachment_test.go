@@ -0,0 +1,89 @@
+// Copyright (c) 2025 Suricata Contributors
+// Original Author: Stefano Scafiti
+//
+// This file is part of Suricata: Type-Safe AI Agents for Go.
+//
+// Licensed under the MIT License. You may obtain a copy of the License at
+//
+//	https://opensource.org/licenses/MIT
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package runtime_test
+
+import (
+	"context"
+	"encoding/json"
+	"strings"
+	"testing"
+
+	"github.com/ostafen/suricata/runtime"
+	"github.com/ostafen/suricata/runtime/runtimetest"
+	"github.com/xeipuuv/gojsonschema"
+)
+
+func TestAttachment_JSON(t *testing.T) {
+	image := runtime.Attachment{MIMEType: "image/png", Data: []byte{0x89, 'P', 'N', 'G'}}
+
+	data, err := json.Marshal(image)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if string(data) != `"data:image/png;base64,iVBORw=="` {
+		t.Errorf("unexpected encoding: %s", data)
+	}
+
+	var decoded runtime.Attachment
+	if err := json.Unmarshal(data, &decoded); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if decoded.MIMEType != image.MIMEType || string(decoded.Data) != string(image.Data) {
+		t.Errorf("unexpected decoded attachment: %+v", decoded)
+	}
+
+	if err := json.Unmarshal([]byte(`"iVBORw=="`), &decoded); err == nil {
+		t.Error("expected error for a value which is not a data URL")
+	}
+}
+
+func TestInvoke_ImageInput(t *testing.T) {
+	type Input struct {
+		Question string               `json:"question"`
+		Photos   []runtime.Attachment `json:"photos"`
+	}
+
+	mock := runtimetest.NewInvoker(t)
+	mock.Expect().PromptContains(`"photos":["[image 1]","[image 2]"]`).Respond("Two cats")
+
+	in := Input{
+		Question: "What is in the photos?",
+		Photos: []runtime.Attachment{
+			{MIMEType: "image/png", Data: []byte("first")},
+			{MIMEType: "image/jpeg", Data: []byte("second")},
+		},
+	}
+
+	var out string
+	err := runtime.NewRuntime(mock).Invoke(context.Background(), runtime.Request{
+		PromptTemplate: "Answer the question.",
+		Input:          &in,
+		Output:         &out,
+		InputSchema:    gojsonschema.NewStringLoader(`{"type":"object"}`),
+	})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	mock.AssertExpectations()
+
+	msg := mock.LastCall().Messages[0]
+	if strings.Contains(msg.Content, "base64") {
+		t.Errorf("expected images to be left out of the prompt, got: %s", msg.Content)
+	}
+	if len(msg.Attachments) != 2 || msg.Attachments[1].MIMEType != "image/jpeg" || string(msg.Attachments[1].Data) != "second" {
+		t.Errorf("unexpected attachments: %+v", msg.Attachments)
+	}
+}
@@ -0,0 +1,131 @@
+// Copyright (c) 2025 Suricata Contributors
+// Original Author: Stefano Scafiti
+//
+// This file is part of Suricata: Type-Safe AI Agents for Go.
+//
+// Licensed under the MIT License. You may obtain a copy of the License at
+//
+//	https://opensource.org/licenses/MIT
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package runtime
+
+import (
+	"context"
+	"errors"
+	"io"
+	"math/rand/v2"
+	"net"
+	"net/http"
+	"time"
+)
+
+// BackoffPolicy controls how HTTP clients built by NewHTTPClient retry requests failing
+// with transient errors: HTTP 408, 429 and 5xx responses, and network timeouts. Unlike
+// RetryPolicy, which asks the model to correct an invalid output, it resends the same request.
+type BackoffPolicy struct {
+	MaxAttempts  int           // Total number of attempts, including the first one. Values <= 1 disable retries.
+	InitialDelay time.Duration // Delay before the first retry, doubled at each following one
+	MaxDelay     time.Duration // Upper bound on a single delay, including the Retry-After ones. Zero means no bound.
+}
+
+// DefaultBackoffPolicy returns a policy making up to 3 attempts, suitable for most providers.
+func DefaultBackoffPolicy() BackoffPolicy {
+	return BackoffPolicy{
+		MaxAttempts:  3,
+		InitialDelay: 500 * time.Millisecond,
+		MaxDelay:     30 * time.Second,
+	}
+}
+
+// delay returns the jittered delay before the given retry, starting from zero.
+// A positive retryAfter, requested by the server, replaces the exponential delay.
+func (p BackoffPolicy) delay(retry int, retryAfter time.Duration) time.Duration {
+	d := retryAfter
+	if d <= 0 {
+		d = p.InitialDelay << retry
+		if d > 0 {
+			// Spread the retries of concurrent callers over [d/2, d)
+			d = d/2 + rand.N(d/2+1)
+		}
+	}
+
+	if p.MaxDelay > 0 {
+		d = min(d, p.MaxDelay)
+	}
+	return d
+}
+
+// retryTransport resends the requests failing with transient errors according to policy.
+type retryTransport struct {
+	next   http.RoundTripper
+	policy BackoffPolicy
+}
+
+func (t *retryTransport) RoundTrip(req *http.Request) (*http.Response, error) {
+	for attempt := 1; ; attempt++ {
+		resp, err := t.next.RoundTrip(req)
+		if attempt >= t.policy.MaxAttempts || !transient(resp, err) || !rewindable(req) {
+			return resp, err
+		}
+
+		var retryAfter time.Duration
+		if resp != nil {
+			retryAfter = ParseRetryAfter(resp.Header.Get("Retry-After"))
+
+			// Drain the body, so that the connection can be reused
+			_, _ = io.Copy(io.Discard, io.LimitReader(resp.Body, MaxErrorBodySize))
+			resp.Body.Close()
+		}
+
+		if err := sleep(req.Context(), t.policy.delay(attempt-1, retryAfter)); err != nil {
+			return nil, err
+		}
+
+		if req.GetBody != nil {
+			body, err := req.GetBody()
+			if err != nil {
+				return nil, err
+			}
+
+			req = req.Clone(req.Context())
+			req.Body = body
+		}
+	}
+}
+
+// transient reports whether the outcome of a request is worth retrying.
+func transient(resp *http.Response, err error) bool {
+	if err != nil {
+		var netErr net.Error
+		return errors.As(err, &netErr) && netErr.Timeout()
+	}
+	return resp.StatusCode == http.StatusTooManyRequests || resp.StatusCode == http.StatusRequestTimeout || resp.StatusCode >= 500
+}
+
+// rewindable reports whether the body of req can be sent again.
+func rewindable(req *http.Request) bool {
+	return req.Body == nil || req.Body == http.NoBody || req.GetBody != nil
+}
+
+// sleep waits for d, or until ctx is done.
+func sleep(ctx context.Context, d time.Duration) error {
+	if d <= 0 {
+		return ctx.Err()
+	}
+
+	t := time.NewTimer(d)
+	defer t.Stop()
+
+	select {
+	case <-ctx.Done():
+		return ctx.Err()
+	case <-t.C:
+		return nil
+	}
+}
@@ -0,0 +1,175 @@
+// Copyright (c) 2025 Suricata Contributors
+// Original Author: Stefano Scafiti
+//
+// This file is part of Suricata: Type-Safe AI Agents for Go.
+//
+// Licensed under the MIT License. You may obtain a copy of the License at
+//
+//	https://opensource.org/licenses/MIT
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package runtime_test
+
+import (
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/ostafen/suricata/runtime"
+)
+
+func TestNewHTTPClient_Retry(t *testing.T) {
+	policy := runtime.BackoffPolicy{MaxAttempts: 3, InitialDelay: time.Millisecond}
+
+	newServer := func(statuses ...int) (*httptest.Server, *[]string) {
+		var bodies []string
+		srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			body, _ := io.ReadAll(r.Body)
+			bodies = append(bodies, string(body))
+
+			status := statuses[min(len(bodies), len(statuses))-1]
+			if status == http.StatusTooManyRequests {
+				w.Header().Set("Retry-After", "0")
+			}
+			w.WriteHeader(status)
+		}))
+		t.Cleanup(srv.Close)
+		return srv, &bodies
+	}
+
+	post := func(t *testing.T, client *http.Client, url string) int {
+		resp, err := client.Post(url, "application/json", strings.NewReader(`{"model":"m"}`))
+		if err != nil {
+			t.Fatal(err)
+		}
+		resp.Body.Close()
+		return resp.StatusCode
+	}
+
+	t.Run("transient errors are retried", func(t *testing.T) {
+		srv, bodies := newServer(http.StatusServiceUnavailable, http.StatusTooManyRequests, http.StatusOK)
+
+		client, err := runtime.NewHTTPClient(runtime.HTTPConfig{Retry: policy})
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		if status := post(t, client, srv.URL); status != http.StatusOK {
+			t.Fatalf("expected status 200, got %d", status)
+		}
+		if len(*bodies) != 3 || (*bodies)[2] != `{"model":"m"}` {
+			t.Errorf("expected the body to be sent 3 times, got %q", *bodies)
+		}
+	})
+
+	t.Run("attempts are bounded", func(t *testing.T) {
+		srv, bodies := newServer(http.StatusBadGateway)
+
+		client, err := runtime.NewHTTPClient(runtime.HTTPConfig{Retry: policy})
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		if status := post(t, client, srv.URL); status != http.StatusBadGateway {
+			t.Fatalf("expected status 502, got %d", status)
+		}
+		if len(*bodies) != 3 {
+			t.Errorf("expected 3 attempts, got %d", len(*bodies))
+		}
+	})
+
+	t.Run("client errors are not retried", func(t *testing.T) {
+		srv, bodies := newServer(http.StatusBadRequest)
+
+		client, err := runtime.NewHTTPClient(runtime.HTTPConfig{Retry: policy})
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		if status := post(t, client, srv.URL); status != http.StatusBadRequest || len(*bodies) != 1 {
+			t.Errorf("expected a single attempt, got status %d after %d attempts", status, len(*bodies))
+		}
+	})
+
+	t.Run("retries can be disabled", func(t *testing.T) {
+		srv, bodies := newServer(http.StatusServiceUnavailable)
+
+		client, err := runtime.NewHTTPClient(runtime.HTTPConfig{Retry: runtime.BackoffPolicy{MaxAttempts: 1}})
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		if post(t, client, srv.URL); len(*bodies) != 1 {
+			t.Errorf("expected a single attempt, got %d", len(*bodies))
+		}
+	})
+
+	t.Run("retries are opt-in", func(t *testing.T) {
+		srv, bodies := newServer(http.StatusServiceUnavailable)
+
+		client, err := runtime.NewHTTPClient(runtime.HTTPConfig{})
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		if post(t, client, srv.URL); len(*bodies) != 1 {
+			t.Errorf("expected a single attempt, got %d", len(*bodies))
+		}
+		if post(t, runtime.DefaultHTTPClient, srv.URL); len(*bodies) != 2 {
+			t.Errorf("expected the default client to make a single attempt, got %d", len(*bodies)-1)
+		}
+	})
+
+	t.Run("empty bodies without GetBody are resent", func(t *testing.T) {
+		srv, bodies := newServer(http.StatusServiceUnavailable, http.StatusOK)
+
+		client, err := runtime.NewHTTPClient(runtime.HTTPConfig{Retry: policy})
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		req, err := http.NewRequest(http.MethodPost, srv.URL, nil)
+		if err != nil {
+			t.Fatal(err)
+		}
+		req.Body = http.NoBody
+
+		resp, err := client.Do(req)
+		if err != nil {
+			t.Fatal(err)
+		}
+		resp.Body.Close()
+
+		if resp.StatusCode != http.StatusOK || len(*bodies) != 2 {
+			t.Errorf("expected status 200 after 2 attempts, got %d after %d", resp.StatusCode, len(*bodies))
+		}
+	})
+
+	t.Run("waiting respects the context", func(t *testing.T) {
+		srv, bodies := newServer(http.StatusServiceUnavailable)
+
+		client, err := runtime.NewHTTPClient(runtime.HTTPConfig{
+			Timeout: 50 * time.Millisecond,
+			Retry:   runtime.BackoffPolicy{MaxAttempts: 5, InitialDelay: time.Minute},
+		})
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		start := time.Now()
+		if _, err := client.Get(srv.URL); err == nil {
+			t.Fatal("expected a timeout error")
+		}
+		if time.Since(start) > 5*time.Second || len(*bodies) != 1 {
+			t.Errorf("expected the client to give up during the backoff delay, after %d attempts", len(*bodies))
+		}
+	})
+}
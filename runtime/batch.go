@@ -0,0 +1,178 @@
+// Copyright (c) 2025 Suricata Contributors
+// Original Author: Stefano Scafiti
+//
+// This file is part of Suricata: Type-Safe AI Agents for Go.
+//
+// Licensed under the MIT License. You may obtain a copy of the License at
+//
+//	https://opensource.org/licenses/MIT
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package runtime
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strconv"
+	"strings"
+
+	"github.com/xeipuuv/gojsonschema"
+)
+
+var (
+	ErrBatchUnsupported = errors.New("invoker does not support batches")
+	ErrBatchFailed      = errors.New("batch failed")
+)
+
+// BatchCall is a single model call of a batch.
+type BatchCall struct {
+	ID           string // Unique within the batch
+	SystemPrompt string
+	Messages     []Message
+	Options      ModelOptions
+	OutputSchema gojsonschema.JSONLoader // Schema the response must conform to. Nil means free text.
+}
+
+// BatchResult is the response to the call with the same ID.
+type BatchResult struct {
+	ID     string
+	Output string
+	Err    error
+}
+
+// BatchInvoker is implemented by invokers which can process calls offline, through the
+// batch API of their provider, which is cheaper but may take hours to complete.
+type BatchInvoker interface {
+	// SubmitBatch submits calls, and returns the identifier of the batch.
+	SubmitBatch(ctx context.Context, calls []BatchCall) (string, error)
+
+	// AwaitBatch waits for the batch to complete, and returns the results of its calls.
+	// Calls without a result were not processed.
+	AwaitBatch(ctx context.Context, id string) ([]BatchResult, error)
+}
+
+// Batch is a set of requests submitted together with Runtime.SubmitBatch. As the ID is
+// the one of the provider, a batch can be awaited by a different process, rebuilding it
+// with the same requests.
+type Batch struct {
+	ID       string
+	Requests []Request
+}
+
+// SubmitBatch submits reqs to the batch API of the invoker of the runtime, whose results
+// are collected by AwaitBatch. Only requests without tools can be batched, as agent loops
+// need several calls, and invalid outputs are reported rather than repaired.
+func (r *Runtime) SubmitBatch(ctx context.Context, reqs []Request) (*Batch, error) {
+	inv, ok := r.invoker.(BatchInvoker)
+	if !ok {
+		return nil, ErrBatchUnsupported
+	}
+
+	batch := &Batch{Requests: make([]Request, len(reqs))}
+
+	calls := make([]BatchCall, len(reqs))
+	for i, req := range reqs {
+		if req.ToolInvoker != nil {
+			return nil, fmt.Errorf("request %d: requests with tools cannot be batched", i)
+		}
+		r.overrides.apply(&req)
+		r.inlineRequestSchemas(&req)
+
+		if err := ValidateJSON(req.Input, req.InputSchema); err != nil {
+			return nil, fmt.Errorf("request %d: %w", i, err)
+		}
+
+		prompt, err := r.preparePrompt(ctx, &req)
+		if err != nil {
+			return nil, fmt.Errorf("request %d: %w", i, err)
+		}
+		r.hooks.promptBuilt(ctx, prompt.Content)
+
+		calls[i] = BatchCall{
+			ID:           batchCallID(i),
+			SystemPrompt: req.Instructions,
+			Messages:     []Message{prompt},
+			Options:      req.ModelOptions.withDefaults(ModelOptionsFromContext(ctx)),
+			OutputSchema: req.OutputSchema,
+		}
+		batch.Requests[i] = req
+	}
+
+	id, err := inv.SubmitBatch(ctx, calls)
+	if err != nil {
+		return nil, err
+	}
+	batch.ID = id
+	return batch, nil
+}
+
+// AwaitBatch waits for batch to complete, and unmarshals the output of each request. It returns
+// the error of each request, which is nil if its output was set, or an error if the batch
+// itself could not be awaited.
+func (r *Runtime) AwaitBatch(ctx context.Context, batch *Batch) ([]error, error) {
+	inv, ok := r.invoker.(BatchInvoker)
+	if !ok {
+		return nil, ErrBatchUnsupported
+	}
+
+	results, err := inv.AwaitBatch(ctx, batch.ID)
+	if err != nil {
+		return nil, err
+	}
+
+	errs := make([]error, len(batch.Requests))
+	for i := range errs {
+		errs[i] = fmt.Errorf("%w: no result", ErrBatchFailed)
+	}
+
+	for _, res := range results {
+		i := batchCallIndex(res.ID, len(batch.Requests))
+		if i < 0 {
+			continue
+		}
+		req := &batch.Requests[i]
+
+		errs[i] = res.Err
+		if res.Err == nil {
+			r.hooks.llmResponse(ctx, res.Output)
+			errs[i] = unmarshalOutput(res.Output, req)
+		}
+		if errs[i] == nil {
+			errs[i] = r.postProcess(ctx, req)
+		}
+		if errs[i] == nil {
+			errs[i] = r.checkOutput(ctx, req)
+		}
+
+		if errs[i] != nil {
+			r.hooks.error(ctx, errs[i])
+			continue
+		}
+		r.hooks.finalOutput(ctx, req.Output)
+	}
+	return errs, nil
+}
+
+func batchCallID(i int) string {
+	return fmt.Sprintf("request-%d", i)
+}
+
+// batchCallIndex returns the index of the request with the given call ID, or -1 if there is none.
+func batchCallIndex(id string, n int) int {
+	s, ok := strings.CutPrefix(id, "request-")
+	if !ok {
+		return -1
+	}
+
+	i, err := strconv.Atoi(s)
+	if err != nil || i < 0 || i >= n {
+		return -1
+	}
+	return i
+}
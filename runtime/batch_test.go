@@ -0,0 +1,144 @@
+// Copyright (c) 2025 Suricata Contributors
+// Original Author: Stefano Scafiti
+//
+// This file is part of Suricata: Type-Safe AI Agents for Go.
+//
+// Licensed under the MIT License. You may obtain a copy of the License at
+//
+//	https://opensource.org/licenses/MIT
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package runtime_test
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"github.com/ostafen/suricata/runtime"
+	"github.com/xeipuuv/gojsonschema"
+)
+
+// batchInvoker answers each call of a batch with the response of its ID.
+type batchInvoker struct {
+	runtime.InvokerFunc
+	calls     []runtime.BatchCall
+	responses map[string]runtime.BatchResult
+}
+
+func (b *batchInvoker) SubmitBatch(ctx context.Context, calls []runtime.BatchCall) (string, error) {
+	b.calls = calls
+	return "batch-1", nil
+}
+
+func (b *batchInvoker) AwaitBatch(ctx context.Context, id string) ([]runtime.BatchResult, error) {
+	if id != "batch-1" {
+		return nil, errors.New("unknown batch")
+	}
+
+	var results []runtime.BatchResult
+	for _, call := range b.calls {
+		if res, ok := b.responses[call.ID]; ok {
+			res.ID = call.ID
+			results = append(results, res)
+		}
+	}
+	return results, nil
+}
+
+func TestRuntime_Batch(t *testing.T) {
+	type (
+		Output struct {
+			Result string `json:"result"`
+		}
+		Input struct {
+			Name string `json:"name"`
+		}
+	)
+
+	var (
+		InputSchema  = gojsonschema.NewStringLoader(`{"type":"object","properties":{"name":{"type":"string"}},"required":["name"]}`)
+		OutputSchema = gojsonschema.NewStringLoader(`{"type":"object","properties":{"result":{"type":"string"}},"required":["result"]}`)
+	)
+
+	inv := &batchInvoker{responses: map[string]runtime.BatchResult{
+		"request-0": {Output: `{"result":"first"}`},
+		"request-1": {Output: `not json`},
+		"request-2": {Err: errors.New("rate limited")},
+	}}
+	rt := runtime.NewRuntime(inv)
+
+	outputs := make([]Output, 4)
+	reqs := make([]runtime.Request, len(outputs))
+	for i := range reqs {
+		reqs[i] = runtime.Request{
+			Instructions:   "Be precise.",
+			PromptTemplate: "Process {{.Name}}",
+			Input:          &Input{Name: "item"},
+			Output:         &outputs[i],
+			InputSchema:    InputSchema,
+			OutputSchema:   OutputSchema,
+			ModelOptions:   runtime.ModelOptions{Model: "small"},
+		}
+	}
+
+	batch, err := rt.SubmitBatch(context.Background(), reqs)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if batch.ID != "batch-1" || len(inv.calls) != 4 {
+		t.Fatalf("unexpected batch %q with %d calls", batch.ID, len(inv.calls))
+	}
+
+	call := inv.calls[0]
+	if call.SystemPrompt != "Be precise." || call.Options.Model != "small" || call.OutputSchema != OutputSchema || len(call.Messages) != 1 {
+		t.Errorf("unexpected call: %+v", call)
+	}
+
+	errs, err := rt.AwaitBatch(context.Background(), batch)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if errs[0] != nil || outputs[0].Result != "first" {
+		t.Errorf("expected the first output to be set, got %v, %+v", errs[0], outputs[0])
+	}
+	if !errors.Is(errs[1], runtime.ErrInvalidOutput) {
+		t.Errorf("expected an invalid output, got %v", errs[1])
+	}
+	if errs[2] == nil || errs[2].Error() != "rate limited" {
+		t.Errorf("expected the error of the call, got %v", errs[2])
+	}
+	if !errors.Is(errs[3], runtime.ErrBatchFailed) {
+		t.Errorf("expected ErrBatchFailed for a missing result, got %v", errs[3])
+	}
+}
+
+func TestRuntime_BatchUnsupported(t *testing.T) {
+	rt := runtime.NewRuntime(runtime.InvokerFunc(func(ctx context.Context, system string, messages []runtime.Message) (string, error) {
+		return "", nil
+	}))
+
+	if _, err := rt.SubmitBatch(context.Background(), nil); !errors.Is(err, runtime.ErrBatchUnsupported) {
+		t.Fatalf("expected ErrBatchUnsupported, got %v", err)
+	}
+}
+
+func TestRuntime_BatchWithTools(t *testing.T) {
+	rt := runtime.NewRuntime(&batchInvoker{})
+
+	req := runtime.Request{
+		PromptTemplate: "Test",
+		Input:          &struct{}{},
+		Output:         &struct{}{},
+		ToolInvoker:    func(ctx context.Context, name string, in any) (any, error) { return nil, nil },
+	}
+	if _, err := rt.SubmitBatch(context.Background(), []runtime.Request{req}); err == nil {
+		t.Fatal("expected an error for a request with tools")
+	}
+}
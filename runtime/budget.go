@@ -0,0 +1,170 @@
+// Copyright (c) 2025 Suricata Contributors
+// Original Author: Stefano Scafiti
+//
+// This file is part of Suricata: Type-Safe AI Agents for Go.
+//
+// Licensed under the MIT License. You may obtain a copy of the License at
+//
+//	https://opensource.org/licenses/MIT
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package runtime
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"time"
+)
+
+var ErrBudgetExceeded = errors.New("budget exceeded")
+
+// BudgetLimit identifies the limit of a Budget which was exceeded.
+type BudgetLimit string
+
+const (
+	BudgetTokens   BudgetLimit = "tokens"
+	BudgetCost     BudgetLimit = "cost"
+	BudgetDuration BudgetLimit = "duration"
+)
+
+// Budget caps the resources consumed by a run across the whole agent loop,
+// including tool iterations and output repairs. Zero fields mean no limit.
+// Token counts are estimated with EstimateTokens, as every message in the
+// history is sent again on each model call.
+type Budget struct {
+	MaxTokens   int           // Prompt and completion tokens.
+	MaxCost     float64       // Cost computed from the token prices below.
+	MaxDuration time.Duration // Wall time of the run.
+
+	PromptTokenPrice     float64 // Cost of a single prompt token.
+	CompletionTokenPrice float64 // Cost of a single completion token.
+}
+
+func (b Budget) isZero() bool {
+	return b.MaxTokens <= 0 && b.MaxCost <= 0 && b.MaxDuration <= 0
+}
+
+// Usage is the amount of resources consumed by a run.
+type Usage struct {
+	PromptTokens     int     `json:"prompt_tokens"`
+	CompletionTokens int     `json:"completion_tokens"`
+	Cost             float64 `json:"cost"`
+}
+
+func (u Usage) TotalTokens() int {
+	return u.PromptTokens + u.CompletionTokens
+}
+
+// BudgetError is returned when a run exceeds its Budget.
+// It matches ErrBudgetExceeded with errors.Is.
+type BudgetError struct {
+	Limit      BudgetLimit
+	Usage      Usage
+	Elapsed    time.Duration
+	Transcript []Message // Chat history of the run, up to the point where it was aborted
+}
+
+func (e *BudgetError) Error() string {
+	switch e.Limit {
+	case BudgetTokens:
+		return fmt.Sprintf("%s: %d tokens used", ErrBudgetExceeded, e.Usage.TotalTokens())
+	case BudgetCost:
+		return fmt.Sprintf("%s: cost %.4f", ErrBudgetExceeded, e.Usage.Cost)
+	default:
+		return fmt.Sprintf("%s: run took %s", ErrBudgetExceeded, e.Elapsed.Round(time.Millisecond))
+	}
+}
+
+func (e *BudgetError) Unwrap() error {
+	return ErrBudgetExceeded
+}
+
+// WithBudget applies budget to requests which do not set their own.
+func WithBudget(budget Budget) Option {
+	return func(r *Runtime) {
+		r.budget = budget
+	}
+}
+
+func (r *Runtime) budgetOf(req *Request) Budget {
+	if req.Budget.isZero() {
+		return r.budget
+	}
+	return req.Budget
+}
+
+var errDurationExceeded = errors.New("run duration exceeded")
+
+// withBudget runs fn under the budget of req, turning overruns into a *BudgetError
+// carrying the transcript of sess.
+func (r *Runtime) withBudget(ctx context.Context, req *Request, sess *ChatSession, st *runState, fn func(ctx context.Context) error) error {
+	budget := r.budgetOf(req)
+	if budget.isZero() {
+		return fn(ctx)
+	}
+
+	start := time.Now()
+	if budget.MaxDuration > 0 {
+		var cancel context.CancelFunc
+		ctx, cancel = context.WithTimeoutCause(ctx, budget.MaxDuration, errDurationExceeded)
+		defer cancel()
+	}
+
+	err := fn(ctx)
+	if err == nil {
+		return nil
+	}
+
+	var budgetErr *BudgetError
+	if !errors.As(err, &budgetErr) {
+		if ctx.Err() == nil || !errors.Is(context.Cause(ctx), errDurationExceeded) {
+			return err
+		}
+		budgetErr = &BudgetError{Limit: BudgetDuration, Usage: st.usage}
+	}
+	budgetErr.Elapsed = time.Since(start)
+
+	// The transcript is read even if the deadline of the run has passed
+	if history, err := sess.History(context.WithoutCancel(ctx)); err == nil {
+		budgetErr.Transcript = history
+	}
+	return budgetErr
+}
+
+// spend accounts for the model response which ends the history of sess,
+// failing once the token or cost limits of the budget are exceeded.
+func (r *Runtime) spend(ctx context.Context, req *Request, sess *ChatSession, st *runState) error {
+	budget := r.budgetOf(req)
+	if budget.MaxTokens <= 0 && budget.MaxCost <= 0 {
+		return nil
+	}
+
+	history, err := sess.History(ctx)
+	if err != nil {
+		return fmt.Errorf("read history: %w", err)
+	}
+	if len(history) == 0 {
+		return nil
+	}
+
+	prompt := EstimateTokens(history[:len(history)-1])
+	completion := EstimateTokens(history[len(history)-1:])
+
+	st.usage.PromptTokens += prompt
+	st.usage.CompletionTokens += completion
+	st.usage.Cost += float64(prompt)*budget.PromptTokenPrice + float64(completion)*budget.CompletionTokenPrice
+
+	if budget.MaxTokens > 0 && st.usage.TotalTokens() > budget.MaxTokens {
+		return &BudgetError{Limit: BudgetTokens, Usage: st.usage}
+	}
+	if budget.MaxCost > 0 && st.usage.Cost > budget.MaxCost {
+		return &BudgetError{Limit: BudgetCost, Usage: st.usage}
+	}
+	return nil
+}
@@ -0,0 +1,127 @@
+// Copyright (c) 2025 Suricata Contributors
+// Original Author: Stefano Scafiti
+//
+// This file is part of Suricata: Type-Safe AI Agents for Go.
+//
+// Licensed under the MIT License. You may obtain a copy of the License at
+//
+//	https://opensource.org/licenses/MIT
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package runtime_test
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"testing"
+	"time"
+
+	"github.com/ostafen/suricata/runtime"
+	"github.com/xeipuuv/gojsonschema"
+)
+
+// loopingInvoker never produces a final output, requesting a new search at each turn.
+func loopingInvoker() runtime.Invoker {
+	var n int
+	return runtime.InvokerFunc(func(ctx context.Context, system string, messages []runtime.Message) (string, error) {
+		n++
+		return fmt.Sprintf(`{"name":"search","args":{"query":"attempt %d"}}`, n), nil
+	})
+}
+
+func budgetRequest(budget runtime.Budget) runtime.Request {
+	return runtime.Request{
+		PromptTemplate: "Find the answer",
+		Input:          map[string]any{},
+		Output:         new(string),
+		InputSchema:    gojsonschema.NewStringLoader(`{"type":"object"}`),
+		ToolUnmarshaller: func(name string, data []byte) (any, error) {
+			var args map[string]string
+			return args, json.Unmarshal(data, &args)
+		},
+		ToolInvoker: func(ctx context.Context, name string, in any) (any, error) {
+			return "no results", nil
+		},
+		Budget: budget,
+	}
+}
+
+func TestRuntime_Budget(t *testing.T) {
+	t.Run("tokens", func(t *testing.T) {
+		rt := runtime.NewRuntime(loopingInvoker())
+
+		err := rt.Invoke(context.Background(), budgetRequest(runtime.Budget{MaxTokens: 200}))
+
+		var budgetErr *runtime.BudgetError
+		if !errors.As(err, &budgetErr) {
+			t.Fatalf("expected BudgetError, got %v", err)
+		}
+		if !errors.Is(err, runtime.ErrBudgetExceeded) {
+			t.Error("expected error to match ErrBudgetExceeded")
+		}
+		if budgetErr.Limit != runtime.BudgetTokens {
+			t.Errorf("expected tokens limit, got %s", budgetErr.Limit)
+		}
+		if budgetErr.Usage.TotalTokens() <= 200 {
+			t.Errorf("expected usage above the limit, got %d", budgetErr.Usage.TotalTokens())
+		}
+		if len(budgetErr.Transcript) < 2 || budgetErr.Transcript[len(budgetErr.Transcript)-1].Role != runtime.RoleAgent {
+			t.Errorf("expected transcript ending with the last model response, got %+v", budgetErr.Transcript)
+		}
+	})
+
+	t.Run("cost", func(t *testing.T) {
+		rt := runtime.NewRuntime(loopingInvoker())
+
+		err := rt.Invoke(context.Background(), budgetRequest(runtime.Budget{
+			MaxCost:              0.01,
+			PromptTokenPrice:     0.0001,
+			CompletionTokenPrice: 0.0002,
+		}))
+
+		var budgetErr *runtime.BudgetError
+		if !errors.As(err, &budgetErr) || budgetErr.Limit != runtime.BudgetCost {
+			t.Fatalf("expected cost BudgetError, got %v", err)
+		}
+		if budgetErr.Usage.Cost <= 0.01 {
+			t.Errorf("expected cost above the limit, got %v", budgetErr.Usage.Cost)
+		}
+	})
+
+	t.Run("duration", func(t *testing.T) {
+		slow := runtime.InvokerFunc(func(ctx context.Context, system string, messages []runtime.Message) (string, error) {
+			if len(messages) > 1 {
+				<-ctx.Done()
+				return "", ctx.Err()
+			}
+			return `{"name":"search","args":{"query":"first"}}`, nil
+		})
+		rt := runtime.NewRuntime(slow)
+
+		err := rt.Invoke(context.Background(), budgetRequest(runtime.Budget{MaxDuration: 20 * time.Millisecond}))
+
+		var budgetErr *runtime.BudgetError
+		if !errors.As(err, &budgetErr) || budgetErr.Limit != runtime.BudgetDuration {
+			t.Fatalf("expected duration BudgetError, got %v", err)
+		}
+		if len(budgetErr.Transcript) == 0 {
+			t.Error("expected partial transcript")
+		}
+	})
+
+	t.Run("runtime default", func(t *testing.T) {
+		rt := runtime.NewRuntime(loopingInvoker(), runtime.WithBudget(runtime.Budget{MaxTokens: 200}))
+
+		err := rt.Invoke(context.Background(), budgetRequest(runtime.Budget{}))
+		if !errors.Is(err, runtime.ErrBudgetExceeded) {
+			t.Fatalf("expected ErrBudgetExceeded, got %v", err)
+		}
+	})
+}
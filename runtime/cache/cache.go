@@ -0,0 +1,150 @@
+// Copyright (c) 2025 Suricata Contributors
+// Original Author: Stefano Scafiti
+//
+// This file is part of Suricata: Type-Safe AI Agents for Go.
+//
+// Licensed under the MIT License. You may obtain a copy of the License at
+//
+//	https://opensource.org/licenses/MIT
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+// Package cache provides an exact-match response cache for runtime.Invoker.
+//
+// Two calls hit the same entry only if they share the system prompt, the
+// messages and the model options found in the context. It pays off for
+// deterministic actions, such as extractions run at temperature 0.
+//
+//	cached := cache.NewInvoker(invoker, cache.NewMemoryStore(1000))
+//	rt := runtime.NewRuntime(cached)
+package cache
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"sync/atomic"
+
+	"github.com/ostafen/suricata/runtime"
+)
+
+// Store persists cached responses by key.
+// Get reports false, with a nil error, when the key is not present.
+type Store interface {
+	Get(ctx context.Context, key string) (string, bool, error)
+	Set(ctx context.Context, key, value string) error
+}
+
+// Stats counts the lookups served by an Invoker.
+type Stats struct {
+	Hits   uint64
+	Misses uint64
+}
+
+// HitRate returns the fraction of lookups served from the cache.
+func (s Stats) HitRate() float64 {
+	total := s.Hits + s.Misses
+	if total == 0 {
+		return 0
+	}
+	return float64(s.Hits) / float64(total)
+}
+
+// Invoker serves responses from a Store, calling the wrapped invoker on a miss.
+// Store failures never fail a call: a lookup error counts as a miss and a
+// failed write is ignored. Errors from the wrapped invoker are not cached.
+type Invoker struct {
+	next   runtime.Invoker
+	store  Store
+	hits   atomic.Uint64
+	misses atomic.Uint64
+}
+
+func NewInvoker(next runtime.Invoker, store Store) *Invoker {
+	return &Invoker{next: next, store: store}
+}
+
+// Middleware returns a runtime.InvokerMiddleware caching responses in store.
+func Middleware(store Store) runtime.InvokerMiddleware {
+	return func(next runtime.Invoker) runtime.Invoker {
+		return NewInvoker(next, store)
+	}
+}
+
+func (c *Invoker) Invoke(ctx context.Context, systemPrompt string, messages []runtime.Message) (string, error) {
+	return c.call(ctx, systemPrompt, messages, nil)
+}
+
+// InvokeStream implements runtime.StreamingInvoker. Misses stream the response of the wrapped
+// invoker, if it supports it, while hits deliver the cached response as a single delta.
+func (c *Invoker) InvokeStream(ctx context.Context, systemPrompt string, messages []runtime.Message, onDelta func(delta string)) (string, error) {
+	return c.call(ctx, systemPrompt, messages, onDelta)
+}
+
+func (c *Invoker) call(ctx context.Context, systemPrompt string, messages []runtime.Message, onDelta func(delta string)) (string, error) {
+	key := Key(ctx, systemPrompt, messages)
+
+	if out, ok, err := c.store.Get(ctx, key); err == nil && ok {
+		c.hits.Add(1)
+		if onDelta != nil {
+			onDelta(out)
+		}
+		return out, nil
+	}
+	c.misses.Add(1)
+
+	var (
+		out string
+		err error
+	)
+	if onDelta != nil {
+		out, err = runtime.InvokeStream(ctx, c.next, systemPrompt, messages, onDelta)
+	} else {
+		out, err = c.next.Invoke(ctx, systemPrompt, messages)
+	}
+	if err != nil {
+		return "", err
+	}
+
+	_ = c.store.Set(ctx, key, out)
+	return out, nil
+}
+
+// Stats returns the hits and misses counted so far.
+func (c *Invoker) Stats() Stats {
+	return Stats{
+		Hits:   c.hits.Load(),
+		Misses: c.misses.Load(),
+	}
+}
+
+// Key returns the hex-encoded SHA-256 of the system prompt, the messages and
+// the model options attached to ctx.
+func Key(ctx context.Context, systemPrompt string, messages []runtime.Message) string {
+	opts := runtime.ModelOptionsFromContext(ctx)
+
+	// Marshalling these types cannot fail.
+	data, _ := json.Marshal(struct {
+		Model       string            `json:"model,omitempty"`
+		Temperature *float64          `json:"temperature,omitempty"`
+		MaxTokens   int               `json:"max_tokens,omitempty"`
+		Seed        *int              `json:"seed,omitempty"`
+		System      string            `json:"system"`
+		Messages    []runtime.Message `json:"messages"`
+	}{
+		Model:       opts.Model,
+		Temperature: opts.Temperature,
+		MaxTokens:   opts.MaxTokens,
+		Seed:        opts.Seed,
+		System:      systemPrompt,
+		Messages:    messages,
+	})
+
+	sum := sha256.Sum256(data)
+	return hex.EncodeToString(sum[:])
+}
@@ -0,0 +1,178 @@
+// Copyright (c) 2025 Suricata Contributors
+// Original Author: Stefano Scafiti
+//
+// This file is part of Suricata: Type-Safe AI Agents for Go.
+//
+// Licensed under the MIT License. You may obtain a copy of the License at
+//
+//	https://opensource.org/licenses/MIT
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package cache_test
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"github.com/ostafen/suricata/runtime"
+	"github.com/ostafen/suricata/runtime/cache"
+)
+
+func countingInvoker(calls *int) runtime.Invoker {
+	return runtime.InvokerFunc(func(ctx context.Context, system string, messages []runtime.Message) (string, error) {
+		*calls++
+		if messages[len(messages)-1].Content == "fail" {
+			return "", errors.New("provider down")
+		}
+		return "reply to " + messages[len(messages)-1].Content, nil
+	})
+}
+
+func userMessage(content string) []runtime.Message {
+	return []runtime.Message{{Role: runtime.RoleUser, Content: content}}
+}
+
+func TestInvoker(t *testing.T) {
+	ctx := context.Background()
+
+	fileStore, err := cache.NewFileStore(t.TempDir())
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	stores := map[string]cache.Store{
+		"memory": cache.NewMemoryStore(0),
+		"file":   fileStore,
+	}
+
+	for name, store := range stores {
+		t.Run(name, func(t *testing.T) {
+			var calls int
+			inv := cache.NewInvoker(countingInvoker(&calls), store)
+
+			for i := 0; i < 3; i++ {
+				out, err := inv.Invoke(ctx, "system", userMessage("hello"))
+				if err != nil {
+					t.Fatalf("unexpected error: %v", err)
+				}
+				if out != "reply to hello" {
+					t.Errorf("unexpected output %q", out)
+				}
+			}
+
+			if calls != 1 {
+				t.Errorf("expected 1 provider call, got %d", calls)
+			}
+
+			if _, err := inv.Invoke(ctx, "other system", userMessage("hello")); err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			if calls != 2 {
+				t.Errorf("a different system prompt must miss the cache, got %d calls", calls)
+			}
+
+			stats := inv.Stats()
+			if stats.Hits != 2 || stats.Misses != 2 {
+				t.Errorf("unexpected stats %+v", stats)
+			}
+			if stats.HitRate() != 0.5 {
+				t.Errorf("expected hit rate 0.5, got %v", stats.HitRate())
+			}
+		})
+	}
+}
+
+func TestInvoker_ErrorsAreNotCached(t *testing.T) {
+	var calls int
+	inv := cache.NewInvoker(countingInvoker(&calls), cache.NewMemoryStore(0))
+
+	for i := 0; i < 2; i++ {
+		if _, err := inv.Invoke(context.Background(), "", userMessage("fail")); err == nil {
+			t.Fatal("expected error")
+		}
+	}
+
+	if calls != 2 {
+		t.Errorf("expected 2 provider calls, got %d", calls)
+	}
+}
+
+func TestKey_ModelOptions(t *testing.T) {
+	ctx := context.Background()
+	msgs := userMessage("hello")
+
+	base := cache.Key(ctx, "system", msgs)
+	if got := cache.Key(ctx, "system", msgs); got != base {
+		t.Error("expected the same key for identical calls")
+	}
+
+	cold := runtime.WithModelOptions(ctx, runtime.ModelOptions{Temperature: runtime.Float64(0)})
+	if cache.Key(cold, "system", msgs) == base {
+		t.Error("expected model options to change the key")
+	}
+}
+
+func TestMemoryStore_Eviction(t *testing.T) {
+	ctx := context.Background()
+	store := cache.NewMemoryStore(2)
+
+	store.Set(ctx, "a", "1")
+	store.Set(ctx, "b", "2")
+	store.Get(ctx, "a")
+	store.Set(ctx, "c", "3")
+
+	if store.Len() != 2 {
+		t.Errorf("expected 2 entries, got %d", store.Len())
+	}
+	if _, ok, _ := store.Get(ctx, "b"); ok {
+		t.Error("expected the least recently used entry to be evicted")
+	}
+	if _, ok, _ := store.Get(ctx, "a"); !ok {
+		t.Error("expected a to be kept")
+	}
+}
+
+// streamingInvoker streams its reply word by word.
+type streamingInvoker struct {
+	calls int
+}
+
+func (s *streamingInvoker) Invoke(ctx context.Context, system string, messages []runtime.Message) (string, error) {
+	return s.InvokeStream(ctx, system, messages, func(string) {})
+}
+
+func (s *streamingInvoker) InvokeStream(ctx context.Context, system string, messages []runtime.Message, onDelta func(string)) (string, error) {
+	s.calls++
+	onDelta("reply ")
+	onDelta("to " + messages[len(messages)-1].Content)
+	return "reply to " + messages[len(messages)-1].Content, nil
+}
+
+func TestInvoker_Stream(t *testing.T) {
+	next := &streamingInvoker{}
+	inv := runtime.Chain(next, cache.Middleware(cache.NewMemoryStore(0))).(runtime.StreamingInvoker)
+
+	var deltas []string
+	onDelta := func(delta string) { deltas = append(deltas, delta) }
+
+	if _, err := inv.InvokeStream(context.Background(), "", userMessage("hi"), onDelta); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if len(deltas) != 2 {
+		t.Errorf("expected the miss to be streamed, got %q", deltas)
+	}
+
+	deltas = nil
+	if _, err := inv.InvokeStream(context.Background(), "", userMessage("hi"), onDelta); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if len(deltas) != 1 || deltas[0] != "reply to hi" || next.calls != 1 {
+		t.Errorf("expected the hit to be delivered as a single delta, got %q after %d calls", deltas, next.calls)
+	}
+}
@@ -0,0 +1,57 @@
+// Copyright (c) 2025 Suricata Contributors
+// Original Author: Stefano Scafiti
+//
+// This file is part of Suricata: Type-Safe AI Agents for Go.
+//
+// Licensed under the MIT License. You may obtain a copy of the License at
+//
+//	https://opensource.org/licenses/MIT
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+// Package redis provides a cache.Store backed by Redis.
+package redis
+
+import (
+	"context"
+	"errors"
+	"time"
+
+	goredis "github.com/redis/go-redis/v9"
+)
+
+const DefaultKeyPrefix = "suricata:cache:"
+
+// Store keeps cached responses as Redis strings.
+type Store struct {
+	client goredis.UniversalClient
+	ttl    time.Duration
+}
+
+// NewStore returns a store using client.
+// A positive ttl makes each entry expire after the given period.
+func NewStore(client goredis.UniversalClient, ttl time.Duration) *Store {
+	return &Store{
+		client: client,
+		ttl:    ttl,
+	}
+}
+
+func (s *Store) Get(ctx context.Context, key string) (string, bool, error) {
+	value, err := s.client.Get(ctx, DefaultKeyPrefix+key).Result()
+	if errors.Is(err, goredis.Nil) {
+		return "", false, nil
+	}
+	if err != nil {
+		return "", false, err
+	}
+	return value, true, nil
+}
+
+func (s *Store) Set(ctx context.Context, key, value string) error {
+	return s.client.Set(ctx, DefaultKeyPrefix+key, value, s.ttl).Err()
+}
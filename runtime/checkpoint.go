@@ -0,0 +1,211 @@
+// Copyright (c) 2025 Suricata Contributors
+// Original Author: Stefano Scafiti
+//
+// This file is part of Suricata: Type-Safe AI Agents for Go.
+//
+// Licensed under the MIT License. You may obtain a copy of the License at
+//
+//	https://opensource.org/licenses/MIT
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package runtime
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"maps"
+	"sync"
+)
+
+var (
+	ErrRunNotFound = errors.New("run not found")
+	ErrRunPaused   = errors.New("run paused")
+)
+
+// Checkpoint is the state of a run, saved each time the model replies.
+type Checkpoint struct {
+	RunID      string         `json:"run_id"`
+	Messages   []Message      `json:"messages"`             // Chat history, ending with the last model response
+	Pending    []PendingCall  `json:"pending,omitempty"`    // Tool calls awaiting approval, when the run is paused
+	Iterations int            `json:"iterations"`           // Tool calls issued so far
+	Failures   int            `json:"failures"`             // Invalid model responses so far
+	SeenCalls  map[string]int `json:"seen_calls,omitempty"` // Number of times each tool call was issued, by name and arguments
+	Usage      Usage          `json:"usage"`                // Resources consumed before the last model response
+	Critiqued  bool           `json:"critiqued,omitempty"`  // Whether the output has already been reviewed, with WithCritique
+}
+
+// CheckpointStore persists the checkpoints of runs, so that they can be resumed
+// after a crash or once a paused run is approved.
+type CheckpointStore interface {
+	// Save replaces the checkpoint of the run.
+	Save(ctx context.Context, cp *Checkpoint) error
+	// Load returns the last checkpoint of the run, or ErrRunNotFound.
+	Load(ctx context.Context, runID string) (*Checkpoint, error)
+	// Delete discards the checkpoint of a completed run.
+	Delete(ctx context.Context, runID string) error
+}
+
+// WithCheckpoints saves the state of each run to store, identifying runs by their request ID (see WithRequestID).
+// Checkpoints of completed runs are deleted.
+func WithCheckpoints(store CheckpointStore) Option {
+	return func(r *Runtime) {
+		r.checkpoints = store
+	}
+}
+
+// InMemoryCheckpointStore is a CheckpointStore keeping checkpoints in a map.
+// It survives paused runs, but not process restarts.
+type InMemoryCheckpointStore struct {
+	mtx         sync.Mutex
+	checkpoints map[string][]byte
+}
+
+func NewInMemoryCheckpointStore() *InMemoryCheckpointStore {
+	return &InMemoryCheckpointStore{checkpoints: make(map[string][]byte)}
+}
+
+func (s *InMemoryCheckpointStore) Save(_ context.Context, cp *Checkpoint) error {
+	// Checkpoints are stored encoded, so that callers cannot alter them afterwards
+	data, err := json.Marshal(cp)
+	if err != nil {
+		return err
+	}
+
+	s.mtx.Lock()
+	defer s.mtx.Unlock()
+
+	s.checkpoints[cp.RunID] = data
+	return nil
+}
+
+func (s *InMemoryCheckpointStore) Load(_ context.Context, runID string) (*Checkpoint, error) {
+	s.mtx.Lock()
+	data, ok := s.checkpoints[runID]
+	s.mtx.Unlock()
+
+	if !ok {
+		return nil, fmt.Errorf("%w: '%s'", ErrRunNotFound, runID)
+	}
+
+	var cp Checkpoint
+	if err := json.Unmarshal(data, &cp); err != nil {
+		return nil, err
+	}
+	return &cp, nil
+}
+
+func (s *InMemoryCheckpointStore) Delete(_ context.Context, runID string) error {
+	s.mtx.Lock()
+	defer s.mtx.Unlock()
+
+	delete(s.checkpoints, runID)
+	return nil
+}
+
+// runState tracks the progress of a run.
+type runState struct {
+	failures   int
+	iterations int
+	seenCalls  map[string]int
+	usage      Usage
+	critiqued  bool // Whether the output has already been reviewed, with WithCritique
+
+	last *Checkpoint // Checkpoint taken when the last model response was received
+}
+
+// checkpoint saves the state of the run, which has just received a model response.
+func (r *Runtime) checkpoint(ctx context.Context, sess *ChatSession, st *runState) error {
+	if r.checkpoints == nil {
+		return nil
+	}
+
+	history, err := sess.History(ctx)
+	if err != nil {
+		return fmt.Errorf("save checkpoint: %w", err)
+	}
+
+	st.last = &Checkpoint{
+		RunID:      RequestIDFromContext(ctx),
+		Messages:   history,
+		Iterations: st.iterations,
+		Failures:   st.failures,
+		SeenCalls:  maps.Clone(st.seenCalls),
+		Usage:      st.usage,
+		Critiqued:  st.critiqued,
+	}
+	if err := r.checkpoints.Save(ctx, st.last); err != nil {
+		return fmt.Errorf("save checkpoint: %w", err)
+	}
+	return nil
+}
+
+// pause stops the run until the pending calls are approved. Resuming the run processes
+// the last model response again, asking for the approval of its tool calls once more.
+func (r *Runtime) pause(ctx context.Context, st *runState, pending []PendingCall) error {
+	runID := RequestIDFromContext(ctx)
+	if r.checkpoints == nil {
+		return fmt.Errorf("%w: run '%s' cannot be resumed without a checkpoint store", ErrRunPaused, runID)
+	}
+
+	cp := *st.last
+	cp.Pending = pending
+	if err := r.checkpoints.Save(ctx, &cp); err != nil {
+		return fmt.Errorf("save checkpoint: %w", err)
+	}
+	return fmt.Errorf("%w: run '%s' awaits approval of %d tool call(s)", ErrRunPaused, runID, len(pending))
+}
+
+// Resume continues the run with the given ID from its last checkpoint.
+// req must describe the same action as the interrupted request; its Input is ignored,
+// as the prompt is already part of the saved history. When req.Memory is set, it is
+// expected to hold the history of the run, which is otherwise restored from the checkpoint.
+// The tool calls requested by the last model response run again, so tools should be idempotent.
+func (r *Runtime) Resume(ctx context.Context, runID string, req Request) error {
+	return r.run(WithRequestID(ctx, runID), &req, r.resume)
+}
+
+func (r *Runtime) resume(ctx context.Context, req *Request) error {
+	if r.checkpoints == nil {
+		return errors.New("resume requires a checkpoint store")
+	}
+
+	cp, err := r.checkpoints.Load(ctx, RequestIDFromContext(ctx))
+	if err != nil {
+		return err
+	}
+
+	if len(cp.Messages) == 0 || cp.Messages[len(cp.Messages)-1].Role != RoleAgent {
+		return fmt.Errorf("checkpoint of run '%s' does not end with a model response", cp.RunID)
+	}
+
+	memory := req.Memory
+	if memory == nil {
+		memory = NewInMemory()
+		if err := memory.Append(ctx, cp.Messages...); err != nil {
+			return fmt.Errorf("restore history: %w", err)
+		}
+	}
+
+	st := &runState{
+		failures:   cp.Failures,
+		iterations: cp.Iterations,
+		seenCalls:  cp.SeenCalls,
+		usage:      cp.Usage,
+		critiqued:  cp.Critiqued,
+	}
+	if st.seenCalls == nil {
+		st.seenCalls = make(map[string]int)
+	}
+
+	sess := r.newSession(req, memory)
+	return r.withBudget(ctx, req, sess, st, func(ctx context.Context) error {
+		return r.loop(ctx, cp.Messages[len(cp.Messages)-1].Content, req, sess, st)
+	})
+}
@@ -0,0 +1,143 @@
+// Copyright (c) 2025 Suricata Contributors
+// Original Author: Stefano Scafiti
+//
+// This file is part of Suricata: Type-Safe AI Agents for Go.
+//
+// Licensed under the MIT License. You may obtain a copy of the License at
+//
+//	https://opensource.org/licenses/MIT
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package runtime_test
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"strings"
+	"testing"
+
+	"github.com/ostafen/suricata/runtime"
+	"github.com/ostafen/suricata/runtime/runtimetest"
+	"github.com/xeipuuv/gojsonschema"
+)
+
+func checkpointRequest(invoked *[]string) runtime.Request {
+	return runtime.Request{
+		PromptTemplate: "Book a flight",
+		Input:          map[string]any{},
+		Output:         new(string),
+		InputSchema:    gojsonschema.NewStringLoader(`{"type":"object"}`),
+		ToolSpecs: []runtime.ToolSpec{
+			{Name: "book", Schema: gojsonschema.NewStringLoader(`{"type":"object"}`), RequiresApproval: true},
+		},
+		ToolUnmarshaller: func(name string, data []byte) (any, error) {
+			var args map[string]string
+			return args, json.Unmarshal(data, &args)
+		},
+		ToolInvoker: func(ctx context.Context, name string, in any) (any, error) {
+			*invoked = append(*invoked, name+":"+in.(map[string]string)["flight"])
+			return "booked", nil
+		},
+	}
+}
+
+func TestRuntime_ResumePausedRun(t *testing.T) {
+	mock := runtimetest.NewInvoker(t).Respond(
+		`{"name":"book","args":{"flight":"AZ1"}}`,
+		`{"done":true,"out":"Flight booked"}`,
+	)
+
+	store := runtime.NewInMemoryCheckpointStore()
+	approved := false
+
+	rt := runtime.NewRuntime(mock, runtime.WithCheckpoints(store), runtime.WithApproval(func(ctx context.Context, call runtime.PendingCall) error {
+		if !approved {
+			return runtime.ErrApprovalPending
+		}
+		return nil
+	}))
+
+	var invoked []string
+	ctx := runtime.WithRequestID(context.Background(), "run-1")
+
+	err := rt.Invoke(ctx, checkpointRequest(&invoked))
+	if !errors.Is(err, runtime.ErrRunPaused) {
+		t.Fatalf("expected ErrRunPaused, got %v", err)
+	}
+
+	cp, err := store.Load(ctx, "run-1")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(cp.Pending) != 1 || cp.Pending[0].Tool != "book" || string(cp.Pending[0].Args) != `{"flight":"AZ1"}` {
+		t.Fatalf("unexpected pending calls: %+v", cp.Pending)
+	}
+	if len(invoked) != 0 {
+		t.Fatalf("tool must not run before approval, got %v", invoked)
+	}
+
+	approved = true
+
+	req := checkpointRequest(&invoked)
+	if err := rt.Resume(context.Background(), "run-1", req); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	if out := *req.Output.(*string); out != "Flight booked" {
+		t.Errorf("unexpected output %q", out)
+	}
+	if strings.Join(invoked, ",") != "book:AZ1" {
+		t.Errorf("unexpected tool calls: %v", invoked)
+	}
+
+	if _, err := store.Load(ctx, "run-1"); !errors.Is(err, runtime.ErrRunNotFound) {
+		t.Errorf("expected checkpoint of completed run to be deleted, got %v", err)
+	}
+}
+
+func TestRuntime_ResumeFailedRun(t *testing.T) {
+	mock := runtimetest.NewInvoker(t)
+	mock.Expect().Respond(`{"name":"book","args":{"flight":"AZ1"}}`)
+	mock.Expect().ReturnError(errors.New("connection reset"))
+	mock.Expect().PromptContains(`book OUTPUT: "booked"`).Respond(`{"done":true,"out":"Flight booked"}`)
+
+	store := runtime.NewInMemoryCheckpointStore()
+	rt := runtime.NewRuntime(mock, runtime.WithCheckpoints(store), runtime.WithApproval(func(ctx context.Context, call runtime.PendingCall) error {
+		return nil
+	}))
+
+	var invoked []string
+	ctx := runtime.WithRequestID(context.Background(), "run-2")
+
+	if err := rt.Invoke(ctx, checkpointRequest(&invoked)); err == nil {
+		t.Fatal("expected error")
+	}
+
+	req := checkpointRequest(&invoked)
+	if err := rt.Resume(context.Background(), "run-2", req); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if out := *req.Output.(*string); out != "Flight booked" {
+		t.Errorf("unexpected output %q", out)
+	}
+
+	// The history of the interrupted run is restored from the checkpoint
+	if history := mock.LastCall().Messages; len(history) != 3 || !strings.Contains(history[0].Content, "Book a flight") {
+		t.Errorf("unexpected history: %+v", history)
+	}
+}
+
+func TestRuntime_ResumeUnknownRun(t *testing.T) {
+	rt := runtime.NewRuntime(runtimetest.NewInvoker(t), runtime.WithCheckpoints(runtime.NewInMemoryCheckpointStore()))
+
+	var invoked []string
+	if err := rt.Resume(context.Background(), "missing", checkpointRequest(&invoked)); !errors.Is(err, runtime.ErrRunNotFound) {
+		t.Errorf("expected ErrRunNotFound, got %v", err)
+	}
+}
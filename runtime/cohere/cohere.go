@@ -0,0 +1,125 @@
+// Copyright (c) 2025 Suricata Contributors
+// Original Author: Stefano Scafiti
+//
+// This file is part of Suricata: Type-Safe AI Agents for Go.
+//
+// Licensed under the MIT License. You may obtain a copy of the License at
+//
+//	https://opensource.org/licenses/MIT
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+// Package cohere provides an embedder backed by the Cohere embed API.
+package cohere
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+
+	"github.com/ostafen/suricata/runtime"
+)
+
+const DefaultBaseURL = "https://api.cohere.com"
+
+// MaxBatchSize is the maximum number of texts embedded by a single API call.
+// Larger inputs are split into several calls.
+const MaxBatchSize = 96
+
+// InputType tells the model how embeddings are going to be used.
+type InputType string
+
+const (
+	InputTypeSearchDocument InputType = "search_document" // Texts stored in a vector store
+	InputTypeSearchQuery    InputType = "search_query"    // Queries searched against stored documents
+	InputTypeClassification InputType = "classification"
+	InputTypeClustering     InputType = "clustering"
+)
+
+// CohereEmbedder is a runtime.Embedder backed by the Cohere embed API.
+type CohereEmbedder struct {
+	baseURL   string
+	apiKey    string
+	model     string
+	inputType InputType
+
+	HTTPClient *http.Client // Client sending the requests. Nil means runtime.DefaultHTTPClient.
+}
+
+// NewEmbedder returns an embedder using the given model, e.g. "embed-english-v3.0".
+func NewEmbedder(baseURL, apiKey, model string, inputType InputType) *CohereEmbedder {
+	return &CohereEmbedder{
+		baseURL:   baseURL,
+		apiKey:    apiKey,
+		model:     model,
+		inputType: inputType,
+	}
+}
+
+func (c *CohereEmbedder) Embed(ctx context.Context, texts []string) ([][]float32, error) {
+	out := make([][]float32, 0, len(texts))
+	for start := 0; start < len(texts); start += MaxBatchSize {
+		batch := texts[start:min(start+MaxBatchSize, len(texts))]
+
+		vectors, err := c.embed(ctx, batch)
+		if err != nil {
+			return nil, err
+		}
+		out = append(out, vectors...)
+	}
+	return out, nil
+}
+
+func (c *CohereEmbedder) embed(ctx context.Context, texts []string) ([][]float32, error) {
+	data, err := json.Marshal(map[string]any{
+		"model":           c.model,
+		"texts":           texts,
+		"input_type":      c.inputType,
+		"embedding_types": []string{"float"},
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	req, err := http.NewRequestWithContext(ctx, "POST", c.baseURL+"/v2/embed", bytes.NewBuffer(data))
+	if err != nil {
+		return nil, err
+	}
+	req.Header.Set("Content-Type", "application/json")
+	req.Header.Set("Authorization", "Bearer "+c.apiKey)
+
+	client := c.HTTPClient
+	if client == nil {
+		client = runtime.DefaultHTTPClient
+	}
+
+	resp, err := client.Do(req)
+	if err != nil {
+		return nil, err
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != 200 {
+		return nil, runtime.NewProviderError("cohere", resp)
+	}
+
+	var result struct {
+		Embeddings struct {
+			Float [][]float32 `json:"float"`
+		} `json:"embeddings"`
+	}
+	if err := json.NewDecoder(resp.Body).Decode(&result); err != nil {
+		return nil, err
+	}
+
+	if len(result.Embeddings.Float) != len(texts) {
+		return nil, fmt.Errorf("cohere error: expected %d embeddings, got %d", len(texts), len(result.Embeddings.Float))
+	}
+	return result.Embeddings.Float, nil
+}
@@ -0,0 +1,77 @@
+// Copyright (c) 2025 Suricata Contributors
+// Original Author: Stefano Scafiti
+//
+// This file is part of Suricata: Type-Safe AI Agents for Go.
+//
+// Licensed under the MIT License. You may obtain a copy of the License at
+//
+//	https://opensource.org/licenses/MIT
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package cohere_test
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/ostafen/suricata/runtime/cohere"
+)
+
+func TestCohereEmbedder_Embed(t *testing.T) {
+	var batches []int
+
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path != "/v2/embed" || r.Header.Get("Authorization") != "Bearer key" {
+			t.Errorf("unexpected request %s %s", r.URL.Path, r.Header.Get("Authorization"))
+		}
+
+		var req struct {
+			Texts     []string `json:"texts"`
+			InputType string   `json:"input_type"`
+		}
+		if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+			t.Fatal(err)
+		}
+		if req.InputType != "search_query" {
+			t.Errorf("unexpected input type %q", req.InputType)
+		}
+		batches = append(batches, len(req.Texts))
+
+		vectors := make([][]float32, len(req.Texts))
+		for i, text := range req.Texts {
+			var n float32
+			fmt.Sscanf(text, "text %g", &n)
+			vectors[i] = []float32{n}
+		}
+		json.NewEncoder(w).Encode(map[string]any{"embeddings": map[string]any{"float": vectors}})
+	}))
+	defer srv.Close()
+
+	texts := make([]string, cohere.MaxBatchSize+4)
+	for i := range texts {
+		texts[i] = fmt.Sprintf("text %d", i)
+	}
+
+	vectors, err := cohere.NewEmbedder(srv.URL, "key", "embed-english-v3.0", cohere.InputTypeSearchQuery).Embed(context.Background(), texts)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	if len(batches) != 2 || batches[0] != cohere.MaxBatchSize || batches[1] != 4 {
+		t.Errorf("unexpected batches: %v", batches)
+	}
+	for i, v := range vectors {
+		if len(v) != 1 || v[0] != float32(i) {
+			t.Fatalf("unexpected vector #%d: %v", i, v)
+		}
+	}
+}
@@ -0,0 +1,133 @@
+// Copyright (c) 2025 Suricata Contributors
+// Original Author: Stefano Scafiti
+//
+// This file is part of Suricata: Type-Safe AI Agents for Go.
+//
+// Licensed under the MIT License. You may obtain a copy of the License at
+//
+//	https://opensource.org/licenses/MIT
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package runtime
+
+import (
+	"context"
+	"fmt"
+	"strings"
+)
+
+const summarizerInstructions = `You are a precise assistant that summarizes conversations between a user and an AI agent.
+Preserve every fact needed to continue the task: tool names, arguments, results, errors, identifiers and decisions taken so far.
+Do not add commentary. Reply with the summary only.`
+
+// HistoryCompactor keeps the chat history within a token budget by summarizing older turns.
+//
+// The first message, which carries the original prompt with its output format, and the
+// most recent messages are always kept verbatim; everything in between is replaced by a summary.
+type HistoryCompactor struct {
+	Invoker    Invoker // Invoker used to summarize. Nil uses the runtime invoker.
+	MaxTokens  int     // Estimated token budget above which history is compacted.
+	KeepRecent int     // Number of most recent messages kept verbatim. Zero means DefaultKeepRecent.
+}
+
+const DefaultKeepRecent = 4
+
+// EstimateTokens returns a rough estimate of the number of tokens in messages.
+func EstimateTokens(messages []Message) int {
+	return CountPromptTokens(HeuristicTokenizer, "", messages)
+}
+
+// Compact returns messages unchanged if they fit the budget, otherwise a shorter history
+// in which the middle part of the conversation has been replaced by a summary.
+func (c *HistoryCompactor) Compact(ctx context.Context, invoker Invoker, messages []Message) ([]Message, bool, error) {
+	if c.MaxTokens <= 0 || EstimateTokens(messages) <= c.MaxTokens {
+		return messages, false, nil
+	}
+
+	split := c.splitIndex(messages)
+	if split < 0 {
+		return messages, false, nil
+	}
+
+	if c.Invoker != nil {
+		invoker = c.Invoker
+	}
+
+	summary, err := invoker.Invoke(ctx, summarizerInstructions, []Message{
+		{Role: RoleUser, Content: summarizePrompt(messages[1:split])},
+	})
+	if err != nil {
+		return nil, false, fmt.Errorf("summarize history: %w", err)
+	}
+
+	compacted := make([]Message, 0, 2+len(messages)-split)
+	compacted = append(compacted, messages[0])
+	compacted = append(compacted, Message{Role: RoleAgent, Content: "[SUMMARY OF PREVIOUS STEPS]\n\n" + summary})
+	compacted = append(compacted, messages[split:]...)
+	return compacted, true, nil
+}
+
+// splitIndex returns the index of the first message kept verbatim after the summary,
+// or -1 if there is not enough history to summarize. The kept part must start with a
+// user or tool message, so that roles keep alternating once the summary is inserted.
+func (c *HistoryCompactor) splitIndex(messages []Message) int {
+	keep := c.KeepRecent
+	if keep <= 0 {
+		keep = DefaultKeepRecent
+	}
+
+	split := len(messages) - keep
+	for split < len(messages) && messages[split].Role != RoleUser && messages[split].Role != RoleTool {
+		split++
+	}
+
+	// At least two messages must be summarized for compaction to be worth it.
+	if split < 3 || split >= len(messages) {
+		return -1
+	}
+	return split
+}
+
+func summarizePrompt(messages []Message) string {
+	var sb strings.Builder
+	sb.WriteString("Summarize the following conversation:\n\n")
+	for _, m := range messages {
+		switch m.Role {
+		case RoleAgent:
+			sb.WriteString("AGENT: ")
+		case RoleTool:
+			sb.WriteString("TOOL: ")
+		default:
+			sb.WriteString("USER: ")
+		}
+		sb.WriteString(m.Text())
+		sb.WriteString("\n\n")
+	}
+	return sb.String()
+}
+
+func (chat *ChatSession) compact(ctx context.Context, messages []Message) ([]Message, error) {
+	if chat.compactor == nil {
+		return messages, nil
+	}
+
+	compacted, changed, err := chat.compactor.Compact(ctx, chat.invoker, messages)
+	if err != nil || !changed {
+		return messages, err
+	}
+
+	// Store the compacted history before discarding the old one, so that
+	// a failure never leaves the memory empty
+	if err := chat.memory.Append(ctx, compacted...); err != nil {
+		return nil, fmt.Errorf("store compacted history: %w", err)
+	}
+	if err := chat.memory.Truncate(ctx, len(compacted)); err != nil {
+		return nil, fmt.Errorf("truncate history: %w", err)
+	}
+	return compacted, nil
+}
@@ -0,0 +1,120 @@
+// Copyright (c) 2025 Suricata Contributors
+// Original Author: Stefano Scafiti
+//
+// This file is part of Suricata: Type-Safe AI Agents for Go.
+//
+// Licensed under the MIT License. You may obtain a copy of the License at
+//
+//	https://opensource.org/licenses/MIT
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+package runtime_test
+
+import (
+	"context"
+	"errors"
+	"strings"
+	"testing"
+
+	"github.com/ostafen/suricata/runtime"
+	"github.com/ostafen/suricata/runtime/runtimetest"
+)
+
+func TestHistoryCompactor_Compact(t *testing.T) {
+	long := strings.Repeat("x", 400)
+
+	messages := []runtime.Message{
+		{Role: runtime.RoleUser, Content: "prompt"},
+		{Role: runtime.RoleAgent, Content: long},
+		{Role: runtime.RoleUser, Content: long},
+		{Role: runtime.RoleAgent, Content: long},
+		{Role: runtime.RoleUser, Content: long},
+		{Role: runtime.RoleAgent, Content: "last"},
+	}
+
+	mock := runtimetest.NewInvoker(t).Respond("summary")
+	c := &runtime.HistoryCompactor{MaxTokens: 100, KeepRecent: 2}
+
+	compacted, changed, err := c.Compact(context.Background(), mock, messages)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if !changed {
+		t.Fatalf("expected history to be compacted")
+	}
+
+	if len(compacted) != 4 {
+		t.Fatalf("expected 4 messages, got %d", len(compacted))
+	}
+
+	if compacted[0].Content != "prompt" {
+		t.Errorf("expected original prompt to be kept, got %q", compacted[0].Content)
+	}
+
+	if compacted[1].Role != runtime.RoleAgent || !strings.Contains(compacted[1].Content, "summary") {
+		t.Errorf("expected agent summary message, got %+v", compacted[1])
+	}
+
+	if compacted[2].Role != runtime.RoleUser || compacted[3].Content != "last" {
+		t.Errorf("expected recent messages to be kept verbatim, got %+v", compacted[2:])
+	}
+}
+
+func TestHistoryCompactor_WithinBudget(t *testing.T) {
+	messages := []runtime.Message{
+		{Role: runtime.RoleUser, Content: "prompt"},
+		{Role: runtime.RoleAgent, Content: "reply"},
+	}
+
+	c := &runtime.HistoryCompactor{MaxTokens: 1000}
+
+	compacted, changed, err := c.Compact(context.Background(), runtimetest.NewInvoker(t), messages)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if changed || len(compacted) != len(messages) {
+		t.Errorf("expected history to be left untouched")
+	}
+}
+
+// appendFailingMemory fails to append several messages at once, as compaction does.
+type appendFailingMemory struct {
+	*runtime.InMemory
+}
+
+func (m appendFailingMemory) Append(ctx context.Context, msgs ...runtime.Message) error {
+	if len(msgs) > 1 {
+		return errors.New("storage unavailable")
+	}
+	return m.InMemory.Append(ctx, msgs...)
+}
+
+func TestChatSession_CompactFailure(t *testing.T) {
+	long := strings.Repeat("x", 400)
+
+	mem := appendFailingMemory{runtime.NewInMemory()}
+	for _, content := range []string{"prompt", long, long, long} {
+		if err := mem.InMemory.Append(context.Background(), runtime.Message{Role: runtime.RoleUser, Content: content}); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	sess := runtime.NewChatSessionWithMemory(runtimetest.NewInvoker(t).Respond("summary"), "", mem)
+	sess.SetCompactor(&runtime.HistoryCompactor{MaxTokens: 100, KeepRecent: 2})
+
+	if _, err := sess.Invoke(context.Background(), "next"); err == nil {
+		t.Fatalf("expected the failure to store the compacted history to be returned")
+	}
+
+	history, err := mem.History(context.Background())
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(history) != 5 || history[0].Content != "prompt" || history[4].Content != "next" {
+		t.Errorf("expected the history to be preserved, got %d messages", len(history))
+	}
+}
@@ -0,0 +1,339 @@
+// Copyright (c) 2025 Suricata Contributors
+// Original Author: Stefano Scafiti
+//
+// This file is part of Suricata: Type-Safe AI Agents for Go.
+//
+// Licensed under the MIT License. You may obtain a copy of the License at
+//
+//	https://opensource.org/licenses/MIT
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+// Package config loads the providers and models of agents from a YAML file, such as
+// suricata.yaml, so that applications and the suricata CLI share the same wiring:
+//
+//	cfg, err := config.Load("suricata.yaml")
+//	invoker, err := cfg.Invoker("helloAgent")
+//	agent := hello.NewHelloAgent(invoker, &tools{}, cfg.Options("helloAgent")...)
+package config
+
+import (
+	"fmt"
+	"os"
+	"slices"
+	"time"
+
+	"github.com/ostafen/suricata/runtime"
+	"github.com/ostafen/suricata/runtime/anthropic"
+	"github.com/ostafen/suricata/runtime/ollama"
+	"github.com/ostafen/suricata/runtime/openaicompat"
+	openai "github.com/sashabaranov/go-openai"
+	"gopkg.in/yaml.v3"
+)
+
+// Provider types.
+const (
+	Ollama       = "ollama"
+	OpenAI       = "openai"
+	OpenAICompat = "openaicompat"
+	Anthropic    = "anthropic"
+)
+
+var providerTypes = []string{Ollama, OpenAI, OpenAICompat, Anthropic}
+
+const (
+	openAIBaseURL          = "https://api.openai.com/v1"
+	defaultAnthropicTokens = 4096
+)
+
+// Config is the runtime configuration of a set of agents.
+type Config struct {
+	Providers map[string]Provider `yaml:"providers"`
+	Default   Model               `yaml:"default"` // Model of the agents without an entry in Agents
+	// Agents overrides the default model of single agents: unset fields are taken from Default.
+	Agents map[string]Model `yaml:"agents"`
+}
+
+// Provider is the connection to an LLM provider.
+type Provider struct {
+	Type    string `yaml:"type"` // One of ollama, openai, openaicompat and anthropic. Defaults to the name of the provider.
+	BaseURL string `yaml:"base_url"`
+	APIKey  string `yaml:"api_key"` // Defaults to OPENAI_API_KEY or ANTHROPIC_API_KEY, for the openai and anthropic types
+
+	Timeout time.Duration `yaml:"timeout"` // Timeout of each request to the provider, e.g. 60s
+	Proxy   string        `yaml:"proxy"`   // URL of the proxy to the provider
+	CAFile  string        `yaml:"ca_file"` // PEM file of additional trusted certificate authorities
+	Retries int           `yaml:"retries"` // Retries of requests failing with transient errors. Zero disables them.
+
+	Headers map[string]string `yaml:"headers"` // Headers set on every request to the provider
+}
+
+// Model selects the provider and the model running an agent.
+type Model struct {
+	// Provider is the name of an entry of Config.Providers, or a provider type, used with its
+	// default settings. Defaults to the only configured provider, or to ollama.
+	Provider    string   `yaml:"provider"`
+	Model       string   `yaml:"model"`
+	Temperature *float64 `yaml:"temperature"`
+	MaxTokens   int      `yaml:"max_tokens"`
+	// InlineSchemas inlines the definitions referenced by the schemas sent to the model,
+	// for models which do not follow references (see runtime.WithInlineSchemas).
+	InlineSchemas *bool `yaml:"inline_schemas"`
+	// ContextSize is the context window of the model, in tokens. It sets the num_ctx option of
+	// ollama models, and enables warnings about prompts exceeding it (see runtime.WithContextCheck).
+	ContextSize int `yaml:"context_size"`
+}
+
+// Load reads the config file at path. See Parse.
+func Load(path string) (*Config, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+
+	cfg, err := Parse(data)
+	if err != nil {
+		return nil, fmt.Errorf("%s: %w", path, err)
+	}
+	return cfg, nil
+}
+
+// Parse decodes a YAML config. References to environment variables, such as
+// ${OPENAI_API_KEY}, are expanded, so that secrets are kept out of the file.
+func Parse(data []byte) (*Config, error) {
+	cfg := &Config{}
+	if err := yaml.Unmarshal([]byte(os.ExpandEnv(string(data))), cfg); err != nil {
+		return nil, err
+	}
+
+	if err := cfg.validate(); err != nil {
+		return nil, err
+	}
+	return cfg, nil
+}
+
+func (cfg *Config) validate() error {
+	for _, name := range sortedKeys(cfg.Providers) {
+		if t := cfg.Providers[name].typ(name); !slices.Contains(providerTypes, t) {
+			return fmt.Errorf("provider %q: unknown type %q", name, t)
+		}
+	}
+
+	if _, err := cfg.provider(cfg.Default.Provider); err != nil {
+		return fmt.Errorf("default: %w", err)
+	}
+	for _, name := range sortedKeys(cfg.Agents) {
+		if _, err := cfg.provider(cfg.Agents[name].Provider); err != nil {
+			return fmt.Errorf("agent %q: %w", name, err)
+		}
+	}
+	return nil
+}
+
+// ApplyEnv overrides the default model with the SURICATA_PROVIDER and SURICATA_MODEL
+// environment variables, and the settings of its provider with SURICATA_BASE_URL and
+// SURICATA_API_KEY, e.g. to switch model in CI without editing the config file.
+func (cfg *Config) ApplyEnv() {
+	if v := os.Getenv("SURICATA_PROVIDER"); v != "" {
+		cfg.Default.Provider = v
+	}
+	if v := os.Getenv("SURICATA_MODEL"); v != "" {
+		cfg.Default.Model = v
+	}
+
+	baseURL, apiKey := os.Getenv("SURICATA_BASE_URL"), os.Getenv("SURICATA_API_KEY")
+	if baseURL == "" && apiKey == "" {
+		return
+	}
+
+	name := cfg.providerName(cfg.Default.Provider)
+	p := cfg.Providers[name]
+	if baseURL != "" {
+		p.BaseURL = baseURL
+	}
+	if apiKey != "" {
+		p.APIKey = apiKey
+	}
+
+	if cfg.Providers == nil {
+		cfg.Providers = make(map[string]Provider)
+	}
+	cfg.Providers[name] = p
+}
+
+// Model returns the model of the given agent: its entry of Agents, completed by Default.
+func (cfg *Config) Model(agent string) Model {
+	m := cfg.Default
+
+	override := cfg.Agents[agent]
+	if override.Provider != "" {
+		m.Provider = override.Provider
+	}
+	if override.Model != "" {
+		m.Model = override.Model
+	}
+	if override.Temperature != nil {
+		m.Temperature = override.Temperature
+	}
+	if override.MaxTokens > 0 {
+		m.MaxTokens = override.MaxTokens
+	}
+	if override.InlineSchemas != nil {
+		m.InlineSchemas = override.InlineSchemas
+	}
+	if override.ContextSize > 0 {
+		m.ContextSize = override.ContextSize
+	}
+
+	m.Provider = cfg.providerName(m.Provider)
+	return m
+}
+
+// Options returns the runtime options applying the temperature, max tokens, schema inlining and
+// context size of the model of agent, to be passed to its constructor. Settings of the spec take precedence.
+func (cfg *Config) Options(agent string) []runtime.Option {
+	m := cfg.Model(agent)
+
+	var opts []runtime.Option
+	if m.Temperature != nil || m.MaxTokens > 0 {
+		opts = append(opts, runtime.WithDefaultModelOptions(runtime.ModelOptions{
+			Temperature: m.Temperature,
+			MaxTokens:   m.MaxTokens,
+		}))
+	}
+	if m.InlineSchemas != nil && *m.InlineSchemas {
+		opts = append(opts, runtime.WithInlineSchemas())
+	}
+	if m.ContextSize > 0 {
+		opts = append(opts, runtime.WithContextCheck(runtime.ContextCheck{Size: m.ContextSize}))
+	}
+	return opts
+}
+
+// Invoker returns a new invoker calling the model of the given agent.
+func (cfg *Config) Invoker(agent string) (runtime.Invoker, error) {
+	m := cfg.Model(agent)
+	if m.Model == "" {
+		if _, ok := cfg.Agents[agent]; ok {
+			return nil, fmt.Errorf("agent %q: model is required", agent)
+		}
+		return nil, fmt.Errorf("default: model is required")
+	}
+
+	p, err := cfg.provider(m.Provider)
+	if err != nil {
+		return nil, err
+	}
+	return newInvoker(m.Provider, p, m)
+}
+
+// providerName returns the name of the provider selected by name, resolving the defaults.
+func (cfg *Config) providerName(name string) string {
+	if name != "" {
+		return name
+	}
+	if len(cfg.Providers) == 1 {
+		for name := range cfg.Providers {
+			return name
+		}
+	}
+	return Ollama
+}
+
+// provider returns the provider selected by name: an entry of Providers, or a provider type.
+func (cfg *Config) provider(name string) (Provider, error) {
+	name = cfg.providerName(name)
+	if p, ok := cfg.Providers[name]; ok {
+		p.Type = p.typ(name)
+		return p, nil
+	}
+	if slices.Contains(providerTypes, name) {
+		return Provider{Type: name}, nil
+	}
+	return Provider{}, fmt.Errorf("unknown provider %q", name)
+}
+
+func (p Provider) typ(name string) string {
+	if p.Type != "" {
+		return p.Type
+	}
+	return name
+}
+
+func newInvoker(name string, p Provider, m Model) (runtime.Invoker, error) {
+	var retry runtime.BackoffPolicy
+	if p.Retries > 0 {
+		retry = runtime.DefaultBackoffPolicy()
+		retry.MaxAttempts = p.Retries + 1
+	}
+
+	client, err := runtime.NewHTTPClient(runtime.HTTPConfig{
+		Timeout:  p.Timeout,
+		Retry:    retry,
+		ProxyURL: p.Proxy,
+		CAFile:   p.CAFile,
+		Headers:  p.Headers,
+	})
+	if err != nil {
+		return nil, fmt.Errorf("provider %q: %w", name, err)
+	}
+
+	switch p.Type {
+	case Ollama:
+		baseURL := p.BaseURL
+		if baseURL == "" {
+			baseURL = ollama.DefaultBaseURL
+		}
+		opts := ollama.DefaultOptions()
+		if m.ContextSize > 0 {
+			opts.NumCtx = m.ContextSize
+		}
+		inv := ollama.NewInvoker(baseURL, m.Model, opts)
+		inv.HTTPClient = client
+		return inv, nil
+	case OpenAI, OpenAICompat:
+		baseURL := p.BaseURL
+		if baseURL == "" && p.Type == OpenAI {
+			baseURL = openAIBaseURL
+		}
+		if baseURL == "" {
+			return nil, fmt.Errorf("provider %q: base_url is required by type %q", name, p.Type)
+		}
+
+		apiKey := p.APIKey
+		if apiKey == "" && p.Type == OpenAI {
+			apiKey = os.Getenv("OPENAI_API_KEY")
+		}
+		clientCfg := openai.DefaultConfig(apiKey)
+		clientCfg.BaseURL = baseURL
+		clientCfg.HTTPClient = client
+		return openaicompat.NewInvokerWithConfig(clientCfg, m.Model), nil
+	case Anthropic:
+		apiKey := p.APIKey
+		if apiKey == "" {
+			apiKey = os.Getenv("ANTHROPIC_API_KEY")
+		}
+
+		maxTokens := m.MaxTokens
+		if maxTokens == 0 {
+			maxTokens = defaultAnthropicTokens
+		}
+		inv := anthropic.NewInvoker(apiKey, anthropic.Model(m.Model), maxTokens)
+		inv.HTTPClient = client
+		return inv, nil
+	}
+	return nil, fmt.Errorf("provider %q: unknown type %q", name, p.Type)
+}
+
+func sortedKeys[T any](m map[string]T) []string {
+	keys := make([]string, 0, len(m))
+	for k := range m {
+		keys = append(keys, k)
+	}
+	slices.Sort(keys)
+	return keys
+}
@@ -0,0 +1,163 @@
+// Copyright (c) 2025 Suricata Contributors
+// Original Author: Stefano Scafiti
+//
+// This file is part of Suricata: Type-Safe AI Agents for Go.
+//
+// Licensed under the MIT License. You may obtain a copy of the License at
+//
+//	https://opensource.org/licenses/MIT
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package config_test
+
+import (
+	"fmt"
+	"testing"
+
+	"github.com/ostafen/suricata/runtime"
+	"github.com/ostafen/suricata/runtime/config"
+	"github.com/ostafen/suricata/runtime/openaicompat"
+)
+
+const testConfig = `
+providers:
+  local:
+    type: ollama
+  gateway:
+    type: openaicompat
+    base_url: http://gateway.local/v1
+    api_key: ${TEST_GATEWAY_KEY}
+    retries: 2
+default:
+  provider: local
+  model: llama3.2
+  temperature: 0.2
+agents:
+  plannerAgent:
+    provider: gateway
+    model: gpt-4o
+  writerAgent:
+    max_tokens: 512
+    inline_schemas: true
+    context_size: 8192
+  reviewAgent:
+    provider: anthropic
+    model: claude-sonnet
+`
+
+func TestParse(t *testing.T) {
+	t.Setenv("TEST_GATEWAY_KEY", "secret")
+
+	cfg, err := config.Parse([]byte(testConfig))
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	if key := cfg.Providers["gateway"].APIKey; key != "secret" {
+		t.Errorf("api key not expanded: %q", key)
+	}
+	if retries := cfg.Providers["gateway"].Retries; retries != 2 {
+		t.Errorf("expected 2 retries, got %d", retries)
+	}
+
+	m := cfg.Model("plannerAgent")
+	if m.Provider != "gateway" || m.Model != "gpt-4o" || m.Temperature == nil || *m.Temperature != 0.2 {
+		t.Errorf("unexpected model of plannerAgent: %+v", m)
+	}
+
+	m = cfg.Model("writerAgent")
+	if m.Provider != "local" || m.Model != "llama3.2" || m.MaxTokens != 512 || m.ContextSize != 8192 {
+		t.Errorf("unexpected model of writerAgent: %+v", m)
+	}
+
+	if m := cfg.Model("otherAgent"); m.Provider != "local" || m.Model != "llama3.2" || m.MaxTokens != 0 {
+		t.Errorf("unexpected default model: %+v", m)
+	}
+
+	if opts := cfg.Options("writerAgent"); len(opts) != 3 {
+		t.Errorf("expected model options, schema inlining and context check, got %d options", len(opts))
+	}
+}
+
+func TestInvoker(t *testing.T) {
+	cfg, err := config.Parse([]byte(testConfig))
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	cases := map[string]string{
+		"plannerAgent": "*openaicompat.OpenAICompatInvoker",
+		"writerAgent":  "*ollama.OllamaInvoker",
+		"reviewAgent":  "*anthropic.AnthropicInvoker",
+	}
+	for agent, want := range cases {
+		inv, err := cfg.Invoker(agent)
+		if err != nil {
+			t.Fatalf("%s: unexpected error: %v", agent, err)
+		}
+		if got := fmt.Sprintf("%T", inv); got != want {
+			t.Errorf("%s: expected %s, got %s", agent, want, got)
+		}
+	}
+
+	inv, err := cfg.Invoker("writerAgent")
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if size := inv.(runtime.ContextSizer).ContextSize(); size != 8192 {
+		t.Errorf("expected the context size of the config, got %d", size)
+	}
+}
+
+func TestParse_Errors(t *testing.T) {
+	cases := map[string]string{
+		"unknown type":     "providers:\n  x:\n    type: foo\n",
+		"unknown provider": "agents:\n  a:\n    provider: missing\n",
+		"bad yaml":         "providers: [",
+	}
+	for name, data := range cases {
+		if _, err := config.Parse([]byte(data)); err == nil {
+			t.Errorf("%s: expected error", name)
+		}
+	}
+
+	cfg, err := config.Parse(nil)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if _, err := cfg.Invoker("a"); err == nil {
+		t.Error("expected error for missing model")
+	}
+}
+
+func TestApplyEnv(t *testing.T) {
+	t.Setenv("SURICATA_PROVIDER", "openaicompat")
+	t.Setenv("SURICATA_MODEL", "qwen")
+	t.Setenv("SURICATA_BASE_URL", "http://localhost:8000/v1")
+
+	cfg, err := config.Parse(nil)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	cfg.ApplyEnv()
+
+	if m := cfg.Model("a"); m.Provider != "openaicompat" || m.Model != "qwen" {
+		t.Errorf("unexpected model: %+v", m)
+	}
+	if url := cfg.Providers["openaicompat"].BaseURL; url != "http://localhost:8000/v1" {
+		t.Errorf("unexpected base url: %q", url)
+	}
+
+	inv, err := cfg.Invoker("a")
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if _, ok := inv.(*openaicompat.OpenAICompatInvoker); !ok {
+		t.Errorf("unexpected invoker %T", inv)
+	}
+}
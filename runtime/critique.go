@@ -0,0 +1,118 @@
+// Copyright (c) 2025 Suricata Contributors
+// Original Author: Stefano Scafiti
+//
+// This file is part of Suricata: Type-Safe AI Agents for Go.
+//
+// Licensed under the MIT License. You may obtain a copy of the License at
+//
+//	https://opensource.org/licenses/MIT
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package runtime
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"strings"
+)
+
+const critiquePrompt = `Review your last response before it is returned. Check it against the task and the OUTPUT FORMAT:
+every value must be supported by the input and the tool results, no information may be missing or made up,
+and the values must be consistent with each other.
+
+Reply with a JSON object only, in the format:
+{"ok": true or false, "issues": ["each problem found, if any"]}`
+
+// WithCritique makes the runtime ask the model to review its final output against the task
+// and the output schema, before returning it. If the review finds issues, the model is asked
+// once to correct its output, which is validated again. Free-text outputs are not reviewed.
+// It costs an extra model call per run, and improves the accuracy of extractions on small models.
+func WithCritique() Option {
+	return func(r *Runtime) {
+		r.critique = true
+	}
+}
+
+// critiqueError reports the issues found by the model reviewing its own output.
+// It matches ErrInvalidOutput with errors.Is, so that the output is repaired.
+type critiqueError struct {
+	issues []string
+}
+
+func (e *critiqueError) Error() string {
+	return fmt.Sprintf("%s: review found: %s", ErrInvalidOutput, strings.Join(e.issues, "; "))
+}
+
+func (e *critiqueError) Unwrap() error {
+	return ErrInvalidOutput
+}
+
+// finalize post-processes the decoded output of req and, with WithCritique, has the model
+// review it, unless it is the correction of an earlier review.
+func (r *Runtime) finalize(ctx context.Context, req *Request, sess *ChatSession, st *runState) error {
+	if err := r.postProcess(ctx, req); err != nil {
+		return err
+	}
+	if !r.critique || st.critiqued || req.isTextOutput() {
+		return nil
+	}
+	st.critiqued = true
+
+	issues, err := r.review(ctx, req, sess, st)
+	if err != nil || len(issues) == 0 {
+		return err
+	}
+	return &critiqueError{issues: issues}
+}
+
+// review asks the model to review its last response, and returns the issues it found.
+// Replies which cannot be decoded accept the output. The review counts towards the budget of the run.
+func (r *Runtime) review(ctx context.Context, req *Request, sess *ChatSession, st *runState) ([]string, error) {
+	history, err := sess.History(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("load history: %w", err)
+	}
+
+	// The review runs on a copy of the history, so that it is not saved to the memory of the request
+	memory := NewInMemory()
+	if err := memory.Append(ctx, history...); err != nil {
+		return nil, err
+	}
+
+	reviewSess := r.newSession(req, memory)
+	out, err := reviewSess.Invoke(ctx, critiquePrompt)
+	if err != nil {
+		return nil, fmt.Errorf("invoke session for review: %w", err)
+	}
+	r.hooks.llmResponse(ctx, out)
+	recording(ctx).add(TranscriptStep{Kind: StepResponse, Content: out})
+
+	if err := r.spend(ctx, req, reviewSess, st); err != nil {
+		return nil, err
+	}
+
+	for _, candidate := range ExtractJSONCandidates(out) {
+		var verdict struct {
+			OK     bool     `json:"ok"`
+			Issues []string `json:"issues"`
+		}
+		if json.Unmarshal([]byte(candidate), &verdict) != nil {
+			continue
+		}
+
+		if verdict.OK {
+			return nil, nil
+		}
+		if len(verdict.Issues) == 0 {
+			return []string{"the response does not fulfill the task"}, nil
+		}
+		return verdict.Issues, nil
+	}
+	return nil, nil
+}
@@ -0,0 +1,159 @@
+// Copyright (c) 2025 Suricata Contributors
+// Original Author: Stefano Scafiti
+//
+// This file is part of Suricata: Type-Safe AI Agents for Go.
+//
+// Licensed under the MIT License. You may obtain a copy of the License at
+//
+//	https://opensource.org/licenses/MIT
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package runtime_test
+
+import (
+	"context"
+	"errors"
+	"strings"
+	"testing"
+
+	"github.com/ostafen/suricata/runtime"
+)
+
+func TestCritique(t *testing.T) {
+	type Input struct {
+		Text string `json:"text"`
+	}
+	type Output struct {
+		CheckIn  string `json:"check_in"`
+		CheckOut string `json:"check_out"`
+	}
+
+	run := func(verdict string) (Output, []runtime.Message, int) {
+		var calls int
+		inv := runtime.InvokerFunc(func(ctx context.Context, system string, messages []runtime.Message) (string, error) {
+			calls++
+			last := messages[len(messages)-1].Content
+			switch {
+			case strings.HasPrefix(last, "Review your last response"):
+				return verdict, nil
+			case strings.Contains(last, "check-in after check-out"):
+				return `{"check_in": "2025-06-01", "check_out": "2025-06-05"}`, nil
+			}
+			return `{"check_in": "2025-06-05", "check_out": "2025-06-01"}`, nil
+		})
+
+		var out Output
+		memory := runtime.NewInMemory()
+		err := runtime.NewRuntime(inv, runtime.WithCritique()).Invoke(context.Background(), runtime.Request{
+			PromptTemplate: "{{.Text}}",
+			Input:          &Input{Text: "from June 1st to June 5th"},
+			InputSchema:    runtime.NewSchema(`{"type":"object"}`),
+			Output:         &out,
+			OutputSchema:   runtime.NewSchema(`{"type":"object"}`),
+			Memory:         memory,
+		})
+		if err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+
+		history, _ := memory.History(context.Background())
+		return out, history, calls
+	}
+
+	out, history, calls := run(`{"ok": false, "issues": ["check-in after check-out"]}`)
+	if out.CheckIn != "2025-06-01" || calls != 3 {
+		t.Errorf("expected the corrected output after 3 calls, got %+v after %d calls", out, calls)
+	}
+	for _, msg := range history {
+		if strings.HasPrefix(msg.Content, "Review your last response") {
+			t.Error("the review was saved to the memory of the request")
+		}
+	}
+
+	out, _, calls = run(`{"ok": true, "issues": []}`)
+	if out.CheckIn != "2025-06-05" || calls != 2 {
+		t.Errorf("expected the reviewed output after 2 calls, got %+v after %d calls", out, calls)
+	}
+
+	out, _, calls = run(`not sure`)
+	if out.CheckIn != "2025-06-05" || calls != 2 {
+		t.Errorf("expected undecodable reviews to accept the output, got %+v after %d calls", out, calls)
+	}
+}
+
+func TestCritique_Budget(t *testing.T) {
+	inv := runtime.InvokerFunc(func(ctx context.Context, system string, messages []runtime.Message) (string, error) {
+		if strings.HasPrefix(messages[len(messages)-1].Content, "Review your last response") {
+			return `{"ok": true, "issues": []}` + strings.Repeat(" ", 4000), nil
+		}
+		return `{"answer": "42"}`, nil
+	})
+
+	rt := runtime.NewRuntime(inv, runtime.WithCritique(), runtime.WithBudget(runtime.Budget{MaxTokens: 300}))
+	err := rt.Invoke(context.Background(), runtime.Request{
+		PromptTemplate: "What is the answer?",
+		Input:          map[string]any{},
+		InputSchema:    runtime.NewSchema(`{"type":"object"}`),
+		Output:         &map[string]any{},
+		OutputSchema:   runtime.NewSchema(`{"type":"object"}`),
+	})
+
+	var budgetErr *runtime.BudgetError
+	if !errors.As(err, &budgetErr) || budgetErr.Limit != runtime.BudgetTokens {
+		t.Errorf("expected the review to exceed the token budget, got %v", err)
+	}
+}
+
+func TestCritique_Resume(t *testing.T) {
+	var calls int
+	inv := runtime.InvokerFunc(func(ctx context.Context, system string, messages []runtime.Message) (string, error) {
+		calls++
+		switch last := messages[len(messages)-1].Content; {
+		case strings.HasPrefix(last, "Review your last response"):
+			return `{"ok": false, "issues": ["wrong answer"]}`, nil
+		case strings.Contains(last, "wrong answer"):
+			return `{"answer": "42"}`, nil
+		}
+		return `{"answer": "41"}`, nil
+	})
+
+	// The corrected output fails to be stored once, after being checkpointed
+	var stored bool
+	store := runtime.PostProcessorFunc(func(ctx context.Context, output any) error {
+		if (*output.(*map[string]any))["answer"] == "42" && !stored {
+			stored = true
+			return errors.New("disk full")
+		}
+		return nil
+	})
+
+	newRequest := func() runtime.Request {
+		return runtime.Request{
+			PromptTemplate: "What is the answer?",
+			Input:          map[string]any{},
+			InputSchema:    runtime.NewSchema(`{"type":"object"}`),
+			Output:         &map[string]any{},
+			OutputSchema:   runtime.NewSchema(`{"type":"object"}`),
+			PostProcessors: []runtime.PostProcessor{store},
+		}
+	}
+
+	rt := runtime.NewRuntime(inv, runtime.WithCritique(), runtime.WithCheckpoints(runtime.NewInMemoryCheckpointStore()))
+	if err := rt.Invoke(runtime.WithRequestID(context.Background(), "run-1"), newRequest()); err == nil {
+		t.Fatal("expected error")
+	}
+
+	// The resumed run does not review the corrected output again
+	req := newRequest()
+	if err := rt.Resume(context.Background(), "run-1", req); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if answer := (*req.Output.(*map[string]any))["answer"]; answer != "42" || calls != 3 {
+		t.Errorf("expected the corrected output after 3 calls, got %v after %d calls", answer, calls)
+	}
+}
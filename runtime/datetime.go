@@ -0,0 +1,195 @@
+// Copyright (c) 2025 Suricata Contributors
+// Original Author: Stefano Scafiti
+//
+// This file is part of Suricata: Type-Safe AI Agents for Go.
+//
+// Licensed under the MIT License. You may obtain a copy of the License at
+//
+//	https://opensource.org/licenses/MIT
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package runtime
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"regexp"
+	"strings"
+	"time"
+
+	"github.com/xeipuuv/gojsonschema"
+)
+
+// timeLayouts are the layouts accepted by ParseTime, besides RFC3339.
+// Models often drop the time zone, or write dates the way a human would.
+var timeLayouts = []string{
+	time.RFC3339Nano,
+	"2006-01-02T15:04:05",
+	"2006-01-02T15:04",
+	"2006-01-02 15:04:05Z07:00",
+	"2006-01-02 15:04:05",
+	"2006-01-02 15:04",
+	"2006-01-02",
+	"2006/01/02",
+	"2 January 2006",
+	"2 Jan 2006",
+	"January 2, 2006",
+	"January 2 2006",
+	"Jan 2, 2006",
+	"Jan 2 2006",
+	"Monday, 2 January 2006",
+	"Monday, January 2, 2006",
+}
+
+var ordinalSuffix = regexp.MustCompile(`\b(\d{1,2})(st|nd|rd|th)\b`)
+
+// ParseTime parses a date or timestamp in RFC3339 or in one of the formats commonly
+// produced by models, such as "2025-08-15" or "15 August 2025".
+// Values without a time zone are interpreted as UTC.
+func ParseTime(s string) (time.Time, error) {
+	s = strings.TrimSpace(s)
+	s = ordinalSuffix.ReplaceAllString(s, "$1")
+
+	for _, layout := range timeLayouts {
+		if t, err := time.Parse(layout, s); err == nil {
+			return t, nil
+		}
+	}
+	return time.Time{}, fmt.Errorf("unrecognized date format %q", s)
+}
+
+// normalizeFormats rewrites the values of data which the schema declares with format "date-time"
+// to RFC3339, the ones with format "decimal" to strings, so that they validate and decode
+// into a time.Time or a Decimal, and UUIDs to their lowercase canonical form.
+// Data is returned unchanged if nothing needs to be rewritten.
+func normalizeFormats(data []byte, schema gojsonschema.JSONLoader) []byte {
+	doc, err := schema.LoadJSON()
+	if err != nil {
+		return data
+	}
+
+	dec := json.NewDecoder(bytes.NewReader(data))
+	dec.UseNumber()
+
+	var value any
+	if err := dec.Decode(&value); err != nil {
+		return data
+	}
+
+	value, changed := normalizeValue(doc, doc, value)
+	if !changed {
+		return data
+	}
+
+	normalized, err := json.Marshal(value)
+	if err != nil {
+		return data
+	}
+	return normalized
+}
+
+// normalizeUUID lowercases a UUID and strips the braces or the "urn:uuid:" prefix
+// some models wrap it in, such as "{3F2504E0-4F89-11D3-9A0C-0305E82C3301}".
+func normalizeUUID(s string) string {
+	u := strings.ToLower(strings.TrimSpace(s))
+	u = strings.TrimPrefix(u, "urn:uuid:")
+	if strings.HasPrefix(u, "{") && strings.HasSuffix(u, "}") {
+		u = u[1 : len(u)-1]
+	}
+	return u
+}
+
+// normalizeValue normalizes value according to its schema. References are resolved against root.
+func normalizeValue(root, schema, value any) (any, bool) {
+	s, ok := schema.(map[string]any)
+	if !ok {
+		return value, false
+	}
+	if ref, ok := s["$ref"].(string); ok {
+		return normalizeValue(root, resolveRef(root, ref), value)
+	}
+
+	switch v := value.(type) {
+	case json.Number:
+		if s["format"] != "decimal" {
+			return v, false
+		}
+		d, err := ParseDecimal(string(v))
+		if err != nil {
+			return v, false
+		}
+		return d.String(), true
+	case string:
+		normalized := v
+		switch s["format"] {
+		case "date-time":
+			if _, err := time.Parse(time.RFC3339, v); err == nil {
+				return v, false
+			}
+			if t, err := ParseTime(v); err == nil {
+				normalized = t.Format(time.RFC3339)
+			}
+		case "decimal":
+			if d, err := ParseDecimal(v); err == nil {
+				normalized = d.String()
+			}
+		case "uuid":
+			normalized = normalizeUUID(v)
+		}
+		return normalized, normalized != v
+	case []any:
+		changed := false
+		for i, item := range v {
+			var c bool
+			v[i], c = normalizeValue(root, s["items"], item)
+			changed = changed || c
+		}
+		return v, changed
+	case map[string]any:
+		props, _ := s["properties"].(map[string]any)
+
+		changed := false
+		for key, item := range v {
+			itemSchema, ok := props[key]
+			if !ok {
+				itemSchema = s["additionalProperties"]
+			}
+
+			var c bool
+			v[key], c = normalizeValue(root, itemSchema, item)
+			changed = changed || c
+		}
+		return v, changed
+	}
+	return value, false
+}
+
+// resolveRef returns the schema pointed by a local reference, such as "#/$defs/Category",
+// or nil if it cannot be resolved.
+func resolveRef(root any, ref string) any {
+	pointer, ok := strings.CutPrefix(ref, "#")
+	if !ok {
+		return nil
+	}
+
+	schema := root
+	for _, token := range strings.Split(strings.TrimPrefix(pointer, "/"), "/") {
+		if token == "" {
+			continue
+		}
+		token = strings.NewReplacer("~1", "/", "~0", "~").Replace(token)
+
+		m, ok := schema.(map[string]any)
+		if !ok {
+			return nil
+		}
+		schema = m[token]
+	}
+	return schema
+}
@@ -0,0 +1,144 @@
+// Copyright (c) 2025 Suricata Contributors
+// Original Author: Stefano Scafiti
+//
+// This file is part of Suricata: Type-Safe AI Agents for Go.
+//
+// Licensed under the MIT License. You may obtain a copy of the License at
+//
+//	https://opensource.org/licenses/MIT
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+package runtime_test
+
+import (
+	"testing"
+	"time"
+
+	"github.com/ostafen/suricata/runtime"
+	"github.com/xeipuuv/gojsonschema"
+)
+
+func TestParseTime(t *testing.T) {
+	want := time.Date(2025, time.August, 15, 0, 0, 0, 0, time.UTC)
+
+	for _, s := range []string{
+		"2025-08-15",
+		"2025-08-15T00:00:00Z",
+		"2025/08/15",
+		"15 August 2025",
+		"15th August 2025",
+		"August 15, 2025",
+		"Aug 15 2025",
+		"Friday, 15 August 2025",
+	} {
+		got, err := runtime.ParseTime(s)
+		if err != nil {
+			t.Errorf("ParseTime(%q): unexpected error: %v", s, err)
+			continue
+		}
+		if !got.Equal(want) {
+			t.Errorf("ParseTime(%q) = %v, want %v", s, got, want)
+		}
+	}
+
+	if _, err := runtime.ParseTime("next friday"); err == nil {
+		t.Errorf("expected error for unrecognized format")
+	}
+}
+
+func TestUnmarshalValidate_DateTime(t *testing.T) {
+	schema := gojsonschema.NewStringLoader(`{
+		"type": "object",
+		"properties": {
+			"departure": {"type": "string", "format": "date-time"},
+			"stops": {"type": "array", "items": {"type": "string", "format": "date-time"}}
+		},
+		"required": ["departure"]
+	}`)
+
+	var out struct {
+		Departure time.Time   `json:"departure"`
+		Stops     []time.Time `json:"stops"`
+	}
+
+	data := []byte(`{"departure": "15 August 2025", "stops": ["2025-08-16", "2025-08-17T10:30:00Z"]}`)
+	if err := runtime.UnmarshalValidate(data, &out, schema); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	if want := time.Date(2025, time.August, 15, 0, 0, 0, 0, time.UTC); !out.Departure.Equal(want) {
+		t.Errorf("expected departure %v, got %v", want, out.Departure)
+	}
+	if len(out.Stops) != 2 || out.Stops[1].Hour() != 10 {
+		t.Errorf("unexpected stops: %v", out.Stops)
+	}
+
+	if err := runtime.UnmarshalValidate([]byte(`{"departure": "someday"}`), &out, schema); err == nil {
+		t.Errorf("expected validation error for unparsable date-time")
+	}
+}
+
+func TestUnmarshalValidate_UUID(t *testing.T) {
+	schema := gojsonschema.NewStringLoader(`{
+		"type": "object",
+		"properties": {
+			"id": {"type": "string", "format": "uuid"},
+			"link": {"type": "string", "format": "uri"}
+		}
+	}`)
+
+	var out struct {
+		ID   string `json:"id"`
+		Link string `json:"link"`
+	}
+
+	data := []byte(`{"id": "{3F2504E0-4F89-11D3-9A0C-0305E82C3301}", "link": "https://example.com/a"}`)
+	if err := runtime.UnmarshalValidate(data, &out, schema); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if out.ID != "3f2504e0-4f89-11d3-9a0c-0305e82c3301" {
+		t.Errorf("expected the canonical UUID, got %s", out.ID)
+	}
+
+	for _, data := range []string{`{"id": "42"}`, `{"link": "example.com/a"}`} {
+		if err := runtime.UnmarshalValidate([]byte(data), &out, schema); err == nil {
+			t.Errorf("expected validation error for %s", data)
+		}
+	}
+}
+
+func TestUnmarshalValidate_Refs(t *testing.T) {
+	schema := gojsonschema.NewStringLoader(`{
+		"type": "object",
+		"properties": {"root": {"$ref": "#/$defs/Event"}},
+		"$defs": {
+			"Event": {
+				"type": "object",
+				"properties": {
+					"at": {"type": "string", "format": "date-time"},
+					"next": {"$ref": "#/$defs/Event"}
+				}
+			}
+		}
+	}`)
+
+	type Event struct {
+		At   time.Time `json:"at"`
+		Next *Event    `json:"next"`
+	}
+	var out struct {
+		Root Event `json:"root"`
+	}
+
+	data := []byte(`{"root": {"at": "2025-08-15", "next": {"at": "16 August 2025"}}}`)
+	if err := runtime.UnmarshalValidate(data, &out, schema); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if out.Root.Next == nil || out.Root.Next.At.Day() != 16 {
+		t.Errorf("expected nested dates to be normalized, got %+v", out.Root)
+	}
+}
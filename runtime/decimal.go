@@ -0,0 +1,253 @@
+// Copyright (c) 2025 Suricata Contributors
+// Original Author: Stefano Scafiti
+//
+// This file is part of Suricata: Type-Safe AI Agents for Go.
+//
+// Licensed under the MIT License. You may obtain a copy of the License at
+//
+//	https://opensource.org/licenses/MIT
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package runtime
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"math/big"
+	"strconv"
+	"strings"
+)
+
+// DecimalPattern is the pattern of the decimals encoded by Decimal, used in JSON Schemas.
+const DecimalPattern = `^-?[0-9]+(\.[0-9]+)?$`
+
+// maxDecimalScale is the maximum number of fractional digits of a Decimal.
+const maxDecimalScale = 18
+
+// maxDecimalExponent bounds the exponents accepted by ParseDecimal: larger ones cannot
+// fit the units of a Decimal, and would only waste time expanding untrusted input.
+const maxDecimalExponent = 40
+
+// Decimal is a fixed-point decimal number, such as a price. It is encoded in JSON as a string,
+// e.g. "1249.90", so that values round-trip without the rounding errors of float64.
+// Its value is Units() / 10^Scale(); arithmetic overflows past about 18 significant digits.
+// The zero value is 0.
+type Decimal struct {
+	units int64
+	scale int
+}
+
+// NewDecimal returns the decimal units / 10^scale, e.g. NewDecimal(124990, 2) is 1249.90.
+// It panics if scale is not between 0 and 18.
+func NewDecimal(units int64, scale int) Decimal {
+	if scale < 0 || scale > maxDecimalScale {
+		panic(fmt.Sprintf("runtime: decimal scale %d out of range [0, %d]", scale, maxDecimalScale))
+	}
+	return Decimal{units: units, scale: scale}
+}
+
+// ParseDecimal parses a decimal number, such as "-12.50" or "1.5e3", keeping its fractional digits.
+func ParseDecimal(s string) (Decimal, error) {
+	s = strings.TrimSpace(s)
+
+	mantissa, exponent := s, 0
+	if i := strings.IndexAny(s, "eE"); i >= 0 {
+		exp, err := strconv.Atoi(s[i+1:])
+		if err != nil {
+			return Decimal{}, fmt.Errorf("invalid decimal %q", s)
+		}
+		if exp > maxDecimalExponent || exp < -maxDecimalExponent {
+			return Decimal{}, fmt.Errorf("decimal %q out of range", s)
+		}
+		mantissa, exponent = s[:i], exp
+	}
+
+	neg := strings.HasPrefix(mantissa, "-")
+	if neg || strings.HasPrefix(mantissa, "+") {
+		mantissa = mantissa[1:]
+	}
+
+	intPart, fracPart, _ := strings.Cut(mantissa, ".")
+	if intPart == "" && fracPart == "" || !isDigits(intPart) || !isDigits(fracPart) {
+		return Decimal{}, fmt.Errorf("invalid decimal %q", s)
+	}
+
+	scale := len(fracPart) - exponent
+	digits := strings.TrimLeft(intPart+fracPart, "0")
+	if scale < 0 {
+		digits += strings.Repeat("0", -scale)
+		scale = 0
+	}
+	if scale > maxDecimalScale {
+		return Decimal{}, fmt.Errorf("decimal %q has more than %d fractional digits", s, maxDecimalScale)
+	}
+
+	var units int64
+	if digits != "" {
+		u, err := strconv.ParseInt(digits, 10, 64)
+		if err != nil {
+			return Decimal{}, fmt.Errorf("decimal %q out of range", s)
+		}
+		units = u
+	}
+	if neg {
+		units = -units
+	}
+	return Decimal{units: units, scale: scale}, nil
+}
+
+// MustParseDecimal is like ParseDecimal but panics if s is not a valid decimal.
+func MustParseDecimal(s string) Decimal {
+	d, err := ParseDecimal(s)
+	if err != nil {
+		panic(err)
+	}
+	return d
+}
+
+func isDigits(s string) bool {
+	for _, c := range s {
+		if c < '0' || c > '9' {
+			return false
+		}
+	}
+	return true
+}
+
+// Units returns the value of d multiplied by 10^Scale().
+func (d Decimal) Units() int64 { return d.units }
+
+// Scale returns the number of fractional digits of d.
+func (d Decimal) Scale() int { return d.scale }
+
+// IsZero reports whether d is 0.
+func (d Decimal) IsZero() bool { return d.units == 0 }
+
+// Float64 returns the nearest float64 to d.
+func (d Decimal) Float64() float64 {
+	f, _ := strconv.ParseFloat(d.String(), 64)
+	return f
+}
+
+// String returns d with all its fractional digits, e.g. "1249.90".
+func (d Decimal) String() string {
+	u := uint64(d.units)
+	if d.units < 0 {
+		u = -u
+	}
+
+	digits := strconv.FormatUint(u, 10)
+	if len(digits) <= d.scale {
+		digits = strings.Repeat("0", d.scale-len(digits)+1) + digits
+	}
+	if d.scale > 0 {
+		digits = digits[:len(digits)-d.scale] + "." + digits[len(digits)-d.scale:]
+	}
+	if d.units < 0 {
+		digits = "-" + digits
+	}
+	return digits
+}
+
+// Cmp compares d and e, returning -1, 0 or +1. Decimals of different scales are compared
+// by value, so that 1.5 equals 1.50.
+func (d Decimal) Cmp(e Decimal) int {
+	return d.bigUnits(e.scale).Cmp(e.bigUnits(d.scale))
+}
+
+// bigUnits returns the units of d rescaled to the greater of its scale and scale.
+func (d Decimal) bigUnits(scale int) *big.Int {
+	units := big.NewInt(d.units)
+	if scale > d.scale {
+		units.Mul(units, big.NewInt(pow10(scale-d.scale)))
+	}
+	return units
+}
+
+// Add returns d + e, with the greater of their scales.
+func (d Decimal) Add(e Decimal) Decimal {
+	d, e = d.rescale(e.scale), e.rescale(d.scale)
+	return Decimal{units: d.units + e.units, scale: d.scale}
+}
+
+// Sub returns d - e, with the greater of their scales.
+func (d Decimal) Sub(e Decimal) Decimal {
+	return d.Add(e.Neg())
+}
+
+// Neg returns -d.
+func (d Decimal) Neg() Decimal {
+	return Decimal{units: -d.units, scale: d.scale}
+}
+
+// Mul returns d multiplied by n, e.g. the price of n items.
+func (d Decimal) Mul(n int64) Decimal {
+	return Decimal{units: d.units * n, scale: d.scale}
+}
+
+// Round returns d rounded half away from zero to scale fractional digits.
+func (d Decimal) Round(scale int) Decimal {
+	scale = min(max(scale, 0), maxDecimalScale)
+	if scale >= d.scale {
+		return d.rescale(scale)
+	}
+
+	p := pow10(d.scale - scale)
+	units, rem := d.units/p, d.units%p
+	switch {
+	case rem >= p-rem && rem > 0:
+		units++
+	case -rem >= p+rem && rem < 0:
+		units--
+	}
+	return Decimal{units: units, scale: scale}
+}
+
+// rescale returns d with at least scale fractional digits.
+func (d Decimal) rescale(scale int) Decimal {
+	if scale <= d.scale {
+		return d
+	}
+	return Decimal{units: d.units * pow10(scale-d.scale), scale: scale}
+}
+
+func pow10(n int) int64 {
+	p := int64(1)
+	for range n {
+		p *= 10
+	}
+	return p
+}
+
+// MarshalJSON encodes d as a string.
+func (d Decimal) MarshalJSON() ([]byte, error) {
+	return []byte(`"` + d.String() + `"`), nil
+}
+
+// UnmarshalJSON decodes d from a string or, as models often write them, from a number.
+func (d *Decimal) UnmarshalJSON(data []byte) error {
+	data = bytes.TrimSpace(data)
+	if string(data) == "null" {
+		return nil
+	}
+
+	s := string(data)
+	if strings.HasPrefix(s, `"`) {
+		if err := json.Unmarshal(data, &s); err != nil {
+			return err
+		}
+	}
+
+	v, err := ParseDecimal(s)
+	if err != nil {
+		return err
+	}
+	*d = v
+	return nil
+}
@@ -0,0 +1,148 @@
+// Copyright (c) 2025 Suricata Contributors
+// Original Author: Stefano Scafiti
+//
+// This file is part of Suricata: Type-Safe AI Agents for Go.
+//
+// Licensed under the MIT License. You may obtain a copy of the License at
+//
+//	https://opensource.org/licenses/MIT
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package runtime_test
+
+import (
+	"context"
+	"encoding/json"
+	"testing"
+
+	"github.com/ostafen/suricata/runtime"
+	"github.com/xeipuuv/gojsonschema"
+)
+
+func TestParseDecimal(t *testing.T) {
+	for s, want := range map[string]string{
+		"12.50":   "12.50",
+		"-0.05":   "-0.05",
+		"+7":      "7",
+		" 3.0 ":   "3.0",
+		".5":      "0.5",
+		"1.5e3":   "1500",
+		"125e-2":  "1.25",
+		"0.10000": "0.10000",
+	} {
+		d, err := runtime.ParseDecimal(s)
+		if err != nil {
+			t.Errorf("ParseDecimal(%q): unexpected error: %v", s, err)
+			continue
+		}
+		if d.String() != want {
+			t.Errorf("ParseDecimal(%q) = %s, want %s", s, d, want)
+		}
+	}
+
+	for _, s := range []string{"", ".", "-", "1.2.3", "12,50", "$12", "1e", "99999999999999999999", "1e3000000", "1e-3000000"} {
+		if _, err := runtime.ParseDecimal(s); err == nil {
+			t.Errorf("ParseDecimal(%q): expected error", s)
+		}
+	}
+}
+
+func TestDecimal_Arithmetic(t *testing.T) {
+	price := runtime.NewDecimal(1999, 2)
+	if got := price.Mul(3).Add(runtime.MustParseDecimal("0.1")).String(); got != "60.07" {
+		t.Errorf("expected 60.07, got %s", got)
+	}
+	if got := price.Sub(runtime.NewDecimal(20, 0)).String(); got != "-0.01" {
+		t.Errorf("expected -0.01, got %s", got)
+	}
+
+	if runtime.MustParseDecimal("1.5").Cmp(runtime.MustParseDecimal("1.50")) != 0 {
+		t.Errorf("expected 1.5 to equal 1.50")
+	}
+	if runtime.MustParseDecimal("-2").Cmp(runtime.MustParseDecimal("1.99")) >= 0 {
+		t.Errorf("expected -2 to be less than 1.99")
+	}
+
+	for s, want := range map[string]string{
+		"2.345":  "2.35",
+		"2.344":  "2.34",
+		"-2.345": "-2.35",
+		"2.3":    "2.30",
+	} {
+		if got := runtime.MustParseDecimal(s).Round(2).String(); got != want {
+			t.Errorf("Round(%s, 2) = %s, want %s", s, got, want)
+		}
+	}
+}
+
+func TestDecimal_JSON(t *testing.T) {
+	var out struct {
+		Price runtime.Decimal `json:"price"`
+		Tax   runtime.Decimal `json:"tax"`
+	}
+	if err := json.Unmarshal([]byte(`{"price": "0.30", "tax": 0.1}`), &out); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if got := out.Price.Add(out.Tax).String(); got != "0.40" {
+		t.Errorf("expected 0.40, got %s", got)
+	}
+
+	data, err := json.Marshal(out)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if string(data) != `{"price":"0.30","tax":"0.1"}` {
+		t.Errorf("unexpected encoding: %s", data)
+	}
+}
+
+func TestUnmarshalValidate_Decimal(t *testing.T) {
+	schema := gojsonschema.NewStringLoader(`{
+		"type": "object",
+		"properties": {
+			"total": {"type": "string", "format": "decimal", "pattern": "^-?[0-9]+(\\.[0-9]+)?$"}
+		},
+		"required": ["total"]
+	}`)
+
+	var out struct {
+		Total runtime.Decimal `json:"total"`
+	}
+	if err := runtime.UnmarshalValidate([]byte(`{"total": 1249.90}`), &out, schema); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if out.Total.String() != "1249.90" {
+		t.Errorf("expected the digits of the number to be kept, got %s", out.Total)
+	}
+
+	if err := runtime.UnmarshalValidate([]byte(`{"total": "about 10"}`), &out, schema); err == nil {
+		t.Errorf("expected validation error for a non-decimal string")
+	}
+}
+
+func TestCheckRules_Decimal(t *testing.T) {
+	type Quote struct {
+		Price    runtime.Decimal `json:"price"`
+		Discount runtime.Decimal `json:"discount"`
+	}
+
+	check := runtime.CheckRules(
+		runtime.MustParseRule("price > discount"),
+		runtime.MustParseRule("discount >= 0"),
+	)
+
+	quote := &Quote{Price: runtime.MustParseDecimal("10.00"), Discount: runtime.MustParseDecimal("9.5")}
+	if err := check.Process(context.Background(), quote); err != nil {
+		t.Errorf("unexpected error: %v", err)
+	}
+
+	quote.Discount = runtime.MustParseDecimal("-1")
+	if err := check.Process(context.Background(), quote); err == nil {
+		t.Errorf("expected a violation for a negative discount")
+	}
+}
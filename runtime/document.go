@@ -0,0 +1,248 @@
+// Copyright (c) 2025 Suricata Contributors
+// Original Author: Stefano Scafiti
+//
+// This file is part of Suricata: Type-Safe AI Agents for Go.
+//
+// Licensed under the MIT License. You may obtain a copy of the License at
+//
+//	https://opensource.org/licenses/MIT
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package runtime
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"html"
+	"io"
+	"maps"
+	"mime"
+	"slices"
+	"strings"
+	"unicode/utf8"
+
+	"github.com/ostafen/suricata/runtime/pdf"
+)
+
+// DefaultMaxFileTokens is the maximum estimated number of tokens of the text of each
+// file of the input, beyond which the text is truncated.
+const DefaultMaxFileTokens = 8000
+
+// ErrUnsupportedFile is returned for files whose text cannot be extracted.
+var ErrUnsupportedFile = errors.New("unsupported file type")
+
+// TextExtractor extracts the text of documents, so that it can be inlined in prompts.
+type TextExtractor interface {
+	ExtractText(ctx context.Context, mimeType string, r io.Reader) (string, error)
+}
+
+// TextExtractorFunc adapts a function to the TextExtractor interface.
+type TextExtractorFunc func(ctx context.Context, mimeType string, r io.Reader) (string, error)
+
+func (f TextExtractorFunc) ExtractText(ctx context.Context, mimeType string, r io.Reader) (string, error) {
+	return f(ctx, mimeType, r)
+}
+
+// DefaultTextExtractor extracts the text of plain text, JSON, XML, HTML and PDF documents.
+// It fails with ErrUnsupportedFile for other types.
+var DefaultTextExtractor TextExtractor = TextExtractorFunc(extractText)
+
+// WithTextExtractor replaces DefaultTextExtractor, e.g. to support more file types.
+func WithTextExtractor(extractor TextExtractor) Option {
+	return func(r *Runtime) {
+		r.extractor = extractor
+	}
+}
+
+func extractText(ctx context.Context, mimeType string, r io.Reader) (string, error) {
+	mediaType, _, err := mime.ParseMediaType(mimeType)
+	if err != nil {
+		return "", fmt.Errorf("%w: %q", ErrUnsupportedFile, mimeType)
+	}
+
+	switch {
+	case mediaType == "application/pdf":
+		return pdf.ExtractText(r)
+	case mediaType == "text/html" || mediaType == "application/xhtml+xml":
+		data, err := io.ReadAll(r)
+		if err != nil {
+			return "", err
+		}
+		return htmlText(string(data)), nil
+	case strings.HasPrefix(mediaType, "text/"),
+		mediaType == "application/json",
+		mediaType == "application/xml",
+		mediaType == "application/yaml":
+		data, err := io.ReadAll(r)
+		if err != nil {
+			return "", err
+		}
+		return strings.ToValidUTF8(string(data), "�"), nil
+	}
+	return "", fmt.Errorf("%w: %q", ErrUnsupportedFile, mediaType)
+}
+
+// htmlBlockTags are the elements starting a new line of text.
+var htmlBlockTags = map[string]bool{
+	"br": true, "p": true, "div": true, "li": true, "tr": true, "table": true, "section": true, "article": true,
+	"h1": true, "h2": true, "h3": true, "h4": true, "h5": true, "h6": true, "pre": true, "blockquote": true,
+}
+
+// htmlText returns the text of an HTML document, without markup, scripts and styles.
+func htmlText(doc string) string {
+	var sb strings.Builder
+	for len(doc) > 0 {
+		start := strings.IndexByte(doc, '<')
+		if start < 0 {
+			sb.WriteString(html.UnescapeString(doc))
+			break
+		}
+		sb.WriteString(html.UnescapeString(doc[:start]))
+		doc = doc[start:]
+
+		if strings.HasPrefix(doc, "<!--") {
+			end := strings.Index(doc, "-->")
+			if end < 0 {
+				break
+			}
+			doc = doc[end+3:]
+			continue
+		}
+
+		end := strings.IndexByte(doc, '>')
+		if end < 0 {
+			break
+		}
+		tag := doc[1:end]
+		doc = doc[end+1:]
+
+		name := strings.TrimPrefix(tag, "/")
+		if i := strings.IndexAny(name, " \t\r\n/"); i >= 0 {
+			name = name[:i]
+		}
+		name = strings.ToLower(name)
+
+		if (name == "script" || name == "style") && !strings.HasPrefix(tag, "/") {
+			// Skip the content of the element, up to its end tag
+			if i := strings.Index(strings.ToLower(doc), "</"+name); i >= 0 {
+				doc = doc[i:]
+			} else {
+				doc = ""
+			}
+			continue
+		}
+		if htmlBlockTags[name] {
+			sb.WriteByte('\n')
+		}
+	}
+
+	// Collapse the spaces of each line, dropping empty ones
+	var lines []string
+	for _, line := range strings.Split(sb.String(), "\n") {
+		if line = strings.Join(strings.Fields(line), " "); line != "" {
+			lines = append(lines, line)
+		}
+	}
+	return strings.Join(lines, "\n")
+}
+
+// truncateText cuts text to about maxTokens tokens, at a line or word boundary when possible,
+// noting how much was left out.
+func truncateText(text string, maxTokens int) string {
+	maxBytes := maxTokens * 4 // See EstimateTokens
+	if len(text) <= maxBytes {
+		return text
+	}
+
+	cut := maxBytes
+	for cut > 0 && !utf8.RuneStart(text[cut]) {
+		cut--
+	}
+	if i := strings.LastIndexAny(text[:cut], "\n "); i > cut/2 {
+		cut = i
+	}
+	return fmt.Sprintf("%s\n[... truncated %d of %d characters ...]", text[:cut], utf8.RuneCountInString(text[cut:]), utf8.RuneCountInString(text))
+}
+
+// prepareInput returns the input of req as shown in the prompt, together with the images to
+// send as attachments. Images are too large to be inlined, so the prompt refers to them by
+// placeholders, while the text of the other files replaces their data. The input is returned
+// unchanged if it holds no attachments.
+func (r *Runtime) prepareInput(ctx context.Context, req *Request) (any, []Attachment, error) {
+	data, err := json.Marshal(req.Input)
+	if err != nil || !bytes.Contains(data, []byte(`"data:`)) {
+		return req.Input, nil, nil
+	}
+
+	var value any
+	if err := json.Unmarshal(data, &value); err != nil {
+		return req.Input, nil, nil
+	}
+
+	extractor := r.extractor
+	if extractor == nil {
+		extractor = DefaultTextExtractor
+	}
+
+	maxTokens := req.MaxFileTokens
+	if maxTokens <= 0 {
+		maxTokens = DefaultMaxFileTokens
+	}
+
+	var (
+		attachments []Attachment
+		found       bool
+	)
+	var walk func(v any) (any, error)
+	walk = func(v any) (any, error) {
+		switch v := v.(type) {
+		case string:
+			att, ok := parseDataURL(v)
+			if !ok {
+				return v, nil
+			}
+			found = true
+
+			if att.IsImage() {
+				attachments = append(attachments, att)
+				return fmt.Sprintf("[image %d]", len(attachments)), nil
+			}
+
+			text, err := extractor.ExtractText(ctx, att.MIMEType, bytes.NewReader(att.Data))
+			if err != nil {
+				return nil, fmt.Errorf("input file: %w", err)
+			}
+			return truncateText(text, maxTokens), nil
+		case []any:
+			for i := range v {
+				var err error
+				if v[i], err = walk(v[i]); err != nil {
+					return nil, err
+				}
+			}
+		case map[string]any:
+			// Visit keys in order, so that images are numbered as they appear in the encoded input
+			for _, key := range slices.Sorted(maps.Keys(v)) {
+				var err error
+				if v[key], err = walk(v[key]); err != nil {
+					return nil, err
+				}
+			}
+		}
+		return v, nil
+	}
+
+	value, err = walk(value)
+	if err != nil || !found {
+		return req.Input, nil, err
+	}
+	return value, attachments, nil
+}
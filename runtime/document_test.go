@@ -0,0 +1,105 @@
+// Copyright (c) 2025 Suricata Contributors
+// Original Author: Stefano Scafiti
+//
+// This file is part of Suricata: Type-Safe AI Agents for Go.
+//
+// Licensed under the MIT License. You may obtain a copy of the License at
+//
+//	https://opensource.org/licenses/MIT
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package runtime_test
+
+import (
+	"context"
+	"errors"
+	"io"
+	"strings"
+	"testing"
+
+	"github.com/ostafen/suricata/runtime"
+	"github.com/ostafen/suricata/runtime/runtimetest"
+	"github.com/xeipuuv/gojsonschema"
+)
+
+func TestDefaultTextExtractor(t *testing.T) {
+	tests := []struct {
+		mimeType string
+		doc      string
+		want     string
+	}{
+		{"text/plain; charset=utf-8", "Hello\nworld", "Hello\nworld"},
+		{
+			"text/html",
+			`<html><head><style>p { color: red }</style><script>alert("x")</script></head>
+			<body><h1>Report</h1><p>Sales &amp; costs<br/>grew   by 5%</p><!-- draft --></body></html>`,
+			"Report\nSales & costs\ngrew by 5%",
+		},
+	}
+
+	for _, tt := range tests {
+		text, err := runtime.DefaultTextExtractor.ExtractText(context.Background(), tt.mimeType, strings.NewReader(tt.doc))
+		if err != nil {
+			t.Fatalf("%s: unexpected error: %v", tt.mimeType, err)
+		}
+		if text != tt.want {
+			t.Errorf("%s: got %q, want %q", tt.mimeType, text, tt.want)
+		}
+	}
+
+	_, err := runtime.DefaultTextExtractor.ExtractText(context.Background(), "application/zip", strings.NewReader("PK"))
+	if !errors.Is(err, runtime.ErrUnsupportedFile) {
+		t.Errorf("expected ErrUnsupportedFile, got %v", err)
+	}
+}
+
+func TestInvoke_FileInput(t *testing.T) {
+	type Input struct {
+		Document runtime.Attachment `json:"document"`
+	}
+
+	doc, err := runtime.NewFile(strings.NewReader(strings.Repeat("lorem ipsum ", 100)), "text/plain")
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	req := runtime.Request{
+		PromptTemplate: "Summarize the document.",
+		Input:          &Input{Document: doc},
+		InputSchema:    gojsonschema.NewStringLoader(`{"type":"object"}`),
+		MaxFileTokens:  10,
+	}
+
+	t.Run("text is extracted and truncated", func(t *testing.T) {
+		mock := runtimetest.NewInvoker(t)
+		mock.Expect().PromptContains(`{"document":"lorem ipsum lorem ipsum lorem ipsum\n[... truncated 1165 of 1200 characters ...]"}`).Respond("Lorem")
+
+		var out string
+		req.Output = &out
+		if err := runtime.NewRuntime(mock).Invoke(context.Background(), req); err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+		mock.AssertExpectations()
+	})
+
+	t.Run("custom extractor", func(t *testing.T) {
+		mock := runtimetest.NewInvoker(t)
+		mock.Expect().PromptContains(`{"document":"TEXT/PLAIN"}`).Respond("Lorem")
+
+		extractor := runtime.TextExtractorFunc(func(ctx context.Context, mimeType string, r io.Reader) (string, error) {
+			return strings.ToUpper(mimeType), nil
+		})
+
+		var out string
+		req.Output = &out
+		if err := runtime.NewRuntime(mock, runtime.WithTextExtractor(extractor)).Invoke(context.Background(), req); err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+		mock.AssertExpectations()
+	})
+}
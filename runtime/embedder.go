@@ -0,0 +1,32 @@
+// Copyright (c) 2025 Suricata Contributors
+// Original Author: Stefano Scafiti
+//
+// This file is part of Suricata: Type-Safe AI Agents for Go.
+//
+// Licensed under the MIT License. You may obtain a copy of the License at
+//
+//	https://opensource.org/licenses/MIT
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package runtime
+
+import "context"
+
+// Embedder turns texts into vectors capturing their meaning, so that semantically
+// similar texts have close vectors. It backs retrieval and semantic caching.
+type Embedder interface {
+	// Embed returns the vectors of the given texts, in order.
+	Embed(ctx context.Context, texts []string) ([][]float32, error)
+}
+
+// EmbedderFunc adapts an ordinary function to the Embedder interface.
+type EmbedderFunc func(ctx context.Context, texts []string) ([][]float32, error)
+
+func (f EmbedderFunc) Embed(ctx context.Context, texts []string) ([][]float32, error) {
+	return f(ctx, texts)
+}
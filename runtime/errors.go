@@ -0,0 +1,204 @@
+// Copyright (c) 2025 Suricata Contributors
+// Original Author: Stefano Scafiti
+//
+// This file is part of Suricata: Type-Safe AI Agents for Go.
+//
+// Licensed under the MIT License. You may obtain a copy of the License at
+//
+//	https://opensource.org/licenses/MIT
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package runtime
+
+import (
+	"fmt"
+	"io"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/xeipuuv/gojsonschema"
+)
+
+// Violation is a single constraint of a JSON schema not satisfied by a document.
+type Violation struct {
+	Field   string // Path of the offending field, such as "items.0.name", or "(root)"
+	Rule    string // Violated constraint, such as "required" or "enum"
+	Message string
+}
+
+func (v Violation) String() string {
+	return v.Field + ": " + v.Message
+}
+
+// SchemaError reports the violations found validating a document against a schema.
+// It matches ErrInvalidOutput with errors.Is.
+type SchemaError struct {
+	Violations []Violation
+}
+
+func newSchemaError(res *gojsonschema.Result) *SchemaError {
+	errs := res.Errors()
+
+	violations := make([]Violation, len(errs))
+	for i, e := range errs {
+		violations[i] = Violation{
+			Field:   e.Field(),
+			Rule:    e.Type(),
+			Message: e.Description(),
+		}
+	}
+	return &SchemaError{Violations: violations}
+}
+
+func (e *SchemaError) Error() string {
+	if len(e.Violations) == 0 {
+		return ErrInvalidOutput.Error()
+	}
+
+	details := make([]string, len(e.Violations))
+	for i, v := range e.Violations {
+		details[i] = v.String()
+	}
+	return fmt.Sprintf("%s: %s", ErrInvalidOutput, strings.Join(details, "; "))
+}
+
+func (e *SchemaError) Unwrap() error {
+	return ErrInvalidOutput
+}
+
+// ToolError is an error returned by a tool, or raised while calling it.
+type ToolError struct {
+	Tool string
+	Err  error
+}
+
+func (e *ToolError) Error() string {
+	return fmt.Sprintf("tool '%s': %v", e.Tool, e.Err)
+}
+
+func (e *ToolError) Unwrap() error {
+	return e.Err
+}
+
+// ProviderError is returned by invokers and embedders when the provider answers
+// with an error status. Transport failures are returned unchanged.
+type ProviderError struct {
+	Provider   string
+	StatusCode int
+	RetryAfter time.Duration // Delay suggested by the provider, or zero if it did not send one
+	Err        error
+}
+
+// MaxErrorBodySize is the maximum number of bytes of an error response reported by a ProviderError.
+const MaxErrorBodySize = 4096
+
+// NewProviderError builds the error for the unsuccessful response resp, reading
+// part of its body as the error message. The caller still has to close the body.
+func NewProviderError(provider string, resp *http.Response) *ProviderError {
+	body, _ := io.ReadAll(io.LimitReader(resp.Body, MaxErrorBodySize))
+
+	msg := strings.TrimSpace(string(body))
+	if msg == "" {
+		msg = resp.Status
+	}
+
+	return &ProviderError{
+		Provider:   provider,
+		StatusCode: resp.StatusCode,
+		RetryAfter: ParseRetryAfter(resp.Header.Get("Retry-After")),
+		Err:        fmt.Errorf("%s", msg),
+	}
+}
+
+func (e *ProviderError) Error() string {
+	return fmt.Sprintf("%s: status %d: %v", e.Provider, e.StatusCode, e.Err)
+}
+
+func (e *ProviderError) Unwrap() error {
+	return e.Err
+}
+
+// As lets errors.As match rate limited errors with a *RateLimitError target, for callers
+// written before ProviderError was introduced.
+func (e *ProviderError) As(target any) bool {
+	t, ok := target.(**RateLimitError)
+	if !ok || !e.RateLimited() {
+		return false
+	}
+	*t = &RateLimitError{RetryAfter: e.RetryAfter, Err: e}
+	return true
+}
+
+// RateLimited reports whether the provider rejected the request with HTTP 429.
+func (e *ProviderError) RateLimited() bool {
+	return e.StatusCode == http.StatusTooManyRequests
+}
+
+// Retryable reports whether the same request may succeed later: the provider
+// was rate limited, overloaded or failed with a server error.
+func (e *ProviderError) Retryable() bool {
+	return e.RateLimited() || e.StatusCode == http.StatusRequestTimeout || e.StatusCode >= 500
+}
+
+// RateLimitError is returned by invokers when the provider rejects a request
+// with HTTP 429. RetryAfter is the delay suggested by the provider, or zero
+// if it did not send one.
+//
+// Deprecated: invokers return a *ProviderError, whose RateLimited method reports
+// HTTP 429. A rate limited *ProviderError still matches a *RateLimitError target
+// of errors.As, and a *RateLimitError one of type *ProviderError.
+type RateLimitError struct {
+	RetryAfter time.Duration
+	Err        error
+}
+
+func (e *RateLimitError) Error() string {
+	if e.RetryAfter > 0 {
+		return fmt.Sprintf("rate limited, retry after %s: %v", e.RetryAfter, e.Err)
+	}
+	return fmt.Sprintf("rate limited: %v", e.Err)
+}
+
+func (e *RateLimitError) Unwrap() error {
+	return e.Err
+}
+
+// As lets errors.As match the error with a *ProviderError target, so that errors
+// returned by custom invokers are still retried by the ratelimit package.
+func (e *RateLimitError) As(target any) bool {
+	t, ok := target.(**ProviderError)
+	if !ok {
+		return false
+	}
+	*t = &ProviderError{StatusCode: http.StatusTooManyRequests, RetryAfter: e.RetryAfter, Err: e.Err}
+	return true
+}
+
+// ParseRetryAfter decodes a Retry-After header, given either in seconds or as
+// an HTTP date. It returns zero if the header is missing or malformed.
+func ParseRetryAfter(header string) time.Duration {
+	if header == "" {
+		return 0
+	}
+
+	if secs, err := strconv.Atoi(header); err == nil {
+		if secs < 0 {
+			return 0
+		}
+		return time.Duration(secs) * time.Second
+	}
+
+	if t, err := http.ParseTime(header); err == nil {
+		if d := time.Until(t); d > 0 {
+			return d
+		}
+	}
+	return 0
+}
@@ -0,0 +1,154 @@
+// Copyright (c) 2025 Suricata Contributors
+// Original Author: Stefano Scafiti
+//
+// This file is part of Suricata: Type-Safe AI Agents for Go.
+//
+// Licensed under the MIT License. You may obtain a copy of the License at
+//
+//	https://opensource.org/licenses/MIT
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package runtime_test
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/ostafen/suricata/runtime"
+	"github.com/ostafen/suricata/runtime/runtimetest"
+	"github.com/xeipuuv/gojsonschema"
+)
+
+func TestSchemaError(t *testing.T) {
+	schema := gojsonschema.NewStringLoader(`{
+		"type": "object",
+		"properties": {
+			"name": {"type": "string"},
+			"tags": {"type": "array", "items": {"type": "string"}}
+		},
+		"required": ["name"]
+	}`)
+
+	err := runtime.ValidateRawJSON([]byte(`{"tags": ["a", 1]}`), schema)
+
+	var schemaErr *runtime.SchemaError
+	if !errors.As(err, &schemaErr) {
+		t.Fatalf("expected SchemaError, got %v", err)
+	}
+	if !errors.Is(err, runtime.ErrInvalidOutput) {
+		t.Error("expected SchemaError to match ErrInvalidOutput")
+	}
+
+	got := make(map[string]string)
+	for _, v := range schemaErr.Violations {
+		got[v.Field] = v.Rule
+	}
+
+	want := map[string]string{"(root)": "required", "tags.1": "invalid_type"}
+	for field, rule := range want {
+		if got[field] != rule {
+			t.Errorf("expected %s violation on %s, got %v", rule, field, got)
+		}
+	}
+}
+
+func TestNewProviderError(t *testing.T) {
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Retry-After", "7")
+		w.WriteHeader(http.StatusTooManyRequests)
+		w.Write([]byte(`{"error":"slow down"}`))
+	}))
+	defer srv.Close()
+
+	resp, err := http.Get(srv.URL)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer resp.Body.Close()
+
+	provErr := runtime.NewProviderError("test", resp)
+	if provErr.StatusCode != http.StatusTooManyRequests || provErr.RetryAfter != 7*time.Second {
+		t.Errorf("unexpected error: %+v", provErr)
+	}
+	if !provErr.RateLimited() || !provErr.Retryable() {
+		t.Error("expected a retryable, rate limited error")
+	}
+	if provErr.Error() != `test: status 429: {"error":"slow down"}` {
+		t.Errorf("unexpected message %q", provErr.Error())
+	}
+
+	badRequest := &runtime.ProviderError{StatusCode: http.StatusBadRequest}
+	if badRequest.Retryable() {
+		t.Error("expected 400 not to be retryable")
+	}
+}
+
+func TestRateLimitError(t *testing.T) {
+	var rateErr *runtime.RateLimitError
+	provErr := &runtime.ProviderError{Provider: "test", StatusCode: http.StatusTooManyRequests, RetryAfter: time.Second, Err: errors.New("slow down")}
+	if !errors.As(fmt.Errorf("call: %w", provErr), &rateErr) || rateErr.RetryAfter != time.Second {
+		t.Errorf("expected a rate limited ProviderError to match RateLimitError, got %v", rateErr)
+	}
+	if errors.As(&runtime.ProviderError{StatusCode: http.StatusBadGateway}, &rateErr) {
+		t.Error("expected a 502 not to match RateLimitError")
+	}
+
+	var target *runtime.ProviderError
+	if !errors.As(&runtime.RateLimitError{RetryAfter: time.Minute, Err: errors.New("429")}, &target) {
+		t.Fatal("expected RateLimitError to match ProviderError")
+	}
+	if !target.RateLimited() || target.RetryAfter != time.Minute {
+		t.Errorf("unexpected error: %+v", target)
+	}
+}
+
+func TestRuntime_ToolError(t *testing.T) {
+	mock := runtimetest.NewInvoker(t).Respond(
+		`{"name":"lookup","args":{}}`,
+		`{"done":true,"out":"not found"}`,
+	)
+
+	var toolErr *runtime.ToolError
+	rt := runtime.NewRuntime(mock, runtime.WithHooks(runtime.Hooks{
+		OnToolResult: func(ctx context.Context, name string, out any, err error) {
+			errors.As(err, &toolErr)
+		},
+	}))
+
+	cause := errors.New("database unavailable")
+	err := rt.Invoke(context.Background(), runtime.Request{
+		PromptTemplate: "Look it up",
+		Input:          map[string]any{},
+		Output:         new(string),
+		InputSchema:    gojsonschema.NewStringLoader(`{"type":"object"}`),
+		ToolUnmarshaller: func(name string, data []byte) (any, error) {
+			var args map[string]any
+			return args, json.Unmarshal(data, &args)
+		},
+		ToolInvoker: func(ctx context.Context, name string, in any) (any, error) {
+			return nil, cause
+		},
+	})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	if toolErr == nil || toolErr.Tool != "lookup" || !errors.Is(toolErr, cause) {
+		t.Fatalf("expected ToolError wrapping the tool failure, got %v", toolErr)
+	}
+	if !strings.Contains(mock.LastCall().LastMessage(), "ERR: tool 'lookup': database unavailable") {
+		t.Errorf("expected the error to be reported to the model, got %q", mock.LastCall().LastMessage())
+	}
+}
@@ -0,0 +1,211 @@
+// Copyright (c) 2025 Suricata Contributors
+// Original Author: Stefano Scafiti
+//
+// This file is part of Suricata: Type-Safe AI Agents for Go.
+//
+// Licensed under the MIT License. You may obtain a copy of the License at
+//
+//	https://opensource.org/licenses/MIT
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package runtime
+
+import (
+	"encoding/json"
+	"regexp"
+	"strings"
+)
+
+// ExtractJSONFromString returns the first JSON object found in the input string,
+// such as a model response wrapping it in commentary or markdown code fences.
+// Objects with trailing commas or single-quoted strings are repaired.
+// It returns an empty string if none is found.
+func ExtractJSONFromString(input string) string {
+	return firstJSON(input, '{')
+}
+
+// ExtractJSONArrayFromString is like ExtractJSONFromString, for JSON arrays.
+func ExtractJSONArrayFromString(input string) string {
+	return firstJSON(input, '[')
+}
+
+// ExtractJSONCandidates returns all the top-level JSON objects and arrays found in
+// the input string, in order, starting with the ones enclosed in code fences.
+// Candidates are repaired as done by ExtractJSONFromString.
+func ExtractJSONCandidates(input string) []string {
+	var (
+		candidates []string
+		seen       = make(map[string]bool)
+	)
+
+	add := func(text string) {
+		for _, c := range scanJSON(text) {
+			if !seen[c] {
+				seen[c] = true
+				candidates = append(candidates, c)
+			}
+		}
+	}
+
+	for _, m := range codeFenceRegexp.FindAllStringSubmatch(input, -1) {
+		add(m[1])
+	}
+	add(input)
+	return candidates
+}
+
+var codeFenceRegexp = regexp.MustCompile("(?s)```[a-zA-Z]*[ \t]*\r?\n(.*?)```")
+
+func firstJSON(input string, open byte) string {
+	for _, c := range ExtractJSONCandidates(input) {
+		if c[0] == open {
+			return c
+		}
+	}
+	return ""
+}
+
+// scanJSON returns the valid, possibly repaired, JSON values starting with '{' or '['
+// in input. Values nested in a returned one are not reported separately.
+func scanJSON(input string) []string {
+	var out []string
+	for i := 0; i < len(input); i++ {
+		if input[i] != '{' && input[i] != '[' {
+			continue
+		}
+
+		end := matchingBracket(input, i)
+		if end < 0 {
+			continue
+		}
+
+		if candidate, ok := validJSON(input[i : end+1]); ok {
+			out = append(out, candidate)
+			i = end
+		}
+	}
+	return out
+}
+
+// matchingBracket returns the index of the bracket closing the one at start, skipping
+// brackets inside double or single-quoted strings, or -1 if it is not closed.
+func matchingBracket(input string, start int) int {
+	var (
+		depth int
+		quote byte
+	)
+
+	for i := start; i < len(input); i++ {
+		c := input[i]
+		if quote != 0 {
+			switch c {
+			case '\\':
+				i++
+			case quote:
+				quote = 0
+			}
+			continue
+		}
+
+		switch c {
+		case '"', '\'':
+			quote = c
+		case '{', '[':
+			depth++
+		case '}', ']':
+			depth--
+			if depth == 0 {
+				return i
+			}
+		}
+	}
+	return -1
+}
+
+// validJSON returns candidate, or its repaired version, if it is valid JSON.
+func validJSON(candidate string) (string, bool) {
+	if json.Valid([]byte(candidate)) {
+		return candidate, true
+	}
+
+	repaired := repairJSON(candidate)
+	if json.Valid([]byte(repaired)) {
+		return repaired, true
+	}
+	return "", false
+}
+
+// repairJSON fixes the mistakes commonly made by small models: it turns single-quoted
+// strings into double-quoted ones and drops trailing commas before closing brackets.
+func repairJSON(input string) string {
+	var sb strings.Builder
+	sb.Grow(len(input))
+
+	for i := 0; i < len(input); i++ {
+		c := input[i]
+		switch c {
+		case '"':
+			end := stringEnd(input, i, '"')
+			sb.WriteString(input[i:end])
+			i = end - 1
+		case '\'':
+			end := stringEnd(input, i, '\'')
+			sb.WriteString(requote(input[i+1 : max(end-1, i+1)]))
+			i = end - 1
+		case ',':
+			j := i + 1
+			for j < len(input) && strings.IndexByte(" \t\r\n", input[j]) >= 0 {
+				j++
+			}
+			if j < len(input) && (input[j] == '}' || input[j] == ']') {
+				continue
+			}
+			sb.WriteByte(c)
+		default:
+			sb.WriteByte(c)
+		}
+	}
+	return sb.String()
+}
+
+// stringEnd returns the index following the quote closing the string starting at start.
+func stringEnd(input string, start int, quote byte) int {
+	for i := start + 1; i < len(input); i++ {
+		switch input[i] {
+		case '\\':
+			i++
+		case quote:
+			return i + 1
+		}
+	}
+	return len(input)
+}
+
+// requote returns the content of a single-quoted string as a double-quoted JSON string.
+func requote(s string) string {
+	s = strings.ReplaceAll(s, `\'`, `'`)
+
+	var sb strings.Builder
+	sb.WriteByte('"')
+	for i := 0; i < len(s); i++ {
+		switch s[i] {
+		case '\\':
+			sb.WriteByte('\\')
+			if i+1 < len(s) {
+				i++
+				sb.WriteByte(s[i])
+			}
+		case '"':
+			sb.WriteString(`\"`)
+		default:
+			sb.WriteByte(s[i])
+		}
+	}
+	sb.WriteByte('"')
+	return sb.String()
+}
@@ -0,0 +1,88 @@
+// Copyright (c) 2025 Suricata Contributors
+// Original Author: Stefano Scafiti
+//
+// This file is part of Suricata: Type-Safe AI Agents for Go.
+//
+// Licensed under the MIT License. You may obtain a copy of the License at
+//
+//	https://opensource.org/licenses/MIT
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package runtime_test
+
+import (
+	"context"
+	"testing"
+
+	"github.com/ostafen/suricata/runtime"
+	"github.com/ostafen/suricata/runtime/runtimetest"
+	"github.com/xeipuuv/gojsonschema"
+)
+
+func TestExtractJSONFromString(t *testing.T) {
+	tests := []struct {
+		name  string
+		input string
+		want  string
+	}{
+		{"plain", `{"a":1}`, `{"a":1}`},
+		{"commentary", "Sure! Here it is: {\"a\":1}\nHope it helps.", `{"a":1}`},
+		{"code fence", "Here:\n```json\n{\"a\": 1}\n```\nDone {not json}", `{"a": 1}`},
+		{"brackets in strings", `{"text":"use } and { freely"}`, `{"text":"use } and { freely"}`},
+		{"invalid first candidate", `{oops} then {"a":1}`, `{"a":1}`},
+		{"trailing commas", `{"a":[1,2,],}`, `{"a":[1,2]}`},
+		{"single quotes", `{'name': 'it\'s "fine"'}`, `{"name": "it's \"fine\""}`},
+		{"none", `no JSON here`, ``},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := runtime.ExtractJSONFromString(tt.input); got != tt.want {
+				t.Errorf("expected %s, got %s", tt.want, got)
+			}
+		})
+	}
+}
+
+func TestExtractJSONArrayFromString(t *testing.T) {
+	got := runtime.ExtractJSONArrayFromString(`Calls: [{"name":"a"},{"name":"b"},]`)
+	if got != `[{"name":"a"},{"name":"b"}]` {
+		t.Errorf("unexpected array %s", got)
+	}
+}
+
+func TestExtractJSONCandidates(t *testing.T) {
+	got := runtime.ExtractJSONCandidates("Draft: {\"a\":1}\n```\n[1, 2]\n```")
+	if len(got) != 2 || got[0] != `[1, 2]` || got[1] != `{"a":1}` {
+		t.Errorf("unexpected candidates %q", got)
+	}
+}
+
+func TestRuntime_PicksSchemaValidCandidate(t *testing.T) {
+	type Output struct {
+		City string `json:"city"`
+	}
+
+	mock := runtimetest.NewInvoker(t).Respond(`First attempt: {"town":"Rome"}. Corrected: {"city":"Rome"}`)
+	rt := runtime.NewRuntime(mock)
+
+	out := &Output{}
+	err := rt.Invoke(context.Background(), runtime.Request{
+		PromptTemplate: "Where?",
+		Input:          map[string]any{},
+		Output:         out,
+		InputSchema:    gojsonschema.NewStringLoader(`{"type":"object"}`),
+		OutputSchema:   gojsonschema.NewStringLoader(`{"type":"object","properties":{"city":{"type":"string"}},"required":["city"],"additionalProperties":false}`),
+	})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if out.City != "Rome" {
+		t.Errorf("expected Rome, got %q", out.City)
+	}
+}
@@ -0,0 +1,188 @@
+// Copyright (c) 2025 Suricata Contributors
+// Original Author: Stefano Scafiti
+//
+// This file is part of Suricata: Type-Safe AI Agents for Go.
+//
+// Licensed under the MIT License. You may obtain a copy of the License at
+//
+//	https://opensource.org/licenses/MIT
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package runtime
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sync"
+	"time"
+)
+
+var ErrNoAvailableInvoker = errors.New("no available invoker")
+
+type FallbackOptions struct {
+	Timeout          time.Duration // Per-provider attempt timeout. Zero means no timeout.
+	FailureThreshold int           // Consecutive failures before a provider is skipped. Zero disables circuit breaking.
+	Cooldown         time.Duration // How long a tripped provider is skipped before being tried again.
+}
+
+func DefaultFallbackOptions() FallbackOptions {
+	return FallbackOptions{
+		Timeout:          0,
+		FailureThreshold: 3,
+		Cooldown:         30 * time.Second,
+	}
+}
+
+// FallbackInvoker tries an ordered list of invokers, moving on to the next one
+// whenever a provider fails, times out or is rate limited.
+type FallbackInvoker struct {
+	invokers []Invoker
+	breakers []*circuitBreaker
+	opts     FallbackOptions
+}
+
+func NewFallbackInvoker(opts FallbackOptions, invokers ...Invoker) *FallbackInvoker {
+	breakers := make([]*circuitBreaker, len(invokers))
+	for i := range breakers {
+		breakers[i] = &circuitBreaker{
+			threshold: opts.FailureThreshold,
+			cooldown:  opts.Cooldown,
+		}
+	}
+
+	return &FallbackInvoker{
+		invokers: invokers,
+		breakers: breakers,
+		opts:     opts,
+	}
+}
+
+func (f *FallbackInvoker) Invoke(ctx context.Context, systemPrompt string, messages []Message) (string, error) {
+	return f.call(ctx, systemPrompt, messages, nil)
+}
+
+// InvokeStream implements StreamingInvoker, streaming the response of the invokers which support it.
+// The deltas of a provider failing midway are not retracted when moving on to the next one.
+func (f *FallbackInvoker) InvokeStream(ctx context.Context, systemPrompt string, messages []Message, onDelta func(delta string)) (string, error) {
+	return f.call(ctx, systemPrompt, messages, onDelta)
+}
+
+// call tries the invokers in order, streaming their responses if onDelta is set.
+func (f *FallbackInvoker) call(ctx context.Context, systemPrompt string, messages []Message, onDelta func(delta string)) (string, error) {
+	var errs []error
+	for i, invoker := range f.invokers {
+		cb := f.breakers[i]
+		if !cb.allow() {
+			continue
+		}
+
+		out, err := f.invoke(ctx, invoker, systemPrompt, messages, onDelta)
+		if err == nil {
+			cb.success()
+			return out, nil
+		}
+
+		// The caller gave up: no point in trying other providers.
+		if ctx.Err() != nil {
+			cb.release()
+			return "", ctx.Err()
+		}
+
+		cb.failure()
+		errs = append(errs, fmt.Errorf("invoker %d: %w", i, err))
+	}
+
+	if len(errs) == 0 {
+		return "", ErrNoAvailableInvoker
+	}
+	return "", fmt.Errorf("%w: %w", ErrNoAvailableInvoker, errors.Join(errs...))
+}
+
+func (f *FallbackInvoker) invoke(ctx context.Context, invoker Invoker, systemPrompt string, messages []Message, onDelta func(delta string)) (string, error) {
+	if f.opts.Timeout > 0 {
+		var cancel context.CancelFunc
+		ctx, cancel = context.WithTimeout(ctx, f.opts.Timeout)
+		defer cancel()
+	}
+
+	if onDelta != nil {
+		return InvokeStream(ctx, invoker, systemPrompt, messages, onDelta)
+	}
+	return invoker.Invoke(ctx, systemPrompt, messages)
+}
+
+// circuitBreaker skips a provider after too many consecutive failures,
+// letting a single trial call through once the cooldown has elapsed.
+type circuitBreaker struct {
+	mtx       sync.Mutex
+	threshold int
+	cooldown  time.Duration
+	failures  int
+	openUntil time.Time
+	probing   bool // Half-open: the trial call is in flight
+}
+
+// available reports whether allow would let a call through, without starting a trial call.
+func (cb *circuitBreaker) available() bool {
+	if cb.threshold <= 0 {
+		return true
+	}
+
+	cb.mtx.Lock()
+	defer cb.mtx.Unlock()
+
+	return cb.failures < cb.threshold || (!cb.probing && !time.Now().Before(cb.openUntil))
+}
+
+func (cb *circuitBreaker) allow() bool {
+	if cb.threshold <= 0 {
+		return true
+	}
+
+	cb.mtx.Lock()
+	defer cb.mtx.Unlock()
+
+	if cb.failures < cb.threshold {
+		return true
+	}
+	if cb.probing || time.Now().Before(cb.openUntil) {
+		return false
+	}
+	cb.probing = true
+	return true
+}
+
+func (cb *circuitBreaker) success() {
+	cb.mtx.Lock()
+	defer cb.mtx.Unlock()
+
+	cb.failures = 0
+	cb.openUntil = time.Time{}
+	cb.probing = false
+}
+
+// release ends a call which neither succeeded nor failed, such as one canceled by
+// the caller, letting another trial call through.
+func (cb *circuitBreaker) release() {
+	cb.mtx.Lock()
+	defer cb.mtx.Unlock()
+
+	cb.probing = false
+}
+
+func (cb *circuitBreaker) failure() {
+	cb.mtx.Lock()
+	defer cb.mtx.Unlock()
+
+	cb.probing = false
+	cb.failures++
+	if cb.threshold > 0 && cb.failures >= cb.threshold {
+		cb.openUntil = time.Now().Add(cb.cooldown)
+	}
+}
@@ -0,0 +1,120 @@
+// Copyright (c) 2025 Suricata Contributors
+// Original Author: Stefano Scafiti
+//
+// This file is part of Suricata: Type-Safe AI Agents for Go.
+//
+// Licensed under the MIT License. You may obtain a copy of the License at
+//
+//	https://opensource.org/licenses/MIT
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package runtime_test
+
+import (
+	"context"
+	"errors"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/ostafen/suricata/runtime"
+)
+
+// countingInvoker replies with reply, or fails if reply is empty, counting its calls.
+func countingInvoker(calls *atomic.Int32, reply string) runtime.InvokerFunc {
+	return func(ctx context.Context, system string, messages []runtime.Message) (string, error) {
+		calls.Add(1)
+		if reply == "" {
+			return "", errors.New("provider down")
+		}
+		return reply, nil
+	}
+}
+
+func TestFallbackInvoker(t *testing.T) {
+	var primary, secondary atomic.Int32
+	inv := runtime.NewFallbackInvoker(runtime.FallbackOptions{}, countingInvoker(&primary, ""), countingInvoker(&secondary, "ok"))
+
+	out, err := inv.Invoke(context.Background(), "", nil)
+	if err != nil || out != "ok" {
+		t.Fatalf("expected the secondary invoker to reply, got %q, %v", out, err)
+	}
+	if primary.Load() != 1 || secondary.Load() != 1 {
+		t.Errorf("expected one call per invoker, got %d and %d", primary.Load(), secondary.Load())
+	}
+
+	inv = runtime.NewFallbackInvoker(runtime.FallbackOptions{}, countingInvoker(&primary, ""))
+	if _, err := inv.Invoke(context.Background(), "", nil); !errors.Is(err, runtime.ErrNoAvailableInvoker) {
+		t.Errorf("expected ErrNoAvailableInvoker, got %v", err)
+	}
+}
+
+func TestFallbackInvoker_Timeout(t *testing.T) {
+	slow := runtime.InvokerFunc(func(ctx context.Context, system string, messages []runtime.Message) (string, error) {
+		<-ctx.Done()
+		return "", ctx.Err()
+	})
+
+	var calls atomic.Int32
+	inv := runtime.NewFallbackInvoker(runtime.FallbackOptions{Timeout: 10 * time.Millisecond}, slow, countingInvoker(&calls, "ok"))
+	if out, err := inv.Invoke(context.Background(), "", nil); err != nil || out != "ok" {
+		t.Errorf("expected the slow invoker to be skipped after its timeout, got %q, %v", out, err)
+	}
+}
+
+func TestFallbackInvoker_CircuitBreaker(t *testing.T) {
+	var fail atomic.Bool
+	fail.Store(true)
+
+	var primary, secondary atomic.Int32
+	started, release := make(chan struct{}), make(chan struct{})
+	flaky := runtime.InvokerFunc(func(ctx context.Context, system string, messages []runtime.Message) (string, error) {
+		if primary.Add(1) == 2 {
+			// The trial call, held until the test releases it
+			close(started)
+			<-release
+		}
+		if fail.Load() {
+			return "", errors.New("provider down")
+		}
+		return "primary", nil
+	})
+
+	opts := runtime.FallbackOptions{FailureThreshold: 1, Cooldown: 20 * time.Millisecond}
+	inv := runtime.NewFallbackInvoker(opts, flaky, countingInvoker(&secondary, "secondary"))
+
+	if out, _ := inv.Invoke(context.Background(), "", nil); out != "secondary" {
+		t.Fatalf("expected the call to fall back, got %q", out)
+	}
+	if out, _ := inv.Invoke(context.Background(), "", nil); out != "secondary" || primary.Load() != 1 {
+		t.Fatalf("expected the open breaker to skip the primary invoker, got %q after %d calls", out, primary.Load())
+	}
+
+	time.Sleep(2 * opts.Cooldown)
+	fail.Store(false)
+
+	done := make(chan string)
+	go func() {
+		out, _ := inv.Invoke(context.Background(), "", nil)
+		done <- out
+	}()
+	<-started
+
+	// While the trial call is in flight, the breaker stays closed to other callers
+	if out, _ := inv.Invoke(context.Background(), "", nil); out != "secondary" || primary.Load() != 2 {
+		t.Errorf("expected a single trial call, got %q after %d calls", out, primary.Load())
+	}
+
+	close(release)
+	if out := <-done; out != "primary" {
+		t.Fatalf("expected the trial call to succeed, got %q", out)
+	}
+	if out, _ := inv.Invoke(context.Background(), "", nil); out != "primary" {
+		t.Errorf("expected the breaker to close after a successful trial, got %q", out)
+	}
+}
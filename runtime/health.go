@@ -0,0 +1,45 @@
+// Copyright (c) 2025 Suricata Contributors
+// Original Author: Stefano Scafiti
+//
+// This file is part of Suricata: Type-Safe AI Agents for Go.
+//
+// Licensed under the MIT License. You may obtain a copy of the License at
+//
+//	https://opensource.org/licenses/MIT
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package runtime
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"slices"
+)
+
+// ErrModelNotFound is returned by HealthChecker.Ping when the provider does not serve the model of the invoker.
+var ErrModelNotFound = errors.New("model not found")
+
+// HealthChecker is implemented by invokers which can check the connection to their provider,
+// so that applications can report configuration errors at startup, before the first agent call.
+type HealthChecker interface {
+	// Ping verifies that the provider is reachable, accepts the credentials of the invoker
+	// and serves its model.
+	Ping(ctx context.Context) error
+
+	// ListModels returns the names of the models available from the provider.
+	ListModels(ctx context.Context) ([]string, error)
+}
+
+// CheckModel returns an error wrapping ErrModelNotFound if model is not one of models.
+func CheckModel(model string, models []string) error {
+	if slices.Contains(models, model) {
+		return nil
+	}
+	return fmt.Errorf("%w: %q is not one of the %d available models", ErrModelNotFound, model, len(models))
+}
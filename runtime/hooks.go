@@ -0,0 +1,101 @@
+// Copyright (c) 2025 Suricata Contributors
+// Original Author: Stefano Scafiti
+//
+// This file is part of Suricata: Type-Safe AI Agents for Go.
+//
+// Licensed under the MIT License. You may obtain a copy of the License at
+//
+//	https://opensource.org/licenses/MIT
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package runtime
+
+import "context"
+
+// Hooks are callbacks invoked by the runtime at each step of a request.
+// All fields are optional. Since tool calls may run concurrently,
+// OnToolCall and OnToolResult must be safe for concurrent use.
+type Hooks struct {
+	OnPromptBuilt func(ctx context.Context, prompt string)
+	OnLLMResponse func(ctx context.Context, response string)
+	// OnValidationError is called when a model response fails parsing or schema validation.
+	OnValidationError func(ctx context.Context, err error)
+	OnToolCall        func(ctx context.Context, name string, in any)
+	OnToolResult      func(ctx context.Context, name string, out any, err error)
+	OnFinalOutput     func(ctx context.Context, out any)
+	OnError           func(ctx context.Context, err error)
+	// OnContextExceeded is called when the estimated tokens of a request exceed the
+	// context window of the model (see WithContextCheck).
+	OnContextExceeded func(ctx context.Context, tokens, limit int)
+}
+
+type hookList []Hooks
+
+func (hl hookList) promptBuilt(ctx context.Context, prompt string) {
+	for _, h := range hl {
+		if h.OnPromptBuilt != nil {
+			h.OnPromptBuilt(ctx, prompt)
+		}
+	}
+}
+
+func (hl hookList) llmResponse(ctx context.Context, response string) {
+	for _, h := range hl {
+		if h.OnLLMResponse != nil {
+			h.OnLLMResponse(ctx, response)
+		}
+	}
+}
+
+func (hl hookList) validationError(ctx context.Context, err error) {
+	for _, h := range hl {
+		if h.OnValidationError != nil {
+			h.OnValidationError(ctx, err)
+		}
+	}
+}
+
+func (hl hookList) toolCall(ctx context.Context, name string, in any) {
+	for _, h := range hl {
+		if h.OnToolCall != nil {
+			h.OnToolCall(ctx, name, in)
+		}
+	}
+}
+
+func (hl hookList) toolResult(ctx context.Context, name string, out any, err error) {
+	for _, h := range hl {
+		if h.OnToolResult != nil {
+			h.OnToolResult(ctx, name, out, err)
+		}
+	}
+}
+
+func (hl hookList) finalOutput(ctx context.Context, out any) {
+	for _, h := range hl {
+		if h.OnFinalOutput != nil {
+			h.OnFinalOutput(ctx, out)
+		}
+	}
+}
+
+func (hl hookList) error(ctx context.Context, err error) {
+	for _, h := range hl {
+		if h.OnError != nil {
+			h.OnError(ctx, err)
+		}
+	}
+}
+
+func (hl hookList) contextExceeded(ctx context.Context, tokens, limit int) {
+	for _, h := range hl {
+		if h.OnContextExceeded != nil {
+			h.OnContextExceeded(ctx, tokens, limit)
+		}
+	}
+}
@@ -0,0 +1,162 @@
+// Copyright (c) 2025 Suricata Contributors
+// Original Author: Stefano Scafiti
+//
+// This file is part of Suricata: Type-Safe AI Agents for Go.
+//
+// Licensed under the MIT License. You may obtain a copy of the License at
+//
+//	https://opensource.org/licenses/MIT
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package runtime
+
+import (
+	"context"
+	"crypto/tls"
+	"crypto/x509"
+	"fmt"
+	"net/http"
+	"net/url"
+	"os"
+	"time"
+)
+
+// HTTPConfig configures the HTTP client invokers and embedders use to reach their provider.
+type HTTPConfig struct {
+	// Timeout limits each request, including its retries and reading the response body,
+	// so it also bounds streamed responses. Zero means no timeout.
+	Timeout time.Duration
+
+	// Retry controls the retries of transient errors, such as DefaultBackoffPolicy().
+	// The zero value disables them.
+	Retry BackoffPolicy
+
+	// ProxyURL is the URL of the proxy requests are sent through. Empty means the proxy
+	// set by the HTTP_PROXY, HTTPS_PROXY and NO_PROXY environment variables.
+	ProxyURL string
+
+	// CAFile is a PEM file of certificate authorities trusted in addition to the system ones,
+	// such as the one of a TLS-intercepting corporate proxy.
+	CAFile string
+
+	// TLSConfig is the TLS configuration of the client. CAFile is added to its root CAs.
+	TLSConfig *tls.Config
+
+	// Headers are set on every request, e.g. to route requests through an AI gateway.
+	Headers map[string]string
+
+	// Auth, if set, authorizes every request, after Headers and the credentials of the
+	// invoker are set, so it can replace them with short-lived ones.
+	Auth AuthProvider
+}
+
+// DefaultHTTPClient is the client used by the invokers and embedders of this module
+// when none is configured. It does not retry requests, which is left to the caller,
+// e.g. ratelimit.NewInvoker or a client built by NewHTTPClient with a Retry policy.
+var DefaultHTTPClient = &http.Client{}
+
+// AuthProvider sets the credentials of a request.
+type AuthProvider interface {
+	Authorize(req *http.Request) error
+}
+
+// AuthFunc adapts an ordinary function to the AuthProvider interface.
+type AuthFunc func(req *http.Request) error
+
+func (f AuthFunc) Authorize(req *http.Request) error {
+	return f(req)
+}
+
+// BearerToken returns an AuthProvider setting the Authorization header to the token
+// returned by token, which is called for each request and is responsible for caching
+// and refreshing it.
+func BearerToken(token func(ctx context.Context) (string, error)) AuthProvider {
+	return AuthFunc(func(req *http.Request) error {
+		t, err := token(req.Context())
+		if err != nil {
+			return fmt.Errorf("auth: %w", err)
+		}
+		req.Header.Set("Authorization", "Bearer "+t)
+		return nil
+	})
+}
+
+// headerTransport sets the headers and credentials of requests before sending them through next.
+type headerTransport struct {
+	next    http.RoundTripper
+	headers map[string]string
+	auth    AuthProvider
+}
+
+func (t *headerTransport) RoundTrip(req *http.Request) (*http.Response, error) {
+	// A RoundTripper must not modify the request it is given
+	req = req.Clone(req.Context())
+	for k, v := range t.headers {
+		req.Header.Set(k, v)
+	}
+
+	if t.auth != nil {
+		if err := t.auth.Authorize(req); err != nil {
+			if req.Body != nil {
+				req.Body.Close()
+			}
+			return nil, err
+		}
+	}
+	return t.next.RoundTrip(req)
+}
+
+// NewHTTPClient returns an HTTP client configured by cfg.
+func NewHTTPClient(cfg HTTPConfig) (*http.Client, error) {
+	transport := http.DefaultTransport.(*http.Transport).Clone()
+
+	if cfg.ProxyURL != "" {
+		proxy, err := url.Parse(cfg.ProxyURL)
+		if err != nil {
+			return nil, fmt.Errorf("invalid proxy URL: %w", err)
+		}
+		transport.Proxy = http.ProxyURL(proxy)
+	}
+
+	if cfg.TLSConfig != nil {
+		transport.TLSClientConfig = cfg.TLSConfig.Clone()
+	}
+
+	if cfg.CAFile != "" {
+		pem, err := os.ReadFile(cfg.CAFile)
+		if err != nil {
+			return nil, err
+		}
+
+		if transport.TLSClientConfig == nil {
+			transport.TLSClientConfig = &tls.Config{}
+		}
+
+		pool := transport.TLSClientConfig.RootCAs
+		if pool == nil {
+			if pool, err = x509.SystemCertPool(); err != nil {
+				pool = x509.NewCertPool()
+			}
+		}
+		if !pool.AppendCertsFromPEM(pem) {
+			return nil, fmt.Errorf("%s: no certificates found", cfg.CAFile)
+		}
+		transport.TLSClientConfig.RootCAs = pool
+	}
+
+	var next http.RoundTripper = transport
+	if len(cfg.Headers) > 0 || cfg.Auth != nil {
+		next = &headerTransport{next: next, headers: cfg.Headers, auth: cfg.Auth}
+	}
+
+	if cfg.Retry.MaxAttempts > 1 {
+		// Retries are outside of headerTransport, so that credentials are refreshed on each attempt
+		next = &retryTransport{next: next, policy: cfg.Retry}
+	}
+	return &http.Client{Transport: next, Timeout: cfg.Timeout}, nil
+}
@@ -0,0 +1,162 @@
+// Copyright (c) 2025 Suricata Contributors
+// Original Author: Stefano Scafiti
+//
+// This file is part of Suricata: Type-Safe AI Agents for Go.
+//
+// Licensed under the MIT License. You may obtain a copy of the License at
+//
+//	https://opensource.org/licenses/MIT
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package runtime_test
+
+import (
+	"context"
+	"encoding/pem"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/ostafen/suricata/runtime"
+)
+
+func TestNewHTTPClient(t *testing.T) {
+	t.Run("proxy", func(t *testing.T) {
+		var host string
+		proxy := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			host = r.Host
+		}))
+		defer proxy.Close()
+
+		client, err := runtime.NewHTTPClient(runtime.HTTPConfig{ProxyURL: proxy.URL})
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		resp, err := client.Get("http://provider.invalid/v1/chat")
+		if err != nil {
+			t.Fatal(err)
+		}
+		resp.Body.Close()
+
+		if host != "provider.invalid" {
+			t.Errorf("expected the request to go through the proxy, got host %q", host)
+		}
+	})
+
+	t.Run("timeout", func(t *testing.T) {
+		srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			time.Sleep(200 * time.Millisecond)
+		}))
+		defer srv.Close()
+
+		client, err := runtime.NewHTTPClient(runtime.HTTPConfig{Timeout: 20 * time.Millisecond})
+		if err != nil {
+			t.Fatal(err)
+		}
+		if _, err := client.Get(srv.URL); err == nil {
+			t.Fatal("expected a timeout error")
+		}
+	})
+
+	t.Run("CA file", func(t *testing.T) {
+		srv := httptest.NewTLSServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {}))
+		defer srv.Close()
+
+		caFile := filepath.Join(t.TempDir(), "ca.pem")
+		cert := pem.EncodeToMemory(&pem.Block{Type: "CERTIFICATE", Bytes: srv.Certificate().Raw})
+		if err := os.WriteFile(caFile, cert, 0o600); err != nil {
+			t.Fatal(err)
+		}
+
+		untrusted, err := runtime.NewHTTPClient(runtime.HTTPConfig{})
+		if err != nil {
+			t.Fatal(err)
+		}
+		if _, err := untrusted.Get(srv.URL); err == nil {
+			t.Fatal("expected a certificate error without the CA file")
+		}
+
+		client, err := runtime.NewHTTPClient(runtime.HTTPConfig{CAFile: caFile})
+		if err != nil {
+			t.Fatal(err)
+		}
+		resp, err := client.Get(srv.URL)
+		if err != nil {
+			t.Fatal(err)
+		}
+		resp.Body.Close()
+	})
+
+	t.Run("headers and auth", func(t *testing.T) {
+		var got http.Header
+		srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			got = r.Header
+		}))
+		defer srv.Close()
+
+		tokens := 0
+		client, err := runtime.NewHTTPClient(runtime.HTTPConfig{
+			Headers: map[string]string{"X-Gateway-Route": "agents", "Authorization": "Bearer static"},
+			Auth: runtime.BearerToken(func(ctx context.Context) (string, error) {
+				tokens++
+				return fmt.Sprintf("token-%d", tokens), nil
+			}),
+		})
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		for i := 0; i < 2; i++ {
+			req, _ := http.NewRequest(http.MethodGet, srv.URL, nil)
+			req.Header.Set("Authorization", "Bearer api-key")
+
+			resp, err := client.Do(req)
+			if err != nil {
+				t.Fatal(err)
+			}
+			resp.Body.Close()
+
+			if req.Header.Get("Authorization") != "Bearer api-key" {
+				t.Fatal("the request of the caller must not be modified")
+			}
+		}
+
+		if got.Get("X-Gateway-Route") != "agents" || got.Get("Authorization") != "Bearer token-2" {
+			t.Errorf("unexpected headers: %v", got)
+		}
+	})
+
+	t.Run("auth error", func(t *testing.T) {
+		client, err := runtime.NewHTTPClient(runtime.HTTPConfig{
+			Auth: runtime.AuthFunc(func(req *http.Request) error { return errors.New("expired") }),
+		})
+		if err != nil {
+			t.Fatal(err)
+		}
+		if _, err := client.Get("http://provider.invalid"); err == nil || !strings.Contains(err.Error(), "expired") {
+			t.Fatalf("expected the auth error, got %v", err)
+		}
+	})
+
+	t.Run("invalid CA file", func(t *testing.T) {
+		caFile := filepath.Join(t.TempDir(), "ca.pem")
+		if err := os.WriteFile(caFile, []byte("not a certificate"), 0o600); err != nil {
+			t.Fatal(err)
+		}
+		if _, err := runtime.NewHTTPClient(runtime.HTTPConfig{CAFile: caFile}); err == nil {
+			t.Fatal("expected an error")
+		}
+	})
+}
@@ -0,0 +1,166 @@
+// Copyright (c) 2025 Suricata Contributors
+// Original Author: Stefano Scafiti
+//
+// This file is part of Suricata: Type-Safe AI Agents for Go.
+//
+// Licensed under the MIT License. You may obtain a copy of the License at
+//
+//	https://opensource.org/licenses/MIT
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+// Package httpserve exposes agent actions as JSON-over-HTTP endpoints.
+// It backs the handlers produced by "suricata gen --http".
+package httpserve
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"strings"
+
+	"github.com/ostafen/suricata/runtime"
+	"github.com/xeipuuv/gojsonschema"
+)
+
+// MaxRequestSize is the maximum size, in bytes, of the body of a request.
+const MaxRequestSize = 8 << 20
+
+// Error is the body of error responses.
+type Error struct {
+	Error string `json:"error"`
+}
+
+// Action returns a handler decoding the request body into the input of fn, validated
+// against schema, and replying with the JSON encoding of its output.
+func Action[In any](schema gojsonschema.JSONLoader, fn func(ctx context.Context, in *In) (any, error)) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		in, ok := decodeInput[In](w, r, schema)
+		if !ok {
+			return
+		}
+
+		out, err := fn(r.Context(), in)
+		if err != nil {
+			writeError(w, statusForError(err), err)
+			return
+		}
+		writeJSON(w, http.StatusOK, out)
+	})
+}
+
+// StreamAction returns a handler for a streaming action, replying with a stream of server-sent events.
+// A "delta" event is sent for each chunk of the model responses, carrying the chunk as a JSON string,
+// followed by a single "result" event with the output of the action, or an "error" event.
+func StreamAction[In, Out any](schema gojsonschema.JSONLoader, fn func(ctx context.Context, in *In) *runtime.Stream[Out]) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		flusher, ok := w.(http.Flusher)
+		if !ok {
+			writeError(w, http.StatusInternalServerError, errors.New("streaming not supported"))
+			return
+		}
+
+		in, ok := decodeInput[In](w, r, schema)
+		if !ok {
+			return
+		}
+
+		stream := fn(r.Context(), in)
+
+		w.Header().Set("Content-Type", "text/event-stream")
+		w.Header().Set("Cache-Control", "no-cache")
+		w.WriteHeader(http.StatusOK)
+
+		for delta := range stream.Deltas() {
+			writeEvent(w, "delta", delta)
+			flusher.Flush()
+		}
+
+		out, err := stream.Result()
+		if err != nil {
+			writeEvent(w, "error", Error{Error: err.Error()})
+		} else {
+			writeEvent(w, "result", out)
+		}
+		flusher.Flush()
+	})
+}
+
+// Document returns a handler serving a static JSON document, such as the OpenAPI description of an agent.
+func Document(doc string) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "application/json")
+		io.WriteString(w, doc)
+	})
+}
+
+func decodeInput[In any](w http.ResponseWriter, r *http.Request, schema gojsonschema.JSONLoader) (*In, bool) {
+	data, err := io.ReadAll(http.MaxBytesReader(w, r.Body, MaxRequestSize))
+	if err != nil {
+		writeError(w, http.StatusRequestEntityTooLarge, err)
+		return nil, false
+	}
+
+	in := new(In)
+	if err := runtime.UnmarshalValidate(data, in, schema); err != nil {
+		// The validator reports schema mismatches as invalid outputs
+		var schemaErr *runtime.SchemaError
+		if errors.As(err, &schemaErr) {
+			err = fmt.Errorf("input does not match the schema: %s", violations(schemaErr))
+		} else if errors.Is(err, runtime.ErrInvalidOutput) {
+			err = errors.New("input does not match the schema")
+		}
+		writeError(w, http.StatusBadRequest, fmt.Errorf("invalid input: %w", err))
+		return nil, false
+	}
+	return in, true
+}
+
+func violations(err *runtime.SchemaError) string {
+	details := make([]string, len(err.Violations))
+	for i, v := range err.Violations {
+		details[i] = v.String()
+	}
+	return strings.Join(details, "; ")
+}
+
+func statusForError(err error) int {
+	switch {
+	case errors.Is(err, context.DeadlineExceeded):
+		return http.StatusGatewayTimeout
+	case errors.Is(err, context.Canceled):
+		// The client went away: the status is never delivered
+		return http.StatusServiceUnavailable
+	}
+
+	var provErr *runtime.ProviderError
+	if errors.As(err, &provErr) {
+		return http.StatusBadGateway
+	}
+	return http.StatusInternalServerError
+}
+
+func writeJSON(w http.ResponseWriter, status int, v any) {
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(status)
+	json.NewEncoder(w).Encode(v)
+}
+
+func writeError(w http.ResponseWriter, status int, err error) {
+	writeJSON(w, status, Error{Error: err.Error()})
+}
+
+func writeEvent(w io.Writer, event string, v any) {
+	data, err := json.Marshal(v)
+	if err != nil {
+		event, data = "error", []byte(fmt.Sprintf("{\"error\":%q}", err.Error()))
+	}
+	fmt.Fprintf(w, "event: %s\ndata: %s\n\n", event, data)
+}
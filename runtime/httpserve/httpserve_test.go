@@ -0,0 +1,96 @@
+// Copyright (c) 2025 Suricata Contributors
+// Original Author: Stefano Scafiti
+//
+// This file is part of Suricata: Type-Safe AI Agents for Go.
+//
+// Licensed under the MIT License. You may obtain a copy of the License at
+//
+//	https://opensource.org/licenses/MIT
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package httpserve_test
+
+import (
+	"context"
+	"errors"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/ostafen/suricata/runtime"
+	"github.com/ostafen/suricata/runtime/httpserve"
+	"github.com/xeipuuv/gojsonschema"
+)
+
+type input struct {
+	Text string `json:"text"`
+}
+
+var inputSchema = gojsonschema.NewStringLoader(`{"type":"object","properties":{"text":{"type":"string"}},"required":["text"]}`)
+
+func post(t *testing.T, h http.Handler, body string) (int, string) {
+	t.Helper()
+
+	rec := httptest.NewRecorder()
+	h.ServeHTTP(rec, httptest.NewRequest(http.MethodPost, "/", strings.NewReader(body)))
+
+	data, err := io.ReadAll(rec.Result().Body)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	return rec.Code, strings.TrimSpace(string(data))
+}
+
+func TestAction(t *testing.T) {
+	h := httpserve.Action(inputSchema, func(ctx context.Context, in *input) (any, error) {
+		if in.Text == "fail" {
+			return nil, errors.New("boom")
+		}
+		return &input{Text: strings.ToUpper(in.Text)}, nil
+	})
+
+	tests := []struct {
+		body   string
+		status int
+		resp   string
+	}{
+		{`{"text": "hi"}`, http.StatusOK, `{"text":"HI"}`},
+		{`{"text": 1}`, http.StatusBadRequest, `{"error":"invalid input: input does not match the schema: text: Invalid type. Expected: string, given: integer"}`},
+		{`{"text": "fail"}`, http.StatusInternalServerError, `{"error":"boom"}`},
+	}
+
+	for _, test := range tests {
+		status, resp := post(t, h, test.body)
+		if status != test.status || resp != test.resp {
+			t.Errorf("%s: expected %d %s, got %d %s", test.body, test.status, test.resp, status, resp)
+		}
+	}
+}
+
+func TestStreamAction(t *testing.T) {
+	h := httpserve.StreamAction(inputSchema, func(ctx context.Context, in *input) *runtime.Stream[string] {
+		return runtime.StartStream(ctx, func(ctx context.Context, out *string, onDelta func(string)) error {
+			onDelta("a")
+			onDelta("b")
+			*out = "ab"
+			return nil
+		})
+	})
+
+	status, resp := post(t, h, `{"text": "hi"}`)
+	if status != http.StatusOK {
+		t.Fatalf("unexpected status %d", status)
+	}
+
+	expected := "event: delta\ndata: \"a\"\n\nevent: delta\ndata: \"b\"\n\nevent: result\ndata: \"ab\""
+	if resp != expected {
+		t.Errorf("expected %q, got %q", expected, resp)
+	}
+}
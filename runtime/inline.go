@@ -0,0 +1,212 @@
+// Copyright (c) 2025 Suricata Contributors
+// Original Author: Stefano Scafiti
+//
+// This file is part of Suricata: Type-Safe AI Agents for Go.
+//
+// Licensed under the MIT License. You may obtain a copy of the License at
+//
+//	https://opensource.org/licenses/MIT
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package runtime
+
+import (
+	"encoding/json"
+	"maps"
+	"reflect"
+	"slices"
+	"strings"
+	"sync"
+
+	"github.com/xeipuuv/gojsonschema"
+)
+
+// WithInlineSchemas makes the runtime inline the definitions referenced with "$ref" in the output
+// and tool schemas of requests, for models which do not follow references. The generated schemas
+// define the messages shared by several fields once, so inlining them makes prompts longer.
+// Definitions which reference themselves cannot be inlined, and are kept.
+func WithInlineSchemas() Option {
+	return func(r *Runtime) {
+		r.inlineSchemas = true
+	}
+}
+
+// inlineRequestSchemas replaces the output and tool schemas of req with their inlined version,
+// with WithInlineSchemas. As inlining preserves the meaning of the schemas, outputs are validated
+// against the schemas shown to the model.
+func (r *Runtime) inlineRequestSchemas(req *Request) {
+	if !r.inlineSchemas {
+		return
+	}
+
+	if req.OutputSchema != nil {
+		req.OutputSchema = InlineSchema(req.OutputSchema)
+	}
+	if len(req.ToolSpecs) > 0 {
+		req.ToolSpecs = slices.Clone(req.ToolSpecs)
+		for i := range req.ToolSpecs {
+			if req.ToolSpecs[i].Schema != nil {
+				req.ToolSpecs[i].Schema = InlineSchema(req.ToolSpecs[i].Schema)
+			}
+		}
+	}
+}
+
+var inlineCache = struct {
+	sync.Mutex
+	schemas map[gojsonschema.JSONLoader]gojsonschema.JSONLoader
+}{
+	schemas: make(map[gojsonschema.JSONLoader]gojsonschema.JSONLoader),
+}
+
+// InlineSchema returns the schema loaded by loader, where the references to its definitions
+// are replaced by the definitions themselves. References to definitions which reference
+// themselves, directly or not, are kept together with their definitions. If the schema has no
+// references or cannot be loaded, loader is returned. Like CompileSchema, the result is cached
+// for pointer loaders.
+func InlineSchema(loader gojsonschema.JSONLoader) gojsonschema.JSONLoader {
+	cacheable := reflect.TypeOf(loader).Kind() == reflect.Pointer
+	if cacheable {
+		inlineCache.Lock()
+		inlined, ok := inlineCache.schemas[loader]
+		inlineCache.Unlock()
+		if ok {
+			return inlined
+		}
+	}
+
+	inlined := loader
+	if doc, err := loader.LoadJSON(); err == nil {
+		if schema, ok := inlineRefs(doc); ok {
+			if data, err := json.Marshal(schema); err == nil {
+				inlined = NewSchema(string(data))
+			}
+		}
+	}
+
+	if cacheable {
+		inlineCache.Lock()
+		defer inlineCache.Unlock()
+
+		if len(inlineCache.schemas) >= SchemaCacheSize {
+			clear(inlineCache.schemas)
+		}
+		inlineCache.schemas[loader] = inlined
+	}
+	return inlined
+}
+
+// inlineRefs returns root with its references inlined, and reports whether there were any.
+func inlineRefs(root any) (any, bool) {
+	s, ok := root.(map[string]any)
+	if !ok || !hasRefs(s) {
+		return root, false
+	}
+
+	in := &inliner{root: root, expanding: make(map[string]bool), kept: make(map[string]bool)}
+	out := in.inline(s).(map[string]any)
+	delete(out, "$defs")
+	delete(out, "definitions")
+
+	// Definitions referenced by the kept references, which may in turn keep others
+	for done := make(map[string]bool); len(done) < len(in.kept); {
+		for _, ref := range slices.Sorted(maps.Keys(in.kept)) {
+			if done[ref] {
+				continue
+			}
+			done[ref] = true
+
+			keyword, name, ok := strings.Cut(strings.TrimPrefix(ref, "#/"), "/")
+			if !ok || (keyword != "$defs" && keyword != "definitions") || strings.Contains(name, "/") {
+				return root, false
+			}
+
+			in.expanding[ref] = true
+			def := in.inline(resolveRef(root, ref))
+			delete(in.expanding, ref)
+
+			defs, _ := out[keyword].(map[string]any)
+			if defs == nil {
+				defs = make(map[string]any)
+				out[keyword] = defs
+			}
+			defs[name] = def
+		}
+	}
+	return out, true
+}
+
+type inliner struct {
+	root      any
+	expanding map[string]bool // References being inlined
+	kept      map[string]bool // References to recursive definitions
+}
+
+func (in *inliner) inline(v any) any {
+	switch v := v.(type) {
+	case map[string]any:
+		if ref, ok := v["$ref"].(string); ok && strings.HasPrefix(ref, "#") {
+			if in.expanding[ref] {
+				in.kept[ref] = true
+				return v
+			}
+
+			target, ok := resolveRef(in.root, ref).(map[string]any)
+			if !ok {
+				return v
+			}
+
+			in.expanding[ref] = true
+			inlined := in.inline(target).(map[string]any)
+			delete(in.expanding, ref)
+
+			// Keywords next to the reference, such as a description, take precedence
+			if in.kept[ref] {
+				return v
+			}
+			inlined = maps.Clone(inlined)
+			for key, value := range v {
+				if key != "$ref" {
+					inlined[key] = in.inline(value)
+				}
+			}
+			return inlined
+		}
+
+		out := make(map[string]any, len(v))
+		for key, value := range v {
+			out[key] = in.inline(value)
+		}
+		return out
+	case []any:
+		out := make([]any, len(v))
+		for i, item := range v {
+			out[i] = in.inline(item)
+		}
+		return out
+	}
+	return v
+}
+
+// hasRefs reports whether the schema holds a "$ref".
+func hasRefs(v any) bool {
+	switch v := v.(type) {
+	case map[string]any:
+		if _, ok := v["$ref"]; ok {
+			return true
+		}
+		for _, value := range v {
+			if hasRefs(value) {
+				return true
+			}
+		}
+	case []any:
+		return slices.ContainsFunc(v, hasRefs)
+	}
+	return false
+}
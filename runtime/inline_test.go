@@ -0,0 +1,95 @@
+// Copyright (c) 2025 Suricata Contributors
+// Original Author: Stefano Scafiti
+//
+// This file is part of Suricata: Type-Safe AI Agents for Go.
+//
+// Licensed under the MIT License. You may obtain a copy of the License at
+//
+//	https://opensource.org/licenses/MIT
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package runtime_test
+
+import (
+	"context"
+	"encoding/json"
+	"strings"
+	"testing"
+
+	"github.com/ostafen/suricata/runtime"
+)
+
+func TestInlineSchema(t *testing.T) {
+	schema := runtime.NewSchema(`{
+		"type": "object",
+		"properties": {
+			"from": {"$ref": "#/$defs/Location"},
+			"to": {"$ref": "#/$defs/Location", "description": "The destination"},
+			"category": {"$ref": "#/$defs/Category"}
+		},
+		"$defs": {
+			"Location": {"type": "object", "properties": {"city": {"type": "string"}}, "description": "A place"},
+			"Category": {"type": "object", "properties": {"children": {"type": "array", "items": {"$ref": "#/$defs/Category"}}}}
+		}
+	}`)
+
+	v, err := runtime.InlineSchema(schema).LoadJSON()
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	data, _ := json.Marshal(v)
+
+	expected := `{"$defs":{"Category":{"properties":{"children":{"items":{"$ref":"#/$defs/Category"},"type":"array"}},"type":"object"}},` +
+		`"properties":{"category":{"$ref":"#/$defs/Category"},` +
+		`"from":{"description":"A place","properties":{"city":{"type":"string"}},"type":"object"},` +
+		`"to":{"description":"The destination","properties":{"city":{"type":"string"}},"type":"object"}},"type":"object"}`
+	if string(data) != expected {
+		t.Errorf("expected:\n%s\ngot:\n%s", expected, data)
+	}
+
+	if runtime.InlineSchema(schema) != runtime.InlineSchema(schema) {
+		t.Errorf("expected the inlined schema to be cached")
+	}
+
+	plain := runtime.NewSchema(`{"type": "object"}`)
+	if runtime.InlineSchema(plain) != plain {
+		t.Errorf("expected a schema without references to be returned as is")
+	}
+}
+
+func TestWithInlineSchemas(t *testing.T) {
+	var prompt string
+	inv := runtime.InvokerFunc(func(ctx context.Context, system string, messages []runtime.Message) (string, error) {
+		prompt = messages[len(messages)-1].Content
+		return `{"to": {"city": "Catania"}}`, nil
+	})
+
+	var out struct {
+		To struct {
+			City string `json:"city"`
+		} `json:"to"`
+	}
+	err := runtime.NewRuntime(inv, runtime.WithInlineSchemas()).Invoke(context.Background(), runtime.Request{
+		PromptTemplate: "Plan a trip.",
+		Input:          &struct{}{},
+		InputSchema:    runtime.NewSchema(`{"type":"object"}`),
+		Output:         &out,
+		OutputSchema: runtime.NewSchema(`{"type":"object","properties":{"to":{"$ref":"#/$defs/Location"}},"required":["to"],` +
+			`"$defs":{"Location":{"type":"object","properties":{"city":{"type":"string"}},"required":["city"]}}}`),
+	})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	if strings.Contains(prompt, "$ref") || !strings.Contains(prompt, `"to":{"properties":{"city"`) {
+		t.Errorf("expected the output schema to be inlined in the prompt, got:\n%s", prompt)
+	}
+	if out.To.City != "Catania" {
+		t.Errorf("unexpected output: %+v", out)
+	}
+}
@@ -14,19 +14,59 @@
 // limitations under the License.
 package runtime
 
-import "context"
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+)
 
+// Role is the author of a message. It is shared by every invoker package,
+// each mapping it to the roles of its provider.
 type Role uint8
 
 const (
-	RoleSystem = iota
+	RoleSystem Role = iota
 	RoleAgent
 	RoleUser
+	RoleTool // Output of a tool call. Providers lacking a matching role receive it as a user message.
 )
 
+func (r Role) String() string {
+	switch r {
+	case RoleSystem:
+		return "system"
+	case RoleAgent:
+		return "assistant"
+	case RoleUser:
+		return "user"
+	case RoleTool:
+		return "tool"
+	}
+	return fmt.Sprintf("Role(%d)", uint8(r))
+}
+
 type Message struct {
-	Role    Role
-	Content string
+	Role    Role   `json:"role"`
+	Content string `json:"content"`
+
+	ToolCall    *ToolCallRef `json:"tool_call,omitempty"`   // Call answered by a RoleTool message
+	Attachments []Attachment `json:"attachments,omitempty"` // Images sent along with a user message
+}
+
+// ToolCallRef identifies the tool call answered by a RoleTool message.
+type ToolCallRef struct {
+	ID   string          `json:"id"`
+	Name string          `json:"name"`
+	Args json.RawMessage `json:"args,omitempty"`
+}
+
+// Text returns the content of the message as plain text. Tool outputs are
+// prefixed by the tool name, for providers without a native tool role.
+func (m Message) Text() string {
+	if m.Role != RoleTool || m.ToolCall == nil {
+		return m.Content
+	}
+	return m.ToolCall.Name + " OUTPUT: " + m.Content
 }
 
 // Invoker sends a prompt string to an LLM and returns the raw string response.
@@ -35,32 +75,94 @@ type Invoker interface {
 }
 
 type ChatSession struct {
-	system   string
-	messages []Message
-	invoker  Invoker
+	system    string
+	memory    Memory
+	invoker   Invoker
+	compactor *HistoryCompactor
+	onDelta   func(delta string)
+	check     func(ctx context.Context, system string, messages []Message) error
 }
 
 func NewChatSession(invoker Invoker, systemPrompt string) *ChatSession {
+	return NewChatSessionWithMemory(invoker, systemPrompt, NewInMemory())
+}
+
+// NewChatSessionWithMemory creates a session whose history is read from and persisted to memory.
+func NewChatSessionWithMemory(invoker Invoker, systemPrompt string, memory Memory) *ChatSession {
 	return &ChatSession{
-		invoker:  invoker,
-		messages: nil,
-		system:   systemPrompt,
+		invoker: invoker,
+		memory:  memory,
+		system:  systemPrompt,
 	}
 }
 
+// SetCompactor enables automatic summarization of the history when it exceeds the compactor budget.
+func (chat *ChatSession) SetCompactor(compactor *HistoryCompactor) {
+	chat.compactor = compactor
+}
+
+// SetStreamHandler enables streaming: onDelta is called with each chunk of the
+// model responses as soon as it is received.
+func (chat *ChatSession) SetStreamHandler(onDelta func(delta string)) {
+	chat.onDelta = onDelta
+}
+
+// Add appends msg to the history.
+//
+// Deprecated: errors of the memory of the session are ignored. Use Append instead.
 func (chat *ChatSession) Add(msg Message) {
-	chat.messages = append(chat.messages, msg)
+	_ = chat.Append(context.Background(), msg)
+}
+
+// Append appends msgs to the history, saving them to the memory of the session.
+func (chat *ChatSession) Append(ctx context.Context, msgs ...Message) error {
+	return chat.memory.Append(ctx, msgs...)
+}
+
+// History returns the messages exchanged so far.
+func (chat *ChatSession) History(ctx context.Context) ([]Message, error) {
+	return chat.memory.History(ctx)
 }
 
 func (chat *ChatSession) Invoke(ctx context.Context, msg string) (string, error) {
-	chat.Add(Message{Role: RoleUser, Content: msg})
+	return chat.InvokeMessages(ctx, Message{Role: RoleUser, Content: msg})
+}
 
-	out, err := chat.invoker.Invoke(ctx, chat.system, chat.messages)
+// InvokeMessages appends msgs to the history, such as the outputs of the tool calls
+// requested by the last model response, and sends the history to the model.
+func (chat *ChatSession) InvokeMessages(ctx context.Context, msgs ...Message) (string, error) {
+	if err := chat.Append(ctx, msgs...); err != nil {
+		return "", fmt.Errorf("append message: %w", err)
+	}
+
+	messages, err := chat.memory.History(ctx)
+	if err != nil {
+		return "", fmt.Errorf("load history: %w", err)
+	}
+
+	messages, err = chat.compact(ctx, messages)
 	if err != nil {
 		return "", err
 	}
 
-	chat.Add(Message{Role: RoleAgent, Content: out})
+	if chat.check != nil {
+		if err := chat.check(ctx, chat.system, messages); err != nil {
+			return "", err
+		}
+	}
+
+	var out string
+	if chat.onDelta != nil {
+		out, err = InvokeStream(ctx, chat.invoker, chat.system, messages, chat.onDelta)
+	} else {
+		out, err = chat.invoker.Invoke(ctx, chat.system, messages)
+	}
+	if err != nil {
+		return "", err
+	}
 
+	if err := chat.Append(ctx, Message{Role: RoleAgent, Content: out}); err != nil {
+		return "", fmt.Errorf("append message: %w", err)
+	}
 	return out, nil
 }
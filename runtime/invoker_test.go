@@ -0,0 +1,36 @@
+// Copyright (c) 2025 Suricata Contributors
+// Original Author: Stefano Scafiti
+//
+// This file is part of Suricata: Type-Safe AI Agents for Go.
+//
+// Licensed under the MIT License. You may obtain a copy of the License at
+//
+//	https://opensource.org/licenses/MIT
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package runtime_test
+
+import (
+	"testing"
+
+	"github.com/ostafen/suricata/runtime"
+)
+
+func TestRole_String(t *testing.T) {
+	for role, expected := range map[runtime.Role]string{
+		runtime.RoleSystem: "system",
+		runtime.RoleAgent:  "assistant",
+		runtime.RoleUser:   "user",
+		runtime.RoleTool:   "tool",
+		runtime.Role(42):   "Role(42)",
+	} {
+		if s := role.String(); s != expected {
+			t.Errorf("expected %q, got %q", expected, s)
+		}
+	}
+}
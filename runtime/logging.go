@@ -0,0 +1,117 @@
+// Copyright (c) 2025 Suricata Contributors
+// Original Author: Stefano Scafiti
+//
+// This file is part of Suricata: Type-Safe AI Agents for Go.
+//
+// Licensed under the MIT License. You may obtain a copy of the License at
+//
+//	https://opensource.org/licenses/MIT
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package runtime
+
+import (
+	"context"
+	"crypto/rand"
+	"encoding/hex"
+	"log/slog"
+)
+
+type requestIDKey struct{}
+
+// WithRequestID returns a context carrying the given correlation ID.
+// When no ID is set, Runtime.Invoke generates a random one.
+func WithRequestID(ctx context.Context, id string) context.Context {
+	return context.WithValue(ctx, requestIDKey{}, id)
+}
+
+// RequestIDFromContext returns the correlation ID of the current request, if any.
+func RequestIDFromContext(ctx context.Context) string {
+	id, _ := ctx.Value(requestIDKey{}).(string)
+	return id
+}
+
+func newRequestID() string {
+	var b [8]byte
+	_, _ = rand.Read(b[:])
+	return hex.EncodeToString(b[:])
+}
+
+// LogLevels configures the level at which each kind of event is logged.
+type LogLevels struct {
+	Prompt          slog.Level
+	Response        slog.Level
+	ToolCall        slog.Level
+	ToolResult      slog.Level
+	ValidationError slog.Level
+	FinalOutput     slog.Level
+	Error           slog.Level
+	ContextExceeded slog.Level
+}
+
+func DefaultLogLevels() LogLevels {
+	return LogLevels{
+		Prompt:          slog.LevelDebug,
+		Response:        slog.LevelDebug,
+		ToolCall:        slog.LevelInfo,
+		ToolResult:      slog.LevelDebug,
+		ValidationError: slog.LevelWarn,
+		FinalOutput:     slog.LevelInfo,
+		Error:           slog.LevelError,
+		ContextExceeded: slog.LevelWarn,
+	}
+}
+
+// WithLogger logs prompts, responses, tool calls and validation failures to logger,
+// using the default log levels. Every record carries the request correlation ID, and
+// the prompt version when set.
+func WithLogger(logger *slog.Logger) Option {
+	return WithLoggerLevels(logger, DefaultLogLevels())
+}
+
+// WithLoggerLevels is like WithLogger, but allows to customize the level of each event.
+func WithLoggerLevels(logger *slog.Logger, levels LogLevels) Option {
+	log := func(ctx context.Context, level slog.Level, msg string, args ...any) {
+		args = append(args, slog.String("request_id", RequestIDFromContext(ctx)))
+		if version := PromptVersionFromContext(ctx); version != "" {
+			args = append(args, slog.String("prompt_version", version))
+		}
+		logger.Log(ctx, level, msg, args...)
+	}
+
+	return WithHooks(Hooks{
+		OnPromptBuilt: func(ctx context.Context, prompt string) {
+			log(ctx, levels.Prompt, "prompt built", slog.String("prompt", prompt))
+		},
+		OnLLMResponse: func(ctx context.Context, response string) {
+			log(ctx, levels.Response, "llm response", slog.String("response", response))
+		},
+		OnValidationError: func(ctx context.Context, err error) {
+			log(ctx, levels.ValidationError, "invalid model output", slog.Any("error", err))
+		},
+		OnToolCall: func(ctx context.Context, name string, in any) {
+			log(ctx, levels.ToolCall, "tool call", slog.String("tool", name), slog.Any("input", in))
+		},
+		OnToolResult: func(ctx context.Context, name string, out any, err error) {
+			if err != nil {
+				log(ctx, max(levels.ToolResult, slog.LevelWarn), "tool failed", slog.String("tool", name), slog.Any("error", err))
+				return
+			}
+			log(ctx, levels.ToolResult, "tool result", slog.String("tool", name), slog.Any("output", out))
+		},
+		OnFinalOutput: func(ctx context.Context, out any) {
+			log(ctx, levels.FinalOutput, "final output", slog.Any("output", out))
+		},
+		OnError: func(ctx context.Context, err error) {
+			log(ctx, levels.Error, "invocation failed", slog.Any("error", err))
+		},
+		OnContextExceeded: func(ctx context.Context, tokens, limit int) {
+			log(ctx, levels.ContextExceeded, "context window exceeded", slog.Int("tokens", tokens), slog.Int("limit", limit))
+		},
+	})
+}
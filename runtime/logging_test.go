@@ -0,0 +1,181 @@
+// Copyright (c) 2025 Suricata Contributors
+// Original Author: Stefano Scafiti
+//
+// This file is part of Suricata: Type-Safe AI Agents for Go.
+//
+// Licensed under the MIT License. You may obtain a copy of the License at
+//
+//	https://opensource.org/licenses/MIT
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package runtime_test
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"log/slog"
+	"strings"
+	"sync"
+	"testing"
+
+	"github.com/ostafen/suricata/runtime"
+	"github.com/ostafen/suricata/runtime/runtimetest"
+)
+
+type logRecord struct {
+	level slog.Level
+	msg   string
+	attrs map[string]any
+}
+
+// captureHandler is a slog.Handler keeping the records of all levels in memory.
+type captureHandler struct {
+	mtx     sync.Mutex
+	records []logRecord
+}
+
+func (h *captureHandler) Enabled(context.Context, slog.Level) bool { return true }
+
+func (h *captureHandler) Handle(_ context.Context, r slog.Record) error {
+	attrs := make(map[string]any)
+	r.Attrs(func(a slog.Attr) bool {
+		attrs[a.Key] = a.Value.Any()
+		return true
+	})
+
+	h.mtx.Lock()
+	defer h.mtx.Unlock()
+	h.records = append(h.records, logRecord{level: r.Level, msg: r.Message, attrs: attrs})
+	return nil
+}
+
+func (h *captureHandler) WithAttrs([]slog.Attr) slog.Handler { return h }
+
+func (h *captureHandler) WithGroup(string) slog.Handler { return h }
+
+// find returns the first record with the given message.
+func (h *captureHandler) find(msg string) (logRecord, bool) {
+	h.mtx.Lock()
+	defer h.mtx.Unlock()
+
+	for _, r := range h.records {
+		if r.msg == msg {
+			return r, true
+		}
+	}
+	return logRecord{}, false
+}
+
+func TestWithLogger(t *testing.T) {
+	mock := runtimetest.NewInvoker(t).Respond(
+		`{"name":"weather","args":{"city":"Rome"}}`,
+		`{"name":"forecast","args":{"city":"Rome"}}`,
+		`{"done":true,"out":"Sunny"}`,
+	)
+
+	h := &captureHandler{}
+	rt := runtime.NewRuntime(mock, runtime.WithLogger(slog.New(h)))
+
+	ctx := runtime.WithRequestID(context.Background(), "req-1")
+	err := rt.Invoke(ctx, runtime.Request{
+		PromptTemplate: "Weather in {{.city}}?",
+		PromptVersion:  "v2",
+		Input:          map[string]any{"city": "Rome"},
+		InputSchema:    runtime.NewSchema(`{"type":"object"}`),
+		Output:         new(string),
+		ToolUnmarshaller: func(name string, data []byte) (any, error) {
+			var args map[string]string
+			return args, json.Unmarshal(data, &args)
+		},
+		ToolInvoker: func(ctx context.Context, name string, in any) (any, error) {
+			if name == "forecast" {
+				return nil, errors.New("forecast unavailable")
+			}
+			return "sunny", nil
+		},
+	})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	tests := []struct {
+		msg   string
+		level slog.Level
+		key   string
+		value any
+	}{
+		{"llm response", slog.LevelDebug, "response", `{"name":"weather","args":{"city":"Rome"}}`},
+		{"tool call", slog.LevelInfo, "tool", "weather"},
+		{"tool result", slog.LevelDebug, "output", "sunny"},
+		{"tool failed", slog.LevelWarn, "tool", "forecast"},
+	}
+	for _, test := range tests {
+		r, ok := h.find(test.msg)
+		if !ok {
+			t.Errorf("expected a %q record", test.msg)
+			continue
+		}
+		if r.level != test.level {
+			t.Errorf("%s: expected level %v, got %v", test.msg, test.level, r.level)
+		}
+		if got := r.attrs[test.key]; got != test.value {
+			t.Errorf("%s: expected %s %v, got %v", test.msg, test.key, test.value, got)
+		}
+	}
+
+	if r, _ := h.find("prompt built"); r.level != slog.LevelDebug || !strings.Contains(fmt.Sprint(r.attrs["prompt"]), "Weather in Rome?") {
+		t.Errorf("expected a debug record of the prompt, got %+v", r)
+	}
+	if r, _ := h.find("final output"); r.level != slog.LevelInfo || *r.attrs["output"].(*string) != "Sunny" {
+		t.Errorf("expected an info record of the output, got %+v", r)
+	}
+
+	// Every record carries the correlation ID and the prompt version
+	for _, r := range h.records {
+		if r.attrs["request_id"] != "req-1" || r.attrs["prompt_version"] != "v2" {
+			t.Errorf("%s: missing correlation attributes: %v", r.msg, r.attrs)
+		}
+	}
+}
+
+func TestWithLoggerLevels(t *testing.T) {
+	mock := runtimetest.NewInvoker(t).Respond(`not a json`)
+
+	levels := runtime.DefaultLogLevels()
+	levels.ValidationError = slog.LevelError
+	h := &captureHandler{}
+	rt := runtime.NewRuntime(mock, runtime.WithLoggerLevels(slog.New(h), levels))
+
+	err := rt.Invoke(context.Background(), runtime.Request{
+		PromptTemplate: "Hello",
+		Input:          map[string]any{},
+		InputSchema:    runtime.NewSchema(`{"type":"object"}`),
+		Output:         &map[string]any{},
+		OutputSchema:   runtime.NewSchema(`{"type":"object"}`),
+	})
+	if !errors.Is(err, runtime.ErrInvalidOutput) {
+		t.Fatalf("expected ErrInvalidOutput, got %v", err)
+	}
+
+	invalid, ok := h.find("invalid model output")
+	if !ok || invalid.level != slog.LevelError || invalid.attrs["error"] == nil {
+		t.Errorf("expected an error record for the invalid output, got %+v", invalid)
+	}
+
+	failed, ok := h.find("invocation failed")
+	if !ok || failed.level != slog.LevelError {
+		t.Errorf("expected an error record for the failed invocation, got %+v", failed)
+	}
+
+	// A correlation ID is generated when none is set
+	if id, _ := failed.attrs["request_id"].(string); id == "" {
+		t.Errorf("expected a generated request ID, got %v", failed.attrs)
+	}
+}
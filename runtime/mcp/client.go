@@ -0,0 +1,454 @@
+// Copyright (c) 2025 Suricata Contributors
+// Original Author: Stefano Scafiti
+//
+// This file is part of Suricata: Type-Safe AI Agents for Go.
+//
+// Licensed under the MIT License. You may obtain a copy of the License at
+//
+//	https://opensource.org/licenses/MIT
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package mcp
+
+import (
+	"bufio"
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"os/exec"
+	"strconv"
+	"strings"
+	"sync"
+	"sync/atomic"
+
+	"github.com/ostafen/suricata/runtime"
+	"github.com/xeipuuv/gojsonschema"
+)
+
+var ErrClosed = errors.New("mcp: connection closed")
+
+// Client is a connection to an MCP server, whose tools can be exposed to agents
+// by passing ListTools, UnmarshalTool and CallTool to a runtime.Request:
+//
+//	specs, err := client.ListTools(ctx)
+//	...
+//	req.ToolSpecs = specs
+//	req.ToolUnmarshaller = client.UnmarshalTool
+//	req.ToolInvoker = client.CallTool
+type Client struct {
+	send  func(ctx context.Context, data []byte) error
+	close func() error
+
+	nextID atomic.Int64
+
+	mu      sync.Mutex
+	pending map[int64]chan *message
+	schemas map[string]gojsonschema.JSONLoader
+
+	done chan struct{}
+	err  error
+}
+
+func newClient(send func(ctx context.Context, data []byte) error, close func() error) *Client {
+	return &Client{
+		send:    send,
+		close:   close,
+		pending: make(map[int64]chan *message),
+		schemas: make(map[string]gojsonschema.JSONLoader),
+		done:    make(chan struct{}),
+	}
+}
+
+// NewClient performs the MCP handshake over a pair of streams carrying newline delimited JSON-RPC messages.
+// Closing the client closes w, if it implements io.Closer.
+func NewClient(ctx context.Context, r io.Reader, w io.Writer) (*Client, error) {
+	var wmu sync.Mutex
+
+	send := func(ctx context.Context, data []byte) error {
+		wmu.Lock()
+		defer wmu.Unlock()
+
+		_, err := w.Write(append(data, '\n'))
+		return err
+	}
+
+	closeFn := func() error {
+		if c, ok := w.(io.Closer); ok {
+			return c.Close()
+		}
+		return nil
+	}
+
+	c := newClient(send, closeFn)
+	go c.readLines(r)
+
+	if err := c.initialize(ctx); err != nil {
+		c.Close()
+		return nil, err
+	}
+	return c, nil
+}
+
+// NewStdioClient starts the given command and talks to it over its standard input and output.
+// The process is terminated when the client is closed.
+func NewStdioClient(ctx context.Context, name string, args ...string) (*Client, error) {
+	cmd := exec.Command(name, args...)
+
+	stdin, err := cmd.StdinPipe()
+	if err != nil {
+		return nil, err
+	}
+
+	stdout, err := cmd.StdoutPipe()
+	if err != nil {
+		return nil, err
+	}
+
+	if err := cmd.Start(); err != nil {
+		return nil, err
+	}
+
+	c, err := NewClient(ctx, stdout, &processCloser{WriteCloser: stdin, cmd: cmd})
+	if err != nil {
+		return nil, fmt.Errorf("mcp: %s: %w", name, err)
+	}
+	return c, nil
+}
+
+type processCloser struct {
+	io.WriteCloser
+	cmd *exec.Cmd
+}
+
+func (p *processCloser) Close() error {
+	p.WriteCloser.Close()
+	p.cmd.Process.Kill()
+	p.cmd.Wait()
+	return nil
+}
+
+// NewSSEClient connects to an MCP server using the SSE transport, listening for events at the given URL.
+func NewSSEClient(ctx context.Context, rawURL string) (*Client, error) {
+	base, err := url.Parse(rawURL)
+	if err != nil {
+		return nil, err
+	}
+
+	streamCtx, cancel := context.WithCancel(context.Background())
+
+	httpReq, err := http.NewRequestWithContext(streamCtx, http.MethodGet, rawURL, nil)
+	if err != nil {
+		cancel()
+		return nil, err
+	}
+	httpReq.Header.Set("Accept", "text/event-stream")
+
+	resp, err := http.DefaultClient.Do(httpReq)
+	if err != nil {
+		cancel()
+		return nil, err
+	}
+
+	if resp.StatusCode != http.StatusOK {
+		resp.Body.Close()
+		cancel()
+		return nil, fmt.Errorf("mcp: unexpected status %s", resp.Status)
+	}
+
+	endpoint := make(chan string, 1)
+
+	var postURL string
+	send := func(ctx context.Context, data []byte) error {
+		httpReq, err := http.NewRequestWithContext(ctx, http.MethodPost, postURL, bytes.NewReader(data))
+		if err != nil {
+			return err
+		}
+		httpReq.Header.Set("Content-Type", "application/json")
+
+		resp, err := http.DefaultClient.Do(httpReq)
+		if err != nil {
+			return err
+		}
+		defer resp.Body.Close()
+
+		if resp.StatusCode/100 != 2 {
+			return fmt.Errorf("mcp: unexpected status %s", resp.Status)
+		}
+		return nil
+	}
+
+	closeFn := func() error {
+		cancel()
+		return resp.Body.Close()
+	}
+
+	c := newClient(send, closeFn)
+	go c.readEvents(resp.Body, endpoint)
+
+	select {
+	case path := <-endpoint:
+		ref, err := url.Parse(path)
+		if err != nil {
+			c.Close()
+			return nil, err
+		}
+		postURL = base.ResolveReference(ref).String()
+	case <-c.done:
+		c.Close()
+		return nil, c.err
+	case <-ctx.Done():
+		c.Close()
+		return nil, ctx.Err()
+	}
+
+	if err := c.initialize(ctx); err != nil {
+		c.Close()
+		return nil, err
+	}
+	return c, nil
+}
+
+// Close terminates the connection to the server.
+func (c *Client) Close() error {
+	return c.close()
+}
+
+// ListTools returns the specs of the tools offered by the server.
+func (c *Client) ListTools(ctx context.Context) ([]runtime.ToolSpec, error) {
+	var res listToolsResult
+	if err := c.call(ctx, "tools/list", struct{}{}, &res); err != nil {
+		return nil, err
+	}
+
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	specs := make([]runtime.ToolSpec, 0, len(res.Tools))
+	for _, tool := range res.Tools {
+		schema := runtime.NewSchema(string(tool.InputSchema))
+
+		c.schemas[tool.Name] = schema
+		specs = append(specs, runtime.ToolSpec{
+			Name:        tool.Name,
+			Description: tool.Description,
+			Schema:      schema,
+		})
+	}
+	return specs, nil
+}
+
+// UnmarshalTool validates the arguments of a call to a tool returned by ListTools.
+// It implements runtime.ToolUnmarshaller.
+func (c *Client) UnmarshalTool(name string, data []byte) (any, error) {
+	c.mu.Lock()
+	schema, ok := c.schemas[name]
+	c.mu.Unlock()
+
+	if !ok {
+		return nil, fmt.Errorf("no such tool: %q", name)
+	}
+
+	var args json.RawMessage
+	if err := runtime.UnmarshalValidate(data, &args, schema); err != nil {
+		return nil, err
+	}
+	return args, nil
+}
+
+// CallTool calls a tool of the server. It implements runtime.ToolInvoker.
+// The text content of the result is returned as JSON when it is valid JSON, or as a string otherwise.
+func (c *Client) CallTool(ctx context.Context, name string, in any) (any, error) {
+	args, err := json.Marshal(in)
+	if err != nil {
+		return nil, err
+	}
+
+	var res callToolResult
+	if err := c.call(ctx, "tools/call", callToolParams{Name: name, Arguments: args}, &res); err != nil {
+		return nil, err
+	}
+
+	var texts []string
+	for _, content := range res.Content {
+		if content.Type == "text" {
+			texts = append(texts, content.Text)
+		}
+	}
+	text := strings.Join(texts, "\n")
+
+	if res.IsError {
+		return nil, fmt.Errorf("tool %q failed: %s", name, text)
+	}
+
+	if json.Valid([]byte(text)) {
+		return json.RawMessage(text), nil
+	}
+	return text, nil
+}
+
+func (c *Client) initialize(ctx context.Context) error {
+	params := initializeParams{
+		ProtocolVersion: ProtocolVersion,
+		Capabilities:    map[string]any{},
+		ClientInfo:      implementation{Name: "suricata"},
+	}
+
+	var res initializeResult
+	if err := c.call(ctx, "initialize", params, &res); err != nil {
+		return err
+	}
+
+	data, err := json.Marshal(request{JSONRPC: "2.0", Method: "notifications/initialized"})
+	if err != nil {
+		return err
+	}
+	return c.send(ctx, data)
+}
+
+// call sends a request to the server, and decodes the result of its response into result.
+func (c *Client) call(ctx context.Context, method string, params, result any) error {
+	rawParams, err := json.Marshal(params)
+	if err != nil {
+		return err
+	}
+
+	id := c.nextID.Add(1)
+	data, err := json.Marshal(request{
+		JSONRPC: "2.0",
+		ID:      json.RawMessage(strconv.FormatInt(id, 10)),
+		Method:  method,
+		Params:  rawParams,
+	})
+	if err != nil {
+		return err
+	}
+
+	ch := make(chan *message, 1)
+
+	c.mu.Lock()
+	c.pending[id] = ch
+	c.mu.Unlock()
+
+	defer func() {
+		c.mu.Lock()
+		delete(c.pending, id)
+		c.mu.Unlock()
+	}()
+
+	if err := c.send(ctx, data); err != nil {
+		return err
+	}
+
+	select {
+	case msg := <-ch:
+		if msg.Error != nil {
+			return fmt.Errorf("mcp: %s: %w", method, msg.Error)
+		}
+		return json.Unmarshal(msg.Result, result)
+	case <-c.done:
+		return c.err
+	case <-ctx.Done():
+		return ctx.Err()
+	}
+}
+
+func (c *Client) readLines(r io.Reader) {
+	scanner := bufio.NewScanner(r)
+	scanner.Buffer(make([]byte, 0, 64*1024), 16*1024*1024)
+	for scanner.Scan() {
+		if len(scanner.Bytes()) > 0 {
+			c.receive(scanner.Bytes())
+		}
+	}
+	c.shutdown(scanner.Err())
+}
+
+// readEvents reads an SSE stream, sending the path announced by the endpoint event to endpoint.
+func (c *Client) readEvents(r io.Reader, endpoint chan<- string) {
+	var (
+		event string
+		data  []string
+	)
+
+	scanner := bufio.NewScanner(r)
+	scanner.Buffer(make([]byte, 0, 64*1024), 16*1024*1024)
+	for scanner.Scan() {
+		line := scanner.Text()
+
+		switch {
+		case line == "":
+			payload := strings.Join(data, "\n")
+			switch event {
+			case "endpoint":
+				select {
+				case endpoint <- payload:
+				default:
+				}
+			case "", "message":
+				c.receive([]byte(payload))
+			}
+			event, data = "", nil
+		case strings.HasPrefix(line, "event:"):
+			event = strings.TrimSpace(strings.TrimPrefix(line, "event:"))
+		case strings.HasPrefix(line, "data:"):
+			data = append(data, strings.TrimPrefix(strings.TrimPrefix(line, "data:"), " "))
+		}
+	}
+	c.shutdown(scanner.Err())
+}
+
+// receive dispatches a message sent by the server.
+func (c *Client) receive(data []byte) {
+	var msg message
+	if err := json.Unmarshal(data, &msg); err != nil {
+		return
+	}
+
+	// Requests from the server: only pings are supported
+	if msg.Method != "" {
+		if len(msg.ID) == 0 {
+			return
+		}
+
+		resp := response{ID: msg.ID, Result: struct{}{}}
+		if msg.Method != "ping" {
+			resp = response{ID: msg.ID, Error: &rpcError{Code: codeMethodNotFound, Message: fmt.Sprintf("method not found: %q", msg.Method)}}
+		}
+		go c.send(context.Background(), encodeResponse(resp))
+		return
+	}
+
+	id, err := strconv.ParseInt(string(msg.ID), 10, 64)
+	if err != nil {
+		return
+	}
+
+	c.mu.Lock()
+	ch, ok := c.pending[id]
+	c.mu.Unlock()
+
+	if ok {
+		select {
+		case ch <- &msg:
+		default:
+		}
+	}
+}
+
+func (c *Client) shutdown(err error) {
+	if err == nil {
+		err = ErrClosed
+	}
+	c.err = err
+	close(c.done)
+}
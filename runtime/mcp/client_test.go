@@ -0,0 +1,92 @@
+// Copyright (c) 2025 Suricata Contributors
+// Original Author: Stefano Scafiti
+//
+// This file is part of Suricata: Type-Safe AI Agents for Go.
+//
+// Licensed under the MIT License. You may obtain a copy of the License at
+//
+//	https://opensource.org/licenses/MIT
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package mcp_test
+
+import (
+	"context"
+	"encoding/json"
+	"io"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/ostafen/suricata/runtime/mcp"
+)
+
+func testClient(t *testing.T, client *mcp.Client) {
+	t.Helper()
+
+	ctx := context.Background()
+
+	specs, err := client.ListTools(ctx)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if len(specs) != 1 || specs[0].Name != "echo" || specs[0].Description != "Echoes its input" {
+		t.Fatalf("unexpected tool specs: %+v", specs)
+	}
+
+	if _, err := client.UnmarshalTool("echo", []byte(`{"text": 1}`)); err == nil {
+		t.Errorf("expected arguments not matching the schema to be rejected")
+	}
+
+	in, err := client.UnmarshalTool("echo", []byte(`{"text": "hi"}`))
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	out, err := client.CallTool(ctx, "echo", in)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if raw, ok := out.(json.RawMessage); !ok || string(raw) != `{"text":"hi"}` {
+		t.Errorf("unexpected output: %v", out)
+	}
+
+	if _, err := client.CallTool(ctx, "echo", json.RawMessage(`{}`)); err == nil {
+		t.Errorf("expected tool error")
+	}
+}
+
+func TestClient(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	clientReader, serverWriter := io.Pipe()
+	serverReader, clientWriter := io.Pipe()
+
+	go newTestServer().Serve(ctx, serverReader, serverWriter)
+
+	client, err := mcp.NewClient(ctx, clientReader, clientWriter)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	defer client.Close()
+
+	testClient(t, client)
+}
+
+func TestSSEClient(t *testing.T) {
+	srv := httptest.NewServer(newTestServer().SSEHandler())
+	defer srv.Close()
+
+	client, err := mcp.NewSSEClient(context.Background(), srv.URL+"/sse")
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	defer client.Close()
+
+	testClient(t, client)
+}
@@ -0,0 +1,331 @@
+// Copyright (c) 2025 Suricata Contributors
+// Original Author: Stefano Scafiti
+//
+// This file is part of Suricata: Type-Safe AI Agents for Go.
+//
+// Licensed under the MIT License. You may obtain a copy of the License at
+//
+//	https://opensource.org/licenses/MIT
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package mcp
+
+import (
+	"bufio"
+	"context"
+	"crypto/rand"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"os"
+	"sync"
+
+	"github.com/xeipuuv/gojsonschema"
+)
+
+// Tool is a tool exposed by a Server.
+type Tool struct {
+	Name        string
+	Description string
+	InputSchema gojsonschema.JSONLoader
+
+	// Handler is called with the raw arguments of each call of the tool.
+	// Results which are not strings are returned to the host as JSON.
+	Handler func(ctx context.Context, args json.RawMessage) (any, error)
+}
+
+// Server serves tools to MCP hosts, over stdio or HTTP with server-sent events.
+type Server struct {
+	name    string
+	version string
+	tools   []Tool
+
+	mu       sync.Mutex
+	sessions map[string]chan []byte
+}
+
+func NewServer(name, version string) *Server {
+	return &Server{
+		name:     name,
+		version:  version,
+		sessions: make(map[string]chan []byte),
+	}
+}
+
+// AddTool registers a tool, replacing any previous tool with the same name.
+func (s *Server) AddTool(tool Tool) {
+	for i := range s.tools {
+		if s.tools[i].Name == tool.Name {
+			s.tools[i] = tool
+			return
+		}
+	}
+	s.tools = append(s.tools, tool)
+}
+
+// ServeStdio serves requests read from stdin, writing responses to stdout.
+func (s *Server) ServeStdio(ctx context.Context) error {
+	return s.Serve(ctx, os.Stdin, os.Stdout)
+}
+
+// Serve serves newline delimited JSON-RPC messages read from r, until r is exhausted or ctx is done.
+func (s *Server) Serve(ctx context.Context, r io.Reader, w io.Writer) error {
+	lines := make(chan []byte)
+	errc := make(chan error, 1)
+
+	go func() {
+		defer close(lines)
+
+		scanner := bufio.NewScanner(r)
+		scanner.Buffer(make([]byte, 0, 64*1024), 16*1024*1024)
+		for scanner.Scan() {
+			line := append([]byte(nil), scanner.Bytes()...)
+			select {
+			case lines <- line:
+			case <-ctx.Done():
+				return
+			}
+		}
+		errc <- scanner.Err()
+	}()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return ctx.Err()
+		case line, ok := <-lines:
+			if !ok {
+				return <-errc
+			}
+			if len(line) == 0 {
+				continue
+			}
+
+			if resp := s.handle(ctx, line); resp != nil {
+				if _, err := w.Write(append(resp, '\n')); err != nil {
+					return err
+				}
+			}
+		}
+	}
+}
+
+// SSEHandler returns an HTTP handler implementing the MCP SSE transport.
+// GET requests open an event stream, whose first event announces the endpoint
+// to which the client must POST its messages. Responses are delivered on the stream.
+func (s *Server) SSEHandler() http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch r.Method {
+		case http.MethodGet:
+			s.serveEvents(w, r)
+		case http.MethodPost:
+			s.serveMessage(w, r)
+		default:
+			w.Header().Set("Allow", "GET, POST")
+			http.Error(w, "method not allowed", http.StatusMethodNotAllowed)
+		}
+	})
+}
+
+func (s *Server) serveEvents(w http.ResponseWriter, r *http.Request) {
+	flusher, ok := w.(http.Flusher)
+	if !ok {
+		http.Error(w, "streaming not supported", http.StatusInternalServerError)
+		return
+	}
+
+	id, err := newSessionID()
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+
+	messages := make(chan []byte, 16)
+
+	s.mu.Lock()
+	s.sessions[id] = messages
+	s.mu.Unlock()
+
+	defer func() {
+		s.mu.Lock()
+		delete(s.sessions, id)
+		s.mu.Unlock()
+	}()
+
+	w.Header().Set("Content-Type", "text/event-stream")
+	w.Header().Set("Cache-Control", "no-cache")
+	w.Header().Set("Connection", "keep-alive")
+
+	fmt.Fprintf(w, "event: endpoint\ndata: %s?sessionId=%s\n\n", r.URL.Path, id)
+	flusher.Flush()
+
+	for {
+		select {
+		case <-r.Context().Done():
+			return
+		case msg := <-messages:
+			fmt.Fprintf(w, "event: message\ndata: %s\n\n", msg)
+			flusher.Flush()
+		}
+	}
+}
+
+func (s *Server) serveMessage(w http.ResponseWriter, r *http.Request) {
+	s.mu.Lock()
+	messages, ok := s.sessions[r.URL.Query().Get("sessionId")]
+	s.mu.Unlock()
+
+	if !ok {
+		http.Error(w, "unknown session", http.StatusNotFound)
+		return
+	}
+
+	data, err := io.ReadAll(r.Body)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+	w.WriteHeader(http.StatusAccepted)
+
+	if resp := s.handle(r.Context(), data); resp != nil {
+		select {
+		case messages <- resp:
+		case <-r.Context().Done():
+		}
+	}
+}
+
+func newSessionID() (string, error) {
+	var b [16]byte
+	if _, err := rand.Read(b[:]); err != nil {
+		return "", err
+	}
+	return hex.EncodeToString(b[:]), nil
+}
+
+// handle processes a single JSON-RPC message, returning the encoded response, if any.
+func (s *Server) handle(ctx context.Context, data []byte) []byte {
+	var req request
+	if err := json.Unmarshal(data, &req); err != nil {
+		return encodeResponse(response{
+			ID:    json.RawMessage("null"),
+			Error: &rpcError{Code: codeParseError, Message: err.Error()},
+		})
+	}
+
+	result, err := s.dispatch(ctx, &req)
+	if req.isNotification() {
+		return nil
+	}
+
+	resp := response{ID: req.ID, Result: result}
+	if err != nil {
+		rpcErr, ok := err.(*rpcError)
+		if !ok {
+			rpcErr = &rpcError{Code: codeInvalidRequest, Message: err.Error()}
+		}
+		resp.Result, resp.Error = nil, rpcErr
+	}
+	return encodeResponse(resp)
+}
+
+func (s *Server) dispatch(ctx context.Context, req *request) (any, error) {
+	switch req.Method {
+	case "initialize":
+		return initializeResult{
+			ProtocolVersion: ProtocolVersion,
+			Capabilities:    map[string]any{"tools": map[string]any{}},
+			ServerInfo:      implementation{Name: s.name, Version: s.version},
+		}, nil
+	case "ping":
+		return struct{}{}, nil
+	case "tools/list":
+		return s.listTools()
+	case "tools/call":
+		var params callToolParams
+		if err := json.Unmarshal(req.Params, &params); err != nil {
+			return nil, &rpcError{Code: codeInvalidParams, Message: err.Error()}
+		}
+		return s.callTool(ctx, &params)
+	}
+
+	if req.isNotification() {
+		return nil, nil
+	}
+	return nil, &rpcError{Code: codeMethodNotFound, Message: fmt.Sprintf("method not found: %q", req.Method)}
+}
+
+func (s *Server) listTools() (*listToolsResult, error) {
+	res := &listToolsResult{Tools: make([]toolInfo, 0, len(s.tools))}
+	for _, tool := range s.tools {
+		schema, err := rawSchema(tool.InputSchema)
+		if err != nil {
+			return nil, fmt.Errorf("tool %q: %w", tool.Name, err)
+		}
+		res.Tools = append(res.Tools, toolInfo{Name: tool.Name, Description: tool.Description, InputSchema: schema})
+	}
+	return res, nil
+}
+
+func (s *Server) callTool(ctx context.Context, params *callToolParams) (*callToolResult, error) {
+	idx := -1
+	for i := range s.tools {
+		if s.tools[i].Name == params.Name {
+			idx = i
+		}
+	}
+	if idx < 0 {
+		return nil, &rpcError{Code: codeInvalidParams, Message: fmt.Sprintf("no such tool: %q", params.Name)}
+	}
+
+	args := params.Arguments
+	if len(args) == 0 {
+		args = json.RawMessage("{}")
+	}
+
+	// Tool failures are reported to the host as results, so that the model can see them
+	out, err := s.tools[idx].Handler(ctx, args)
+	if err != nil {
+		return &callToolResult{Content: []content{{Type: "text", Text: err.Error()}}, IsError: true}, nil
+	}
+
+	text, ok := out.(string)
+	if !ok {
+		data, err := json.Marshal(out)
+		if err != nil {
+			return nil, err
+		}
+		text = string(data)
+	}
+	return &callToolResult{Content: []content{{Type: "text", Text: text}}}, nil
+}
+
+// rawSchema returns the JSON encoding of the schema held by loader.
+func rawSchema(loader gojsonschema.JSONLoader) (json.RawMessage, error) {
+	if loader == nil {
+		return json.RawMessage(`{"type":"object"}`), nil
+	}
+
+	if src, ok := loader.JsonSource().(string); ok {
+		return json.RawMessage(src), nil
+	}
+
+	doc, err := loader.LoadJSON()
+	if err != nil {
+		return nil, err
+	}
+	return json.Marshal(doc)
+}
+
+func encodeResponse(resp response) []byte {
+	resp.JSONRPC = "2.0"
+	data, _ := json.Marshal(resp)
+	return data
+}
@@ -0,0 +1,108 @@
+// Copyright (c) 2025 Suricata Contributors
+// Original Author: Stefano Scafiti
+//
+// This file is part of Suricata: Type-Safe AI Agents for Go.
+//
+// Licensed under the MIT License. You may obtain a copy of the License at
+//
+//	https://opensource.org/licenses/MIT
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package mcp_test
+
+import (
+	"bufio"
+	"context"
+	"encoding/json"
+	"errors"
+	"strings"
+	"testing"
+
+	"github.com/ostafen/suricata/runtime/mcp"
+	"github.com/xeipuuv/gojsonschema"
+)
+
+func newTestServer() *mcp.Server {
+	srv := mcp.NewServer("test", "1.0.0")
+	srv.AddTool(mcp.Tool{
+		Name:        "echo",
+		Description: "Echoes its input",
+		InputSchema: gojsonschema.NewStringLoader(`{"type":"object","properties":{"text":{"type":"string"}}}`),
+		Handler: func(ctx context.Context, args json.RawMessage) (any, error) {
+			var in struct {
+				Text string `json:"text"`
+			}
+			if err := json.Unmarshal(args, &in); err != nil {
+				return nil, err
+			}
+			if in.Text == "" {
+				return nil, errors.New("empty text")
+			}
+			return map[string]string{"text": in.Text}, nil
+		},
+	})
+	return srv
+}
+
+func TestServeStdio(t *testing.T) {
+	input := strings.Join([]string{
+		`{"jsonrpc":"2.0","id":1,"method":"initialize","params":{}}`,
+		`{"jsonrpc":"2.0","method":"notifications/initialized"}`,
+		`{"jsonrpc":"2.0","id":2,"method":"tools/list"}`,
+		`{"jsonrpc":"2.0","id":3,"method":"tools/call","params":{"name":"echo","arguments":{"text":"hi"}}}`,
+		`{"jsonrpc":"2.0","id":4,"method":"tools/call","params":{"name":"echo","arguments":{}}}`,
+		`{"jsonrpc":"2.0","id":5,"method":"tools/call","params":{"name":"missing"}}`,
+		`{"jsonrpc":"2.0","id":6,"method":"unknown"}`,
+	}, "\n")
+
+	var out strings.Builder
+	if err := newTestServer().Serve(context.Background(), strings.NewReader(input), &out); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	var responses []map[string]any
+	scanner := bufio.NewScanner(strings.NewReader(out.String()))
+	for scanner.Scan() {
+		var resp map[string]any
+		if err := json.Unmarshal(scanner.Bytes(), &resp); err != nil {
+			t.Fatalf("invalid response %q: %v", scanner.Text(), err)
+		}
+		responses = append(responses, resp)
+	}
+
+	// The notification must not be answered
+	if len(responses) != 6 {
+		t.Fatalf("expected 6 responses, got %d", len(responses))
+	}
+
+	info := responses[0]["result"].(map[string]any)["serverInfo"].(map[string]any)
+	if info["name"] != "test" {
+		t.Errorf("unexpected server info: %v", info)
+	}
+
+	tools := responses[1]["result"].(map[string]any)["tools"].([]any)
+	if len(tools) != 1 || tools[0].(map[string]any)["inputSchema"].(map[string]any)["type"] != "object" {
+		t.Errorf("unexpected tools: %v", tools)
+	}
+
+	res := responses[2]["result"].(map[string]any)
+	if text := res["content"].([]any)[0].(map[string]any)["text"]; text != `{"text":"hi"}` {
+		t.Errorf("unexpected tool output: %v", text)
+	}
+
+	res = responses[3]["result"].(map[string]any)
+	if res["isError"] != true {
+		t.Errorf("expected tool error to be reported as result, got %v", res)
+	}
+
+	for _, resp := range responses[4:] {
+		if resp["error"] == nil {
+			t.Errorf("expected error, got %v", resp)
+		}
+	}
+}
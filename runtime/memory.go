@@ -0,0 +1,81 @@
+// Copyright (c) 2025 Suricata Contributors
+// Original Author: Stefano Scafiti
+//
+// This file is part of Suricata: Type-Safe AI Agents for Go.
+//
+// Licensed under the MIT License. You may obtain a copy of the License at
+//
+//	https://opensource.org/licenses/MIT
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package runtime
+
+import (
+	"context"
+	"sync"
+)
+
+// Memory stores the message history of a single conversation.
+// Implementations backed by a database allow conversations to be resumed across process restarts.
+type Memory interface {
+	// Append adds messages at the end of the history.
+	Append(ctx context.Context, msgs ...Message) error
+	// History returns all messages in insertion order.
+	History(ctx context.Context) ([]Message, error)
+	// Truncate discards all but the last n messages.
+	Truncate(ctx context.Context, n int) error
+}
+
+// WithMemory sets the memory of requests which do not set their own to the one returned by
+// memory, which can select the conversation of the caller from ctx, e.g. by a session ID.
+// A nil Memory starts a fresh conversation.
+func WithMemory(memory func(ctx context.Context) Memory) Option {
+	return func(r *Runtime) {
+		r.memory = memory
+	}
+}
+
+// InMemory is a Memory which keeps messages in a slice.
+type InMemory struct {
+	mtx      sync.RWMutex
+	messages []Message
+}
+
+func NewInMemory() *InMemory {
+	return &InMemory{}
+}
+
+func (m *InMemory) Append(_ context.Context, msgs ...Message) error {
+	m.mtx.Lock()
+	defer m.mtx.Unlock()
+
+	m.messages = append(m.messages, msgs...)
+	return nil
+}
+
+func (m *InMemory) History(_ context.Context) ([]Message, error) {
+	m.mtx.RLock()
+	defer m.mtx.RUnlock()
+
+	out := make([]Message, len(m.messages))
+	copy(out, m.messages)
+	return out, nil
+}
+
+func (m *InMemory) Truncate(_ context.Context, n int) error {
+	m.mtx.Lock()
+	defer m.mtx.Unlock()
+
+	if n < 0 {
+		n = 0
+	}
+	if n < len(m.messages) {
+		m.messages = append([]Message(nil), m.messages[len(m.messages)-n:]...)
+	}
+	return nil
+}
@@ -0,0 +1,93 @@
+// Copyright (c) 2025 Suricata Contributors
+// Original Author: Stefano Scafiti
+//
+// This file is part of Suricata: Type-Safe AI Agents for Go.
+//
+// Licensed under the MIT License. You may obtain a copy of the License at
+//
+//	https://opensource.org/licenses/MIT
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+// Package redis provides a runtime.Memory persisted in a Redis list.
+package redis
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"time"
+
+	goredis "github.com/redis/go-redis/v9"
+
+	"github.com/ostafen/suricata/runtime"
+)
+
+const DefaultKeyPrefix = "suricata:conversation:"
+
+// Memory stores the messages of a single conversation in a Redis list.
+type Memory struct {
+	client goredis.UniversalClient
+	key    string
+	ttl    time.Duration
+}
+
+// NewMemory returns a memory for the given conversation.
+// A positive ttl makes the conversation expire after the given period of inactivity.
+func NewMemory(client goredis.UniversalClient, conversationID string, ttl time.Duration) *Memory {
+	return &Memory{
+		client: client,
+		key:    DefaultKeyPrefix + conversationID,
+		ttl:    ttl,
+	}
+}
+
+func (m *Memory) Append(ctx context.Context, msgs ...runtime.Message) error {
+	if len(msgs) == 0 {
+		return nil
+	}
+
+	values := make([]any, len(msgs))
+	for i, msg := range msgs {
+		data, err := json.Marshal(msg)
+		if err != nil {
+			return fmt.Errorf("marshal message: %w", err)
+		}
+		values[i] = data
+	}
+
+	pipe := m.client.TxPipeline()
+	pipe.RPush(ctx, m.key, values...)
+	if m.ttl > 0 {
+		pipe.Expire(ctx, m.key, m.ttl)
+	}
+
+	_, err := pipe.Exec(ctx)
+	return err
+}
+
+func (m *Memory) History(ctx context.Context) ([]runtime.Message, error) {
+	values, err := m.client.LRange(ctx, m.key, 0, -1).Result()
+	if err != nil {
+		return nil, err
+	}
+
+	messages := make([]runtime.Message, len(values))
+	for i, v := range values {
+		if err := json.Unmarshal([]byte(v), &messages[i]); err != nil {
+			return nil, fmt.Errorf("unmarshal message: %w", err)
+		}
+	}
+	return messages, nil
+}
+
+func (m *Memory) Truncate(ctx context.Context, n int) error {
+	if n <= 0 {
+		return m.client.Del(ctx, m.key).Err()
+	}
+	return m.client.LTrim(ctx, m.key, int64(-n), -1).Err()
+}
@@ -0,0 +1,80 @@
+// Copyright (c) 2025 Suricata Contributors
+// Original Author: Stefano Scafiti
+//
+// This file is part of Suricata: Type-Safe AI Agents for Go.
+//
+// Licensed under the MIT License. You may obtain a copy of the License at
+//
+//	https://opensource.org/licenses/MIT
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package redis_test
+
+import (
+	"context"
+	"fmt"
+	"testing"
+	"time"
+
+	"github.com/alicebob/miniredis/v2"
+	goredis "github.com/redis/go-redis/v9"
+
+	"github.com/ostafen/suricata/runtime"
+	"github.com/ostafen/suricata/runtime/memory/redis"
+	"github.com/ostafen/suricata/runtime/runtimetest"
+)
+
+func newClient(t *testing.T) (*goredis.Client, *miniredis.Miniredis) {
+	srv := miniredis.RunT(t)
+
+	client := goredis.NewClient(&goredis.Options{Addr: srv.Addr()})
+	t.Cleanup(func() { client.Close() })
+	return client, srv
+}
+
+func conversationID(t *testing.T) string {
+	return fmt.Sprintf("%s-%d", t.Name(), time.Now().UnixNano())
+}
+
+func TestMemory(t *testing.T) {
+	client, _ := newClient(t)
+
+	runtimetest.TestMemory(t, func(t *testing.T) runtime.Memory {
+		return redis.NewMemory(client, conversationID(t), time.Minute)
+	})
+}
+
+func TestMemory_TTL(t *testing.T) {
+	client, srv := newClient(t)
+	ctx := context.Background()
+
+	id := conversationID(t)
+	m := redis.NewMemory(client, id, time.Minute)
+
+	if err := m.Append(ctx, runtime.Message{Role: runtime.RoleUser, Content: "hello"}); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	ttl, err := client.TTL(ctx, redis.DefaultKeyPrefix+id).Result()
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if ttl <= 0 || ttl > time.Minute {
+		t.Fatalf("expected a ttl of at most a minute, got %v", ttl)
+	}
+
+	srv.FastForward(time.Minute)
+
+	history, err := m.History(ctx)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if len(history) != 0 {
+		t.Fatalf("expected the conversation to expire, got %v", history)
+	}
+}
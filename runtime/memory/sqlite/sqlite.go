@@ -0,0 +1,124 @@
+// Copyright (c) 2025 Suricata Contributors
+// Original Author: Stefano Scafiti
+//
+// This file is part of Suricata: Type-Safe AI Agents for Go.
+//
+// Licensed under the MIT License. You may obtain a copy of the License at
+//
+//	https://opensource.org/licenses/MIT
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+// Package sqlite provides a runtime.Memory persisted in a SQL database.
+//
+// The package only depends on database/sql: callers open the database with the
+// SQLite driver of their choice (e.g. modernc.org/sqlite or mattn/go-sqlite3).
+//
+// Only the role and content of messages are stored: the tool calls answered by
+// tool messages are dropped, so invokers receive them as plain text, and so are
+// the attachments of user messages.
+package sqlite
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+
+	"github.com/ostafen/suricata/runtime"
+)
+
+const DefaultTable = "suricata_messages"
+
+// Memory stores the messages of a single conversation in a SQL table.
+type Memory struct {
+	db             *sql.DB
+	table          string
+	conversationID string
+}
+
+// NewMemory returns a memory for the given conversation, creating the backing table if needed.
+func NewMemory(ctx context.Context, db *sql.DB, conversationID string) (*Memory, error) {
+	return NewMemoryWithTable(ctx, db, DefaultTable, conversationID)
+}
+
+// NewMemoryWithTable is like NewMemory but stores messages in a custom table.
+func NewMemoryWithTable(ctx context.Context, db *sql.DB, table, conversationID string) (*Memory, error) {
+	_, err := db.ExecContext(ctx, fmt.Sprintf(`CREATE TABLE IF NOT EXISTS %s (
+		id INTEGER PRIMARY KEY AUTOINCREMENT,
+		conversation_id TEXT NOT NULL,
+		role INTEGER NOT NULL,
+		content TEXT NOT NULL
+	)`, table))
+	if err != nil {
+		return nil, fmt.Errorf("create table: %w", err)
+	}
+
+	_, err = db.ExecContext(ctx, fmt.Sprintf(`CREATE INDEX IF NOT EXISTS %s_conversation_idx ON %s (conversation_id, id)`, table, table))
+	if err != nil {
+		return nil, fmt.Errorf("create index: %w", err)
+	}
+
+	return &Memory{
+		db:             db,
+		table:          table,
+		conversationID: conversationID,
+	}, nil
+}
+
+func (m *Memory) Append(ctx context.Context, msgs ...runtime.Message) error {
+	tx, err := m.db.BeginTx(ctx, nil)
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback()
+
+	query := fmt.Sprintf(`INSERT INTO %s (conversation_id, role, content) VALUES (?, ?, ?)`, m.table)
+	for _, msg := range msgs {
+		if _, err := tx.ExecContext(ctx, query, m.conversationID, msg.Role, msg.Content); err != nil {
+			return fmt.Errorf("insert message: %w", err)
+		}
+	}
+	return tx.Commit()
+}
+
+func (m *Memory) History(ctx context.Context) ([]runtime.Message, error) {
+	rows, err := m.db.QueryContext(ctx, fmt.Sprintf(`SELECT role, content FROM %s WHERE conversation_id = ? ORDER BY id`, m.table), m.conversationID)
+	if err != nil {
+		return nil, fmt.Errorf("query messages: %w", err)
+	}
+	defer rows.Close()
+
+	var messages []runtime.Message
+	for rows.Next() {
+		var msg runtime.Message
+		if err := rows.Scan(&msg.Role, &msg.Content); err != nil {
+			return nil, fmt.Errorf("scan message: %w", err)
+		}
+		messages = append(messages, msg)
+	}
+	return messages, rows.Err()
+}
+
+func (m *Memory) Truncate(ctx context.Context, n int) error {
+	if n < 0 {
+		n = 0
+	}
+
+	_, err := m.db.ExecContext(ctx, fmt.Sprintf(`DELETE FROM %s WHERE conversation_id = ? AND id NOT IN (
+		SELECT id FROM %s WHERE conversation_id = ? ORDER BY id DESC LIMIT ?
+	)`, m.table, m.table), m.conversationID, m.conversationID, n)
+	if err != nil {
+		return fmt.Errorf("truncate messages: %w", err)
+	}
+	return nil
+}
+
+// Clear removes the whole conversation.
+func (m *Memory) Clear(ctx context.Context) error {
+	_, err := m.db.ExecContext(ctx, fmt.Sprintf(`DELETE FROM %s WHERE conversation_id = ?`, m.table), m.conversationID)
+	return err
+}
@@ -0,0 +1,89 @@
+// Copyright (c) 2025 Suricata Contributors
+// Original Author: Stefano Scafiti
+//
+// This file is part of Suricata: Type-Safe AI Agents for Go.
+//
+// Licensed under the MIT License. You may obtain a copy of the License at
+//
+//	https://opensource.org/licenses/MIT
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package sqlite_test
+
+import (
+	"context"
+	"database/sql"
+	"testing"
+
+	_ "modernc.org/sqlite"
+
+	"github.com/ostafen/suricata/runtime"
+	"github.com/ostafen/suricata/runtime/memory/sqlite"
+	"github.com/ostafen/suricata/runtime/runtimetest"
+)
+
+func openDB(t *testing.T) *sql.DB {
+	db, err := sql.Open("sqlite", ":memory:")
+	if err != nil {
+		t.Fatalf("open: %v", err)
+	}
+	// Each connection to :memory: opens a distinct database.
+	db.SetMaxOpenConns(1)
+	t.Cleanup(func() { db.Close() })
+	return db
+}
+
+func TestMemory(t *testing.T) {
+	runtimetest.TestMemory(t, func(t *testing.T) runtime.Memory {
+		m, err := sqlite.NewMemory(context.Background(), openDB(t), "conv")
+		if err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+		return m
+	})
+}
+
+func TestMemory_Conversations(t *testing.T) {
+	ctx := context.Background()
+	db := openDB(t)
+
+	a, err := sqlite.NewMemory(ctx, db, "a")
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	b, err := sqlite.NewMemoryWithTable(ctx, db, sqlite.DefaultTable, "b")
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	if err := a.Append(ctx, runtime.Message{Role: runtime.RoleUser, Content: "to a"}); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if err := b.Append(ctx, runtime.Message{Role: runtime.RoleUser, Content: "to b"}); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if err := a.Clear(ctx); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	history, err := b.History(ctx)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if len(history) != 1 || history[0].Content != "to b" {
+		t.Fatalf("unexpected history of b: %v", history)
+	}
+
+	history, err = a.History(ctx)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if len(history) != 0 {
+		t.Fatalf("expected a cleared history, got %v", history)
+	}
+}
@@ -0,0 +1,49 @@
+// Copyright (c) 2025 Suricata Contributors
+// Original Author: Stefano Scafiti
+//
+// This file is part of Suricata: Type-Safe AI Agents for Go.
+//
+// Licensed under the MIT License. You may obtain a copy of the License at
+//
+//	https://opensource.org/licenses/MIT
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package runtime_test
+
+import (
+	"context"
+	"testing"
+
+	"github.com/ostafen/suricata/runtime"
+	"github.com/ostafen/suricata/runtime/runtimetest"
+)
+
+func TestInMemory(t *testing.T) {
+	runtimetest.TestMemory(t, func(t *testing.T) runtime.Memory {
+		return runtime.NewInMemory()
+	})
+}
+
+func TestChatSession_Append(t *testing.T) {
+	mem := runtime.NewInMemory()
+	sess := runtime.NewChatSessionWithMemory(runtimetest.NewInvoker(t), "", mem)
+
+	sess.Add(runtime.Message{Role: runtime.RoleUser, Content: "hello"})
+	err := sess.Append(context.Background(), runtime.Message{Role: runtime.RoleAgent, Content: "hi"})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	history, err := mem.History(context.Background())
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if len(history) != 2 || history[0].Content != "hello" || history[1].Content != "hi" {
+		t.Fatalf("unexpected history: %v", history)
+	}
+}
@@ -0,0 +1,50 @@
+// Copyright (c) 2025 Suricata Contributors
+// Original Author: Stefano Scafiti
+//
+// This file is part of Suricata: Type-Safe AI Agents for Go.
+//
+// Licensed under the MIT License. You may obtain a copy of the License at
+//
+//	https://opensource.org/licenses/MIT
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package metrics
+
+import (
+	"github.com/prometheus/client_golang/prometheus"
+
+	"github.com/ostafen/suricata/runtime/cache"
+)
+
+// RegisterCache exports the hits and misses of a response cache on reg.
+// The name label distinguishes caches registered on the same registry.
+func RegisterCache(reg prometheus.Registerer, name string, c *cache.Invoker) error {
+	labels := prometheus.Labels{"cache": name}
+
+	collectors := []prometheus.Collector{
+		prometheus.NewCounterFunc(prometheus.CounterOpts{
+			Namespace:   Namespace,
+			Name:        "cache_hits_total",
+			Help:        "Number of LLM calls served from the response cache.",
+			ConstLabels: labels,
+		}, func() float64 { return float64(c.Stats().Hits) }),
+		prometheus.NewCounterFunc(prometheus.CounterOpts{
+			Namespace:   Namespace,
+			Name:        "cache_misses_total",
+			Help:        "Number of LLM calls forwarded to the provider by the response cache.",
+			ConstLabels: labels,
+		}, func() float64 { return float64(c.Stats().Misses) }),
+	}
+
+	for _, col := range collectors {
+		if err := reg.Register(col); err != nil {
+			return err
+		}
+	}
+	return nil
+}
@@ -0,0 +1,185 @@
+// Copyright (c) 2025 Suricata Contributors
+// Original Author: Stefano Scafiti
+//
+// This file is part of Suricata: Type-Safe AI Agents for Go.
+//
+// Licensed under the MIT License. You may obtain a copy of the License at
+//
+//	https://opensource.org/licenses/MIT
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+// Package metrics exports Prometheus metrics about agent executions.
+//
+//	collector, err := metrics.NewCollector(prometheus.DefaultRegisterer)
+//	rt := runtime.NewRuntime(invoker, runtime.WithTracer(collector))
+package metrics
+
+import (
+	"context"
+	"sync"
+	"time"
+
+	"github.com/prometheus/client_golang/prometheus"
+
+	"github.com/ostafen/suricata/runtime"
+)
+
+const Namespace = "suricata"
+
+const (
+	statusOK    = "ok"
+	statusError = "error"
+)
+
+// Collector records metrics about invocations, LLM calls and tool calls.
+// It implements runtime.Tracer, so it is enabled through runtime.WithTracer.
+type Collector struct {
+	invocations        *prometheus.CounterVec
+	invocationDuration *prometheus.HistogramVec
+	llmCalls           *prometheus.CounterVec
+	llmCallDuration    *prometheus.HistogramVec
+	toolCalls          *prometheus.CounterVec
+	toolCallDuration   *prometheus.HistogramVec
+	tokens             *prometheus.CounterVec
+}
+
+// NewCollector creates the metrics and registers them on reg.
+func NewCollector(reg prometheus.Registerer) (*Collector, error) {
+	c := &Collector{
+		invocations: prometheus.NewCounterVec(prometheus.CounterOpts{
+			Namespace: Namespace,
+			Name:      "invocations_total",
+			Help:      "Number of agent action invocations, by prompt version and status.",
+		}, []string{"prompt_version", "status"}),
+		invocationDuration: prometheus.NewHistogramVec(prometheus.HistogramOpts{
+			Namespace: Namespace,
+			Name:      "invocation_duration_seconds",
+			Help:      "Duration of agent action invocations, by prompt version.",
+			Buckets:   prometheus.ExponentialBuckets(0.1, 2, 12),
+		}, []string{"prompt_version"}),
+		llmCalls: prometheus.NewCounterVec(prometheus.CounterOpts{
+			Namespace: Namespace,
+			Name:      "llm_calls_total",
+			Help:      "Number of LLM calls, by model and status.",
+		}, []string{"model", "status"}),
+		llmCallDuration: prometheus.NewHistogramVec(prometheus.HistogramOpts{
+			Namespace: Namespace,
+			Name:      "llm_call_duration_seconds",
+			Help:      "Duration of LLM calls, by model.",
+			Buckets:   prometheus.ExponentialBuckets(0.1, 2, 12),
+		}, []string{"model"}),
+		toolCalls: prometheus.NewCounterVec(prometheus.CounterOpts{
+			Namespace: Namespace,
+			Name:      "tool_calls_total",
+			Help:      "Number of tool calls, by tool and status.",
+		}, []string{"tool", "status"}),
+		toolCallDuration: prometheus.NewHistogramVec(prometheus.HistogramOpts{
+			Namespace: Namespace,
+			Name:      "tool_call_duration_seconds",
+			Help:      "Duration of tool calls, by tool.",
+			Buckets:   prometheus.DefBuckets,
+		}, []string{"tool"}),
+		tokens: prometheus.NewCounterVec(prometheus.CounterOpts{
+			Namespace: Namespace,
+			Name:      "tokens_total",
+			Help:      "Estimated number of tokens exchanged with LLMs, by model and type (prompt or completion).",
+		}, []string{"model", "type"}),
+	}
+
+	collectors := []prometheus.Collector{
+		c.invocations,
+		c.invocationDuration,
+		c.llmCalls,
+		c.llmCallDuration,
+		c.toolCalls,
+		c.toolCallDuration,
+		c.tokens,
+	}
+
+	for _, col := range collectors {
+		if err := reg.Register(col); err != nil {
+			return nil, err
+		}
+	}
+	return c, nil
+}
+
+func (c *Collector) Start(ctx context.Context, name string, attrs ...runtime.Attr) (context.Context, runtime.Span) {
+	s := &span{
+		collector: c,
+		name:      name,
+		start:     time.Now(),
+		attrs:     make(map[string]any),
+	}
+	s.SetAttributes(attrs...)
+	return ctx, s
+}
+
+type span struct {
+	mtx       sync.Mutex
+	collector *Collector
+	name      string
+	start     time.Time
+	attrs     map[string]any
+}
+
+func (s *span) SetAttributes(attrs ...runtime.Attr) {
+	s.mtx.Lock()
+	defer s.mtx.Unlock()
+
+	for _, a := range attrs {
+		s.attrs[a.Key] = a.Value
+	}
+}
+
+func (s *span) End(err error) {
+	s.mtx.Lock()
+	defer s.mtx.Unlock()
+
+	elapsed := time.Since(s.start).Seconds()
+
+	status := statusOK
+	if err != nil {
+		status = statusError
+	}
+
+	c := s.collector
+	switch s.name {
+	case runtime.SpanInvoke:
+		version := s.stringAttr(runtime.AttrPromptVersion)
+		c.invocations.WithLabelValues(version, status).Inc()
+		c.invocationDuration.WithLabelValues(version).Observe(elapsed)
+	case runtime.SpanLLMCall:
+		model := s.stringAttr(runtime.AttrModel)
+		c.llmCalls.WithLabelValues(model, status).Inc()
+		c.llmCallDuration.WithLabelValues(model).Observe(elapsed)
+		c.tokens.WithLabelValues(model, "prompt").Add(s.floatAttr(runtime.AttrPromptTokens))
+		c.tokens.WithLabelValues(model, "completion").Add(s.floatAttr(runtime.AttrCompletionTokens))
+	case runtime.SpanToolCall:
+		tool := s.stringAttr(runtime.AttrToolName)
+		c.toolCalls.WithLabelValues(tool, status).Inc()
+		c.toolCallDuration.WithLabelValues(tool).Observe(elapsed)
+	}
+}
+
+func (s *span) stringAttr(key string) string {
+	v, _ := s.attrs[key].(string)
+	return v
+}
+
+func (s *span) floatAttr(key string) float64 {
+	switch v := s.attrs[key].(type) {
+	case int:
+		return float64(v)
+	case int64:
+		return float64(v)
+	case float64:
+		return v
+	}
+	return 0
+}
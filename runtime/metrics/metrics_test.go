@@ -0,0 +1,144 @@
+// Copyright (c) 2025 Suricata Contributors
+// Original Author: Stefano Scafiti
+//
+// This file is part of Suricata: Type-Safe AI Agents for Go.
+//
+// Licensed under the MIT License. You may obtain a copy of the License at
+//
+//	https://opensource.org/licenses/MIT
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package metrics_test
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"testing"
+
+	"github.com/prometheus/client_golang/prometheus"
+	dto "github.com/prometheus/client_model/go"
+
+	"github.com/ostafen/suricata/runtime"
+	"github.com/ostafen/suricata/runtime/metrics"
+	"github.com/ostafen/suricata/runtime/runtimetest"
+)
+
+// find returns the metric of the family with the given name matching all the labels, or nil.
+func find(t *testing.T, reg *prometheus.Registry, name string, labels map[string]string) *dto.Metric {
+	t.Helper()
+
+	families, err := reg.Gather()
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	for _, family := range families {
+		if family.GetName() != name {
+			continue
+		}
+	metrics:
+		for _, m := range family.GetMetric() {
+			for _, label := range m.GetLabel() {
+				if value, ok := labels[label.GetName()]; ok && value != label.GetValue() {
+					continue metrics
+				}
+			}
+			return m
+		}
+	}
+	return nil
+}
+
+func TestCollector(t *testing.T) {
+	reg := prometheus.NewRegistry()
+	collector, err := metrics.NewCollector(reg)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	mock := runtimetest.NewInvoker(t).Respond(
+		`{"name":"weather","args":{"city":"Rome"}}`,
+		`{"name":"forecast","args":{"city":"Rome"}}`,
+		`{"done":true,"out":"Sunny"}`,
+	)
+	rt := runtime.NewRuntime(mock, runtime.WithTracer(collector))
+
+	ctx := runtime.WithModelOptions(context.Background(), runtime.ModelOptions{Model: "small"})
+	err = rt.Invoke(ctx, runtime.Request{
+		PromptTemplate: "Weather in Rome?",
+		PromptVersion:  "v2",
+		Input:          map[string]any{},
+		InputSchema:    runtime.NewSchema(`{"type":"object"}`),
+		Output:         new(string),
+		ToolUnmarshaller: func(name string, data []byte) (any, error) {
+			var args map[string]string
+			return args, json.Unmarshal(data, &args)
+		},
+		ToolInvoker: func(ctx context.Context, name string, in any) (any, error) {
+			if name == "forecast" {
+				return nil, errors.New("forecast unavailable")
+			}
+			return "sunny", nil
+		},
+	})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	counters := []struct {
+		name   string
+		labels map[string]string
+		value  float64
+	}{
+		{"suricata_invocations_total", map[string]string{"prompt_version": "v2", "status": "ok"}, 1},
+		{"suricata_llm_calls_total", map[string]string{"model": "small", "status": "ok"}, 3},
+		{"suricata_tool_calls_total", map[string]string{"tool": "weather", "status": "ok"}, 1},
+		{"suricata_tool_calls_total", map[string]string{"tool": "forecast", "status": "error"}, 1},
+	}
+	for _, c := range counters {
+		m := find(t, reg, c.name, c.labels)
+		if m == nil || m.GetCounter().GetValue() != c.value {
+			t.Errorf("%s%v: expected %v, got %v", c.name, c.labels, c.value, m)
+		}
+	}
+
+	for _, typ := range []string{"prompt", "completion"} {
+		if m := find(t, reg, "suricata_tokens_total", map[string]string{"model": "small", "type": typ}); m.GetCounter().GetValue() <= 0 {
+			t.Errorf("expected %s tokens to be counted, got %v", typ, m)
+		}
+	}
+
+	histograms := []struct {
+		name   string
+		labels map[string]string
+		count  uint64
+	}{
+		{"suricata_invocation_duration_seconds", map[string]string{"prompt_version": "v2"}, 1},
+		{"suricata_llm_call_duration_seconds", map[string]string{"model": "small"}, 3},
+		{"suricata_tool_call_duration_seconds", map[string]string{"tool": "forecast"}, 1},
+	}
+	for _, h := range histograms {
+		m := find(t, reg, h.name, h.labels)
+		if m == nil || m.GetHistogram().GetSampleCount() != h.count {
+			t.Errorf("%s%v: expected %d observations, got %v", h.name, h.labels, h.count, m)
+		}
+	}
+}
+
+func TestNewCollector_AlreadyRegistered(t *testing.T) {
+	reg := prometheus.NewRegistry()
+	if _, err := metrics.NewCollector(reg); err != nil {
+		t.Fatal(err)
+	}
+
+	var already prometheus.AlreadyRegisteredError
+	if _, err := metrics.NewCollector(reg); !errors.As(err, &already) {
+		t.Errorf("expected AlreadyRegisteredError, got %v", err)
+	}
+}
@@ -0,0 +1,115 @@
+// Copyright (c) 2025 Suricata Contributors
+// Original Author: Stefano Scafiti
+//
+// This file is part of Suricata: Type-Safe AI Agents for Go.
+//
+// Licensed under the MIT License. You may obtain a copy of the License at
+//
+//	https://opensource.org/licenses/MIT
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package runtime
+
+import "context"
+
+// InvokerFunc adapts an ordinary function to the Invoker interface.
+type InvokerFunc func(ctx context.Context, systemPrompt string, messages []Message) (string, error)
+
+func (f InvokerFunc) Invoke(ctx context.Context, systemPrompt string, messages []Message) (string, error) {
+	return f(ctx, systemPrompt, messages)
+}
+
+// InvokerMiddleware wraps an Invoker to add cross-cutting behaviour such as
+// logging, rate limiting, caching or redaction.
+type InvokerMiddleware func(next Invoker) Invoker
+
+// Chain wraps invoker with the given middlewares. The first middleware is the
+// outermost one, so it sees each call first and each response last.
+//
+// If invoker is a StreamingInvoker, so is the chain: the deltas of a streamed call reach
+// the caller through middlewares which only implement Invoke. A middleware answering
+// without calling the next invoker, e.g. from a cache, delivers its response as a single delta.
+func Chain(invoker Invoker, middlewares ...InvokerMiddleware) Invoker {
+	streaming, ok := invoker.(StreamingInvoker)
+	if !ok || len(middlewares) == 0 {
+		for i := len(middlewares) - 1; i >= 0; i-- {
+			invoker = middlewares[i](invoker)
+		}
+		return invoker
+	}
+
+	end := &streamingEnd{next: streaming}
+
+	invoker = end
+	for i := len(middlewares) - 1; i >= 0; i-- {
+		invoker = middlewares[i](invoker)
+	}
+
+	if _, ok := invoker.(StreamingInvoker); ok {
+		return invoker
+	}
+	return streamingChain{Invoker: invoker, end: end}
+}
+
+// deltaSinkKey is the context key of the deltaSink of the chain ending with end, so that
+// the calls of other chains sharing the context, made by a middleware, are not streamed.
+type deltaSinkKey struct {
+	end *streamingEnd
+}
+
+// deltaSink forwards the deltas of a call streamed through a chain of middlewares.
+type deltaSink struct {
+	onDelta   func(delta string)
+	delivered bool
+}
+
+func (s *deltaSink) deliver(delta string) {
+	s.delivered = true
+	s.onDelta(delta)
+}
+
+// streamingChain is a chain of middlewares ending with a streaming invoker. It passes the
+// deltas handler of InvokeStream calls through the context to the end of the chain.
+type streamingChain struct {
+	Invoker
+	end *streamingEnd
+}
+
+func (c streamingChain) InvokeStream(ctx context.Context, systemPrompt string, messages []Message, onDelta func(delta string)) (string, error) {
+	sink := &deltaSink{onDelta: onDelta}
+
+	out, err := c.Invoke(context.WithValue(ctx, deltaSinkKey{end: c.end}, sink), systemPrompt, messages)
+	if err == nil && !sink.delivered {
+		onDelta(out)
+	}
+	return out, err
+}
+
+// streamingEnd is the end of a streamingChain: it streams the calls whose context
+// carries a deltaSink.
+type streamingEnd struct {
+	next StreamingInvoker
+}
+
+func (e *streamingEnd) Invoke(ctx context.Context, systemPrompt string, messages []Message) (string, error) {
+	if sink, ok := ctx.Value(deltaSinkKey{end: e}).(*deltaSink); ok {
+		return e.next.InvokeStream(ctx, systemPrompt, messages, sink.deliver)
+	}
+	return e.next.Invoke(ctx, systemPrompt, messages)
+}
+
+func (e *streamingEnd) InvokeStream(ctx context.Context, systemPrompt string, messages []Message, onDelta func(delta string)) (string, error) {
+	return e.next.InvokeStream(ctx, systemPrompt, messages, onDelta)
+}
+
+// WithMiddleware wraps the invoker of the runtime with middlewares, as Chain does.
+func WithMiddleware(middlewares ...InvokerMiddleware) Option {
+	return func(r *Runtime) {
+		r.invoker = Chain(r.invoker, middlewares...)
+	}
+}
@@ -0,0 +1,55 @@
+// Copyright (c) 2025 Suricata Contributors
+// Original Author: Stefano Scafiti
+//
+// This file is part of Suricata: Type-Safe AI Agents for Go.
+//
+// Licensed under the MIT License. You may obtain a copy of the License at
+//
+//	https://opensource.org/licenses/MIT
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+package runtime_test
+
+import (
+	"context"
+	"strings"
+	"testing"
+
+	"github.com/ostafen/suricata/runtime"
+)
+
+func TestChain_Order(t *testing.T) {
+	var calls []string
+
+	tag := func(name string) runtime.InvokerMiddleware {
+		return func(next runtime.Invoker) runtime.Invoker {
+			return runtime.InvokerFunc(func(ctx context.Context, system string, messages []runtime.Message) (string, error) {
+				calls = append(calls, name)
+				out, err := next.Invoke(ctx, system, messages)
+				return name + "(" + out + ")", err
+			})
+		}
+	}
+
+	base := runtime.InvokerFunc(func(ctx context.Context, system string, messages []runtime.Message) (string, error) {
+		calls = append(calls, "base")
+		return "out", nil
+	})
+
+	out, err := runtime.Chain(base, tag("a"), tag("b")).Invoke(context.Background(), "", nil)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	if got := strings.Join(calls, ","); got != "a,b,base" {
+		t.Errorf("expected call order a,b,base, got %s", got)
+	}
+
+	if out != "a(b(out))" {
+		t.Errorf("expected a(b(out)), got %s", out)
+	}
+}
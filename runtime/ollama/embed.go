@@ -0,0 +1,80 @@
+// Copyright (c) 2025 Suricata Contributors
+// Original Author: Stefano Scafiti
+//
+// This file is part of Suricata: Type-Safe AI Agents for Go.
+//
+// Licensed under the MIT License. You may obtain a copy of the License at
+//
+//	https://opensource.org/licenses/MIT
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package ollama
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+
+	"github.com/ostafen/suricata/runtime"
+)
+
+// OllamaEmbedder is a runtime.Embedder backed by the embed endpoint of Ollama.
+type OllamaEmbedder struct {
+	baseURL string
+	model   string
+
+	HTTPClient *http.Client // Client sending the requests. Nil means runtime.DefaultHTTPClient.
+}
+
+// NewEmbedder returns an embedder using the given embedding model, e.g. "nomic-embed-text".
+func NewEmbedder(baseURL, model string) *OllamaEmbedder {
+	return &OllamaEmbedder{
+		baseURL: baseURL,
+		model:   model,
+	}
+}
+
+func (o *OllamaEmbedder) Embed(ctx context.Context, texts []string) ([][]float32, error) {
+	data, err := json.Marshal(map[string]any{
+		"model": o.model,
+		"input": texts,
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	req, err := http.NewRequestWithContext(ctx, "POST", fmt.Sprintf("%s/api/embed", o.baseURL), bytes.NewBuffer(data))
+	if err != nil {
+		return nil, err
+	}
+	req.Header.Set("Content-Type", "application/json")
+
+	resp, err := httpClient(o.HTTPClient).Do(req)
+	if err != nil {
+		return nil, err
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != 200 {
+		return nil, runtime.NewProviderError("ollama", resp)
+	}
+
+	var result struct {
+		Embeddings [][]float32 `json:"embeddings"`
+	}
+	if err := json.NewDecoder(resp.Body).Decode(&result); err != nil {
+		return nil, err
+	}
+
+	if len(result.Embeddings) != len(texts) {
+		return nil, fmt.Errorf("ollama error: expected %d embeddings, got %d", len(texts), len(result.Embeddings))
+	}
+	return result.Embeddings, nil
+}
@@ -22,6 +22,7 @@ import (
 	"fmt"
 	"io"
 	"net/http"
+	"strings"
 
 	"github.com/ostafen/suricata/runtime"
 )
@@ -32,6 +33,8 @@ type OllamaInvoker struct {
 	baseURL string
 	model   string
 	opts    Options
+
+	HTTPClient *http.Client // Client sending the requests. Nil means runtime.DefaultHTTPClient.
 }
 
 func NewInvoker(baseURL, model string, opts Options) *OllamaInvoker {
@@ -42,6 +45,11 @@ func NewInvoker(baseURL, model string, opts Options) *OllamaInvoker {
 	}
 }
 
+// ContextSize implements runtime.ContextSizer, returning the NumCtx option of the invoker.
+func (o *OllamaInvoker) ContextSize() int {
+	return o.opts.NumCtx
+}
+
 func roleToOllamaRole(role runtime.Role) string {
 	switch role {
 	case runtime.RoleSystem:
@@ -50,19 +58,25 @@ func roleToOllamaRole(role runtime.Role) string {
 		return "assistant"
 	case runtime.RoleUser:
 		return "user"
+	case runtime.RoleTool:
+		return "tool"
 	default:
 		return "user"
 	}
 }
 
 type OllamaMessage struct {
-	Role    string `json:"role"`
-	Content string `json:"content"`
+	Role     string   `json:"role"`
+	Content  string   `json:"content"`
+	ToolName string   `json:"tool_name,omitempty"` // Tool whose output is reported by a tool message
+	Images   [][]byte `json:"images,omitempty"`    // Images for multimodal models, encoded in base64
 }
 
 type Options struct {
 	Temperature float64 `json:"temperature"`
 	NumCtx      int     `json:"num_ctx"`
+	NumPredict  int     `json:"num_predict,omitempty"` // Maximum number of tokens to generate
+	Seed        *int    `json:"seed,omitempty"`        // Seed of the sampling, for reproducible responses
 }
 
 type OllamaPayload struct {
@@ -70,17 +84,94 @@ type OllamaPayload struct {
 	Messages []OllamaMessage `json:"messages"`
 	Prompt   string          `json:"prompt"`
 	Stream   bool            `json:"stream"`
+	Format   json.RawMessage `json:"format,omitempty"` // JSON schema constraining the response
 	Options  Options         `json:"options"`
 }
 
 func (o *OllamaInvoker) Invoke(ctx context.Context, systemPrompt string, messages []runtime.Message) (string, error) {
+	resp, err := o.post(ctx, o.payload(ctx, systemPrompt, messages, false))
+	if err != nil {
+		return "", err
+	}
+	defer resp.Body.Close()
+
+	var result struct {
+		Message OllamaMessage `json:"message"`
+	}
+
+	if err := json.NewDecoder(resp.Body).Decode(&result); err != nil {
+		return "", err
+	}
+	return result.Message.Content, nil
+}
+
+// InvokeStream implements runtime.StreamingInvoker, decoding the stream of
+// partial responses returned by the chat endpoint.
+func (o *OllamaInvoker) InvokeStream(ctx context.Context, systemPrompt string, messages []runtime.Message, onDelta func(delta string)) (string, error) {
+	resp, err := o.post(ctx, o.payload(ctx, systemPrompt, messages, true))
+	if err != nil {
+		return "", err
+	}
+	defer resp.Body.Close()
+
+	var out strings.Builder
+
+	dec := json.NewDecoder(resp.Body)
+	for {
+		var chunk struct {
+			Message OllamaMessage `json:"message"`
+			Done    bool          `json:"done"`
+			Error   string        `json:"error"`
+		}
+
+		if err := dec.Decode(&chunk); err != nil {
+			if err == io.EOF {
+				return out.String(), nil
+			}
+			return "", err
+		}
+
+		if chunk.Error != "" {
+			return "", fmt.Errorf("ollama error: %s", chunk.Error)
+		}
+
+		if chunk.Message.Content != "" {
+			out.WriteString(chunk.Message.Content)
+			onDelta(chunk.Message.Content)
+		}
+
+		if chunk.Done {
+			return out.String(), nil
+		}
+	}
+}
+
+func (o *OllamaInvoker) payload(ctx context.Context, systemPrompt string, messages []runtime.Message, stream bool) OllamaPayload {
+	modelOpts := runtime.ModelOptionsFromContext(ctx)
+
 	payload := OllamaPayload{
-		Model:    o.model,
+		Model:    modelOpts.ModelOr(o.model),
 		Messages: nil,
-		Stream:   false,
+		Stream:   stream,
 		Options:  o.opts,
 	}
 
+	if modelOpts.Temperature != nil {
+		payload.Options.Temperature = *modelOpts.Temperature
+	}
+	payload.Options.NumPredict = modelOpts.MaxTokensOr(o.opts.NumPredict)
+	if modelOpts.Seed != nil {
+		payload.Options.Seed = modelOpts.Seed
+	}
+
+	// The schema is only a hint: if it cannot be loaded, the response is still
+	// extracted and validated by the runtime.
+	if schema := runtime.OutputSchemaFromContext(ctx); schema != nil {
+		if v, err := schema.LoadJSON(); err == nil {
+			payload.Format, _ = json.Marshal(v)
+		}
+	}
+
 	if systemPrompt != "" {
 		payload.Messages = append(payload.Messages, OllamaMessage{
 			Role:    roleToOllamaRole(runtime.RoleSystem),
@@ -89,40 +180,100 @@ func (o *OllamaInvoker) Invoke(ctx context.Context, systemPrompt string, message
 	}
 
 	for _, m := range messages {
-		payload.Messages = append(payload.Messages, OllamaMessage{
+		msg := OllamaMessage{
 			Role:    roleToOllamaRole(m.Role),
 			Content: m.Content,
-		})
+		}
+		if m.ToolCall != nil {
+			msg.ToolName = m.ToolCall.Name
+		}
+		for _, att := range m.Attachments {
+			if att.IsImage() {
+				msg.Images = append(msg.Images, att.Data)
+			}
+		}
+		payload.Messages = append(payload.Messages, msg)
 	}
+	return payload
+}
 
+// post sends payload to the chat endpoint. On success, the caller must close the response body.
+func (o *OllamaInvoker) post(ctx context.Context, payload OllamaPayload) (*http.Response, error) {
 	data, err := json.Marshal(payload)
 	if err != nil {
-		return "", err
+		return nil, err
 	}
 
 	req, err := http.NewRequestWithContext(ctx, "POST", fmt.Sprintf("%s/api/chat", o.baseURL), bytes.NewBuffer(data))
 	if err != nil {
-		return "", err
+		return nil, err
 	}
 	req.Header.Set("Content-Type", "application/json")
 
-	resp, err := http.DefaultClient.Do(req)
+	resp, err := httpClient(o.HTTPClient).Do(req)
 	if err != nil {
-		return "", err
+		return nil, err
+	}
+
+	if resp.StatusCode != 200 {
+		defer resp.Body.Close()
+
+		return nil, runtime.NewProviderError("ollama", resp)
+	}
+	return resp, nil
+}
+
+// ListModels implements runtime.HealthChecker, returning the models pulled on the server.
+func (o *OllamaInvoker) ListModels(ctx context.Context) ([]string, error) {
+	req, err := http.NewRequestWithContext(ctx, "GET", fmt.Sprintf("%s/api/tags", o.baseURL), nil)
+	if err != nil {
+		return nil, err
+	}
+
+	resp, err := httpClient(o.HTTPClient).Do(req)
+	if err != nil {
+		return nil, err
 	}
 	defer resp.Body.Close()
 
 	if resp.StatusCode != 200 {
-		body, _ := io.ReadAll(resp.Body)
-		return "", fmt.Errorf("ollama error: %s", string(body))
+		return nil, runtime.NewProviderError("ollama", resp)
 	}
 
 	var result struct {
-		Message OllamaMessage `json:"message"`
+		Models []struct {
+			Name string `json:"name"`
+		} `json:"models"`
 	}
-
 	if err := json.NewDecoder(resp.Body).Decode(&result); err != nil {
-		return "", err
+		return nil, err
 	}
-	return result.Message.Content, nil
+
+	models := make([]string, len(result.Models))
+	for i, m := range result.Models {
+		models[i] = m.Name
+	}
+	return models, nil
+}
+
+// Ping implements runtime.HealthChecker. Models named without a tag refer to the latest one.
+func (o *OllamaInvoker) Ping(ctx context.Context) error {
+	models, err := o.ListModels(ctx)
+	if err != nil {
+		return err
+	}
+
+	model := o.model
+	if !strings.Contains(model, ":") {
+		model += ":latest"
+	}
+	return runtime.CheckModel(model, models)
+}
+
+// httpClient returns client, or runtime.DefaultHTTPClient if client is nil.
+func httpClient(client *http.Client) *http.Client {
+	if client == nil {
+		return runtime.DefaultHTTPClient
+	}
+	return client
 }
@@ -0,0 +1,104 @@
+// Copyright (c) 2025 Suricata Contributors
+// Original Author: Stefano Scafiti
+//
+// This file is part of Suricata: Type-Safe AI Agents for Go.
+//
+// Licensed under the MIT License. You may obtain a copy of the License at
+//
+//	https://opensource.org/licenses/MIT
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package ollama_test
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/ostafen/suricata/runtime"
+	"github.com/ostafen/suricata/runtime/ollama"
+)
+
+func TestInvoke_Format(t *testing.T) {
+	var formats []json.RawMessage
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		var payload ollama.OllamaPayload
+		if err := json.NewDecoder(r.Body).Decode(&payload); err != nil {
+			t.Errorf("failed to decode payload: %v", err)
+		}
+		formats = append(formats, payload.Format)
+		w.Write([]byte(`{"message":{"role":"assistant","content":"{\"city\":\"Rome\"}"}}`))
+	}))
+	defer srv.Close()
+
+	inv := ollama.NewInvoker(srv.URL, "llama3", ollama.DefaultOptions())
+	msgs := []runtime.Message{{Role: runtime.RoleUser, Content: "Capital of Italy?"}}
+
+	schema := runtime.NewSchema(`{"type":"object","properties":{"city":{"type":"string"}}}`)
+	if _, err := inv.Invoke(runtime.WithOutputSchema(context.Background(), schema), "", msgs); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := inv.Invoke(context.Background(), "", msgs); err != nil {
+		t.Fatal(err)
+	}
+
+	if len(formats) != 2 || string(formats[0]) != `{"properties":{"city":{"type":"string"}},"type":"object"}` {
+		t.Fatalf("expected the output schema as format, got %s", formats)
+	}
+	if formats[1] != nil {
+		t.Errorf("expected no format without an output schema, got %s", formats[1])
+	}
+}
+
+func TestPing(t *testing.T) {
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path != "/api/tags" {
+			t.Errorf("unexpected path %s", r.URL.Path)
+		}
+		w.Write([]byte(`{"models":[{"name":"llama3:latest"},{"name":"qwen3:8b"}]}`))
+	}))
+	defer srv.Close()
+
+	for _, model := range []string{"llama3", "qwen3:8b"} {
+		if err := ollama.NewInvoker(srv.URL, model, ollama.DefaultOptions()).Ping(context.Background()); err != nil {
+			t.Errorf("%s: unexpected error: %v", model, err)
+		}
+	}
+
+	err := ollama.NewInvoker(srv.URL, "qwen3", ollama.DefaultOptions()).Ping(context.Background())
+	if !errors.Is(err, runtime.ErrModelNotFound) {
+		t.Errorf("expected ErrModelNotFound, got %v", err)
+	}
+}
+
+func TestInvoke_Deterministic(t *testing.T) {
+	var opts ollama.Options
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		var payload ollama.OllamaPayload
+		if err := json.NewDecoder(r.Body).Decode(&payload); err != nil {
+			t.Errorf("failed to decode payload: %v", err)
+		}
+		opts = payload.Options
+		w.Write([]byte(`{"message":{"role":"assistant","content":"Rome"}}`))
+	}))
+	defer srv.Close()
+
+	inv := ollama.NewInvoker(srv.URL, "llama3", ollama.DefaultOptions())
+	msgs := []runtime.Message{{Role: runtime.RoleUser, Content: "Capital of Italy?"}}
+
+	ctx := runtime.WithModelOptions(context.Background(), runtime.Deterministic(42))
+	if _, err := inv.Invoke(ctx, "", msgs); err != nil {
+		t.Fatal(err)
+	}
+	if opts.Temperature != 0 || opts.Seed == nil || *opts.Seed != 42 {
+		t.Errorf("expected temperature 0 and seed 42, got %v and %v", opts.Temperature, opts.Seed)
+	}
+}
@@ -0,0 +1,179 @@
+// Copyright (c) 2025 Suricata Contributors
+// Original Author: Stefano Scafiti
+//
+// This file is part of Suricata: Type-Safe AI Agents for Go.
+//
+// Licensed under the MIT License. You may obtain a copy of the License at
+//
+//	https://opensource.org/licenses/MIT
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package openai
+
+import (
+	"bufio"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"strings"
+	"time"
+
+	openai "github.com/sashabaranov/go-openai"
+
+	"github.com/ostafen/suricata/runtime"
+)
+
+// DefaultBatchPollInterval is the interval between status checks of AwaitBatch when BatchPollInterval is not set.
+const DefaultBatchPollInterval = 30 * time.Second
+
+// SubmitBatch implements runtime.BatchInvoker, uploading calls as the input file of a batch
+// of chat completions, which is processed within 24 hours at about half the price.
+func (o *OpenAIInvoker) SubmitBatch(ctx context.Context, calls []runtime.BatchCall) (string, error) {
+	var file openai.UploadBatchFileRequest
+	for _, call := range calls {
+		callCtx := runtime.WithOutputSchema(runtime.WithModelOptions(ctx, call.Options), call.OutputSchema)
+		file.AddChatCompletion(call.ID, o.chatRequest(callCtx, call.SystemPrompt, call.Messages))
+	}
+
+	resp, err := o.client.CreateBatchWithUploadFile(ctx, openai.CreateBatchWithUploadFileRequest{
+		Endpoint:               openai.BatchEndpointChatCompletions,
+		UploadBatchFileRequest: file,
+	})
+	if err != nil {
+		return "", wrapError(err)
+	}
+	return resp.ID, nil
+}
+
+// AwaitBatch implements runtime.BatchInvoker, polling the batch every BatchPollInterval
+// until it ends. Expired and cancelled batches return the results of the completed calls.
+func (o *OpenAIInvoker) AwaitBatch(ctx context.Context, id string) ([]runtime.BatchResult, error) {
+	interval := o.BatchPollInterval
+	if interval <= 0 {
+		interval = DefaultBatchPollInterval
+	}
+
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+
+	for {
+		batch, err := o.client.RetrieveBatch(ctx, id)
+		if err != nil {
+			return nil, wrapError(err)
+		}
+
+		switch batch.Status {
+		case "failed":
+			return nil, fmt.Errorf("%w: %s", runtime.ErrBatchFailed, batchErrors(batch.Batch))
+		case "completed", "expired", "cancelled":
+			return o.batchResults(ctx, batch.Batch)
+		}
+
+		select {
+		case <-ctx.Done():
+			return nil, ctx.Err()
+		case <-ticker.C:
+		}
+	}
+}
+
+// batchResults reads the results of the completed and failed calls of batch.
+func (o *OpenAIInvoker) batchResults(ctx context.Context, batch openai.Batch) ([]runtime.BatchResult, error) {
+	var results []runtime.BatchResult
+	for _, fileID := range []*string{batch.OutputFileID, batch.ErrorFileID} {
+		if fileID == nil || *fileID == "" {
+			continue
+		}
+
+		content, err := o.client.GetFileContent(ctx, *fileID)
+		if err != nil {
+			return nil, wrapError(err)
+		}
+
+		res, err := readBatchResults(content)
+		content.Close()
+		if err != nil {
+			return nil, err
+		}
+		results = append(results, res...)
+	}
+	return results, nil
+}
+
+// batchLine is a line of the output and error files of a batch.
+type batchLine struct {
+	CustomID string `json:"custom_id"`
+	Response *struct {
+		StatusCode int             `json:"status_code"`
+		Body       json.RawMessage `json:"body"`
+	} `json:"response"`
+	Error *struct {
+		Code    string `json:"code"`
+		Message string `json:"message"`
+	} `json:"error"`
+}
+
+func readBatchResults(r io.Reader) ([]runtime.BatchResult, error) {
+	var results []runtime.BatchResult
+
+	sc := bufio.NewScanner(r)
+	sc.Buffer(nil, 64<<20)
+	for sc.Scan() {
+		if strings.TrimSpace(sc.Text()) == "" {
+			continue
+		}
+
+		var line batchLine
+		if err := json.Unmarshal(sc.Bytes(), &line); err != nil {
+			return nil, fmt.Errorf("invalid batch result: %w", err)
+		}
+		results = append(results, batchResult(line))
+	}
+	return results, sc.Err()
+}
+
+func batchResult(line batchLine) runtime.BatchResult {
+	res := runtime.BatchResult{ID: line.CustomID}
+
+	switch {
+	case line.Error != nil:
+		res.Err = fmt.Errorf("%w: %s: %s", runtime.ErrBatchFailed, line.Error.Code, line.Error.Message)
+	case line.Response == nil:
+		res.Err = fmt.Errorf("%w: missing response", runtime.ErrBatchFailed)
+	case line.Response.StatusCode != 200:
+		res.Err = &runtime.ProviderError{
+			Provider:   "openai",
+			StatusCode: line.Response.StatusCode,
+			Err:        errors.New(string(line.Response.Body)),
+		}
+	default:
+		var body openai.ChatCompletionResponse
+		if err := json.Unmarshal(line.Response.Body, &body); err != nil {
+			res.Err = fmt.Errorf("invalid batch response: %w", err)
+		} else if len(body.Choices) == 0 {
+			res.Err = errors.New("no response from OpenAI")
+		} else {
+			res.Output = body.Choices[0].Message.Content
+		}
+	}
+	return res
+}
+
+func batchErrors(batch openai.Batch) string {
+	if batch.Errors == nil || len(batch.Errors.Data) == 0 {
+		return batch.Status
+	}
+
+	msgs := make([]string, len(batch.Errors.Data))
+	for i, e := range batch.Errors.Data {
+		msgs[i] = e.Message
+	}
+	return strings.Join(msgs, "; ")
+}
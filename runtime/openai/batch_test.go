@@ -0,0 +1,121 @@
+// Copyright (c) 2025 Suricata Contributors
+// Original Author: Stefano Scafiti
+//
+// This file is part of Suricata: Type-Safe AI Agents for Go.
+//
+// Licensed under the MIT License. You may obtain a copy of the License at
+//
+//	https://opensource.org/licenses/MIT
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package openai_test
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	goopenai "github.com/sashabaranov/go-openai"
+
+	"github.com/ostafen/suricata/runtime"
+	"github.com/ostafen/suricata/runtime/openai"
+)
+
+func TestBatch(t *testing.T) {
+	var (
+		input  string
+		polls  int
+		output = `{"custom_id":"request-0","response":{"status_code":200,"body":{"choices":[{"message":{"role":"assistant","content":"{\"city\":\"Rome\"}"}}]}}}
+{"custom_id":"request-1","response":{"status_code":400,"body":{"error":{"message":"invalid model"}}}}
+`
+	)
+
+	mux := http.NewServeMux()
+	mux.HandleFunc("POST /v1/files", func(w http.ResponseWriter, r *http.Request) {
+		file, _, err := r.FormFile("file")
+		if err != nil {
+			t.Fatal(err)
+		}
+		data, _ := io.ReadAll(file)
+		input = string(data)
+		w.Write([]byte(`{"id":"file-in"}`))
+	})
+	mux.HandleFunc("POST /v1/batches", func(w http.ResponseWriter, r *http.Request) {
+		w.Write([]byte(`{"id":"batch-1","status":"validating"}`))
+	})
+	mux.HandleFunc("GET /v1/batches/batch-1", func(w http.ResponseWriter, r *http.Request) {
+		polls++
+		if polls < 2 {
+			w.Write([]byte(`{"id":"batch-1","status":"in_progress"}`))
+			return
+		}
+		w.Write([]byte(`{"id":"batch-1","status":"completed","output_file_id":"file-out"}`))
+	})
+	mux.HandleFunc("GET /v1/files/file-out/content", func(w http.ResponseWriter, r *http.Request) {
+		w.Write([]byte(output))
+	})
+
+	srv := httptest.NewServer(mux)
+	defer srv.Close()
+
+	cfg := goopenai.DefaultConfig("key")
+	cfg.BaseURL = srv.URL + "/v1"
+	inv := openai.NewInvokerWithConfig(cfg, "gpt-4o-mini")
+	inv.BatchPollInterval = time.Millisecond
+
+	schema := runtime.NewSchema(`{"type":"object","properties":{"city":{"type":"string"}},"required":["city"]}`)
+	id, err := inv.SubmitBatch(context.Background(), []runtime.BatchCall{
+		{ID: "request-0", SystemPrompt: "Be brief.", Messages: []runtime.Message{{Role: runtime.RoleUser, Content: "Capital of Italy?"}}, OutputSchema: schema},
+		{ID: "request-1", Messages: []runtime.Message{{Role: runtime.RoleUser, Content: "Hi"}}, Options: runtime.ModelOptions{Model: "unknown"}},
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if id != "batch-1" {
+		t.Fatalf("unexpected batch id %q", id)
+	}
+
+	lines := strings.Split(input, "\n")
+	if len(lines) != 2 {
+		t.Fatalf("expected 2 input lines, got %q", input)
+	}
+
+	var first struct {
+		CustomID string                         `json:"custom_id"`
+		URL      string                         `json:"url"`
+		Body     goopenai.ChatCompletionRequest `json:"body"`
+	}
+	if err := json.Unmarshal([]byte(lines[0]), &first); err != nil {
+		t.Fatal(err)
+	}
+	if first.CustomID != "request-0" || first.URL != "/v1/chat/completions" || first.Body.ResponseFormat == nil || len(first.Body.Messages) != 2 {
+		t.Errorf("unexpected first line: %s", lines[0])
+	}
+	if !strings.Contains(lines[1], `"model":"unknown"`) {
+		t.Errorf("expected the model override in the second line: %s", lines[1])
+	}
+
+	results, err := inv.AwaitBatch(context.Background(), id)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(results) != 2 || results[0].Output != `{"city":"Rome"}` || results[0].Err != nil {
+		t.Fatalf("unexpected results: %+v", results)
+	}
+
+	var provErr *runtime.ProviderError
+	if !errors.As(results[1].Err, &provErr) || provErr.StatusCode != http.StatusBadRequest {
+		t.Errorf("expected a provider error, got %v", results[1].Err)
+	}
+}
@@ -0,0 +1,81 @@
+// Copyright (c) 2025 Suricata Contributors
+// Original Author: Stefano Scafiti
+//
+// This file is part of Suricata: Type-Safe AI Agents for Go.
+//
+// Licensed under the MIT License. You may obtain a copy of the License at
+//
+//	https://opensource.org/licenses/MIT
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package openai
+
+import (
+	"context"
+	"fmt"
+
+	openai "github.com/sashabaranov/go-openai"
+
+	"github.com/ostafen/suricata/runtime"
+)
+
+// DefaultEmbeddingModel is a cheap general-purpose embedding model.
+const DefaultEmbeddingModel = string(openai.SmallEmbedding3)
+
+// OpenAIEmbedder is a runtime.Embedder backed by the OpenAI embeddings API.
+type OpenAIEmbedder struct {
+	client *openai.Client
+	model  string
+}
+
+func NewEmbedder(authToken string, model string) *OpenAIEmbedder {
+	cfg := openai.DefaultConfig(authToken)
+	cfg.HTTPClient = runtime.DefaultHTTPClient
+
+	return NewEmbedderWithConfig(cfg, model)
+}
+
+// NewEmbedderWithBaseURL returns an embedder for an OpenAI-compatible embeddings endpoint.
+func NewEmbedderWithBaseURL(baseURL, authToken, model string) *OpenAIEmbedder {
+	cfg := openai.DefaultConfig(authToken)
+	cfg.BaseURL = baseURL
+	cfg.HTTPClient = runtime.DefaultHTTPClient
+
+	return NewEmbedderWithConfig(cfg, model)
+}
+
+// NewEmbedderWithConfig returns an embedder using the given client configuration.
+func NewEmbedderWithConfig(cfg openai.ClientConfig, model string) *OpenAIEmbedder {
+	return &OpenAIEmbedder{
+		client: openai.NewClientWithConfig(cfg),
+		model:  model,
+	}
+}
+
+func (o *OpenAIEmbedder) Embed(ctx context.Context, texts []string) ([][]float32, error) {
+	resp, err := o.client.CreateEmbeddings(ctx, openai.EmbeddingRequestStrings{
+		Input: texts,
+		Model: openai.EmbeddingModel(o.model),
+	})
+	if err != nil {
+		return nil, wrapError(err)
+	}
+
+	if len(resp.Data) != len(texts) {
+		return nil, fmt.Errorf("expected %d embeddings from OpenAI, got %d", len(texts), len(resp.Data))
+	}
+
+	out := make([][]float32, len(texts))
+	for _, e := range resp.Data {
+		if e.Index < 0 || e.Index >= len(out) {
+			return nil, fmt.Errorf("invalid embedding index %d from OpenAI", e.Index)
+		}
+		out[e.Index] = e.Embedding
+	}
+	return out, nil
+}
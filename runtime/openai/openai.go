@@ -18,78 +18,119 @@ package openai
 import (
 	"context"
 	"errors"
+	"time"
 
 	openai "github.com/sashabaranov/go-openai"
-)
-
-type Role uint8
 
-const (
-	RoleSystem Role = iota
-	RoleAgent
-	RoleUser
+	"github.com/ostafen/suricata/runtime"
+	"github.com/ostafen/suricata/runtime/openaicompat"
 )
 
-type Message struct {
-	Role    Role
-	Content string
-}
-
-// Invoker interface
-type Invoker interface {
-	Invoke(ctx context.Context, systemPrompt string, messages []Message) (string, error)
-}
-
+// OpenAIInvoker is a runtime.Invoker backed by the OpenAI chat completions API.
 type OpenAIInvoker struct {
 	client *openai.Client
 	model  string
+
+	// OutputMode selects how output schemas are passed to the model.
+	// NewInvoker defaults to openaicompat.OutputSchema (structured outputs).
+	OutputMode openaicompat.OutputMode
+
+	BatchPollInterval time.Duration // Interval between status checks of AwaitBatch. Zero means DefaultBatchPollInterval.
 }
 
 func NewInvoker(authToken string, model string) *OpenAIInvoker {
+	cfg := openai.DefaultConfig(authToken)
+	cfg.HTTPClient = runtime.DefaultHTTPClient
+
+	return NewInvokerWithConfig(cfg, model)
+}
+
+// NewInvokerWithConfig returns an invoker using the given client configuration,
+// which allows for setting the HTTP client of the invoker.
+func NewInvokerWithConfig(cfg openai.ClientConfig, model string) *OpenAIInvoker {
 	return &OpenAIInvoker{
-		client: openai.NewClient(authToken),
-		model:  model,
+		client:     openai.NewClientWithConfig(cfg),
+		model:      model,
+		OutputMode: openaicompat.OutputSchema,
+	}
+}
+
+func (o *OpenAIInvoker) Invoke(ctx context.Context, systemPrompt string, messages []runtime.Message) (string, error) {
+	resp, err := o.client.CreateChatCompletion(ctx, o.chatRequest(ctx, systemPrompt, messages))
+	if err != nil {
+		return "", wrapError(err)
+	}
+
+	if len(resp.Choices) == 0 {
+		return "", errors.New("no response from OpenAI")
 	}
+	return resp.Choices[0].Message.Content, nil
 }
 
-func roleToOpenAIRole(role Role) string {
-	switch role {
-	case RoleSystem:
-		return "system"
-	case RoleAgent:
-		return "assistant"
-	case RoleUser:
-		return "user"
-	default:
-		return "user"
+// InvokeStream implements runtime.StreamingInvoker on top of the streaming chat completions API.
+func (o *OpenAIInvoker) InvokeStream(ctx context.Context, systemPrompt string, messages []runtime.Message, onDelta func(delta string)) (string, error) {
+	out, err := openaicompat.StreamChatCompletion(ctx, o.client, o.chatRequest(ctx, systemPrompt, messages), onDelta)
+	if err != nil {
+		return "", wrapError(err)
 	}
+	return out, nil
 }
 
-func (o *OpenAIInvoker) Invoke(ctx context.Context, systemPrompt string, messages []Message) (string, error) {
-	var chatMessages []openai.ChatCompletionMessage
+func (o *OpenAIInvoker) chatRequest(ctx context.Context, systemPrompt string, messages []runtime.Message) openai.ChatCompletionRequest {
+	opts := runtime.ModelOptionsFromContext(ctx)
 
-	chatMessages = append(chatMessages, openai.ChatCompletionMessage{
-		Role:    "system",
-		Content: systemPrompt,
-	})
+	chatReq := openai.ChatCompletionRequest{
+		Model:          opts.ModelOr(o.model),
+		Messages:       openaicompat.ChatMessages(systemPrompt, messages),
+		MaxTokens:      opts.MaxTokens,
+		ResponseFormat: openaicompat.ResponseFormat(ctx, o.OutputMode),
+	}
+	if opts.Temperature != nil {
+		chatReq.Temperature = openaicompat.Temperature(*opts.Temperature)
+	}
+	chatReq.Seed = opts.Seed
+	return chatReq
+}
+
+// ListModels implements runtime.HealthChecker, returning the models available through the API.
+func (o *OpenAIInvoker) ListModels(ctx context.Context) ([]string, error) {
+	list, err := o.client.ListModels(ctx)
+	if err != nil {
+		return nil, wrapError(err)
+	}
 
-	for _, m := range messages {
-		chatMessages = append(chatMessages, openai.ChatCompletionMessage{
-			Role:    roleToOpenAIRole(m.Role),
-			Content: m.Content,
-		})
+	models := make([]string, len(list.Models))
+	for i, m := range list.Models {
+		models[i] = m.ID
 	}
+	return models, nil
+}
 
-	resp, err := o.client.CreateChatCompletion(ctx, openai.ChatCompletionRequest{
-		Model:    o.model,
-		Messages: chatMessages,
-	})
+// Ping implements runtime.HealthChecker.
+func (o *OpenAIInvoker) Ping(ctx context.Context) error {
+	models, err := o.ListModels(ctx)
 	if err != nil {
-		return "", err
+		return err
 	}
+	return runtime.CheckModel(o.model, models)
+}
 
-	if len(resp.Choices) == 0 {
-		return "", errors.New("no response from OpenAI")
+// CountTokens implements runtime.Tokenizer, estimating the tokens of text with the
+// encodings of OpenAI models.
+func (o *OpenAIInvoker) CountTokens(text string) int {
+	return runtime.TiktokenTokenizer.CountTokens(text)
+}
+
+// wrapError turns the error responses of the API into a *runtime.ProviderError.
+func wrapError(err error) error {
+	var apiErr *openai.APIError
+	if errors.As(err, &apiErr) && apiErr.HTTPStatusCode != 0 {
+		return &runtime.ProviderError{Provider: "openai", StatusCode: apiErr.HTTPStatusCode, Err: err}
 	}
-	return resp.Choices[0].Message.Content, nil
+
+	var reqErr *openai.RequestError
+	if errors.As(err, &reqErr) && reqErr.HTTPStatusCode != 0 {
+		return &runtime.ProviderError{Provider: "openai", StatusCode: reqErr.HTTPStatusCode, Err: err}
+	}
+	return err
 }
@@ -0,0 +1,71 @@
+// Copyright (c) 2025 Suricata Contributors
+// Original Author: Stefano Scafiti
+//
+// This file is part of Suricata: Type-Safe AI Agents for Go.
+//
+// Licensed under the MIT License. You may obtain a copy of the License at
+//
+//	https://opensource.org/licenses/MIT
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package openai_test
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	goopenai "github.com/sashabaranov/go-openai"
+
+	"github.com/ostafen/suricata/runtime"
+	"github.com/ostafen/suricata/runtime/openai"
+)
+
+func TestInvokeStream(t *testing.T) {
+	var req goopenai.ChatCompletionRequest
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+			t.Errorf("failed to decode request: %v", err)
+		}
+		w.Header().Set("Content-Type", "text/event-stream")
+		w.Write([]byte(`data: {"choices":[{"delta":{"role":"assistant"}}]}
+
+data: {"choices":[{"delta":{"content":"Ro"}}]}
+
+data: {"choices":[{"delta":{"content":"me"}}]}
+
+data: [DONE]
+
+`))
+	}))
+	defer srv.Close()
+
+	cfg := goopenai.DefaultConfig("key")
+	cfg.BaseURL = srv.URL + "/v1"
+	inv := openai.NewInvokerWithConfig(cfg, "gpt-4o-mini")
+
+	var deltas []string
+	out, err := inv.InvokeStream(context.Background(), "", []runtime.Message{{Role: runtime.RoleUser, Content: "Capital of Italy?"}}, func(delta string) {
+		deltas = append(deltas, delta)
+	})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	if !req.Stream {
+		t.Error("expected a streaming request")
+	}
+	if out != "Rome" {
+		t.Errorf("expected 'Rome', got %q", out)
+	}
+	if len(deltas) != 2 || deltas[0] != "Ro" || deltas[1] != "me" {
+		t.Errorf("unexpected deltas: %q", deltas)
+	}
+}
@@ -0,0 +1,154 @@
+// Copyright (c) 2025 Suricata Contributors
+// Original Author: Stefano Scafiti
+//
+// This file is part of Suricata: Type-Safe AI Agents for Go.
+//
+// Licensed under the MIT License. You may obtain a copy of the License at
+//
+//	https://opensource.org/licenses/MIT
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package openaicompat
+
+import (
+	"context"
+	"encoding/json"
+
+	openai "github.com/sashabaranov/go-openai"
+
+	"github.com/ostafen/suricata/runtime"
+)
+
+// OutputMode selects how the output schema of a request (see runtime.OutputSchemaFromContext)
+// is passed to the model.
+type OutputMode int
+
+const (
+	// OutputText sends no response format: the JSON output is extracted from the response text.
+	OutputText OutputMode = iota
+	// OutputJSON enables JSON mode, which guarantees syntactically valid JSON objects.
+	OutputJSON
+	// OutputSchema enables structured outputs, sending the output schema as a json_schema
+	// response format. The schema is enforced strictly when it meets the requirements of
+	// strict mode: every property is required, and objects are not used as maps.
+	OutputSchema
+)
+
+// ResponseFormat returns the response format for the output schema attached to ctx, or nil
+// if there is no output schema, or the mode or the schema do not allow for a response format.
+// Both JSON mode and structured outputs require the output to be an object.
+func ResponseFormat(ctx context.Context, mode OutputMode) *openai.ChatCompletionResponseFormat {
+	loader := runtime.OutputSchemaFromContext(ctx)
+	if loader == nil || mode == OutputText {
+		return nil
+	}
+
+	v, err := loader.LoadJSON()
+	if err != nil {
+		return nil
+	}
+
+	schema, ok := v.(map[string]any)
+	if !ok || schema["type"] != "object" {
+		return nil
+	}
+
+	if mode == OutputJSON {
+		return &openai.ChatCompletionResponseFormat{Type: openai.ChatCompletionResponseFormatTypeJSONObject}
+	}
+
+	strict, ok := strictSchema(schema)
+	if ok {
+		schema = strict
+	}
+
+	data, err := json.Marshal(schema)
+	if err != nil {
+		return nil
+	}
+	return &openai.ChatCompletionResponseFormat{
+		Type: openai.ChatCompletionResponseFormatTypeJSONSchema,
+		JSONSchema: &openai.ChatCompletionResponseFormatJSONSchema{
+			Name:   "output",
+			Schema: json.RawMessage(data),
+			Strict: ok,
+		},
+	}
+}
+
+// strictSchema returns a copy of schema compatible with strict mode, where objects do not
+// allow additional properties, and reports whether the conversion is possible.
+func strictSchema(schema map[string]any) (map[string]any, bool) {
+	out := make(map[string]any, len(schema)+1)
+	for k, v := range schema {
+		switch k {
+		case "properties", "$defs", "definitions":
+			// Maps of names to schemas
+			m, ok := v.(map[string]any)
+			if !ok {
+				return nil, false
+			}
+			strict := make(map[string]any, len(m))
+			for name, sub := range m {
+				if strict[name], ok = strictSubschema(sub); !ok {
+					return nil, false
+				}
+			}
+			out[k] = strict
+		case "items", "not", "anyOf", "oneOf", "allOf":
+			var ok bool
+			if out[k], ok = strictSubschema(v); !ok {
+				return nil, false
+			}
+		default:
+			out[k] = v
+		}
+	}
+
+	props, _ := schema["properties"].(map[string]any)
+	if props == nil && schema["type"] != "object" {
+		return out, true
+	}
+
+	if additional, ok := schema["additionalProperties"]; ok && additional != false {
+		return nil, false
+	}
+	out["additionalProperties"] = false
+
+	required := make(map[string]bool)
+	list, _ := schema["required"].([]any)
+	for _, name := range list {
+		if s, ok := name.(string); ok {
+			required[s] = true
+		}
+	}
+	for name := range props {
+		if !required[name] {
+			return nil, false
+		}
+	}
+	return out, true
+}
+
+// strictSubschema applies strictSchema to a schema or a list of schemas.
+func strictSubschema(v any) (any, bool) {
+	switch v := v.(type) {
+	case map[string]any:
+		return strictSchema(v)
+	case []any:
+		out := make([]any, len(v))
+		for i, sub := range v {
+			var ok bool
+			if out[i], ok = strictSubschema(sub); !ok {
+				return nil, false
+			}
+		}
+		return out, true
+	}
+	return v, true
+}
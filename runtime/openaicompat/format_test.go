@@ -0,0 +1,93 @@
+// Copyright (c) 2025 Suricata Contributors
+// Original Author: Stefano Scafiti
+//
+// This file is part of Suricata: Type-Safe AI Agents for Go.
+//
+// Licensed under the MIT License. You may obtain a copy of the License at
+//
+//	https://opensource.org/licenses/MIT
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package openaicompat_test
+
+import (
+	"context"
+	"encoding/json"
+	"testing"
+
+	openai "github.com/sashabaranov/go-openai"
+
+	"github.com/ostafen/suricata/runtime"
+	"github.com/ostafen/suricata/runtime/openaicompat"
+)
+
+func TestResponseFormat(t *testing.T) {
+	withSchema := func(src string) context.Context {
+		return runtime.WithOutputSchema(context.Background(), runtime.NewSchema(src))
+	}
+
+	t.Run("strict schema", func(t *testing.T) {
+		ctx := withSchema(`{"type":"object","properties":{"tags":{"type":"array","items":{"type":"object","properties":{"name":{"type":"string"}},"required":["name"]}}},"required":["tags"]}`)
+
+		format := openaicompat.ResponseFormat(ctx, openaicompat.OutputSchema)
+		if format == nil || format.Type != openai.ChatCompletionResponseFormatTypeJSONSchema || !format.JSONSchema.Strict {
+			t.Fatalf("expected a strict json_schema format, got %+v", format)
+		}
+
+		data, _ := json.Marshal(format.JSONSchema.Schema)
+		want := `{"additionalProperties":false,"properties":{"tags":{"items":{"additionalProperties":false,"properties":{"name":{"type":"string"}},"required":["name"],"type":"object"},"type":"array"}},"required":["tags"],"type":"object"}`
+		if string(data) != want {
+			t.Errorf("unexpected schema:\n got %s\nwant %s", data, want)
+		}
+	})
+
+	t.Run("optional properties are not strict", func(t *testing.T) {
+		ctx := withSchema(`{"type":"object","properties":{"name":{"type":"string"},"note":{"type":"string"}},"required":["name"]}`)
+
+		format := openaicompat.ResponseFormat(ctx, openaicompat.OutputSchema)
+		if format == nil || format.JSONSchema.Strict {
+			t.Fatalf("expected a non-strict json_schema format, got %+v", format)
+		}
+		if data, _ := json.Marshal(format.JSONSchema.Schema); string(data) != `{"properties":{"name":{"type":"string"},"note":{"type":"string"}},"required":["name"],"type":"object"}` {
+			t.Errorf("expected the schema unchanged, got %s", data)
+		}
+	})
+
+	t.Run("maps are not strict", func(t *testing.T) {
+		ctx := withSchema(`{"type":"object","properties":{"counts":{"type":"object","additionalProperties":{"type":"integer"}}},"required":["counts"]}`)
+
+		if format := openaicompat.ResponseFormat(ctx, openaicompat.OutputSchema); format == nil || format.JSONSchema.Strict {
+			t.Fatalf("expected a non-strict json_schema format, got %+v", format)
+		}
+	})
+
+	t.Run("json mode", func(t *testing.T) {
+		ctx := withSchema(`{"type":"object"}`)
+
+		format := openaicompat.ResponseFormat(ctx, openaicompat.OutputJSON)
+		if format == nil || format.Type != openai.ChatCompletionResponseFormatTypeJSONObject {
+			t.Fatalf("expected json_object format, got %+v", format)
+		}
+	})
+
+	t.Run("no format", func(t *testing.T) {
+		cases := []struct {
+			ctx  context.Context
+			mode openaicompat.OutputMode
+		}{
+			{context.Background(), openaicompat.OutputSchema},
+			{withSchema(`{"type":"object"}`), openaicompat.OutputText},
+			{withSchema(`{"type":"array","items":{"type":"string"}}`), openaicompat.OutputSchema},
+		}
+		for _, c := range cases {
+			if format := openaicompat.ResponseFormat(c.ctx, c.mode); format != nil {
+				t.Errorf("expected no format, got %+v", format)
+			}
+		}
+	})
+}
@@ -0,0 +1,283 @@
+// Copyright (c) 2025 Suricata Contributors
+// Original Author: Stefano Scafiti
+//
+// This file is part of Suricata: Type-Safe AI Agents for Go.
+//
+// Licensed under the MIT License. You may obtain a copy of the License at
+//
+//	https://opensource.org/licenses/MIT
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+// Package openaicompat provides an invoker for any endpoint implementing the
+// OpenAI chat completions API (Groq, Mistral, Together, OpenRouter, vLLM, ...).
+package openaicompat
+
+import (
+	"context"
+	"errors"
+	"io"
+	"math"
+	"strings"
+
+	openai "github.com/sashabaranov/go-openai"
+
+	"github.com/ostafen/suricata/runtime"
+)
+
+// Base URLs of well-known OpenAI-compatible providers.
+const (
+	GroqBaseURL       = "https://api.groq.com/openai/v1"
+	MistralBaseURL    = "https://api.mistral.ai/v1"
+	TogetherBaseURL   = "https://api.together.xyz/v1"
+	OpenRouterBaseURL = "https://openrouter.ai/api/v1"
+	VLLMBaseURL       = "http://localhost:8000/v1"
+)
+
+type OpenAICompatInvoker struct {
+	client *openai.Client
+	model  string
+
+	// OutputMode selects how output schemas are passed to the model. It defaults
+	// to OutputText, as support for response formats varies across providers.
+	OutputMode OutputMode
+}
+
+// NewInvoker creates an invoker talking to the OpenAI-compatible endpoint at baseURL.
+// The apiKey may be empty for self-hosted servers which do not require authentication.
+func NewInvoker(baseURL, apiKey, model string) *OpenAICompatInvoker {
+	cfg := openai.DefaultConfig(apiKey)
+	cfg.BaseURL = baseURL
+	cfg.HTTPClient = runtime.DefaultHTTPClient
+
+	return NewInvokerWithConfig(cfg, model)
+}
+
+// NewInvokerWithConfig returns an invoker using the given client configuration,
+// which allows for setting the HTTP client of the invoker.
+func NewInvokerWithConfig(cfg openai.ClientConfig, model string) *OpenAICompatInvoker {
+	return &OpenAICompatInvoker{
+		client: openai.NewClientWithConfig(cfg),
+		model:  model,
+	}
+}
+
+func (o *OpenAICompatInvoker) Invoke(ctx context.Context, systemPrompt string, messages []runtime.Message) (string, error) {
+	resp, err := o.client.CreateChatCompletion(ctx, o.chatRequest(ctx, systemPrompt, messages))
+	if err != nil {
+		return "", wrapError(err)
+	}
+
+	if len(resp.Choices) == 0 {
+		return "", errors.New("no response from OpenAI-compatible endpoint")
+	}
+	return resp.Choices[0].Message.Content, nil
+}
+
+// InvokeStream implements runtime.StreamingInvoker on top of the streaming chat completions API.
+func (o *OpenAICompatInvoker) InvokeStream(ctx context.Context, systemPrompt string, messages []runtime.Message, onDelta func(delta string)) (string, error) {
+	out, err := StreamChatCompletion(ctx, o.client, o.chatRequest(ctx, systemPrompt, messages), onDelta)
+	if err != nil {
+		return "", wrapError(err)
+	}
+	return out, nil
+}
+
+// StreamChatCompletion sends req through the streaming chat completions API, calling onDelta
+// with each chunk of the response content, and returns the whole content.
+func StreamChatCompletion(ctx context.Context, client *openai.Client, req openai.ChatCompletionRequest, onDelta func(delta string)) (string, error) {
+	req.Stream = true
+
+	stream, err := client.CreateChatCompletionStream(ctx, req)
+	if err != nil {
+		return "", err
+	}
+	defer stream.Close()
+
+	var out strings.Builder
+	for {
+		resp, err := stream.Recv()
+		if errors.Is(err, io.EOF) {
+			return out.String(), nil
+		}
+		if err != nil {
+			return "", err
+		}
+
+		if len(resp.Choices) == 0 || resp.Choices[0].Delta.Content == "" {
+			continue
+		}
+
+		delta := resp.Choices[0].Delta.Content
+		out.WriteString(delta)
+		onDelta(delta)
+	}
+}
+
+func (o *OpenAICompatInvoker) chatRequest(ctx context.Context, systemPrompt string, messages []runtime.Message) openai.ChatCompletionRequest {
+	opts := runtime.ModelOptionsFromContext(ctx)
+
+	chatReq := openai.ChatCompletionRequest{
+		Model:          opts.ModelOr(o.model),
+		Messages:       ChatMessages(systemPrompt, messages),
+		MaxTokens:      opts.MaxTokens,
+		ResponseFormat: ResponseFormat(ctx, o.OutputMode),
+	}
+	if opts.Temperature != nil {
+		chatReq.Temperature = Temperature(*opts.Temperature)
+	}
+	chatReq.Seed = opts.Seed
+	return chatReq
+}
+
+// ListModels implements runtime.HealthChecker, returning the models available through the API.
+func (o *OpenAICompatInvoker) ListModels(ctx context.Context) ([]string, error) {
+	list, err := o.client.ListModels(ctx)
+	if err != nil {
+		return nil, wrapError(err)
+	}
+
+	models := make([]string, len(list.Models))
+	for i, m := range list.Models {
+		models[i] = m.ID
+	}
+	return models, nil
+}
+
+// Ping implements runtime.HealthChecker.
+func (o *OpenAICompatInvoker) Ping(ctx context.Context) error {
+	models, err := o.ListModels(ctx)
+	if err != nil {
+		return err
+	}
+	return runtime.CheckModel(o.model, models)
+}
+
+// CountTokens implements runtime.Tokenizer. The tokens of OpenAI models, which
+// are also served through this API, are estimated with their encodings.
+func (o *OpenAICompatInvoker) CountTokens(text string) int {
+	if isOpenAIModel(o.model) {
+		return runtime.TiktokenTokenizer.CountTokens(text)
+	}
+	return runtime.HeuristicTokenizer.CountTokens(text)
+}
+
+func isOpenAIModel(model string) bool {
+	for _, prefix := range []string{"gpt-", "chatgpt-", "o1", "o3", "o4"} {
+		if strings.HasPrefix(model, prefix) {
+			return true
+		}
+	}
+	return false
+}
+
+// wrapError turns the error responses of the API into a *runtime.ProviderError.
+func wrapError(err error) error {
+	var apiErr *openai.APIError
+	if errors.As(err, &apiErr) && apiErr.HTTPStatusCode != 0 {
+		return &runtime.ProviderError{Provider: "openai-compatible endpoint", StatusCode: apiErr.HTTPStatusCode, Err: err}
+	}
+
+	var reqErr *openai.RequestError
+	if errors.As(err, &reqErr) && reqErr.HTTPStatusCode != 0 {
+		return &runtime.ProviderError{Provider: "openai-compatible endpoint", StatusCode: reqErr.HTTPStatusCode, Err: err}
+	}
+	return err
+}
+
+// Temperature converts t to the temperature of a chat completion request. The request omits
+// zero temperatures, which would select the default one of the provider instead: they are
+// sent as the smallest positive temperature.
+func Temperature(t float64) float32 {
+	if t == 0 {
+		return math.SmallestNonzeroFloat32
+	}
+	return float32(t)
+}
+
+// ChatMessages converts the system prompt and messages to chat completion messages.
+// Tool messages become native tool messages, and the calls they answer are attached
+// to the preceding assistant message. Tool messages not following an assistant
+// message, or lacking a call reference, are sent as user messages. Image attachments
+// become image content parts.
+func ChatMessages(systemPrompt string, messages []runtime.Message) []openai.ChatCompletionMessage {
+	out := make([]openai.ChatCompletionMessage, 0, len(messages)+1)
+	if systemPrompt != "" {
+		out = append(out, openai.ChatCompletionMessage{
+			Role:    openai.ChatMessageRoleSystem,
+			Content: systemPrompt,
+		})
+	}
+
+	// Index of the assistant message answered by the tool messages which follow it
+	assistant := -1
+	for _, m := range messages {
+		if m.Role == runtime.RoleTool && m.ToolCall != nil && assistant >= 0 {
+			args := string(m.ToolCall.Args)
+			if args == "" {
+				args = "{}"
+			}
+
+			out[assistant].ToolCalls = append(out[assistant].ToolCalls, openai.ToolCall{
+				ID:   m.ToolCall.ID,
+				Type: openai.ToolTypeFunction,
+				Function: openai.FunctionCall{
+					Name:      m.ToolCall.Name,
+					Arguments: args,
+				},
+			})
+			out = append(out, openai.ChatCompletionMessage{
+				Role:       openai.ChatMessageRoleTool,
+				Content:    m.Content,
+				ToolCallID: m.ToolCall.ID,
+			})
+			continue
+		}
+
+		out = append(out, chatMessage(m))
+
+		assistant = -1
+		if m.Role == runtime.RoleAgent {
+			assistant = len(out) - 1
+		}
+	}
+	return out
+}
+
+// chatMessage converts m to a chat completion message. Images are sent as
+// content parts, which vision models accept in user messages.
+func chatMessage(m runtime.Message) openai.ChatCompletionMessage {
+	msg := openai.ChatCompletionMessage{Role: chatRole(m.Role)}
+	if len(m.Attachments) == 0 {
+		msg.Content = m.Text()
+		return msg
+	}
+
+	msg.MultiContent = []openai.ChatMessagePart{{Type: openai.ChatMessagePartTypeText, Text: m.Text()}}
+	for _, att := range m.Attachments {
+		if att.IsImage() {
+			msg.MultiContent = append(msg.MultiContent, openai.ChatMessagePart{
+				Type:     openai.ChatMessagePartTypeImageURL,
+				ImageURL: &openai.ChatMessageImageURL{URL: att.DataURL()},
+			})
+		}
+	}
+	return msg
+}
+
+// chatRole returns the chat completion role of messages with the given role. Tool messages
+// which cannot be matched to a call are sent as user messages, as the tool role requires
+// the ID of the call answered.
+func chatRole(role runtime.Role) string {
+	switch role {
+	case runtime.RoleSystem:
+		return openai.ChatMessageRoleSystem
+	case runtime.RoleAgent:
+		return openai.ChatMessageRoleAssistant
+	}
+	return openai.ChatMessageRoleUser
+}
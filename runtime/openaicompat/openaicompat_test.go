@@ -0,0 +1,143 @@
+// Copyright (c) 2025 Suricata Contributors
+// Original Author: Stefano Scafiti
+//
+// This file is part of Suricata: Type-Safe AI Agents for Go.
+//
+// Licensed under the MIT License. You may obtain a copy of the License at
+//
+//	https://opensource.org/licenses/MIT
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package openaicompat_test
+
+import (
+	"context"
+	"encoding/json"
+	"math"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	openai "github.com/sashabaranov/go-openai"
+
+	"github.com/ostafen/suricata/runtime"
+	"github.com/ostafen/suricata/runtime/openaicompat"
+)
+
+func TestChatMessages(t *testing.T) {
+	messages := []runtime.Message{
+		{Role: runtime.RoleUser, Content: "Weather in Rome and Milan?"},
+		{Role: runtime.RoleAgent, Content: `[{"name":"weather","args":{"city":"Rome"}},{"name":"weather","args":{"city":"Milan"}}]`},
+		{Role: runtime.RoleTool, Content: `"sunny"`, ToolCall: &runtime.ToolCallRef{ID: "call00001", Name: "weather", Args: json.RawMessage(`{"city":"Rome"}`)}},
+		{Role: runtime.RoleTool, Content: `"rainy"`, ToolCall: &runtime.ToolCallRef{ID: "call00002", Name: "weather", Args: json.RawMessage(`{"city":"Milan"}`)}},
+		{Role: runtime.RoleUser, Content: "Thanks"},
+		{Role: runtime.RoleTool, Content: `"orphan"`, ToolCall: &runtime.ToolCallRef{ID: "call00003", Name: "weather"}},
+	}
+
+	out := openaicompat.ChatMessages("Be brief.", messages)
+
+	roles := []string{
+		openai.ChatMessageRoleSystem,
+		openai.ChatMessageRoleUser,
+		openai.ChatMessageRoleAssistant,
+		openai.ChatMessageRoleTool,
+		openai.ChatMessageRoleTool,
+		openai.ChatMessageRoleUser,
+		openai.ChatMessageRoleUser,
+	}
+	if len(out) != len(roles) {
+		t.Fatalf("expected %d messages, got %d", len(roles), len(out))
+	}
+	for i, role := range roles {
+		if out[i].Role != role {
+			t.Errorf("message %d: expected role %s, got %s", i, role, out[i].Role)
+		}
+	}
+
+	calls := out[2].ToolCalls
+	if len(calls) != 2 || calls[0].ID != "call00001" || calls[1].Function.Arguments != `{"city":"Milan"}` {
+		t.Errorf("unexpected tool calls on the assistant message: %+v", calls)
+	}
+	if out[3].ToolCallID != "call00001" || out[3].Content != `"sunny"` {
+		t.Errorf("unexpected tool message: %+v", out[3])
+	}
+
+	// A tool message which does not follow an assistant message has no call to answer
+	if out[6].Content != `weather OUTPUT: "orphan"` {
+		t.Errorf("expected orphan tool output as text, got %q", out[6].Content)
+	}
+}
+
+func TestChatMessages_Roles(t *testing.T) {
+	out := openaicompat.ChatMessages("", []runtime.Message{
+		{Role: runtime.RoleSystem, Content: "Be brief."},
+		{Role: runtime.RoleUser, Content: "Weather in Rome?"},
+		{Role: runtime.RoleAgent, Content: `{"name":"weather","args":{"city":"Rome"}}`},
+		{Role: runtime.RoleTool, Content: `"sunny"`, ToolCall: &runtime.ToolCallRef{ID: "call00001", Name: "weather"}},
+		{Role: runtime.RoleTool, Content: `"rainy"`},
+	})
+
+	roles := []string{
+		openai.ChatMessageRoleSystem,
+		openai.ChatMessageRoleUser,
+		openai.ChatMessageRoleAssistant,
+		openai.ChatMessageRoleTool,
+		openai.ChatMessageRoleUser, // No call to answer
+	}
+	if len(out) != len(roles) {
+		t.Fatalf("expected %d messages, got %d", len(roles), len(out))
+	}
+	for i, role := range roles {
+		if out[i].Role != role {
+			t.Errorf("message %d: expected role %s, got %s", i, role, out[i].Role)
+		}
+	}
+}
+
+func TestChatMessages_Images(t *testing.T) {
+	image := runtime.Attachment{MIMEType: "image/png", Data: []byte{0x89, 'P', 'N', 'G'}}
+	out := openaicompat.ChatMessages("", []runtime.Message{
+		{Role: runtime.RoleUser, Content: "Describe [image 1]", Attachments: []runtime.Attachment{image}},
+	})
+
+	if len(out) != 1 || out[0].Content != "" || len(out[0].MultiContent) != 2 {
+		t.Fatalf("expected a message with text and image parts, got %+v", out)
+	}
+	if part := out[0].MultiContent[0]; part.Type != openai.ChatMessagePartTypeText || part.Text != "Describe [image 1]" {
+		t.Errorf("unexpected text part: %+v", part)
+	}
+	if part := out[0].MultiContent[1]; part.ImageURL == nil || part.ImageURL.URL != "data:image/png;base64,iVBORw==" {
+		t.Errorf("unexpected image part: %+v", part)
+	}
+}
+
+func TestInvoke_Deterministic(t *testing.T) {
+	var req openai.ChatCompletionRequest
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+			t.Errorf("failed to decode request: %v", err)
+		}
+		w.Header().Set("Content-Type", "application/json")
+		w.Write([]byte(`{"choices":[{"message":{"role":"assistant","content":"Rome"}}]}`))
+	}))
+	defer srv.Close()
+
+	inv := openaicompat.NewInvoker(srv.URL, "key", "model")
+	msgs := []runtime.Message{{Role: runtime.RoleUser, Content: "Capital of Italy?"}}
+
+	ctx := runtime.WithModelOptions(context.Background(), runtime.Deterministic(42))
+	if _, err := inv.Invoke(ctx, "", msgs); err != nil {
+		t.Fatal(err)
+	}
+	if req.Seed == nil || *req.Seed != 42 {
+		t.Errorf("expected seed 42, got %v", req.Seed)
+	}
+	if req.Temperature != math.SmallestNonzeroFloat32 {
+		t.Errorf("expected the zero temperature to be sent, got %v", req.Temperature)
+	}
+}
@@ -0,0 +1,175 @@
+// Copyright (c) 2025 Suricata Contributors
+// Original Author: Stefano Scafiti
+//
+// This file is part of Suricata: Type-Safe AI Agents for Go.
+//
+// Licensed under the MIT License. You may obtain a copy of the License at
+//
+//	https://opensource.org/licenses/MIT
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package runtime
+
+import (
+	"context"
+	"maps"
+
+	"github.com/xeipuuv/gojsonschema"
+)
+
+// ModelOptions overrides the invoker defaults for a single request.
+// Zero values leave the corresponding invoker setting untouched.
+type ModelOptions struct {
+	Model       string
+	Temperature *float64
+	MaxTokens   int
+	Seed        *int // Seed of the sampling of the model, for providers supporting it, such as Ollama and OpenAI
+}
+
+// Float64 returns a pointer to v, to help filling optional numeric options.
+func Float64(v float64) *float64 {
+	return &v
+}
+
+// Int returns a pointer to v, to help filling optional numeric options.
+func Int(v int) *int {
+	return &v
+}
+
+// Deterministic returns the options pinning the temperature to zero and the seed of the
+// sampling to seed, so that models reply the same way to the same prompts, as far as
+// their provider allows it. Set on requests, or attached to a context with WithModelOptions
+// for the requests which do not set their own, they make tests with local models reproducible.
+func Deterministic(seed int) ModelOptions {
+	return ModelOptions{Temperature: Float64(0), Seed: &seed}
+}
+
+type (
+	modelOptionsKey       struct{}
+	callerModelOptionsKey struct{}
+)
+
+// WithModelOptions returns a context carrying opts. Invokers read them back
+// through ModelOptionsFromContext and apply them on top of their defaults.
+func WithModelOptions(ctx context.Context, opts ModelOptions) context.Context {
+	return context.WithValue(ctx, modelOptionsKey{}, opts)
+}
+
+// withCallerModelOptions records the options of ctx before a run merges the ones of its
+// request and runtime in, so that tools are called with the options of the caller only.
+// Otherwise, an agent called by a tool would take the model of the calling agent as an
+// override of its own defaults.
+func withCallerModelOptions(ctx context.Context) context.Context {
+	return context.WithValue(ctx, callerModelOptionsKey{}, ModelOptionsFromContext(ctx))
+}
+
+// callerModelOptions restores the options recorded by withCallerModelOptions.
+func callerModelOptions(ctx context.Context) context.Context {
+	opts, _ := ctx.Value(callerModelOptionsKey{}).(ModelOptions)
+	return WithModelOptions(ctx, opts)
+}
+
+// ModelOptionsFromContext returns the options attached to ctx, if any.
+func ModelOptionsFromContext(ctx context.Context) ModelOptions {
+	opts, _ := ctx.Value(modelOptionsKey{}).(ModelOptions)
+	return opts
+}
+
+// ModelOr returns the overridden model name, or def if no override is set.
+func (opts ModelOptions) ModelOr(def string) string {
+	if opts.Model != "" {
+		return opts.Model
+	}
+	return def
+}
+
+// MaxTokensOr returns the overridden max tokens, or def if no override is set.
+func (opts ModelOptions) MaxTokensOr(def int) int {
+	if opts.MaxTokens > 0 {
+		return opts.MaxTokens
+	}
+	return def
+}
+
+// withDefaults returns opts, with the unset fields taken from defaults.
+func (opts ModelOptions) withDefaults(defaults ModelOptions) ModelOptions {
+	if opts.Model == "" {
+		opts.Model = defaults.Model
+	}
+	if opts.Temperature == nil {
+		opts.Temperature = defaults.Temperature
+	}
+	if opts.MaxTokens <= 0 {
+		opts.MaxTokens = defaults.MaxTokens
+	}
+	if opts.Seed == nil {
+		opts.Seed = defaults.Seed
+	}
+	return opts
+}
+
+// WithDefaultModelOptions applies opts to requests, for the options which are set
+// neither by the request nor by the context.
+func WithDefaultModelOptions(opts ModelOptions) Option {
+	return func(r *Runtime) {
+		r.modelOptions = opts
+	}
+}
+
+type toolSpecsKey struct{}
+
+// WithToolSpecs returns a context carrying the tools available to the model. Runtime.Invoke
+// sets it from Request.ToolSpecs, so that invokers supporting native tool calling can
+// declare the tools to their provider.
+func WithToolSpecs(ctx context.Context, specs []ToolSpec) context.Context {
+	return context.WithValue(ctx, toolSpecsKey{}, specs)
+}
+
+// ToolSpecsFromContext returns the tools attached to ctx, if any.
+func ToolSpecsFromContext(ctx context.Context) []ToolSpec {
+	specs, _ := ctx.Value(toolSpecsKey{}).([]ToolSpec)
+	return specs
+}
+
+type valuesKey struct{}
+
+// WithValues returns a context carrying request-scoped values, such as the locale of the
+// user. Prompt templates read them through the meta function (e.g. {{meta.UserLocale}}),
+// and tools through ValuesFromContext. Values set by nested calls are merged with the
+// outer ones, replacing those with the same key.
+func WithValues(ctx context.Context, values map[string]any) context.Context {
+	merged := maps.Clone(ValuesFromContext(ctx))
+	if merged == nil {
+		merged = make(map[string]any, len(values))
+	}
+	maps.Copy(merged, values)
+	return context.WithValue(ctx, valuesKey{}, merged)
+}
+
+// ValuesFromContext returns the values attached to ctx through WithValues, if any.
+// The returned map must not be modified.
+func ValuesFromContext(ctx context.Context) map[string]any {
+	values, _ := ctx.Value(valuesKey{}).(map[string]any)
+	return values
+}
+
+type outputSchemaKey struct{}
+
+// WithOutputSchema returns a context carrying the schema the response of the model must
+// conform to. Runtime.Invoke sets it from Request.OutputSchema, unless the request runs
+// an agent loop, whose responses are tool calls, so that invokers supporting structured
+// outputs can constrain the response on the provider side.
+func WithOutputSchema(ctx context.Context, schema gojsonschema.JSONLoader) context.Context {
+	return context.WithValue(ctx, outputSchemaKey{}, schema)
+}
+
+// OutputSchemaFromContext returns the output schema attached to ctx, if any.
+func OutputSchemaFromContext(ctx context.Context) gojsonschema.JSONLoader {
+	schema, _ := ctx.Value(outputSchemaKey{}).(gojsonschema.JSONLoader)
+	return schema
+}
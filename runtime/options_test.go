@@ -0,0 +1,167 @@
+// Copyright (c) 2025 Suricata Contributors
+// Original Author: Stefano Scafiti
+//
+// This file is part of Suricata: Type-Safe AI Agents for Go.
+//
+// Licensed under the MIT License. You may obtain a copy of the License at
+//
+//	https://opensource.org/licenses/MIT
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package runtime_test
+
+import (
+	"context"
+	"encoding/json"
+	"strings"
+	"testing"
+
+	"github.com/ostafen/suricata/runtime"
+)
+
+func TestRuntimeOptions(t *testing.T) {
+	var (
+		models  []string
+		history []int
+		wrapped int
+	)
+	inv := runtime.InvokerFunc(func(ctx context.Context, system string, messages []runtime.Message) (string, error) {
+		models = append(models, runtime.ModelOptionsFromContext(ctx).Model)
+		history = append(history, len(messages))
+		return "ok", nil
+	})
+
+	type sessionKey struct{}
+	sessions := map[string]runtime.Memory{}
+
+	rt := runtime.NewRuntime(inv,
+		runtime.WithDefaultModelOptions(runtime.ModelOptions{Model: "default"}),
+		runtime.WithMemory(func(ctx context.Context) runtime.Memory {
+			id, _ := ctx.Value(sessionKey{}).(string)
+			if sessions[id] == nil {
+				sessions[id] = runtime.NewInMemory()
+			}
+			return sessions[id]
+		}),
+		runtime.WithMiddleware(func(next runtime.Invoker) runtime.Invoker {
+			return runtime.InvokerFunc(func(ctx context.Context, system string, messages []runtime.Message) (string, error) {
+				wrapped++
+				return next.Invoke(ctx, system, messages)
+			})
+		}),
+	)
+
+	invoke := func(session string, opts runtime.ModelOptions) {
+		var out string
+		ctx := context.WithValue(context.Background(), sessionKey{}, session)
+		if err := rt.Invoke(ctx, runtime.Request{PromptTemplate: "Hi", Input: &struct{}{}, InputSchema: runtime.NewSchema(`{"type":"object"}`), Output: &out, ModelOptions: opts}); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	invoke("alice", runtime.ModelOptions{})
+	invoke("alice", runtime.ModelOptions{Model: "large"})
+	invoke("bob", runtime.ModelOptions{})
+
+	if models[0] != "default" || models[1] != "large" {
+		t.Errorf("expected the default model unless overridden, got %v", models)
+	}
+	if history[0] != 1 || history[1] != 3 || history[2] != 1 {
+		t.Errorf("expected the conversation of each session to be kept, got %v messages", history)
+	}
+	if wrapped != 3 {
+		t.Errorf("expected 3 calls through the middleware, got %d", wrapped)
+	}
+}
+
+func TestRuntimeOptions_NestedAgent(t *testing.T) {
+	var inner runtime.ModelOptions
+	innerRt := runtime.NewRuntime(runtime.InvokerFunc(func(ctx context.Context, system string, messages []runtime.Message) (string, error) {
+		inner = runtime.ModelOptionsFromContext(ctx)
+		return "ok", nil
+	}), runtime.WithDefaultModelOptions(runtime.ModelOptions{Model: "small-local"}))
+
+	var outer []string
+	outerRt := runtime.NewRuntime(runtime.InvokerFunc(func(ctx context.Context, system string, messages []runtime.Message) (string, error) {
+		outer = append(outer, runtime.ModelOptionsFromContext(ctx).Model)
+		if len(outer) == 1 {
+			return `{"name":"summarize","args":{}}`, nil
+		}
+		return `{"done":true,"out":"done"}`, nil
+	}))
+
+	ctx := runtime.WithModelOptions(context.Background(), runtime.Deterministic(42))
+	err := outerRt.Invoke(ctx, runtime.Request{
+		PromptTemplate: "Hi",
+		Input:          &struct{}{},
+		InputSchema:    runtime.NewSchema(`{"type":"object"}`),
+		Output:         new(string),
+		ModelOptions:   runtime.ModelOptions{Model: "gpt-big", Temperature: runtime.Float64(0.7)},
+		ToolUnmarshaller: func(name string, data []byte) (any, error) {
+			var args map[string]any
+			return args, json.Unmarshal(data, &args)
+		},
+		ToolInvoker: func(ctx context.Context, name string, in any) (any, error) {
+			var out string
+			err := innerRt.Invoke(ctx, runtime.Request{PromptTemplate: "Summarize", Input: &struct{}{}, InputSchema: runtime.NewSchema(`{"type":"object"}`), Output: &out})
+			return out, err
+		},
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if len(outer) != 2 || outer[0] != "gpt-big" || outer[1] != "gpt-big" {
+		t.Errorf("expected the outer agent to use its request model, got %v", outer)
+	}
+	if inner.Model != "small-local" {
+		t.Errorf("expected the nested agent to use its default model, got %q", inner.Model)
+	}
+	if inner.Temperature == nil || *inner.Temperature != 0 || inner.Seed == nil || *inner.Seed != 42 {
+		t.Errorf("expected the nested agent to inherit the options of the context, got %+v", inner)
+	}
+}
+
+func TestWithValues(t *testing.T) {
+	var prompt string
+	inv := runtime.InvokerFunc(func(ctx context.Context, system string, messages []runtime.Message) (string, error) {
+		prompt = messages[len(messages)-1].Content
+		return "ok", nil
+	})
+
+	ctx := runtime.WithValues(context.Background(), map[string]any{"UserLocale": "en-US", "Plan": "free"})
+	ctx = runtime.WithValues(ctx, map[string]any{"UserLocale": "it-IT"})
+
+	values := runtime.ValuesFromContext(ctx)
+	if values["UserLocale"] != "it-IT" || values["Plan"] != "free" {
+		t.Errorf("expected nested values to be merged, got %v", values)
+	}
+
+	var out string
+	rt := runtime.NewRuntime(inv)
+	req := runtime.Request{
+		PromptTemplate: "Reply in {{meta.UserLocale}}",
+		Input:          &struct{}{},
+		InputSchema:    runtime.NewSchema(`{"type":"object"}`),
+		Output:         &out,
+	}
+	if err := rt.Invoke(ctx, req); err != nil {
+		t.Fatal(err)
+	}
+	if !strings.Contains(prompt, "Reply in it-IT") {
+		t.Errorf("expected the locale in the prompt, got %q", prompt)
+	}
+
+	rendered, err := runtime.RenderPrompt(req)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !strings.Contains(rendered, "Reply in <no value>") {
+		t.Errorf("expected no values outside of a call, got %q", rendered)
+	}
+}
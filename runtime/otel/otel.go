@@ -0,0 +1,96 @@
+// Copyright (c) 2025 Suricata Contributors
+// Original Author: Stefano Scafiti
+//
+// This file is part of Suricata: Type-Safe AI Agents for Go.
+//
+// Licensed under the MIT License. You may obtain a copy of the License at
+//
+//	https://opensource.org/licenses/MIT
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+// Package otel traces suricata agent executions with OpenTelemetry.
+//
+//	rt := runtime.NewRuntime(invoker, otel.WithTracing(tracerProvider))
+package otel
+
+import (
+	"context"
+	"fmt"
+
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/codes"
+	"go.opentelemetry.io/otel/trace"
+
+	"github.com/ostafen/suricata/runtime"
+)
+
+const InstrumentationName = "github.com/ostafen/suricata/runtime"
+
+// Tracer adapts an OpenTelemetry tracer to the runtime.Tracer interface.
+type Tracer struct {
+	tracer trace.Tracer
+}
+
+func NewTracer(tp trace.TracerProvider) *Tracer {
+	return &Tracer{
+		tracer: tp.Tracer(InstrumentationName),
+	}
+}
+
+// WithTracing returns a runtime option emitting spans to the given provider.
+func WithTracing(tp trace.TracerProvider) runtime.Option {
+	return runtime.WithTracer(NewTracer(tp))
+}
+
+func (t *Tracer) Start(ctx context.Context, name string, attrs ...runtime.Attr) (context.Context, runtime.Span) {
+	ctx, span := t.tracer.Start(ctx, name, trace.WithAttributes(toAttributes(attrs)...))
+	return ctx, &Span{span: span}
+}
+
+type Span struct {
+	span trace.Span
+}
+
+func (s *Span) SetAttributes(attrs ...runtime.Attr) {
+	s.span.SetAttributes(toAttributes(attrs)...)
+}
+
+func (s *Span) End(err error) {
+	if err != nil {
+		s.span.RecordError(err)
+		s.span.SetStatus(codes.Error, err.Error())
+	}
+	s.span.End()
+}
+
+func toAttributes(attrs []runtime.Attr) []attribute.KeyValue {
+	out := make([]attribute.KeyValue, 0, len(attrs))
+	for _, a := range attrs {
+		out = append(out, toAttribute(a))
+	}
+	return out
+}
+
+func toAttribute(a runtime.Attr) attribute.KeyValue {
+	switch v := a.Value.(type) {
+	case string:
+		return attribute.String(a.Key, v)
+	case int:
+		return attribute.Int(a.Key, v)
+	case int64:
+		return attribute.Int64(a.Key, v)
+	case float64:
+		return attribute.Float64(a.Key, v)
+	case bool:
+		return attribute.Bool(a.Key, v)
+	case []string:
+		return attribute.StringSlice(a.Key, v)
+	default:
+		return attribute.String(a.Key, fmt.Sprint(v))
+	}
+}
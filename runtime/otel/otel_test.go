@@ -0,0 +1,184 @@
+// Copyright (c) 2025 Suricata Contributors
+// Original Author: Stefano Scafiti
+//
+// This file is part of Suricata: Type-Safe AI Agents for Go.
+//
+// Licensed under the MIT License. You may obtain a copy of the License at
+//
+//	https://opensource.org/licenses/MIT
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package otel_test
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"sync"
+	"testing"
+
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/codes"
+	"go.opentelemetry.io/otel/trace"
+	"go.opentelemetry.io/otel/trace/noop"
+
+	"github.com/ostafen/suricata/runtime"
+	"github.com/ostafen/suricata/runtime/otel"
+	"github.com/ostafen/suricata/runtime/runtimetest"
+)
+
+// recorder is a trace.TracerProvider keeping the ended spans in memory.
+type recorder struct {
+	noop.TracerProvider
+
+	mtx   sync.Mutex
+	ended []*span
+}
+
+func (r *recorder) Tracer(string, ...trace.TracerOption) trace.Tracer {
+	return &tracer{recorder: r}
+}
+
+// byName returns the ended spans with the given name.
+func (r *recorder) byName(name string) []*span {
+	r.mtx.Lock()
+	defer r.mtx.Unlock()
+
+	var spans []*span
+	for _, s := range r.ended {
+		if s.name == name {
+			spans = append(spans, s)
+		}
+	}
+	return spans
+}
+
+type tracer struct {
+	noop.Tracer
+	recorder *recorder
+}
+
+func (t *tracer) Start(ctx context.Context, name string, opts ...trace.SpanStartOption) (context.Context, trace.Span) {
+	s := &span{recorder: t.recorder, name: name, attrs: make(map[attribute.Key]attribute.Value)}
+	cfg := trace.NewSpanStartConfig(opts...)
+	s.SetAttributes(cfg.Attributes()...)
+	return trace.ContextWithSpan(ctx, s), s
+}
+
+type span struct {
+	noop.Span
+	recorder *recorder
+
+	name   string
+	attrs  map[attribute.Key]attribute.Value
+	status codes.Code
+	errs   []error
+}
+
+func (s *span) SetAttributes(kv ...attribute.KeyValue) {
+	for _, a := range kv {
+		s.attrs[a.Key] = a.Value
+	}
+}
+
+func (s *span) RecordError(err error, _ ...trace.EventOption) { s.errs = append(s.errs, err) }
+
+func (s *span) SetStatus(code codes.Code, _ string) { s.status = code }
+
+func (s *span) End(...trace.SpanEndOption) {
+	s.recorder.mtx.Lock()
+	defer s.recorder.mtx.Unlock()
+	s.recorder.ended = append(s.recorder.ended, s)
+}
+
+func toolRequest() runtime.Request {
+	return runtime.Request{
+		PromptTemplate: "Weather in Rome?",
+		Input:          map[string]any{},
+		InputSchema:    runtime.NewSchema(`{"type":"object"}`),
+		Output:         new(string),
+		ToolSpecs:      []runtime.ToolSpec{{Name: "weather", Schema: runtime.NewSchema(`{"type":"object"}`)}},
+		ToolUnmarshaller: func(name string, data []byte) (any, error) {
+			var args map[string]string
+			return args, json.Unmarshal(data, &args)
+		},
+		ToolInvoker: func(ctx context.Context, name string, in any) (any, error) {
+			return "sunny", nil
+		},
+	}
+}
+
+func TestWithTracing(t *testing.T) {
+	mock := runtimetest.NewInvoker(t).Respond(
+		`{"name":"weather","args":{"city":"Rome"}}`,
+		`{"done":true,"out":"Sunny"}`,
+	)
+
+	rec := &recorder{}
+	rt := runtime.NewRuntime(mock, otel.WithTracing(rec))
+
+	req := toolRequest()
+	req.PromptVersion = "v2"
+	ctx := runtime.WithModelOptions(context.Background(), runtime.ModelOptions{Model: "small"})
+	if err := rt.Invoke(ctx, req); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	invoke := rec.byName(runtime.SpanInvoke)
+	if len(invoke) != 1 {
+		t.Fatalf("expected 1 invoke span, got %d", len(invoke))
+	}
+	if attrs := invoke[0].attrs; attrs[runtime.AttrModel].AsString() != "small" || attrs[runtime.AttrPromptVersion].AsString() != "v2" {
+		t.Errorf("unexpected invoke attributes: %v", attrs)
+	}
+
+	calls := rec.byName(runtime.SpanLLMCall)
+	if len(calls) != 2 {
+		t.Fatalf("expected 2 LLM call spans, got %d", len(calls))
+	}
+	for _, call := range calls {
+		if call.attrs[runtime.AttrModel].AsString() != "small" || call.attrs[runtime.AttrCompletionTokens].AsInt64() <= 0 {
+			t.Errorf("unexpected LLM call attributes: %v", call.attrs)
+		}
+	}
+
+	tools := rec.byName(runtime.SpanToolCall)
+	if len(tools) != 1 || tools[0].attrs[runtime.AttrToolName].AsString() != "weather" {
+		t.Errorf("expected a weather tool call span, got %+v", tools)
+	}
+
+	loops := rec.byName(runtime.SpanAgentLoop)
+	if len(loops) != 1 || loops[0].attrs[runtime.AttrToolIterations].AsInt64() != 1 {
+		t.Errorf("expected an agent loop span with 1 iteration, got %+v", loops)
+	}
+
+	for _, s := range rec.ended {
+		if s.status != codes.Unset || len(s.errs) > 0 {
+			t.Errorf("span %s: unexpected error status", s.name)
+		}
+	}
+}
+
+func TestWithTracing_Error(t *testing.T) {
+	failure := errors.New("connection reset")
+	mock := runtimetest.NewInvoker(t)
+	mock.Expect().ReturnError(failure)
+
+	rec := &recorder{}
+	rt := runtime.NewRuntime(mock, otel.WithTracing(rec))
+	if err := rt.Invoke(context.Background(), toolRequest()); !errors.Is(err, failure) {
+		t.Fatalf("expected %v, got %v", failure, err)
+	}
+
+	for _, name := range []string{runtime.SpanInvoke, runtime.SpanLLMCall} {
+		spans := rec.byName(name)
+		if len(spans) != 1 || spans[0].status != codes.Error || len(spans[0].errs) != 1 {
+			t.Errorf("expected an errored %s span, got %+v", name, spans)
+		}
+	}
+}
@@ -0,0 +1,51 @@
+// Copyright (c) 2025 Suricata Contributors
+// Original Author: Stefano Scafiti
+//
+// This file is part of Suricata: Type-Safe AI Agents for Go.
+//
+// Licensed under the MIT License. You may obtain a copy of the License at
+//
+//	https://opensource.org/licenses/MIT
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package runtime
+
+// overrides replace the instructions and prompts compiled into generated agents.
+type overrides struct {
+	instructions *string
+	prompts      map[string]string // Prompt templates, by action
+}
+
+// WithInstructionOverride replaces the instructions of every request with instructions,
+// so that the system prompt of an agent can be changed without regenerating it.
+func WithInstructionOverride(instructions string) Option {
+	return func(r *Runtime) {
+		r.overrides.instructions = &instructions
+	}
+}
+
+// WithPromptOverride replaces the prompt template of the requests of the given action,
+// identified by Request.Action, so that prompts can be patched at deploy time, e.g.
+// from a configuration file, without regenerating the agent.
+func WithPromptOverride(action, tmpl string) Option {
+	return func(r *Runtime) {
+		if r.overrides.prompts == nil {
+			r.overrides.prompts = make(map[string]string)
+		}
+		r.overrides.prompts[action] = tmpl
+	}
+}
+
+func (o *overrides) apply(req *Request) {
+	if o.instructions != nil {
+		req.Instructions = *o.instructions
+	}
+	if tmpl, ok := o.prompts[req.Action]; ok && req.Action != "" {
+		req.PromptTemplate = tmpl
+	}
+}
@@ -0,0 +1,52 @@
+// Copyright (c) 2025 Suricata Contributors
+// Original Author: Stefano Scafiti
+//
+// This file is part of Suricata: Type-Safe AI Agents for Go.
+//
+// Licensed under the MIT License. You may obtain a copy of the License at
+//
+//	https://opensource.org/licenses/MIT
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package runtime_test
+
+import (
+	"context"
+	"testing"
+
+	"github.com/ostafen/suricata/runtime"
+	"github.com/ostafen/suricata/runtime/runtimetest"
+	"github.com/xeipuuv/gojsonschema"
+)
+
+func TestPromptOverrides(t *testing.T) {
+	mock := runtimetest.NewInvoker(t)
+	mock.Expect().SystemContains("Answer in French.").PromptContains("Salute Ada").Respond("Bonjour")
+	mock.Expect().SystemContains("Answer in French.").PromptContains("Describe Ada").Respond("Ada est...")
+
+	rt := runtime.NewRuntime(mock,
+		runtime.WithInstructionOverride("Answer in French."),
+		runtime.WithPromptOverride("greet", "Salute {{.Name}}"),
+	)
+
+	for _, action := range []string{"greet", "describe"} {
+		var out string
+		err := rt.Invoke(context.Background(), runtime.Request{
+			Action:         action,
+			Instructions:   "Answer in English.",
+			PromptTemplate: "Describe {{.Name}}",
+			Input:          map[string]any{"Name": "Ada"},
+			Output:         &out,
+			InputSchema:    gojsonschema.NewStringLoader(`{"type":"object"}`),
+		})
+		if err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+	}
+	mock.AssertExpectations()
+}
@@ -0,0 +1,249 @@
+// Copyright (c) 2025 Suricata Contributors
+// Original Author: Stefano Scafiti
+//
+// This file is part of Suricata: Type-Safe AI Agents for Go.
+//
+// Licensed under the MIT License. You may obtain a copy of the License at
+//
+//	https://opensource.org/licenses/MIT
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package runtime
+
+import (
+	"encoding/json"
+	"reflect"
+	"strings"
+)
+
+// CompletePartialJSON returns the JSON encoding of the values completed so far in
+// prefix, the beginning of a JSON object being generated. Members whose value is
+// still incomplete are left out, except objects and arrays, which keep their
+// completed members. Text preceding the object is ignored. It returns an empty
+// string if the object has not started yet.
+func CompletePartialJSON(prefix string) string {
+	start := strings.IndexByte(prefix, '{')
+	if start < 0 {
+		return ""
+	}
+
+	p := partialParser{s: prefix, i: start}
+	v, _ := p.value()
+	if v == nil {
+		return ""
+	}
+
+	data, err := json.Marshal(v)
+	if err != nil {
+		return ""
+	}
+	return string(data)
+}
+
+// partialParser parses a possibly truncated JSON document. Each method reports
+// whether the value it parsed was complete.
+type partialParser struct {
+	s string
+	i int
+}
+
+func (p *partialParser) skipSpaces() {
+	for p.i < len(p.s) && strings.IndexByte(" \t\r\n", p.s[p.i]) >= 0 {
+		p.i++
+	}
+}
+
+func (p *partialParser) value() (any, bool) {
+	p.skipSpaces()
+	if p.i >= len(p.s) {
+		return nil, false
+	}
+
+	switch c := p.s[p.i]; {
+	case c == '{':
+		return p.object()
+	case c == '[':
+		return p.array()
+	case c == '"':
+		return p.string()
+	default:
+		return p.literal()
+	}
+}
+
+func (p *partialParser) object() (any, bool) {
+	obj := make(map[string]any)
+	p.i++ // '{'
+
+	for {
+		p.skipSpaces()
+		if p.i >= len(p.s) {
+			return obj, false
+		}
+
+		switch p.s[p.i] {
+		case '}':
+			p.i++
+			return obj, true
+		case ',':
+			p.i++
+			continue
+		}
+
+		key, ok := p.string()
+		if !ok {
+			return obj, false
+		}
+
+		p.skipSpaces()
+		if p.i >= len(p.s) || p.s[p.i] != ':' {
+			return obj, false
+		}
+		p.i++
+
+		v, complete := p.value()
+		if complete || isContainer(v) {
+			obj[key.(string)] = v
+		}
+		if !complete {
+			return obj, false
+		}
+	}
+}
+
+func (p *partialParser) array() (any, bool) {
+	arr := make([]any, 0)
+	p.i++ // '['
+
+	for {
+		p.skipSpaces()
+		if p.i >= len(p.s) {
+			return arr, false
+		}
+
+		switch p.s[p.i] {
+		case ']':
+			p.i++
+			return arr, true
+		case ',':
+			p.i++
+			continue
+		}
+
+		v, complete := p.value()
+		if complete || isContainer(v) {
+			arr = append(arr, v)
+		}
+		if !complete {
+			return arr, false
+		}
+	}
+}
+
+func (p *partialParser) string() (any, bool) {
+	if p.i >= len(p.s) || p.s[p.i] != '"' {
+		return nil, false
+	}
+
+	for j := p.i + 1; j < len(p.s); j++ {
+		switch p.s[j] {
+		case '\\':
+			j++
+		case '"':
+			var s string
+			if err := json.Unmarshal([]byte(p.s[p.i:j+1]), &s); err != nil {
+				return nil, false
+			}
+			p.i = j + 1
+			return s, true
+		}
+	}
+	p.i = len(p.s)
+	return nil, false
+}
+
+// literal parses numbers, booleans and null. A literal is complete only once
+// it is followed by another character, as more digits may still come.
+func (p *partialParser) literal() (any, bool) {
+	j := p.i
+	for j < len(p.s) && strings.IndexByte(",}] \t\r\n", p.s[j]) < 0 {
+		j++
+	}
+
+	token := p.s[p.i:j]
+	p.i = j
+	if j >= len(p.s) {
+		return nil, false
+	}
+
+	var v any
+	if err := json.Unmarshal([]byte(token), &v); err != nil {
+		return nil, false
+	}
+	return v, true
+}
+
+func isContainer(v any) bool {
+	switch v.(type) {
+	case map[string]any, []any:
+		return true
+	}
+	return false
+}
+
+// partialOutput decodes the output of req from the response being streamed, calling
+// req.OnPartialOutput each time a value is completed.
+type partialOutput struct {
+	req  *Request
+	buf  strings.Builder
+	last string
+}
+
+func (p *partialOutput) write(delta string) {
+	p.buf.WriteString(delta)
+
+	snapshot := CompletePartialJSON(p.buf.String())
+	if snapshot == "" || snapshot == p.last {
+		return
+	}
+	p.last = snapshot
+
+	data := []byte(snapshot)
+	if p.req.ToolInvoker != nil {
+		// Agent loops wrap the final output in the "out" member
+		var resp struct {
+			Out json.RawMessage `json:"out"`
+		}
+		if err := json.Unmarshal(data, &resp); err != nil || resp.Out == nil {
+			return
+		}
+		data = resp.Out
+	}
+
+	out := reflect.New(reflect.TypeOf(p.req.Output).Elem())
+	if err := json.Unmarshal(data, out.Interface()); err != nil {
+		return
+	}
+	p.req.OnPartialOutput(out.Interface())
+}
+
+// streamHandler returns the handler receiving the chunks of the next model response for req,
+// or nil if streaming is not enabled.
+func streamHandler(req *Request) func(delta string) {
+	if req.OnPartialOutput == nil || req.isTextOutput() {
+		return req.OnDelta
+	}
+
+	partial := &partialOutput{req: req}
+	return func(delta string) {
+		if req.OnDelta != nil {
+			req.OnDelta(delta)
+		}
+		partial.write(delta)
+	}
+}
@@ -0,0 +1,87 @@
+// Copyright (c) 2025 Suricata Contributors
+// Original Author: Stefano Scafiti
+//
+// This file is part of Suricata: Type-Safe AI Agents for Go.
+//
+// Licensed under the MIT License. You may obtain a copy of the License at
+//
+//	https://opensource.org/licenses/MIT
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package runtime_test
+
+import (
+	"context"
+	"testing"
+
+	"github.com/ostafen/suricata/runtime"
+	"github.com/xeipuuv/gojsonschema"
+)
+
+func TestCompletePartialJSON(t *testing.T) {
+	tests := []struct {
+		prefix string
+		want   string
+	}{
+		{``, ``},
+		{`Here is the itinerary`, ``},
+		{`{`, `{}`},
+		{`{"dest`, `{}`},
+		{`{"destination": "Ro`, `{}`},
+		{`{"destination": "Rome", "days": 1`, `{"destination":"Rome"}`},
+		{`{"destination": "Rome", "days": 12,`, `{"days":12,"destination":"Rome"}`},
+		{`{"ok": tr`, `{}`},
+		{`{"ok": true}`, `{"ok":true}`},
+		{`{"stops": ["Rome", "Flor`, `{"stops":["Rome"]}`},
+		{`{"dates": {"from": "2024-05-01", "to": "2024`, `{"dates":{"from":"2024-05-01"}}`},
+		{`{"note": "say \"hi`, `{}`},
+		{"```json\n{\"a\": \"b\", ", `{"a":"b"}`},
+	}
+
+	for _, tt := range tests {
+		if got := runtime.CompletePartialJSON(tt.prefix); got != tt.want {
+			t.Errorf("CompletePartialJSON(%q) = %q, want %q", tt.prefix, got, tt.want)
+		}
+	}
+}
+
+func TestOnPartialOutput(t *testing.T) {
+	type Itinerary struct {
+		Destination string   `json:"destination"`
+		Dates       []string `json:"dates"`
+	}
+
+	inv := &chunkInvoker{chunks: []string{`{"destination": "Ro`, `me", "dates": ["2024-05-01", `, `"2024-05-0`, `7"]}`}}
+	rt := runtime.NewRuntime(inv)
+
+	var partials []Itinerary
+	var out Itinerary
+	err := rt.Invoke(context.Background(), runtime.Request{
+		PromptTemplate: "Plan a trip",
+		Input:          map[string]any{},
+		Output:         &out,
+		InputSchema:    gojsonschema.NewStringLoader(`{"type":"object"}`),
+		OutputSchema:   gojsonschema.NewStringLoader(`{"type":"object"}`),
+		OnPartialOutput: func(partial any) {
+			partials = append(partials, *partial.(*Itinerary))
+		},
+	})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	if len(partials) != 3 {
+		t.Fatalf("expected 3 partial outputs, got %d: %+v", len(partials), partials)
+	}
+	if partials[1].Destination != "Rome" || len(partials[1].Dates) != 1 {
+		t.Errorf("unexpected partial output: %+v", partials[1])
+	}
+	if out.Destination != "Rome" || len(out.Dates) != 2 {
+		t.Errorf("unexpected output: %+v", out)
+	}
+}
@@ -0,0 +1,373 @@
+// Copyright (c) 2025 Suricata Contributors
+// Original Author: Stefano Scafiti
+//
+// This file is part of Suricata: Type-Safe AI Agents for Go.
+//
+// Licensed under the MIT License. You may obtain a copy of the License at
+//
+//	https://opensource.org/licenses/MIT
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+// Package pdf extracts the text of PDF documents, using the standard library only.
+//
+// It reads the text shown by the content streams of the document, either uncompressed
+// or Flate-compressed, decoding strings as Latin-1. This covers the documents produced
+// by most office suites and report generators, but not fonts with custom encodings
+// nor scanned documents: a dedicated library can be plugged in through a
+// runtime.TextExtractor for those.
+package pdf
+
+import (
+	"bytes"
+	"compress/zlib"
+	"errors"
+	"io"
+	"regexp"
+	"strconv"
+	"strings"
+)
+
+// ErrNotPDF is returned for documents lacking the PDF header.
+var ErrNotPDF = errors.New("pdf: not a PDF document")
+
+// streamRegexp matches the dictionary and the start of each stream object.
+var streamRegexp = regexp.MustCompile(`(?s)<<(.*?)>>\s*stream\r?\n`)
+
+// ExtractText returns the text of the PDF document read from r, one line per text line
+// of the document.
+func ExtractText(r io.Reader) (string, error) {
+	data, err := io.ReadAll(r)
+	if err != nil {
+		return "", err
+	}
+	if !bytes.HasPrefix(bytes.TrimLeft(data, "\x00\t\r\n "), []byte("%PDF-")) {
+		return "", ErrNotPDF
+	}
+
+	var sb strings.Builder
+	for _, loc := range streamRegexp.FindAllSubmatchIndex(data, -1) {
+		start := loc[1]
+		end := bytes.Index(data[start:], []byte("endstream"))
+		if end < 0 {
+			break
+		}
+
+		content, ok := decodeStream(data[loc[2]:loc[3]], data[start:start+end])
+		if ok {
+			extractContentText(content, &sb)
+		}
+	}
+	return strings.TrimSpace(sb.String()), nil
+}
+
+// decodeStream returns the decoded data of a stream whose dictionary is dict,
+// and whether it can be decoded.
+func decodeStream(dict, data []byte) ([]byte, bool) {
+	// The match may span from the dictionary of a previous object
+	if i := bytes.LastIndex(dict, []byte(" obj")); i >= 0 {
+		dict = dict[i:]
+	}
+	if bytes.Contains(dict, []byte("/Image")) {
+		return nil, false
+	}
+
+	filter := bytes.Contains(dict, []byte("/Filter"))
+	switch {
+	case !filter:
+		return data, true
+	case bytes.Contains(dict, []byte("/FlateDecode")) && !bytes.Contains(dict, []byte("/DecodeParms")):
+		zr, err := zlib.NewReader(bytes.NewReader(data))
+		if err != nil {
+			return nil, false
+		}
+		defer zr.Close()
+
+		// Streams are often followed by stray bytes: keep what could be inflated
+		out, _ := io.ReadAll(zr)
+		return out, len(out) > 0
+	}
+	return nil, false
+}
+
+// extractContentText writes the text shown by the operators of a content stream to sb.
+func extractContentText(content []byte, sb *strings.Builder) {
+	lex := lexer{data: content}
+
+	var (
+		inText   bool
+		operands []token
+	)
+	for {
+		tok, ok := lex.next()
+		if !ok {
+			return
+		}
+		if tok.kind != tokenOperator {
+			operands = append(operands, tok)
+			continue
+		}
+
+		switch tok.text {
+		case "BT":
+			inText = true
+		case "ET":
+			inText = false
+			newline(sb)
+		case "Tj":
+			if inText {
+				writeStrings(sb, operands)
+			}
+		case "'", `"`:
+			if inText {
+				newline(sb)
+				writeStrings(sb, operands[len(operands)-min(1, len(operands)):])
+			}
+		case "TJ":
+			if inText {
+				writeStrings(sb, operands)
+			}
+		case "T*":
+			if inText {
+				newline(sb)
+			}
+		case "Td", "TD":
+			if inText && len(operands) >= 2 {
+				if ty, _ := strconv.ParseFloat(operands[len(operands)-1].text, 64); ty != 0 {
+					newline(sb)
+				} else {
+					space(sb)
+				}
+			}
+		case "Tm":
+			if inText {
+				space(sb)
+			}
+		}
+		operands = operands[:0]
+	}
+}
+
+// writeStrings writes the strings among operands. In the arrays of TJ operators,
+// large negative offsets are rendered as spaces between words.
+func writeStrings(sb *strings.Builder, operands []token) {
+	for _, op := range operands {
+		switch op.kind {
+		case tokenString:
+			sb.WriteString(latin1(op.text))
+		case tokenNumber:
+			if n, _ := strconv.ParseFloat(op.text, 64); n < -200 {
+				space(sb)
+			}
+		case tokenArray:
+			writeStrings(sb, op.items)
+		}
+	}
+}
+
+func latin1(s string) string {
+	runes := make([]rune, 0, len(s))
+	for i := 0; i < len(s); i++ {
+		if c := s[i]; c >= 0x20 || c == '\t' {
+			runes = append(runes, rune(c))
+		}
+	}
+	return string(runes)
+}
+
+func newline(sb *strings.Builder) {
+	s := sb.String()
+	if len(s) > 0 && !strings.HasSuffix(s, "\n") {
+		sb.WriteByte('\n')
+	}
+}
+
+func space(sb *strings.Builder) {
+	s := sb.String()
+	if len(s) > 0 && !strings.HasSuffix(s, " ") && !strings.HasSuffix(s, "\n") {
+		sb.WriteByte(' ')
+	}
+}
+
+type tokenKind int
+
+const (
+	tokenOperator tokenKind = iota
+	tokenNumber
+	tokenString
+	tokenName
+	tokenArray
+	tokenOther
+)
+
+type token struct {
+	kind  tokenKind
+	text  string
+	items []token // Elements of arrays
+}
+
+// lexer splits a content stream into tokens.
+type lexer struct {
+	data []byte
+	pos  int
+}
+
+func isDelimiter(c byte) bool {
+	return strings.IndexByte("()<>[]{}/%", c) >= 0
+}
+
+func isSpace(c byte) bool {
+	return strings.IndexByte("\x00\t\n\f\r ", c) >= 0
+}
+
+func (l *lexer) next() (token, bool) {
+	for l.pos < len(l.data) {
+		c := l.data[l.pos]
+		switch {
+		case isSpace(c):
+			l.pos++
+		case c == '%':
+			for l.pos < len(l.data) && l.data[l.pos] != '\n' && l.data[l.pos] != '\r' {
+				l.pos++
+			}
+		case c == '(':
+			return token{kind: tokenString, text: l.literalString()}, true
+		case c == '<' && l.pos+1 < len(l.data) && l.data[l.pos+1] == '<':
+			l.pos += 2
+			return token{kind: tokenOther, text: "<<"}, true
+		case c == '>' && l.pos+1 < len(l.data) && l.data[l.pos+1] == '>':
+			l.pos += 2
+			return token{kind: tokenOther, text: ">>"}, true
+		case c == '<':
+			return token{kind: tokenString, text: l.hexString()}, true
+		case c == '[':
+			l.pos++
+			return l.array(), true
+		case c == ']':
+			l.pos++
+			return token{kind: tokenOther, text: "]"}, true
+		case c == '/':
+			l.pos++
+			return token{kind: tokenName, text: l.word()}, true
+		case isDelimiter(c):
+			l.pos++
+		default:
+			word := l.word()
+			if _, err := strconv.ParseFloat(word, 64); err == nil {
+				return token{kind: tokenNumber, text: word}, true
+			}
+			return token{kind: tokenOperator, text: word}, true
+		}
+	}
+	return token{}, false
+}
+
+func (l *lexer) word() string {
+	start := l.pos
+	for l.pos < len(l.data) && !isSpace(l.data[l.pos]) && !isDelimiter(l.data[l.pos]) {
+		l.pos++
+	}
+	return string(l.data[start:l.pos])
+}
+
+func (l *lexer) array() token {
+	arr := token{kind: tokenArray}
+	for {
+		tok, ok := l.next()
+		if !ok || (tok.kind == tokenOther && tok.text == "]") {
+			return arr
+		}
+		arr.items = append(arr.items, tok)
+	}
+}
+
+// literalString reads a string delimited by balanced parentheses, decoding escapes.
+func (l *lexer) literalString() string {
+	var sb strings.Builder
+	depth := 0
+	for l.pos < len(l.data) {
+		c := l.data[l.pos]
+		l.pos++
+
+		switch c {
+		case '(':
+			depth++
+			if depth == 1 {
+				continue
+			}
+		case ')':
+			depth--
+			if depth == 0 {
+				return sb.String()
+			}
+		case '\\':
+			if l.pos >= len(l.data) {
+				return sb.String()
+			}
+			c = l.data[l.pos]
+			l.pos++
+
+			switch c {
+			case 'n':
+				c = '\n'
+			case 'r':
+				c = '\r'
+			case 't':
+				c = '\t'
+			case 'b':
+				c = '\b'
+			case 'f':
+				c = '\f'
+			case '\r', '\n':
+				// Line continuation
+				if c == '\r' && l.pos < len(l.data) && l.data[l.pos] == '\n' {
+					l.pos++
+				}
+				continue
+			default:
+				if c >= '0' && c <= '7' {
+					n := int(c - '0')
+					for i := 0; i < 2 && l.pos < len(l.data) && l.data[l.pos] >= '0' && l.data[l.pos] <= '7'; i++ {
+						n = n*8 + int(l.data[l.pos]-'0')
+						l.pos++
+					}
+					c = byte(n)
+				}
+			}
+		}
+		sb.WriteByte(c)
+	}
+	return sb.String()
+}
+
+// hexString reads a string of hexadecimal digits delimited by angle brackets.
+func (l *lexer) hexString() string {
+	l.pos++ // '<'
+
+	var digits []byte
+	for l.pos < len(l.data) && l.data[l.pos] != '>' {
+		if c := l.data[l.pos]; !isSpace(c) {
+			digits = append(digits, c)
+		}
+		l.pos++
+	}
+	l.pos++ // '>'
+
+	if len(digits)%2 == 1 {
+		digits = append(digits, '0')
+	}
+
+	out := make([]byte, 0, len(digits)/2)
+	for i := 0; i < len(digits); i += 2 {
+		n, err := strconv.ParseUint(string(digits[i:i+2]), 16, 8)
+		if err != nil {
+			return string(out)
+		}
+		out = append(out, byte(n))
+	}
+	return string(out)
+}
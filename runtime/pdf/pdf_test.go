@@ -0,0 +1,81 @@
+// Copyright (c) 2025 Suricata Contributors
+// Original Author: Stefano Scafiti
+//
+// This file is part of Suricata: Type-Safe AI Agents for Go.
+//
+// Licensed under the MIT License. You may obtain a copy of the License at
+//
+//	https://opensource.org/licenses/MIT
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package pdf_test
+
+import (
+	"bytes"
+	"compress/zlib"
+	"errors"
+	"fmt"
+	"strings"
+	"testing"
+
+	"github.com/ostafen/suricata/runtime/pdf"
+)
+
+// buildPDF returns a minimal PDF document with a page showing content.
+func buildPDF(content []byte, filter string) []byte {
+	var buf bytes.Buffer
+	buf.WriteString("%PDF-1.4\n")
+	buf.WriteString("1 0 obj\n<< /Type /Catalog /Pages 2 0 R >>\nendobj\n")
+	buf.WriteString("2 0 obj\n<< /Type /Pages /Kids [3 0 R] /Count 1 >>\nendobj\n")
+	buf.WriteString("3 0 obj\n<< /Type /Page /Parent 2 0 R /Contents 4 0 R >>\nendobj\n")
+	fmt.Fprintf(&buf, "4 0 obj\n<< /Length %d%s >>\nstream\n", len(content), filter)
+	buf.Write(content)
+	buf.WriteString("\nendstream\nendobj\ntrailer\n<< /Root 1 0 R >>\n%%EOF\n")
+	return buf.Bytes()
+}
+
+const content = `BT
+/F1 12 Tf
+72 720 Td
+(Invoice \(draft\)) Tj
+0 -14 Td
+[(Total:) -250 (42) ( EUR)] TJ
+T*
+<48656C6C6F> Tj
+ET`
+
+func TestExtractText(t *testing.T) {
+	var compressed bytes.Buffer
+	zw := zlib.NewWriter(&compressed)
+	zw.Write([]byte(content))
+	zw.Close()
+
+	for name, doc := range map[string][]byte{
+		"uncompressed": buildPDF([]byte(content), ""),
+		"flate":        buildPDF(compressed.Bytes(), " /Filter /FlateDecode"),
+	} {
+		t.Run(name, func(t *testing.T) {
+			text, err := pdf.ExtractText(bytes.NewReader(doc))
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+
+			want := "Invoice (draft)\nTotal: 42 EUR\nHello"
+			if text != want {
+				t.Errorf("got %q, want %q", text, want)
+			}
+		})
+	}
+}
+
+func TestExtractText_NotPDF(t *testing.T) {
+	_, err := pdf.ExtractText(strings.NewReader("<html></html>"))
+	if !errors.Is(err, pdf.ErrNotPDF) {
+		t.Errorf("expected ErrNotPDF, got %v", err)
+	}
+}
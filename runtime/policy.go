@@ -0,0 +1,76 @@
+// Copyright (c) 2025 Suricata Contributors
+// Original Author: Stefano Scafiti
+//
+// This file is part of Suricata: Type-Safe AI Agents for Go.
+//
+// Licensed under the MIT License. You may obtain a copy of the License at
+//
+//	https://opensource.org/licenses/MIT
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package runtime
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+)
+
+// ErrToolDenied is reported to the model when a tool call is denied by the ToolPolicy of the runtime.
+var ErrToolDenied = errors.New("tool call denied by policy")
+
+// ToolPolicy is consulted before every tool call, with the name of the tool and the arguments
+// of the call; the caller can be identified from the context, e.g. through ValuesFromContext.
+// It returns the arguments the call runs with, which may be rewritten, or an error denying the
+// call, which is reported to the model in place of the tool output.
+type ToolPolicy func(ctx context.Context, tool string, args json.RawMessage) (json.RawMessage, error)
+
+// WithToolPolicy registers the policy consulted before every tool call. Calls allowed by
+// the policy may still require approval, see WithApproval.
+func WithToolPolicy(policy ToolPolicy) Option {
+	return func(r *Runtime) {
+		r.toolPolicy = policy
+	}
+}
+
+// applyToolPolicy consults the tool policy about calls, marking the denied ones
+// and decoding the rewritten arguments of the others.
+func (r *Runtime) applyToolPolicy(ctx context.Context, calls []toolCall, req *Request) {
+	if r.toolPolicy == nil {
+		return
+	}
+
+	for i, call := range calls {
+		if call.err != nil {
+			continue
+		}
+
+		args, err := r.toolPolicy(ctx, call.name, call.args)
+		if err != nil {
+			if !errors.Is(err, ErrToolDenied) {
+				err = fmt.Errorf("%w: %w", ErrToolDenied, err)
+			}
+			calls[i].err = &ToolError{Tool: call.name, Err: err}
+			continue
+		}
+
+		if args == nil || bytes.Equal(args, call.args) {
+			continue
+		}
+
+		in, err := req.ToolUnmarshaller(call.name, args)
+		if err != nil {
+			calls[i].err = &ToolError{Tool: call.name, Err: fmt.Errorf("unmarshal rewritten args: %w", err)}
+			continue
+		}
+		calls[i].in = in
+		calls[i].args = args
+	}
+}
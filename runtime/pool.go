@@ -0,0 +1,178 @@
+// Copyright (c) 2025 Suricata Contributors
+// Original Author: Stefano Scafiti
+//
+// This file is part of Suricata: Type-Safe AI Agents for Go.
+//
+// Licensed under the MIT License. You may obtain a copy of the License at
+//
+//	https://opensource.org/licenses/MIT
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package runtime
+
+import (
+	"cmp"
+	"context"
+	"errors"
+	"fmt"
+	"slices"
+	"sync/atomic"
+	"time"
+)
+
+// Balancing is the strategy a PooledInvoker uses to pick an endpoint.
+type Balancing int
+
+const (
+	RoundRobin  Balancing = iota // Endpoints take turns
+	LeastLoaded                  // The endpoint with the fewest calls in flight is picked
+)
+
+type PoolOptions struct {
+	Balancing        Balancing
+	FailureThreshold int           // Consecutive failures before an endpoint is taken out of the pool. Zero disables health tracking.
+	Cooldown         time.Duration // How long an unhealthy endpoint is skipped before being tried again.
+}
+
+func DefaultPoolOptions() PoolOptions {
+	return PoolOptions{
+		Balancing:        RoundRobin,
+		FailureThreshold: 3,
+		Cooldown:         30 * time.Second,
+	}
+}
+
+// PooledInvoker balances calls across invokers serving the same model, such as several
+// Ollama hosts, vLLM replicas or API keys. A call failing because of its endpoint, due to
+// a transport error or a retryable *ProviderError, is retried on the next healthy one.
+type PooledInvoker struct {
+	endpoints []*endpoint
+	opts      PoolOptions
+	turn      atomic.Uint64
+}
+
+type endpoint struct {
+	invoker  Invoker
+	breaker  *circuitBreaker
+	inFlight atomic.Int64
+}
+
+func NewPooledInvoker(opts PoolOptions, invokers ...Invoker) *PooledInvoker {
+	endpoints := make([]*endpoint, len(invokers))
+	for i, invoker := range invokers {
+		endpoints[i] = &endpoint{
+			invoker: invoker,
+			breaker: &circuitBreaker{
+				threshold: opts.FailureThreshold,
+				cooldown:  opts.Cooldown,
+			},
+		}
+	}
+
+	return &PooledInvoker{
+		endpoints: endpoints,
+		opts:      opts,
+	}
+}
+
+// Healthy returns the number of endpoints currently accepting calls.
+func (p *PooledInvoker) Healthy() int {
+	n := 0
+	for _, e := range p.endpoints {
+		if e.breaker.available() {
+			n++
+		}
+	}
+	return n
+}
+
+func (p *PooledInvoker) Invoke(ctx context.Context, systemPrompt string, messages []Message) (string, error) {
+	return p.call(ctx, systemPrompt, messages, nil)
+}
+
+// InvokeStream implements StreamingInvoker, streaming the response of the endpoints which support it.
+// The deltas of an endpoint failing midway are not retracted when the call is retried on the next one.
+func (p *PooledInvoker) InvokeStream(ctx context.Context, systemPrompt string, messages []Message, onDelta func(delta string)) (string, error) {
+	return p.call(ctx, systemPrompt, messages, onDelta)
+}
+
+// call tries the healthy endpoints in turn, streaming their responses if onDelta is set.
+func (p *PooledInvoker) call(ctx context.Context, systemPrompt string, messages []Message, onDelta func(delta string)) (string, error) {
+	var errs []error
+	for _, i := range p.order() {
+		e := p.endpoints[i]
+		if !e.breaker.allow() {
+			continue
+		}
+
+		e.inFlight.Add(1)
+		out, err := e.invoke(ctx, systemPrompt, messages, onDelta)
+		e.inFlight.Add(-1)
+
+		if err == nil {
+			e.breaker.success()
+			return out, nil
+		}
+
+		if ctx.Err() != nil {
+			e.breaker.release()
+			return "", ctx.Err()
+		}
+
+		// The endpoint is healthy: the request itself was rejected
+		var provErr *ProviderError
+		if errors.As(err, &provErr) && !provErr.Retryable() {
+			e.breaker.success()
+			return "", err
+		}
+
+		e.breaker.failure()
+		errs = append(errs, fmt.Errorf("endpoint %d: %w", i, err))
+	}
+
+	if len(errs) == 0 {
+		return "", ErrNoAvailableInvoker
+	}
+	return "", fmt.Errorf("%w: %w", ErrNoAvailableInvoker, errors.Join(errs...))
+}
+
+func (e *endpoint) invoke(ctx context.Context, systemPrompt string, messages []Message, onDelta func(delta string)) (string, error) {
+	if onDelta != nil {
+		return InvokeStream(ctx, e.invoker, systemPrompt, messages, onDelta)
+	}
+	return e.invoker.Invoke(ctx, systemPrompt, messages)
+}
+
+// order returns the indexes of the endpoints in the order they should be tried.
+func (p *PooledInvoker) order() []int {
+	n := len(p.endpoints)
+	if n == 0 {
+		return nil
+	}
+
+	start := int((p.turn.Add(1) - 1) % uint64(n))
+
+	order := make([]int, n)
+	for i := range order {
+		order[i] = (start + i) % n
+	}
+
+	if p.opts.Balancing == LeastLoaded {
+		// Sort a snapshot of the loads, as calls start and end concurrently
+		loads := make([]int64, n)
+		for i, e := range p.endpoints {
+			loads[i] = e.inFlight.Load()
+		}
+
+		// The stable sort keeps the round robin order among equally loaded endpoints
+		slices.SortStableFunc(order, func(a, b int) int {
+			return cmp.Compare(loads[a], loads[b])
+		})
+	}
+	return order
+}
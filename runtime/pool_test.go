@@ -0,0 +1,182 @@
+// Copyright (c) 2025 Suricata Contributors
+// Original Author: Stefano Scafiti
+//
+// This file is part of Suricata: Type-Safe AI Agents for Go.
+//
+// Licensed under the MIT License. You may obtain a copy of the License at
+//
+//	https://opensource.org/licenses/MIT
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package runtime_test
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/ostafen/suricata/runtime"
+)
+
+// endpointInvoker records the calls it receives and fails them with err.
+type endpointInvoker struct {
+	mu    sync.Mutex
+	calls int
+	err   error
+	block chan struct{}
+}
+
+func (e *endpointInvoker) Invoke(ctx context.Context, system string, messages []runtime.Message) (string, error) {
+	e.mu.Lock()
+	e.calls++
+	e.mu.Unlock()
+
+	if e.block != nil {
+		<-e.block
+	}
+	return "ok", e.err
+}
+
+func (e *endpointInvoker) count() int {
+	e.mu.Lock()
+	defer e.mu.Unlock()
+	return e.calls
+}
+
+func TestPooledInvoker(t *testing.T) {
+	ctx := context.Background()
+
+	t.Run("round robin", func(t *testing.T) {
+		a, b := &endpointInvoker{}, &endpointInvoker{}
+		pool := runtime.NewPooledInvoker(runtime.DefaultPoolOptions(), a, b)
+
+		for i := 0; i < 4; i++ {
+			if _, err := pool.Invoke(ctx, "", nil); err != nil {
+				t.Fatal(err)
+			}
+		}
+		if a.count() != 2 || b.count() != 2 {
+			t.Errorf("expected 2 calls per endpoint, got %d and %d", a.count(), b.count())
+		}
+	})
+
+	t.Run("least loaded", func(t *testing.T) {
+		busy := &endpointInvoker{block: make(chan struct{})}
+		idle := &endpointInvoker{}
+		pool := runtime.NewPooledInvoker(runtime.PoolOptions{Balancing: runtime.LeastLoaded}, busy, idle)
+
+		done := make(chan struct{})
+		go func() {
+			defer close(done)
+			pool.Invoke(ctx, "", nil)
+		}()
+		for busy.count() == 0 {
+			time.Sleep(time.Millisecond)
+		}
+
+		for i := 0; i < 3; i++ {
+			if _, err := pool.Invoke(ctx, "", nil); err != nil {
+				t.Fatal(err)
+			}
+		}
+		close(busy.block)
+		<-done
+
+		if busy.count() != 1 || idle.count() != 3 {
+			t.Errorf("expected calls to avoid the busy endpoint, got %d and %d", busy.count(), idle.count())
+		}
+	})
+
+	t.Run("unhealthy endpoints are skipped", func(t *testing.T) {
+		down := &endpointInvoker{err: errors.New("connection refused")}
+		up := &endpointInvoker{}
+		pool := runtime.NewPooledInvoker(runtime.PoolOptions{FailureThreshold: 1, Cooldown: time.Hour}, down, up)
+
+		for i := 0; i < 4; i++ {
+			if _, err := pool.Invoke(ctx, "", nil); err != nil {
+				t.Fatal(err)
+			}
+		}
+		if down.count() != 1 || up.count() != 4 {
+			t.Errorf("expected a single call to the failed endpoint, got %d and %d", down.count(), up.count())
+		}
+		if pool.Healthy() != 1 {
+			t.Errorf("expected 1 healthy endpoint, got %d", pool.Healthy())
+		}
+	})
+
+	t.Run("health checks do not start trial calls", func(t *testing.T) {
+		e := &endpointInvoker{err: errors.New("connection refused")}
+		pool := runtime.NewPooledInvoker(runtime.PoolOptions{FailureThreshold: 1, Cooldown: time.Millisecond}, e)
+
+		pool.Invoke(ctx, "", nil)
+		time.Sleep(5 * time.Millisecond)
+
+		if pool.Healthy() != 1 || pool.Healthy() != 1 {
+			t.Errorf("expected the endpoint to be healthy after the cooldown")
+		}
+
+		e.mu.Lock()
+		e.err = nil
+		e.mu.Unlock()
+
+		if _, err := pool.Invoke(ctx, "", nil); err != nil {
+			t.Fatalf("expected the recovered endpoint to rejoin the pool, got %v", err)
+		}
+	})
+
+	t.Run("canceled trial calls are released", func(t *testing.T) {
+		var calls int
+		pool := runtime.NewPooledInvoker(runtime.PoolOptions{FailureThreshold: 1, Cooldown: time.Millisecond},
+			runtime.InvokerFunc(func(ctx context.Context, system string, messages []runtime.Message) (string, error) {
+				calls++
+				if calls == 1 {
+					return "", errors.New("connection refused")
+				}
+				return "ok", ctx.Err()
+			}))
+
+		pool.Invoke(ctx, "", nil)
+		time.Sleep(5 * time.Millisecond)
+
+		canceled, cancel := context.WithCancel(ctx)
+		cancel()
+		if _, err := pool.Invoke(canceled, "", nil); !errors.Is(err, context.Canceled) {
+			t.Fatalf("expected context.Canceled, got %v", err)
+		}
+
+		if _, err := pool.Invoke(ctx, "", nil); err != nil {
+			t.Fatalf("expected a new trial call, got %v", err)
+		}
+	})
+
+	t.Run("rejected requests are not retried", func(t *testing.T) {
+		rejected := &runtime.ProviderError{Provider: "test", StatusCode: http.StatusBadRequest, Err: errors.New("bad request")}
+		a, b := &endpointInvoker{err: rejected}, &endpointInvoker{err: rejected}
+		pool := runtime.NewPooledInvoker(runtime.DefaultPoolOptions(), a, b)
+
+		if _, err := pool.Invoke(ctx, "", nil); !errors.Is(err, rejected) {
+			t.Fatalf("expected the provider error, got %v", err)
+		}
+		if a.count()+b.count() != 1 || pool.Healthy() != 2 {
+			t.Errorf("expected a single call and healthy endpoints")
+		}
+	})
+
+	t.Run("no endpoint available", func(t *testing.T) {
+		down := &endpointInvoker{err: errors.New("connection refused")}
+		pool := runtime.NewPooledInvoker(runtime.DefaultPoolOptions(), down)
+
+		if _, err := pool.Invoke(ctx, "", nil); !errors.Is(err, runtime.ErrNoAvailableInvoker) {
+			t.Fatalf("expected ErrNoAvailableInvoker, got %v", err)
+		}
+	})
+}
@@ -0,0 +1,231 @@
+// Copyright (c) 2025 Suricata Contributors
+// Original Author: Stefano Scafiti
+//
+// This file is part of Suricata: Type-Safe AI Agents for Go.
+//
+// Licensed under the MIT License. You may obtain a copy of the License at
+//
+//	https://opensource.org/licenses/MIT
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package runtime
+
+import (
+	"context"
+	"fmt"
+	"reflect"
+	"slices"
+	"strings"
+)
+
+// PostProcessor transforms the output of a request once decoded, before output guards run,
+// e.g. to normalize values or convert units. The output is the value of Request.Output:
+// a pointer to a struct, or a *string for free-text outputs.
+//
+// Returning an error matching ErrInvalidOutput, such as a *SchemaError, rejects the output:
+// the model is asked to repair it, as for invalid JSON. Other errors fail the run.
+type PostProcessor interface {
+	Process(ctx context.Context, output any) error
+}
+
+// PostProcessorFunc adapts an ordinary function to the PostProcessor interface.
+type PostProcessorFunc func(ctx context.Context, output any) error
+
+func (f PostProcessorFunc) Process(ctx context.Context, output any) error {
+	return f(ctx, output)
+}
+
+// WithPostProcessors registers post-processors applied to the outputs of the runtime, in order,
+// before the ones of each request.
+func WithPostProcessors(processors ...PostProcessor) Option {
+	return func(r *Runtime) {
+		r.postProcessors = append(r.postProcessors, processors...)
+	}
+}
+
+func (r *Runtime) postProcess(ctx context.Context, req *Request) error {
+	for _, p := range slices.Concat(r.postProcessors, req.PostProcessors) {
+		if err := p.Process(ctx, req.Output); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// TrimStrings returns a post-processor trimming the leading and trailing white space
+// of the strings of the output, including the ones of nested messages, lists and maps.
+func TrimStrings() PostProcessor {
+	return PostProcessorFunc(func(ctx context.Context, output any) error {
+		trimStrings(reflect.ValueOf(output))
+		return nil
+	})
+}
+
+func trimStrings(v reflect.Value) {
+	switch v.Kind() {
+	case reflect.Pointer:
+		if !v.IsNil() {
+			trimStrings(v.Elem())
+		}
+	case reflect.String:
+		if v.CanSet() {
+			v.SetString(strings.TrimSpace(v.String()))
+		}
+	case reflect.Struct:
+		for _, field := range reflect.VisibleFields(v.Type()) {
+			if field.IsExported() && !field.Anonymous {
+				trimStrings(v.FieldByIndex(field.Index))
+			}
+		}
+	case reflect.Slice, reflect.Array:
+		for i := range v.Len() {
+			trimStrings(v.Index(i))
+		}
+	case reflect.Map:
+		if v.Type().Elem().Kind() != reflect.String {
+			return
+		}
+		iter := v.MapRange()
+		for iter.Next() {
+			v.SetMapIndex(iter.Key(), reflect.ValueOf(strings.TrimSpace(iter.Value().String())).Convert(v.Type().Elem()))
+		}
+	}
+}
+
+// AllowFields returns a post-processor resetting the fields of the output not listed in paths
+// to their zero value, dropping the values the model is not supposed to produce, such as IDs.
+// Paths are made of the JSON names of the fields, separated by dots: "items" keeps the whole
+// field, while "items.name" keeps only the name of its elements.
+func AllowFields(paths ...string) PostProcessor {
+	allowed := make(fieldTree)
+	for _, path := range paths {
+		allowed.add(strings.Split(path, "."))
+	}
+
+	return PostProcessorFunc(func(ctx context.Context, output any) error {
+		allowed.apply(reflect.ValueOf(output))
+		return nil
+	})
+}
+
+// fieldTree holds the allowed fields by JSON name. A nil subtree allows the whole field.
+type fieldTree map[string]fieldTree
+
+func (t fieldTree) add(path []string) {
+	sub, ok := t[path[0]]
+	if len(path) == 1 {
+		t[path[0]] = nil
+		return
+	}
+	if ok && sub == nil {
+		return // The whole field is already allowed
+	}
+	if sub == nil {
+		sub = make(fieldTree)
+		t[path[0]] = sub
+	}
+	sub.add(path[1:])
+}
+
+func (t fieldTree) apply(v reflect.Value) {
+	switch v.Kind() {
+	case reflect.Pointer:
+		if !v.IsNil() {
+			t.apply(v.Elem())
+		}
+	case reflect.Slice, reflect.Array:
+		for i := range v.Len() {
+			t.apply(v.Index(i))
+		}
+	case reflect.Struct:
+		for _, field := range reflect.VisibleFields(v.Type()) {
+			name, ok := jsonFieldName(field)
+			if !ok {
+				continue
+			}
+
+			fv := v.FieldByIndex(field.Index)
+			sub, allowed := t[name]
+			switch {
+			case !allowed:
+				fv.SetZero()
+			case sub != nil:
+				sub.apply(fv)
+			}
+		}
+	}
+}
+
+// MapField returns a post-processor replacing the values at path with fn(value), e.g. to convert
+// units. The path is made of the JSON names of the fields, separated by dots: the values of lists
+// are mapped one by one, and an empty path maps the whole output, such as a free-text one.
+// Values not of type T fail the run.
+func MapField[T any](path string, fn func(T) T) PostProcessor {
+	var segments []string
+	if path != "" {
+		segments = strings.Split(path, ".")
+	}
+	typ := reflect.TypeFor[T]()
+
+	var apply func(v reflect.Value) error
+	apply = func(v reflect.Value) error {
+		if v.Type() == typ {
+			v.Set(reflect.ValueOf(fn(v.Interface().(T))))
+			return nil
+		}
+
+		switch v.Kind() {
+		case reflect.Pointer:
+			if v.IsNil() {
+				return nil
+			}
+			return apply(v.Elem())
+		case reflect.Slice, reflect.Array:
+			for i := range v.Len() {
+				if err := apply(v.Index(i)); err != nil {
+					return err
+				}
+			}
+			return nil
+		}
+		return fmt.Errorf("map field %q: expected %s, got %s", path, typ, v.Type())
+	}
+
+	return PostProcessorFunc(func(ctx context.Context, output any) error {
+		return walkPath(reflect.ValueOf(output).Elem(), segments, apply)
+	})
+}
+
+// walkPath calls fn on the values found at path, made of JSON field names, starting from v.
+// Lists along the path are walked element by element. Missing values are skipped.
+func walkPath(v reflect.Value, path []string, fn func(v reflect.Value) error) error {
+	if len(path) == 0 {
+		return fn(v)
+	}
+
+	switch v.Kind() {
+	case reflect.Pointer:
+		if v.IsNil() {
+			return nil
+		}
+		return walkPath(v.Elem(), path, fn)
+	case reflect.Slice, reflect.Array:
+		for i := range v.Len() {
+			if err := walkPath(v.Index(i), path, fn); err != nil {
+				return err
+			}
+		}
+	case reflect.Struct:
+		for _, field := range reflect.VisibleFields(v.Type()) {
+			if name, ok := jsonFieldName(field); ok && name == path[0] {
+				return walkPath(v.FieldByIndex(field.Index), path[1:], fn)
+			}
+		}
+	}
+	return nil
+}
@@ -0,0 +1,205 @@
+// Copyright (c) 2025 Suricata Contributors
+// Original Author: Stefano Scafiti
+//
+// This file is part of Suricata: Type-Safe AI Agents for Go.
+//
+// Licensed under the MIT License. You may obtain a copy of the License at
+//
+//	https://opensource.org/licenses/MIT
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package runtime_test
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strings"
+	"testing"
+
+	"github.com/ostafen/suricata/runtime"
+)
+
+type flight struct {
+	ID     string   `json:"id"`
+	Route  string   `json:"route"`
+	Price  float64  `json:"price"`
+	Tags   []string `json:"tags"`
+	Legs   []*leg   `json:"legs"`
+	Extras map[string]string
+}
+
+type leg struct {
+	From     string  `json:"from"`
+	To       string  `json:"to"`
+	Distance float64 `json:"distance"`
+}
+
+func newFlight() *flight {
+	return &flight{
+		ID:     "hallucinated",
+		Route:  "  MXP-JFK ",
+		Price:  12.5,
+		Tags:   []string{" direct", "cheap "},
+		Legs:   []*leg{{From: " MXP", To: "JFK ", Distance: 100}, nil},
+		Extras: map[string]string{"seat": " 12A "},
+	}
+}
+
+func TestTrimStrings(t *testing.T) {
+	out := newFlight()
+	if err := runtime.TrimStrings().Process(context.Background(), out); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	if out.Route != "MXP-JFK" || out.Tags[0] != "direct" || out.Tags[1] != "cheap" {
+		t.Errorf("strings not trimmed: %+v", out)
+	}
+	if out.Legs[0].From != "MXP" || out.Legs[0].To != "JFK" || out.Extras["seat"] != "12A" {
+		t.Errorf("nested strings not trimmed: %+v %+v", out.Legs[0], out.Extras)
+	}
+
+	text := "  hello\n"
+	if err := runtime.TrimStrings().Process(context.Background(), &text); err != nil || text != "hello" {
+		t.Errorf("unexpected free-text output %q (%v)", text, err)
+	}
+}
+
+func TestAllowFields(t *testing.T) {
+	out := newFlight()
+	if err := runtime.AllowFields("route", "price", "legs.from", "legs.to").Process(context.Background(), out); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	if out.ID != "" || out.Tags != nil || out.Extras != nil {
+		t.Errorf("fields not reset: %+v", out)
+	}
+	if out.Route == "" || out.Price == 0 {
+		t.Errorf("allowed fields reset: %+v", out)
+	}
+	if l := out.Legs[0]; l.From == "" || l.To == "" || l.Distance != 0 {
+		t.Errorf("unexpected leg: %+v", l)
+	}
+}
+
+func TestMapField(t *testing.T) {
+	out := newFlight()
+
+	toCents := runtime.MapField("price", func(p float64) float64 { return p * 100 })
+	toKm := runtime.MapField("legs.distance", func(d float64) float64 { return d * 1.5 })
+	upper := runtime.MapField("tags", strings.ToUpper)
+
+	for _, p := range []runtime.PostProcessor{toCents, toKm, upper} {
+		if err := p.Process(context.Background(), out); err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+	}
+
+	if out.Price != 1250 || out.Legs[0].Distance != 150 || out.Tags[0] != " DIRECT" {
+		t.Errorf("fields not mapped: %+v %+v", out, out.Legs[0])
+	}
+
+	err := runtime.MapField("route", func(n int) int { return n }).Process(context.Background(), out)
+	if err == nil {
+		t.Error("expected error for mismatched type")
+	}
+}
+
+func TestPostProcessors_Repair(t *testing.T) {
+	type Input struct{}
+	type Output struct {
+		Price float64 `json:"price"`
+	}
+
+	replies := []string{`{"price": -1}`, `{"price": 10}`}
+	var calls int
+	inv := runtime.InvokerFunc(func(ctx context.Context, system string, messages []runtime.Message) (string, error) {
+		calls++
+		return replies[min(calls, len(replies))-1], nil
+	})
+
+	positive := runtime.PostProcessorFunc(func(ctx context.Context, output any) error {
+		if output.(*Output).Price < 0 {
+			return fmt.Errorf("%w: price must be positive", runtime.ErrInvalidOutput)
+		}
+		return nil
+	})
+	double := runtime.MapField("price", func(p float64) float64 { return p * 2 })
+
+	var out Output
+	rt := runtime.NewRuntime(inv, runtime.WithPostProcessors(positive))
+	err := rt.Invoke(context.Background(), runtime.Request{
+		PromptTemplate: "price",
+		Input:          &Input{},
+		InputSchema:    runtime.NewSchema(`{"type":"object"}`),
+		Output:         &out,
+		OutputSchema:   runtime.NewSchema(`{"type":"object","properties":{"price":{"type":"number"}}}`),
+		Retry:          runtime.RetryPolicy{MaxAttempts: 2},
+		PostProcessors: []runtime.PostProcessor{double},
+	})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if calls != 2 || out.Price != 20 {
+		t.Errorf("expected a repaired and doubled price, got %v after %d calls", out.Price, calls)
+	}
+
+	failure := errors.New("boom")
+	calls = 0
+	err = runtime.NewRuntime(inv).Invoke(context.Background(), runtime.Request{
+		PromptTemplate: "price",
+		Input:          &Input{},
+		InputSchema:    runtime.NewSchema(`{"type":"object"}`),
+		Output:         &out,
+		Retry:          runtime.RetryPolicy{MaxAttempts: 2},
+		OutputSchema:   runtime.NewSchema(`{"type":"object"}`),
+		PostProcessors: []runtime.PostProcessor{runtime.PostProcessorFunc(func(ctx context.Context, output any) error { return failure })},
+	})
+	if !errors.Is(err, failure) || calls != 1 {
+		t.Errorf("expected the run to fail without repair, got %v after %d calls", err, calls)
+	}
+}
+
+func TestPostProcessors_RepairText(t *testing.T) {
+	type Input struct{}
+
+	replies := []string{"hi", "Hello, world!"}
+	var repair string
+	var calls int
+	inv := runtime.InvokerFunc(func(ctx context.Context, system string, messages []runtime.Message) (string, error) {
+		calls++
+		repair = messages[len(messages)-1].Content
+		return replies[min(calls, len(replies))-1], nil
+	})
+
+	polite := runtime.PostProcessorFunc(func(ctx context.Context, output any) error {
+		if !strings.HasPrefix(*output.(*string), "Hello") {
+			return fmt.Errorf("%w: greet with Hello", runtime.ErrInvalidOutput)
+		}
+		return nil
+	})
+
+	var out string
+	err := runtime.NewRuntime(inv).Invoke(context.Background(), runtime.Request{
+		PromptTemplate: "greet",
+		Input:          &Input{},
+		InputSchema:    runtime.NewSchema(`{"type":"object"}`),
+		Output:         &out,
+		Retry:          runtime.RetryPolicy{MaxAttempts: 2},
+		PostProcessors: []runtime.PostProcessor{polite},
+	})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if calls != 2 || out != "Hello, world!" {
+		t.Fatalf("expected a repaired reply, got %q after %d calls", out, calls)
+	}
+	if strings.Contains(repair, "JSON") || strings.Contains(repair, "OUTPUT FORMAT") {
+		t.Errorf("expected the repair prompt of a text reply to ask for text, got:\n%s", repair)
+	}
+}
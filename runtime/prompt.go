@@ -15,6 +15,7 @@
 package runtime
 
 import (
+	"context"
 	"encoding/json"
 	"fmt"
 	"strings"
@@ -22,39 +23,57 @@ import (
 	"github.com/xeipuuv/gojsonschema"
 )
 
+type promptVersionKey struct{}
+
+// WithPromptVersion returns a context carrying the version of the prompt being run.
+// Runtime.Invoke sets it from Request.PromptVersion.
+func WithPromptVersion(ctx context.Context, version string) context.Context {
+	return context.WithValue(ctx, promptVersionKey{}, version)
+}
+
+// PromptVersionFromContext returns the version of the prompt being run, if any.
+func PromptVersionFromContext(ctx context.Context) string {
+	version, _ := ctx.Value(promptVersionKey{}).(string)
+	return version
+}
+
+// Example is a sample input of an action, together with the expected output.
+// Values are encoded as JSON, except string outputs of free-text actions.
+type Example struct {
+	Input  any
+	Output any
+}
+
 type PromptBuilder struct {
 	strings.Builder
 }
 
+// Build returns the first user message sent for req. The instructions of req are
+// not part of it, as they are delivered separately, as the system prompt.
 func (pb *PromptBuilder) Build(userPrompt string, req *Request) string {
-	pb.writeInstructions(req)
-
 	if len(req.ToolSpecs) > 0 {
 		pb.writeWorkflow()
 	}
 
 	pb.writeTools(req.ToolSpecs)
+	pb.writeExamples(req.Examples, req.isTextOutput())
 
 	if !req.SkipInput {
 		pb.writeInput(req.Input)
 	}
 
-	pb.writeOutputFormat(req.OutputSchema, len(req.ToolSpecs) > 0)
-	pb.writeGuidelines()
+	// Free-text replies need no output format, unless tool calls must be told apart from the final answer
+	if !req.isTextOutput() || len(req.ToolSpecs) > 0 {
+		pb.writeOutputFormat(req.OutputSchema, len(req.ToolSpecs) > 0)
+		pb.writeGuidelines()
+	} else {
+		pb.WriteString("\n")
+	}
 	pb.writeUserPrompt(userPrompt)
 
 	return pb.String()
 }
 
-func (pb *PromptBuilder) writeInstructions(req *Request) {
-	// System instructions
-	if req.Instructions != "" {
-		pb.WriteString("[SYSTEM INSTRUCTIONS]\n\n")
-		pb.WriteString(req.Instructions)
-		pb.WriteString("\n\n")
-	}
-}
-
 func (pb *PromptBuilder) writeUserPrompt(prompt string) {
 	// User prompt
 	pb.WriteString("[USER PROMPT]\n\n")
@@ -78,6 +97,25 @@ func (pb *PromptBuilder) writeWorkflow() {
 `)
 }
 
+func (pb *PromptBuilder) writeExamples(examples []Example, textOutput bool) {
+	if len(examples) == 0 {
+		return
+	}
+
+	pb.WriteString("\n[EXAMPLES]\n")
+	for i, example := range examples {
+		rawInput, _ := json.Marshal(example.Input)
+		fmt.Fprintf(&pb.Builder, "\nExample %d:\n\nInput: %s\n", i+1, rawInput)
+
+		if out, ok := example.Output.(string); ok && textOutput {
+			fmt.Fprintf(&pb.Builder, "Output: %s\n", out)
+			continue
+		}
+		rawOutput, _ := json.Marshal(example.Output)
+		fmt.Fprintf(&pb.Builder, "Output: %s\n", rawOutput)
+	}
+}
+
 func (pb *PromptBuilder) writeInput(in any) {
 	rawInput, _ := json.Marshal(in)
 	pb.WriteString("\n[INPUT]:\n\n")
@@ -97,8 +135,11 @@ func (pb *PromptBuilder) writeTools(tools []ToolSpec) {
 }
 
 func (pb *PromptBuilder) writeOutputFormat(outSchema gojsonschema.JSONLoader, hasTools bool) {
-	jsonSchema, _ := outSchema.LoadJSON()
-	rawSchema, _ := json.Marshal(jsonSchema)
+	var rawSchema []byte
+	if outSchema != nil {
+		jsonSchema, _ := outSchema.LoadJSON()
+		rawSchema, _ = json.Marshal(jsonSchema)
+	}
 
 	if !hasTools {
 		pb.WriteString(`
@@ -110,6 +151,14 @@ Return ONLY a valid JSON object matching the following schema:
 		return
 	}
 
+	// Free-text final answers are wrapped in a JSON string
+	outValue, outFormat := `"..."`, `where "out" is a string containing your final answer to the user.`
+	if outSchema != nil {
+		outValue, outFormat = "{...}", `where "out" is a JSON object strictly matching the following JSON schema:
+
+`+string(rawSchema)
+	}
+
 	pb.WriteString(`
 [OUTPUT FORMAT]
 
@@ -126,16 +175,21 @@ After each tool output or error, you must return exactly one JSON object, follow
 - "args": A JSON object that matches the input schema for the selected tool exactly.
 - Do not include extra fields or omit required fields.
 
+If several independent tool calls are needed, you may request them at once by returning a JSON array of tool call objects:
+
+[
+	{"name": "<tool name>", "args": {...}},
+	{"name": "<tool name>", "args": {...}}
+]
+
 2. If goal is achieved (final output):
 
 {
 	"done": true,
-	"out": {...}
+	"out": ` + outValue + `
 }
 
-where "out" is a JSON object strictly matching the following JSON schema:
-
-` + string(rawSchema))
+` + outFormat)
 }
 
 func (pb *PromptBuilder) writeGuidelines() {
@@ -63,8 +63,8 @@ func TestPromptBuilder_Build(t *testing.T) {
 	prompt := builder.Build("What is AI?", req)
 
 	// Assert
-	if !strings.Contains(prompt, "SYSTEM INSTRUCTIONS") {
-		t.Errorf("Expected SYSTEM INSTRUCTIONS section, got: %s", prompt)
+	if strings.Contains(prompt, "Follow these system rules.") {
+		t.Errorf("Expected instructions to be left to the system prompt, got: %s", prompt)
 	}
 
 	if !strings.Contains(prompt, "[USER PROMPT]\n\nWhat is AI?") {
@@ -123,3 +123,69 @@ func TestPromptBuilder_Build_NoTools(t *testing.T) {
 		t.Errorf("Expected no TOOLS section when ToolSpecs is empty")
 	}
 }
+
+func TestPromptBuilder_Build_TextOutput(t *testing.T) {
+	req := &runtime.Request{
+		Instructions: "Summarize the input",
+		Input:        map[string]string{"text": "value"},
+	}
+
+	builder := &runtime.PromptBuilder{}
+	prompt := builder.Build("Summarize", req)
+
+	if strings.Contains(prompt, "[OUTPUT FORMAT]") || strings.Contains(prompt, "[GUIDELINES]") {
+		t.Errorf("Expected no OUTPUT FORMAT and GUIDELINES sections for free-text output, got: %s", prompt)
+	}
+}
+
+func TestRenderPrompt(t *testing.T) {
+	req := runtime.Request{
+		Instructions:   "Be concise.",
+		PromptTemplate: "Greet {{.Name}}.",
+		Input:          struct{ Name string }{Name: "Bob"},
+	}
+
+	prompt, err := runtime.RenderPrompt(req)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	for _, expected := range []string{`{"Name":"Bob"}`, "Greet Bob."} {
+		if !strings.Contains(prompt, expected) {
+			t.Errorf("expected prompt to contain %q, got:\n%s", expected, prompt)
+		}
+	}
+
+	req.PromptTemplate = "{{.Missing"
+	if _, err := runtime.RenderPrompt(req); err == nil {
+		t.Errorf("expected template error")
+	}
+}
+
+func TestPromptBuilder_Build_Examples(t *testing.T) {
+	req := &runtime.Request{
+		Input:        map[string]string{"text": "Bob lives in Rome"},
+		OutputSchema: gojsonschema.NewStringLoader(`{"type": "object"}`),
+		Examples: []runtime.Example{
+			{Input: map[string]string{"text": "Ada lives in London"}, Output: json.RawMessage(`{"city":"London"}`)},
+		},
+	}
+
+	builder := &runtime.PromptBuilder{}
+	prompt := builder.Build("Extract the city", req)
+
+	example := "[EXAMPLES]\n\nExample 1:\n\nInput: {\"text\":\"Ada lives in London\"}\nOutput: {\"city\":\"London\"}\n"
+	if !strings.Contains(prompt, example) {
+		t.Fatalf("expected examples in prompt, got: %s", prompt)
+	}
+	if strings.Index(prompt, "[EXAMPLES]") > strings.Index(prompt, "[INPUT]") {
+		t.Errorf("expected examples before the input, got: %s", prompt)
+	}
+
+	req.OutputSchema = nil
+	req.Examples = []runtime.Example{{Input: map[string]string{"text": "..."}, Output: "A short summary."}}
+	prompt = (&runtime.PromptBuilder{}).Build("Summarize", req)
+	if !strings.Contains(prompt, "Output: A short summary.\n") {
+		t.Errorf("expected free-text example output, got: %s", prompt)
+	}
+}
@@ -0,0 +1,230 @@
+// Copyright (c) 2025 Suricata Contributors
+// Original Author: Stefano Scafiti
+//
+// This file is part of Suricata: Type-Safe AI Agents for Go.
+//
+// Licensed under the MIT License. You may obtain a copy of the License at
+//
+//	https://opensource.org/licenses/MIT
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+// Package ratelimit throttles the calls made to an LLM provider.
+//
+// A single Invoker should be shared by every agent using the same API key,
+// so that the limits apply to their combined traffic:
+//
+//	limited := ratelimit.NewInvoker(invoker, ratelimit.Options{
+//		RequestsPerSecond: 5,
+//		MaxConcurrent:     4,
+//		MaxRetries:        3,
+//	})
+package ratelimit
+
+import (
+	"context"
+	"errors"
+	"sync"
+	"time"
+
+	"github.com/ostafen/suricata/runtime"
+)
+
+// DefaultRetryAfter is the pause applied after a rate limit error that does
+// not carry a Retry-After delay.
+const DefaultRetryAfter = time.Second
+
+type Options struct {
+	RequestsPerSecond float64       // Sustained request rate. Zero or less disables the limit.
+	Burst             int           // Requests allowed at once before the rate applies. Defaults to 1.
+	MaxConcurrent     int           // Requests in flight at the same time. Zero or less means no limit.
+	MaxRetries        int           // Retries after a rate limited *runtime.ProviderError. Zero returns the error immediately.
+	MaxRetryAfter     time.Duration // Upper bound on a single pause. Zero means no bound.
+}
+
+// Invoker enforces Options on the calls forwarded to the wrapped invoker.
+// When the provider answers with HTTP 429, every caller
+// pauses for the requested delay before the call is retried.
+type Invoker struct {
+	next   runtime.Invoker
+	opts   Options
+	bucket *bucket
+	slots  chan struct{}
+
+	mu          sync.Mutex
+	pausedUntil time.Time
+}
+
+func NewInvoker(next runtime.Invoker, opts Options) *Invoker {
+	inv := &Invoker{
+		next: next,
+		opts: opts,
+	}
+
+	if opts.RequestsPerSecond > 0 {
+		inv.bucket = newBucket(opts.RequestsPerSecond, max(opts.Burst, 1))
+	}
+	if opts.MaxConcurrent > 0 {
+		inv.slots = make(chan struct{}, opts.MaxConcurrent)
+	}
+	return inv
+}
+
+// Middleware returns a runtime.InvokerMiddleware enforcing opts.
+// All invokers wrapped by the returned middleware share the same limits.
+func Middleware(opts Options) runtime.InvokerMiddleware {
+	limiter := NewInvoker(nil, opts)
+	return func(next runtime.Invoker) runtime.Invoker {
+		return runtime.InvokerFunc(func(ctx context.Context, systemPrompt string, messages []runtime.Message) (string, error) {
+			return limiter.invoke(ctx, next, systemPrompt, messages)
+		})
+	}
+}
+
+func (l *Invoker) Invoke(ctx context.Context, systemPrompt string, messages []runtime.Message) (string, error) {
+	return l.invoke(ctx, l.next, systemPrompt, messages)
+}
+
+// InvokeStream implements runtime.StreamingInvoker, streaming the response of the wrapped invoker if it supports it.
+func (l *Invoker) InvokeStream(ctx context.Context, systemPrompt string, messages []runtime.Message, onDelta func(delta string)) (string, error) {
+	return l.call(ctx, l.next, systemPrompt, messages, onDelta)
+}
+
+func (l *Invoker) invoke(ctx context.Context, next runtime.Invoker, systemPrompt string, messages []runtime.Message) (string, error) {
+	return l.call(ctx, next, systemPrompt, messages, nil)
+}
+
+// call sends the request to next, streaming its response if onDelta is set, and retries it while rate limited.
+func (l *Invoker) call(ctx context.Context, next runtime.Invoker, systemPrompt string, messages []runtime.Message, onDelta func(delta string)) (string, error) {
+	for retries := 0; ; retries++ {
+		out, err := l.attempt(ctx, next, systemPrompt, messages, onDelta)
+
+		var provErr *runtime.ProviderError
+		if !errors.As(err, &provErr) || !provErr.RateLimited() || retries >= l.opts.MaxRetries {
+			return out, err
+		}
+		l.pause(provErr.RetryAfter)
+	}
+}
+
+func (l *Invoker) attempt(ctx context.Context, next runtime.Invoker, systemPrompt string, messages []runtime.Message, onDelta func(delta string)) (string, error) {
+	if l.slots != nil {
+		select {
+		case l.slots <- struct{}{}:
+		case <-ctx.Done():
+			return "", ctx.Err()
+		}
+		defer func() { <-l.slots }()
+	}
+
+	if err := l.wait(ctx); err != nil {
+		return "", err
+	}
+
+	if onDelta != nil {
+		return runtime.InvokeStream(ctx, next, systemPrompt, messages, onDelta)
+	}
+	return next.Invoke(ctx, systemPrompt, messages)
+}
+
+// wait blocks until the provider pause is over and a token is available.
+func (l *Invoker) wait(ctx context.Context) error {
+	l.mu.Lock()
+	delay := time.Until(l.pausedUntil)
+	l.mu.Unlock()
+
+	if err := sleep(ctx, delay); err != nil {
+		return err
+	}
+
+	if l.bucket == nil {
+		return nil
+	}
+
+	delay = l.bucket.reserve(time.Now())
+	if err := sleep(ctx, delay); err != nil {
+		l.bucket.cancel()
+		return err
+	}
+	return nil
+}
+
+func (l *Invoker) pause(d time.Duration) {
+	if d <= 0 {
+		d = DefaultRetryAfter
+	}
+	if l.opts.MaxRetryAfter > 0 {
+		d = min(d, l.opts.MaxRetryAfter)
+	}
+
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	if until := time.Now().Add(d); until.After(l.pausedUntil) {
+		l.pausedUntil = until
+	}
+}
+
+func sleep(ctx context.Context, d time.Duration) error {
+	if d <= 0 {
+		return ctx.Err()
+	}
+
+	t := time.NewTimer(d)
+	defer t.Stop()
+
+	select {
+	case <-ctx.Done():
+		return ctx.Err()
+	case <-t.C:
+		return nil
+	}
+}
+
+// bucket is a token bucket refilled at rate tokens per second.
+// Tokens can go negative: each reservation waits for the debt it creates.
+type bucket struct {
+	mu     sync.Mutex
+	rate   float64
+	burst  float64
+	tokens float64
+	last   time.Time
+}
+
+func newBucket(rate float64, burst int) *bucket {
+	return &bucket{
+		rate:   rate,
+		burst:  float64(burst),
+		tokens: float64(burst),
+		last:   time.Now(),
+	}
+}
+
+// reserve takes a token and returns how long to wait before using it.
+func (b *bucket) reserve(now time.Time) time.Duration {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	if elapsed := now.Sub(b.last).Seconds(); elapsed > 0 {
+		b.tokens = min(b.burst, b.tokens+elapsed*b.rate)
+		b.last = now
+	}
+
+	b.tokens--
+	if b.tokens >= 0 {
+		return 0
+	}
+	return time.Duration(-b.tokens / b.rate * float64(time.Second))
+}
+
+// cancel gives back a token reserved but never used.
+func (b *bucket) cancel() {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	b.tokens = min(b.burst, b.tokens+1)
+}
@@ -0,0 +1,176 @@
+// Copyright (c) 2025 Suricata Contributors
+// Original Author: Stefano Scafiti
+//
+// This file is part of Suricata: Type-Safe AI Agents for Go.
+//
+// Licensed under the MIT License. You may obtain a copy of the License at
+//
+//	https://opensource.org/licenses/MIT
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package ratelimit_test
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/ostafen/suricata/runtime"
+	"github.com/ostafen/suricata/runtime/ratelimit"
+)
+
+func TestInvoker_MaxConcurrent(t *testing.T) {
+	var inFlight, peak atomic.Int32
+
+	base := runtime.InvokerFunc(func(ctx context.Context, system string, messages []runtime.Message) (string, error) {
+		n := inFlight.Add(1)
+		defer inFlight.Add(-1)
+
+		for {
+			p := peak.Load()
+			if n <= p || peak.CompareAndSwap(p, n) {
+				break
+			}
+		}
+		time.Sleep(10 * time.Millisecond)
+		return "ok", nil
+	})
+
+	inv := ratelimit.NewInvoker(base, ratelimit.Options{MaxConcurrent: 2})
+
+	var wg sync.WaitGroup
+	for i := 0; i < 8; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			if _, err := inv.Invoke(context.Background(), "", nil); err != nil {
+				t.Errorf("unexpected error: %v", err)
+			}
+		}()
+	}
+	wg.Wait()
+
+	if got := peak.Load(); got != 2 {
+		t.Errorf("expected at most 2 concurrent calls, got %d", got)
+	}
+}
+
+func TestInvoker_RequestsPerSecond(t *testing.T) {
+	base := runtime.InvokerFunc(func(ctx context.Context, system string, messages []runtime.Message) (string, error) {
+		return "ok", nil
+	})
+
+	inv := ratelimit.NewInvoker(base, ratelimit.Options{RequestsPerSecond: 50, Burst: 2})
+
+	start := time.Now()
+	for i := 0; i < 5; i++ {
+		if _, err := inv.Invoke(context.Background(), "", nil); err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+	}
+
+	// Two calls fit in the burst, the other three wait 20ms each.
+	if elapsed := time.Since(start); elapsed < 55*time.Millisecond {
+		t.Errorf("expected calls to be throttled, took %s", elapsed)
+	}
+}
+
+func TestInvoker_RetryAfter(t *testing.T) {
+	var calls int
+	base := runtime.InvokerFunc(func(ctx context.Context, system string, messages []runtime.Message) (string, error) {
+		calls++
+		if calls < 3 {
+			return "", &runtime.ProviderError{StatusCode: http.StatusTooManyRequests, RetryAfter: 20 * time.Millisecond, Err: errors.New("slow down")}
+		}
+		return "ok", nil
+	})
+
+	t.Run("retries", func(t *testing.T) {
+		inv := ratelimit.NewInvoker(base, ratelimit.Options{MaxRetries: 3})
+
+		start := time.Now()
+		out, err := inv.Invoke(context.Background(), "", nil)
+		if err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+		if out != "ok" || calls != 3 {
+			t.Errorf("expected success on the third call, got %q after %d calls", out, calls)
+		}
+		if elapsed := time.Since(start); elapsed < 40*time.Millisecond {
+			t.Errorf("expected Retry-After to be honoured, took %s", elapsed)
+		}
+	})
+
+	t.Run("exhausted", func(t *testing.T) {
+		calls = 0
+		inv := ratelimit.NewInvoker(base, ratelimit.Options{MaxRetries: 1})
+
+		_, err := inv.Invoke(context.Background(), "", nil)
+
+		var provErr *runtime.ProviderError
+		if !errors.As(err, &provErr) || !provErr.RateLimited() {
+			t.Fatalf("expected rate limited ProviderError, got %v", err)
+		}
+		if calls != 2 {
+			t.Errorf("expected 2 calls, got %d", calls)
+		}
+	})
+}
+
+func TestInvoker_ContextCanceled(t *testing.T) {
+	base := runtime.InvokerFunc(func(ctx context.Context, system string, messages []runtime.Message) (string, error) {
+		return "", &runtime.ProviderError{StatusCode: http.StatusTooManyRequests, RetryAfter: time.Hour, Err: errors.New("slow down")}
+	})
+
+	inv := ratelimit.NewInvoker(base, ratelimit.Options{MaxRetries: 1})
+
+	ctx, cancel := context.WithTimeout(context.Background(), 20*time.Millisecond)
+	defer cancel()
+
+	if _, err := inv.Invoke(ctx, "", nil); !errors.Is(err, context.DeadlineExceeded) {
+		t.Errorf("expected deadline exceeded, got %v", err)
+	}
+}
+
+type streamingInvoker struct{}
+
+func (streamingInvoker) Invoke(ctx context.Context, system string, messages []runtime.Message) (string, error) {
+	return "ab", nil
+}
+
+func (streamingInvoker) InvokeStream(ctx context.Context, system string, messages []runtime.Message, onDelta func(string)) (string, error) {
+	onDelta("a")
+	onDelta("b")
+	return "ab", nil
+}
+
+func TestInvoker_Stream(t *testing.T) {
+	opts := ratelimit.Options{RequestsPerSecond: 100, MaxConcurrent: 1}
+
+	for name, inv := range map[string]runtime.Invoker{
+		"invoker":    ratelimit.NewInvoker(streamingInvoker{}, opts),
+		"middleware": runtime.Chain(streamingInvoker{}, ratelimit.Middleware(opts)),
+	} {
+		t.Run(name, func(t *testing.T) {
+			var deltas []string
+			_, err := inv.(runtime.StreamingInvoker).InvokeStream(context.Background(), "", nil, func(delta string) {
+				deltas = append(deltas, delta)
+			})
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			if len(deltas) != 2 {
+				t.Errorf("expected the response to be streamed, got %q", deltas)
+			}
+		})
+	}
+}
@@ -0,0 +1,253 @@
+// Copyright (c) 2025 Suricata Contributors
+// Original Author: Stefano Scafiti
+//
+// This file is part of Suricata: Type-Safe AI Agents for Go.
+//
+// Licensed under the MIT License. You may obtain a copy of the License at
+//
+//	https://opensource.org/licenses/MIT
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package runtime
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"maps"
+	"reflect"
+	"slices"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// ErrNotReplayable is returned when a transcript lacks the prompt of its run, such as
+// the transcripts of resumed runs.
+var ErrNotReplayable = errors.New("transcript cannot be replayed")
+
+// MaxReplayIterations is the maximum number of tool calls made while replaying a transcript.
+const MaxReplayIterations = 32
+
+// OutputDiff is a value of the output of a run which changed when replayed.
+type OutputDiff struct {
+	Field    string `json:"field"` // Path of the value, such as "items.0.name", or "(root)"
+	Recorded any    `json:"recorded"`
+	Replayed any    `json:"replayed"`
+}
+
+func (d OutputDiff) String() string {
+	recorded, _ := json.Marshal(d.Recorded)
+	replayed, _ := json.Marshal(d.Replayed)
+	return fmt.Sprintf("%s: %s -> %s", d.Field, recorded, replayed)
+}
+
+// ReplayResult is the outcome of replaying a transcript.
+type ReplayResult struct {
+	Transcript *Transcript  // Record of the replayed run
+	Diffs      []OutputDiff // Differences between the recorded output and the replayed one
+}
+
+// Equal reports whether the replayed output is the same as the recorded one.
+func (res *ReplayResult) Equal() bool {
+	return len(res.Diffs) == 0
+}
+
+// Replay sends the prompt of a recorded run to invoker, such as the invoker of a different model
+// or provider, and compares the output with the recorded one. Tool calls made with the same
+// arguments as in the recording are answered with the recorded results, without running the tools;
+// any other call is reported to the model as failed. Replayed outputs are not validated against
+// the output schema, so that invalid outputs show up as differences.
+func Replay(ctx context.Context, t *Transcript, invoker Invoker) (*ReplayResult, error) {
+	prompt, ok := t.prompt()
+	if !ok {
+		return nil, ErrNotReplayable
+	}
+
+	specs := make([]ToolSpec, len(t.Tools))
+	for i, tool := range t.Tools {
+		specs[i] = ToolSpec{Name: tool.Name, Description: tool.Description}
+		if tool.Schema != nil {
+			specs[i].Schema = NewSchema(string(tool.Schema))
+		}
+	}
+	ctx = WithToolSpecs(ctx, specs)
+	if !t.ToolLoop && t.OutputSchema != nil {
+		ctx = WithOutputSchema(ctx, NewSchema(string(t.OutputSchema)))
+	}
+
+	replayed := &Transcript{
+		RequestID:     t.RequestID,
+		Action:        t.Action,
+		PromptVersion: t.PromptVersion,
+		Model:         ModelOptionsFromContext(ctx).Model,
+		Instructions:  t.Instructions,
+		Tools:         t.Tools,
+		ToolLoop:      t.ToolLoop,
+		OutputSchema:  t.OutputSchema,
+		Input:         t.Input,
+		Start:         time.Now(),
+	}
+
+	sess := NewChatSession(invoker, t.Instructions)
+	out, err := replayRun(ctx, t, replayed, sess, prompt)
+	if err != nil {
+		return nil, err
+	}
+	replayed.Duration = time.Since(replayed.Start)
+	replayed.Output = out
+
+	var recordedOut, replayedOut any
+	_ = json.Unmarshal(t.Output, &recordedOut)
+	_ = json.Unmarshal(out, &replayedOut)
+	return &ReplayResult{Transcript: replayed, Diffs: diffValues("(root)", recordedOut, replayedOut, nil)}, nil
+}
+
+// replayRun drives the replayed conversation to its output, encoded as JSON.
+func replayRun(ctx context.Context, t, replayed *Transcript, sess *ChatSession, prompt string) (json.RawMessage, error) {
+	msgs := []Message{{Role: RoleUser, Content: prompt}}
+	replayed.add(TranscriptStep{Kind: StepPrompt, Content: prompt})
+
+	for iterations := 0; ; {
+		start := time.Now()
+		out, err := sess.InvokeMessages(ctx, msgs...)
+		replayed.add(TranscriptStep{Kind: StepResponse, Time: start, Duration: time.Since(start), Content: out, Error: errorString(err)})
+		if err != nil {
+			return nil, err
+		}
+
+		if !t.ToolLoop {
+			return replayOutput(t, out)
+		}
+
+		resps, err := parseToolResponses(out)
+		if err != nil {
+			replayed.add(TranscriptStep{Kind: StepValidationError, Error: err.Error()})
+			return json.Marshal(out)
+		}
+		if len(resps) == 1 && resps[0].Done {
+			return json.Marshal(resps[0].Out)
+		}
+
+		msgs = msgs[:0]
+		for _, resp := range resps {
+			iterations++
+			if iterations > MaxReplayIterations {
+				return nil, fmt.Errorf("%w: limit is %d", ErrMaxIterations, MaxReplayIterations)
+			}
+
+			args, _ := json.Marshal(resp.Args)
+			step := TranscriptStep{Kind: StepToolCall, Time: time.Now(), Tool: resp.Name, Args: args}
+			msg := Message{Role: RoleTool, ToolCall: &ToolCallRef{ID: toolCallID(iterations), Name: resp.Name, Args: args}}
+
+			if recorded, ok := t.toolCall(resp.Name, args); ok {
+				step.Result, step.Error = recorded.Result, recorded.Error
+				msg.Content = string(recorded.Result)
+				if recorded.Error != "" {
+					msg.Content = "ERR: " + recorded.Error
+				}
+			} else {
+				step.Error = "call not recorded in the transcript"
+				msg.Content = "ERR: " + step.Error
+			}
+
+			replayed.add(step)
+			msgs = append(msgs, msg)
+		}
+	}
+}
+
+// replayOutput returns the output of a response of a run without tools.
+func replayOutput(t *Transcript, out string) (json.RawMessage, error) {
+	if t.OutputSchema == nil {
+		return json.Marshal(strings.TrimSpace(out))
+	}
+
+	for _, candidate := range ExtractJSONCandidates(out) {
+		if candidate[0] == '{' {
+			return json.RawMessage(candidate), nil
+		}
+	}
+	return json.Marshal(out)
+}
+
+// prompt returns the first prompt of the recorded run.
+func (t *Transcript) prompt() (string, bool) {
+	for _, step := range t.Steps {
+		if step.Kind == StepPrompt {
+			return step.Content, true
+		}
+	}
+	return "", false
+}
+
+// toolCall returns the first recorded call of the named tool with the given arguments.
+func (t *Transcript) toolCall(name string, args json.RawMessage) (TranscriptStep, bool) {
+	for _, step := range t.Steps {
+		if step.Kind == StepToolCall && step.Tool == name && sameJSON(step.Args, args) {
+			return step, true
+		}
+	}
+	return TranscriptStep{}, false
+}
+
+func sameJSON(a, b json.RawMessage) bool {
+	var va, vb any
+	if json.Unmarshal(a, &va) != nil || json.Unmarshal(b, &vb) != nil {
+		return false
+	}
+	return reflect.DeepEqual(va, vb)
+}
+
+// diffValues appends to diffs the differences between two decoded JSON values.
+func diffValues(field string, recorded, replayed any, diffs []OutputDiff) []OutputDiff {
+	switch rec := recorded.(type) {
+	case map[string]any:
+		rep, ok := replayed.(map[string]any)
+		if !ok {
+			break
+		}
+
+		keys := maps.Clone(rec)
+		maps.Copy(keys, rep)
+		for _, key := range slices.Sorted(maps.Keys(keys)) {
+			diffs = diffValues(joinField(field, key), rec[key], rep[key], diffs)
+		}
+		return diffs
+	case []any:
+		rep, ok := replayed.([]any)
+		if !ok {
+			break
+		}
+
+		for i := range max(len(rec), len(rep)) {
+			var a, b any
+			if i < len(rec) {
+				a = rec[i]
+			}
+			if i < len(rep) {
+				b = rep[i]
+			}
+			diffs = diffValues(joinField(field, strconv.Itoa(i)), a, b, diffs)
+		}
+		return diffs
+	}
+
+	if !reflect.DeepEqual(recorded, replayed) {
+		diffs = append(diffs, OutputDiff{Field: field, Recorded: recorded, Replayed: replayed})
+	}
+	return diffs
+}
+
+func joinField(parent, key string) string {
+	if parent == "(root)" {
+		return key
+	}
+	return parent + "." + key
+}
@@ -0,0 +1,245 @@
+// Copyright (c) 2025 Suricata Contributors
+// Original Author: Stefano Scafiti
+//
+// This file is part of Suricata: Type-Safe AI Agents for Go.
+//
+// Licensed under the MIT License. You may obtain a copy of the License at
+//
+//	https://opensource.org/licenses/MIT
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package retrieval
+
+import (
+	"strings"
+	"unicode"
+)
+
+// Chunker splits a text into chunks, which are embedded and indexed separately.
+type Chunker interface {
+	Chunk(text string) []string
+}
+
+// ChunkerFunc adapts an ordinary function to the Chunker interface.
+type ChunkerFunc func(text string) []string
+
+func (f ChunkerFunc) Chunk(text string) []string {
+	return f(text)
+}
+
+// DefaultChunkSize is the maximum size, in characters, of the chunks of chunkers whose size is not set.
+const DefaultChunkSize = 1000
+
+// FixedChunker splits texts into chunks of Size characters, each one repeating
+// the last Overlap characters of the previous chunk.
+type FixedChunker struct {
+	Size    int
+	Overlap int
+}
+
+func (c FixedChunker) Chunk(text string) []string {
+	size := chunkSize(c.Size)
+	overlap := max(0, min(c.Overlap, size-1))
+
+	runes := []rune(text)
+
+	var chunks []string
+	for start := 0; start < len(runes); start += size - overlap {
+		end := min(start+size, len(runes))
+		if chunk := strings.TrimSpace(string(runes[start:end])); chunk != "" {
+			chunks = append(chunks, chunk)
+		}
+		if end == len(runes) {
+			break
+		}
+	}
+	return chunks
+}
+
+// SentenceChunker groups consecutive sentences into chunks of at most MaxSize characters.
+// Paragraphs always start a new chunk, and sentences longer than MaxSize are split with a FixedChunker.
+type SentenceChunker struct {
+	MaxSize int
+}
+
+func (c SentenceChunker) Chunk(text string) []string {
+	size := chunkSize(c.MaxSize)
+
+	var (
+		chunks  []string
+		current strings.Builder
+		n       int // Length of current, in runes
+	)
+
+	flush := func() {
+		if chunk := strings.TrimSpace(current.String()); chunk != "" {
+			chunks = append(chunks, chunk)
+		}
+		current.Reset()
+		n = 0
+	}
+
+	for _, paragraph := range splitParagraphs(text) {
+		for _, sentence := range splitSentences(paragraph) {
+			length := len([]rune(sentence))
+
+			if length > size {
+				flush()
+				chunks = append(chunks, FixedChunker{Size: size}.Chunk(sentence)...)
+				continue
+			}
+
+			if n > 0 && n+1+length > size {
+				flush()
+			}
+			if n > 0 {
+				current.WriteByte(' ')
+				n++
+			}
+			current.WriteString(sentence)
+			n += length
+		}
+		flush()
+	}
+	return chunks
+}
+
+// MarkdownChunker splits markdown documents into sections, at headings of level up to MaxLevel
+// (all levels if zero). Sections longer than MaxSize are split into sentences, repeating the
+// heading of the section at the top of each chunk. Headings inside fenced code blocks are ignored.
+type MarkdownChunker struct {
+	MaxSize  int
+	MaxLevel int
+}
+
+func (c MarkdownChunker) Chunk(text string) []string {
+	size := chunkSize(c.MaxSize)
+
+	var chunks []string
+	for _, section := range c.sections(text) {
+		body := strings.TrimSpace(section.body)
+		if body == "" && section.heading == "" {
+			continue
+		}
+
+		whole := strings.TrimSpace(section.heading + "\n\n" + body)
+		if len([]rune(whole)) <= size {
+			chunks = append(chunks, whole)
+			continue
+		}
+
+		// Leave room for the heading repeated in each chunk
+		bodySize := max(size-len([]rune(section.heading))-2, size/2)
+		for _, chunk := range (SentenceChunker{MaxSize: bodySize}).Chunk(body) {
+			chunks = append(chunks, strings.TrimSpace(section.heading+"\n\n"+chunk))
+		}
+	}
+	return chunks
+}
+
+type markdownSection struct {
+	heading string
+	body    string
+}
+
+func (c MarkdownChunker) sections(text string) []markdownSection {
+	var (
+		sections []markdownSection
+		current  markdownSection
+		body     strings.Builder
+		fence    string // Marker of the open code block, if any
+	)
+
+	for _, line := range strings.SplitAfter(text, "\n") {
+		trimmed := strings.TrimSpace(line)
+
+		switch {
+		case fence != "":
+			if strings.HasPrefix(trimmed, fence) {
+				fence = ""
+			}
+		case strings.HasPrefix(trimmed, "```") || strings.HasPrefix(trimmed, "~~~"):
+			fence = trimmed[:3]
+		case c.isHeading(trimmed):
+			current.body = body.String()
+			sections = append(sections, current)
+
+			current = markdownSection{heading: trimmed}
+			body.Reset()
+			continue
+		}
+		body.WriteString(line)
+	}
+
+	current.body = body.String()
+	return append(sections, current)
+}
+
+func (c MarkdownChunker) isHeading(line string) bool {
+	level := 0
+	for level < len(line) && line[level] == '#' {
+		level++
+	}
+
+	if level == 0 || level > 6 || (c.MaxLevel > 0 && level > c.MaxLevel) {
+		return false
+	}
+	return level == len(line) || line[level] == ' '
+}
+
+func chunkSize(size int) int {
+	if size <= 0 {
+		return DefaultChunkSize
+	}
+	return size
+}
+
+// splitParagraphs splits text at blank lines.
+func splitParagraphs(text string) []string {
+	var (
+		paragraphs []string
+		current    []string
+	)
+
+	for _, line := range strings.Split(text, "\n") {
+		if strings.TrimSpace(line) == "" {
+			if len(current) > 0 {
+				paragraphs = append(paragraphs, strings.Join(current, " "))
+				current = nil
+			}
+			continue
+		}
+		current = append(current, strings.TrimSpace(line))
+	}
+
+	if len(current) > 0 {
+		paragraphs = append(paragraphs, strings.Join(current, " "))
+	}
+	return paragraphs
+}
+
+// splitSentences splits text after sentence terminators followed by a space.
+func splitSentences(text string) []string {
+	var sentences []string
+
+	runes := []rune(text)
+	start := 0
+	for i, r := range runes {
+		if (r == '.' || r == '!' || r == '?') && i+1 < len(runes) && unicode.IsSpace(runes[i+1]) {
+			if s := strings.TrimSpace(string(runes[start : i+1])); s != "" {
+				sentences = append(sentences, s)
+			}
+			start = i + 1
+		}
+	}
+
+	if s := strings.TrimSpace(string(runes[start:])); s != "" {
+		sentences = append(sentences, s)
+	}
+	return sentences
+}
@@ -0,0 +1,107 @@
+// Copyright (c) 2025 Suricata Contributors
+// Original Author: Stefano Scafiti
+//
+// This file is part of Suricata: Type-Safe AI Agents for Go.
+//
+// Licensed under the MIT License. You may obtain a copy of the License at
+//
+//	https://opensource.org/licenses/MIT
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package retrieval_test
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"reflect"
+	"testing"
+
+	"github.com/ostafen/suricata/runtime"
+	"github.com/ostafen/suricata/runtime/retrieval"
+)
+
+func TestChunkers(t *testing.T) {
+	tests := []struct {
+		name     string
+		chunker  retrieval.Chunker
+		text     string
+		expected []string
+	}{
+		{
+			name:     "fixed with overlap",
+			chunker:  retrieval.FixedChunker{Size: 4, Overlap: 1},
+			text:     "abcdefghij",
+			expected: []string{"abcd", "defg", "ghij"},
+		},
+		{
+			name:     "sentences",
+			chunker:  retrieval.SentenceChunker{MaxSize: 30},
+			text:     "One sentence. Another one! A third?\n\nNew paragraph. Averyveryveryverylongwordwithoutspaces.",
+			expected: []string{"One sentence. Another one!", "A third?", "New paragraph.", "Averyveryveryverylongwordwitho", "utspaces."},
+		},
+		{
+			name:    "markdown",
+			chunker: retrieval.MarkdownChunker{MaxSize: 45},
+			text:    "Intro.\n\n# Install\n\nRun it.\n\n```sh\n# not a heading\n```\n\n## Usage\n\nFirst step is long. Second step is long.\n",
+			expected: []string{
+				"Intro.",
+				"# Install\n\nRun it.\n\n```sh\n# not a heading\n```",
+				"## Usage\n\nFirst step is long.",
+				"## Usage\n\nSecond step is long.",
+			},
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			if got := test.chunker.Chunk(test.text); !reflect.DeepEqual(got, test.expected) {
+				t.Errorf("expected %q, got %q", test.expected, got)
+			}
+		})
+	}
+}
+
+func TestIngestor_IngestFiles(t *testing.T) {
+	dir := t.TempDir()
+
+	path := filepath.Join(dir, "faq.md")
+	if err := os.WriteFile(path, []byte("# Flights\n\nFlight changes are free.\n\n# Hotels\n\nHotel refunds take 5 days.\n"), 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	var calls int
+	embedder := runtime.EmbedderFunc(func(ctx context.Context, texts []string) ([][]float32, error) {
+		calls++
+		return keywordEmbedder(ctx, texts)
+	})
+
+	store := retrieval.NewInMemoryStore()
+	r := retrieval.NewRetriever(embedder, store)
+
+	n, err := retrieval.NewIngestor(r, retrieval.WithBatchSize(1)).IngestFiles(context.Background(), path)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if n != 2 || calls != 2 {
+		t.Fatalf("expected 2 chunks embedded one at a time, got %d chunks in %d calls", n, calls)
+	}
+
+	matches, err := r.Search(context.Background(), "hotel refund", 1)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	expected := map[string]string{"source": path, "chunk": "1"}
+	if len(matches) != 1 || matches[0].ID != path+"#1" || !reflect.DeepEqual(matches[0].Metadata, expected) {
+		t.Errorf("unexpected matches: %+v", matches)
+	}
+	if matches[0].Text != "# Hotels\n\nHotel refunds take 5 days." {
+		t.Errorf("unexpected chunk %q", matches[0].Text)
+	}
+}
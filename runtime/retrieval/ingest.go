@@ -0,0 +1,148 @@
+// Copyright (c) 2025 Suricata Contributors
+// Original Author: Stefano Scafiti
+//
+// This file is part of Suricata: Type-Safe AI Agents for Go.
+//
+// Licensed under the MIT License. You may obtain a copy of the License at
+//
+//	https://opensource.org/licenses/MIT
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package retrieval
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"maps"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+)
+
+// DefaultBatchSize is the number of chunks embedded by a single call to the embedder.
+const DefaultBatchSize = 64
+
+// Ingestor splits documents into chunks and indexes them through a Retriever.
+type Ingestor struct {
+	retriever *Retriever
+	chunker   Chunker
+	batchSize int
+}
+
+// IngestorOption configures optional Ingestor features.
+type IngestorOption func(in *Ingestor)
+
+// WithChunker sets the strategy splitting documents into chunks. The default is a SentenceChunker.
+func WithChunker(chunker Chunker) IngestorOption {
+	return func(in *Ingestor) {
+		in.chunker = chunker
+	}
+}
+
+// WithBatchSize sets the number of chunks embedded by a single call to the embedder.
+func WithBatchSize(n int) IngestorOption {
+	return func(in *Ingestor) {
+		in.batchSize = n
+	}
+}
+
+func NewIngestor(retriever *Retriever, opts ...IngestorOption) *Ingestor {
+	in := &Ingestor{
+		retriever: retriever,
+		chunker:   SentenceChunker{},
+		batchSize: DefaultBatchSize,
+	}
+
+	for _, opt := range opts {
+		opt(in)
+	}
+	return in
+}
+
+// Ingest reads a document from r and indexes its chunks, returning their number.
+// Chunks are identified by source and their position, so ingesting a source again replaces
+// its chunks; trailing chunks of a previous, longer version of the document are left in place.
+// Each chunk carries the given metadata, along with the "source" and "chunk" keys.
+func (in *Ingestor) Ingest(ctx context.Context, source string, r io.Reader, metadata map[string]string) (int, error) {
+	data, err := io.ReadAll(r)
+	if err != nil {
+		return 0, fmt.Errorf("read %s: %w", source, err)
+	}
+
+	chunks := in.chunker.Chunk(string(data))
+
+	docs := make([]Document, len(chunks))
+	for i, chunk := range chunks {
+		meta := maps.Clone(metadata)
+		if meta == nil {
+			meta = make(map[string]string, 2)
+		}
+		meta["source"] = source
+		meta["chunk"] = strconv.Itoa(i)
+
+		docs[i] = Document{
+			ID:       source + "#" + strconv.Itoa(i),
+			Text:     chunk,
+			Metadata: meta,
+		}
+	}
+
+	batchSize := in.batchSize
+	if batchSize <= 0 {
+		batchSize = DefaultBatchSize
+	}
+
+	for start := 0; start < len(docs); start += batchSize {
+		batch := docs[start:min(start+batchSize, len(docs))]
+		if err := in.retriever.Index(ctx, batch...); err != nil {
+			return start, fmt.Errorf("index %s: %w", source, err)
+		}
+	}
+	return len(docs), nil
+}
+
+// IngestFiles ingests the given files, using their path as source. Markdown files
+// (.md, .markdown) are split with a MarkdownChunker if the ingestor uses the default chunker.
+// It returns the total number of indexed chunks.
+func (in *Ingestor) IngestFiles(ctx context.Context, paths ...string) (int, error) {
+	total := 0
+	for _, path := range paths {
+		n, err := in.ingestFile(ctx, path)
+		total += n
+		if err != nil {
+			return total, err
+		}
+	}
+	return total, nil
+}
+
+func (in *Ingestor) ingestFile(ctx context.Context, path string) (int, error) {
+	f, err := os.Open(path)
+	if err != nil {
+		return 0, err
+	}
+	defer f.Close()
+
+	ingestor := in
+	if sc, ok := in.chunker.(SentenceChunker); ok && isMarkdown(path) {
+		md := *in
+		md.chunker = MarkdownChunker{MaxSize: sc.MaxSize}
+		ingestor = &md
+	}
+	return ingestor.Ingest(ctx, path, f, nil)
+}
+
+func isMarkdown(path string) bool {
+	switch strings.ToLower(filepath.Ext(path)) {
+	case ".md", ".markdown":
+		return true
+	}
+	return false
+}
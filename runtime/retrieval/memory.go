@@ -0,0 +1,86 @@
+// Copyright (c) 2025 Suricata Contributors
+// Original Author: Stefano Scafiti
+//
+// This file is part of Suricata: Type-Safe AI Agents for Go.
+//
+// Licensed under the MIT License. You may obtain a copy of the License at
+//
+//	https://opensource.org/licenses/MIT
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package retrieval
+
+import (
+	"cmp"
+	"context"
+	"math"
+	"slices"
+	"sync"
+)
+
+// InMemoryStore is a VectorStore scanning all its documents on each query.
+// It suits tests and corpora of a few thousand documents.
+type InMemoryStore struct {
+	mtx  sync.RWMutex
+	docs map[string]Document
+}
+
+func NewInMemoryStore() *InMemoryStore {
+	return &InMemoryStore{docs: make(map[string]Document)}
+}
+
+func (s *InMemoryStore) Upsert(_ context.Context, docs ...Document) error {
+	s.mtx.Lock()
+	defer s.mtx.Unlock()
+
+	for _, doc := range docs {
+		s.docs[doc.ID] = doc
+	}
+	return nil
+}
+
+func (s *InMemoryStore) Query(_ context.Context, vector []float32, k int) ([]Match, error) {
+	s.mtx.RLock()
+	defer s.mtx.RUnlock()
+
+	matches := make([]Match, 0, len(s.docs))
+	for _, doc := range s.docs {
+		matches = append(matches, Match{Document: doc, Score: CosineSimilarity(vector, doc.Vector)})
+	}
+
+	slices.SortFunc(matches, func(a, b Match) int {
+		if c := cmp.Compare(b.Score, a.Score); c != 0 {
+			return c
+		}
+		return cmp.Compare(a.ID, b.ID)
+	})
+
+	if len(matches) > k {
+		matches = matches[:k]
+	}
+	return matches, nil
+}
+
+// CosineSimilarity returns the cosine of the angle between a and b, or 0 if their lengths differ.
+func CosineSimilarity(a, b []float32) float32 {
+	if len(a) != len(b) || len(a) == 0 {
+		return 0
+	}
+
+	var dot, normA, normB float64
+	for i := range a {
+		dot += float64(a[i]) * float64(b[i])
+		normA += float64(a[i]) * float64(a[i])
+		normB += float64(b[i]) * float64(b[i])
+	}
+
+	if normA == 0 || normB == 0 {
+		return 0
+	}
+	return float32(dot / (math.Sqrt(normA) * math.Sqrt(normB)))
+}
@@ -0,0 +1,129 @@
+// Copyright (c) 2025 Suricata Contributors
+// Original Author: Stefano Scafiti
+//
+// This file is part of Suricata: Type-Safe AI Agents for Go.
+//
+// Licensed under the MIT License. You may obtain a copy of the License at
+//
+//	https://opensource.org/licenses/MIT
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+// Package pgvector provides a retrieval.VectorStore backed by PostgreSQL with the pgvector extension.
+//
+// The package only depends on database/sql: callers open the database with the
+// PostgreSQL driver of their choice (e.g. github.com/jackc/pgx/v5/stdlib or github.com/lib/pq).
+package pgvector
+
+import (
+	"context"
+	"database/sql"
+	"encoding/json"
+	"fmt"
+	"strconv"
+	"strings"
+
+	"github.com/ostafen/suricata/runtime/retrieval"
+)
+
+const DefaultTable = "suricata_documents"
+
+// Store keeps documents in a table with a vector column of fixed dimension.
+type Store struct {
+	db    *sql.DB
+	table string
+}
+
+// NewStore returns a store backed by the given table, creating the extension, the table
+// and its cosine distance index if needed. dim is the length of the vectors of the embedder.
+func NewStore(ctx context.Context, db *sql.DB, table string, dim int) (*Store, error) {
+	if _, err := db.ExecContext(ctx, `CREATE EXTENSION IF NOT EXISTS vector`); err != nil {
+		return nil, fmt.Errorf("create extension: %w", err)
+	}
+
+	_, err := db.ExecContext(ctx, fmt.Sprintf(`CREATE TABLE IF NOT EXISTS %s (
+		id TEXT PRIMARY KEY,
+		text TEXT NOT NULL,
+		metadata JSONB NOT NULL DEFAULT '{}',
+		embedding vector(%d) NOT NULL
+	)`, table, dim))
+	if err != nil {
+		return nil, fmt.Errorf("create table: %w", err)
+	}
+
+	_, err = db.ExecContext(ctx, fmt.Sprintf(`CREATE INDEX IF NOT EXISTS %s_embedding_idx ON %s USING hnsw (embedding vector_cosine_ops)`, table, table))
+	if err != nil {
+		return nil, fmt.Errorf("create index: %w", err)
+	}
+
+	return &Store{
+		db:    db,
+		table: table,
+	}, nil
+}
+
+func (s *Store) Upsert(ctx context.Context, docs ...retrieval.Document) error {
+	tx, err := s.db.BeginTx(ctx, nil)
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback()
+
+	query := fmt.Sprintf(`INSERT INTO %s (id, text, metadata, embedding) VALUES ($1, $2, $3, $4::vector)
+		ON CONFLICT (id) DO UPDATE SET text = EXCLUDED.text, metadata = EXCLUDED.metadata, embedding = EXCLUDED.embedding`, s.table)
+
+	for _, doc := range docs {
+		metadata, err := json.Marshal(doc.Metadata)
+		if err != nil {
+			return err
+		}
+		if doc.Metadata == nil {
+			metadata = []byte("{}")
+		}
+
+		if _, err := tx.ExecContext(ctx, query, doc.ID, doc.Text, string(metadata), formatVector(doc.Vector)); err != nil {
+			return fmt.Errorf("upsert document: %w", err)
+		}
+	}
+	return tx.Commit()
+}
+
+func (s *Store) Query(ctx context.Context, vector []float32, k int) ([]retrieval.Match, error) {
+	query := fmt.Sprintf(`SELECT id, text, metadata, 1 - (embedding <=> $1::vector) AS score
+		FROM %s ORDER BY embedding <=> $1::vector LIMIT $2`, s.table)
+
+	rows, err := s.db.QueryContext(ctx, query, formatVector(vector), k)
+	if err != nil {
+		return nil, fmt.Errorf("query documents: %w", err)
+	}
+	defer rows.Close()
+
+	var matches []retrieval.Match
+	for rows.Next() {
+		var (
+			m        retrieval.Match
+			metadata []byte
+		)
+		if err := rows.Scan(&m.ID, &m.Text, &metadata, &m.Score); err != nil {
+			return nil, err
+		}
+		if err := json.Unmarshal(metadata, &m.Metadata); err != nil {
+			return nil, fmt.Errorf("decode metadata: %w", err)
+		}
+		matches = append(matches, m)
+	}
+	return matches, rows.Err()
+}
+
+// formatVector returns the text representation of a vector, e.g. "[1,2.5,3]".
+func formatVector(v []float32) string {
+	parts := make([]string, len(v))
+	for i, x := range v {
+		parts[i] = strconv.FormatFloat(float64(x), 'f', -1, 32)
+	}
+	return "[" + strings.Join(parts, ",") + "]"
+}
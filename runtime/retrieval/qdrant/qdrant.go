@@ -0,0 +1,144 @@
+// Copyright (c) 2025 Suricata Contributors
+// Original Author: Stefano Scafiti
+//
+// This file is part of Suricata: Type-Safe AI Agents for Go.
+//
+// Licensed under the MIT License. You may obtain a copy of the License at
+//
+//	https://opensource.org/licenses/MIT
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+// Package qdrant provides a retrieval.VectorStore backed by a Qdrant collection, through its REST API.
+package qdrant
+
+import (
+	"bytes"
+	"context"
+	"crypto/sha1"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+
+	"github.com/ostafen/suricata/runtime/retrieval"
+)
+
+const DefaultBaseURL = "http://localhost:6333"
+
+// Store keeps documents as the points of a collection. Qdrant only accepts integer or UUID
+// point IDs, so points are identified by a UUID derived from the document ID, which is
+// kept in the payload along with the text and the metadata of the document.
+type Store struct {
+	baseURL    string
+	apiKey     string
+	collection string
+}
+
+// NewStore returns a store for the given collection. The apiKey may be empty for local instances.
+func NewStore(baseURL, apiKey, collection string) *Store {
+	return &Store{
+		baseURL:    baseURL,
+		apiKey:     apiKey,
+		collection: collection,
+	}
+}
+
+// CreateCollection creates the collection, for vectors of dimension dim compared by cosine similarity.
+func (s *Store) CreateCollection(ctx context.Context, dim int) error {
+	return s.do(ctx, http.MethodPut, "", map[string]any{
+		"vectors": map[string]any{"size": dim, "distance": "Cosine"},
+	}, nil)
+}
+
+type payload struct {
+	ID       string            `json:"id"`
+	Text     string            `json:"text"`
+	Metadata map[string]string `json:"metadata,omitempty"`
+}
+
+func (s *Store) Upsert(ctx context.Context, docs ...retrieval.Document) error {
+	points := make([]map[string]any, len(docs))
+	for i, doc := range docs {
+		points[i] = map[string]any{
+			"id":      pointID(doc.ID),
+			"vector":  doc.Vector,
+			"payload": payload{ID: doc.ID, Text: doc.Text, Metadata: doc.Metadata},
+		}
+	}
+	return s.do(ctx, http.MethodPut, "/points?wait=true", map[string]any{"points": points}, nil)
+}
+
+func (s *Store) Query(ctx context.Context, vector []float32, k int) ([]retrieval.Match, error) {
+	var resp struct {
+		Result []struct {
+			Score   float32 `json:"score"`
+			Payload payload `json:"payload"`
+		} `json:"result"`
+	}
+
+	err := s.do(ctx, http.MethodPost, "/points/search", map[string]any{
+		"vector":       vector,
+		"limit":        k,
+		"with_payload": true,
+	}, &resp)
+	if err != nil {
+		return nil, err
+	}
+
+	matches := make([]retrieval.Match, len(resp.Result))
+	for i, r := range resp.Result {
+		matches[i] = retrieval.Match{
+			Document: retrieval.Document{ID: r.Payload.ID, Text: r.Payload.Text, Metadata: r.Payload.Metadata},
+			Score:    r.Score,
+		}
+	}
+	return matches, nil
+}
+
+// do sends a request to the collection endpoint, decoding the response into out, if not nil.
+func (s *Store) do(ctx context.Context, method, path string, body, out any) error {
+	data, err := json.Marshal(body)
+	if err != nil {
+		return err
+	}
+
+	endpoint := fmt.Sprintf("%s/collections/%s%s", s.baseURL, url.PathEscape(s.collection), path)
+	req, err := http.NewRequestWithContext(ctx, method, endpoint, bytes.NewBuffer(data))
+	if err != nil {
+		return err
+	}
+	req.Header.Set("Content-Type", "application/json")
+	if s.apiKey != "" {
+		req.Header.Set("api-key", s.apiKey)
+	}
+
+	resp, err := http.DefaultClient.Do(req)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != 200 {
+		body, _ := io.ReadAll(resp.Body)
+		return fmt.Errorf("qdrant error: %s", string(body))
+	}
+
+	if out == nil {
+		return nil
+	}
+	return json.NewDecoder(resp.Body).Decode(out)
+}
+
+// pointID derives a name-based (version 5 style) UUID from a document ID.
+func pointID(id string) string {
+	h := sha1.Sum([]byte(id))
+	h[6] = (h[6] & 0x0f) | 0x50
+	h[8] = (h[8] & 0x3f) | 0x80
+	return fmt.Sprintf("%x-%x-%x-%x-%x", h[0:4], h[4:6], h[6:8], h[8:10], h[10:16])
+}
@@ -0,0 +1,113 @@
+// Copyright (c) 2025 Suricata Contributors
+// Original Author: Stefano Scafiti
+//
+// This file is part of Suricata: Type-Safe AI Agents for Go.
+//
+// Licensed under the MIT License. You may obtain a copy of the License at
+//
+//	https://opensource.org/licenses/MIT
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+// Package retrieval implements retrieval-augmented generation: documents are embedded
+// and indexed in a VectorStore, and agents search them through a builtin tool.
+package retrieval
+
+import (
+	"context"
+	"errors"
+	"fmt"
+
+	"github.com/ostafen/suricata/runtime"
+)
+
+// DefaultLimit is the number of documents returned by searches which do not set a limit.
+const DefaultLimit = 5
+
+// Document is a piece of text indexed for retrieval.
+type Document struct {
+	ID       string
+	Text     string
+	Metadata map[string]string
+	Vector   []float32 // Embedding of Text
+}
+
+// Match is a document returned by a query, along with its similarity to the query.
+type Match struct {
+	Document
+	Score float32 // Cosine similarity: higher is closer
+}
+
+// VectorStore indexes documents by their embedding.
+type VectorStore interface {
+	// Upsert indexes the documents, replacing the ones with the same ID.
+	Upsert(ctx context.Context, docs ...Document) error
+	// Query returns the k documents closest to vector, by decreasing similarity.
+	Query(ctx context.Context, vector []float32, k int) ([]Match, error)
+}
+
+// Retriever embeds documents and queries with an Embedder, and indexes them in a VectorStore.
+type Retriever struct {
+	embedder runtime.Embedder
+	store    VectorStore
+}
+
+func NewRetriever(embedder runtime.Embedder, store VectorStore) *Retriever {
+	return &Retriever{
+		embedder: embedder,
+		store:    store,
+	}
+}
+
+// Index embeds the documents whose vector is missing, then upserts all of them.
+func (r *Retriever) Index(ctx context.Context, docs ...Document) error {
+	var (
+		texts   []string
+		indices []int
+	)
+	for i, doc := range docs {
+		if doc.ID == "" {
+			return errors.New("document has empty ID")
+		}
+		if doc.Vector == nil {
+			texts = append(texts, doc.Text)
+			indices = append(indices, i)
+		}
+	}
+
+	if len(texts) > 0 {
+		vectors, err := r.embedder.Embed(ctx, texts)
+		if err != nil {
+			return fmt.Errorf("embed documents: %w", err)
+		}
+		if len(vectors) != len(texts) {
+			return fmt.Errorf("embed documents: expected %d vectors, got %d", len(texts), len(vectors))
+		}
+
+		docs = append([]Document(nil), docs...)
+		for i, idx := range indices {
+			docs[idx].Vector = vectors[i]
+		}
+	}
+	return r.store.Upsert(ctx, docs...)
+}
+
+// Search returns the documents most similar to query. A non-positive limit means DefaultLimit.
+func (r *Retriever) Search(ctx context.Context, query string, limit int) ([]Match, error) {
+	if limit <= 0 {
+		limit = DefaultLimit
+	}
+
+	vectors, err := r.embedder.Embed(ctx, []string{query})
+	if err != nil {
+		return nil, fmt.Errorf("embed query: %w", err)
+	}
+	if len(vectors) != 1 {
+		return nil, fmt.Errorf("embed query: expected 1 vector, got %d", len(vectors))
+	}
+	return r.store.Query(ctx, vectors[0], limit)
+}
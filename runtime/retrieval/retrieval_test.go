@@ -0,0 +1,104 @@
+// Copyright (c) 2025 Suricata Contributors
+// Original Author: Stefano Scafiti
+//
+// This file is part of Suricata: Type-Safe AI Agents for Go.
+//
+// Licensed under the MIT License. You may obtain a copy of the License at
+//
+//	https://opensource.org/licenses/MIT
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package retrieval_test
+
+import (
+	"context"
+	"strings"
+	"testing"
+
+	"github.com/ostafen/suricata/runtime"
+	"github.com/ostafen/suricata/runtime/retrieval"
+)
+
+// keywordEmbedder maps texts to vectors counting the occurrences of a fixed set of keywords.
+var keywordEmbedder = runtime.EmbedderFunc(func(ctx context.Context, texts []string) ([][]float32, error) {
+	keywords := []string{"flight", "hotel", "refund"}
+
+	vectors := make([][]float32, len(texts))
+	for i, text := range texts {
+		vectors[i] = make([]float32, len(keywords))
+		for j, kw := range keywords {
+			vectors[i][j] = float32(strings.Count(strings.ToLower(text), kw))
+		}
+	}
+	return vectors, nil
+})
+
+func newRetriever(t *testing.T) *retrieval.Retriever {
+	t.Helper()
+
+	r := retrieval.NewRetriever(keywordEmbedder, retrieval.NewInMemoryStore())
+	err := r.Index(context.Background(),
+		retrieval.Document{ID: "flights", Text: "Flight changes are free up to 24 hours before the flight."},
+		retrieval.Document{ID: "hotels", Text: "Hotel bookings can be cancelled.", Metadata: map[string]string{"source": "faq.md"}},
+		retrieval.Document{ID: "refunds", Text: "Refunds of a flight or a hotel take 5 days."},
+	)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return r
+}
+
+func TestRetriever_Search(t *testing.T) {
+	r := newRetriever(t)
+
+	matches, err := r.Search(context.Background(), "how do I cancel my hotel?", 2)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	if len(matches) != 2 || matches[0].ID != "hotels" || matches[1].ID != "refunds" {
+		t.Fatalf("unexpected matches: %+v", matches)
+	}
+	if matches[0].Score < 0.99 || matches[0].Metadata["source"] != "faq.md" {
+		t.Errorf("unexpected best match: %+v", matches[0])
+	}
+}
+
+func TestTool(t *testing.T) {
+	// Types generated for the messages of a builtin retrieval tool
+	type (
+		RetrievalQuery struct {
+			Query string `json:"query"`
+			Limit *int   `json:"limit,omitempty"`
+		}
+		RetrievalResult struct {
+			Id       string            `json:"id"`
+			Text     string            `json:"text"`
+			Score    float64           `json:"score"`
+			Metadata map[string]string `json:"metadata,omitempty"`
+		}
+		RetrievalResults struct {
+			Results []RetrievalResult `json:"results,omitempty"`
+		}
+	)
+
+	search := retrieval.Tool[RetrievalQuery, RetrievalResults](newRetriever(t))
+
+	limit := 1
+	out, err := search(context.Background(), &RetrievalQuery{Query: "flight refund", Limit: &limit})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if len(out.Results) != 1 || out.Results[0].Id != "refunds" {
+		t.Errorf("unexpected results: %+v", out.Results)
+	}
+
+	if _, err := search(context.Background(), &RetrievalQuery{}); err != nil {
+		t.Errorf("unexpected error for empty query: %v", err)
+	}
+}
@@ -0,0 +1,118 @@
+// Copyright (c) 2025 Suricata Contributors
+// Original Author: Stefano Scafiti
+//
+// This file is part of Suricata: Type-Safe AI Agents for Go.
+//
+// Licensed under the MIT License. You may obtain a copy of the License at
+//
+//	https://opensource.org/licenses/MIT
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package retrieval
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+
+	"github.com/ostafen/suricata/runtime"
+)
+
+// SearchInput is the input of the builtin retrieval tool.
+// It mirrors the RetrievalQuery message added to specs declaring a tool with "builtin: retrieval".
+type SearchInput struct {
+	Query string `json:"query"`
+	Limit int    `json:"limit,omitempty"`
+}
+
+// SearchOutput is the output of the builtin retrieval tool, mirroring the RetrievalResults message.
+type SearchOutput struct {
+	Results []Result `json:"results"`
+}
+
+// Result is a document found by the builtin retrieval tool, mirroring the RetrievalResult message.
+type Result struct {
+	ID       string            `json:"id"`
+	Text     string            `json:"text"`
+	Score    float32           `json:"score"`
+	Metadata map[string]string `json:"metadata,omitempty"`
+}
+
+// SearchSchema is the JSON schema of SearchInput.
+var SearchSchema = runtime.NewSchema(`{
+	"type": "object",
+	"properties": {
+		"query": {"type": "string", "description": "Text to search for"},
+		"limit": {"type": "integer", "minimum": 1, "description": "Maximum number of documents to return"}
+	},
+	"required": ["query"],
+	"additionalProperties": false
+}`)
+
+// ToolSpec returns the spec of a tool searching indexed documents, to be handled with SearchTool.
+func ToolSpec(name, description string) runtime.ToolSpec {
+	if description == "" {
+		description = "Searches the indexed documents relevant to a query"
+	}
+	return runtime.ToolSpec{Name: name, Description: description, Schema: SearchSchema}
+}
+
+// SearchTool runs the builtin retrieval tool.
+func (r *Retriever) SearchTool(ctx context.Context, in *SearchInput) (*SearchOutput, error) {
+	matches, err := r.Search(ctx, in.Query, in.Limit)
+	if err != nil {
+		return nil, err
+	}
+
+	out := &SearchOutput{Results: make([]Result, len(matches))}
+	for i, m := range matches {
+		out.Results[i] = Result{ID: m.ID, Text: m.Text, Score: m.Score, Metadata: m.Metadata}
+	}
+	return out, nil
+}
+
+// JSONTool adapts the builtin retrieval tool to tools exchanging raw JSON,
+// such as the ones served by pkg/host.
+func (r *Retriever) JSONTool() func(ctx context.Context, args json.RawMessage) (json.RawMessage, error) {
+	return func(ctx context.Context, args json.RawMessage) (json.RawMessage, error) {
+		var in SearchInput
+		if err := runtime.UnmarshalValidate(args, &in, SearchSchema); err != nil {
+			return nil, err
+		}
+
+		out, err := r.SearchTool(ctx, &in)
+		if err != nil {
+			return nil, err
+		}
+		return json.Marshal(out)
+	}
+}
+
+// Tool adapts the builtin retrieval tool to the method generated for it in the tools interface of an agent,
+// whose In and Out types are the RetrievalQuery and RetrievalResults messages of the spec.
+func Tool[In, Out any](r *Retriever) func(ctx context.Context, in *In) (*Out, error) {
+	tool := r.JSONTool()
+
+	return func(ctx context.Context, in *In) (*Out, error) {
+		args, err := json.Marshal(in)
+		if err != nil {
+			return nil, fmt.Errorf("marshal retrieval query: %w", err)
+		}
+
+		res, err := tool(ctx, args)
+		if err != nil {
+			return nil, err
+		}
+
+		var out Out
+		if err := json.Unmarshal(res, &out); err != nil {
+			return nil, fmt.Errorf("unmarshal retrieval results: %w", err)
+		}
+		return &out, nil
+	}
+}
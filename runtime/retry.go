@@ -0,0 +1,141 @@
+// Copyright (c) 2025 Suricata Contributors
+// Original Author: Stefano Scafiti
+//
+// This file is part of Suricata: Type-Safe AI Agents for Go.
+//
+// Licensed under the MIT License. You may obtain a copy of the License at
+//
+//	https://opensource.org/licenses/MIT
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package runtime
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strings"
+	"time"
+)
+
+// RetryPolicy controls how the runtime recovers from malformed or schema-invalid
+// model output. Instead of failing immediately, the validation error is sent back
+// to the model as a corrective message and the model is asked to try again.
+type RetryPolicy struct {
+	MaxAttempts int           // Total number of attempts, including the first one. Values <= 1 disable retries.
+	Backoff     time.Duration // Delay before each corrective attempt.
+}
+
+// DefaultRetryPolicy returns a policy suitable for small local models.
+func DefaultRetryPolicy() RetryPolicy {
+	return RetryPolicy{
+		MaxAttempts: 3,
+		Backoff:     0,
+	}
+}
+
+// WithRetryPolicy applies policy to requests which do not set their own,
+// so that invalid outputs are sent back to the model for correction.
+func WithRetryPolicy(policy RetryPolicy) Option {
+	return func(r *Runtime) {
+		r.retry = policy
+	}
+}
+
+func (r *Runtime) retryOf(req *Request) RetryPolicy {
+	if req.Retry.MaxAttempts == 0 {
+		return r.retry
+	}
+	return req.Retry
+}
+
+func (p *RetryPolicy) canRetry(failures int) bool {
+	return failures < p.MaxAttempts
+}
+
+func (p *RetryPolicy) wait(ctx context.Context) error {
+	if p.Backoff <= 0 {
+		return ctx.Err()
+	}
+
+	t := time.NewTimer(p.Backoff)
+	defer t.Stop()
+
+	select {
+	case <-ctx.Done():
+		return ctx.Err()
+	case <-t.C:
+		return nil
+	}
+}
+
+// repair asks the model to fix its previous response, reporting the error that made it invalid.
+// It returns cause unchanged once the request retry policy is exhausted.
+func (r *Runtime) repair(ctx context.Context, sess *ChatSession, req *Request, failures *int, cause error) (string, error) {
+	r.hooks.validationError(ctx, cause)
+	recording(ctx).add(TranscriptStep{Kind: StepValidationError, Error: cause.Error()})
+
+	// The correction asked by a review is a single extra round, outside of the retry policy
+	var critique *critiqueError
+	if !errors.As(cause, &critique) {
+		policy := r.retryOf(req)
+
+		*failures++
+		if !policy.canRetry(*failures) {
+			return "", cause
+		}
+
+		if err := policy.wait(ctx); err != nil {
+			return "", err
+		}
+	}
+
+	out, err := r.send(ctx, req, sess, Message{Role: RoleUser, Content: repairPrompt(cause, req.isTextOutput() && len(req.ToolSpecs) == 0)})
+	if err != nil {
+		return "", fmt.Errorf("invoke session for output repair: %w", err)
+	}
+	return out, nil
+}
+
+// repairPrompt reports cause to the model. Schema violations are listed one per line,
+// with the path of the offending field and the violated constraint. Text replies, which
+// have no output format, are asked for again as text.
+func repairPrompt(cause error, text bool) string {
+	var critique *critiqueError
+	if errors.As(cause, &critique) {
+		var sb strings.Builder
+		sb.WriteString("Your review found the following issues in your previous response:\n")
+		for _, issue := range critique.issues {
+			fmt.Fprintf(&sb, "- %s\n", issue)
+		}
+		sb.WriteString("\n" + repairInstructions("Fix them", text))
+		return sb.String()
+	}
+
+	var schemaErr *SchemaError
+	if !errors.As(cause, &schemaErr) || len(schemaErr.Violations) == 0 {
+		return fmt.Sprintf("Your previous response could not be accepted: %s.\n\n%s", cause, repairInstructions("Fix the problem", text))
+	}
+
+	var sb strings.Builder
+	sb.WriteString("Your previous response could not be accepted, as it does not match the output schema:\n")
+	for _, v := range schemaErr.Violations {
+		fmt.Fprintf(&sb, "- %s: %s (%s)\n", v.Field, v.Message, v.Rule)
+	}
+	sb.WriteString("\n" + repairInstructions("Fix these fields", text))
+	return sb.String()
+}
+
+// repairInstructions completes fix with the format of the corrected reply.
+func repairInstructions(fix string, text bool) string {
+	if text {
+		return fix + " and reply again with the corrected answer only, as plain text."
+	}
+	return fix + ` and reply again, following the OUTPUT FORMAT and GUIDELINES exactly.
+Return ONLY the corrected JSON object.`
+}
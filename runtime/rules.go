@@ -0,0 +1,348 @@
+// Copyright (c) 2025 Suricata Contributors
+// Original Author: Stefano Scafiti
+//
+// This file is part of Suricata: Type-Safe AI Agents for Go.
+//
+// Licensed under the MIT License. You may obtain a copy of the License at
+//
+//	https://opensource.org/licenses/MIT
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package runtime
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"regexp"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// ruleOperators are the comparisons supported by rules. Two-character operators come first,
+// so that ">=" is not taken for ">".
+var ruleOperators = []string{"==", "!=", ">=", "<=", ">", "<"}
+
+var rulePathRegexp = regexp.MustCompile(`^[A-Za-z_][A-Za-z0-9_]*(\.[A-Za-z_][A-Za-z0-9_]*)*$`)
+
+// Rule is an invariant relating the fields of an output, such as "check_out > check_in",
+// which JSON schemas cannot express. A rule compares two operands with one of ==, !=, <, <=, >
+// and >=: fields, named by their JSON path (e.g. "dates.end"), or literals, such as 10, "EUR"
+// or true. Numbers are compared as numbers, strings holding RFC 3339 timestamps as instants,
+// and other strings lexicographically. Rules with a missing or null operand hold.
+type Rule struct {
+	Expr  string
+	Left  RuleOperand
+	Op    string
+	Right RuleOperand
+	Scope string // JSON path of the values the rule applies to. Empty means the whole output.
+}
+
+// RuleOperand is a field or a literal compared by a rule.
+type RuleOperand struct {
+	Field string // JSON path of the field, if the operand is not a literal
+	Value any    // Literal: a float64, a string or a bool
+}
+
+// ParseRule parses an expression such as "check_out > check_in".
+func ParseRule(expr string) (Rule, error) {
+	left, op, right, ok := splitRule(expr)
+	if !ok {
+		return Rule{}, fmt.Errorf("rule %q: expected a comparison with one of %s", expr, strings.Join(ruleOperators, ", "))
+	}
+
+	l, err := parseRuleOperand(left)
+	if err != nil {
+		return Rule{}, fmt.Errorf("rule %q: %w", expr, err)
+	}
+	r, err := parseRuleOperand(right)
+	if err != nil {
+		return Rule{}, fmt.Errorf("rule %q: %w", expr, err)
+	}
+	if l.Field == "" && r.Field == "" {
+		return Rule{}, fmt.Errorf("rule %q: at least one operand must be a field", expr)
+	}
+	return Rule{Expr: strings.TrimSpace(expr), Left: l, Op: op, Right: r}, nil
+}
+
+// MustParseRule is like ParseRule, but panics if the expression cannot be parsed.
+func MustParseRule(expr string) Rule {
+	rule, err := ParseRule(expr)
+	if err != nil {
+		panic(err)
+	}
+	return rule
+}
+
+// At returns the rule applied to the values found at path, such as the elements of a list
+// of nested messages, instead of the whole output.
+func (r Rule) At(path string) Rule {
+	r.Scope = path
+	return r
+}
+
+// Fields returns the paths of the fields compared by the rule.
+func (r Rule) Fields() []string {
+	var fields []string
+	for _, op := range []RuleOperand{r.Left, r.Right} {
+		if op.Field != "" {
+			fields = append(fields, op.Field)
+		}
+	}
+	return fields
+}
+
+// splitRule splits expr around its comparison operator, skipping quoted literals.
+func splitRule(expr string) (left, op, right string, ok bool) {
+	var quote rune
+	for i, c := range expr {
+		switch {
+		case quote != 0:
+			if c == quote {
+				quote = 0
+			}
+		case c == '"' || c == '\'':
+			quote = c
+		default:
+			for _, op := range ruleOperators {
+				if strings.HasPrefix(expr[i:], op) {
+					return strings.TrimSpace(expr[:i]), op, strings.TrimSpace(expr[i+len(op):]), true
+				}
+			}
+		}
+	}
+	return "", "", "", false
+}
+
+func parseRuleOperand(s string) (RuleOperand, error) {
+	switch {
+	case s == "":
+		return RuleOperand{}, errors.New("missing operand")
+	case s == "true" || s == "false":
+		return RuleOperand{Value: s == "true"}, nil
+	case len(s) >= 2 && (s[0] == '"' || s[0] == '\'') && s[len(s)-1] == s[0]:
+		return RuleOperand{Value: s[1 : len(s)-1]}, nil
+	case rulePathRegexp.MatchString(s):
+		return RuleOperand{Field: s}, nil
+	}
+
+	if n, err := strconv.ParseFloat(s, 64); err == nil {
+		return RuleOperand{Value: n}, nil
+	}
+	return RuleOperand{}, fmt.Errorf("invalid operand %q", s)
+}
+
+// CheckRules returns a post-processor checking rules on the output. Violations reject the
+// output with a *SchemaError, so that the model is asked to repair it.
+func CheckRules(rules ...Rule) PostProcessor {
+	return PostProcessorFunc(func(ctx context.Context, output any) error {
+		data, err := json.Marshal(output)
+		if err != nil {
+			return fmt.Errorf("marshal output: %w", err)
+		}
+
+		var doc any
+		if err := json.Unmarshal(data, &doc); err != nil {
+			return fmt.Errorf("unmarshal output: %w", err)
+		}
+
+		var violations []Violation
+		for _, rule := range rules {
+			violations = append(violations, rule.check(doc)...)
+		}
+		if len(violations) > 0 {
+			return &SchemaError{Violations: violations}
+		}
+		return nil
+	})
+}
+
+// check evaluates the rule on each value of doc in its scope.
+func (r Rule) check(doc any) []Violation {
+	var violations []Violation
+	for _, scoped := range jsonValuesAt(doc, r.Scope, "") {
+		left, lok := r.Left.eval(scoped.value)
+		right, rok := r.Right.eval(scoped.value)
+		if !lok || !rok || compareRule(left, r.Op, right) {
+			continue
+		}
+
+		field := joinPath(scoped.path, r.Fields()[0])
+		violations = append(violations, Violation{
+			Field:   field,
+			Rule:    "rule",
+			Message: fmt.Sprintf("must satisfy %s, got %s", r.Expr, r.describe(scoped.value)),
+		})
+	}
+	return violations
+}
+
+// describe lists the values of the fields compared by the rule.
+func (r Rule) describe(v any) string {
+	var parts []string
+	for _, field := range r.Fields() {
+		value, _ := jsonValueAt(v, field)
+		data, _ := json.Marshal(value)
+		parts = append(parts, fmt.Sprintf("%s = %s", field, data))
+	}
+	return strings.Join(parts, ", ")
+}
+
+func (op RuleOperand) eval(v any) (any, bool) {
+	if op.Field == "" {
+		return op.Value, true
+	}
+	value, ok := jsonValueAt(v, op.Field)
+	return value, ok && value != nil
+}
+
+// compareRule reports whether left op right holds. Values of different kinds only satisfy "!=".
+// Strings holding decimals, such as the ones of Decimal fields, are compared as numbers.
+func compareRule(left any, op string, right any) bool {
+	if l, r, ok := decimalOperands(left, right); ok {
+		return holds(l.Cmp(r), op)
+	}
+
+	var cmp int
+	switch l := left.(type) {
+	case float64:
+		r, ok := right.(float64)
+		if !ok {
+			return op == "!="
+		}
+		cmp = compareOrdered(l, r)
+	case string:
+		r, ok := right.(string)
+		if !ok {
+			return op == "!="
+		}
+		lt, lerr := time.Parse(time.RFC3339, l)
+		rt, rerr := time.Parse(time.RFC3339, r)
+		if lerr == nil && rerr == nil {
+			cmp = lt.Compare(rt)
+		} else {
+			cmp = strings.Compare(l, r)
+		}
+	case bool:
+		r, ok := right.(bool)
+		if !ok || (op != "==" && op != "!=") {
+			return op == "!="
+		}
+		if l != r {
+			cmp = 1
+		}
+	default:
+		return op == "!="
+	}
+	return holds(cmp, op)
+}
+
+// holds reports whether the result of a comparison satisfies op.
+func holds(cmp int, op string) bool {
+	switch op {
+	case "==":
+		return cmp == 0
+	case "!=":
+		return cmp != 0
+	case "<":
+		return cmp < 0
+	case "<=":
+		return cmp <= 0
+	case ">":
+		return cmp > 0
+	}
+	return cmp >= 0
+}
+
+// decimalOperands converts the operands to decimals if at least one of them is a string
+// and both are numbers or strings holding decimals.
+func decimalOperands(left, right any) (Decimal, Decimal, bool) {
+	_, lstr := left.(string)
+	_, rstr := right.(string)
+	if !lstr && !rstr {
+		return Decimal{}, Decimal{}, false
+	}
+
+	l, lok := toDecimal(left)
+	r, rok := toDecimal(right)
+	return l, r, lok && rok
+}
+
+func toDecimal(v any) (Decimal, bool) {
+	var s string
+	switch v := v.(type) {
+	case float64:
+		s = strconv.FormatFloat(v, 'f', -1, 64)
+	case string:
+		s = v
+	default:
+		return Decimal{}, false
+	}
+	d, err := ParseDecimal(s)
+	return d, err == nil
+}
+
+func compareOrdered(a, b float64) int {
+	switch {
+	case a < b:
+		return -1
+	case a > b:
+		return 1
+	}
+	return 0
+}
+
+type scopedValue struct {
+	path  string
+	value any
+}
+
+// jsonValuesAt returns the values of the decoded JSON document v found at path, walking
+// lists element by element, together with their concrete path, such as "legs.1".
+func jsonValuesAt(v any, path, prefix string) []scopedValue {
+	if list, ok := v.([]any); ok {
+		var values []scopedValue
+		for i, elem := range list {
+			values = append(values, jsonValuesAt(elem, path, joinPath(prefix, strconv.Itoa(i)))...)
+		}
+		return values
+	}
+	if path == "" {
+		return []scopedValue{{path: prefix, value: v}}
+	}
+
+	head, rest, _ := strings.Cut(path, ".")
+	obj, ok := v.(map[string]any)
+	if !ok || obj[head] == nil {
+		return nil
+	}
+	return jsonValuesAt(obj[head], rest, joinPath(prefix, head))
+}
+
+// jsonValueAt returns the value of the decoded JSON document v at path.
+func jsonValueAt(v any, path string) (any, bool) {
+	for _, key := range strings.Split(path, ".") {
+		obj, ok := v.(map[string]any)
+		if !ok {
+			return nil, false
+		}
+		if v, ok = obj[key]; !ok {
+			return nil, false
+		}
+	}
+	return v, true
+}
+
+func joinPath(prefix, path string) string {
+	if prefix == "" {
+		return path
+	}
+	return prefix + "." + path
+}
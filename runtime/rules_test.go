@@ -0,0 +1,104 @@
+// Copyright (c) 2025 Suricata Contributors
+// Original Author: Stefano Scafiti
+//
+// This file is part of Suricata: Type-Safe AI Agents for Go.
+//
+// Licensed under the MIT License. You may obtain a copy of the License at
+//
+//	https://opensource.org/licenses/MIT
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package runtime_test
+
+import (
+	"context"
+	"errors"
+	"strings"
+	"testing"
+
+	"github.com/ostafen/suricata/runtime"
+)
+
+func TestParseRule(t *testing.T) {
+	rule, err := runtime.ParseRule(`dates.end >= dates.start`)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if rule.Left.Field != "dates.end" || rule.Op != ">=" || rule.Right.Field != "dates.start" {
+		t.Errorf("unexpected rule: %+v", rule)
+	}
+
+	rule, err = runtime.ParseRule(`currency != "a >= b"`)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if rule.Op != "!=" || rule.Right.Value != "a >= b" {
+		t.Errorf("unexpected rule: %+v", rule)
+	}
+
+	for _, expr := range []string{"price", "1 < 2", "price > ", "price > 1x", "price => 1"} {
+		if _, err := runtime.ParseRule(expr); err == nil {
+			t.Errorf("%q: expected error", expr)
+		}
+	}
+}
+
+func TestCheckRules(t *testing.T) {
+	type Stay struct {
+		CheckIn  string `json:"check_in"`
+		CheckOut string `json:"check_out"`
+	}
+	type Trip struct {
+		Start  string  `json:"start"`
+		End    string  `json:"end"`
+		Budget *int    `json:"budget"`
+		Stays  []Stay  `json:"stays"`
+		Price  float64 `json:"price"`
+	}
+
+	check := runtime.CheckRules(
+		runtime.MustParseRule("end >= start"),
+		runtime.MustParseRule("budget > 100"),
+		runtime.MustParseRule("price > 0"),
+		runtime.MustParseRule("check_out > check_in").At("stays"),
+	)
+
+	trip := &Trip{
+		Start: "2025-06-01T10:00:00+02:00",
+		End:   "2025-06-01T09:30:00Z",
+		Stays: []Stay{
+			{CheckIn: "2025-06-01", CheckOut: "2025-06-03"},
+			{CheckIn: "2025-06-05", CheckOut: "2025-06-07"},
+		},
+		Price: 12,
+	}
+	if err := check.Process(context.Background(), trip); err != nil {
+		t.Errorf("unexpected error: %v", err)
+	}
+
+	trip.End = "2025-06-01T07:30:00Z"
+	trip.Stays[1].CheckOut = "2025-06-04"
+	err := check.Process(context.Background(), trip)
+
+	var schemaErr *runtime.SchemaError
+	if !errors.As(err, &schemaErr) || !errors.Is(err, runtime.ErrInvalidOutput) {
+		t.Fatalf("expected a schema error, got %v", err)
+	}
+
+	var got []string
+	for _, v := range schemaErr.Violations {
+		got = append(got, v.String())
+	}
+	want := []string{
+		`end: must satisfy end >= start, got end = "2025-06-01T07:30:00Z", start = "2025-06-01T10:00:00+02:00"`,
+		`stays.1.check_out: must satisfy check_out > check_in, got check_out = "2025-06-04", check_in = "2025-06-05"`,
+	}
+	if strings.Join(got, "\n") != strings.Join(want, "\n") {
+		t.Errorf("unexpected violations:\n%s", strings.Join(got, "\n"))
+	}
+}
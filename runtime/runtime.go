@@ -21,13 +21,29 @@ import (
 	"encoding/json"
 	"errors"
 	"fmt"
-	"html/template"
 	"strings"
+	"sync"
+	"text/template"
+	"time"
 
 	"github.com/xeipuuv/gojsonschema"
 )
 
-var ErrInvalidOutput = errors.New("invalid output")
+var (
+	ErrInvalidOutput = errors.New("invalid output")
+	ErrMaxIterations = errors.New("max tool iterations exceeded")
+	ErrToolLoop      = errors.New("tool call loop detected")
+	ErrToolTimeout   = errors.New("tool call timed out")
+	ErrUnknownTool   = errors.New("tool not available")
+)
+
+// DefaultMaxRepeatedToolCalls is the number of times the same tool may be called with
+// identical arguments before the agent loop is considered stuck.
+const DefaultMaxRepeatedToolCalls = 3
+
+// DefaultMaxParallelToolCalls is the number of tool calls, requested in a single model
+// response, which are executed concurrently.
+const DefaultMaxParallelToolCalls = 4
 
 type (
 	ToolUnmarshaller func(name string, data []byte) (any, error)
@@ -37,6 +53,9 @@ type (
 		Name        string
 		Description string
 		Schema      gojsonschema.JSONLoader
+		Timeout     time.Duration // Overrides Request.ToolTimeout for this tool when positive
+
+		RequiresApproval bool // Calls must be approved by the ApprovalFunc of the runtime before running
 	}
 
 	ToolResponse struct {
@@ -48,57 +67,262 @@ type (
 	}
 
 	Request struct {
+		Action         string // Name of the action issuing the request, matched by WithPromptOverride
 		SkipInput      bool
 		Instructions   string
-		PromptTemplate string // Go template string for the prompt
-		Input          any    // Data passed to the prompt template
-		Output         any
+		PromptTemplate string            // Go template string for the prompt. See TemplateFuncs for the available functions.
+		PromptPartials map[string]string // Named sub-templates, which the prompt can include with {{template "name" .}}
+		StrictTemplate bool              // Fail when the prompt references a missing map key, instead of rendering "<no value>"
+		PromptVersion  string            // Version of the prompt, reported to hooks (see PromptVersionFromContext) and tracers
+		Input          any               // Data passed to the prompt template
+		Examples       []Example         // Sample inputs and outputs, shown to the model before the actual input
+		MaxFileTokens  int               // Maximum estimated tokens of the text of each file of the input. Zero means DefaultMaxFileTokens.
+		Output         any               // Pointer to struct to unmarshal output JSON into, or *string for free-text output
 		InputSchema    gojsonschema.JSONLoader
-		OutputSchema   gojsonschema.JSONLoader // Pointer to struct to unmarshal output JSON into
+		OutputSchema   gojsonschema.JSONLoader // Schema of the output. Nil means the model replies with free text.
 
 		ToolUnmarshaller ToolUnmarshaller
 		ToolInvoker      ToolInvoker
 		ToolSpecs        []ToolSpec
+
+		Retry RetryPolicy // Output-repair policy applied when the model returns invalid output. Zero uses the policy of the runtime, if any.
+
+		MaxToolIterations    int           // Maximum number of tool calls per invocation. Zero means unlimited.
+		MaxRepeatedToolCalls int           // Maximum identical tool calls before ErrToolLoop. Zero means DefaultMaxRepeatedToolCalls.
+		MaxParallelToolCalls int           // Maximum tool calls executed concurrently. Zero means DefaultMaxParallelToolCalls.
+		ToolTimeout          time.Duration // Maximum duration of a single tool call. Zero means no timeout.
+
+		ModelOptions ModelOptions      // Per-request model overrides honored by invokers. They take precedence over the options attached to the context.
+		Memory       Memory            // Conversation history to resume and extend. Nil starts a fresh conversation.
+		Compactor    *HistoryCompactor // Summarizes older turns when the history exceeds a token budget
+
+		Budget Budget // Limits on the tokens, cost and duration of the run. Zero uses the budget of the runtime, if any.
+
+		OnDelta func(delta string) // Receives the chunks of the model responses as they are generated. Setting it enables streaming.
+
+		// OnPartialOutput receives a new value of the type pointed by Output each time a field of the
+		// output is completed, while the response is being generated. Setting it enables streaming.
+		// It is ignored for free-text outputs.
+		OnPartialOutput func(partial any)
+
+		// PostProcessors transform the decoded output, in order, after the ones of the runtime.
+		PostProcessors []PostProcessor
 	}
 
 	Runtime struct {
 		invoker Invoker
+		base    Invoker // Invoker of the runtime before middlewares, for its optional interfaces
+		hooks   hookList
+		tracer  Tracer
+		approve ApprovalFunc
+		budget  Budget
+		retry   RetryPolicy
+
+		checkpoints    CheckpointStore
+		overrides      overrides
+		extractor      TextExtractor
+		memory         func(ctx context.Context) Memory
+		modelOptions   ModelOptions
+		inputGuards    []InputGuard
+		outputGuards   []OutputGuard
+		postProcessors []PostProcessor
+		critique       bool
+		inlineSchemas  bool
+		contextCheck   *ContextCheck
+		toolPolicy     ToolPolicy
 	}
+
+	// Option configures optional Runtime features.
+	Option func(r *Runtime)
 )
 
-func NewRuntime(invoker Invoker) *Runtime {
-	return &Runtime{
+func NewRuntime(invoker Invoker, opts ...Option) *Runtime {
+	r := &Runtime{
 		invoker: invoker,
+		base:    invoker,
+		tracer:  noopTracer{},
+	}
+
+	for _, opt := range opts {
+		opt(r)
+	}
+	return r
+}
+
+// WithHooks registers callbacks invoked at each step of a request.
+// It can be passed multiple times: hooks run in registration order.
+func WithHooks(hooks Hooks) Option {
+	return func(r *Runtime) {
+		r.hooks = append(r.hooks, hooks)
 	}
 }
 
 func (r *Runtime) Invoke(ctx context.Context, req Request) error {
-	if err := ValidateJSON(req.Input, req.InputSchema); err != nil {
-		return err
+	if RequestIDFromContext(ctx) == "" {
+		ctx = WithRequestID(ctx, newRequestID())
+	}
+	return r.run(ctx, &req, r.invoke)
+}
+
+// run executes fn, which drives the model to the output of req, notifying hooks and tracing the request.
+func (r *Runtime) run(ctx context.Context, req *Request, fn func(ctx context.Context, req *Request) error) error {
+	r.overrides.apply(req)
+	r.inlineRequestSchemas(req)
+	if req.Memory == nil && r.memory != nil {
+		req.Memory = r.memory(ctx)
+	}
+
+	ctx = withCallerModelOptions(ctx)
+	if opts := req.ModelOptions.withDefaults(ModelOptionsFromContext(ctx)).withDefaults(r.modelOptions); opts != (ModelOptions{}) {
+		ctx = WithModelOptions(ctx, opts)
 	}
+	// Set even when empty, so that the tools of a calling agent are not inherited
+	ctx = WithToolSpecs(ctx, req.ToolSpecs)
 
-	prompt, err := r.preparePrompt(&req)
+	var format gojsonschema.JSONLoader
+	if req.ToolInvoker == nil {
+		format = req.OutputSchema
+	}
+	ctx = WithOutputSchema(ctx, format)
+
+	var attrs []Attr
+	if model := ModelOptionsFromContext(ctx).Model; model != "" {
+		attrs = append(attrs, Attr{Key: AttrModel, Value: model})
+	}
+	if req.PromptVersion != "" {
+		ctx = WithPromptVersion(ctx, req.PromptVersion)
+		attrs = append(attrs, Attr{Key: AttrPromptVersion, Value: req.PromptVersion})
+	}
+
+	ctx, span := r.tracer.Start(ctx, SpanInvoke, attrs...)
+	ctx = startTranscript(ctx, req)
+
+	err := fn(ctx, req)
+	if err == nil {
+		err = r.checkOutput(ctx, req)
+	}
+	recording(ctx).finish(req.Output, err)
+	span.End(err)
 	if err != nil {
+		r.hooks.error(ctx, err)
 		return err
 	}
 
-	sess := NewChatSession(r.invoker, req.Instructions)
+	if r.checkpoints != nil {
+		// A stale checkpoint is harmless: resuming a completed run yields its output again
+		_ = r.checkpoints.Delete(ctx, RequestIDFromContext(ctx))
+	}
+
+	r.hooks.finalOutput(ctx, req.Output)
+	return nil
+}
 
-	out, err := sess.Invoke(
-		ctx,
-		prompt,
-	)
+func (r *Runtime) invoke(ctx context.Context, req *Request) error {
+	if err := ValidateJSON(req.Input, req.InputSchema); err != nil {
+		return err
+	}
+
+	prompt, err := r.preparePrompt(ctx, req)
 	if err != nil {
 		return err
 	}
+	r.hooks.promptBuilt(ctx, prompt.Content)
+	recording(ctx).add(TranscriptStep{Kind: StepPrompt, Content: prompt.Content})
 
+	memory := req.Memory
+	if memory == nil {
+		memory = NewInMemory()
+	}
+	sess := r.newSession(req, memory)
+	st := &runState{seenCalls: make(map[string]int)}
+
+	return r.withBudget(ctx, req, sess, st, func(ctx context.Context) error {
+		out, err := r.send(ctx, req, sess, prompt)
+		if err != nil {
+			return err
+		}
+		return r.loop(ctx, out, req, sess, st)
+	})
+}
+
+func (r *Runtime) newSession(req *Request, memory Memory) *ChatSession {
+	sess := NewChatSessionWithMemory(r.invoker, req.Instructions, memory)
+	sess.SetCompactor(req.Compactor)
+	if r.contextCheck != nil {
+		sess.check = r.checkContext
+	}
+	return sess
+}
+
+// loop processes the model response out, until the output of req is produced.
+func (r *Runtime) loop(ctx context.Context, out string, req *Request, sess *ChatSession, st *runState) error {
 	if req.ToolInvoker == nil {
-		return unmarshalOutput(out, &req)
+		return r.outputLoop(ctx, out, req, sess, st)
+	}
+	return r.agentLoop(ctx, out, req, sess, st)
+}
+
+// send delivers msgs to the model through the chat session and notifies hooks of the response.
+func (r *Runtime) send(ctx context.Context, req *Request, sess *ChatSession, msgs ...Message) (string, error) {
+	var attrs []Attr
+	if model := ModelOptionsFromContext(ctx).Model; model != "" {
+		attrs = append(attrs, Attr{Key: AttrModel, Value: model})
+	}
+
+	ctx, span := r.tracer.Start(ctx, SpanLLMCall, attrs...)
+
+	sess.SetStreamHandler(streamHandler(req))
+	start := time.Now()
+	out, err := sess.InvokeMessages(ctx, msgs...)
+	recording(ctx).add(TranscriptStep{Kind: StepResponse, Time: start, Duration: time.Since(start), Content: out, Error: errorString(err)})
+	if err != nil {
+		span.End(err)
+		return "", err
+	}
+
+	if history, err := sess.History(ctx); err == nil && len(history) > 0 {
+		span.SetAttributes(
+			Attr{Key: AttrPromptTokens, Value: EstimateTokens(history[:len(history)-1])},
+			Attr{Key: AttrCompletionTokens, Value: EstimateTokens(history[len(history)-1:])},
+		)
+	}
+	span.End(nil)
+
+	r.hooks.llmResponse(ctx, out)
+	return out, nil
+}
+
+func (r *Runtime) outputLoop(ctx context.Context, out string, req *Request, sess *ChatSession, st *runState) error {
+	for {
+		if err := r.checkpoint(ctx, sess, st); err != nil {
+			return err
+		}
+
+		if err := r.spend(ctx, req, sess, st); err != nil {
+			return err
+		}
+
+		err := unmarshalOutput(out, req)
+		if err == nil {
+			if err = r.finalize(ctx, req, sess, st); !errors.Is(err, ErrInvalidOutput) {
+				return err
+			}
+		}
+
+		out, err = r.repair(ctx, sess, req, &st.failures, err)
+		if err != nil {
+			return err
+		}
 	}
-	return r.agentLoop(ctx, out, &req, sess)
 }
 
-func (r *Runtime) agentLoop(ctx context.Context, out string, req *Request, sess *ChatSession) error {
+func (r *Runtime) agentLoop(ctx context.Context, out string, req *Request, sess *ChatSession, st *runState) (err error) {
+	ctx, span := r.tracer.Start(ctx, SpanAgentLoop)
+	defer func() {
+		span.SetAttributes(Attr{Key: AttrToolIterations, Value: st.iterations})
+		span.End(err)
+	}()
+
 	for {
 		select {
 		case <-ctx.Done():
@@ -106,102 +330,404 @@ func (r *Runtime) agentLoop(ctx context.Context, out string, req *Request, sess
 		default:
 		}
 
-		resp, err := parseToolResponse(out)
-		if err != nil {
+		if err := r.checkpoint(ctx, sess, st); err != nil {
+			return err
+		}
+
+		if err := r.spend(ctx, req, sess, st); err != nil {
 			return err
 		}
 
-		if resp.Done {
-			rawOut, err := json.Marshal(resp.Out)
+		resps, err := parseToolResponses(out)
+		if err != nil {
+			out, err = r.repair(ctx, sess, req, &st.failures, err)
+			if err != nil {
+				return err
+			}
+			continue
+		}
+
+		if len(resps) == 1 && resps[0].Done {
+			if req.isTextOutput() {
+				text, ok := resps[0].Out.(string)
+				if !ok {
+					err = errors.New(`"out" must be a string`)
+				} else if err = setTextOutput(text, req); err != nil {
+					return err
+				} else if err = r.finalize(ctx, req, sess, st); !errors.Is(err, ErrInvalidOutput) {
+					return err
+				}
+
+				out, err = r.repair(ctx, sess, req, &st.failures, err)
+				if err != nil {
+					return err
+				}
+				continue
+			}
+
+			rawOut, err := json.Marshal(resps[0].Out)
 			if err != nil {
 				return fmt.Errorf("marshal final output: %w", err)
 			}
-			return unmarshalOutput(string(rawOut), req)
+
+			err = unmarshalOutput(string(rawOut), req)
+			if err == nil {
+				if err = r.finalize(ctx, req, sess, st); !errors.Is(err, ErrInvalidOutput) {
+					return err
+				}
+			}
+
+			out, err = r.repair(ctx, sess, req, &st.failures, err)
+			if err != nil {
+				return err
+			}
+			continue
 		}
 
-		// Validate tool name and args
-		if resp.Name == "" {
-			return errors.New("tool response missing 'name'")
+		calls := make([]toolCall, 0, len(resps))
+		for _, resp := range resps {
+			// Validate tool name and args
+			if resp.Name == "" {
+				return errors.New("tool response missing 'name'")
+			}
+			if resp.Args == nil {
+				return fmt.Errorf("tool '%s' missing 'args'", resp.Name)
+			}
+
+			// Convert raw args into typed input
+			rawArgs, err := json.Marshal(resp.Args)
+			if err != nil {
+				return fmt.Errorf("marshal tool args: %w", err)
+			}
+
+			st.iterations++
+			if req.MaxToolIterations > 0 && st.iterations > req.MaxToolIterations {
+				return fmt.Errorf("%w: limit is %d", ErrMaxIterations, req.MaxToolIterations)
+			}
+
+			callKey := resp.Name + ":" + string(rawArgs)
+			st.seenCalls[callKey]++
+			if st.seenCalls[callKey] > req.maxRepeatedToolCalls() {
+				return fmt.Errorf("%w: tool '%s' called %d times with the same arguments", ErrToolLoop, resp.Name, st.seenCalls[callKey])
+			}
+
+			var inType any
+			if req.exposesTool(resp.Name) {
+				inType, err = req.ToolUnmarshaller(resp.Name, rawArgs)
+				if err != nil {
+					err = &ToolError{Tool: resp.Name, Err: fmt.Errorf("unmarshal args: %w", err)}
+				}
+			} else {
+				err = &ToolError{Tool: resp.Name, Err: ErrUnknownTool}
+			}
+
+			if err != nil {
+				calls = nil
+				out, err = r.repair(ctx, sess, req, &st.failures, err)
+				if err != nil {
+					return err
+				}
+				break
+			}
+			calls = append(calls, toolCall{id: toolCallID(st.iterations), name: resp.Name, args: rawArgs, in: inType})
 		}
-		if resp.Args == nil {
-			return fmt.Errorf("tool '%s' missing 'args'", resp.Name)
+
+		if len(calls) == 0 {
+			continue
 		}
 
-		// Convert raw args into typed input
-		rawArgs, err := json.Marshal(resp.Args)
-		if err != nil {
-			return fmt.Errorf("marshal tool args: %w", err)
+		r.applyToolPolicy(ctx, calls, req)
+		if err := r.approveCalls(ctx, calls, req, st); err != nil {
+			return err
 		}
 
-		inType, err := req.ToolUnmarshaller(resp.Name, rawArgs)
+		out, err = r.send(ctx, req, sess, r.callTools(ctx, calls, req)...)
 		if err != nil {
-			return fmt.Errorf("tool unmarshal for '%s': %w", resp.Name, err)
+			return fmt.Errorf("invoke session after tool '%s': %w", calls[len(calls)-1].name, err)
 		}
+	}
+}
 
-		toolOutput := r.callTool(ctx, resp.Name, inType, req)
+// isTextOutput reports whether the model is expected to reply with free text rather than JSON.
+func (req *Request) isTextOutput() bool {
+	return req.OutputSchema == nil
+}
 
-		out, err = sess.Invoke(ctx, toolOutput)
-		if err != nil {
-			return fmt.Errorf("invoke session after tool '%s': %w", resp.Name, err)
+func setTextOutput(out string, req *Request) error {
+	text, ok := req.Output.(*string)
+	if !ok {
+		return fmt.Errorf("free-text output requires a *string output, got %T", req.Output)
+	}
+	*text = strings.TrimSpace(out)
+	return nil
+}
+
+// exposesTool reports whether the model is allowed to call the named tool.
+// When ToolSpecs is empty, every tool known to the ToolUnmarshaller is allowed.
+func (req *Request) exposesTool(name string) bool {
+	if len(req.ToolSpecs) == 0 {
+		return true
+	}
+	for _, spec := range req.ToolSpecs {
+		if spec.Name == name {
+			return true
+		}
+	}
+	return false
+}
+
+func (req *Request) requiresApproval(name string) bool {
+	for _, spec := range req.ToolSpecs {
+		if spec.Name == name {
+			return spec.RequiresApproval
+		}
+	}
+	return false
+}
+
+func (req *Request) toolTimeout(name string) time.Duration {
+	for _, spec := range req.ToolSpecs {
+		if spec.Name == name && spec.Timeout > 0 {
+			return spec.Timeout
 		}
 	}
+	return req.ToolTimeout
+}
+
+func (req *Request) maxParallelToolCalls() int {
+	if req.MaxParallelToolCalls > 0 {
+		return req.MaxParallelToolCalls
+	}
+	return DefaultMaxParallelToolCalls
 }
 
-func parseToolResponse(raw string) (ToolResponse, error) {
+func (req *Request) maxRepeatedToolCalls() int {
+	if req.MaxRepeatedToolCalls > 0 {
+		return req.MaxRepeatedToolCalls
+	}
+	return DefaultMaxRepeatedToolCalls
+}
+
+// parseToolResponses parses either a single tool response object or an array of tool calls.
+func parseToolResponses(raw string) ([]ToolResponse, error) {
+	obj := strings.Index(raw, "{")
+	arr := strings.Index(raw, "[")
+
+	if arr != -1 && (obj == -1 || arr < obj) {
+		if rawJSON := ExtractJSONArrayFromString(raw); rawJSON != "" {
+			var resps []ToolResponse
+			if err := json.Unmarshal([]byte(rawJSON), &resps); err != nil {
+				return nil, fmt.Errorf("invalid JSON format: %s", rawJSON)
+			}
+			if len(resps) == 0 {
+				return nil, errors.New("empty tool call array")
+			}
+			for _, resp := range resps {
+				if resp.Done {
+					return nil, errors.New("final output must not be mixed with tool calls")
+				}
+			}
+			return resps, nil
+		}
+	}
+
 	rawJSON := ExtractJSONFromString(raw)
 	if rawJSON == "" {
-		return ToolResponse{}, errors.New("no valid JSON found in response")
+		return nil, errors.New("no valid JSON found in response")
 	}
 
 	var resp ToolResponse
 	if err := json.Unmarshal([]byte(rawJSON), &resp); err != nil {
-		return ToolResponse{}, fmt.Errorf("invalid JSON format: %s", rawJSON)
+		return nil, fmt.Errorf("invalid JSON format: %s", rawJSON)
 	}
-	return resp, nil
+	return []ToolResponse{resp}, nil
 }
 
-func (r *Runtime) callTool(ctx context.Context, name string, inType any, req *Request) string {
-	toolResp, err := req.ToolInvoker(ctx, name, inType)
+type toolCall struct {
+	id   string
+	name string
+	args json.RawMessage
+	in   any
+	err  error // Reason why the call must not run, such as a denied approval
+}
+
+// toolCallID returns the ID of the n-th tool call of a run. IDs are made of nine
+// alphanumeric characters, the strictest format required by OpenAI-compatible providers.
+func toolCallID(n int) string {
+	return fmt.Sprintf("call%05d", n%100000)
+}
+
+// callTools executes calls concurrently, with at most MaxParallelToolCalls running at the same time,
+// and returns their outputs as tool messages, in the order the calls were issued.
+func (r *Runtime) callTools(ctx context.Context, calls []toolCall, req *Request) []Message {
+	if len(calls) == 1 {
+		return []Message{r.callTool(ctx, calls[0], req)}
+	}
+
+	outputs := make([]Message, len(calls))
+	sem := make(chan struct{}, req.maxParallelToolCalls())
+
+	var wg sync.WaitGroup
+	for i, call := range calls {
+		wg.Add(1)
+		sem <- struct{}{}
+
+		go func() {
+			defer func() {
+				<-sem
+				wg.Done()
+			}()
+			outputs[i] = r.callTool(ctx, call, req)
+		}()
+	}
+	wg.Wait()
+
+	return outputs
+}
+
+func (r *Runtime) callTool(ctx context.Context, call toolCall, req *Request) Message {
+	msg := Message{
+		Role:     RoleTool,
+		ToolCall: &ToolCallRef{ID: call.id, Name: call.name, Args: call.args},
+	}
+
+	if call.err != nil {
+		recording(ctx).add(TranscriptStep{Kind: StepToolCall, Tool: call.name, Args: call.args, Error: call.err.Error()})
+		msg.Content = "ERR: " + call.err.Error()
+		return msg
+	}
+	name, inType := call.name, call.in
+
+	if timeout := req.toolTimeout(name); timeout > 0 {
+		var cancel context.CancelFunc
+		ctx, cancel = context.WithTimeout(ctx, timeout)
+		defer cancel()
+	}
+
+	ctx, span := r.tracer.Start(ctx, SpanToolCall, Attr{Key: AttrToolName, Value: name})
+
+	r.hooks.toolCall(ctx, name, inType)
+
+	start := time.Now()
+	toolResp, err := invokeTool(callerModelOptions(ctx), req.ToolInvoker, name, inType)
+	r.hooks.toolResult(ctx, name, toolResp, err)
+	if t := recording(ctx); t != nil {
+		step := TranscriptStep{Kind: StepToolCall, Time: start, Duration: time.Since(start), Tool: name, Args: call.args, Error: errorString(err)}
+		if err == nil {
+			step.Result, _ = json.Marshal(toolResp)
+		}
+		t.add(step)
+	}
+	span.End(err)
 	if err != nil {
-		return "ERR: " + err.Error()
+		msg.Content = "ERR: " + err.Error()
+		return msg
 	}
 
 	rawToolResp, _ := json.Marshal(toolResp)
-	return name + " OUTPUT: " + string(rawToolResp)
+	msg.Content = string(rawToolResp)
+	return msg
+}
+
+// invokeTool runs the tool in a separate goroutine, so that implementations ignoring
+// the context cannot block the agent loop past the tool deadline.
+// Failures are reported as a *ToolError.
+func invokeTool(ctx context.Context, invoker ToolInvoker, name string, in any) (any, error) {
+	type result struct {
+		out any
+		err error
+	}
+
+	done := make(chan result, 1)
+	go func() {
+		out, err := invoker(ctx, name, in)
+		done <- result{out: out, err: err}
+	}()
+
+	select {
+	case res := <-done:
+		if res.err != nil {
+			return nil, &ToolError{Tool: name, Err: res.err}
+		}
+		return res.out, nil
+	case <-ctx.Done():
+		if errors.Is(ctx.Err(), context.DeadlineExceeded) {
+			return nil, &ToolError{Tool: name, Err: ErrToolTimeout}
+		}
+		return nil, &ToolError{Tool: name, Err: ctx.Err()}
+	}
 }
 
 func unmarshalOutput(out string, req *Request) error {
-	out = ExtractJSONFromString(out)
-	if out == "" {
-		return ErrInvalidOutput
+	if req.isTextOutput() {
+		return setTextOutput(out, req)
+	}
+
+	// Models may reply with several objects, such as a draft and a corrected version:
+	// the first one matching the schema is taken
+	var firstErr error
+	for _, candidate := range ExtractJSONCandidates(out) {
+		if candidate[0] != '{' {
+			continue
+		}
+
+		err := UnmarshalValidate([]byte(candidate), req.Output, req.OutputSchema)
+		if err == nil {
+			return nil
+		}
+		if firstErr == nil {
+			firstErr = err
+		}
+	}
+
+	if firstErr == nil {
+		return fmt.Errorf("%w: no JSON object found", ErrInvalidOutput)
 	}
-	return UnmarshalValidate([]byte(out), req.Output, req.OutputSchema)
+	return firstErr
 }
 
-func (r *Runtime) preparePrompt(req *Request) (string, error) {
-	compiledPrompt, err := r.compilePrompt(req)
+// RenderPrompt returns the prompt which would be sent to the model for req, without invoking it.
+// The instructions of req are not part of it, as they are sent separately, as the system prompt.
+func RenderPrompt(req Request) (string, error) {
+	var r Runtime
+	prompt, err := r.preparePrompt(context.Background(), &req)
+	return prompt.Content, err
+}
+
+// preparePrompt returns the first user message sent for req.
+func (r *Runtime) preparePrompt(ctx context.Context, req *Request) (Message, error) {
+	compiledPrompt, err := r.compilePrompt(ctx, req)
 	if err != nil {
-		return "", err
+		return Message{}, err
 	}
 
-	var pb PromptBuilder
+	in, attachments, err := r.prepareInput(ctx, req)
+	if err != nil {
+		return Message{}, err
+	}
+	withInput := *req
+	withInput.Input = in
 
-	prompt := pb.Build(compiledPrompt, req)
-	return prompt, nil
-}
+	var pb PromptBuilder
 
-func (r *Runtime) compilePrompt(req *Request) (string, error) {
-	// TODO: add more utility functions
-	funcMap := template.FuncMap{
-		"join": strings.Join,
+	prompt := pb.Build(compiledPrompt, &withInput)
+	if err := r.checkInput(ctx, prompt); err != nil {
+		return Message{}, err
 	}
+	return Message{Role: RoleUser, Content: prompt, Attachments: attachments}, nil
+}
 
-	tmpl, err := template.New("prompt").
-		Funcs(funcMap).
-		Parse(req.PromptTemplate)
+func (r *Runtime) compilePrompt(ctx context.Context, req *Request) (string, error) {
+	tmpl, err := ParsePrompt(req.PromptTemplate, req.PromptPartials)
 	if err != nil {
 		return "", fmt.Errorf("template parse: %w", err)
 	}
+	tmpl.Funcs(template.FuncMap{
+		"meta": func() map[string]any { return ValuesFromContext(ctx) },
+	})
+	if req.StrictTemplate {
+		tmpl.Option("missingkey=error")
+	}
 
 	var promptBuf bytes.Buffer
 	if err := tmpl.Execute(&promptBuf, req.Input); err != nil {
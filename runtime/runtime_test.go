@@ -12,15 +12,20 @@
 // WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
 // See the License for the specific language governing permissions and
 // limitations under the License.
-package runtime
+package runtime_test
 
 import (
 	"context"
 	"encoding/json"
 	"errors"
 	"fmt"
+	"strings"
+	"sync"
 	"testing"
+	"time"
 
+	"github.com/ostafen/suricata/runtime"
+	"github.com/ostafen/suricata/runtime/runtimetest"
 	"github.com/xeipuuv/gojsonschema"
 )
 
@@ -40,13 +45,11 @@ func TestRuntime_Invoke(t *testing.T) {
 	)
 
 	t.Run("basic success no tools", func(t *testing.T) {
-		mock := &mockInvoker{
-			responses: []string{`{"result":"hello"}`},
-		}
+		mock := runtimetest.NewInvoker(t).Respond(`{"result":"hello"}`)
 
-		rt := NewRuntime(mock)
+		rt := runtime.NewRuntime(mock)
 
-		req := Request{
+		req := runtime.Request{
 			PromptTemplate: "Hello, {{.Name}}",
 			Input:          &Input{Name: "Pluto"},
 			Output:         &Output{},
@@ -65,13 +68,77 @@ func TestRuntime_Invoke(t *testing.T) {
 		}
 	})
 
-	t.Run("invalid output JSON", func(t *testing.T) {
-		mock := &mockInvoker{
-			responses: []string{`not a json`},
+	t.Run("instructions are sent as the system prompt", func(t *testing.T) {
+		mock := runtimetest.NewInvoker(t)
+		mock.Expect().SystemContains("Answer in Italian.").Respond(`{"result":"ciao"}`)
+
+		rt := runtime.NewRuntime(mock)
+
+		err := rt.Invoke(context.Background(), runtime.Request{
+			Instructions:   "Answer in Italian.",
+			PromptTemplate: "Hello, {{.Name}}",
+			Input:          &Input{Name: "Pluto"},
+			Output:         &Output{},
+			InputSchema:    InputSchema,
+			OutputSchema:   OutputSchema,
+		})
+		if err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+		mock.AssertExpectations()
+
+		if strings.Contains(mock.LastCall().LastMessage(), "Answer in Italian.") {
+			t.Error("expected instructions to be left out of the user prompt")
+		}
+	})
+
+	t.Run("tool outputs are sent as tool messages", func(t *testing.T) {
+		mock := runtimetest.NewInvoker(t).Respond(
+			`[{"name":"search","args":{"val":"a"}},{"name":"search","args":{"val":"b"}}]`,
+			`{"done":true,"out":{"result":"done"}}`,
+		)
+		rt := runtime.NewRuntime(mock)
+
+		err := rt.Invoke(context.Background(), runtime.Request{
+			PromptTemplate: "Tool test",
+			Input:          &Input{Name: "Pluto"},
+			Output:         &Output{},
+			InputSchema:    InputSchema,
+			OutputSchema:   OutputSchema,
+			ToolUnmarshaller: func(name string, data []byte) (any, error) {
+				var args map[string]string
+				return args, json.Unmarshal(data, &args)
+			},
+			ToolInvoker: func(ctx context.Context, name string, in any) (any, error) {
+				return in.(map[string]string)["val"], nil
+			},
+		})
+		if err != nil {
+			t.Fatalf("unexpected error: %v", err)
 		}
-		rt := NewRuntime(mock)
 
-		req := Request{
+		msgs := mock.LastCall().Messages
+		tools := msgs[len(msgs)-2:]
+		for i, want := range []string{"a", "b"} {
+			m := tools[i]
+			if m.Role != runtime.RoleTool || m.ToolCall == nil || m.ToolCall.Name != "search" {
+				t.Fatalf("expected a tool message, got %+v", m)
+			}
+			if m.Content != `"`+want+`"` || string(m.ToolCall.Args) != `{"val":"`+want+`"}` {
+				t.Errorf("unexpected tool message %+v", m)
+			}
+		}
+		if tools[0].ToolCall.ID == tools[1].ToolCall.ID {
+			t.Errorf("expected distinct call IDs, got %s", tools[0].ToolCall.ID)
+		}
+	})
+
+	t.Run("invalid output JSON", func(t *testing.T) {
+		// The nil input is rejected before calling the model
+		mock := runtimetest.NewInvoker(t)
+		rt := runtime.NewRuntime(mock)
+
+		req := runtime.Request{
 			PromptTemplate: "Hello",
 			Input:          nil,
 			Output:         &Output{},
@@ -80,23 +147,201 @@ func TestRuntime_Invoke(t *testing.T) {
 		}
 
 		err := rt.Invoke(context.Background(), req)
-		if !errors.Is(err, ErrInvalidOutput) {
+		if !errors.Is(err, runtime.ErrInvalidOutput) {
 			t.Errorf("expected ErrInvalidOutput, got %v", err)
 		}
 	})
 
-	t.Run("agent loop with tool call", func(t *testing.T) {
-		mock := &mockInvoker{
-			responses: []string{
-				`{"name":"tool1","args":{"val":"x"},"done":false}`,
-				`{"done":true,"out":{"result":"final"}}`,
+	t.Run("invalid model response", func(t *testing.T) {
+		mock := runtimetest.NewInvoker(t).Respond(`not a json`)
+		rt := runtime.NewRuntime(mock)
+
+		req := runtime.Request{
+			PromptTemplate: "Hello",
+			Input:          &Input{Name: "Pluto"},
+			Output:         &Output{},
+			InputSchema:    InputSchema,
+			OutputSchema:   OutputSchema,
+		}
+
+		err := rt.Invoke(context.Background(), req)
+		if !errors.Is(err, runtime.ErrInvalidOutput) {
+			t.Errorf("expected ErrInvalidOutput, got %v", err)
+		}
+		if len(mock.Calls()) != 1 {
+			t.Errorf("expected 1 invocation, got %d", len(mock.Calls()))
+		}
+	})
+
+	t.Run("output repair retries invalid output", func(t *testing.T) {
+		mock := runtimetest.NewInvoker(t).Respond(
+			`not a json`,
+			`{"wrong":"field"}`,
+			`{"result":"fixed"}`,
+		)
+		rt := runtime.NewRuntime(mock)
+
+		req := runtime.Request{
+			PromptTemplate: "Hello",
+			Input:          &Input{Name: "Pluto"},
+			Output:         &Output{},
+			InputSchema:    InputSchema,
+			OutputSchema:   OutputSchema,
+			Retry:          runtime.RetryPolicy{MaxAttempts: 3},
+		}
+
+		err := rt.Invoke(context.Background(), req)
+		if err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+
+		out := req.Output.(*Output)
+		if out.Result != "fixed" {
+			t.Errorf("expected 'fixed', got %q", out.Result)
+		}
+
+		if len(mock.Calls()) != 3 {
+			t.Errorf("expected 3 invocations, got %d", len(mock.Calls()))
+		}
+	})
+
+	t.Run("output repair gives up after max attempts", func(t *testing.T) {
+		mock := runtimetest.NewInvoker(t).Respond(`not a json`, `still not a json`)
+		rt := runtime.NewRuntime(mock)
+
+		req := runtime.Request{
+			PromptTemplate: "Hello",
+			Input:          &Input{Name: "Pluto"},
+			Output:         &Output{},
+			InputSchema:    InputSchema,
+			OutputSchema:   OutputSchema,
+			Retry:          runtime.RetryPolicy{MaxAttempts: 2},
+		}
+
+		err := rt.Invoke(context.Background(), req)
+		if !errors.Is(err, runtime.ErrInvalidOutput) {
+			t.Errorf("expected ErrInvalidOutput, got %v", err)
+		}
+	})
+
+	t.Run("output repair reports schema violations", func(t *testing.T) {
+		mock := runtimetest.NewInvoker(t)
+		mock.Expect().Respond(`{"result":1}`)
+		mock.Expect().PromptContains(
+			"does not match the output schema",
+			"- result: Invalid type. Expected: string, given: integer (invalid_type)",
+		).Respond(`{"result":"fixed"}`)
+
+		rt := runtime.NewRuntime(mock, runtime.WithRetryPolicy(runtime.RetryPolicy{MaxAttempts: 2}))
+
+		req := runtime.Request{
+			PromptTemplate: "Hello",
+			Input:          &Input{Name: "Pluto"},
+			Output:         &Output{},
+			InputSchema:    InputSchema,
+			OutputSchema:   OutputSchema,
+		}
+
+		if err := rt.Invoke(context.Background(), req); err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+		mock.AssertExpectations()
+
+		if out := req.Output.(*Output); out.Result != "fixed" {
+			t.Errorf("expected 'fixed', got %q", out.Result)
+		}
+	})
+
+	t.Run("request model options take precedence", func(t *testing.T) {
+		var got runtime.ModelOptions
+		inv := runtime.InvokerFunc(func(ctx context.Context, system string, messages []runtime.Message) (string, error) {
+			got = runtime.ModelOptionsFromContext(ctx)
+			return `{"result":"hello"}`, nil
+		})
+		rt := runtime.NewRuntime(inv)
+
+		req := runtime.Request{
+			PromptTemplate: "Hello",
+			Input:          &Input{Name: "Pluto"},
+			Output:         &Output{},
+			InputSchema:    InputSchema,
+			OutputSchema:   OutputSchema,
+			ModelOptions:   runtime.ModelOptions{Model: "small", MaxTokens: 100},
+		}
+
+		ctx := runtime.WithModelOptions(context.Background(), runtime.ModelOptions{Model: "large", Temperature: runtime.Float64(0)})
+		if err := rt.Invoke(ctx, req); err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+
+		if got.Model != "small" || got.MaxTokens != 100 || got.Temperature == nil || *got.Temperature != 0 {
+			t.Errorf("expected model small with 100 max tokens, and the temperature of the context, got %+v", got)
+		}
+	})
+
+	t.Run("free-text output", func(t *testing.T) {
+		mock := runtimetest.NewInvoker(t).Respond("  Once upon a time...\n")
+		rt := runtime.NewRuntime(mock)
+
+		var out string
+		req := runtime.Request{
+			PromptTemplate: "Tell me a story",
+			Input:          &Input{Name: "Pluto"},
+			Output:         &out,
+			InputSchema:    InputSchema,
+		}
+
+		if err := rt.Invoke(context.Background(), req); err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+
+		if out != "Once upon a time..." {
+			t.Errorf("expected story, got %q", out)
+		}
+	})
+
+	t.Run("free-text output with tools", func(t *testing.T) {
+		mock := runtimetest.NewInvoker(t).Respond(
+			`{"name":"tool1","args":{"val":"x"}}`,
+			`{"done":true,"out":{"result":"not a string"}}`,
+			`{"done":true,"out":"final answer"}`,
+		)
+		rt := runtime.NewRuntime(mock)
+
+		var out string
+		req := runtime.Request{
+			PromptTemplate: "Tool test",
+			Input:          &Input{},
+			Output:         &out,
+			InputSchema:    InputSchema,
+			Retry:          runtime.RetryPolicy{MaxAttempts: 2},
+			ToolUnmarshaller: func(name string, data []byte) (any, error) {
+				return nil, nil
+			},
+			ToolInvoker: func(ctx context.Context, name string, in any) (any, error) {
+				return nil, nil
 			},
 		}
 
-		rt := NewRuntime(mock)
+		if err := rt.Invoke(context.Background(), req); err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+
+		if out != "final answer" {
+			t.Errorf("expected 'final answer', got %q", out)
+		}
+	})
+
+	t.Run("agent loop with tool call", func(t *testing.T) {
+		mock := runtimetest.NewInvoker(t).Respond(
+			`{"name":"tool1","args":{"val":"x"},"done":false}`,
+			`{"done":true,"out":{"result":"final"}}`,
+		)
+
+		rt := runtime.NewRuntime(mock)
 
 		toolCalled := false
-		req := Request{
+		req := runtime.Request{
 			PromptTemplate: "Tool test",
 			Input:          &Input{},
 			Output:         &Output{},
@@ -131,19 +376,292 @@ func TestRuntime_Invoke(t *testing.T) {
 		}
 	})
 
-	t.Run("context cancel in agent loop", func(t *testing.T) {
-		mock := &mockInvoker{
-			responses: []string{
-				`{"name":"tool1","args":{"val":"x"},"done":false}`,
+	t.Run("agent loop with parallel tool calls", func(t *testing.T) {
+		mock := runtimetest.NewInvoker(t).Respond(
+			`[{"name":"tool1","args":{"val":"a"}},{"name":"tool1","args":{"val":"b"}},{"name":"tool1","args":{"val":"c"}}]`,
+			`{"done":true,"out":{"result":"final"}}`,
+		)
+		rt := runtime.NewRuntime(mock)
+
+		var (
+			mtx   sync.Mutex
+			calls []string
+		)
+
+		req := runtime.Request{
+			PromptTemplate:       "Tool test",
+			Input:                &Input{},
+			Output:               &Output{},
+			InputSchema:          InputSchema,
+			OutputSchema:         OutputSchema,
+			MaxParallelToolCalls: 2,
+			ToolUnmarshaller: func(name string, data []byte) (any, error) {
+				var args struct{ Val string }
+				err := json.Unmarshal(data, &args)
+				return args.Val, err
+			},
+			ToolInvoker: func(ctx context.Context, name string, in any) (any, error) {
+				mtx.Lock()
+				defer mtx.Unlock()
+
+				calls = append(calls, in.(string))
+				return map[string]string{"toolResult": in.(string)}, nil
 			},
 		}
 
-		rt := NewRuntime(mock)
+		err := rt.Invoke(context.Background(), req)
+		if err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+
+		if len(calls) != 3 {
+			t.Errorf("expected 3 tool calls, got %d", len(calls))
+		}
+
+		out := req.Output.(*Output)
+		if out.Result != "final" {
+			t.Errorf("expected 'final', got %q", out.Result)
+		}
+	})
+
+	t.Run("tool timeout is reported to the model", func(t *testing.T) {
+		mock := runtimetest.NewInvoker(t).Respond(
+			`{"name":"tool1","args":{"val":"x"}}`,
+			`{"done":true,"out":{"result":"recovered"}}`,
+		)
+		rt := runtime.NewRuntime(mock)
+
+		block := make(chan struct{})
+		defer close(block)
+
+		req := runtime.Request{
+			PromptTemplate: "Tool test",
+			Input:          &Input{},
+			Output:         &Output{},
+			InputSchema:    InputSchema,
+			OutputSchema:   OutputSchema,
+			ToolTimeout:    10 * time.Millisecond,
+			ToolUnmarshaller: func(name string, data []byte) (any, error) {
+				return nil, nil
+			},
+			ToolInvoker: func(ctx context.Context, name string, in any) (any, error) {
+				<-block // ignores ctx on purpose
+				return nil, nil
+			},
+		}
+
+		err := rt.Invoke(context.Background(), req)
+		if err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+
+		last := mock.LastCall().LastMessage()
+		if !strings.Contains(last, runtime.ErrToolTimeout.Error()) {
+			t.Errorf("expected timeout error to be sent to the model, got %q", last)
+		}
+	})
+
+	t.Run("agent loop exceeds max tool iterations", func(t *testing.T) {
+		mock := runtimetest.NewInvoker(t).Respond(
+			`{"name":"tool1","args":{"val":"a"}}`,
+			`{"name":"tool1","args":{"val":"b"}}`,
+			`{"name":"tool1","args":{"val":"c"}}`,
+		)
+		rt := runtime.NewRuntime(mock)
+
+		req := runtime.Request{
+			PromptTemplate:    "Test",
+			Input:             &Input{},
+			Output:            &Output{},
+			InputSchema:       InputSchema,
+			OutputSchema:      OutputSchema,
+			MaxToolIterations: 2,
+			ToolUnmarshaller: func(name string, data []byte) (any, error) {
+				return nil, nil
+			},
+			ToolInvoker: func(ctx context.Context, name string, in any) (any, error) {
+				return nil, nil
+			},
+		}
+
+		err := rt.Invoke(context.Background(), req)
+		if !errors.Is(err, runtime.ErrMaxIterations) {
+			t.Errorf("expected ErrMaxIterations, got %v", err)
+		}
+	})
+
+	t.Run("agent loop detects repeated tool calls", func(t *testing.T) {
+		mock := runtimetest.NewInvoker(t).Respond(
+			`{"name":"tool1","args":{"val":"x"}}`,
+			`{"name":"tool1","args":{"val":"x"}}`,
+			`{"name":"tool1","args":{"val":"x"}}`,
+		)
+		rt := runtime.NewRuntime(mock)
+
+		req := runtime.Request{
+			PromptTemplate:       "Test",
+			Input:                &Input{},
+			Output:               &Output{},
+			InputSchema:          InputSchema,
+			OutputSchema:         OutputSchema,
+			MaxRepeatedToolCalls: 2,
+			ToolUnmarshaller: func(name string, data []byte) (any, error) {
+				return nil, nil
+			},
+			ToolInvoker: func(ctx context.Context, name string, in any) (any, error) {
+				return nil, nil
+			},
+		}
+
+		err := rt.Invoke(context.Background(), req)
+		if !errors.Is(err, runtime.ErrToolLoop) {
+			t.Errorf("expected ErrToolLoop, got %v", err)
+		}
+	})
+
+	t.Run("tool not in tool specs is rejected", func(t *testing.T) {
+		mock := runtimetest.NewInvoker(t).Respond(
+			`{"name":"tool2","args":{"val":"x"}}`,
+		)
+		rt := runtime.NewRuntime(mock)
+
+		req := runtime.Request{
+			PromptTemplate: "Test",
+			Input:          &Input{},
+			Output:         &Output{},
+			InputSchema:    InputSchema,
+			OutputSchema:   OutputSchema,
+			ToolSpecs:      []runtime.ToolSpec{{Name: "tool1", Schema: InputSchema}},
+			ToolUnmarshaller: func(name string, data []byte) (any, error) {
+				return nil, nil
+			},
+			ToolInvoker: func(ctx context.Context, name string, in any) (any, error) {
+				t.Errorf("unexpected call to tool %s", name)
+				return nil, nil
+			},
+		}
+
+		err := rt.Invoke(context.Background(), req)
+		if !errors.Is(err, runtime.ErrUnknownTool) {
+			t.Errorf("expected ErrUnknownTool, got %v", err)
+		}
+	})
+
+	t.Run("hooks are invoked", func(t *testing.T) {
+		mock := runtimetest.NewInvoker(t).Respond(
+			`{"name":"tool1","args":{"val":"x"}}`,
+			`{"done":true,"out":{"result":"final"}}`,
+		)
+
+		var events []string
+		rt := runtime.NewRuntime(mock, runtime.WithHooks(runtime.Hooks{
+			OnPromptBuilt: func(ctx context.Context, prompt string) { events = append(events, "prompt") },
+			OnLLMResponse: func(ctx context.Context, response string) { events = append(events, "response") },
+			OnToolCall:    func(ctx context.Context, name string, in any) { events = append(events, "call:"+name) },
+			OnToolResult: func(ctx context.Context, name string, out any, err error) {
+				events = append(events, "result:"+name)
+			},
+			OnFinalOutput: func(ctx context.Context, out any) { events = append(events, "final") },
+			OnError:       func(ctx context.Context, err error) { events = append(events, "error") },
+		}))
+
+		req := runtime.Request{
+			PromptTemplate: "Tool test",
+			Input:          &Input{},
+			Output:         &Output{},
+			InputSchema:    InputSchema,
+			OutputSchema:   OutputSchema,
+			ToolUnmarshaller: func(name string, data []byte) (any, error) {
+				return nil, nil
+			},
+			ToolInvoker: func(ctx context.Context, name string, in any) (any, error) {
+				return nil, nil
+			},
+		}
+
+		if err := rt.Invoke(context.Background(), req); err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+
+		expected := "prompt,response,call:tool1,result:tool1,response,final"
+		if got := strings.Join(events, ","); got != expected {
+			t.Errorf("expected events %s, got %s", expected, got)
+		}
+	})
+
+	t.Run("prompt version is reported to hooks", func(t *testing.T) {
+		mock := runtimetest.NewInvoker(t).Respond(`{"result":"ok"}`)
+
+		var versions []string
+		rt := runtime.NewRuntime(mock, runtime.WithHooks(runtime.Hooks{
+			OnPromptBuilt: func(ctx context.Context, prompt string) {
+				versions = append(versions, runtime.PromptVersionFromContext(ctx))
+			},
+			OnFinalOutput: func(ctx context.Context, out any) {
+				versions = append(versions, runtime.PromptVersionFromContext(ctx))
+			},
+		}))
+
+		req := runtime.Request{
+			PromptTemplate: "Test",
+			PromptVersion:  "v2",
+			Input:          &Input{},
+			Output:         &Output{},
+			InputSchema:    InputSchema,
+			OutputSchema:   OutputSchema,
+		}
+
+		if err := rt.Invoke(context.Background(), req); err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+		if got := strings.Join(versions, ","); got != "v2,v2" {
+			t.Errorf("expected prompt version v2 for each hook, got %q", got)
+		}
+	})
+
+	t.Run("output schema is passed to the invoker outside agent loops", func(t *testing.T) {
+		var schemas []gojsonschema.JSONLoader
+		inv := runtime.InvokerFunc(func(ctx context.Context, system string, messages []runtime.Message) (string, error) {
+			schemas = append(schemas, runtime.OutputSchemaFromContext(ctx))
+			if len(schemas) == 2 {
+				return `{"out":{"result":"ok"},"done":true}`, nil
+			}
+			return `{"result":"ok"}`, nil
+		})
+		rt := runtime.NewRuntime(inv)
+
+		req := runtime.Request{
+			PromptTemplate: "Test",
+			Input:          &Input{},
+			Output:         &Output{},
+			InputSchema:    InputSchema,
+			OutputSchema:   OutputSchema,
+		}
+		if err := rt.Invoke(context.Background(), req); err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+
+		req.ToolInvoker = func(ctx context.Context, name string, in any) (any, error) { return nil, nil }
+		if err := rt.Invoke(context.Background(), req); err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+
+		if len(schemas) != 2 || schemas[0] != OutputSchema || schemas[1] != nil {
+			t.Errorf("expected the output schema for the first call only, got %v", schemas)
+		}
+	})
+
+	t.Run("context cancel in agent loop", func(t *testing.T) {
+		mock := runtimetest.NewInvoker(t).Respond(
+			`{"name":"tool1","args":{"val":"x"},"done":false}`,
+		)
+
+		rt := runtime.NewRuntime(mock)
 
 		ctx, cancel := context.WithCancel(context.Background())
 		cancel() // cancel immediately
 
-		req := Request{
+		req := runtime.Request{
 			PromptTemplate: "Test",
 			Input:          &Input{},
 			Output:         &Output{},
@@ -162,18 +680,149 @@ func TestRuntime_Invoke(t *testing.T) {
 			t.Errorf("expected context.Canceled, got %v", err)
 		}
 	})
-}
+	t.Run("tool calls requiring approval", func(t *testing.T) {
+		mock := runtimetest.NewInvoker(t).Respond(
+			`[{"name":"book","args":{"val":"rome"}},{"name":"search","args":{"val":"x"}}]`,
+			`{"name":"book","args":{"val":"paris"}}`,
+			`{"done":true,"out":{"result":"booked"}}`,
+		)
 
-type mockInvoker struct {
-	responses []string
-	callCount int
-}
+		var pending []runtime.PendingCall
+		rt := runtime.NewRuntime(mock, runtime.WithApproval(func(ctx context.Context, call runtime.PendingCall) error {
+			pending = append(pending, call)
+			if strings.Contains(string(call.Args), "rome") {
+				return errors.New("no budget left")
+			}
+			return nil
+		}))
+
+		var invoked []string
+		req := runtime.Request{
+			PromptTemplate: "Tool test",
+			Input:          &Input{},
+			Output:         &Output{},
+			InputSchema:    InputSchema,
+			OutputSchema:   OutputSchema,
+			ToolSpecs: []runtime.ToolSpec{
+				{Name: "book", Schema: gojsonschema.NewStringLoader(`{"type":"object"}`), RequiresApproval: true},
+				{Name: "search", Schema: gojsonschema.NewStringLoader(`{"type":"object"}`)},
+			},
+			ToolUnmarshaller: func(name string, data []byte) (any, error) {
+				var args map[string]string
+				return args, json.Unmarshal(data, &args)
+			},
+			ToolInvoker: func(ctx context.Context, name string, in any) (any, error) {
+				invoked = append(invoked, name+":"+in.(map[string]string)["val"])
+				return "ok", nil
+			},
+		}
+
+		if err := rt.Invoke(runtime.WithRequestID(context.Background(), "req-1"), req); err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+
+		if strings.Join(invoked, ",") != "search:x,book:paris" {
+			t.Errorf("unexpected tool calls: %v", invoked)
+		}
+
+		raw, _ := json.Marshal(pending)
+		if string(raw) != `[{"request_id":"req-1","tool":"book","args":{"val":"rome"}},{"request_id":"req-1","tool":"book","args":{"val":"paris"}}]` {
+			t.Errorf("unexpected pending calls: %s", raw)
+		}
+
+		if msg := mock.Calls()[1].Prompt(); !strings.Contains(msg, runtime.ErrApprovalDenied.Error()) || !strings.Contains(msg, "no budget left") {
+			t.Errorf("expected denial to be sent to the model, got %q", msg)
+		}
+	})
+
+	t.Run("tool policy", func(t *testing.T) {
+		mock := runtimetest.NewInvoker(t).Respond(
+			`[{"name":"delete","args":{"val":"db"}},{"name":"search","args":{"val":"x"}}]`,
+			`{"done":true,"out":{"result":"searched"}}`,
+		)
+
+		rt := runtime.NewRuntime(mock,
+			runtime.WithToolPolicy(func(ctx context.Context, tool string, args json.RawMessage) (json.RawMessage, error) {
+				if runtime.ValuesFromContext(ctx)["tenant"] != "admin" && tool == "delete" {
+					return nil, errors.New("tenant not allowed")
+				}
+				return json.RawMessage(`{"val":"x","limit":"10"}`), nil
+			}),
+			runtime.WithApproval(func(ctx context.Context, call runtime.PendingCall) error {
+				t.Errorf("denied calls must not be submitted for approval: %+v", call)
+				return nil
+			}),
+		)
+
+		var invoked []string
+		req := runtime.Request{
+			PromptTemplate: "Tool test",
+			Input:          &Input{},
+			Output:         &Output{},
+			InputSchema:    InputSchema,
+			OutputSchema:   OutputSchema,
+			ToolSpecs: []runtime.ToolSpec{
+				{Name: "delete", Schema: gojsonschema.NewStringLoader(`{"type":"object"}`), RequiresApproval: true},
+				{Name: "search", Schema: gojsonschema.NewStringLoader(`{"type":"object"}`)},
+			},
+			ToolUnmarshaller: func(name string, data []byte) (any, error) {
+				var args map[string]string
+				return args, json.Unmarshal(data, &args)
+			},
+			ToolInvoker: func(ctx context.Context, name string, in any) (any, error) {
+				args := in.(map[string]string)
+				invoked = append(invoked, name+":"+args["val"]+":"+args["limit"])
+				return "ok", nil
+			},
+		}
 
-func (m *mockInvoker) Invoke(ctx context.Context, input string, messages []Message) (string, error) {
-	if m.callCount >= len(m.responses) {
-		return "", fmt.Errorf("unexpected call")
-	}
-	resp := m.responses[m.callCount]
-	m.callCount++
-	return resp, nil
+		var transcript runtime.Transcript
+		ctx := runtime.WithValues(context.Background(), map[string]any{"tenant": "guest"})
+		if err := rt.Invoke(runtime.WithTranscript(ctx, &transcript), req); err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+
+		if strings.Join(invoked, ",") != "search:x:10" {
+			t.Errorf("expected only the rewritten search call to run, got %v", invoked)
+		}
+		for _, step := range transcript.Steps {
+			if step.Kind == runtime.StepToolCall && step.Tool == "search" && string(step.Args) != `{"val":"x","limit":"10"}` {
+				t.Errorf("expected the rewritten args to be recorded, got %s", step.Args)
+			}
+		}
+		if msg := mock.Calls()[1].Prompt(); !strings.Contains(msg, runtime.ErrToolDenied.Error()) || !strings.Contains(msg, "tenant not allowed") {
+			t.Errorf("expected denial to be sent to the model, got %q", msg)
+		}
+	})
+
+	t.Run("tool calls requiring approval are denied without an approval func", func(t *testing.T) {
+		mock := runtimetest.NewInvoker(t).Respond(
+			`{"name":"book","args":{"val":"rome"}}`,
+			`{"done":true,"out":{"result":"not booked"}}`,
+		)
+		rt := runtime.NewRuntime(mock)
+
+		req := runtime.Request{
+			PromptTemplate: "Tool test",
+			Input:          &Input{},
+			Output:         &Output{},
+			InputSchema:    InputSchema,
+			OutputSchema:   OutputSchema,
+			ToolSpecs:      []runtime.ToolSpec{{Name: "book", Schema: gojsonschema.NewStringLoader(`{"type":"object"}`), RequiresApproval: true}},
+			ToolUnmarshaller: func(name string, data []byte) (any, error) {
+				return nil, nil
+			},
+			ToolInvoker: func(ctx context.Context, name string, in any) (any, error) {
+				t.Error("tool must not be invoked")
+				return nil, nil
+			},
+		}
+
+		if err := rt.Invoke(context.Background(), req); err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+		if last := mock.LastCall().LastMessage(); !strings.Contains(last, runtime.ErrApprovalDenied.Error()) {
+			t.Errorf("expected denial to be sent to the model, got %q", last)
+		}
+	})
 }
@@ -0,0 +1,97 @@
+// Copyright (c) 2025 Suricata Contributors
+// Original Author: Stefano Scafiti
+//
+// This file is part of Suricata: Type-Safe AI Agents for Go.
+//
+// Licensed under the MIT License. You may obtain a copy of the License at
+//
+//	https://opensource.org/licenses/MIT
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package runtimetest
+
+import (
+	"context"
+	"slices"
+	"testing"
+
+	"github.com/ostafen/suricata/runtime"
+)
+
+// TestMemory checks that the memories returned by newMemory round-trip messages and truncate
+// the history as runtime.Memory requires. Each call of newMemory must return an empty memory.
+// Only the role and content of messages are compared, as some backends drop the rest.
+func TestMemory(t *testing.T, newMemory func(t *testing.T) runtime.Memory) {
+	msgs := []runtime.Message{
+		{Role: runtime.RoleSystem, Content: "system"},
+		{Role: runtime.RoleUser, Content: "question"},
+		{Role: runtime.RoleAgent, Content: "answer"},
+		{Role: runtime.RoleTool, Content: `{"temp":21}`},
+	}
+
+	t.Run("empty", func(t *testing.T) {
+		expectHistory(t, newMemory(t), nil)
+	})
+
+	t.Run("round trip", func(t *testing.T) {
+		m := newMemory(t)
+		appendMessages(t, m, msgs[:1]...)
+		appendMessages(t, m, msgs[1:]...)
+		appendMessages(t, m)
+		expectHistory(t, m, msgs)
+	})
+
+	t.Run("truncate", func(t *testing.T) {
+		m := newMemory(t)
+		appendMessages(t, m, msgs...)
+
+		truncate(t, m, len(msgs)+1)
+		expectHistory(t, m, msgs)
+
+		truncate(t, m, 2)
+		expectHistory(t, m, msgs[2:])
+
+		appendMessages(t, m, msgs[0])
+		expectHistory(t, m, []runtime.Message{msgs[2], msgs[3], msgs[0]})
+
+		truncate(t, m, 0)
+		expectHistory(t, m, nil)
+
+		appendMessages(t, m, msgs[1])
+		expectHistory(t, m, msgs[1:2])
+	})
+}
+
+func appendMessages(t *testing.T, m runtime.Memory, msgs ...runtime.Message) {
+	t.Helper()
+	if err := m.Append(context.Background(), msgs...); err != nil {
+		t.Fatalf("append: %v", err)
+	}
+}
+
+func truncate(t *testing.T, m runtime.Memory, n int) {
+	t.Helper()
+	if err := m.Truncate(context.Background(), n); err != nil {
+		t.Fatalf("truncate(%d): %v", n, err)
+	}
+}
+
+func expectHistory(t *testing.T, m runtime.Memory, want []runtime.Message) {
+	t.Helper()
+	got, err := m.History(context.Background())
+	if err != nil {
+		t.Fatalf("history: %v", err)
+	}
+
+	equal := func(a, b runtime.Message) bool {
+		return a.Role == b.Role && a.Content == b.Content
+	}
+	if !slices.EqualFunc(got, want, equal) {
+		t.Fatalf("expected history %v, got %v", want, got)
+	}
+}
@@ -0,0 +1,229 @@
+// Copyright (c) 2025 Suricata Contributors
+// Original Author: Stefano Scafiti
+//
+// This file is part of Suricata: Type-Safe AI Agents for Go.
+//
+// Licensed under the MIT License. You may obtain a copy of the License at
+//
+//	https://opensource.org/licenses/MIT
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+// Package runtimetest provides a scripted runtime.Invoker to unit-test generated agents
+// without calling a real LLM.
+//
+//	inv := runtimetest.NewInvoker(t)
+//	inv.Expect().PromptContains("Milan").RespondToolCall("FindFlights", args)
+//	inv.Expect().RespondFinal(&travel.FlightReply{})
+//
+//	agent := travel.NewFlightAgent(inv, tools)
+package runtimetest
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"strings"
+	"sync"
+	"testing"
+
+	"github.com/ostafen/suricata/runtime"
+)
+
+var ErrUnexpectedCall = errors.New("runtimetest: unexpected invoker call")
+
+// Call records the arguments of a single invocation.
+type Call struct {
+	System   string
+	Messages []runtime.Message
+}
+
+// LastMessage returns the text of the most recent message of the call.
+func (c Call) LastMessage() string {
+	if len(c.Messages) == 0 {
+		return ""
+	}
+	return c.Messages[len(c.Messages)-1].Text()
+}
+
+// Prompt returns the text of the messages sent after the last model response,
+// such as the outputs of all the tool calls it requested.
+func (c Call) Prompt() string {
+	start := len(c.Messages)
+	for start > 0 && c.Messages[start-1].Role != runtime.RoleAgent {
+		start--
+	}
+
+	texts := make([]string, 0, len(c.Messages)-start)
+	for _, m := range c.Messages[start:] {
+		texts = append(texts, m.Text())
+	}
+	return strings.Join(texts, "\n\n")
+}
+
+// ToolCall describes a tool call the scripted model should request.
+type ToolCall struct {
+	Name string `json:"name"`
+	Args any    `json:"args"`
+}
+
+// Invoker is a runtime.Invoker which replays a script of expectations, in order.
+type Invoker struct {
+	t testing.TB
+
+	mtx          sync.Mutex
+	expectations []*Expectation
+	calls        []Call
+}
+
+// NewInvoker returns an invoker whose unmet expectations are reported to t when the test ends.
+func NewInvoker(t testing.TB) *Invoker {
+	inv := &Invoker{t: t}
+	t.Cleanup(inv.AssertExpectations)
+	return inv
+}
+
+// Expect appends a new expectation to the script.
+func (inv *Invoker) Expect() *Expectation {
+	inv.mtx.Lock()
+	defer inv.mtx.Unlock()
+
+	e := &Expectation{}
+	inv.expectations = append(inv.expectations, e)
+	return e
+}
+
+// Respond appends one expectation for each canned response, with no constraints on the prompt.
+func (inv *Invoker) Respond(responses ...string) *Invoker {
+	for _, r := range responses {
+		inv.Expect().Respond(r)
+	}
+	return inv
+}
+
+func (inv *Invoker) Invoke(ctx context.Context, system string, messages []runtime.Message) (string, error) {
+	inv.mtx.Lock()
+	defer inv.mtx.Unlock()
+
+	call := Call{
+		System:   system,
+		Messages: append([]runtime.Message(nil), messages...),
+	}
+	inv.calls = append(inv.calls, call)
+
+	n := len(inv.calls)
+	if n > len(inv.expectations) {
+		inv.t.Errorf("runtimetest: unexpected call #%d with message: %s", n, call.LastMessage())
+		return "", ErrUnexpectedCall
+	}
+
+	e := inv.expectations[n-1]
+	e.check(inv.t, n, call)
+	return e.response, e.err
+}
+
+// Calls returns all the calls received so far.
+func (inv *Invoker) Calls() []Call {
+	inv.mtx.Lock()
+	defer inv.mtx.Unlock()
+
+	return append([]Call(nil), inv.calls...)
+}
+
+// LastCall returns the most recent call, or the zero Call if none was received.
+func (inv *Invoker) LastCall() Call {
+	inv.mtx.Lock()
+	defer inv.mtx.Unlock()
+
+	if len(inv.calls) == 0 {
+		return Call{}
+	}
+	return inv.calls[len(inv.calls)-1]
+}
+
+// AssertExpectations reports an error if some expectations were not consumed.
+func (inv *Invoker) AssertExpectations() {
+	inv.mtx.Lock()
+	defer inv.mtx.Unlock()
+
+	if missing := len(inv.expectations) - len(inv.calls); missing > 0 {
+		inv.t.Errorf("runtimetest: %d expected call(s) were not made", missing)
+	}
+}
+
+// Expectation describes one expected invocation and the scripted model answer.
+type Expectation struct {
+	promptContains []string
+	systemContains []string
+
+	response string
+	err      error
+}
+
+// PromptContains requires the messages sent after the last model response to contain all the given substrings.
+func (e *Expectation) PromptContains(substrs ...string) *Expectation {
+	e.promptContains = append(e.promptContains, substrs...)
+	return e
+}
+
+// SystemContains requires the system prompt to contain all the given substrings.
+func (e *Expectation) SystemContains(substrs ...string) *Expectation {
+	e.systemContains = append(e.systemContains, substrs...)
+	return e
+}
+
+// Respond makes the model answer with the raw string.
+func (e *Expectation) Respond(raw string) *Expectation {
+	e.response = raw
+	return e
+}
+
+// RespondJSON makes the model answer with the JSON encoding of v.
+func (e *Expectation) RespondJSON(v any) *Expectation {
+	data, err := json.Marshal(v)
+	if err != nil {
+		panic(fmt.Sprintf("runtimetest: marshal response: %s", err))
+	}
+	return e.Respond(string(data))
+}
+
+// RespondToolCall makes the model request a single tool call.
+func (e *Expectation) RespondToolCall(name string, args any) *Expectation {
+	return e.RespondJSON(ToolCall{Name: name, Args: args})
+}
+
+// RespondToolCalls makes the model request several tool calls at once.
+func (e *Expectation) RespondToolCalls(calls ...ToolCall) *Expectation {
+	return e.RespondJSON(calls)
+}
+
+// RespondFinal makes the model complete an agent loop with the given output.
+func (e *Expectation) RespondFinal(out any) *Expectation {
+	return e.RespondJSON(map[string]any{"done": true, "out": out})
+}
+
+// ReturnError makes the invoker fail with err.
+func (e *Expectation) ReturnError(err error) *Expectation {
+	e.err = err
+	return e
+}
+
+func (e *Expectation) check(t testing.TB, n int, call Call) {
+	prompt := call.Prompt()
+	for _, s := range e.promptContains {
+		if !strings.Contains(prompt, s) {
+			t.Errorf("runtimetest: call #%d: expected prompt to contain %q, got: %s", n, s, prompt)
+		}
+	}
+
+	for _, s := range e.systemContains {
+		if !strings.Contains(call.System, s) {
+			t.Errorf("runtimetest: call #%d: expected system prompt to contain %q, got: %s", n, s, call.System)
+		}
+	}
+}
@@ -0,0 +1,108 @@
+// Copyright (c) 2025 Suricata Contributors
+// Original Author: Stefano Scafiti
+//
+// This file is part of Suricata: Type-Safe AI Agents for Go.
+//
+// Licensed under the MIT License. You may obtain a copy of the License at
+//
+//	https://opensource.org/licenses/MIT
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package runtime
+
+import (
+	"errors"
+	"reflect"
+	"sync"
+
+	"github.com/xeipuuv/gojsonschema"
+)
+
+// Schema is a JSON schema which is compiled once, on first use.
+// It implements gojsonschema.JSONLoader, so it can be used wherever a loader is expected,
+// and validating against it skips the compilation of the schema.
+type Schema struct {
+	gojsonschema.JSONLoader
+
+	once     sync.Once
+	compiled *gojsonschema.Schema
+	err      error
+}
+
+// NewSchema returns the schema described by the JSON document src.
+func NewSchema(src string) *Schema {
+	return &Schema{JSONLoader: gojsonschema.NewStringLoader(src)}
+}
+
+// Compile returns the compiled schema. The result is computed on the first call only.
+func (s *Schema) Compile() (*gojsonschema.Schema, error) {
+	s.once.Do(func() {
+		s.compiled, s.err = gojsonschema.NewSchema(s.JSONLoader)
+	})
+	return s.compiled, s.err
+}
+
+// SchemaCacheSize is the maximum number of loaders whose compiled schema is cached by CompileSchema.
+const SchemaCacheSize = 1024
+
+var schemaCache = struct {
+	sync.Mutex
+	schemas map[gojsonschema.JSONLoader]*gojsonschema.Schema
+}{
+	schemas: make(map[gojsonschema.JSONLoader]*gojsonschema.Schema),
+}
+
+// CompileSchema compiles the schema held by loader. Schemas loaded by the same *Schema,
+// or by the same pointer loader (such as the ones returned by gojsonschema.NewStringLoader),
+// are only compiled once, so validating against a loader which is reused across calls is cheap.
+func CompileSchema(loader gojsonschema.JSONLoader) (*gojsonschema.Schema, error) {
+	if s, ok := loader.(*Schema); ok {
+		return s.Compile()
+	}
+
+	if loader == nil {
+		return nil, errors.New("missing schema")
+	}
+
+	// Only pointers identify a loader: other values may not even be comparable
+	if reflect.TypeOf(loader).Kind() != reflect.Pointer {
+		return gojsonschema.NewSchema(loader)
+	}
+
+	schemaCache.Lock()
+	compiled, ok := schemaCache.schemas[loader]
+	schemaCache.Unlock()
+
+	if ok {
+		return compiled, nil
+	}
+
+	compiled, err := gojsonschema.NewSchema(loader)
+	if err != nil {
+		return nil, err
+	}
+
+	schemaCache.Lock()
+	defer schemaCache.Unlock()
+
+	// Loaders created on each call would make the cache grow forever
+	if len(schemaCache.schemas) >= SchemaCacheSize {
+		clear(schemaCache.schemas)
+	}
+	schemaCache.schemas[loader] = compiled
+	return compiled, nil
+}
+
+// validate validates the document loaded by doc against schema, compiling schema only if needed.
+func validate(schema gojsonschema.JSONLoader, doc gojsonschema.JSONLoader) (*gojsonschema.Result, error) {
+	compiled, err := CompileSchema(schema)
+	if err != nil {
+		return nil, err
+	}
+	return compiled.Validate(doc)
+}
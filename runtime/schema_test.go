@@ -0,0 +1,74 @@
+// Copyright (c) 2025 Suricata Contributors
+// Original Author: Stefano Scafiti
+//
+// This file is part of Suricata: Type-Safe AI Agents for Go.
+//
+// Licensed under the MIT License. You may obtain a copy of the License at
+//
+//	https://opensource.org/licenses/MIT
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package runtime_test
+
+import (
+	"errors"
+	"testing"
+
+	"github.com/ostafen/suricata/runtime"
+	"github.com/xeipuuv/gojsonschema"
+)
+
+func TestSchema(t *testing.T) {
+	schema := runtime.NewSchema(`{"type":"object","properties":{"n":{"type":"integer"}},"required":["n"]}`)
+
+	first, err := schema.Compile()
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if second, _ := schema.Compile(); second != first {
+		t.Errorf("expected the schema to be compiled once")
+	}
+
+	var out struct{ N int }
+	if err := runtime.UnmarshalValidate([]byte(`{"n": 3}`), &out, schema); err != nil || out.N != 3 {
+		t.Errorf("unexpected result: %v, %+v", err, out)
+	}
+
+	if err := runtime.ValidateRawJSON([]byte(`{"n": "x"}`), schema); !errors.Is(err, runtime.ErrInvalidOutput) {
+		t.Errorf("expected ErrInvalidOutput, got %v", err)
+	}
+}
+
+func TestSchema_Invalid(t *testing.T) {
+	schema := runtime.NewSchema(`{"type": 1}`)
+
+	if err := runtime.ValidateRawJSON([]byte(`{}`), schema); err == nil {
+		t.Errorf("expected compilation error")
+	}
+}
+
+func TestCompileSchema_Cache(t *testing.T) {
+	loader := gojsonschema.NewStringLoader(`{"type":"string"}`)
+
+	first, err := runtime.CompileSchema(loader)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if second, _ := runtime.CompileSchema(loader); second != first {
+		t.Errorf("expected the schema of the same loader to be compiled once")
+	}
+
+	other, _ := runtime.CompileSchema(gojsonschema.NewStringLoader(`{"type":"string"}`))
+	if other == first {
+		t.Errorf("expected distinct loaders to be compiled separately")
+	}
+
+	if err := runtime.ValidateRawJSON([]byte(`1`), loader); !errors.Is(err, runtime.ErrInvalidOutput) {
+		t.Errorf("expected ErrInvalidOutput, got %v", err)
+	}
+}
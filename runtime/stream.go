@@ -0,0 +1,125 @@
+// Copyright (c) 2025 Suricata Contributors
+// Original Author: Stefano Scafiti
+//
+// This file is part of Suricata: Type-Safe AI Agents for Go.
+//
+// Licensed under the MIT License. You may obtain a copy of the License at
+//
+//	https://opensource.org/licenses/MIT
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package runtime
+
+import "context"
+
+// StreamingInvoker is implemented by invokers able to deliver a response incrementally.
+type StreamingInvoker interface {
+	Invoker
+
+	// InvokeStream behaves like Invoke, but calls onDelta with each chunk
+	// of the response as soon as it is received.
+	InvokeStream(ctx context.Context, systemPrompt string, messages []Message, onDelta func(delta string)) (string, error)
+}
+
+// InvokeStream streams the response of invoker, if supported.
+// Otherwise, the whole response is delivered as a single delta.
+// Invokers wrapping other ones use it to implement StreamingInvoker.
+func InvokeStream(ctx context.Context, invoker Invoker, systemPrompt string, messages []Message, onDelta func(delta string)) (string, error) {
+	if s, ok := invoker.(StreamingInvoker); ok {
+		return s.InvokeStream(ctx, systemPrompt, messages, onDelta)
+	}
+
+	out, err := invoker.Invoke(ctx, systemPrompt, messages)
+	if err != nil {
+		return "", err
+	}
+	onDelta(out)
+	return out, nil
+}
+
+// Stream delivers the chunks of the model responses of a streaming action, followed by its typed output.
+//
+// The action runs until its deltas are consumed, Result or Close is called, or its context is done:
+// callers abandoning a stream must call Close to release it.
+type Stream[T any] struct {
+	deltas chan string
+	done   chan struct{}
+	cancel context.CancelFunc
+	out    *T
+	err    error
+}
+
+// StartStream runs fn in a separate goroutine, forwarding the deltas it emits to the returned stream.
+// The output filled by fn is available through Result once fn returns.
+func StartStream[T any](ctx context.Context, fn func(ctx context.Context, out *T, onDelta func(delta string)) error) *Stream[T] {
+	ctx, cancel := context.WithCancel(ctx)
+
+	s := &Stream[T]{
+		deltas: make(chan string),
+		done:   make(chan struct{}),
+		cancel: cancel,
+		out:    new(T),
+	}
+
+	onDelta := func(delta string) {
+		select {
+		case s.deltas <- delta:
+		case <-ctx.Done():
+		}
+	}
+
+	go func() {
+		defer close(s.done)
+		defer close(s.deltas)
+		defer cancel()
+
+		s.err = fn(ctx, s.out, onDelta)
+	}()
+	return s
+}
+
+// StreamError returns a stream which fails immediately with err.
+func StreamError[T any](err error) *Stream[T] {
+	s := &Stream[T]{
+		deltas: make(chan string),
+		done:   make(chan struct{}),
+		cancel: func() {},
+		err:    err,
+	}
+	close(s.deltas)
+	close(s.done)
+	return s
+}
+
+// Deltas returns the chunks of the model responses, in order of arrival.
+// The channel is closed when the action completes.
+func (s *Stream[T]) Deltas() <-chan string {
+	return s.deltas
+}
+
+// Result waits for the action to complete and returns its output.
+// Deltas which have not been consumed yet are discarded.
+func (s *Stream[T]) Result() (*T, error) {
+	for range s.deltas {
+	}
+	<-s.done
+
+	if s.err != nil {
+		return nil, s.err
+	}
+	return s.out, nil
+}
+
+// Close cancels the action, if still running, and waits for it to return.
+// Deltas which have not been consumed yet are discarded.
+func (s *Stream[T]) Close() {
+	s.cancel()
+	for range s.deltas {
+	}
+	<-s.done
+}
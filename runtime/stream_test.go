@@ -0,0 +1,182 @@
+// Copyright (c) 2025 Suricata Contributors
+// Original Author: Stefano Scafiti
+//
+// This file is part of Suricata: Type-Safe AI Agents for Go.
+//
+// Licensed under the MIT License. You may obtain a copy of the License at
+//
+//	https://opensource.org/licenses/MIT
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+package runtime_test
+
+import (
+	"context"
+	"errors"
+	"slices"
+	"strings"
+	"testing"
+
+	"github.com/ostafen/suricata/runtime"
+	"github.com/xeipuuv/gojsonschema"
+)
+
+type chunkInvoker struct {
+	chunks []string
+}
+
+func (inv *chunkInvoker) Invoke(ctx context.Context, system string, messages []runtime.Message) (string, error) {
+	return strings.Join(inv.chunks, ""), nil
+}
+
+func (inv *chunkInvoker) InvokeStream(ctx context.Context, system string, messages []runtime.Message, onDelta func(string)) (string, error) {
+	for _, c := range inv.chunks {
+		onDelta(c)
+	}
+	return strings.Join(inv.chunks, ""), nil
+}
+
+func TestStartStream(t *testing.T) {
+	type Output struct {
+		Result string `json:"result"`
+	}
+
+	schema := gojsonschema.NewStringLoader(`{"type":"object","properties":{"result":{"type":"string"}},"required":["result"]}`)
+
+	for name, inv := range map[string]runtime.Invoker{
+		"streaming invoker": &chunkInvoker{chunks: []string{`{"result":`, `"hel`, `lo"}`}},
+		"plain invoker": runtime.InvokerFunc(func(ctx context.Context, system string, messages []runtime.Message) (string, error) {
+			return `{"result":"hello"}`, nil
+		}),
+	} {
+		t.Run(name, func(t *testing.T) {
+			rt := runtime.NewRuntime(inv)
+
+			stream := runtime.StartStream(context.Background(), func(ctx context.Context, out *Output, onDelta func(string)) error {
+				return rt.Invoke(ctx, runtime.Request{
+					PromptTemplate: "Hello",
+					Input:          map[string]any{},
+					Output:         out,
+					InputSchema:    gojsonschema.NewStringLoader(`{"type":"object"}`),
+					OutputSchema:   schema,
+					OnDelta:        onDelta,
+				})
+			})
+
+			var sb strings.Builder
+			for delta := range stream.Deltas() {
+				sb.WriteString(delta)
+			}
+
+			if sb.String() != `{"result":"hello"}` {
+				t.Errorf("unexpected deltas: %q", sb.String())
+			}
+
+			out, err := stream.Result()
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			if out.Result != "hello" {
+				t.Errorf("expected 'hello', got %q", out.Result)
+			}
+		})
+	}
+}
+
+func TestStream_Close(t *testing.T) {
+	returned := make(chan struct{})
+	stream := runtime.StartStream(context.Background(), func(ctx context.Context, out *string, onDelta func(string)) error {
+		defer close(returned)
+
+		for ctx.Err() == nil {
+			onDelta("chunk")
+		}
+		return ctx.Err()
+	})
+
+	if delta := <-stream.Deltas(); delta != "chunk" {
+		t.Fatalf("unexpected delta: %q", delta)
+	}
+
+	// The consumer stops reading: closing the stream must stop the action.
+	stream.Close()
+
+	select {
+	case <-returned:
+	default:
+		t.Fatal("expected the action to return")
+	}
+
+	if _, err := stream.Result(); !errors.Is(err, context.Canceled) {
+		t.Errorf("expected context.Canceled, got %v", err)
+	}
+}
+
+func TestInvokeStream_Wrappers(t *testing.T) {
+	chunks := []string{`{"result":`, `"hel`, `lo"}`}
+	failing := runtime.InvokerFunc(func(ctx context.Context, system string, messages []runtime.Message) (string, error) {
+		return "", errors.New("connection refused")
+	})
+	passthrough := func(next runtime.Invoker) runtime.Invoker {
+		return runtime.InvokerFunc(func(ctx context.Context, system string, messages []runtime.Message) (string, error) {
+			return next.Invoke(ctx, system, messages)
+		})
+	}
+
+	for name, inv := range map[string]runtime.Invoker{
+		"middleware chain": runtime.Chain(&chunkInvoker{chunks: chunks}, passthrough, passthrough),
+		"fallback":         runtime.NewFallbackInvoker(runtime.DefaultFallbackOptions(), failing, &chunkInvoker{chunks: chunks}),
+		"pool":             runtime.NewPooledInvoker(runtime.DefaultPoolOptions(), &chunkInvoker{chunks: chunks}),
+		"fallback in a chain": runtime.Chain(
+			runtime.NewFallbackInvoker(runtime.DefaultFallbackOptions(), failing, &chunkInvoker{chunks: chunks}),
+			passthrough,
+		),
+	} {
+		t.Run(name, func(t *testing.T) {
+			streaming, ok := inv.(runtime.StreamingInvoker)
+			if !ok {
+				t.Fatalf("expected %T to implement StreamingInvoker", inv)
+			}
+
+			var deltas []string
+			out, err := streaming.InvokeStream(context.Background(), "", nil, func(delta string) {
+				deltas = append(deltas, delta)
+			})
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			if out != strings.Join(chunks, "") || !slices.Equal(deltas, chunks) {
+				t.Errorf("expected the chunks to be streamed, got %q (%q)", deltas, out)
+			}
+		})
+	}
+}
+
+func TestChain_Streaming(t *testing.T) {
+	cached := func(next runtime.Invoker) runtime.Invoker {
+		return runtime.InvokerFunc(func(ctx context.Context, system string, messages []runtime.Message) (string, error) {
+			return "cached", nil
+		})
+	}
+
+	inv := runtime.Chain(&chunkInvoker{chunks: []string{"a", "b"}}, cached).(runtime.StreamingInvoker)
+
+	var deltas []string
+	if _, err := inv.InvokeStream(context.Background(), "", nil, func(delta string) { deltas = append(deltas, delta) }); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if !slices.Equal(deltas, []string{"cached"}) {
+		t.Errorf("expected the response of the middleware as a single delta, got %q", deltas)
+	}
+
+	plain := runtime.Chain(runtime.InvokerFunc(func(ctx context.Context, system string, messages []runtime.Message) (string, error) {
+		return "ok", nil
+	}), cached)
+	if _, ok := plain.(runtime.StreamingInvoker); ok {
+		t.Error("expected a chain of a non-streaming invoker not to stream")
+	}
+}
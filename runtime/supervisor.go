@@ -0,0 +1,159 @@
+// Copyright (c) 2025 Suricata Contributors
+// Original Author: Stefano Scafiti
+//
+// This file is part of Suricata: Type-Safe AI Agents for Go.
+//
+// Licensed under the MIT License. You may obtain a copy of the License at
+//
+//	https://opensource.org/licenses/MIT
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package runtime
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"strings"
+
+	"github.com/xeipuuv/gojsonschema"
+)
+
+// SupervisorInstructions are the system instructions of the requests issued by a Supervisor.
+const SupervisorInstructions = `You are a supervisor coordinating a team of agents.
+Given a request, pick the single agent action best suited to fulfill it, and extract its input from the request.`
+
+// Route is an agent action a Supervisor can delegate requests to.
+type Route struct {
+	Agent       string
+	Action      string
+	Description string
+	InputSchema gojsonschema.JSONLoader
+	// Invoke runs the action on an input matching InputSchema and returns its output.
+	Invoke func(ctx context.Context, in json.RawMessage) (any, error)
+}
+
+// Name returns the identifier of the route, in the form "agent.action".
+func (r *Route) Name() string {
+	return r.Agent + "." + r.Action
+}
+
+// Delegation is the outcome of a request handled by a Supervisor.
+type Delegation struct {
+	Agent  string
+	Action string
+	Input  json.RawMessage
+	Output any // Output of the action, as returned by Route.Invoke
+}
+
+// Supervisor routes free-form requests to the agent action best suited to handle them.
+type Supervisor struct {
+	runtime *Runtime
+	routes  map[string]*Route
+	prompt  string // Description of the routes
+	schema  *Schema
+}
+
+// NewSupervisor returns a supervisor delegating requests to the given routes.
+// Options configure the runtime used to select a route.
+func NewSupervisor(invoker Invoker, routes []Route, opts ...Option) (*Supervisor, error) {
+	if len(routes) == 0 {
+		return nil, errors.New("supervisor: no routes")
+	}
+
+	s := &Supervisor{
+		runtime: NewRuntime(invoker, opts...),
+		routes:  make(map[string]*Route, len(routes)),
+	}
+
+	var (
+		prompt   strings.Builder
+		variants = make([]any, 0, len(routes))
+	)
+
+	prompt.WriteString("[ROUTES]\n\n")
+	for i := range routes {
+		route := &routes[i]
+		if _, ok := s.routes[route.Name()]; ok {
+			return nil, fmt.Errorf("supervisor: duplicate route %q", route.Name())
+		}
+		s.routes[route.Name()] = route
+
+		if route.InputSchema == nil {
+			return nil, fmt.Errorf("supervisor: route %q has no input schema", route.Name())
+		}
+		inSchema, err := route.InputSchema.LoadJSON()
+		if err != nil {
+			return nil, fmt.Errorf("supervisor: load input schema of route %q: %w", route.Name(), err)
+		}
+
+		variants = append(variants, map[string]any{
+			"type": "object",
+			"properties": map[string]any{
+				"route": map[string]any{"type": "string", "enum": []string{route.Name()}},
+				"input": inSchema,
+			},
+			"required": []string{"route", "input"},
+		})
+		fmt.Fprintf(&prompt, "Route: %s\nDescription: %s\n\n", route.Name(), route.Description)
+	}
+
+	rawSchema, err := json.Marshal(map[string]any{"type": "object", "oneOf": variants})
+	if err != nil {
+		return nil, fmt.Errorf("supervisor: %w", err)
+	}
+
+	s.prompt = prompt.String()
+	s.schema = NewSchema(string(rawSchema))
+	return s, nil
+}
+
+const supervisorPrompt = "{{.routes}}[REQUEST]\n\n{{.request}}"
+
+var supervisorInputSchema = NewSchema(`{"type":"object","properties":{"routes":{"type":"string"},"request":{"type":"string"}},"required":["routes","request"]}`)
+
+// Delegate asks the model to pick a route for request, then invokes it.
+// Routing decisions whose input does not match the schema of the route are sent back to the model for repair.
+func (s *Supervisor) Delegate(ctx context.Context, request string) (*Delegation, error) {
+	var decision struct {
+		Route string          `json:"route"`
+		Input json.RawMessage `json:"input"`
+	}
+
+	err := s.runtime.Invoke(ctx, Request{
+		SkipInput:      true,
+		Instructions:   SupervisorInstructions,
+		PromptTemplate: supervisorPrompt,
+		Input:          map[string]any{"routes": s.prompt, "request": request},
+		Output:         &decision,
+		InputSchema:    supervisorInputSchema,
+		OutputSchema:   s.schema,
+		Retry:          DefaultRetryPolicy(),
+	})
+	if err != nil {
+		return nil, fmt.Errorf("select route: %w", err)
+	}
+
+	route, ok := s.routes[decision.Route]
+	if !ok {
+		return nil, fmt.Errorf("select route: unknown route %q", decision.Route)
+	}
+
+	out, err := route.Invoke(ctx, decision.Input)
+	if err != nil {
+		return nil, fmt.Errorf("delegate to %s: %w", route.Name(), err)
+	}
+
+	return &Delegation{
+		Agent:  route.Agent,
+		Action: route.Action,
+		Input:  decision.Input,
+		Output: out,
+	}, nil
+}
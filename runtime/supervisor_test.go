@@ -0,0 +1,80 @@
+// Copyright (c) 2025 Suricata Contributors
+// Original Author: Stefano Scafiti
+//
+// This file is part of Suricata: Type-Safe AI Agents for Go.
+//
+// Licensed under the MIT License. You may obtain a copy of the License at
+//
+//	https://opensource.org/licenses/MIT
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package runtime_test
+
+import (
+	"context"
+	"encoding/json"
+	"testing"
+
+	"github.com/ostafen/suricata/runtime"
+	"github.com/ostafen/suricata/runtime/runtimetest"
+)
+
+func TestSupervisor_Delegate(t *testing.T) {
+	type Booking struct {
+		City string `json:"city"`
+	}
+
+	schema := runtime.NewSchema(`{"type":"object","properties":{"city":{"type":"string"}},"required":["city"]}`)
+	route := func(agent, action string) runtime.Route {
+		return runtime.Route{
+			Agent:       agent,
+			Action:      action,
+			Description: "Handles " + action + " requests",
+			InputSchema: schema,
+			Invoke: func(ctx context.Context, in json.RawMessage) (any, error) {
+				var b Booking
+				if err := json.Unmarshal(in, &b); err != nil {
+					return nil, err
+				}
+				return &Booking{City: action + ":" + b.City}, nil
+			},
+		}
+	}
+
+	inv := runtimetest.NewInvoker(t)
+	inv.Expect().
+		PromptContains("Route: travel.flights", "Route: travel.hotels", "Handles hotels requests", "a room in Rome").
+		RespondJSON(map[string]any{"route": "travel.hotels", "input": map[string]any{}}) // input does not match the schema
+	inv.Expect().RespondJSON(map[string]any{"route": "travel.hotels", "input": map[string]any{"city": "Rome"}})
+
+	sup, err := runtime.NewSupervisor(inv, []runtime.Route{route("travel", "flights"), route("travel", "hotels")})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	d, err := sup.Delegate(context.Background(), "Book a room in Rome")
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	inv.AssertExpectations()
+
+	if d.Agent != "travel" || d.Action != "hotels" || string(d.Input) != `{"city":"Rome"}` {
+		t.Errorf("unexpected delegation: %+v", d)
+	}
+	if out, ok := d.Output.(*Booking); !ok || out.City != "hotels:Rome" {
+		t.Errorf("unexpected output: %#v", d.Output)
+	}
+}
+
+func TestNewSupervisor_DuplicateRoute(t *testing.T) {
+	route := runtime.Route{Agent: "a", Action: "b", InputSchema: runtime.NewSchema(`{"type":"object"}`)}
+
+	if _, err := runtime.NewSupervisor(runtimetest.NewInvoker(t), []runtime.Route{route, route}); err == nil {
+		t.Error("expected error for duplicate routes")
+	}
+}
@@ -0,0 +1,216 @@
+// Copyright (c) 2025 Suricata Contributors
+// Original Author: Stefano Scafiti
+//
+// This file is part of Suricata: Type-Safe AI Agents for Go.
+//
+// Licensed under the MIT License. You may obtain a copy of the License at
+//
+//	https://opensource.org/licenses/MIT
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package runtime
+
+import (
+	"encoding/json"
+	"fmt"
+	"maps"
+	"reflect"
+	"slices"
+	"strings"
+	"text/template"
+	"time"
+)
+
+// TemplateFuncs returns the functions available to prompt templates:
+//
+//   - join: joins a list of strings with a separator.
+//   - upper, lower, trim: change the case of a string, or remove its surrounding spaces.
+//   - default: returns its first argument if the second one is empty (e.g. {{default "none" .Notes}}).
+//   - toJson: encodes a value as JSON.
+//   - formatDate: formats a time.Time, or a date string in RFC 3339 or YYYY-MM-DD format, with a Go layout.
+//   - truncate: cuts a string to at most n characters, marking the cut with "...".
+//   - table: renders a list of structs or maps as a markdown table.
+//   - meta: returns the values attached to the context of the call with WithValues (e.g. {{meta.UserLocale}}).
+func TemplateFuncs() template.FuncMap {
+	return template.FuncMap{
+		"join":       strings.Join,
+		"upper":      strings.ToUpper,
+		"lower":      strings.ToLower,
+		"trim":       strings.TrimSpace,
+		"default":    defaultValue,
+		"toJson":     toJSON,
+		"formatDate": formatDate,
+		"truncate":   truncate,
+		"table":      markdownTable,
+		"meta":       noValues,
+	}
+}
+
+// ParsePrompt parses a prompt template. Partials are named sub-templates, which the
+// prompt and the other partials can include with {{template "name" .}}.
+func ParsePrompt(text string, partials map[string]string) (*template.Template, error) {
+	tmpl := template.New("prompt").Funcs(TemplateFuncs())
+	for _, name := range slices.Sorted(maps.Keys(partials)) {
+		if _, err := tmpl.New(name).Parse(partials[name]); err != nil {
+			return nil, fmt.Errorf("partial %q: %w", name, err)
+		}
+	}
+	return tmpl.Parse(text)
+}
+
+func noValues() map[string]any {
+	return nil
+}
+
+func defaultValue(def, value any) any {
+	if value == nil {
+		return def
+	}
+
+	v := reflect.ValueOf(value)
+	if v.IsZero() || ((v.Kind() == reflect.Slice || v.Kind() == reflect.Map) && v.Len() == 0) {
+		return def
+	}
+	return value
+}
+
+func toJSON(v any) (string, error) {
+	data, err := json.Marshal(v)
+	return string(data), err
+}
+
+func formatDate(layout string, value any) (string, error) {
+	var t time.Time
+	switch v := value.(type) {
+	case time.Time:
+		t = v
+	case *time.Time:
+		if v == nil {
+			return "", nil
+		}
+		t = *v
+	case string:
+		var err error
+		if t, err = time.Parse(time.RFC3339, v); err != nil {
+			if t, err = time.Parse(time.DateOnly, v); err != nil {
+				return "", fmt.Errorf("formatDate: invalid date %q", v)
+			}
+		}
+	default:
+		return "", fmt.Errorf("formatDate: unsupported value of type %T", value)
+	}
+	return t.Format(layout), nil
+}
+
+func truncate(n int, s string) string {
+	runes := []rune(s)
+	if n < 0 || len(runes) <= n {
+		return s
+	}
+	return string(runes[:n]) + "..."
+}
+
+// markdownTable renders rows, a slice of structs or maps, as a markdown table. The columns
+// of structs are their exported fields, named after their JSON tags; those of maps are their
+// keys, sorted.
+func markdownTable(rows any) (string, error) {
+	v := reflect.Indirect(reflect.ValueOf(rows))
+	if v.Kind() != reflect.Slice && v.Kind() != reflect.Array {
+		return "", fmt.Errorf("table: expected a list, got %T", rows)
+	}
+
+	var (
+		columns []string
+		cells   [][]string
+	)
+	for i := 0; i < v.Len(); i++ {
+		row := reflect.Indirect(v.Index(i))
+		if row.Kind() == reflect.Interface {
+			row = reflect.Indirect(row.Elem())
+		}
+
+		values := make(map[string]any)
+		switch row.Kind() {
+		case reflect.Struct:
+			for _, field := range reflect.VisibleFields(row.Type()) {
+				name, ok := jsonFieldName(field)
+				if !ok {
+					continue
+				}
+				values[name] = row.FieldByIndex(field.Index).Interface()
+				if i == 0 {
+					columns = append(columns, name)
+				}
+			}
+		case reflect.Map:
+			for _, key := range row.MapKeys() {
+				values[fmt.Sprint(key.Interface())] = row.MapIndex(key).Interface()
+			}
+			if i == 0 {
+				columns = slices.Sorted(maps.Keys(values))
+			}
+		default:
+			return "", fmt.Errorf("table: expected a list of structs or maps, got %s", row.Type())
+		}
+
+		cell := make([]string, len(columns))
+		for j, col := range columns {
+			cell[j] = tableCell(values[col])
+		}
+		cells = append(cells, cell)
+	}
+
+	if len(columns) == 0 {
+		return "", nil
+	}
+
+	var sb strings.Builder
+	sb.WriteString("| " + strings.Join(columns, " | ") + " |\n")
+	sb.WriteString("|" + strings.Repeat(" --- |", len(columns)) + "\n")
+	for _, cell := range cells {
+		sb.WriteString("| " + strings.Join(cell, " | ") + " |\n")
+	}
+	return sb.String(), nil
+}
+
+// jsonFieldName returns the JSON name of a struct field, and whether it is encoded at all.
+func jsonFieldName(field reflect.StructField) (string, bool) {
+	if !field.IsExported() || field.Anonymous {
+		return "", false
+	}
+
+	tag := field.Tag.Get("json")
+	if tag == "-" {
+		return "", false
+	}
+	if name, _, _ := strings.Cut(tag, ","); name != "" {
+		return name, true
+	}
+	return field.Name, true
+}
+
+func tableCell(v any) string {
+	var s string
+	switch v := v.(type) {
+	case nil:
+	case string:
+		s = v
+	case fmt.Stringer:
+		s = v.String()
+	default:
+		data, err := json.Marshal(v)
+		if err != nil {
+			s = fmt.Sprint(v)
+		} else {
+			s = string(data)
+		}
+	}
+
+	s = strings.ReplaceAll(s, "|", `\|`)
+	return strings.ReplaceAll(s, "\n", " ")
+}
@@ -0,0 +1,114 @@
+// Copyright (c) 2025 Suricata Contributors
+// Original Author: Stefano Scafiti
+//
+// This file is part of Suricata: Type-Safe AI Agents for Go.
+//
+// Licensed under the MIT License. You may obtain a copy of the License at
+//
+//	https://opensource.org/licenses/MIT
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package runtime_test
+
+import (
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/ostafen/suricata/runtime"
+	"github.com/xeipuuv/gojsonschema"
+)
+
+func TestTemplateFuncs(t *testing.T) {
+	type Flight struct {
+		Code  string `json:"code"`
+		Price int    `json:"price"`
+		Notes string `json:"-"`
+	}
+
+	tests := []struct {
+		name     string
+		template string
+		input    any
+		want     string
+	}{
+		{"join", `{{join .Tags ", "}}`, map[string]any{"Tags": []string{"a", "b"}}, "a, b"},
+		{"upper", `{{upper .Name}}`, map[string]any{"Name": "rome"}, "ROME"},
+		{"lower", `{{.Name | lower}}`, map[string]any{"Name": "ROME"}, "rome"},
+		{"default", `{{default "none" .Notes}}`, map[string]any{"Notes": ""}, "none"},
+		{"default set", `{{default "none" .Notes}}`, map[string]any{"Notes": "window"}, "window"},
+		{"toJson", `{{toJson .}}`, map[string]any{"a": 1}, `{"a":1}`},
+		{"formatDate", `{{formatDate "Jan 2, 2006" .Date}}`, map[string]any{"Date": "2024-05-01"}, "May 1, 2024"},
+		{"formatDate time", `{{formatDate "2006" .Date}}`, map[string]any{"Date": time.Date(2024, 5, 1, 0, 0, 0, 0, time.UTC)}, "2024"},
+		{"truncate", `{{truncate 4 .Text}}`, map[string]any{"Text": "itinerary"}, "itin..."},
+		{"truncate short", `{{truncate 20 .Text}}`, map[string]any{"Text": "itinerary"}, "itinerary"},
+		{"no escaping", `{{.Text}}`, map[string]any{"Text": `"it's" <b>`}, `"it's" <b>`},
+		{
+			"table",
+			`{{table .}}`,
+			[]Flight{{Code: "AZ1", Price: 120}, {Code: "A|B", Price: 80}},
+			"| code | price |\n| --- | --- |\n| AZ1 | 120 |\n| A\\|B | 80 |\n",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			tmpl, err := runtime.ParsePrompt(tt.template, nil)
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+
+			var sb strings.Builder
+			if err := tmpl.Execute(&sb, tt.input); err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			if sb.String() != tt.want {
+				t.Errorf("got %q, want %q", sb.String(), tt.want)
+			}
+		})
+	}
+}
+
+func TestRenderPrompt_Partials(t *testing.T) {
+	req := runtime.Request{
+		PromptTemplate: `Greet {{template "person" .}}.`,
+		PromptPartials: map[string]string{"person": `{{.Name}} <{{.Email}}>`},
+		Input:          map[string]any{"Name": "Ada", "Email": "ada@example.com"},
+		InputSchema:    gojsonschema.NewStringLoader(`{"type":"object"}`),
+		SkipInput:      true,
+	}
+
+	prompt, err := runtime.RenderPrompt(req)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if !strings.Contains(prompt, "Greet Ada <ada@example.com>.") {
+		t.Errorf("partial not rendered: %q", prompt)
+	}
+}
+
+func TestRenderPrompt_StrictTemplate(t *testing.T) {
+	req := runtime.Request{
+		PromptTemplate: `Hello {{.Nmae}}`,
+		Input:          map[string]any{"Name": "Ada"},
+		InputSchema:    gojsonschema.NewStringLoader(`{"type":"object"}`),
+	}
+
+	prompt, err := runtime.RenderPrompt(req)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if !strings.Contains(prompt, "Hello <no value>") {
+		t.Errorf("expected missing key to render as <no value>: %q", prompt)
+	}
+
+	req.StrictTemplate = true
+	if _, err := runtime.RenderPrompt(req); err == nil || !strings.Contains(err.Error(), `map has no entry for key "Nmae"`) {
+		t.Errorf("expected missing key error, got %v", err)
+	}
+}
@@ -0,0 +1,197 @@
+// Copyright (c) 2025 Suricata Contributors
+// Original Author: Stefano Scafiti
+//
+// This file is part of Suricata: Type-Safe AI Agents for Go.
+//
+// Licensed under the MIT License. You may obtain a copy of the License at
+//
+//	https://opensource.org/licenses/MIT
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package runtime
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"unicode"
+	"unicode/utf8"
+)
+
+// ErrContextExceeded is returned, wrapped in a *ContextError, when a prompt does not fit
+// the context window of the model.
+var ErrContextExceeded = errors.New("context window exceeded")
+
+// Tokenizer counts the tokens of a text, as the tokenizer of a model would.
+// Invokers implement it to refine the estimates of the runtime for their model.
+type Tokenizer interface {
+	CountTokens(text string) int
+}
+
+// TokenizerFunc adapts a function to the Tokenizer interface.
+type TokenizerFunc func(text string) int
+
+func (f TokenizerFunc) CountTokens(text string) int {
+	return f(text)
+}
+
+var (
+	// HeuristicTokenizer assumes four bytes per token, which roughly holds for English
+	// text with most tokenizers. It is used for models whose tokenizer is unknown.
+	HeuristicTokenizer Tokenizer = TokenizerFunc(func(text string) int {
+		return len(text) / 4
+	})
+
+	// TiktokenTokenizer approximates the byte-pair encodings of OpenAI models, by splitting
+	// text the way tiktoken does before merging bytes: words with their leading space,
+	// groups of up to three digits, runs of punctuation and runs of whitespace.
+	TiktokenTokenizer Tokenizer = TokenizerFunc(tiktokenCount)
+)
+
+// TokenizerOf returns the tokenizer of invoker, if it implements Tokenizer, or HeuristicTokenizer.
+func TokenizerOf(invoker Invoker) Tokenizer {
+	if tok, ok := invoker.(Tokenizer); ok {
+		return tok
+	}
+	return HeuristicTokenizer
+}
+
+// ContextSizer is implemented by invokers which know the size of the context window of
+// their model, in tokens. Zero means that the size is unknown.
+type ContextSizer interface {
+	ContextSize() int
+}
+
+// CountPromptTokens estimates with tok the tokens of the system prompt and of messages,
+// including the few tokens framing each message.
+func CountPromptTokens(tok Tokenizer, system string, messages []Message) int {
+	tokens := 0
+	if system != "" {
+		tokens += 4 + tok.CountTokens(system)
+	}
+	for _, m := range messages {
+		tokens += 4 + tok.CountTokens(m.Content)
+	}
+	return tokens
+}
+
+func tiktokenCount(text string) int {
+	tokens := 0
+	for i := 0; i < len(text); {
+		r, _ := utf8.DecodeRuneInString(text[i:])
+		switch {
+		case unicode.IsSpace(r):
+			j := scan(text, i, unicode.IsSpace)
+			// A single space is merged with the word or the symbols which follow it
+			if j == len(text) || j-i > 1 || text[i] != ' ' {
+				tokens++
+			}
+			i = j
+		case isWordRune(r):
+			j := scan(text, i, isWordRune)
+			ascii := 0
+			for _, r := range text[i:j] {
+				if r < utf8.RuneSelf {
+					ascii++
+				} else {
+					tokens++
+				}
+			}
+			tokens += (ascii + 5) / 6
+			i = j
+		case unicode.IsNumber(r):
+			j := scan(text, i, unicode.IsNumber)
+			tokens += (utf8.RuneCountInString(text[i:j]) + 2) / 3
+			i = j
+		default:
+			j := scan(text, i, func(r rune) bool {
+				return !unicode.IsSpace(r) && !isWordRune(r) && !unicode.IsNumber(r)
+			})
+			tokens += (utf8.RuneCountInString(text[i:j]) + 1) / 2
+			i = j
+		}
+	}
+	return tokens
+}
+
+func isWordRune(r rune) bool {
+	return unicode.IsLetter(r) || unicode.IsMark(r)
+}
+
+// scan returns the end of the run of runes of text, starting at i, which satisfy f.
+func scan(text string, i int, f func(r rune) bool) int {
+	for i < len(text) {
+		r, size := utf8.DecodeRuneInString(text[i:])
+		if !f(r) {
+			break
+		}
+		i += size
+	}
+	return i
+}
+
+// ContextCheck compares the estimated size of each prompt, together with the tokens
+// reserved for the reply by ModelOptions.MaxTokens, with the context window of the model.
+type ContextCheck struct {
+	Size      int       // Context window, in tokens. Zero means the ContextSize of the invoker, if it is a ContextSizer
+	Tokenizer Tokenizer // Defaults to the tokenizer of the invoker (see TokenizerOf)
+	Strict    bool      // Fail oversized requests with a *ContextError, instead of only notifying OnContextExceeded hooks
+}
+
+// ContextError is returned by strict context checks. It matches ErrContextExceeded with errors.Is.
+type ContextError struct {
+	Tokens int // Estimated tokens of the prompt and of the reply
+	Limit  int // Context window of the model
+}
+
+func (e *ContextError) Error() string {
+	return fmt.Sprintf("%s: ~%d tokens for a window of %d", ErrContextExceeded, e.Tokens, e.Limit)
+}
+
+func (e *ContextError) Unwrap() error {
+	return ErrContextExceeded
+}
+
+// WithContextCheck checks, before each call to the model, that the conversation fits its context
+// window. Requests which do not fit are reported to OnContextExceeded hooks, and fail if check is strict.
+// The context size and the tokenizer are those of the invoker passed to NewRuntime, even when wrapped by
+// the middlewares of WithMiddleware.
+func WithContextCheck(check ContextCheck) Option {
+	return func(r *Runtime) {
+		r.contextCheck = &check
+	}
+}
+
+// checkContext applies the context check of r to the system prompt and messages about to be sent.
+func (r *Runtime) checkContext(ctx context.Context, system string, messages []Message) error {
+	check := r.contextCheck
+
+	limit := check.Size
+	if cs, ok := r.base.(ContextSizer); ok && limit == 0 {
+		limit = cs.ContextSize()
+	}
+	if limit <= 0 {
+		return nil
+	}
+
+	tok := check.Tokenizer
+	if tok == nil {
+		tok = TokenizerOf(r.base)
+	}
+
+	tokens := CountPromptTokens(tok, system, messages) + ModelOptionsFromContext(ctx).MaxTokens
+	if tokens <= limit {
+		return nil
+	}
+
+	r.hooks.contextExceeded(ctx, tokens, limit)
+	if check.Strict {
+		return &ContextError{Tokens: tokens, Limit: limit}
+	}
+	return nil
+}
@@ -0,0 +1,129 @@
+// Copyright (c) 2025 Suricata Contributors
+// Original Author: Stefano Scafiti
+//
+// This file is part of Suricata: Type-Safe AI Agents for Go.
+//
+// Licensed under the MIT License. You may obtain a copy of the License at
+//
+//	https://opensource.org/licenses/MIT
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package runtime_test
+
+import (
+	"context"
+	"errors"
+	"strings"
+	"testing"
+
+	"github.com/ostafen/suricata/runtime"
+)
+
+func TestTiktokenTokenizer(t *testing.T) {
+	cases := map[string]int{
+		"":                       0,
+		"hello world":            2,
+		"internationalization":   4,
+		"  indented":             3,
+		"year 2025, month 10":    6,
+		`{"city": "Catania"}`:    7,
+		"你好世界":                   4,
+		"line one\n\nline two\n": 6,
+	}
+	for text, want := range cases {
+		if got := runtime.TiktokenTokenizer.CountTokens(text); got != want {
+			t.Errorf("%q: expected %d tokens, got %d", text, want, got)
+		}
+	}
+}
+
+func TestCountPromptTokens(t *testing.T) {
+	messages := []runtime.Message{
+		{Role: runtime.RoleUser, Content: strings.Repeat("a", 40)},
+		{Role: runtime.RoleAgent, Content: strings.Repeat("b", 8)},
+	}
+	if got := runtime.CountPromptTokens(runtime.HeuristicTokenizer, "", messages); got != runtime.EstimateTokens(messages) {
+		t.Errorf("expected the heuristic count to match EstimateTokens, got %d", got)
+	}
+	if got := runtime.CountPromptTokens(runtime.HeuristicTokenizer, "be brief", messages); got != 26 {
+		t.Errorf("expected 26 tokens, got %d", got)
+	}
+}
+
+// sizedInvoker is an invoker whose model has a context window of size tokens.
+type sizedInvoker struct {
+	runtime.InvokerFunc
+	size int
+}
+
+func (inv sizedInvoker) ContextSize() int {
+	return inv.size
+}
+
+func TestWithContextCheck(t *testing.T) {
+	calls := 0
+	inv := sizedInvoker{
+		InvokerFunc: func(ctx context.Context, system string, messages []runtime.Message) (string, error) {
+			calls++
+			return `{"ok": true}`, nil
+		},
+		size: 16,
+	}
+
+	var exceeded []int
+	hooks := runtime.WithHooks(runtime.Hooks{
+		OnContextExceeded: func(ctx context.Context, tokens, limit int) {
+			exceeded = append(exceeded, tokens, limit)
+		},
+	})
+
+	invoke := func(rt *runtime.Runtime) error {
+		var out struct {
+			OK bool `json:"ok"`
+		}
+		return rt.Invoke(context.Background(), runtime.Request{
+			PromptTemplate: "Summarize the following report, keeping only the figures which changed since last year.",
+			Input:          &struct{}{},
+			InputSchema:    runtime.NewSchema(`{"type":"object"}`),
+			Output:         &out,
+			OutputSchema:   runtime.NewSchema(`{"type":"object"}`),
+		})
+	}
+
+	if err := invoke(runtime.NewRuntime(inv, hooks, runtime.WithContextCheck(runtime.ContextCheck{}))); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if len(exceeded) != 2 || exceeded[0] <= 16 || exceeded[1] != 16 {
+		t.Errorf("expected the hook to report the context window of the invoker, got %v", exceeded)
+	}
+	if calls != 1 {
+		t.Errorf("expected the model to be called despite the warning, got %d calls", calls)
+	}
+
+	err := invoke(runtime.NewRuntime(inv, runtime.WithContextCheck(runtime.ContextCheck{Strict: true})))
+	var ctxErr *runtime.ContextError
+	if !errors.Is(err, runtime.ErrContextExceeded) || !errors.As(err, &ctxErr) || ctxErr.Limit != 16 {
+		t.Fatalf("expected a context error, got %v", err)
+	}
+	if calls != 1 {
+		t.Errorf("expected strict checks not to call the model, got %d calls", calls)
+	}
+
+	passThrough := func(next runtime.Invoker) runtime.Invoker {
+		return runtime.InvokerFunc(next.Invoke)
+	}
+	err = invoke(runtime.NewRuntime(inv, runtime.WithMiddleware(passThrough), runtime.WithContextCheck(runtime.ContextCheck{Strict: true})))
+	if !errors.Is(err, runtime.ErrContextExceeded) {
+		t.Errorf("expected middlewares not to hide the context size of the invoker, got %v", err)
+	}
+
+	big := runtime.ContextCheck{Size: 1 << 20, Strict: true}
+	if err := invoke(runtime.NewRuntime(inv, runtime.WithContextCheck(big))); err != nil {
+		t.Errorf("expected the size of the check to take precedence, got %v", err)
+	}
+}
@@ -0,0 +1,53 @@
+// Copyright (c) 2025 Suricata Contributors
+// Original Author: Stefano Scafiti
+//
+// This file is part of Suricata: Type-Safe AI Agents for Go.
+//
+// Licensed under the MIT License. You may obtain a copy of the License at
+//
+//	https://opensource.org/licenses/MIT
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package tools
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+)
+
+// Adapt adapts fn, the implementation of a builtin tool, to the method generated for it in the tools
+// interface of an agent, whose In and Out types are the messages of the spec matching I and O.
+// Values are converted through their JSON encoding.
+func Adapt[In, Out, I, O any](name string, fn func(ctx context.Context, in *I) (*O, error)) func(ctx context.Context, in *In) (*Out, error) {
+	return func(ctx context.Context, in *In) (*Out, error) {
+		var args I
+		if err := convert(in, &args); err != nil {
+			return nil, fmt.Errorf("convert %s input: %w", name, err)
+		}
+
+		res, err := fn(ctx, &args)
+		if err != nil {
+			return nil, err
+		}
+
+		var out Out
+		if err := convert(res, &out); err != nil {
+			return nil, fmt.Errorf("convert %s output: %w", name, err)
+		}
+		return &out, nil
+	}
+}
+
+func convert(from, to any) error {
+	data, err := json.Marshal(from)
+	if err != nil {
+		return err
+	}
+	return json.Unmarshal(data, to)
+}
@@ -0,0 +1,201 @@
+// Copyright (c) 2025 Suricata Contributors
+// Original Author: Stefano Scafiti
+//
+// This file is part of Suricata: Type-Safe AI Agents for Go.
+//
+// Licensed under the MIT License. You may obtain a copy of the License at
+//
+//	https://opensource.org/licenses/MIT
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+// Package exec implements a tool running commands in a sandbox, with a time limit, a memory
+// limit, an isolated working directory and a bounded output. Agents declare it in specs
+// with "builtin: exec".
+//
+// The sandbox limits the resources of the commands, but does not isolate them from the host
+// as a container would: only allow commands which are safe to run with the privileges of
+// the process, and consider requiring the approval of the calls.
+package exec
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"fmt"
+	"os"
+	osexec "os/exec"
+	"slices"
+	"strconv"
+	"time"
+
+	"github.com/ostafen/suricata/runtime"
+	"github.com/ostafen/suricata/runtime/tools"
+)
+
+// Default limits of a Sandbox.
+const (
+	DefaultTimeout   = 10 * time.Second
+	DefaultMaxOutput = 64 << 10
+)
+
+// ErrCommandNotAllowed is returned when the command of a call is not allowed by the sandbox.
+var ErrCommandNotAllowed = errors.New("command not allowed")
+
+// Input is the input of the builtin exec tool.
+// It mirrors the ExecCommand message added to specs declaring a tool with "builtin: exec".
+type Input struct {
+	Command string   `json:"command"`
+	Args    []string `json:"args,omitempty"`
+	Stdin   string   `json:"stdin,omitempty"`
+}
+
+// Output is the output of the builtin exec tool, mirroring the ExecResult message.
+type Output struct {
+	Stdout    string `json:"stdout"`
+	Stderr    string `json:"stderr"`
+	ExitCode  int    `json:"exit_code"`
+	TimedOut  bool   `json:"timed_out"`
+	Truncated bool   `json:"truncated"`
+}
+
+// InputSchema is the JSON schema of Input.
+var InputSchema = runtime.NewSchema(`{
+	"type": "object",
+	"properties": {
+		"command": {"type": "string", "description": "Program to run"},
+		"args": {"type": "array", "items": {"type": "string"}, "description": "Arguments of the program"},
+		"stdin": {"type": "string", "description": "Standard input of the program, such as the code run by an interpreter"}
+	},
+	"required": ["command"],
+	"additionalProperties": false
+}`)
+
+// Sandbox runs commands with limited resources.
+type Sandbox struct {
+	Allowed   []string      // Commands which may be run, such as "python3". Empty allows none.
+	Timeout   time.Duration // Maximum duration of a command. Zero means DefaultTimeout.
+	MaxMemory int64         // Maximum virtual memory of a command, in bytes. Zero means no limit. Requires a POSIX shell.
+	MaxOutput int           // Maximum size of the standard output and error of a command, each. Zero means DefaultMaxOutput.
+	Dir       string        // Directory holding the working directories of the commands. Empty means os.TempDir().
+	Env       []string      // Environment of the commands, in "key=value" form. The environment of the process is not inherited.
+}
+
+// Run runs the command of in, in a working directory of its own, removed afterwards.
+// Commands exiting with a non-zero status, or killed for exceeding the time limit,
+// are reported by the output rather than by an error.
+func (s *Sandbox) Run(ctx context.Context, in *Input) (*Output, error) {
+	if !slices.Contains(s.Allowed, in.Command) {
+		return nil, fmt.Errorf("%w: %q", ErrCommandNotAllowed, in.Command)
+	}
+
+	// Resolved here, as the environment of the command may not set the path
+	path, err := osexec.LookPath(in.Command)
+	if err != nil {
+		return nil, err
+	}
+
+	dir, err := os.MkdirTemp(s.Dir, "suricata-exec-")
+	if err != nil {
+		return nil, fmt.Errorf("create working directory: %w", err)
+	}
+	defer os.RemoveAll(dir)
+
+	ctx, cancel := context.WithTimeout(ctx, s.timeout())
+	defer cancel()
+
+	cmd := s.command(ctx, path, in.Args)
+	cmd.Dir = dir
+	isolate(cmd)
+	cmd.Env = s.Env
+	if cmd.Env == nil {
+		cmd.Env = []string{}
+	}
+	// Do not wait for the output of orphaned children past the deadline
+	cmd.WaitDelay = time.Second
+
+	stdout := &limitedBuffer{max: s.maxOutput()}
+	stderr := &limitedBuffer{max: s.maxOutput()}
+	cmd.Stdin = bytes.NewBufferString(in.Stdin)
+	cmd.Stdout, cmd.Stderr = stdout, stderr
+
+	err = cmd.Run()
+
+	out := &Output{
+		Stdout:    stdout.String(),
+		Stderr:    stderr.String(),
+		ExitCode:  cmd.ProcessState.ExitCode(),
+		TimedOut:  errors.Is(ctx.Err(), context.DeadlineExceeded),
+		Truncated: stdout.truncated || stderr.truncated,
+	}
+
+	var exitErr *osexec.ExitError
+	if err != nil && !errors.As(err, &exitErr) && !out.TimedOut {
+		return nil, fmt.Errorf("run %q: %w", in.Command, err)
+	}
+	return out, nil
+}
+
+// command returns the command running path, wrapped in a shell setting its memory limit if needed.
+func (s *Sandbox) command(ctx context.Context, path string, args []string) *osexec.Cmd {
+	if s.MaxMemory <= 0 {
+		return osexec.CommandContext(ctx, path, args...)
+	}
+
+	limit := strconv.FormatInt(max(s.MaxMemory/1024, 1), 10)
+	args = append([]string{"-c", `ulimit -v ` + limit + ` && exec "$0" "$@"`, path}, args...)
+	return osexec.CommandContext(ctx, "/bin/sh", args...)
+}
+
+func (s *Sandbox) timeout() time.Duration {
+	if s.Timeout > 0 {
+		return s.Timeout
+	}
+	return DefaultTimeout
+}
+
+func (s *Sandbox) maxOutput() int {
+	if s.MaxOutput > 0 {
+		return s.MaxOutput
+	}
+	return DefaultMaxOutput
+}
+
+// ToolSpec returns the spec of a tool running commands, to be handled with Sandbox.Run.
+func ToolSpec(name, description string) runtime.ToolSpec {
+	if description == "" {
+		description = "Runs a command in a sandbox, returning its output"
+	}
+	return runtime.ToolSpec{Name: name, Description: description, Schema: InputSchema}
+}
+
+// Tool adapts the sandbox to the method generated for the builtin exec tool in the tools interface
+// of an agent, whose In and Out types are the ExecCommand and ExecResult messages of the spec.
+func Tool[In, Out any](s *Sandbox) func(ctx context.Context, in *In) (*Out, error) {
+	return tools.Adapt[In, Out]("exec", s.Run)
+}
+
+// limitedBuffer keeps the first max bytes written to it, discarding the rest.
+type limitedBuffer struct {
+	buf       bytes.Buffer
+	max       int
+	truncated bool
+}
+
+func (b *limitedBuffer) Write(p []byte) (int, error) {
+	n := len(p)
+	if room := b.max - b.buf.Len(); len(p) > room {
+		p = p[:max(room, 0)]
+		b.truncated = true
+	}
+	b.buf.Write(p)
+	return n, nil
+}
+
+func (b *limitedBuffer) String() string {
+	return b.buf.String()
+}
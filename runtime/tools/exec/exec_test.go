@@ -0,0 +1,104 @@
+// Copyright (c) 2025 Suricata Contributors
+// Original Author: Stefano Scafiti
+//
+// This file is part of Suricata: Type-Safe AI Agents for Go.
+//
+// Licensed under the MIT License. You may obtain a copy of the License at
+//
+//	https://opensource.org/licenses/MIT
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package exec_test
+
+import (
+	"context"
+	"errors"
+	"os"
+	osexec "os/exec"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/ostafen/suricata/runtime/tools/exec"
+)
+
+func TestSandbox_Run(t *testing.T) {
+	if _, err := osexec.LookPath("sh"); err != nil {
+		t.Skip("sh not available")
+	}
+
+	dir := t.TempDir()
+	sandbox := &exec.Sandbox{Allowed: []string{"sh"}, Dir: dir, Env: []string{"GREETING=hello"}}
+	ctx := context.Background()
+
+	t.Run("output", func(t *testing.T) {
+		out, err := sandbox.Run(ctx, &exec.Input{Command: "sh", Stdin: "echo $GREETING; pwd; echo oops >&2; exit 3"})
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		lines := strings.Split(strings.TrimSpace(out.Stdout), "\n")
+		if len(lines) != 2 || lines[0] != "hello" || !strings.HasPrefix(lines[1], dir) {
+			t.Errorf("unexpected stdout %q", out.Stdout)
+		}
+		if out.Stderr != "oops\n" || out.ExitCode != 3 || out.TimedOut || out.Truncated {
+			t.Errorf("unexpected output %+v", out)
+		}
+
+		if entries, _ := os.ReadDir(dir); len(entries) != 0 {
+			t.Errorf("expected the working directory to be removed, got %v", entries)
+		}
+	})
+
+	t.Run("not allowed", func(t *testing.T) {
+		_, err := sandbox.Run(ctx, &exec.Input{Command: "rm", Args: []string{"-rf", "/"}})
+		if !errors.Is(err, exec.ErrCommandNotAllowed) {
+			t.Errorf("expected ErrCommandNotAllowed, got %v", err)
+		}
+	})
+
+	t.Run("timeout", func(t *testing.T) {
+		s := *sandbox
+		s.Timeout = 100 * time.Millisecond
+
+		start := time.Now()
+		out, err := s.Run(ctx, &exec.Input{Command: "sh", Args: []string{"-c", "sleep 5"}})
+		if err != nil {
+			t.Fatal(err)
+		}
+		if !out.TimedOut || time.Since(start) > 3*time.Second {
+			t.Errorf("expected the command to be killed, got %+v after %v", out, time.Since(start))
+		}
+	})
+
+	t.Run("truncated output", func(t *testing.T) {
+		s := *sandbox
+		s.MaxOutput = 4
+
+		out, err := s.Run(ctx, &exec.Input{Command: "sh", Stdin: "echo 123456789"})
+		if err != nil {
+			t.Fatal(err)
+		}
+		if out.Stdout != "1234" || !out.Truncated {
+			t.Errorf("expected the output to be truncated, got %+v", out)
+		}
+	})
+
+	t.Run("memory limit", func(t *testing.T) {
+		s := *sandbox
+		s.MaxMemory = 256 << 20
+
+		out, err := s.Run(ctx, &exec.Input{Command: "sh", Stdin: "ulimit -v"})
+		if err != nil {
+			t.Fatal(err)
+		}
+		if strings.TrimSpace(out.Stdout) != "262144" {
+			t.Errorf("expected the memory limit to be set, got %+v", out)
+		}
+	})
+}
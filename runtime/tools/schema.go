@@ -0,0 +1,199 @@
+// Copyright (c) 2025 Suricata Contributors
+// Original Author: Stefano Scafiti
+//
+// This file is part of Suricata: Type-Safe AI Agents for Go.
+//
+// Licensed under the MIT License. You may obtain a copy of the License at
+//
+//	https://opensource.org/licenses/MIT
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package tools
+
+import (
+	"encoding"
+	"encoding/json"
+	"fmt"
+	"reflect"
+	"strings"
+	"time"
+
+	"github.com/ostafen/suricata/runtime"
+)
+
+var (
+	timeType            = reflect.TypeOf(time.Time{})
+	decimalType         = reflect.TypeOf(runtime.Decimal{})
+	durationType        = reflect.TypeOf(time.Duration(0))
+	rawMessageType      = reflect.TypeOf(json.RawMessage(nil))
+	textMarshalerType   = reflect.TypeOf((*encoding.TextMarshaler)(nil)).Elem()
+	jsonUnmarshalerType = reflect.TypeOf((*json.Unmarshaler)(nil)).Elem()
+)
+
+// Schema returns the JSON schema of the values of t, which must be a struct type.
+// Properties are named after the JSON tags of the fields, and are required unless
+// the field is a pointer, which may also be null, or is tagged with omitempty. Fields can be further described with
+// the tags:
+//
+//   - description: the description of the property.
+//   - enum: the comma-separated values allowed for a string property.
+//   - format: the format of a string property, such as "date" or "email".
+//
+// Time values are encoded as date-time strings. Interface values and types implementing
+// json.Unmarshaler accept any value.
+func Schema(t reflect.Type) (*runtime.Schema, error) {
+	if t.Kind() != reflect.Struct {
+		return nil, fmt.Errorf("tools: expected a struct type, got %s", t)
+	}
+
+	schema, err := typeSchema(t, nil)
+	if err != nil {
+		return nil, err
+	}
+
+	data, err := json.Marshal(schema)
+	if err != nil {
+		return nil, err
+	}
+	return runtime.NewSchema(string(data)), nil
+}
+
+// typeSchema returns the schema of t. Visiting holds the structs being visited,
+// to reject recursive types, which cannot be described without references.
+func typeSchema(t reflect.Type, visiting []reflect.Type) (map[string]any, error) {
+	switch {
+	case t == timeType:
+		return map[string]any{"type": "string", "format": "date-time"}, nil
+	case t == decimalType:
+		return map[string]any{"type": "string", "format": "decimal", "pattern": runtime.DecimalPattern}, nil
+	case t == durationType:
+		return map[string]any{"type": "integer", "description": "Duration in nanoseconds"}, nil
+	case t == rawMessageType:
+		return map[string]any{}, nil
+	case t.Kind() != reflect.Pointer && reflect.PointerTo(t).Implements(jsonUnmarshalerType):
+		return map[string]any{}, nil
+	case t.Kind() != reflect.Pointer && reflect.PointerTo(t).Implements(textMarshalerType):
+		return map[string]any{"type": "string"}, nil
+	}
+
+	switch t.Kind() {
+	case reflect.Bool:
+		return map[string]any{"type": "boolean"}, nil
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64,
+		reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
+		return map[string]any{"type": "integer"}, nil
+	case reflect.Float32, reflect.Float64:
+		return map[string]any{"type": "number"}, nil
+	case reflect.String:
+		return map[string]any{"type": "string"}, nil
+	case reflect.Interface:
+		return map[string]any{}, nil
+	case reflect.Pointer:
+		schema, err := typeSchema(t.Elem(), visiting)
+		if err != nil {
+			return nil, err
+		}
+		if typ, ok := schema["type"].(string); ok {
+			schema["type"] = []string{typ, "null"}
+		}
+		return schema, nil
+	case reflect.Slice, reflect.Array:
+		if t.Elem().Kind() == reflect.Uint8 {
+			return map[string]any{"type": "string", "contentEncoding": "base64"}, nil
+		}
+
+		items, err := typeSchema(t.Elem(), visiting)
+		if err != nil {
+			return nil, err
+		}
+		return map[string]any{"type": "array", "items": items}, nil
+	case reflect.Map:
+		if t.Key().Kind() != reflect.String {
+			return nil, fmt.Errorf("tools: unsupported map key type %s", t.Key())
+		}
+
+		values, err := typeSchema(t.Elem(), visiting)
+		if err != nil {
+			return nil, err
+		}
+		return map[string]any{"type": "object", "additionalProperties": values}, nil
+	case reflect.Struct:
+		return structSchema(t, visiting)
+	}
+	return nil, fmt.Errorf("tools: unsupported type %s", t)
+}
+
+func structSchema(t reflect.Type, visiting []reflect.Type) (map[string]any, error) {
+	for _, v := range visiting {
+		if v == t {
+			return nil, fmt.Errorf("tools: recursive type %s", t)
+		}
+	}
+	visiting = append(visiting, t)
+
+	properties := make(map[string]any)
+	required := []string{}
+	for _, field := range reflect.VisibleFields(t) {
+		if !field.IsExported() || field.Anonymous {
+			continue
+		}
+
+		name, optional, ok := jsonField(field)
+		if !ok {
+			continue
+		}
+
+		prop, err := typeSchema(field.Type, visiting)
+		if err != nil {
+			return nil, fmt.Errorf("field %s: %w", field.Name, err)
+		}
+		if desc := field.Tag.Get("description"); desc != "" {
+			prop["description"] = desc
+		}
+		if enum := field.Tag.Get("enum"); enum != "" {
+			prop["enum"] = strings.Split(enum, ",")
+		}
+		if format := field.Tag.Get("format"); format != "" {
+			prop["format"] = format
+		}
+
+		properties[name] = prop
+		if !optional && field.Type.Kind() != reflect.Pointer {
+			required = append(required, name)
+		}
+	}
+
+	schema := map[string]any{
+		"type":       "object",
+		"properties": properties,
+	}
+	if len(required) > 0 {
+		schema["required"] = required
+	}
+	return schema, nil
+}
+
+// jsonField returns the JSON name of a struct field, whether it may be omitted,
+// and whether it is encoded at all.
+func jsonField(field reflect.StructField) (name string, optional, ok bool) {
+	tag := field.Tag.Get("json")
+	if tag == "-" {
+		return "", false, false
+	}
+
+	name, opts, _ := strings.Cut(tag, ",")
+	if name == "" {
+		name = field.Name
+	}
+	for _, opt := range strings.Split(opts, ",") {
+		if opt == "omitempty" || opt == "omitzero" {
+			optional = true
+		}
+	}
+	return name, optional, true
+}
@@ -0,0 +1,172 @@
+// Copyright (c) 2025 Suricata Contributors
+// Original Author: Stefano Scafiti
+//
+// This file is part of Suricata: Type-Safe AI Agents for Go.
+//
+// Licensed under the MIT License. You may obtain a copy of the License at
+//
+//	https://opensource.org/licenses/MIT
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+// Package tools derives tools from plain Go functions, for prototyping tools which are
+// not declared in the spec of an agent.
+package tools
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"reflect"
+	goruntime "runtime"
+	"strings"
+
+	"github.com/ostafen/suricata/runtime"
+)
+
+var (
+	contextType = reflect.TypeOf((*context.Context)(nil)).Elem()
+	errorType   = reflect.TypeOf((*error)(nil)).Elem()
+)
+
+// Tool is a tool backed by a Go function.
+type Tool struct {
+	Spec runtime.ToolSpec
+
+	fn     reflect.Value
+	inType reflect.Type // Struct type of the input
+	inPtr  bool         // Whether the function takes a pointer to the input
+}
+
+// FromFunc returns the tool calling fn, which must have the signature
+// func(context.Context, In) (Out, error), where In is a struct or a pointer to a struct.
+// The tool is named after fn, and the schema of its arguments is derived from the fields of In:
+// see Schema for the supported types and struct tags.
+// Anonymous functions must be named with WithName.
+func FromFunc(fn any, description string) (*Tool, error) {
+	v := reflect.ValueOf(fn)
+	if v.Kind() != reflect.Func {
+		return nil, fmt.Errorf("tools: expected a function, got %T", fn)
+	}
+
+	t := v.Type()
+	if t.NumIn() != 2 || t.In(0) != contextType || t.NumOut() != 2 || t.Out(1) != errorType {
+		return nil, fmt.Errorf("tools: expected func(context.Context, In) (Out, error), got %s", t)
+	}
+
+	inType, inPtr := t.In(1), false
+	if inType.Kind() == reflect.Pointer {
+		inType, inPtr = inType.Elem(), true
+	}
+	if inType.Kind() != reflect.Struct {
+		return nil, fmt.Errorf("tools: the input must be a struct or a pointer to a struct, got %s", t.In(1))
+	}
+
+	schema, err := Schema(inType)
+	if err != nil {
+		return nil, err
+	}
+
+	return &Tool{
+		Spec:   runtime.ToolSpec{Name: funcName(v), Description: description, Schema: schema},
+		fn:     v,
+		inType: inType,
+		inPtr:  inPtr,
+	}, nil
+}
+
+// WithName returns a copy of t named name.
+func (t *Tool) WithName(name string) *Tool {
+	named := *t
+	named.Spec.Name = name
+	return &named
+}
+
+// funcName returns the name of the function held by v, without its package,
+// or an empty string for anonymous functions.
+func funcName(v reflect.Value) string {
+	f := goruntime.FuncForPC(v.Pointer())
+	if f == nil {
+		return ""
+	}
+
+	name := f.Name()
+	name = name[strings.LastIndex(name, ".")+1:]
+	name = strings.TrimSuffix(name, "-fm") // Method values
+	if strings.HasPrefix(name, "func") && strings.Trim(name[len("func"):], "0123456789") == "" {
+		return ""
+	}
+	return name
+}
+
+// Unmarshal validates the arguments of a call against the schema of t, and decodes them.
+func (t *Tool) Unmarshal(data []byte) (any, error) {
+	in := reflect.New(t.inType)
+	if err := runtime.UnmarshalValidate(data, in.Interface(), t.Spec.Schema); err != nil {
+		return nil, err
+	}
+	return in.Interface(), nil
+}
+
+// Call calls the function of t with in, as returned by Unmarshal.
+func (t *Tool) Call(ctx context.Context, in any) (any, error) {
+	arg := reflect.ValueOf(in)
+	if arg.Type() != reflect.PointerTo(t.inType) {
+		return nil, fmt.Errorf("tools: %s: unexpected input of type %T", t.Spec.Name, in)
+	}
+	if !t.inPtr {
+		arg = arg.Elem()
+	}
+
+	res := t.fn.Call([]reflect.Value{reflect.ValueOf(ctx), arg})
+	err, _ := res[1].Interface().(error)
+	return res[0].Interface(), err
+}
+
+// Set is a list of tools, which provides the tool fields of a runtime.Request:
+//
+//	req.ToolSpecs, req.ToolUnmarshaller, req.ToolInvoker = set.Specs(), set.Unmarshal, set.Invoke
+type Set []*Tool
+
+// ErrNoSuchTool is returned when a call refers to a tool not in the set.
+var ErrNoSuchTool = errors.New("no such tool")
+
+// Specs returns the specs of the tools.
+func (s Set) Specs() []runtime.ToolSpec {
+	specs := make([]runtime.ToolSpec, len(s))
+	for i, t := range s {
+		specs[i] = t.Spec
+	}
+	return specs
+}
+
+// Unmarshal decodes the arguments of a call to the named tool. It is a runtime.ToolUnmarshaller.
+func (s Set) Unmarshal(name string, data []byte) (any, error) {
+	t, err := s.lookup(name)
+	if err != nil {
+		return nil, err
+	}
+	return t.Unmarshal(data)
+}
+
+// Invoke calls the named tool. It is a runtime.ToolInvoker.
+func (s Set) Invoke(ctx context.Context, name string, in any) (any, error) {
+	t, err := s.lookup(name)
+	if err != nil {
+		return nil, err
+	}
+	return t.Call(ctx, in)
+}
+
+func (s Set) lookup(name string) (*Tool, error) {
+	for _, t := range s {
+		if t.Spec.Name == name {
+			return t, nil
+		}
+	}
+	return nil, fmt.Errorf("%w: %q", ErrNoSuchTool, name)
+}
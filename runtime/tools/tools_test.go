@@ -0,0 +1,204 @@
+// Copyright (c) 2025 Suricata Contributors
+// Original Author: Stefano Scafiti
+//
+// This file is part of Suricata: Type-Safe AI Agents for Go.
+//
+// Licensed under the MIT License. You may obtain a copy of the License at
+//
+//	https://opensource.org/licenses/MIT
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package tools_test
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"reflect"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/ostafen/suricata/runtime/tools"
+)
+
+type FlightQuery struct {
+	From   string    `json:"from" description:"IATA code of the departure airport"`
+	To     string    `json:"to"`
+	Date   time.Time `json:"date"`
+	Class  string    `json:"class,omitempty" enum:"economy,business"`
+	Max    *float64  `json:"max_price"`
+	Tags   []string  `json:"tags,omitempty"`
+	Extra  map[string]int
+	hidden string
+	Skip   string `json:"-"`
+}
+
+type Flight struct {
+	Code string `json:"code"`
+}
+
+func FindFlights(ctx context.Context, q *FlightQuery) ([]Flight, error) {
+	if q.From == "" {
+		return nil, errors.New("missing departure")
+	}
+	return []Flight{{Code: q.From + "-" + q.To}}, nil
+}
+
+func TestFromFunc(t *testing.T) {
+	tool, err := tools.FromFunc(FindFlights, "Finds flights")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if tool.Spec.Name != "FindFlights" || tool.Spec.Description != "Finds flights" {
+		t.Errorf("unexpected spec: %+v", tool.Spec)
+	}
+
+	src, err := tool.Spec.Schema.LoadJSON()
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	var schema struct {
+		Properties map[string]map[string]any `json:"properties"`
+		Required   []string                  `json:"required"`
+	}
+	data, _ := json.Marshal(src)
+	if err := json.Unmarshal(data, &schema); err != nil {
+		t.Fatal(err)
+	}
+
+	if want := []string{"from", "to", "date", "Extra"}; !reflect.DeepEqual(schema.Required, want) {
+		t.Errorf("expected required %v, got %v", want, schema.Required)
+	}
+	if len(schema.Properties) != 7 {
+		t.Errorf("expected 7 properties, got %v", schema.Properties)
+	}
+	if schema.Properties["from"]["description"] != "IATA code of the departure airport" {
+		t.Errorf("expected the description tag to be used: %v", schema.Properties["from"])
+	}
+	if schema.Properties["date"]["format"] != "date-time" {
+		t.Errorf("expected a date-time string: %v", schema.Properties["date"])
+	}
+	if enum, _ := schema.Properties["class"]["enum"].([]any); len(enum) != 2 {
+		t.Errorf("expected the enum tag to be used: %v", schema.Properties["class"])
+	}
+	if !reflect.DeepEqual(schema.Properties["max_price"]["type"], []any{"number", "null"}) || schema.Properties["Extra"]["type"] != "object" {
+		t.Errorf("unexpected property types: %v", schema.Properties)
+	}
+}
+
+func TestSet(t *testing.T) {
+	find, err := tools.FromFunc(FindFlights, "Finds flights")
+	if err != nil {
+		t.Fatal(err)
+	}
+	echo, err := tools.FromFunc(func(ctx context.Context, in struct {
+		Text string `json:"text"`
+	}) (string, error) {
+		return in.Text, nil
+	}, "Echoes a text")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if echo.Spec.Name != "" {
+		t.Errorf("expected anonymous functions to be unnamed, got %q", echo.Spec.Name)
+	}
+
+	set := tools.Set{find, echo.WithName("Echo")}
+	if specs := set.Specs(); len(specs) != 2 || specs[1].Name != "Echo" {
+		t.Errorf("unexpected specs: %+v", specs)
+	}
+
+	ctx := context.Background()
+	call := func(name, args string) (any, error) {
+		in, err := set.Unmarshal(name, []byte(args))
+		if err != nil {
+			return nil, err
+		}
+		return set.Invoke(ctx, name, in)
+	}
+
+	out, err := call("FindFlights", `{"from":"FCO","to":"JFK","date":"2025-06-01T10:00:00Z","max_price":null,"Extra":{}}`)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if flights := out.([]Flight); len(flights) != 1 || flights[0].Code != "FCO-JFK" {
+		t.Errorf("unexpected output: %v", out)
+	}
+
+	if out, err := call("Echo", `{"text":"hi"}`); err != nil || out != "hi" {
+		t.Errorf("expected the input to be passed by value, got %v, %v", out, err)
+	}
+
+	if _, err := call("FindFlights", `{"from":"FCO"}`); err == nil {
+		t.Error("expected arguments missing required properties to be rejected")
+	}
+	if _, err := call("FindFlights", `{"from":"","to":"JFK","date":"2025-06-01T10:00:00Z","max_price":1,"Extra":{}}`); err == nil || err.Error() != "missing departure" {
+		t.Errorf("expected the error of the function, got %v", err)
+	}
+	if _, err := call("Missing", `{}`); !errors.Is(err, tools.ErrNoSuchTool) {
+		t.Errorf("expected ErrNoSuchTool, got %v", err)
+	}
+}
+
+func TestFromFunc_InvalidSignature(t *testing.T) {
+	type Node struct {
+		Children []Node `json:"children"`
+	}
+
+	tests := []struct {
+		name string
+		fn   any
+		want string
+	}{
+		{"not a function", 42, "expected a function"},
+		{"no context", func(in FlightQuery) (string, error) { return "", nil }, "expected func"},
+		{"no error", func(ctx context.Context, in FlightQuery) string { return "" }, "expected func"},
+		{"scalar input", func(ctx context.Context, in string) (string, error) { return "", nil }, "must be a struct"},
+		{"recursive input", func(ctx context.Context, in Node) (string, error) { return "", nil }, "recursive type"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if _, err := tools.FromFunc(tt.fn, ""); err == nil || !strings.Contains(err.Error(), tt.want) {
+				t.Errorf("expected an error containing %q, got %v", tt.want, err)
+			}
+		})
+	}
+}
+
+func TestAdapt(t *testing.T) {
+	// Types generated from the spec, matching the ones of the tool
+	type Query struct {
+		From string `json:"from"`
+		To   string `json:"to"`
+	}
+	type Result struct {
+		Code string `json:"code"`
+	}
+
+	find := tools.Adapt[Query, Result]("flights", func(ctx context.Context, q *FlightQuery) (*Flight, error) {
+		if q.From == "" {
+			return nil, errors.New("missing departure")
+		}
+		return &Flight{Code: q.From + q.To}, nil
+	})
+
+	out, err := find(context.Background(), &Query{From: "FCO", To: "JFK"})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if out.Code != "FCOJFK" {
+		t.Errorf("unexpected result %+v", out)
+	}
+
+	if _, err := find(context.Background(), &Query{}); err == nil || err.Error() != "missing departure" {
+		t.Errorf("expected the error of the tool, got %v", err)
+	}
+}
@@ -0,0 +1,67 @@
+// Copyright (c) 2025 Suricata Contributors
+// Original Author: Stefano Scafiti
+//
+// This file is part of Suricata: Type-Safe AI Agents for Go.
+//
+// Licensed under the MIT License. You may obtain a copy of the License at
+//
+//	https://opensource.org/licenses/MIT
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package websearch
+
+import (
+	"context"
+	"net/http"
+	"net/url"
+	"strconv"
+)
+
+// BingBaseURL is the base URL of the Bing Web Search API.
+const BingBaseURL = "https://api.bing.microsoft.com"
+
+// Bing is a Searcher backed by the Bing Web Search API.
+type Bing struct {
+	baseURL string
+	apiKey  string
+
+	HTTPClient *http.Client // Client sending the requests. Nil means runtime.DefaultHTTPClient.
+}
+
+// NewBing returns a searcher using the Bing Web Search API, usually at BingBaseURL.
+func NewBing(baseURL, apiKey string) *Bing {
+	return &Bing{baseURL: baseURL, apiKey: apiKey}
+}
+
+func (b *Bing) Search(ctx context.Context, query string, limit int) ([]Result, error) {
+	params := url.Values{"q": {query}, "count": {strconv.Itoa(min(limit, 50))}, "responseFilter": {"Webpages"}}
+	req, err := http.NewRequestWithContext(ctx, "GET", b.baseURL+"/v7.0/search?"+params.Encode(), nil)
+	if err != nil {
+		return nil, err
+	}
+	req.Header.Set("Ocp-Apim-Subscription-Key", b.apiKey)
+
+	var resp struct {
+		WebPages struct {
+			Value []struct {
+				Name    string `json:"name"`
+				URL     string `json:"url"`
+				Snippet string `json:"snippet"`
+			} `json:"value"`
+		} `json:"webPages"`
+	}
+	if err := getJSON(b.HTTPClient, "bing", req, &resp); err != nil {
+		return nil, err
+	}
+
+	results := make([]Result, len(resp.WebPages.Value))
+	for i, r := range resp.WebPages.Value {
+		results[i] = Result{Title: r.Name, URL: r.URL, Snippet: r.Snippet}
+	}
+	return results, nil
+}
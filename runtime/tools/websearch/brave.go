@@ -0,0 +1,67 @@
+// Copyright (c) 2025 Suricata Contributors
+// Original Author: Stefano Scafiti
+//
+// This file is part of Suricata: Type-Safe AI Agents for Go.
+//
+// Licensed under the MIT License. You may obtain a copy of the License at
+//
+//	https://opensource.org/licenses/MIT
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package websearch
+
+import (
+	"context"
+	"net/http"
+	"net/url"
+	"strconv"
+)
+
+// BraveBaseURL is the base URL of the Brave Search API.
+const BraveBaseURL = "https://api.search.brave.com"
+
+// Brave is a Searcher backed by the Brave Search API.
+type Brave struct {
+	baseURL string
+	apiKey  string
+
+	HTTPClient *http.Client // Client sending the requests. Nil means runtime.DefaultHTTPClient.
+}
+
+// NewBrave returns a searcher using the Brave Search API, usually at BraveBaseURL.
+func NewBrave(baseURL, apiKey string) *Brave {
+	return &Brave{baseURL: baseURL, apiKey: apiKey}
+}
+
+func (b *Brave) Search(ctx context.Context, query string, limit int) ([]Result, error) {
+	params := url.Values{"q": {query}, "count": {strconv.Itoa(min(limit, 20))}}
+	req, err := http.NewRequestWithContext(ctx, "GET", b.baseURL+"/res/v1/web/search?"+params.Encode(), nil)
+	if err != nil {
+		return nil, err
+	}
+	req.Header.Set("X-Subscription-Token", b.apiKey)
+
+	var resp struct {
+		Web struct {
+			Results []struct {
+				Title       string `json:"title"`
+				URL         string `json:"url"`
+				Description string `json:"description"`
+			} `json:"results"`
+		} `json:"web"`
+	}
+	if err := getJSON(b.HTTPClient, "brave", req, &resp); err != nil {
+		return nil, err
+	}
+
+	results := make([]Result, len(resp.Web.Results))
+	for i, r := range resp.Web.Results {
+		results[i] = Result{Title: r.Title, URL: r.URL, Snippet: r.Description}
+	}
+	return results, nil
+}
@@ -0,0 +1,62 @@
+// Copyright (c) 2025 Suricata Contributors
+// Original Author: Stefano Scafiti
+//
+// This file is part of Suricata: Type-Safe AI Agents for Go.
+//
+// Licensed under the MIT License. You may obtain a copy of the License at
+//
+//	https://opensource.org/licenses/MIT
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package websearch
+
+import (
+	"context"
+	"net/http"
+	"net/url"
+)
+
+// SearxNG is a Searcher backed by a SearxNG instance, whose JSON output format must be enabled.
+type SearxNG struct {
+	baseURL string
+
+	HTTPClient *http.Client // Client sending the requests. Nil means runtime.DefaultHTTPClient.
+}
+
+// NewSearxNG returns a searcher querying the SearxNG instance at baseURL.
+func NewSearxNG(baseURL string) *SearxNG {
+	return &SearxNG{baseURL: baseURL}
+}
+
+func (s *SearxNG) Search(ctx context.Context, query string, limit int) ([]Result, error) {
+	params := url.Values{"q": {query}, "format": {"json"}}
+	req, err := http.NewRequestWithContext(ctx, "GET", s.baseURL+"/search?"+params.Encode(), nil)
+	if err != nil {
+		return nil, err
+	}
+
+	var resp struct {
+		Results []struct {
+			Title   string `json:"title"`
+			URL     string `json:"url"`
+			Content string `json:"content"`
+		} `json:"results"`
+	}
+	if err := getJSON(s.HTTPClient, "searxng", req, &resp); err != nil {
+		return nil, err
+	}
+
+	// SearxNG does not support limiting the number of results
+	resp.Results = resp.Results[:min(limit, len(resp.Results))]
+
+	results := make([]Result, len(resp.Results))
+	for i, r := range resp.Results {
+		results[i] = Result{Title: r.Title, URL: r.URL, Snippet: r.Content}
+	}
+	return results, nil
+}
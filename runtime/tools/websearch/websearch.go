@@ -0,0 +1,117 @@
+// Copyright (c) 2025 Suricata Contributors
+// Original Author: Stefano Scafiti
+//
+// This file is part of Suricata: Type-Safe AI Agents for Go.
+//
+// Licensed under the MIT License. You may obtain a copy of the License at
+//
+//	https://opensource.org/licenses/MIT
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+// Package websearch implements a tool searching the web, backed by SearxNG, Brave Search
+// or Bing. Agents declare it in specs with "builtin: websearch".
+package websearch
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+
+	"github.com/ostafen/suricata/runtime"
+	"github.com/ostafen/suricata/runtime/tools"
+)
+
+// DefaultLimit is the number of results returned by searches which do not set a limit.
+const DefaultLimit = 5
+
+// Result is a web page found by a search.
+type Result struct {
+	Title   string `json:"title"`
+	URL     string `json:"url"`
+	Snippet string `json:"snippet"`
+}
+
+// Searcher searches the web.
+type Searcher interface {
+	Search(ctx context.Context, query string, limit int) ([]Result, error)
+}
+
+// SearchInput is the input of the builtin websearch tool.
+// It mirrors the WebSearchQuery message added to specs declaring a tool with "builtin: websearch".
+type SearchInput struct {
+	Query string `json:"query"`
+	Limit int    `json:"limit,omitempty"`
+}
+
+// SearchOutput is the output of the builtin websearch tool, mirroring the WebSearchResults message.
+type SearchOutput struct {
+	Results []Result `json:"results"`
+}
+
+// SearchSchema is the JSON schema of SearchInput.
+var SearchSchema = runtime.NewSchema(`{
+	"type": "object",
+	"properties": {
+		"query": {"type": "string", "description": "Text to search the web for"},
+		"limit": {"type": "integer", "minimum": 1, "description": "Maximum number of results to return"}
+	},
+	"required": ["query"],
+	"additionalProperties": false
+}`)
+
+// ToolSpec returns the spec of a tool searching the web, to be handled with SearchTool.
+func ToolSpec(name, description string) runtime.ToolSpec {
+	if description == "" {
+		description = "Searches the web, returning the title, the URL and a snippet of the pages found"
+	}
+	return runtime.ToolSpec{Name: name, Description: description, Schema: SearchSchema}
+}
+
+// SearchTool runs the builtin websearch tool with searcher.
+func SearchTool(ctx context.Context, searcher Searcher, in *SearchInput) (*SearchOutput, error) {
+	limit := in.Limit
+	if limit <= 0 {
+		limit = DefaultLimit
+	}
+
+	results, err := searcher.Search(ctx, in.Query, limit)
+	if err != nil {
+		return nil, err
+	}
+	if len(results) > limit {
+		results = results[:limit]
+	}
+	return &SearchOutput{Results: results}, nil
+}
+
+// Tool adapts searcher to the method generated for the builtin websearch tool in the tools interface
+// of an agent, whose In and Out types are the WebSearchQuery and WebSearchResults messages of the spec.
+func Tool[In, Out any](searcher Searcher) func(ctx context.Context, in *In) (*Out, error) {
+	return tools.Adapt[In, Out]("websearch", func(ctx context.Context, in *SearchInput) (*SearchOutput, error) {
+		return SearchTool(ctx, searcher, in)
+	})
+}
+
+// getJSON sends req, decoding the JSON response into v. Errors are reported as a *runtime.ProviderError.
+func getJSON(client *http.Client, provider string, req *http.Request, v any) error {
+	if client == nil {
+		client = runtime.DefaultHTTPClient
+	}
+	req.Header.Set("Accept", "application/json")
+
+	resp, err := client.Do(req)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		return runtime.NewProviderError(provider, resp)
+	}
+	return json.NewDecoder(resp.Body).Decode(v)
+}
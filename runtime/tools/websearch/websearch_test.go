@@ -0,0 +1,103 @@
+// Copyright (c) 2025 Suricata Contributors
+// Original Author: Stefano Scafiti
+//
+// This file is part of Suricata: Type-Safe AI Agents for Go.
+//
+// Licensed under the MIT License. You may obtain a copy of the License at
+//
+//	https://opensource.org/licenses/MIT
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package websearch_test
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/ostafen/suricata/runtime"
+	"github.com/ostafen/suricata/runtime/tools/websearch"
+)
+
+func TestSearchers(t *testing.T) {
+	tests := []struct {
+		name     string
+		path     string
+		header   string
+		body     string
+		searcher func(url string) websearch.Searcher
+	}{
+		{
+			name: "searxng",
+			path: "/search",
+			body: `{"results":[{"title":"Go","url":"https://go.dev","content":"The Go language"},{"title":"Other","url":"https://example.com","content":"x"}]}`,
+			searcher: func(url string) websearch.Searcher {
+				return websearch.NewSearxNG(url)
+			},
+		},
+		{
+			name:   "brave",
+			path:   "/res/v1/web/search",
+			header: "X-Subscription-Token",
+			body:   `{"web":{"results":[{"title":"Go","url":"https://go.dev","description":"The Go language"}]}}`,
+			searcher: func(url string) websearch.Searcher {
+				return websearch.NewBrave(url, "key")
+			},
+		},
+		{
+			name:   "bing",
+			path:   "/v7.0/search",
+			header: "Ocp-Apim-Subscription-Key",
+			body:   `{"webPages":{"value":[{"name":"Go","url":"https://go.dev","snippet":"The Go language"}]}}`,
+			searcher: func(url string) websearch.Searcher {
+				return websearch.NewBing(url, "key")
+			},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				if r.URL.Path != tt.path || r.URL.Query().Get("q") != "golang" {
+					t.Errorf("unexpected request %s", r.URL)
+				}
+				if tt.header != "" && r.Header.Get(tt.header) != "key" {
+					t.Errorf("expected the API key in the %s header", tt.header)
+				}
+				w.Write([]byte(tt.body))
+			}))
+			defer srv.Close()
+
+			out, err := websearch.SearchTool(context.Background(), tt.searcher(srv.URL), &websearch.SearchInput{Query: "golang", Limit: 1})
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			want := websearch.Result{Title: "Go", URL: "https://go.dev", Snippet: "The Go language"}
+			if len(out.Results) != 1 || out.Results[0] != want {
+				t.Errorf("unexpected results %+v", out.Results)
+			}
+		})
+	}
+}
+
+func TestSearchers_ProviderError(t *testing.T) {
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		http.Error(w, "invalid subscription token", http.StatusUnauthorized)
+	}))
+	defer srv.Close()
+
+	_, err := websearch.NewBrave(srv.URL, "bad").Search(context.Background(), "golang", 5)
+
+	var perr *runtime.ProviderError
+	if !errors.As(err, &perr) || perr.StatusCode != http.StatusUnauthorized || perr.Provider != "brave" {
+		t.Errorf("expected a provider error, got %v", err)
+	}
+}
@@ -0,0 +1,104 @@
+// Copyright (c) 2025 Suricata Contributors
+// Original Author: Stefano Scafiti
+//
+// This file is part of Suricata: Type-Safe AI Agents for Go.
+//
+// Licensed under the MIT License. You may obtain a copy of the License at
+//
+//	https://opensource.org/licenses/MIT
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package runtime
+
+import "context"
+
+// Names of the operations traced by the runtime.
+const (
+	SpanInvoke    = "suricata.invoke"
+	SpanAgentLoop = "suricata.agent_loop"
+	SpanLLMCall   = "suricata.llm_call"
+	SpanToolCall  = "suricata.tool_call"
+)
+
+// Attribute keys attached to runtime spans.
+const (
+	AttrModel            = "llm.model"
+	AttrPromptVersion    = "prompt.version"
+	AttrPromptTokens     = "llm.usage.prompt_tokens"
+	AttrCompletionTokens = "llm.usage.completion_tokens"
+	AttrToolName         = "tool.name"
+	AttrToolIterations   = "agent.tool_iterations"
+)
+
+// Attr is a key-value pair attached to a span.
+type Attr struct {
+	Key   string
+	Value any
+}
+
+// Tracer starts spans around runtime operations.
+// See the runtime/otel package for an OpenTelemetry implementation.
+type Tracer interface {
+	Start(ctx context.Context, name string, attrs ...Attr) (context.Context, Span)
+}
+
+type Span interface {
+	SetAttributes(attrs ...Attr)
+	// End completes the span, recording err if not nil.
+	End(err error)
+}
+
+// WithTracer enables tracing of invocations, agent loops, LLM calls and tool calls.
+// It can be passed multiple times: every span is forwarded to all tracers.
+func WithTracer(tracer Tracer) Option {
+	return func(r *Runtime) {
+		switch t := r.tracer.(type) {
+		case noopTracer:
+			r.tracer = tracer
+		case multiTracer:
+			r.tracer = append(t, tracer)
+		default:
+			r.tracer = multiTracer{t, tracer}
+		}
+	}
+}
+
+type multiTracer []Tracer
+
+func (mt multiTracer) Start(ctx context.Context, name string, attrs ...Attr) (context.Context, Span) {
+	spans := make(multiSpan, len(mt))
+	for i, t := range mt {
+		ctx, spans[i] = t.Start(ctx, name, attrs...)
+	}
+	return ctx, spans
+}
+
+type multiSpan []Span
+
+func (ms multiSpan) SetAttributes(attrs ...Attr) {
+	for _, s := range ms {
+		s.SetAttributes(attrs...)
+	}
+}
+
+func (ms multiSpan) End(err error) {
+	for i := len(ms) - 1; i >= 0; i-- {
+		ms[i].End(err)
+	}
+}
+
+type noopTracer struct{}
+
+func (noopTracer) Start(ctx context.Context, _ string, _ ...Attr) (context.Context, Span) {
+	return ctx, noopSpan{}
+}
+
+type noopSpan struct{}
+
+func (noopSpan) SetAttributes(...Attr) {}
+func (noopSpan) End(error)             {}
@@ -0,0 +1,177 @@
+// Copyright (c) 2025 Suricata Contributors
+// Original Author: Stefano Scafiti
+//
+// This file is part of Suricata: Type-Safe AI Agents for Go.
+//
+// Licensed under the MIT License. You may obtain a copy of the License at
+//
+//	https://opensource.org/licenses/MIT
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package runtime
+
+import (
+	"context"
+	"encoding/json"
+	"sync"
+	"time"
+
+	"github.com/xeipuuv/gojsonschema"
+)
+
+// StepKind is the kind of a step of a transcript.
+type StepKind string
+
+const (
+	StepPrompt          StepKind = "prompt"           // Prompt sent to the model
+	StepResponse        StepKind = "response"         // Response of the model
+	StepToolCall        StepKind = "tool_call"        // Call of a tool, with its result
+	StepValidationError StepKind = "validation_error" // Response rejected by parsing or schema validation
+)
+
+// TranscriptStep is a step of a run.
+type TranscriptStep struct {
+	Kind     StepKind        `json:"kind"`
+	Time     time.Time       `json:"time"`
+	Duration time.Duration   `json:"duration,omitempty"`
+	Content  string          `json:"content,omitempty"` // Text of prompts and responses
+	Tool     string          `json:"tool,omitempty"`
+	Args     json.RawMessage `json:"args,omitempty"`
+	Result   json.RawMessage `json:"result,omitempty"`
+	Error    string          `json:"error,omitempty"`
+}
+
+// TranscriptTool is a tool available to a recorded run.
+type TranscriptTool struct {
+	Name        string          `json:"name"`
+	Description string          `json:"description,omitempty"`
+	Schema      json.RawMessage `json:"schema,omitempty"`
+}
+
+// Transcript is the record of a run: its input, every prompt, model response, tool call and
+// validation failure, and its outcome. It serializes to JSON, for audit, debugging and replay.
+type Transcript struct {
+	RequestID     string           `json:"request_id"`
+	Action        string           `json:"action,omitempty"`
+	PromptVersion string           `json:"prompt_version,omitempty"`
+	Model         string           `json:"model,omitempty"`
+	Instructions  string           `json:"instructions,omitempty"`
+	Tools         []TranscriptTool `json:"tools,omitempty"`
+	ToolLoop      bool             `json:"tool_loop,omitempty"`     // Whether responses are tool calls or final outputs
+	OutputSchema  json.RawMessage  `json:"output_schema,omitempty"` // Nil for free-text outputs
+	Input         json.RawMessage  `json:"input,omitempty"`
+	Output        json.RawMessage  `json:"output,omitempty"`
+	Error         string           `json:"error,omitempty"`
+	Start         time.Time        `json:"start"`
+	Duration      time.Duration    `json:"duration"`
+	Steps         []TranscriptStep `json:"steps"`
+
+	mu sync.Mutex
+}
+
+type (
+	transcriptKey struct{}
+	recordingKey  struct{}
+)
+
+// WithTranscript returns a context recording the run invoked with it into t, which should not
+// be shared by several runs. Runs of other agents started by the tools of the run, which inherit
+// the context, are not recorded.
+func WithTranscript(ctx context.Context, t *Transcript) context.Context {
+	return context.WithValue(ctx, transcriptKey{}, t)
+}
+
+// startTranscript returns the context of a run, recording it if a transcript was requested.
+func startTranscript(ctx context.Context, req *Request) context.Context {
+	t, _ := ctx.Value(transcriptKey{}).(*Transcript)
+	if t == nil && recording(ctx) == nil {
+		return ctx
+	}
+
+	ctx = context.WithValue(ctx, transcriptKey{}, (*Transcript)(nil))
+	ctx = context.WithValue(ctx, recordingKey{}, t)
+	if t == nil {
+		return ctx
+	}
+
+	t.mu.Lock()
+	defer t.mu.Unlock()
+
+	t.RequestID = RequestIDFromContext(ctx)
+	t.Action = req.Action
+	t.PromptVersion = req.PromptVersion
+	t.Model = ModelOptionsFromContext(ctx).Model
+	t.Instructions = req.Instructions
+	t.ToolLoop = req.ToolInvoker != nil
+	t.OutputSchema = schemaJSON(req.OutputSchema)
+	t.Input, _ = json.Marshal(req.Input)
+
+	t.Tools = make([]TranscriptTool, len(req.ToolSpecs))
+	for i, spec := range req.ToolSpecs {
+		t.Tools[i] = TranscriptTool{Name: spec.Name, Description: spec.Description, Schema: schemaJSON(spec.Schema)}
+	}
+	t.Start = time.Now()
+	return ctx
+}
+
+// recording returns the transcript of the run of ctx, if any.
+func recording(ctx context.Context) *Transcript {
+	t, _ := ctx.Value(recordingKey{}).(*Transcript)
+	return t
+}
+
+func (t *Transcript) add(step TranscriptStep) {
+	if t == nil {
+		return
+	}
+
+	t.mu.Lock()
+	defer t.mu.Unlock()
+
+	if step.Time.IsZero() {
+		step.Time = time.Now()
+	}
+	t.Steps = append(t.Steps, step)
+}
+
+func (t *Transcript) finish(out any, err error) {
+	if t == nil {
+		return
+	}
+
+	t.mu.Lock()
+	defer t.mu.Unlock()
+
+	t.Duration = time.Since(t.Start)
+	if err != nil {
+		t.Error = err.Error()
+		return
+	}
+	t.Output, _ = json.Marshal(out)
+}
+
+// schemaJSON returns the document of schema, or nil if it is nil or cannot be loaded.
+func schemaJSON(schema gojsonschema.JSONLoader) json.RawMessage {
+	if schema == nil {
+		return nil
+	}
+
+	doc, err := schema.LoadJSON()
+	if err != nil {
+		return nil
+	}
+	data, _ := json.Marshal(doc)
+	return data
+}
+
+func errorString(err error) string {
+	if err == nil {
+		return ""
+	}
+	return err.Error()
+}
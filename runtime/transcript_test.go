@@ -0,0 +1,189 @@
+// Copyright (c) 2025 Suricata Contributors
+// Original Author: Stefano Scafiti
+//
+// This file is part of Suricata: Type-Safe AI Agents for Go.
+//
+// Licensed under the MIT License. You may obtain a copy of the License at
+//
+//	https://opensource.org/licenses/MIT
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package runtime_test
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"testing"
+
+	"github.com/ostafen/suricata/runtime"
+	"github.com/ostafen/suricata/runtime/runtimetest"
+)
+
+func TestTranscript(t *testing.T) {
+	type Input struct {
+		City string `json:"city"`
+	}
+	type Output struct {
+		Result string `json:"result"`
+	}
+
+	mock := runtimetest.NewInvoker(t).Respond(
+		`{"name":"search","args":{"city":"rome"}}`,
+		`{"done":true,"out":{"wrong":1}}`,
+		`{"done":true,"out":{"result":"found"}}`,
+	)
+	rt := runtime.NewRuntime(mock, runtime.WithRetryPolicy(runtime.RetryPolicy{MaxAttempts: 2}))
+
+	var out Output
+	req := runtime.Request{
+		Action:         "Find",
+		Instructions:   "You find things.",
+		PromptTemplate: "Find in {{.City}}",
+		Input:          &Input{City: "rome"},
+		InputSchema:    runtime.NewSchema(`{"type":"object"}`),
+		Output:         &out,
+		OutputSchema:   runtime.NewSchema(`{"type":"object","properties":{"result":{"type":"string"}},"required":["result"],"additionalProperties":false}`),
+		ToolSpecs:      []runtime.ToolSpec{{Name: "search", Schema: runtime.NewSchema(`{"type":"object"}`)}},
+		ToolUnmarshaller: func(name string, data []byte) (any, error) {
+			var args map[string]string
+			return args, json.Unmarshal(data, &args)
+		},
+		ToolInvoker: func(ctx context.Context, name string, in any) (any, error) {
+			return map[string]int{"hits": 3}, nil
+		},
+	}
+
+	var transcript runtime.Transcript
+	ctx := runtime.WithTranscript(runtime.WithRequestID(context.Background(), "req-1"), &transcript)
+	if err := rt.Invoke(ctx, req); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	if transcript.RequestID != "req-1" || transcript.Action != "Find" || transcript.Instructions != "You find things." {
+		t.Errorf("unexpected transcript: %+v", &transcript)
+	}
+	if string(transcript.Input) != `{"city":"rome"}` || string(transcript.Output) != `{"result":"found"}` || transcript.Error != "" {
+		t.Errorf("unexpected input and output: %s, %s", transcript.Input, transcript.Output)
+	}
+
+	var kinds []runtime.StepKind
+	for _, step := range transcript.Steps {
+		kinds = append(kinds, step.Kind)
+	}
+	want := []runtime.StepKind{
+		runtime.StepPrompt, runtime.StepResponse, runtime.StepToolCall, runtime.StepResponse,
+		runtime.StepValidationError, runtime.StepResponse,
+	}
+	if len(kinds) != len(want) {
+		t.Fatalf("expected steps %v, got %v", want, kinds)
+	}
+	for i := range want {
+		if kinds[i] != want[i] {
+			t.Fatalf("expected steps %v, got %v", want, kinds)
+		}
+	}
+
+	call := transcript.Steps[2]
+	if call.Tool != "search" || string(call.Args) != `{"city":"rome"}` || string(call.Result) != `{"hits":3}` {
+		t.Errorf("unexpected tool call step: %+v", call)
+	}
+
+	data, err := json.Marshal(&transcript)
+	if err != nil {
+		t.Fatal(err)
+	}
+	var decoded runtime.Transcript
+	if err := json.Unmarshal(data, &decoded); err != nil || len(decoded.Steps) != len(want) {
+		t.Errorf("expected the transcript to round-trip through JSON, got %v", err)
+	}
+
+}
+
+func TestReplay(t *testing.T) {
+	type Output struct {
+		City  string   `json:"city"`
+		Hotel string   `json:"hotel"`
+		Tags  []string `json:"tags"`
+	}
+
+	recorder := runtimetest.NewInvoker(t).Respond(
+		`{"name":"search","args":{"city":"rome","stars":4}}`,
+		`{"done":true,"out":{"city":"rome","hotel":"Roma","tags":["center"]}}`,
+	)
+	rt := runtime.NewRuntime(recorder)
+
+	var out Output
+	req := runtime.Request{
+		Instructions:   "You book hotels.",
+		PromptTemplate: "Book a hotel",
+		Input:          &struct{}{},
+		InputSchema:    runtime.NewSchema(`{"type":"object"}`),
+		Output:         &out,
+		OutputSchema:   runtime.NewSchema(`{"type":"object"}`),
+		ToolSpecs:      []runtime.ToolSpec{{Name: "search", Description: "Searches hotels", Schema: runtime.NewSchema(`{"type":"object"}`)}},
+		ToolUnmarshaller: func(name string, data []byte) (any, error) {
+			var args map[string]any
+			return args, json.Unmarshal(data, &args)
+		},
+		ToolInvoker: func(ctx context.Context, name string, in any) (any, error) {
+			return []string{"Roma", "Colosseo"}, nil
+		},
+	}
+
+	var transcript runtime.Transcript
+	if err := rt.Invoke(runtime.WithTranscript(context.Background(), &transcript), req); err != nil {
+		t.Fatal(err)
+	}
+
+	data, _ := json.Marshal(&transcript)
+	var saved runtime.Transcript
+	if err := json.Unmarshal(data, &saved); err != nil {
+		t.Fatal(err)
+	}
+
+	replayer := runtimetest.NewInvoker(t)
+	replayer.Expect().PromptContains("Book a hotel").Respond(`[{"name":"search","args":{"stars":4,"city":"rome"}},{"name":"search","args":{"city":"milan"}}]`)
+	replayer.Expect().PromptContains(`["Roma","Colosseo"]`).Respond(`{"done":true,"out":{"city":"rome","hotel":"Colosseo","tags":["center","view"]}}`)
+
+	var specs []runtime.ToolSpec
+	inv := runtime.InvokerFunc(func(ctx context.Context, system string, messages []runtime.Message) (string, error) {
+		specs = runtime.ToolSpecsFromContext(ctx)
+		return replayer.Invoke(ctx, system, messages)
+	})
+
+	res, err := runtime.Replay(context.Background(), &saved, inv)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	if len(specs) != 1 || specs[0].Name != "search" || specs[0].Description != "Searches hotels" {
+		t.Errorf("expected the recorded tools to be declared, got %+v", specs)
+	}
+	if replayer.Calls()[0].System != "You book hotels." {
+		t.Errorf("expected the recorded instructions, got %q", replayer.Calls()[0].System)
+	}
+
+	calls := res.Transcript.Steps[2:4]
+	if string(calls[0].Result) != `["Roma","Colosseo"]` || calls[1].Error == "" {
+		t.Errorf("expected recorded calls to be answered from the transcript, and others to fail: %+v", calls)
+	}
+
+	var diffs []string
+	for _, d := range res.Diffs {
+		diffs = append(diffs, d.String())
+	}
+	want := []string{`hotel: "Roma" -> "Colosseo"`, `tags.1: null -> "view"`}
+	if res.Equal() || len(diffs) != len(want) || diffs[0] != want[0] || diffs[1] != want[1] {
+		t.Errorf("expected diffs %v, got %v", want, diffs)
+	}
+
+	if _, err := runtime.Replay(context.Background(), &runtime.Transcript{}, replayer); !errors.Is(err, runtime.ErrNotReplayable) {
+		t.Errorf("expected ErrNotReplayable, got %v", err)
+	}
+}
@@ -17,13 +17,15 @@ package runtime
 
 import (
 	"encoding/json"
-	"strings"
 
 	"github.com/xeipuuv/gojsonschema"
 )
 
 // UnmarshalValidate validates JSON against a schema, then unmarshals it into 'out'.
+// Date-time values not in RFC3339 format are converted using ParseTime before validation,
+// decimal values written as numbers are converted to strings and UUIDs are lowercased.
 func UnmarshalValidate(data []byte, out any, schema gojsonschema.JSONLoader) error {
+	data = normalizeFormats(data, schema)
 	if err := ValidateRawJSON(data, schema); err != nil {
 		return err
 	}
@@ -32,13 +34,13 @@ func UnmarshalValidate(data []byte, out any, schema gojsonschema.JSONLoader) err
 
 // ValidateRawJSON checks if JSON data conforms to the given schema.
 func ValidateRawJSON(data []byte, schema gojsonschema.JSONLoader) error {
-	res, err := gojsonschema.Validate(schema, gojsonschema.NewBytesLoader(data))
+	res, err := validate(schema, gojsonschema.NewBytesLoader(data))
 	if err != nil {
 		return err
 	}
 
 	if !res.Valid() {
-		return ErrInvalidOutput
+		return newSchemaError(res)
 	}
 	return nil
 }
@@ -51,31 +53,3 @@ func ValidateJSON(in any, schema gojsonschema.JSONLoader) error {
 	}
 	return ValidateRawJSON(data, schema)
 }
-
-// ExtractJSONFromString tries to find the first valid JSON object in the input string.
-// It returns the JSON string and an error if none is found.
-func ExtractJSONFromString(input string) string {
-	start := strings.Index(input, "{")
-	if start == -1 {
-		return ""
-	}
-
-	braceCount := 0
-	for i := start; i < len(input); i++ {
-		switch input[i] {
-		case '{':
-			braceCount++
-		case '}':
-			braceCount--
-			if braceCount == 0 {
-				candidate := input[start : i+1]
-
-				var js json.RawMessage
-				if err := json.Unmarshal([]byte(candidate), &js); err == nil {
-					return candidate
-				}
-			}
-		}
-	}
-	return ""
-}